@@ -3,20 +3,9 @@
 package main
 
 import (
-	"log"
-
-	"github.com/Akaiko1/file-tree-scanner/internal/config"
-	"github.com/Akaiko1/file-tree-scanner/internal/ui"
+	"github.com/Akaiko1/file-tree-scanner/pkg/filetree/app"
 )
 
 func main() {
-	log.Println("Starting File Tree Scanner...")
-
-	config := config.DefaultConfig()
-	log.Printf("Config: MaxDepth=%d, ShowHidden=%v", config.MaxDepth, config.ShowHidden)
-
-	app := ui.NewFileTreeApp(config)
-	log.Println("App created, starting UI...")
-
-	app.Run()
-}
\ No newline at end of file
+	app.Main()
+}
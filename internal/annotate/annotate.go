@@ -0,0 +1,68 @@
+package annotate
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Func returns a short label for a node, or false to leave the node unannotated.
+type Func func(node *scanner.TreeNode) (label string, ok bool)
+
+// Annotator is a registered annotation source.
+type Annotator struct {
+	Name     string
+	Prepare  func(root *scanner.TreeNode) // Optional; runs once per scan before any Annotate call
+	Annotate Func
+}
+
+var (
+	mu         sync.RWMutex
+	annotators []Annotator
+)
+
+// Register adds an annotator; annotations appear in registration order.
+func Register(a Annotator) {
+	mu.Lock()
+	defer mu.Unlock()
+	annotators = append(annotators, a)
+}
+
+// Active reports whether any annotator is registered.
+func Active() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(annotators) > 0
+}
+
+// Prepare runs every annotator's batch pre-pass over a freshly scanned tree.
+func Prepare(root *scanner.TreeNode) {
+	if root == nil {
+		return
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, a := range annotators {
+		if a.Prepare != nil {
+			a.Prepare(root)
+		}
+	}
+}
+
+// Suffix returns the node's annotations in brackets with a leading space, e.g. " [@docs] [//app:lib]".
+func Suffix(node *scanner.TreeNode) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(annotators) == 0 || node == nil {
+		return ""
+	}
+
+	var builder strings.Builder
+	for _, a := range annotators {
+		if label, ok := a.Annotate(node); ok && label != "" {
+			builder.WriteString(" [" + label + "]")
+		}
+	}
+	return builder.String()
+}
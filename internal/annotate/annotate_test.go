@@ -0,0 +1,69 @@
+package annotate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// withAnnotators runs the test with only the given annotators registered.
+func withAnnotators(t *testing.T, list ...Annotator) {
+	t.Helper()
+	mu.Lock()
+	saved := annotators
+	annotators = nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		annotators = saved
+		mu.Unlock()
+	})
+	for _, a := range list {
+		Register(a)
+	}
+}
+
+func TestSuffix(t *testing.T) {
+	withAnnotators(t)
+	node := &scanner.TreeNode{Name: "main.go", Path: "/repo/main.go"}
+	if Active() || Suffix(node) != "" {
+		t.Fatal("annotations without annotators")
+	}
+
+	withAnnotators(t,
+		Annotator{Name: "owner", Annotate: func(n *scanner.TreeNode) (string, bool) { return "@docs", true }},
+		Annotator{Name: "none", Annotate: func(n *scanner.TreeNode) (string, bool) { return "hidden", false }},
+		Annotator{Name: "empty", Annotate: func(n *scanner.TreeNode) (string, bool) { return "", true }},
+		Annotator{Name: "target", Annotate: func(n *scanner.TreeNode) (string, bool) {
+			return "//app:" + strings.TrimSuffix(n.Name, ".go"), true
+		}},
+	)
+	if !Active() {
+		t.Error("Active() with annotators registered")
+	}
+	if got := Suffix(node); got != " [@docs] [//app:main]" {
+		t.Errorf("Suffix = %q, want the labels in registration order", got)
+	}
+	if got := Suffix(nil); got != "" {
+		t.Errorf("Suffix(nil) = %q", got)
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	var prepared []*scanner.TreeNode
+	withAnnotators(t,
+		Annotator{Name: "plain", Annotate: func(*scanner.TreeNode) (string, bool) { return "", false }},
+		Annotator{
+			Name:     "batch",
+			Prepare:  func(root *scanner.TreeNode) { prepared = append(prepared, root) },
+			Annotate: func(*scanner.TreeNode) (string, bool) { return "", false },
+		},
+	)
+	root := &scanner.TreeNode{Name: "repo", IsDir: true}
+	Prepare(root)
+	Prepare(nil)
+	if len(prepared) != 1 || prepared[0] != root {
+		t.Errorf("pre-pass ran for %v, want once for the root", prepared)
+	}
+}
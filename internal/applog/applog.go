@@ -0,0 +1,79 @@
+package applog
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DefaultTailLines is the number of log lines kept in memory for diagnostics.
+const DefaultTailLines = 200
+
+// RingBuffer is an io.Writer that keeps the last N complete log lines.
+type RingBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	next    int
+	full    bool
+	partial strings.Builder
+}
+
+// NewRingBuffer creates a RingBuffer holding at most max lines.
+func NewRingBuffer(max int) *RingBuffer {
+	if max <= 0 {
+		max = DefaultTailLines
+	}
+	return &RingBuffer{lines: make([]string, max)}
+}
+
+// Write appends p to the buffer, splitting it into lines.
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, b := range p {
+		if b != '\n' {
+			r.partial.WriteByte(b)
+			continue
+		}
+		r.lines[r.next] = r.partial.String()
+		r.partial.Reset()
+		r.next = (r.next + 1) % len(r.lines)
+		if r.next == 0 {
+			r.full = true
+		}
+	}
+	return len(p), nil
+}
+
+// Lines returns the buffered lines, oldest first.
+func (r *RingBuffer) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	out := make([]string, 0, len(r.lines))
+	out = append(out, r.lines[r.next:]...)
+	return append(out, r.lines[:r.next]...)
+}
+
+var std = NewRingBuffer(DefaultTailLines)
+
+// Install routes the standard logger to stderr and the in-memory tail.
+func Install() {
+	SetConsole(os.Stderr)
+}
+
+// SetConsole routes the standard logger to w and the in-memory tail.
+func SetConsole(w io.Writer) {
+	log.SetOutput(io.MultiWriter(w, std))
+}
+
+// Tail returns the most recent lines written to the standard logger since Install.
+func Tail() []string {
+	return std.Lines()
+}
@@ -0,0 +1,109 @@
+package applog
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Class is a kind of warning a Sampler counts, such as permission denied on directories.
+type Class struct {
+	Problem string // What went wrong, e.g. "permission denied"
+	Items   string // What it went wrong on, in the plural, e.g. "directories"
+}
+
+// Sampler logs the first few warnings of each class in full and counts the rest, so a problem
+// repeated over a whole subtree logs a handful of lines and one summary instead of a line per
+// path. It is safe for concurrent use.
+type Sampler struct {
+	mu       sync.Mutex
+	perClass int
+	classes  map[Class]*sample
+	order    []Class
+	logf     func(format string, args ...any)
+}
+
+// sample is what a Sampler knows of one class.
+type sample struct {
+	count int
+	under string // Deepest path that is or holds every path seen; empty once they share none
+}
+
+// NewSampler creates a Sampler logging at most perClass warnings of each class in full, through
+// the standard logger.
+func NewSampler(perClass int) *Sampler {
+	if perClass < 0 {
+		perClass = 0
+	}
+	return &Sampler{perClass: perClass, classes: make(map[Class]*sample), logf: log.Printf}
+}
+
+// Printf counts a warning of class about path, logging it when fewer than the sampler's limit of
+// its class have been logged.
+func (s *Sampler) Printf(class Class, path string, format string, args ...any) {
+	s.mu.Lock()
+	entry := s.classes[class]
+	if entry == nil {
+		entry = &sample{under: path}
+		s.classes[class] = entry
+		s.order = append(s.order, class)
+	} else if entry.under != "" {
+		entry.under = commonDir(entry.under, path)
+	}
+	entry.count++
+	shown := entry.count <= s.perClass
+	s.mu.Unlock()
+
+	if shown {
+		s.logf(format, args...)
+	}
+}
+
+// Flush logs one summary for each class with warnings left unlogged, in the order the classes
+// first appeared, and starts counting afresh.
+func (s *Sampler) Flush() {
+	s.mu.Lock()
+	var summaries []string
+	for _, class := range s.order {
+		if entry := s.classes[class]; entry.count > s.perClass {
+			summaries = append(summaries, summarize(class, entry, s.perClass))
+		}
+	}
+	s.classes = make(map[Class]*sample)
+	s.order = nil
+	s.mu.Unlock()
+
+	for _, summary := range summaries {
+		s.logf("%s", summary)
+	}
+}
+
+// summarize describes the warnings of a class, e.g. "Warning: permission denied on 12841
+// directories under /var/lib (first 3 logged above)".
+func summarize(class Class, entry *sample, shown int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Warning: %s on %d %s", class.Problem, entry.count, class.Items)
+	if entry.under != "" && entry.under != "." {
+		fmt.Fprintf(&b, " under %s", entry.under)
+	}
+	if shown > 0 {
+		fmt.Fprintf(&b, " (first %d logged above)", shown)
+	}
+	return b.String()
+}
+
+// commonDir returns the deepest path that is or holds both a and b, or "" if they share none.
+func commonDir(a, b string) string {
+	for {
+		if a == b || strings.HasPrefix(b, strings.TrimSuffix(a, string(filepath.Separator))+string(filepath.Separator)) {
+			return a
+		}
+		parent := filepath.Dir(a)
+		if parent == a {
+			return ""
+		}
+		a = parent
+	}
+}
@@ -0,0 +1,114 @@
+package applog
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// recordingSampler returns a sampler logging perClass warnings of each class into the returned
+// lines instead of the standard logger.
+func recordingSampler(perClass int) (*Sampler, *[]string) {
+	var lines []string
+	var mu sync.Mutex
+	s := NewSampler(perClass)
+	s.logf = func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	return s, &lines
+}
+
+var (
+	denied  = Class{Problem: "permission denied", Items: "directories"}
+	limited = Class{Problem: "entry limit reached", Items: "directories"}
+)
+
+// path returns a path from slash-separated parts.
+func path(parts ...string) string {
+	return filepath.Join(append([]string{string(filepath.Separator)}, parts...)...)
+}
+
+func TestSamplerCountsAndFlushes(t *testing.T) {
+	s, lines := recordingSampler(2)
+	for i := 0; i < 5; i++ {
+		p := path("var", "lib", fmt.Sprint(i))
+		s.Printf(denied, p, "denied %s", p)
+	}
+	s.Printf(limited, path("srv"), "limited %s", path("srv"))
+	want := []string{"denied " + path("var", "lib", "0"), "denied " + path("var", "lib", "1"), "limited " + path("srv")}
+	if !slices.Equal(*lines, want) {
+		t.Fatalf("logged %q before flushing, want %q", *lines, want)
+	}
+
+	// Only the class past its limit is summarized
+	s.Flush()
+	summary := fmt.Sprintf("Warning: permission denied on 5 directories under %s (first 2 logged above)", path("var", "lib"))
+	if got := (*lines)[len(want):]; !slices.Equal(got, []string{summary}) {
+		t.Errorf("flush logged %q, want %q", got, summary)
+	}
+
+	// Counting starts afresh
+	*lines = nil
+	s.Printf(denied, path("a"), "again")
+	s.Flush()
+	if !slices.Equal(*lines, []string{"again"}) {
+		t.Errorf("after a flush logged %q, want the warning alone", *lines)
+	}
+}
+
+func TestSamplerSummaryOrder(t *testing.T) {
+	s, lines := recordingSampler(0)
+	s.Printf(limited, path("b"), "limited")
+	s.Printf(denied, path("a"), "denied")
+	s.Printf(limited, path("c"), "limited")
+	if len(*lines) != 0 {
+		t.Fatalf("logged %q with a limit of 0", *lines)
+	}
+	s.Flush()
+	// Classes in the order they first appeared; paths sharing only the root name none
+	want := []string{
+		"Warning: entry limit reached on 2 directories under " + string(filepath.Separator),
+		"Warning: permission denied on 1 directories under " + path("a"),
+	}
+	if !slices.Equal(*lines, want) {
+		t.Errorf("flush logged %q, want %q", *lines, want)
+	}
+}
+
+func TestSamplerConcurrent(t *testing.T) {
+	s, lines := recordingSampler(3)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Printf(denied, path("data", fmt.Sprint(i)), "denied")
+		}(i)
+	}
+	wg.Wait()
+	s.Flush()
+	want := fmt.Sprintf("Warning: permission denied on 50 directories under %s (first 3 logged above)", path("data"))
+	if len(*lines) != 4 || (*lines)[3] != want {
+		t.Errorf("logged %q, want 3 warnings and %q", *lines, want)
+	}
+}
+
+func TestCommonDir(t *testing.T) {
+	tests := []struct{ a, b, want string }{
+		{path("a", "b"), path("a", "b"), path("a", "b")},
+		{path("a", "b"), path("a", "b", "c"), path("a", "b")},
+		{path("a", "b", "c"), path("a", "b"), path("a", "b")},
+		{path("a", "bc"), path("a", "b"), path("a")},
+		{path("a"), path("z"), string(filepath.Separator)},
+		{"rel", "other", ""},
+	}
+	for _, tt := range tests {
+		if got := commonDir(tt.a, tt.b); got != tt.want {
+			t.Errorf("commonDir(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
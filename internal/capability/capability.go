@@ -0,0 +1,62 @@
+// Package capability records which optional features this build supports. Each package providing
+// one registers it when the program starts, from the build-tagged file implementing it where the
+// feature depends on the platform or build tags, so a build without that package or file does
+// not report the feature.
+package capability
+
+import (
+	"slices"
+	"sync"
+)
+
+// Name identifies a feature.
+type Name string
+
+// Features that may be registered.
+const (
+	Scan               Name = "scan"                // Reading directories into trees; always present
+	Render             Name = "render"              // Text, HTML, OPML and card output; always present
+	Hashes             Name = "hashes"              // Hashing file contents to find duplicates
+	Xattrs             Name = "xattrs"              // Listing extended attributes or alternate data streams
+	AllocatedSize      Name = "allocated-size"      // Sizes allocated on disk, apart from apparent sizes
+	BackgroundPriority Name = "background-priority" // Scanning at low CPU and I/O priority
+	OneFileSystem      Name = "one-file-system"     // Telling filesystems apart to stay on one and break directory cycles
+	VolumeInfo         Name = "volume-info"         // Capacity and free space of the scanned volume
+	Archives           Name = "archives"            // Listing zip and tar archives like folders
+	SQLiteExport       Name = "sqlite-export"       // Exporting trees as SQLite databases
+	ContextPack        Name = "context-pack"        // Writing trees with file contents for AI assistants
+	LayoutCheck        Name = "layout-check"        // Checking trees against expected layouts
+	Snapshots          Name = "snapshots"           // Scheduled snapshots and comparing them
+	Watch              Name = "watch"               // Keeping a shown tree up to date as the folder changes
+)
+
+var (
+	mu         sync.Mutex
+	registered = make(map[Name]bool)
+)
+
+// Register records that this build supports name.
+func Register(name Name) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered[name] = true
+}
+
+// Has reports whether this build supports name.
+func Has(name Name) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return registered[name]
+}
+
+// List returns the features this build supports, sorted by name.
+func List() []Name {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]Name, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
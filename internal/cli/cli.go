@@ -0,0 +1,682 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
+	"github.com/Akaiko1/file-tree-scanner/internal/applog"
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/contextpack"
+	"github.com/Akaiko1/file-tree-scanner/internal/diagnose"
+	"github.com/Akaiko1/file-tree-scanner/internal/exporter"
+	"github.com/Akaiko1/file-tree-scanner/internal/folderopts"
+	"github.com/Akaiko1/file-tree-scanner/internal/importer"
+	"github.com/Akaiko1/file-tree-scanner/internal/layout"
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/snapshot"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK        = 0
+	ExitFailure   = 1
+	ExitUsage     = 2
+	ExitTruncated = 3 // Output was written but covers only part of the tree
+	ExitOutputCut = 4 // Output was cut to --max-output-bytes
+	ExitNoDisplay = 5 // The GUI was asked for without a display to show it on
+	ExitLayout    = 6 // The tree does not match the --validate layout
+)
+
+const (
+	noGUIFlag    = "no-gui"
+	doctorFlag   = "doctor"
+	validateFlag = "validate"
+	scheduleFlag = "schedule"
+)
+
+// Requested reports whether the command line asks for headless mode, the doctor, a layout
+// check or scheduled snapshots.
+func Requested(args []string) bool {
+	for _, arg := range args {
+		for _, name := range []string{noGUIFlag, doctorFlag, validateFlag, scheduleFlag} {
+			if arg == "-"+name || arg == "--"+name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Headless reports whether args, given without --no-gui, still fully describe a headless scan:
+// they parse as command line flags and name exactly one directory.
+func Headless(args []string) bool {
+	opts, err := parseArgs(args, io.Discard)
+	return err == nil && !opts.doctor && opts.path != ""
+}
+
+// NoDisplay explains on w that the GUI cannot start without a display, suggesting headless mode
+// with an example for the program named name, and returns ExitNoDisplay.
+func NoDisplay(w io.Writer, name, detail string) int {
+	fmt.Fprintf(w, "Error: the GUI needs a display, but %s.\n", detail)
+	fmt.Fprintln(w, "Scan without the GUI instead, for example:")
+	fmt.Fprintf(w, "  %s --%s --sizes /path/to/folder\n", name, noGUIFlag)
+	fmt.Fprintf(w, "Run %s --%s -h for all options.\n", name, noGUIFlag)
+	return ExitNoDisplay
+}
+
+// options holds the parsed command line.
+type options struct {
+	path        string
+	format      string
+	output      string
+	doctor      bool
+	layout      *layout.Layout // Expected structure to check the tree against instead of writing it
+	schedule    *snapshot.Schedule
+	snapshotDir string
+	keep        int // Snapshots kept by --schedule; 0 keeps all
+	rowsPerFile int
+	progress    string
+	color       string
+	symbols     bool // Mark links and executables with glyphs instead of colors
+	depthColors bool
+	indent      int  // Spaces per level of outline output
+	indentTabs  bool // Indent outline output with tabs instead
+	pack        contextpack.Options
+	hideIgnored bool
+	quoteNames  bool
+	unreadable  bool
+	kindIcons   bool
+	onlyXattrs  bool
+	verbose     bool
+	maxBytes    int64
+	redactions  []string
+	pseudonyms  bool
+	redactor    *renderer.Redactor
+	folder      *folderopts.Folder // Options saved for the scanned folder and merged into config, nil for none
+	useFolder   bool               // Whether options saved for the folder apply
+	messages    *messages          // Errors, warnings and the summary for stderr, in the form --progress asks for
+	config      *config.Config
+}
+
+// Run scans the directory given on the command line and writes the result, returning the process exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	opts, err := parseArgs(args, stderr)
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(stderr, "Error:", err)
+		}
+		return ExitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if opts.doctor {
+		return runDoctor(ctx, stdout)
+	}
+	if opts.schedule != nil {
+		return runSchedule(ctx, opts, stdout, stderr)
+	}
+
+	// JSON progress owns stderr; keep log lines from corrupting the stream
+	msgs := opts.messages
+	if opts.progress == progressJSON {
+		applog.SetConsole(io.Discard)
+	}
+
+	// Colors and their symbols need the executable bit, which costs a stat per file
+	opts.config.MarkExecutables = opts.layout == nil && opts.format == "text" && opts.output == "" && (opts.symbols || useColor(opts.color, stdout))
+
+	s := scanner.NewFileTreeScanner(opts.config)
+	info, serr := os.Stat(opts.path)
+	archive := serr == nil && !info.IsDir() && importer.IsArchive(opts.path)
+	scanCtx := ctx
+	if limit := opts.config.ScanTimeout; limit > 0 {
+		var stopTimer context.CancelFunc
+		if archive {
+			scanCtx, stopTimer = context.WithTimeoutCause(ctx, limit, scanner.Stop(scanner.ReasonTimeout, limit.String()))
+		} else {
+			scanCtx, stopTimer = scanner.WithScanBudget(ctx, s, limit)
+		}
+		defer stopTimer()
+	}
+	started := time.Now()
+	var result *scanner.ScanResult
+	if archive {
+		// Archives are listed like the folder they would extract to
+		result, err = importer.FromArchive(scanCtx, opts.path)
+	} else {
+		var estimate int
+		if opts.progress == progressBar {
+			// A two-level count gives the bar something to measure against
+			if preflight, perr := s.Preflight(scanCtx, opts.path); perr == nil {
+				estimate = preflight.Estimate
+			}
+		}
+		result, err = s.ScanDirectoryWithProgress(scanCtx, opts.path, newProgressFunc(opts.progress, stderr, estimate))
+	}
+	if err != nil && result != nil && ctx.Err() == nil && scanCtx.Err() != nil {
+		err = nil // Out of time: the partial tree is written and reported as truncated
+	}
+	if err != nil {
+		msgs.error(err)
+		return ExitFailure
+	}
+	elapsed := time.Since(started)
+
+	if opts.layout != nil {
+		return runValidate(result, opts.layout, stdout, msgs)
+	}
+
+	annotate.Prepare(result.Root)
+	written, err := writeResult(ctx, result, opts, stdout)
+	var cut *renderer.OutputLimitError
+	if err != nil && !errors.As(err, &cut) {
+		msgs.error(err)
+		return ExitFailure
+	}
+	if opts.verbose {
+		writeSummary(msgs.lines(), result, elapsed, written, opts)
+	}
+	if cut != nil {
+		msgs.warnf("%v", cut)
+		return ExitOutputCut
+	}
+	if result.Truncated {
+		msgs.warnf("%s, output is partial", result.TruncatedReason.Message(result.TruncatedLimit))
+		return ExitTruncated
+	}
+	return ExitOK
+}
+
+// parseArgs parses command line flags into options. Options saved for the folder are merged
+// into the defaults the flags start from, so that flags given explicitly win over them.
+func parseArgs(args []string, stderr io.Writer) (*options, error) {
+	opts, err := parseFlags(args, stderr, config.DefaultConfig())
+	if err != nil || opts.doctor || !opts.useFolder {
+		return opts, err
+	}
+	// The folder is only known once the flags are parsed, so they are parsed again over its options
+	folder := savedOptions(opts.path, opts.messages)
+	if folder == nil {
+		return opts, nil
+	}
+	cfg := config.DefaultConfig()
+	folder.Options.Apply(cfg)
+	if opts, err = parseFlags(args, stderr, cfg); err != nil {
+		return nil, err
+	}
+	opts.folder = folder
+	return opts, nil
+}
+
+// parseFlags parses command line flags into options, starting from the settings in cfg.
+func parseFlags(args []string, stderr io.Writer, cfg *config.Config) (*options, error) {
+	opts := &options{config: cfg, pack: contextpack.DefaultOptions()}
+
+	flags := flag.NewFlagSet("file-tree-scanner", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Bool(noGUIFlag, false, "run without the GUI")
+	flags.BoolVar(&opts.doctor, doctorFlag, false, "check the environment and print pass/warn/fail diagnostics")
+	layoutPath := flags.String(validateFlag, "", "check the tree against the expected layout in this text or JSON file and print its violations instead of the tree, exiting with 6 if there are any")
+	scheduleSpec := flags.String(scheduleFlag, "", "write a JSON snapshot of the directory to --snapshot-dir on this schedule until interrupted: an interval such as 6h, @hourly, @daily or \"daily 02:30\"")
+	flags.StringVar(&opts.snapshotDir, "snapshot-dir", "", "directory --schedule writes timestamped snapshots to")
+	flags.IntVar(&opts.keep, "keep", 30, "snapshots --schedule keeps, deleting the oldest (0 keeps all)")
+	flags.StringVar(&opts.format, "format", "text", "output format: text, html, opml, outline, cards, json, csv, flat, sqlite or pack")
+	flags.IntVar(&opts.rowsPerFile, "rows-per-file", 0, "split csv and flat output into numbered files of this many rows inside the --output directory")
+	flags.StringVar(&opts.output, "output", "", "write output to this file instead of stdout")
+	flags.StringVar(&opts.progress, "progress", progressNone, "report progress on stderr: json, which also writes errors, warnings and the summary as JSON records, or bar")
+	flags.StringVar(&opts.color, "color", colorAuto, "color text output on stdout: auto, always or never")
+	flags.BoolVar(&opts.symbols, "symbols", false, "mark links (↪), broken links (✗) and executables (*) with symbols instead of colors on stdout")
+	flags.BoolVar(&opts.depthColors, "depth-colors", false, "mute html text progressively with depth")
+	flags.IntVar(&opts.indent, "indent", len(renderer.DefaultOutlineIndent), "spaces per level of outline output")
+	flags.BoolVar(&opts.indentTabs, "indent-tabs", false, "indent outline output with one tab per level instead of spaces")
+	flags.BoolVar(&opts.pack.HonorExportIgnore, "honor-export-ignore", opts.pack.HonorExportIgnore, "leave paths marked export-ignore in .gitattributes out of context packs")
+	flags.BoolVar(&opts.pack.Dedup, "dedup", false, "store each distinct file content of a context pack once, addressed by its hash")
+	flags.BoolVar(&opts.hideIgnored, "hide-export-ignored", false, "also leave export-ignore paths out of text, html and opml output")
+	flags.BoolVar(&opts.unreadable, "mark-unreadable", false, "append ⚠ to directories that could not be read in text, html and opml output")
+	flags.Int64Var(&opts.maxBytes, "max-output-bytes", 0, "cut text output at the last line that fits in this many bytes and end it with \"#TRUNCATED items_omitted=N\"; json output leaves out entries instead and stays valid (0 for no limit)")
+	flags.BoolVar(&opts.verbose, "verbose", false, "print a summary of the scan and the output written on stderr")
+	flags.BoolVar(&opts.kindIcons, "kind-icons", false, "mark code, images, documents, archives and binaries with icons of their own in text and html output")
+	flags.BoolVar(&opts.onlyXattrs, "only-xattrs", false, "draw only entries with extended attributes, or alternate data streams on Windows, in text, html and opml output; implies --xattrs")
+	flags.BoolVar(&opts.quoteNames, "quote-names", false, "quote names in text and html output that contain tree connectors, icons or edge whitespace")
+	flags.Func("redact", "mask this text in text, html, opml, cards and pack output, file contents of packs included; prefix \"re:\" for a regular expression whose groups, if any, are masked (repeatable)", func(rule string) error {
+		opts.redactions = append(opts.redactions, rule)
+		return nil
+	})
+	flags.BoolVar(&opts.pseudonyms, "pseudonyms", false, "replace --redact matches with stable pseudonyms instead of "+renderer.RedactionMask)
+	flags.IntVar(&cfg.MaxDepth, "max-depth", cfg.MaxDepth, "maximum directory depth (-1 for unlimited)")
+	flags.IntVar(&cfg.HardDepthLimit, "hard-depth-limit", cfg.HardDepthLimit, "depth never scanned past, even with --max-depth -1, marking where the tree was cut (0 for no cap)")
+	flags.BoolVar(&cfg.ShowSize, "sizes", cfg.ShowSize, "collect sizes and show them after each entry, directories as the sum of their contents")
+	flags.BoolVar(&cfg.CollectTimes, "times", cfg.CollectTimes, "collect modification times and show each entry's date")
+	flags.BoolVar(&cfg.CollectMode, "modes", cfg.CollectMode, "collect permissions and show them before each entry, like \"drwxr-xr-x\"")
+	flags.BoolVar(&cfg.CollectXattrs, "xattrs", cfg.CollectXattrs, "list extended attributes, or alternate data streams on Windows, of every entry")
+	flags.BoolVar(&cfg.ComputeHashes, "hashes", cfg.ComputeHashes, "hash file contents to find duplicates, reported with --verbose; reads every file")
+	flags.Int64Var(&cfg.HashMaxBytes, "hash-max-bytes", cfg.HashMaxBytes, "files larger than this are not hashed (0 for no limit)")
+	flags.BoolVar(&cfg.CountLines, "lines", cfg.CountLines, "count the lines of text files and show them after each file, with the total reported by --verbose")
+	flags.Int64Var(&cfg.LinesMaxBytes, "lines-max-bytes", cfg.LinesMaxBytes, "files larger than this are not counted (0 for no limit)")
+	flags.BoolVar(&cfg.EstimateTokens, "tokens", cfg.EstimateTokens, "estimate the language model tokens of text files and show each directory's total, like \"≈ 48k tokens\"; accurate to about a fifth")
+	flags.Int64Var(&cfg.TokensMaxBytes, "tokens-max-bytes", cfg.TokensMaxBytes, "files larger than this get no token estimate (0 for no limit)")
+	flags.IntVar(&cfg.DuplicateDirMinItems, "duplicate-dirs", cfg.DuplicateDirMinItems, "find directories that are copies of each other holding at least this many entries, listed with --verbose (0 for off)")
+	flags.StringVar(&cfg.SizeBasis, "size-basis", cfg.SizeBasis, "size to show: apparent or allocated")
+	flags.BoolVar(&cfg.NaturalSort, "natural-sort", cfg.NaturalSort, "sort names with numbers by value and ignoring case, so file2 comes before file10 (false sorts by byte value)")
+	flags.StringVar(&cfg.SortBy, "sort", cfg.SortBy, "order entries of each directory by name, size, time or extension, directories first; size and time need --sizes and --times")
+	flags.BoolVar(&cfg.SortDescending, "sort-descending", cfg.SortDescending, "reverse the --sort order, e.g. largest or newest first")
+	flags.BoolVar(&cfg.ShowHidden, "hidden", cfg.ShowHidden, "include hidden files and directories")
+	flags.BoolVar(&cfg.RespectGitignore, "gitignore", cfg.RespectGitignore, "skip entries matched by .gitignore files")
+	flags.BoolVar(&cfg.TreeIgnore, "treeignore", cfg.TreeIgnore, "skip entries matched by a .treeignore file at the scan root, one --exclude pattern per line (--treeignore=false to read none)")
+	flags.BoolVar(&cfg.BackgroundPriority, "background", cfg.BackgroundPriority, "scan at low CPU and I/O priority so foreground work is not disturbed")
+	flags.BoolVar(&cfg.OneFileSystem, "one-file-system", cfg.OneFileSystem, "do not read directories on other filesystems than the root, like du -x; they are marked [mount]")
+	flags.BoolVar(&cfg.FollowSymlinks, "follow-symlinks", cfg.FollowSymlinks, "descend into symbolic links to directories, reading each real directory once")
+	flags.StringVar(&cfg.Locale, "locale", cfg.Locale, "locale for the output footer, e.g. de-DE (default from LANG)")
+	flags.BoolVar(&cfg.PortableOutput, "portable", cfg.PortableOutput, "format output with the C locale for diffable exports")
+	flags.BoolVar(&cfg.OutputFooter, "footer", cfg.OutputFooter, "append directory and file counts and the scan date to text output")
+	includePatterns := flags.String("include", strings.Join(cfg.IncludePatterns, ","), "comma-separated patterns of the only files to keep, e.g. \"*.go,*.md\"")
+	excludePatterns := flags.String("exclude", strings.Join(cfg.ExcludePatterns, ","), "comma-separated patterns to leave out, e.g. \"*.log,node_modules,build/**\"")
+	flags.BoolVar(&cfg.PruneEmptyDirs, "prune-empty", cfg.PruneEmptyDirs, "leave out directories with no entries after filtering, including chains of them")
+	flags.IntVar(&cfg.MaxEntriesPerDir, "max-entries", cfg.MaxEntriesPerDir, "entries to keep per directory, noting how many more were left out (0 for no limit)")
+	fileKinds := flags.String("kinds", "", "comma-separated extension=kind overrides of the file kind table, e.g. \".proto=code,.dat=text\"; kinds are code, image, document, archive, binary, text and other")
+	languageTable := flags.String("languages", "", "comma-separated extension=language overrides of the table behind the language summary, e.g. \".tpl=Go Template,.txt=\"; an empty language leaves the files out")
+	skipPaths := flags.String("skip-paths", strings.Join(cfg.SkipPaths, ","), "comma-separated system paths never scanned, matched on whole trailing path components, or from the volume root when starting with \\ or a drive (\"\" to scan everything)")
+	alwaysShow := flags.String("always-show", strings.Join(cfg.AlwaysShowNames, ","), "comma-separated hidden names or globs kept without --hidden, e.g. \".github,.env.example\"; --exclude and .gitignore still leave them out")
+	flags.IntVar(&cfg.MaxNodes, "max-nodes", cfg.MaxNodes, "stop descending once this many entries were found, keeping the partial tree (0 for no limit)")
+	flags.DurationVar(&cfg.ScanTimeout, "timeout", cfg.ScanTimeout, "stop the scan after this long, e.g. 2m, keeping the partial tree (0 for no limit)")
+	flags.DurationVar(&cfg.DirTimeout, "dir-timeout", cfg.DirTimeout, "skip a directory whose listing takes longer than this, as on a stalled network share, recording it as unreadable (0 to wait)")
+	maxHeapMB := flags.Uint64("max-heap-mb", cfg.MaxHeapBytes>>20, "stop descending when the heap exceeds this many MiB (0 for no limit)")
+	noFolderOptions := flags.Bool("no-folder-options", false, "ignore the options saved for the directory in the GUI, which otherwise replace the defaults of these flags")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: file-tree-scanner --no-gui [flags] <directory>")
+		fmt.Fprintln(stderr, "       file-tree-scanner --doctor")
+		fmt.Fprintln(stderr, "       file-tree-scanner --validate <layout> [flags] <directory>")
+		fmt.Fprintln(stderr, "       file-tree-scanner --schedule <spec> --snapshot-dir <dir> [flags] <directory>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.doctor {
+		return opts, nil
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return nil, fmt.Errorf("expected exactly one directory, got %d", flags.NArg())
+	}
+	opts.path = flags.Arg(0)
+	opts.messages = newMessages(opts.progress, stderr)
+	cfg.MaxHeapBytes = *maxHeapMB << 20
+	cfg.IncludePatterns = scanner.ParsePatterns(*includePatterns)
+	cfg.ExcludePatterns = scanner.ParsePatterns(*excludePatterns)
+	cfg.SkipPaths = scanner.ParsePatterns(*skipPaths)
+	cfg.AlwaysShowNames = scanner.ParsePatterns(*alwaysShow)
+	kinds, err := scanner.ParseKinds(*fileKinds)
+	if err != nil {
+		return nil, err
+	}
+	cfg.FileKinds = kinds
+	languages, err := scanner.ParseLanguages(*languageTable)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Languages = languages
+	opts.useFolder = !*noFolderOptions
+	if err := scanner.ValidateSortBy(cfg.SortBy); err != nil {
+		return nil, err
+	}
+	cfg.CollectXattrs = cfg.CollectXattrs || opts.onlyXattrs
+	for _, patterns := range [][]string{cfg.IncludePatterns, cfg.ExcludePatterns} {
+		if err := scanner.ValidatePatterns(patterns); err != nil {
+			return nil, err
+		}
+	}
+
+	switch opts.progress {
+	case progressNone, progressJSON, progressBar:
+	default:
+		return nil, fmt.Errorf("unknown progress mode %q", opts.progress)
+	}
+
+	switch opts.color {
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return nil, fmt.Errorf("unknown color mode %q", opts.color)
+	}
+
+	switch cfg.SizeBasis {
+	case config.SizeApparent, config.SizeAllocated:
+	default:
+		return nil, fmt.Errorf("unknown size basis %q", cfg.SizeBasis)
+	}
+
+	if opts.maxBytes < 0 {
+		return nil, fmt.Errorf("--max-output-bytes must not be negative")
+	}
+	if opts.maxBytes > 0 && opts.format != "text" && opts.format != "outline" && opts.format != "json" {
+		return nil, fmt.Errorf("--max-output-bytes only applies to text, outline and json output")
+	}
+	if opts.indent < 1 {
+		return nil, fmt.Errorf("--indent must be at least 1")
+	}
+
+	redactor, err := renderer.NewRedactor(opts.redactions, opts.pseudonyms)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil && (opts.format == "json" || opts.format == "csv" || opts.format == "flat" || opts.format == "sqlite") {
+		return nil, fmt.Errorf("--redact only applies to text, html, opml, cards and pack output")
+	}
+	opts.redactor, opts.pack.Redactor = redactor, redactor
+
+	if *layoutPath != "" {
+		if opts.output != "" || opts.format != "text" {
+			return nil, fmt.Errorf("--validate prints violations and cannot be combined with --output or --format")
+		}
+		if opts.layout, err = readLayout(*layoutPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if *scheduleSpec != "" {
+		if opts.output != "" || opts.format != "text" || opts.layout != nil {
+			return nil, fmt.Errorf("--schedule writes JSON snapshots and cannot be combined with --output, --format or --validate")
+		}
+		if opts.snapshotDir == "" {
+			return nil, fmt.Errorf("--schedule requires --snapshot-dir")
+		}
+		if opts.keep < 0 {
+			return nil, fmt.Errorf("--keep must not be negative")
+		}
+		schedule, err := snapshot.ParseSchedule(*scheduleSpec)
+		if err != nil {
+			return nil, err
+		}
+		opts.schedule = &schedule
+	}
+
+	switch opts.format {
+	case "text", "html", "opml", "outline", "cards", "json", "pack":
+	case "sqlite":
+		if opts.output == "" {
+			return nil, fmt.Errorf("--format sqlite requires --output")
+		}
+	case "csv", "flat":
+		if opts.rowsPerFile > 0 && opts.output == "" {
+			return nil, fmt.Errorf("--rows-per-file requires --output")
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", opts.format)
+	}
+	return opts, nil
+}
+
+// savedOptions returns the options saved for the directory at path, or nil when there are none
+// or they cannot be read, which is only warned about.
+func savedOptions(path string, msgs *messages) *folderopts.Folder {
+	store, err := folderopts.DefaultStore()
+	if err == nil {
+		var folder *folderopts.Folder
+		if folder, err = store.Lookup(path); err == nil {
+			return folder
+		}
+	}
+	msgs.warnf("ignoring saved folder options: %v", err)
+	return nil
+}
+
+// writeSummary prints what was scanned and written, for --verbose.
+func writeSummary(w io.Writer, result *scanner.ScanResult, elapsed time.Duration, written renderer.OutputStats, opts *options) {
+	f := locale.New(opts.config.Locale)
+	if opts.folder != nil {
+		fmt.Fprintf(w, "Used saved options for this folder: %s\n", opts.folder.Options.Summary())
+	}
+	fmt.Fprintf(w, "Scanned %s items (%s directories, %s files) in %s\n",
+		f.Int(result.NodeCount), f.Int(result.DirCount), f.Int(result.FileCount), elapsed.Round(time.Millisecond))
+	if skipped := result.Skipped[scanner.SystemPathRule]; skipped > 0 {
+		fmt.Fprintf(w, "Skipped %s system paths\n", f.Int(skipped))
+	}
+	if result.TruncatedCount > 0 {
+		fmt.Fprintf(w, "%s directories not shown in full, marked with a … line\n", f.Int(result.TruncatedCount))
+	}
+	if result.HasLines {
+		fmt.Fprintf(w, "Counted %s lines of text\n", f.Int(result.TotalLines))
+	}
+	if result.HasTokens {
+		fmt.Fprintf(w, "Estimated %s\n", renderer.TokensLabel(result.TotalTokens, f))
+	}
+	if len(result.Languages) > 0 {
+		fmt.Fprintf(w, "Languages by %s: %s\n", result.LanguagesBy, scanner.FormatLanguages(result.Languages))
+	}
+	if files, size := result.DuplicateWaste(); files > 0 {
+		fmt.Fprintf(w, "Found %s duplicate files in %s groups", f.Int(files), f.Int(len(result.Duplicates)))
+		if result.HasSizes {
+			fmt.Fprintf(w, " wasting %s", f.Size(size))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, group := range result.DuplicateDirs {
+		fmt.Fprintf(w, "Copied directories of %s entries", f.Int(group.Items))
+		if result.HasSizes {
+			fmt.Fprintf(w, ", %s each", f.Size(group.Size))
+		}
+		fmt.Fprintln(w, ":")
+		for _, dir := range group.Dirs {
+			fmt.Fprintf(w, "  %s\n", dir.Path)
+		}
+	}
+	for _, scanErr := range result.Errors {
+		fmt.Fprintf(w, "Could not %s %s: %v\n", scanErr.Op, scanErr.Path, scanErr.Err)
+	}
+	target := "stdout"
+	if opts.output != "" {
+		target = opts.output
+	}
+	if written.Lines == 0 {
+		fmt.Fprintf(w, "Wrote %s to %s\n", f.Size(written.Bytes), target)
+		return
+	}
+	fmt.Fprintf(w, "Wrote %s (%s lines) to %s\n", f.Size(written.Bytes), f.Int(written.Lines), target)
+}
+
+// writeResult renders or exports the scan result in the requested format, returning what was
+// written. Output exported by path reports only its size, and nothing for a directory of parts.
+func writeResult(ctx context.Context, result *scanner.ScanResult, opts *options, stdout io.Writer) (stats renderer.OutputStats, err error) {
+	out := renderer.NewCountingWriter(stdout)
+	switch opts.format {
+	case "sqlite":
+		return exportedStats(opts.output, (&exporter.SQLiteExporter{}).Export(ctx, result, opts.output))
+	case "pack":
+		if opts.output != "" {
+			return exportedStats(opts.output, (&contextpack.Exporter{Options: opts.pack}).Export(ctx, result, opts.output))
+		}
+		err = contextpack.Write(ctx, out, result, opts.pack)
+		return out.Stats(), err
+	case "csv", "flat":
+		var fileExporter exporter.FileExporter = &exporter.CSVExporter{RowsPerFile: opts.rowsPerFile}
+		if opts.format == "flat" {
+			fileExporter = &exporter.FlatExporter{RowsPerFile: opts.rowsPerFile}
+		}
+		if opts.output != "" {
+			return exportedStats(opts.output, fileExporter.Export(ctx, result, opts.output))
+		}
+		err = exportToStdout(ctx, fileExporter, result, out)
+		return out.Stats(), err
+	}
+
+	renderOpts := renderer.DefaultOptions()
+	renderOpts.HideExportIgnored = opts.hideIgnored
+	renderOpts.QuoteNames = opts.quoteNames
+	renderOpts.MarkUnreadable = opts.unreadable
+	renderOpts.OnlyXattrs = opts.onlyXattrs
+	if opts.kindIcons {
+		renderOpts.KindIcons = renderer.DefaultKindIcons()
+	}
+	renderOpts.IncludePatterns = result.IncludePatterns
+	renderOpts.ExcludePatterns = result.ExcludePatterns
+	renderOpts.ShowSizes = result.HasSizes
+	renderOpts.ShowTimes = result.HasTimes
+	renderOpts.ShowModes = result.HasModes
+	renderOpts.ShowTokens = result.HasTokens
+	renderOpts.SizeBasis = opts.config.SizeBasis
+	renderOpts.MaxBytes = opts.maxBytes
+	renderOpts.OutlineIndent = renderer.OutlineIndent(opts.indent, opts.indentTabs)
+	renderOpts.Redactor = opts.redactor
+	renderOpts.Style = renderer.AnnotationStyle{Symbols: opts.symbols}
+	if opts.config.OutputFooter && opts.format == "text" {
+		// Part of the rendered text, so it counts towards --max-output-bytes
+		renderOpts.Footer = renderer.Footer(result, outputFormatter(opts.config))
+	}
+	var treeRenderer renderer.TreeRenderer = renderer.NewStandardTreeRenderer(renderOpts)
+	switch {
+	case opts.format == "html":
+		treeRenderer = renderer.NewHTMLTreeRenderer(renderOpts, opts.depthColors)
+	case opts.format == "opml":
+		treeRenderer = renderer.NewOPMLTreeRenderer(renderOpts)
+	case opts.format == "outline":
+		treeRenderer = renderer.NewOutlineRenderer(renderOpts)
+	case opts.format == "cards":
+		treeRenderer = renderer.NewCardsRenderer(renderOpts, nil)
+	case opts.config.MarkExecutables:
+		treeRenderer = renderer.NewANSITreeRenderer(renderOpts)
+	}
+
+	if opts.output != "" {
+		file, ferr := os.Create(opts.output)
+		if ferr != nil {
+			return renderer.OutputStats{}, ferr
+		}
+		out = renderer.NewCountingWriter(file)
+		defer func() {
+			if cerr := file.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}()
+	}
+
+	if opts.format == "json" {
+		return writeJSON(out, result, opts.maxBytes)
+	}
+
+	// Plain, colored and outline text is written line by line; the other formats are rendered whole
+	if streamer, ok := treeRenderer.(renderer.TreeWriter); ok {
+		err = streamer.WriteTree(out, result.Root)
+		return out.Stats(), err
+	}
+	out.WriteString(treeRenderer.RenderTree(result.Root))
+	return out.Stats(), out.Err()
+}
+
+// writeJSON writes the tree document of result, leaving out entries to fit maxBytes when it is
+// set. Cut documents are reported with an *renderer.OutputLimitError.
+func writeJSON(out *renderer.CountingWriter, result *scanner.ScanResult, maxBytes int64) (renderer.OutputStats, error) {
+	doc := report.NewTreeDocument(result)
+	if maxBytes > 0 {
+		if doc = report.LimitTree(doc, maxBytes); doc == nil {
+			return renderer.OutputStats{}, fmt.Errorf("--max-output-bytes %d is too small for the root of the tree", maxBytes)
+		}
+	}
+	if err := report.WriteTree(out, doc); err != nil {
+		return out.Stats(), err
+	}
+	if doc.ItemsOmitted > 0 {
+		return out.Stats(), &renderer.OutputLimitError{Limit: maxBytes, Omitted: doc.ItemsOmitted}
+	}
+	return out.Stats(), nil
+}
+
+// exportedStats returns the size of a file written by an exporter that failed with err, if any.
+func exportedStats(path string, err error) (renderer.OutputStats, error) {
+	if err != nil {
+		return renderer.OutputStats{}, err
+	}
+	var stats renderer.OutputStats
+	if info, serr := os.Stat(path); serr == nil && info.Mode().IsRegular() {
+		stats.Bytes = info.Size()
+	}
+	return stats, nil
+}
+
+// outputFormatter returns the formatter for rendered output.
+func outputFormatter(cfg *config.Config) *locale.Formatter {
+	if cfg.PortableOutput {
+		return locale.New(locale.Portable)
+	}
+	return locale.New(cfg.Locale)
+}
+
+// readLayout reads the layout file at path.
+func readLayout(path string) (*layout.Layout, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open layout: %w", err)
+	}
+	defer file.Close()
+	l, err := layout.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// runValidate prints the violations of l in result, one per line, and returns ExitLayout if
+// there are any. A tree that matches but was cut short returns ExitTruncated.
+func runValidate(result *scanner.ScanResult, l *layout.Layout, stdout io.Writer, msgs *messages) int {
+	violations := layout.Check(result.Root, l)
+	for _, violation := range violations {
+		fmt.Fprintln(stdout, violation)
+	}
+	if len(violations) > 0 {
+		msgs.infof("%s does not match the layout, violations: %d", result.RootPath, len(violations))
+		return ExitLayout
+	}
+	if result.Truncated {
+		msgs.warnf("%s, the layout was checked against part of the tree", result.TruncatedReason.Message(result.TruncatedLimit))
+		return ExitTruncated
+	}
+	msgs.infof("%s matches the layout", result.RootPath)
+	return ExitOK
+}
+
+// runDoctor runs the environment checks, failing if any check fails.
+func runDoctor(ctx context.Context, stdout io.Writer) int {
+	results := diagnose.Run(ctx)
+	if err := diagnose.Write(stdout, results); err != nil {
+		return ExitFailure
+	}
+	if diagnose.Worst(results) == diagnose.Fail {
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// exportToStdout runs a file exporter into a temporary file and copies it to stdout.
+func exportToStdout(ctx context.Context, fileExporter exporter.FileExporter, result *scanner.ScanResult, stdout io.Writer) error {
+	dir, err := os.MkdirTemp("", "file-tree-scanner-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "output")
+	if err := fileExporter.Export(ctx, result, path); err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(stdout, file)
+	return err
+}
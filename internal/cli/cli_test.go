@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/contextpack"
+	"github.com/Akaiko1/file-tree-scanner/internal/folderopts"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+)
+
+// runCLI runs the command line with args after --no-gui, returning its output and exit code.
+func runCLI(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = Run(append([]string{"--" + noGUIFlag}, args...), &out, &errOut)
+	return out.String(), errOut.String(), code
+}
+
+// writeTestZip writes a zip archive holding names to dir.
+func writeTestZip(t *testing.T, dir string, names ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, "listing.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	w := zip.NewWriter(file)
+	for _, name := range names {
+		if _, err := w.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestArchive(t *testing.T) {
+	path := writeTestZip(t, t.TempDir(), "docs/guide.md", "main.go")
+	stdout, stderr, code := runCLI(t, path)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	for _, name := range []string{"docs", "guide.md", "main.go"} {
+		if !strings.Contains(stdout, name) {
+			t.Errorf("output lacks %s:\n%s", name, stdout)
+		}
+	}
+}
+
+func TestArchiveTimeout(t *testing.T) {
+	path := writeTestZip(t, t.TempDir(), "docs/guide.md", "main.go")
+	stdout, stderr, code := runCLI(t, "--timeout", "1ns", path)
+	if code != ExitTruncated {
+		t.Fatalf("exit code %d, want %d for a partial listing: %s", code, ExitTruncated, stderr)
+	}
+	if !strings.Contains(stdout, "listing.zip") {
+		t.Errorf("partial tree not written:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, "output is partial") {
+		t.Errorf("no warning about the partial output:\n%s", stderr)
+	}
+}
+
+func TestFormatOPML(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	stdout, stderr, code := runCLI(t, "--format", "opml", root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	var doc struct {
+		Version  string `xml:"version,attr"`
+		Title    string `xml:"head>title"`
+		Outlines []struct {
+			Text string `xml:"text,attr"`
+			Kind string `xml:"kind,attr"`
+		} `xml:"body>outline"`
+	}
+	if err := xml.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("output is not well-formed: %v\n%s", err, stdout)
+	}
+	if doc.Version != "2.0" || doc.Title != root || len(doc.Outlines) != 4 {
+		t.Errorf("version %q, title %q, %d top-level outlines; want 2.0, %q and 4:\n%s", doc.Version, doc.Title, len(doc.Outlines), root, stdout)
+	}
+}
+
+func TestFormatJSONRoundTrip(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	stdout, stderr, code := runCLI(t, "--format", "json", "--sizes", root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	doc, err := report.ReadTree(strings.NewReader(stdout))
+	if err != nil {
+		t.Fatalf("output is not a tree file: %v\n%s", err, stdout)
+	}
+	result, err := doc.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RootPath != root || result.FileCount != 4 || !result.HasSizes {
+		t.Errorf("read back %q with %d files, sizes %v; want %q with 4 files and sizes", result.RootPath, result.FileCount, result.HasSizes, root)
+	}
+	var again bytes.Buffer
+	if err := report.WriteTree(&again, report.NewTreeDocument(result)); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != stdout {
+		t.Errorf("export → import → export changed the file:\n%s\nwant:\n%s", again.String(), stdout)
+	}
+}
+
+func TestPackDedup(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	flat, stderr, code := runCLI(t, "--format", "pack", root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	deduplicated, stderr, code := runCLI(t, "--format", "pack", "--dedup", root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d with --dedup: %s", code, stderr)
+	}
+	if !strings.Contains(deduplicated, "## Manifest") || strings.Contains(deduplicated, "## Files") {
+		t.Fatalf("--dedup did not write a deduplicated pack:\n%s", deduplicated)
+	}
+
+	var unpacked bytes.Buffer
+	if err := contextpack.Unpack(strings.NewReader(deduplicated), &unpacked); err != nil {
+		t.Fatal(err)
+	}
+	if unpacked.String() != flat {
+		t.Errorf("unpacked --dedup output differs from the flat pack\n--- unpacked\n%s\n--- flat\n%s", unpacked.String(), flat)
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the configuration directory is only moved through XDG_CONFIG_HOME on Linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	stdout, stderr, code := runCLI(t, "--doctor")
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s%s", code, stdout, stderr)
+	}
+	for _, check := range []string{"Display", "Clipboard", "Config directory", "] Sample scan", "Watch backend", "Long paths", "Capabilities"} {
+		if !strings.Contains(stdout, check) {
+			t.Errorf("report lacks %s:\n%s", check, stdout)
+		}
+	}
+
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", blocked)
+	stdout, _, code = runCLI(t, "--doctor")
+	if code != ExitFailure || !strings.Contains(stdout, "[FAIL] Config directory") {
+		t.Errorf("exit code %d with a failing check, want %d:\n%s", code, ExitFailure, stdout)
+	}
+}
+
+func TestFolderOptionsUnderFlags(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the configuration directory is only moved through XDG_CONFIG_HOME on Linux")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := writeTestTree(t, t.TempDir())
+	store, err := folderopts.DefaultStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	depth := 1
+	sizes := true
+	saved := folderopts.Options{
+		MaxDepth: &depth,
+		ShowSize: &sizes,
+		Exclude:  &folderopts.Patterns{Mode: folderopts.Append, Patterns: []string{"docs"}},
+	}
+	if err := store.Save(root, saved); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		depth   int
+		sizes   bool
+		exclude []string
+		folder  bool
+	}{
+		{"saved options apply", nil, 1, true, []string{"docs"}, true},
+		{"explicit flags win", []string{"--max-depth=4", "--sizes=false", "--exclude=*.md"}, 4, false, []string{"*.md"}, true},
+		{"ignored on request", []string{"--no-folder-options"}, config.DefaultConfig().MaxDepth, config.DefaultConfig().ShowSize, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseArgs(append(tt.args, root), io.Discard)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := opts.config
+			if cfg.MaxDepth != tt.depth || cfg.ShowSize != tt.sizes || !slices.Equal(cfg.ExcludePatterns, tt.exclude) {
+				t.Errorf("depth %d, sizes %v, exclude %q; want %d, %v, %q", cfg.MaxDepth, cfg.ShowSize, cfg.ExcludePatterns, tt.depth, tt.sizes, tt.exclude)
+			}
+			if (opts.folder != nil) != tt.folder {
+				t.Errorf("folder options reported %v, want %v", opts.folder != nil, tt.folder)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"io"
+	"os"
+)
+
+// Values of the --color flag.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// useColor decides whether text written to w gets ANSI colors. In auto mode colors are
+// used only for a terminal, and never when NO_COLOR is set or TERM is "dumb".
+func useColor(mode string, w io.Writer) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
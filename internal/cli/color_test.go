@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	if err := os.Chmod(filepath.Join(root, "go.mod"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		mode    string
+		colored bool
+	}{
+		{"always", true},
+		{"never", false},
+		{"auto", false}, // Not a terminal
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			stdout, stderr, code := runCLI(t, "--color="+tt.mode, root)
+			if code != ExitOK {
+				t.Fatalf("exit code %d: %s", code, stderr)
+			}
+			if got := strings.Contains(stdout, "\x1b["); got != tt.colored {
+				t.Errorf("colored %v, want %v:\n%q", got, tt.colored, stdout)
+			}
+			if tt.colored && (!strings.Contains(stdout, "📁 \x1b[1;34mcmd/\x1b[0m") || !strings.Contains(stdout, "\x1b[32mgo.mod\x1b[0m")) {
+				t.Errorf("directories not bold blue or executables not green:\n%q", stdout)
+			}
+		})
+	}
+}
+
+func TestColorNeverInFiles(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	for _, name := range []string{"tree.txt", "tree.html"} {
+		output := filepath.Join(t.TempDir(), name)
+		if _, stderr, code := runCLI(t, "--color=always", "--output", output, root); code != ExitOK {
+			t.Fatalf("exit code %d: %s", code, stderr)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "\x1b") {
+			t.Errorf("%s holds escape sequences", name)
+		}
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if stdout, _, _ := runCLI(t, "--color=always", root); !strings.Contains(stdout, "\x1b[") {
+		t.Error("NO_COLOR overrode --color=always")
+	}
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFooterLocale(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	tests := []struct {
+		name string
+		args []string
+		date *regexp.Regexp
+	}{
+		{"en", []string{"--locale", "en-US"}, regexp.MustCompile(`Scanned on [A-Z][a-z]{2} \d{1,2}, \d{4} \d{2}:\d{2}\n`)},
+		{"de", []string{"--locale", "de-DE"}, regexp.MustCompile(`Scanned on \d{2}\.\d{2}\.\d{4} \d{2}:\d{2}\n`)},
+		{"fr", []string{"--locale", "fr-FR"}, regexp.MustCompile(`Scanned on \d{2}/\d{2}/\d{4} \d{2}:\d{2}\n`)},
+		{"portable overrides the locale", []string{"--locale", "de-DE", "--portable"}, regexp.MustCompile(`Scanned on \d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z\n`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runCLI(t, append(append([]string{"--footer"}, tt.args...), root)...)
+			if code != ExitOK {
+				t.Fatalf("exit code %d: %s", code, stderr)
+			}
+			if !regexp.MustCompile(`\n3 directories, 4 files\n`).MatchString(stdout) || !tt.date.MatchString(stdout) {
+				t.Errorf("footer not formatted for %s:\n%s", tt.name, stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+)
+
+// truncatedLine matches the line ending output cut by --max-output-bytes.
+var truncatedLine = regexp.MustCompile(`^#TRUNCATED items_omitted=(\d+)$`)
+
+// outputHeaderLines is the number of lines text output starts with before the first entry.
+const outputHeaderLines = 3
+
+// entryLines returns the number of entry lines in text output starting with the header.
+func entryLines(text string) int {
+	return max(strings.Count(text, "\n")-outputHeaderLines, 0)
+}
+
+func TestMaxOutputBytesText(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	full, stderr, code := runCLI(t, root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	entries := entryLines(full)
+
+	if out, _, code := runCLI(t, fmt.Sprintf("--max-output-bytes=%d", len(full)), root); code != ExitOK || out != full {
+		t.Errorf("output that fits exactly was changed, exit code %d:\n%s", code, out)
+	}
+
+	for limit := len(truncationMarkerFor(entries)); limit < len(full); limit += 5 {
+		out, stderr, code := runCLI(t, fmt.Sprintf("--max-output-bytes=%d", limit), root)
+		if code != ExitOutputCut {
+			t.Fatalf("limit %d: exit code %d, want %d: %s", limit, code, ExitOutputCut, stderr)
+		}
+		if len(out) > limit {
+			t.Errorf("limit %d: wrote %d bytes", limit, len(out))
+		}
+
+		// Whole lines of the full output, then the marker line
+		body, marker, ok := cutLastLine(out)
+		if !ok || !strings.HasPrefix(full, body) || (body != "" && !strings.HasSuffix(body, "\n")) {
+			t.Fatalf("limit %d: output is not whole lines of the full output and a marker:\n%s", limit, out)
+		}
+		match := truncatedLine.FindStringSubmatch(marker)
+		if match == nil {
+			t.Fatalf("limit %d: last line %q is not the truncation marker", limit, marker)
+		}
+		omitted, _ := strconv.Atoi(match[1])
+		if want := entries - entryLines(body); omitted != want {
+			t.Errorf("limit %d: marker counts %d omitted items, want %d", limit, omitted, want)
+		}
+		if want := fmt.Sprintf("%d items omitted", omitted); !strings.Contains(stderr, want) {
+			t.Errorf("limit %d: warning %q does not say %q", limit, stderr, want)
+		}
+	}
+}
+
+// truncationMarkerFor returns the marker line for omitted items.
+func truncationMarkerFor(omitted int) string {
+	return fmt.Sprintf("#TRUNCATED items_omitted=%d\n", omitted)
+}
+
+// cutLastLine splits text ending in a newline into its last line and what comes before it.
+func cutLastLine(text string) (before, last string, ok bool) {
+	text, ok = strings.CutSuffix(text, "\n")
+	if !ok {
+		return "", "", false
+	}
+	at := strings.LastIndexByte(text, '\n')
+	return text[:at+1], text[at+1:], true
+}
+
+func TestMaxOutputBytesJSON(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	full, stderr, code := runCLI(t, "--format=json", root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+
+	cut := 0
+	for limit := len(full) - 40; ; limit -= 40 { // Below the full size, whose timestamp varies in length
+		out, stderr, code := runCLI(t, "--format=json", fmt.Sprintf("--max-output-bytes=%d", limit), root)
+		if code == ExitFailure && strings.Contains(stderr, "too small for the root") {
+			break
+		}
+		if code != ExitOutputCut {
+			t.Fatalf("limit %d: exit code %d, want %d: %s", limit, code, ExitOutputCut, stderr)
+		}
+		cut++
+		if len(out) > limit {
+			t.Errorf("limit %d: wrote %d bytes", limit, len(out))
+		}
+		var doc report.TreeDocument
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatalf("limit %d: output is not valid JSON: %v\n%s", limit, err, out)
+		}
+		if doc.ItemsOmitted == 0 {
+			t.Errorf("limit %d: cut document has no items_omitted", limit)
+		}
+		if want := fmt.Sprintf("%d items omitted", doc.ItemsOmitted); !strings.Contains(stderr, want) {
+			t.Errorf("limit %d: warning %q does not say %q", limit, stderr, want)
+		}
+	}
+	if cut == 0 {
+		t.Error("no limit cut the document")
+	}
+}
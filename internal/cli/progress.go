@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Progress output modes accepted by --progress.
+const (
+	progressNone = ""
+	progressJSON = "json"
+	progressBar  = "bar"
+)
+
+// Layout of the terminal bar.
+const (
+	barCurrentWidth = 48 // Characters of the current path shown
+	barMeterWidth   = 20 // Cells of the meter shown against an estimate
+)
+
+// progressLine is one JSON progress record.
+type progressLine struct {
+	Items     int    `json:"items"`
+	Current   string `json:"current,omitempty"`
+	ElapsedMS int64  `json:"elapsed_ms"`
+	Done      bool   `json:"done,omitempty"`
+}
+
+// messageLine is one JSON record of an error, warning or summary line, written in place of the
+// plain line while JSON progress owns stderr.
+type messageLine struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// Levels of message records.
+const (
+	levelError   = "error"
+	levelWarning = "warning"
+	levelInfo    = "info"
+)
+
+// messages writes errors, warnings and summary lines to stderr: as plain lines, or as JSON
+// records when JSON progress owns the stream, so that every line of it parses.
+type messages struct {
+	w    io.Writer
+	json bool
+}
+
+// newMessages returns the messages for stderr w in the given progress mode.
+func newMessages(mode string, w io.Writer) *messages {
+	return &messages{w: w, json: mode == progressJSON}
+}
+
+// error writes err, prefixed with "Error:" as a plain line.
+func (m *messages) error(err error) {
+	m.write(levelError, "Error: ", err.Error())
+}
+
+// warnf writes a warning, prefixed with "Warning:" as a plain line.
+func (m *messages) warnf(format string, args ...any) {
+	m.write(levelWarning, "Warning: ", fmt.Sprintf(format, args...))
+}
+
+// infof writes an informational line.
+func (m *messages) infof(format string, args ...any) {
+	m.write(levelInfo, "", fmt.Sprintf(format, args...))
+}
+
+// write writes message as a record of level, or as a plain line after prefix.
+func (m *messages) write(level, prefix, message string) {
+	if m.json {
+		json.NewEncoder(m.w).Encode(messageLine{Level: level, Message: message})
+		return
+	}
+	fmt.Fprintln(m.w, prefix+message)
+}
+
+// lines returns a writer passing each line written to it on as an informational message, for
+// output written line by line in pieces like the --verbose summary.
+func (m *messages) lines() io.Writer {
+	if !m.json {
+		return m.w
+	}
+	return &lineWriter{m: m}
+}
+
+// lineWriter turns the complete lines written to it into info records.
+type lineWriter struct {
+	m       *messages
+	pending []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		end := bytes.IndexByte(w.pending, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		w.m.write(levelInfo, "", string(w.pending[:end]))
+		w.pending = w.pending[end+1:]
+	}
+}
+
+// newProgressFunc returns a progress callback writing to w in the given mode, or nil for no progress.
+// The bar measures progress against estimate, the expected number of items, when it is positive.
+func newProgressFunc(mode string, w io.Writer, estimate int) scanner.ProgressFunc {
+	switch mode {
+	case progressJSON:
+		encoder := json.NewEncoder(w)
+		return func(p scanner.ScanProgress) {
+			encoder.Encode(progressLine{
+				Items:     p.Items,
+				Current:   p.Current,
+				ElapsedMS: p.Elapsed.Milliseconds(),
+				Done:      p.Done,
+			})
+		}
+	case progressBar:
+		bar := &terminalBar{w: w, estimate: estimate}
+		return bar.update
+	}
+	return nil
+}
+
+// terminalBar draws a single, continuously rewritten status line.
+type terminalBar struct {
+	w        io.Writer
+	estimate int // Expected number of items; 0 if unknown
+	frame    int
+	width    int
+}
+
+// spinnerFrames animate the bar while the total is unknown or exceeded.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// update redraws the bar; the final report clears it and prints a summary line.
+func (b *terminalBar) update(p scanner.ScanProgress) {
+	if p.Done {
+		b.clear()
+		fmt.Fprintf(b.w, "Scanned %d items in %s\n", p.Items, p.Elapsed.Round(time.Millisecond))
+		return
+	}
+
+	current := []rune(p.Current)
+	if len(current) > barCurrentWidth {
+		current = append([]rune("…"), current[len(current)-barCurrentWidth:]...)
+	}
+	var line string
+	if b.estimate > 0 && p.Items < b.estimate {
+		filled := p.Items * barMeterWidth / b.estimate
+		line = fmt.Sprintf("[%s%s] %3d%% %d/~%d items  %s  %s", strings.Repeat("#", filled), strings.Repeat(".", barMeterWidth-filled),
+			p.Items*100/b.estimate, p.Items, b.estimate, p.Elapsed.Round(100*time.Millisecond), string(current))
+	} else {
+		// The estimate is a lower bound; past it the total is unknown again
+		line = fmt.Sprintf("%s %d items  %s  %s", spinnerFrames[b.frame%len(spinnerFrames)], p.Items, p.Elapsed.Round(100*time.Millisecond), string(current))
+		b.frame++
+	}
+
+	b.clear()
+	fmt.Fprint(b.w, line)
+	b.width = len([]rune(line))
+}
+
+// clear erases the previously drawn line.
+func (b *terminalBar) clear() {
+	if b.width > 0 {
+		fmt.Fprint(b.w, "\r"+strings.Repeat(" ", b.width)+"\r")
+		b.width = 0
+	}
+}
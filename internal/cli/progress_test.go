@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// writeTestTree creates a folder of three directories and four files in dir.
+func writeTestTree(t *testing.T, dir string) string {
+	t.Helper()
+	root := filepath.Join(dir, "project")
+	for _, name := range []string{"cmd/app/main.go", "docs/guide.md", "README.md", "go.mod"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestProgressJSON(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	stdout, stderr, code := runCLI(t, "--progress=json", root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if strings.Contains(stdout, "elapsed_ms") || !strings.Contains(stdout, "main.go") {
+		t.Errorf("stdout holds progress or lacks the tree:\n%s", stdout)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stderr, "\n"), "\n")
+	var last progressLine
+	for i, line := range lines {
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.DisallowUnknownFields()
+		var record progressLine
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("line %d is not a progress record: %q: %v", i+1, line, err)
+		}
+		if record.Items < last.Items || record.ElapsedMS < last.ElapsedMS {
+			t.Errorf("line %d went backwards: %+v after %+v", i+1, record, last)
+		}
+		if record.Done != (i == len(lines)-1) {
+			t.Errorf("line %d: done %v, want it only on the last line", i+1, record.Done)
+		}
+		last = record
+	}
+	// The root, three directories and four files
+	if last.Items != 8 {
+		t.Errorf("final report counts %d items, want 8", last.Items)
+	}
+}
+
+func TestProgressJSONMessages(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	// Output cut short warns, and --verbose adds the summary
+	_, stderr, code := runCLI(t, "--progress=json", "--verbose", "--max-output-bytes=20", root)
+	if code != ExitOutputCut {
+		t.Fatalf("exit code %d, want %d: %s", code, ExitOutputCut, stderr)
+	}
+
+	var warnings, infos, progress int
+	for i, line := range strings.Split(strings.TrimSuffix(stderr, "\n"), "\n") {
+		var record struct {
+			progressLine
+			messageLine
+		}
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("line %d is not a JSON record: %q: %v", i+1, line, err)
+		}
+		switch record.Level {
+		case levelWarning:
+			warnings++
+			if strings.HasPrefix(record.Message, "Warning") {
+				t.Errorf("warning record keeps its plain prefix: %q", record.Message)
+			}
+		case levelInfo:
+			infos++
+		case "":
+			progress++
+		default:
+			t.Errorf("line %d has level %q", i+1, record.Level)
+		}
+	}
+	if warnings != 1 || infos == 0 || progress == 0 {
+		t.Errorf("%d warnings, %d summary lines and %d progress records, want 1 and some of each:\n%s", warnings, infos, progress, stderr)
+	}
+}
+
+func TestMessagesPlain(t *testing.T) {
+	var out bytes.Buffer
+	m := newMessages(progressNone, &out)
+	m.error(errors.New("boom"))
+	m.warnf("%d left", 3)
+	fmt.Fprintf(m.lines(), "Scanned %d", 8)
+	fmt.Fprintln(m.lines(), " items")
+	if want := "Error: boom\nWarning: 3 left\nScanned 8 items\n"; out.String() != want {
+		t.Errorf("wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	stdout, stderr, code := runCLI(t, "--progress=bar", root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "main.go") || strings.Contains(stdout, "Scanned") {
+		t.Errorf("stdout holds progress or lacks the tree:\n%s", stdout)
+	}
+	if !strings.HasSuffix(stderr, "\n") || !strings.Contains(stderr, "Scanned 8 items in ") {
+		t.Errorf("no summary line on stderr: %q", stderr)
+	}
+}
+
+func TestProgressMode(t *testing.T) {
+	if _, stderr, code := runCLI(t, "--progress=dots", t.TempDir()); code != ExitUsage {
+		t.Errorf("exit code %d for an unknown mode, want %d: %s", code, ExitUsage, stderr)
+	}
+	if newProgressFunc(progressNone, &bytes.Buffer{}, 0) != nil {
+		t.Error("a callback was made without a progress mode")
+	}
+}
+
+func TestTerminalBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := newProgressFunc(progressBar, &out, 10)
+
+	bar(scanner.ScanProgress{Items: 5, Current: "/src", Elapsed: time.Second})
+	if got := out.String(); !strings.HasPrefix(got, "[##########..........]  50% 5/~10 items  1s  /src") {
+		t.Errorf("bar against the estimate drawn as %q", got)
+	}
+
+	// Past the estimate the total is unknown again
+	out.Reset()
+	bar(scanner.ScanProgress{Items: 12, Current: "/" + strings.Repeat("d", 60), Elapsed: 2 * time.Second})
+	drawn := out.String()
+	if !strings.Contains(drawn, spinnerFrames[0]+" 12 items  2s  …"+strings.Repeat("d", barCurrentWidth)) {
+		t.Errorf("bar past the estimate drawn as %q", drawn)
+	}
+	if !strings.HasPrefix(drawn, "\r"+strings.Repeat(" ", 49)+"\r") {
+		t.Errorf("previous line not cleared first: %q", drawn)
+	}
+
+	out.Reset()
+	bar(scanner.ScanProgress{Items: 14, Elapsed: 3 * time.Second, Done: true})
+	if got := out.String(); !strings.HasSuffix(got, "\rScanned 14 items in 3s\n") {
+		t.Errorf("final report drawn as %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/snapshot"
+)
+
+// runLogLayout starts each line --schedule logs for a run.
+const runLogLayout = "2006-01-02 15:04:05"
+
+// runSchedule writes snapshots of the directory on its schedule until interrupted, logging one
+// line per run. The schedule picks up from the newest snapshot already in the directory, so a
+// restart does not scan again before the next run is due; a failed run waits for the next one.
+func runSchedule(ctx context.Context, opts *options, stdout, stderr io.Writer) int {
+	last, err := snapshot.Latest(opts.snapshotDir)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return ExitFailure
+	}
+	fmt.Fprintf(stderr, "Taking snapshots of %s %s into %s until interrupted\n", opts.path, opts.schedule, opts.snapshotDir)
+
+	f := locale.New(opts.config.Locale)
+	for {
+		if err := snapshot.Wait(ctx, *opts.schedule, last); err != nil {
+			return ExitOK // Interrupted
+		}
+		last = time.Now()
+		summary, err := takeSnapshot(ctx, opts, last, f)
+		if ctx.Err() != nil {
+			return ExitOK // Interrupted mid-scan; nothing was written
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s Error: %v\n", last.Format(runLogLayout), err)
+			continue
+		}
+		fmt.Fprintf(stdout, "%s %s\n", last.Format(runLogLayout), summary)
+	}
+}
+
+// takeSnapshot scans the directory, writes its snapshot taken at taken and deletes snapshots
+// beyond --keep, returning a summary of the run.
+func takeSnapshot(ctx context.Context, opts *options, taken time.Time, f *locale.Formatter) (string, error) {
+	started := time.Now()
+	result, err := scanner.NewFileTreeScanner(opts.config).ScanDirectory(ctx, opts.path)
+	if err != nil {
+		return "", err
+	}
+	doc := report.NewTreeDocument(result)
+	doc.Options = opts.config
+	saved, err := snapshot.Write(opts.snapshotDir, doc, taken)
+	if err != nil {
+		return "", err
+	}
+
+	summary := fmt.Sprintf("%s: %s directories, %s files", saved.Name, f.Int(result.DirCount), f.Int(result.FileCount))
+	if result.HasSizes {
+		summary += ", " + f.Size(result.TotalSize)
+	}
+	summary += " in " + time.Since(started).Round(time.Millisecond).String()
+	if result.Truncated {
+		summary += " (partial: " + result.TruncatedReason.Message(result.TruncatedLimit) + ")"
+	}
+	removed, err := snapshot.Prune(opts.snapshotDir, opts.keep)
+	if len(removed) > 0 {
+		summary += fmt.Sprintf("; removed %s old", f.Int(len(removed)))
+	}
+	if err != nil {
+		summary += "; " + err.Error()
+	}
+	return summary, nil
+}
@@ -0,0 +1,43 @@
+//go:build linux
+
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestOnlyXattrs(t *testing.T) {
+	root := writeTestTree(t, t.TempDir())
+	tagged := filepath.Join(root, "docs", "guide.md")
+	if err := unix.Setxattr(tagged, "user.origin", []byte("download"), 0); err != nil {
+		if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EPERM) {
+			t.Skipf("the file system keeps no user attributes: %v", err)
+		}
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runCLI(t, "--only-xattrs", root)
+	if code != ExitOK {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	// The entry with attributes and the directory leading to it, nothing else
+	for _, want := range []string{"docs/", "guide.md"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output lacks %s:\n%s", want, stdout)
+		}
+	}
+	for _, unwanted := range []string{"cmd/", "main.go", "README.md", "go.mod", "empty/"} {
+		if strings.Contains(stdout, unwanted) {
+			t.Errorf("output holds %s, which has no attributes:\n%s", unwanted, stdout)
+		}
+	}
+}
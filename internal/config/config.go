@@ -1,12 +1,108 @@
 package config
 
+import (
+	"maps"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// Size bases for totals and size-based views.
+const (
+	SizeApparent  = "apparent"  // Logical file length
+	SizeAllocated = "allocated" // Bytes allocated on disk (sparse/compressed aware)
+)
+
+// Sort keys for the entries of each directory.
+const (
+	SortByName      = "name"
+	SortBySize      = "size"      // Directories by their total once sizes are collected, by name otherwise
+	SortByModTime   = "time"      // Needs CollectTimes to tell entries apart
+	SortByExtension = "extension" // Files by lower-case extension; directories by name
+)
+
 // Config defines configuration parameters for directory scanning behavior and UI settings.
 type Config struct {
-	MaxDepth      int
-	ShowHidden    bool
-	SortDirs      bool
-	ShowSize      bool
-	ConcurrentOps int
+	MaxDepth        int
+	ShowHidden      bool
+	SortDirs        bool
+	NaturalSort     bool   // Sort names with numbers by value, ignoring case, so "file2" comes before "file10"
+	SortBy          string // SortByName, SortBySize, SortByModTime or SortByExtension; ties go by name
+	SortDescending  bool   // Reverse the SortBy key; directories still come first
+	ShowSize        bool
+	MarkExecutables bool   // Stat files to flag executables for colored output
+	CollectTimes    bool   // Stat entries to record modification times
+	CollectMode     bool   // Stat entries to record their permissions and type
+	SizeBasis       string // SizeApparent or SizeAllocated
+	ConcurrentOps   int    // Directories read in parallel; 1 scans sequentially
+	MaxHeapBytes    uint64 // Soft heap ceiling; the scan stops descending beyond it (0 = no limit)
+	MaxNodes        int    // Entries added before the scan stops descending, keeping what it has (0 = no limit)
+
+	ScanTimeout time.Duration // Overall budget of a scan, after which it stops with what it has (0 = no limit)
+	DirTimeout  time.Duration // Longest wait for one directory listing, as on a stalled network share; the directory is then skipped (0 = no limit)
+
+	MaxEntriesPerDir int // Entries kept per directory after filtering; the rest are counted as omitted (0 = no limit)
+	HardDepthLimit   int // Depth beyond which directories are never read, even with MaxDepth -1 (0 = no cap)
+
+	BackgroundPriority bool // Scan at low CPU and I/O priority so foreground work is not disturbed
+
+	Locale         string // BCP 47 tag or POSIX name for number and date formatting ("" = system)
+	PortableOutput bool   // Format output with the C locale so exports diff cleanly
+	OutputFooter   bool   // Append directory and file counts and the scan date to rendered output
+
+	ExportPaths []string // Files saved by the app, excluded from scans unless ShowExports is set
+	ShowExports bool
+
+	RespectGitignore bool // Skip entries matched by .gitignore files in the tree and its repository
+	TreeIgnore       bool // Skip entries matched by the patterns of a .treeignore file at the scan root, and the file
+	FollowSymlinks   bool // Descend into symbolic links to directories; each real directory is read once
+	OneFileSystem    bool // Do not read directories on another filesystem than the root, like du -x; ignored on Windows
+
+	ExcludePatterns []string // Doublestar-style patterns such as "*.log" or "build/**"; matched directories are not read
+	IncludePatterns []string // When set, only files matching one of these patterns and their directories are kept
+
+	SkipPaths []string // System paths never read, matched on whole trailing path components, or from the volume root when they start with a separator or drive; empty scans everything
+
+	AlwaysShowNames []string // Names or globs such as ".github" of hidden entries kept while ShowHidden is off; other exclusions still apply
+
+	PruneEmptyDirs bool // Remove directories left without entries after filtering, so chains of empty folders disappear
+
+	FileKinds map[string]string // Lower-case extensions such as ".proto" mapped to file kinds, overriding the built-in table
+	Languages map[string]string // Lower-case extensions such as ".tpl" mapped to language names for the language summary, overriding the built-in table; "" leaves files out
+
+	ComputeHashes bool  // Hash the content of regular files to find duplicates; reads every file
+	HashMaxBytes  int64 // Files larger than this are not hashed (0 = no limit)
+
+	CountLines    bool  // Count the lines of text files; reads every text file up to LinesMaxBytes
+	LinesMaxBytes int64 // Files larger than this are not counted (0 = no limit)
+
+	EstimateTokens bool  // Estimate the language model tokens of text files up to TokensMaxBytes, summed per directory
+	TokensMaxBytes int64 // Files larger than this get no estimate (0 = no limit)
+
+	CollectXattrs bool // List extended attributes, or alternate data streams on Windows, of every entry
+
+	DuplicateDirMinItems int // Report directories that are copies of each other holding at least this many entries (0 = off)
+}
+
+// DefaultSkipPaths returns the Windows system paths that often cause permission issues, each
+// anchored at the root of its volume. Other systems skip nothing by default.
+func DefaultSkipPaths() []string {
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+	return []string{
+		`\System Volume Information`,
+		`\$Recycle.Bin`,
+		`\$WINDOWS.~BT`,
+		`\Recovery`,
+		`\ProgramData\Microsoft\Windows Defender`,
+		`\Windows\System32\config`,
+	}
+}
+
+// DefaultAlwaysShowNames returns the hidden names that matter for understanding a project.
+func DefaultAlwaysShowNames() []string {
+	return []string{".github", ".gitignore", ".gitattributes"}
 }
 
 // DefaultConfig returns a configuration with sensible defaults: max depth 15, hidden files disabled, directory sorting enabled.
@@ -15,7 +111,44 @@ func DefaultConfig() *Config {
 		MaxDepth:      15, // Reasonable depth limit to prevent hangs
 		ShowHidden:    false,
 		SortDirs:      true,
+		NaturalSort:   true,
+		TreeIgnore:    true,
+		SortBy:        SortByName,
 		ShowSize:      false,
+		SizeBasis:     SizeApparent,
 		ConcurrentOps: 5, // Reduced for stability
+		MaxHeapBytes:  1536 << 20,
+		DirTimeout:    5 * time.Second,
+
+		MaxEntriesPerDir: 10000,
+		HardDepthLimit:   256, // Deep enough for Maven and node_modules trees, shallow enough to stop runaway recursion
+
+		SkipPaths:       DefaultSkipPaths(),
+		AlwaysShowNames: DefaultAlwaysShowNames(),
+
+		HashMaxBytes:  256 << 20,
+		LinesMaxBytes: 1 << 20,
+
+		TokensMaxBytes: 1 << 20,
 	}
-}
\ No newline at end of file
+}
+
+// Clone returns a copy of c that shares no slices or maps with it, so it can be kept as a
+// snapshot while c changes.
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.ExportPaths = slices.Clone(c.ExportPaths)
+	clone.ExcludePatterns = slices.Clone(c.ExcludePatterns)
+	clone.IncludePatterns = slices.Clone(c.IncludePatterns)
+	clone.SkipPaths = slices.Clone(c.SkipPaths)
+	clone.AlwaysShowNames = slices.Clone(c.AlwaysShowNames)
+	clone.FileKinds = maps.Clone(c.FileKinds)
+	clone.Languages = maps.Clone(c.Languages)
+	return &clone
+}
+
+// Restore sets c back to snapshot, taken with Clone. Scanners sharing c see the restored values
+// from their next scan.
+func (c *Config) Restore(snapshot *Config) {
+	*c = *snapshot.Clone()
+}
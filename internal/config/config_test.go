@@ -0,0 +1,83 @@
+package config
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCloneSharesNothing(t *testing.T) {
+	c := DefaultConfig()
+	c.ExcludePatterns = []string{"*.log"}
+	c.SkipPaths = []string{`\Recovery`}
+	c.FileKinds = map[string]string{".proto": "schema"}
+	clone := c.Clone()
+	clone.ExcludePatterns[0] = "build/**"
+	clone.SkipPaths[0] = "changed"
+	clone.FileKinds[".proto"] = "changed"
+	if c.ExcludePatterns[0] != "*.log" || c.SkipPaths[0] == "changed" || c.FileKinds[".proto"] != "schema" {
+		t.Error("changing the clone changed the original")
+	}
+}
+
+func TestRestore(t *testing.T) {
+	c := DefaultConfig()
+	c.SkipPaths = []string{`\Recovery`}
+	snapshot := c.Clone()
+	c.MaxDepth = 3
+	c.IncludePatterns = []string{"*.go"}
+	c.Restore(snapshot)
+	if c.MaxDepth != snapshot.MaxDepth || len(c.IncludePatterns) != 0 {
+		t.Errorf("restored MaxDepth %d, patterns %v", c.MaxDepth, c.IncludePatterns)
+	}
+	c.SkipPaths[0] = "changed"
+	if snapshot.SkipPaths[0] == "changed" {
+		t.Error("the restored config shares slices with the snapshot")
+	}
+}
+
+func TestEventsPublish(t *testing.T) {
+	var events Events
+	var got []Change
+	var order []int
+	events.Subscribe(func(change Change) { got = append(got, change); order = append(order, 1) })
+	events.Subscribe(func(Change) { order = append(order, 2) })
+
+	c := DefaultConfig()
+	previous := c.Clone()
+	events.Publish(previous, c)
+	if len(got) != 0 {
+		t.Fatalf("published %d changes for an unchanged config", len(got))
+	}
+
+	c.ExcludePatterns = []string{"node_modules"}
+	events.Publish(previous, c)
+	if len(got) != 1 || !slices.Equal(order, []int{1, 2}) {
+		t.Fatalf("published %d changes to listeners %v, want 1 to [1 2]", len(got), order)
+	}
+	if len(got[0].Previous.ExcludePatterns) != 0 || !slices.Equal(got[0].Current.ExcludePatterns, []string{"node_modules"}) {
+		t.Errorf("change from %v to %v", got[0].Previous.ExcludePatterns, got[0].Current.ExcludePatterns)
+	}
+
+	// The change holds snapshots, not the live config
+	c.ExcludePatterns[0] = "changed"
+	if got[0].Current.ExcludePatterns[0] != "node_modules" {
+		t.Error("the published change follows later edits")
+	}
+}
+
+func TestDefaultSkipPathsAnchored(t *testing.T) {
+	paths := DefaultSkipPaths()
+	if runtime.GOOS != "windows" {
+		if len(paths) != 0 {
+			t.Errorf("skip paths %q off Windows, want none", paths)
+		}
+		return
+	}
+	for _, path := range paths {
+		if !strings.HasPrefix(path, `\`) {
+			t.Errorf("%q is not anchored at the volume root", path)
+		}
+	}
+}
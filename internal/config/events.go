@@ -0,0 +1,43 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Change is a configuration change that was applied, as snapshots taken with Clone before and
+// after it.
+type Change struct {
+	Previous *Config
+	Current  *Config
+}
+
+// Events tells listeners about configuration changes once they are applied, so edits still in
+// progress reach no one. The zero value is ready to use.
+type Events struct {
+	mu        sync.Mutex
+	listeners []func(Change)
+}
+
+// Subscribe calls listener with every change published from now on.
+func (e *Events) Subscribe(listener func(Change)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, listener)
+}
+
+// Publish calls the listeners, in the order they subscribed, with the change from previous to
+// current. Nothing is published when the two are equal.
+func (e *Events) Publish(previous, current *Config) {
+	if reflect.DeepEqual(previous, current) {
+		return
+	}
+	change := Change{Previous: previous.Clone(), Current: current.Clone()}
+	e.mu.Lock()
+	listeners := slices.Clone(e.listeners)
+	e.mu.Unlock()
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
@@ -0,0 +1,204 @@
+package contextpack
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+func init() {
+	capability.Register(capability.ContextPack)
+}
+
+// filesHeading starts the file sections of a flat pack.
+const filesHeading = "## Files\n"
+
+// binarySniffLen is how much of a file is checked for NUL bytes to detect binary content.
+const binarySniffLen = 8000
+
+// Options controls which files a context pack includes.
+type Options struct {
+	HonorExportIgnore bool  // Leave out entries marked export-ignore, like `git archive`
+	MaxFileBytes      int64 // Larger files are listed in the tree but their content is omitted
+	Dedup             bool  // Store each distinct content once, addressed by its hash; see Unpack
+
+	// Redactor masks sensitive text in the tree, in file paths and in file contents (nil for none)
+	Redactor *renderer.Redactor
+}
+
+// DefaultOptions returns the options used by the GUI and CLI.
+func DefaultOptions() Options {
+	return Options{HonorExportIgnore: true, MaxFileBytes: 256 << 10}
+}
+
+// Exporter writes context packs to files.
+type Exporter struct {
+	Options Options
+}
+
+// Export writes a context pack of result to path.
+func (e *Exporter) Export(ctx context.Context, result *scanner.ScanResult, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", path, err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if err := Write(ctx, writer, result, e.Options); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	return file.Close()
+}
+
+// Write renders a context pack: a Markdown document with the tree followed by the
+// contents of every text file in it, or a deduplicated pack when opts.Dedup is set.
+func Write(ctx context.Context, w io.Writer, result *scanner.ScanResult, opts Options) error {
+	if result == nil || result.Root == nil {
+		return fmt.Errorf("no scan result to export")
+	}
+
+	renderOpts := renderer.DefaultOptions()
+	renderOpts.Header = ""
+	renderOpts.HideExportIgnored = opts.HonorExportIgnore
+	renderOpts.Redactor = opts.Redactor
+	tree := renderer.NewStandardTreeRenderer(renderOpts).RenderTree(result.Root)
+
+	if _, err := fmt.Fprintf(w, "# Context pack: %s\n\n## Tree\n\n```text\n%s```\n\n", opts.Redactor.Apply(result.RootPath), tree); err != nil {
+		return err
+	}
+	if opts.Dedup {
+		return writeDeduplicated(ctx, w, result, opts)
+	}
+
+	if _, err := io.WriteString(w, filesHeading); err != nil {
+		return err
+	}
+	for _, node := range Files(result.Root, opts) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		content, note := opts.readContent(node.Path)
+		if err := writeSection(w, opts.Redactor.Apply(relPath(result.RootPath, node.Path)), content, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relPath returns path relative to root with forward slashes.
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Files lists the file nodes a pack includes, in tree order.
+func Files(root *scanner.TreeNode, opts Options) []*scanner.TreeNode {
+	var files []*scanner.TreeNode
+	var walk func(node *scanner.TreeNode)
+	walk = func(node *scanner.TreeNode) {
+		if opts.HonorExportIgnore && node.ExportIgnore {
+			return
+		}
+		if !node.IsDir && !node.IsVirtual {
+			files = append(files, node)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return files
+}
+
+// writeSection writes one file section; note replaces the content of unreadable, large or binary files.
+func writeSection(w io.Writer, rel string, content []byte, note string) error {
+	if _, err := fmt.Fprintf(w, "\n### %s\n\n", rel); err != nil {
+		return err
+	}
+	if note != "" {
+		_, err := fmt.Fprintf(w, "_%s_\n", note)
+		return err
+	}
+
+	fence := Fence(content)
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	_, err := fmt.Fprintf(w, "%s%s\n%s%s\n", fence, language(rel), content, fence)
+	return err
+}
+
+// readContent returns a file's content, or a note explaining why it is left out.
+func readContent(path string, maxBytes int64) ([]byte, string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Sprintf("unreadable: %v", err)
+	}
+	if maxBytes > 0 && info.Size() > maxBytes {
+		return nil, fmt.Sprintf("omitted: %s exceeds the %s limit", renderer.FormatSize(info.Size()), renderer.FormatSize(maxBytes))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Sprintf("unreadable: %v", err)
+	}
+	if bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0 {
+		return nil, "omitted: binary file"
+	}
+	return content, ""
+}
+
+// readContent returns a file's content, or a note explaining why it is left out, both redacted.
+func (o Options) readContent(path string) ([]byte, string) {
+	content, note := readContent(path, o.MaxFileBytes)
+	if o.Redactor == nil {
+		return content, note
+	}
+	if content != nil {
+		content = []byte(o.Redactor.Apply(string(content)))
+	}
+	return content, o.Redactor.Apply(note)
+}
+
+// Fence returns a backtick fence longer than any backtick run in content.
+func Fence(content []byte) string {
+	longest, run := 0, 0
+	for _, b := range content {
+		if b == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// languages maps file extensions to Markdown code block languages.
+var languages = map[string]string{
+	".go": "go", ".py": "python", ".js": "javascript", ".jsx": "jsx", ".ts": "typescript",
+	".tsx": "tsx", ".rs": "rust", ".java": "java", ".c": "c", ".h": "c", ".cpp": "cpp",
+	".cs": "csharp", ".rb": "ruby", ".php": "php", ".sh": "bash", ".ps1": "powershell",
+	".json": "json", ".yaml": "yaml", ".yml": "yaml", ".toml": "toml", ".md": "markdown",
+	".html": "html", ".css": "css", ".sql": "sql", ".xml": "xml",
+}
+
+// language returns the code block language for a file name, or "".
+func language(name string) string {
+	return languages[strings.ToLower(filepath.Ext(name))]
+}
@@ -0,0 +1,107 @@
+package contextpack
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// scanFiles writes files, keyed by slash-separated path, below a temporary directory and scans it.
+func scanFiles(t *testing.T, files map[string]string) *scanner.ScanResult {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.DefaultConfig()
+	cfg.ShowHidden = true
+	cfg.MaxDepth = -1
+	result, err := scanner.NewFileTreeScanner(cfg).ScanDirectory(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// writePack renders a pack of result with opts, failing the test on error.
+func writePack(t *testing.T, result *scanner.ScanResult, opts Options) string {
+	t.Helper()
+	var pack bytes.Buffer
+	if err := Write(context.Background(), &pack, result, opts); err != nil {
+		t.Fatal(err)
+	}
+	return pack.String()
+}
+
+// exportIgnoreFiles is a project whose .gitattributes leaves tests, docs and logs out of archives.
+var exportIgnoreFiles = map[string]string{
+	".gitattributes":    "/docs export-ignore\n*_test.go export-ignore\n*.log export-ignore\nkeep.log -export-ignore\n",
+	"main.go":           "package main\n",
+	"main_test.go":      "package main // test\n",
+	"docs/guide.md":     "# Guide\n",
+	"logs/app.log":      "started\n",
+	"logs/keep.log":     "kept\n",
+	"internal/x/x.go":   "package x\n",
+	"internal/x/x_test": "not a test file\n",
+}
+
+func TestFilesHonorExportIgnore(t *testing.T) {
+	result := scanFiles(t, exportIgnoreFiles)
+	tests := []struct {
+		honor bool
+		want  []string
+	}{
+		{true, []string{"internal/x/x.go", "internal/x/x_test", "logs/keep.log", ".gitattributes", "main.go"}},
+		{false, []string{"docs/guide.md", "internal/x/x.go", "internal/x/x_test", "logs/app.log", "logs/keep.log", ".gitattributes", "main.go", "main_test.go"}},
+	}
+	for _, tt := range tests {
+		opts := DefaultOptions()
+		opts.HonorExportIgnore = tt.honor
+		var got []string
+		for _, node := range Files(result.Root, opts) {
+			got = append(got, relPath(result.RootPath, node.Path))
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("HonorExportIgnore %v: got %q, want %q", tt.honor, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLeavesOutExportIgnored(t *testing.T) {
+	result := scanFiles(t, exportIgnoreFiles)
+	for _, dedup := range []bool{false, true} {
+		opts := DefaultOptions()
+		opts.Dedup = dedup
+		pack := writePack(t, result, opts)
+		for _, ignored := range []string{"docs/", "guide.md", "main_test.go", "app.log", "# Guide", "package main // test", "started"} {
+			if strings.Contains(pack, ignored) {
+				t.Errorf("Dedup %v: pack contains export-ignored %q:\n%s", dedup, ignored, pack)
+			}
+		}
+		for _, kept := range []string{"main.go", "keep.log", "x_test", "package x", "kept"} {
+			if !strings.Contains(pack, kept) {
+				t.Errorf("Dedup %v: pack is missing %q:\n%s", dedup, kept, pack)
+			}
+		}
+
+		opts.HonorExportIgnore = false
+		pack = writePack(t, result, opts)
+		for _, shown := range []string{"docs/", "guide.md", "main_test.go", "app.log", "# Guide", "started"} {
+			if !strings.Contains(pack, shown) {
+				t.Errorf("Dedup %v without HonorExportIgnore: pack is missing %q", dedup, shown)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package contextpack
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Headings and key prefixes of a deduplicated pack. The manifest lists one file per
+// line as "<key>\t<path>", where the key is hashPrefix plus the hex SHA-256 of the
+// content, or notePrefix plus the reason the content was left out. Each distinct
+// content then appears once under "### <key>" in the blobs section.
+const (
+	manifestHeading = "## Manifest"
+	blobsHeading    = "## Blobs"
+	hashPrefix      = "sha256:"
+	notePrefix      = "note:"
+)
+
+// manifestEntry is one file of a deduplicated pack.
+type manifestEntry struct {
+	key  string
+	path string
+}
+
+// writeDeduplicated writes the manifest and blobs sections for the files of result.
+func writeDeduplicated(ctx context.Context, w io.Writer, result *scanner.ScanResult, opts Options) error {
+	var entries []manifestEntry
+	var order []string
+	blobs := make(map[string][]byte)
+
+	for _, node := range Files(result.Root, opts) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel := opts.Redactor.Apply(relPath(result.RootPath, node.Path))
+		content, note := opts.readContent(node.Path)
+		if note != "" {
+			entries = append(entries, manifestEntry{key: notePrefix + note, path: rel})
+			continue
+		}
+		sum := sha256.Sum256(content)
+		key := hashPrefix + hex.EncodeToString(sum[:])
+		if _, ok := blobs[key]; !ok {
+			blobs[key] = content
+			order = append(order, key)
+		}
+		entries = append(entries, manifestEntry{key: key, path: rel})
+	}
+
+	var manifest strings.Builder
+	for _, entry := range entries {
+		manifest.WriteString(entry.key + "\t" + entry.path + "\n")
+	}
+	fence := Fence([]byte(manifest.String()))
+	if _, err := fmt.Fprintf(w, "%s\n\n%stext\n%s%s\n\n%s\n", manifestHeading, fence, manifest.String(), fence, blobsHeading); err != nil {
+		return err
+	}
+
+	for _, key := range order {
+		if err := writeSection(w, key, blobs[key], ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Unpack reads a deduplicated pack from r and writes the equivalent flat pack to w.
+func Unpack(r io.Reader, w io.Writer) error {
+	reader := &lineReader{r: bufio.NewReader(r)}
+
+	// Everything before the manifest is the shared title and tree
+	var header strings.Builder
+	for {
+		line, err := reader.next()
+		if err != nil {
+			return fmt.Errorf("no manifest in pack: %w", err)
+		}
+		if line == manifestHeading {
+			break
+		}
+		header.WriteString(line + "\n")
+	}
+
+	manifestLines, err := reader.fenced()
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var entries []manifestEntry
+	for _, line := range manifestLines {
+		key, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			return fmt.Errorf("malformed manifest line %q", line)
+		}
+		entries = append(entries, manifestEntry{key: key, path: path})
+	}
+
+	blobs := make(map[string][]byte)
+	for {
+		line, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		key, ok := strings.CutPrefix(line, "### ")
+		if !ok {
+			continue
+		}
+		content, err := reader.fenced()
+		if err != nil {
+			return fmt.Errorf("failed to read blob %s: %w", key, err)
+		}
+		blobs[key] = []byte(strings.Join(content, "\n") + "\n")
+	}
+
+	if _, err := io.WriteString(w, header.String()+filesHeading); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if note, ok := strings.CutPrefix(entry.key, notePrefix); ok {
+			if err := writeSection(w, entry.path, nil, note); err != nil {
+				return err
+			}
+			continue
+		}
+		content, ok := blobs[entry.key]
+		if !ok {
+			return fmt.Errorf("pack has no blob %s for %s", entry.key, entry.path)
+		}
+		if err := writeSection(w, entry.path, content, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineReader reads a pack line by line without a line length limit.
+type lineReader struct {
+	r *bufio.Reader
+}
+
+// next returns the next line without its newline.
+func (l *lineReader) next() (string, error) {
+	line, err := l.r.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// fenced skips to the next code fence and returns the lines inside it.
+func (l *lineReader) fenced() ([]string, error) {
+	var fence string
+	for fence == "" {
+		line, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, "```") {
+			fence = line[:len(line)-len(strings.TrimLeft(line, "`"))]
+		}
+	}
+
+	var lines []string
+	for {
+		line, err := l.next()
+		if err != nil {
+			return nil, fmt.Errorf("unterminated code block: %w", err)
+		}
+		if line == fence {
+			return lines, nil
+		}
+		lines = append(lines, line)
+	}
+}
@@ -0,0 +1,135 @@
+package contextpack
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+)
+
+// dedupFiles is a project with vendored copies, a lockfile per workspace and contents that
+// test the fencing of blobs.
+var dedupFiles = map[string]string{
+	"go.mod":                         "module example.com/app\n",
+	"main.go":                        "package main\n\nfunc main() {}\n",
+	"vendor/a/lib.go":                "package lib\n",
+	"vendor/b/lib.go":                "package lib\n",
+	"third_party/lib.go":             "package lib\n",
+	"web/package-lock.json":          "{\"lockfileVersion\": 3}\n",
+	"admin/package-lock.json":        "{\"lockfileVersion\": 3}\n",
+	"docs/fences.md":                 "# Fences\n\n```go\nx := 1\n```\n\n````\nnested\n````\n",
+	"docs/heading.md":                "### sha256:not-a-blob\n\n## Manifest\n",
+	"docs/no-newline.txt":            "last line without a newline",
+	"docs/blank-lines.txt":           "\n\nbetween\n\n",
+	"empty.txt":                      "",
+	"empty-too.txt":                  "",
+	"bin/tool":                       "ELF\x00\x01\x02",
+	"big/data.csv":                   strings.Repeat("1,2,3\n", 100),
+	"names/with spaces.txt":          "spaced\n",
+	"names/tab\tseparated.txt":       "tabbed\n",
+	"names/unicode-\u00e9t\u00e9.md": "été\n",
+}
+
+// packOptions returns the default options with a small content limit, so big/data.csv is left out.
+func packOptions(dedup bool) Options {
+	opts := DefaultOptions()
+	opts.MaxFileBytes = 512
+	opts.Dedup = dedup
+	return opts
+}
+
+// unpack returns the flat form of a deduplicated pack, failing the test on error.
+func unpack(t *testing.T, pack string) string {
+	t.Helper()
+	var flat bytes.Buffer
+	if err := Unpack(strings.NewReader(pack), &flat); err != nil {
+		t.Fatalf("unpack: %v\n%s", err, pack)
+	}
+	return flat.String()
+}
+
+func TestDedupRoundTrip(t *testing.T) {
+	result := scanFiles(t, dedupFiles)
+	flat := writePack(t, result, packOptions(false))
+	deduplicated := writePack(t, result, packOptions(true))
+
+	if got := unpack(t, deduplicated); got != flat {
+		t.Errorf("unpacked pack differs from the flat pack\n--- unpacked\n%s\n--- flat\n%s", got, flat)
+	}
+}
+
+func TestDedupStoresEachContentOnce(t *testing.T) {
+	result := scanFiles(t, dedupFiles)
+	pack := writePack(t, result, packOptions(true))
+
+	blobs := regexp.MustCompile(`(?m)^### `+hashPrefix+`[0-9a-f]{64}$`).FindAllString(pack, -1)
+	distinct := make(map[string]bool)
+	for _, content := range dedupFiles {
+		if !strings.Contains(content, "\x00") && len(content) <= 512 {
+			distinct[content] = true
+		}
+	}
+	if len(blobs) != len(distinct) {
+		t.Errorf("%d blobs, want one for each of the %d distinct contents", len(blobs), len(distinct))
+	}
+	seen := make(map[string]bool)
+	for _, blob := range blobs {
+		if seen[blob] {
+			t.Errorf("blob %s stored twice", blob)
+		}
+		seen[blob] = true
+	}
+	if strings.Count(pack, "package lib") != 1 {
+		t.Errorf("vendored copies stored %d times, want once", strings.Count(pack, "package lib"))
+	}
+
+	// Left-out contents are notes in the manifest, not blobs
+	for _, note := range []string{notePrefix + "omitted: binary file\tbin/tool", notePrefix + "omitted: 600 B exceeds the 512 B limit\tbig/data.csv"} {
+		if !strings.Contains(pack, note) {
+			t.Errorf("manifest is missing %q:\n%s", note, pack)
+		}
+	}
+}
+
+func TestDedupRoundTripRedacted(t *testing.T) {
+	result := scanFiles(t, map[string]string{
+		"a/secret.txt": "token=hunter2\n",
+		"b/secret.txt": "token=hunter2\n",
+		"hunter2.txt":  "named after the secret\n",
+	})
+	redactor, err := renderer.NewRedactor([]string{"hunter2"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := packOptions(false)
+	opts.Redactor = redactor
+	flat := writePack(t, result, opts)
+	opts.Dedup = true
+	deduplicated := writePack(t, result, opts)
+
+	if strings.Contains(deduplicated, "hunter2") {
+		t.Errorf("deduplicated pack leaks the redacted text:\n%s", deduplicated)
+	}
+	if got := unpack(t, deduplicated); got != flat {
+		t.Errorf("unpacked pack differs from the flat pack\n--- unpacked\n%s\n--- flat\n%s", got, flat)
+	}
+}
+
+func TestUnpackErrors(t *testing.T) {
+	missing := "# Context pack: x\n\n" + manifestHeading + "\n\n```text\n" + hashPrefix + "abc\tmain.go\n```\n\n" + blobsHeading + "\n"
+	tests := map[string]string{
+		"no manifest":       "# Context pack: x\n\n## Files\n",
+		"unterminated":      "# Context pack: x\n\n" + manifestHeading + "\n\n```text\n" + hashPrefix + "abc\tmain.go\n",
+		"malformed line":    "# Context pack: x\n\n" + manifestHeading + "\n\n```text\nno tab here\n```\n",
+		"missing blob":      missing,
+		"unterminated blob": missing + "\n### " + hashPrefix + "abc\n\n```\npackage main\n",
+	}
+	for name, pack := range tests {
+		var flat bytes.Buffer
+		if err := Unpack(strings.NewReader(pack), &flat); err == nil {
+			t.Errorf("%s: unpacked without error:\n%s", name, flat.String())
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Status is the outcome of a single check.
+type Status int
+
+// Check outcomes, from best to worst.
+const (
+	Pass Status = iota
+	Warn
+	Fail
+)
+
+// String returns the status label used in reports.
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Warn:
+		return "WARN"
+	default:
+		return "FAIL"
+	}
+}
+
+// Result is the outcome of a check with an optional remediation hint.
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Hint   string
+}
+
+// Run executes every check in order.
+func Run(ctx context.Context) []Result {
+	return []Result{
+		Display(),
+		Clipboard(),
+		ConfigDir(),
+		SampleScan(ctx),
+		WatchBackend(),
+		LongPaths(),
+		Capabilities(),
+	}
+}
+
+// Worst returns the most severe status among results.
+func Worst(results []Result) Status {
+	worst := Pass
+	for _, result := range results {
+		if result.Status > worst {
+			worst = result.Status
+		}
+	}
+	return worst
+}
+
+// Write prints results one per line, each hint indented below its check.
+func Write(w io.Writer, results []Result) error {
+	for _, result := range results {
+		if _, err := fmt.Fprintf(w, "[%s] %s: %s\n", result.Status, result.Name, result.Detail); err != nil {
+			return err
+		}
+		if result.Hint != "" && result.Status != Pass {
+			if _, err := fmt.Fprintf(w, "       hint: %s\n", result.Hint); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// usesX11 reports whether the GUI on this platform needs an X11 or Wayland display.
+func usesX11() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin", "android", "ios", "js":
+		return false
+	}
+	return true
+}
+
+// Display checks that a display server is reachable for the GUI.
+func Display() Result {
+	return DisplayFrom(os.Getenv)
+}
+
+// DisplayFrom checks for a display server like Display, reading the environment through getenv.
+func DisplayFrom(getenv func(string) string) Result {
+	result := Result{Name: "Display"}
+	if !usesX11() {
+		result.Detail = "native windowing on " + runtime.GOOS
+		return result
+	}
+	for _, key := range []string{"WAYLAND_DISPLAY", "DISPLAY"} {
+		if value := getenv(key); value != "" {
+			result.Detail = key + "=" + value
+			return result
+		}
+	}
+	// Headless scans still work, so a missing display is not fatal
+	result.Status = Warn
+	result.Detail = "neither DISPLAY nor WAYLAND_DISPLAY is set"
+	result.Hint = "run from a desktop session, or use --no-gui for headless scans"
+	return result
+}
+
+// Clipboard checks that a clipboard backend is available.
+func Clipboard() Result {
+	result := Result{Name: "Clipboard"}
+	if !usesX11() {
+		result.Detail = "system clipboard"
+		return result
+	}
+	if Display().Status != Pass {
+		result.Status = Warn
+		result.Detail = "no display, so no clipboard; copy actions will fail"
+		result.Hint = "save to a file instead, or run from a desktop session"
+		return result
+	}
+	result.Detail = "provided by the display server"
+	return result
+}
+
+// ConfigDir checks that the per-user configuration directory, where preferences are stored, is writable.
+func ConfigDir() Result {
+	result := Result{Name: "Config directory"}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		result.Status = Fail
+		result.Detail = err.Error()
+		result.Hint = "set HOME (or XDG_CONFIG_HOME) so settings can be saved"
+		return result
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return configDirFailure(result, dir, err)
+	}
+	probe, err := os.CreateTemp(dir, ".file-tree-scanner-probe-*")
+	if err != nil {
+		return configDirFailure(result, dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	result.Detail = "writable (" + dir + ")"
+	return result
+}
+
+// configDirFailure fills result for a configuration directory that cannot be written.
+func configDirFailure(result Result, dir string, err error) Result {
+	result.Status = Fail
+	result.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+	result.Hint = "fix the directory's permissions; settings and export history are not saved until then"
+	return result
+}
+
+// Capabilities lists the optional features this build supports.
+func Capabilities() Result {
+	names := capability.List()
+	list := make([]string, len(names))
+	for i, name := range names {
+		list[i] = string(name)
+	}
+	return Result{Name: "Capabilities", Detail: strings.Join(list, ", ")}
+}
+
+// SampleScan scans a small temporary tree and checks that every entry is found.
+func SampleScan(ctx context.Context) Result {
+	result := Result{Name: "Sample scan"}
+	dir, err := os.MkdirTemp("", "file-tree-scanner-doctor-*")
+	if err != nil {
+		result.Status = Fail
+		result.Detail = fmt.Sprintf("failed to create temp dir: %v", err)
+		result.Hint = "check that TMPDIR points to a writable directory"
+		return result
+	}
+	defer os.RemoveAll(dir)
+
+	// Root, two directories and three files
+	files := []string{"a.txt", filepath.Join("sub", "b.txt"), filepath.Join("sub", "deeper", "c.txt")}
+	for _, name := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			err = os.WriteFile(path, []byte("doctor\n"), 0o644)
+		}
+		if err != nil {
+			result.Status = Fail
+			result.Detail = fmt.Sprintf("failed to create sample files: %v", err)
+			return result
+		}
+	}
+	const want = 6
+
+	scanResult, err := scanner.NewFileTreeScanner(config.DefaultConfig()).ScanDirectory(ctx, dir)
+	if err != nil {
+		result.Status = Fail
+		result.Detail = err.Error()
+		return result
+	}
+	if scanResult.NodeCount != want {
+		result.Status = Fail
+		result.Detail = fmt.Sprintf("found %d entries, expected %d", scanResult.NodeCount, want)
+		result.Hint = "security software may be hiding files in temp directories"
+		return result
+	}
+	result.Detail = fmt.Sprintf("found all %d entries", want)
+	return result
+}
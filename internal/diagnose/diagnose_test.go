@@ -0,0 +1,131 @@
+package diagnose
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// env returns a getenv reading from values.
+func env(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func TestDisplayFrom(t *testing.T) {
+	if !usesX11() {
+		if result := DisplayFrom(env(nil)); result.Status != Pass {
+			t.Errorf("native windowing reported %s", result.Status)
+		}
+		return
+	}
+	tests := []struct {
+		name   string
+		env    map[string]string
+		status Status
+		detail string
+	}{
+		{"X11", map[string]string{"DISPLAY": ":0"}, Pass, "DISPLAY=:0"},
+		{"Wayland first", map[string]string{"DISPLAY": ":0", "WAYLAND_DISPLAY": "wayland-0"}, Pass, "WAYLAND_DISPLAY=wayland-0"},
+		{"headless", nil, Warn, "neither DISPLAY nor WAYLAND_DISPLAY is set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DisplayFrom(env(tt.env))
+			if result.Status != tt.status || result.Detail != tt.detail {
+				t.Errorf("got %s %q, want %s %q", result.Status, result.Detail, tt.status, tt.detail)
+			}
+			if (result.Hint != "") != (tt.status != Pass) {
+				t.Errorf("hint %q for %s", result.Hint, result.Status)
+			}
+		})
+	}
+}
+
+func TestClipboard(t *testing.T) {
+	if !usesX11() {
+		t.Skip("the clipboard does not depend on a display server on " + runtime.GOOS)
+	}
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", "")
+	if result := Clipboard(); result.Status != Warn || result.Hint == "" {
+		t.Errorf("without a display: %+v, want a warning with a hint", result)
+	}
+	t.Setenv("DISPLAY", ":1")
+	if result := Clipboard(); result.Status != Pass {
+		t.Errorf("with a display: %+v, want a pass", result)
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the configuration directory is only moved through XDG_CONFIG_HOME on Linux")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	if result := ConfigDir(); result.Status != Pass || !strings.Contains(result.Detail, dir) {
+		t.Errorf("writable directory: %+v", result)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "config")); len(entries) != 0 {
+		t.Errorf("the probe file was left behind: %v", entries)
+	}
+
+	// A file where the directory should be cannot be written to
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", blocked)
+	if result := ConfigDir(); result.Status != Fail || !strings.Contains(result.Detail, "is not writable") || result.Hint == "" {
+		t.Errorf("unwritable directory: %+v", result)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "")
+	if result := ConfigDir(); result.Status != Fail || result.Hint == "" {
+		t.Errorf("no home directory: %+v", result)
+	}
+}
+
+func TestSampleScan(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	if result := SampleScan(context.Background()); result.Status != Pass {
+		t.Errorf("sample scan: %+v", result)
+	}
+	if entries, _ := os.ReadDir(os.TempDir()); len(entries) != 0 {
+		t.Errorf("the sample tree was left behind: %v", entries)
+	}
+
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+	if result := SampleScan(context.Background()); result.Status != Fail || result.Hint == "" {
+		t.Errorf("without a temp directory: %+v", result)
+	}
+}
+
+func TestWorstAndWrite(t *testing.T) {
+	results := []Result{
+		{Name: "One", Status: Pass, Detail: "fine", Hint: "not shown"},
+		{Name: "Two", Status: Warn, Detail: "hmm", Hint: "do this"},
+	}
+	if worst := Worst(results); worst != Warn {
+		t.Errorf("Worst = %s, want WARN", worst)
+	}
+	if worst := Worst(append(results, Result{Status: Fail})); worst != Fail {
+		t.Errorf("Worst = %s, want FAIL", worst)
+	}
+	if worst := Worst(nil); worst != Pass {
+		t.Errorf("Worst of nothing = %s, want PASS", worst)
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	want := "[PASS] One: fine\n[WARN] Two: hmm\n       hint: do this\n"
+	if out.String() != want {
+		t.Errorf("Write:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
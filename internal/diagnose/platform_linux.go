@@ -0,0 +1,55 @@
+package diagnose
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// minInotifyWatches is the watch limit below which watching a large tree is likely to fail.
+const minInotifyWatches = 65536
+
+// maxUserWatchesPath is the file holding the inotify watch limit.
+const maxUserWatchesPath = "/proc/sys/fs/inotify/max_user_watches"
+
+// WatchBackend checks the inotify watch limit.
+func WatchBackend() Result {
+	return watchBackendFrom(maxUserWatchesPath)
+}
+
+// watchBackendFrom checks the inotify watch limit like WatchBackend, reading it from path.
+func watchBackendFrom(path string) Result {
+	result := Result{Name: "Watch backend"}
+	value, err := trimmed(path)
+	if err != nil {
+		result.Status = Warn
+		result.Detail = fmt.Sprintf("inotify limits unavailable: %v", err)
+		result.Hint = "folder watching may not work in this environment"
+		return result
+	}
+
+	watches, err := strconv.Atoi(value)
+	if err != nil {
+		result.Status = Warn
+		result.Detail = fmt.Sprintf("unexpected max_user_watches value %q", value)
+		return result
+	}
+	result.Detail = fmt.Sprintf("inotify, max_user_watches=%d", watches)
+	if watches < minInotifyWatches {
+		result.Status = Warn
+		result.Hint = fmt.Sprintf("raise it with: sysctl fs.inotify.max_user_watches=%d", minInotifyWatches*8)
+	}
+	return result
+}
+
+// LongPaths checks long path support, which only needs enabling on Windows.
+func LongPaths() Result {
+	return Result{Name: "Long paths", Detail: "not limited on linux"}
+}
+
+// trimmed reads a small file and returns its trimmed content.
+func trimmed(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	return strings.TrimSpace(string(data)), err
+}
@@ -0,0 +1,43 @@
+package diagnose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWatchBackend(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content *string
+		status  Status
+		detail  string
+		hint    bool
+	}{
+		{"high limit", ptr("524288\n"), Pass, "max_user_watches=524288", false},
+		{"low limit", ptr("8192\n"), Warn, "max_user_watches=8192", true},
+		{"garbage", ptr("lots\n"), Warn, "unexpected max_user_watches value", false},
+		{"no inotify", nil, Warn, "inotify limits unavailable", true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, string(rune('a'+i)))
+			if tt.content != nil {
+				if err := os.WriteFile(path, []byte(*tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			result := watchBackendFrom(path)
+			if result.Status != tt.status || !strings.Contains(result.Detail, tt.detail) || (result.Hint != "") != tt.hint {
+				t.Errorf("got %+v, want %s with %q and a hint %v", result, tt.status, tt.detail, tt.hint)
+			}
+		})
+	}
+}
+
+// ptr returns a pointer to s.
+func ptr(s string) *string {
+	return &s
+}
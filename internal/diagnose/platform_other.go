@@ -0,0 +1,20 @@
+//go:build !linux && !windows
+
+package diagnose
+
+import "runtime"
+
+// WatchBackend checks the directory change notification backend.
+func WatchBackend() Result {
+	result := Result{Name: "Watch backend", Detail: "native on " + runtime.GOOS}
+	if runtime.GOOS == "js" {
+		result.Status = Warn
+		result.Detail = "not available in the browser"
+	}
+	return result
+}
+
+// LongPaths checks long path support, which only needs enabling on Windows.
+func LongPaths() Result {
+	return Result{Name: "Long paths", Detail: "not limited on " + runtime.GOOS}
+}
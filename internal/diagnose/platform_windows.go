@@ -0,0 +1,32 @@
+package diagnose
+
+import (
+	"golang.org/x/sys/windows/registry"
+)
+
+// WatchBackend checks the directory change notification backend.
+func WatchBackend() Result {
+	return Result{Name: "Watch backend", Detail: "ReadDirectoryChangesW"}
+}
+
+// LongPaths checks whether paths longer than 260 characters are enabled.
+func LongPaths() Result {
+	result := Result{Name: "Long paths"}
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\FileSystem`, registry.QUERY_VALUE)
+	if err != nil {
+		result.Status = Warn
+		result.Detail = "cannot read the LongPathsEnabled setting: " + err.Error()
+		return result
+	}
+	defer key.Close()
+
+	enabled, _, err := key.GetIntegerValue("LongPathsEnabled")
+	if err != nil || enabled == 0 {
+		result.Status = Warn
+		result.Detail = "LongPathsEnabled is off; paths over 260 characters cannot be read"
+		result.Hint = "enable \"Win32 long paths\" in Group Policy or set LongPathsEnabled=1 in the registry"
+		return result
+	}
+	result.Detail = "LongPathsEnabled is on"
+	return result
+}
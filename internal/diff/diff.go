@@ -0,0 +1,244 @@
+// Package diff compares two scans of a folder entry by entry, such as one taken before a build
+// and one after, and merges them into a single tree marking what was added, removed or modified.
+package diff
+
+import (
+	"path/filepath"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Change is how an entry differs between the old scan and the new one.
+type Change string
+
+// Changes an entry can have.
+const (
+	Unchanged Change = ""
+	Added     Change = "added"
+	Removed   Change = "removed"
+	Modified  Change = "modified" // Size, time, content or link target differs, or a file became a directory or the reverse
+)
+
+// Annotation returns how output marks c, through a renderer.AnnotationStyle.
+func (c Change) Annotation() renderer.Annotation {
+	switch c {
+	case Added:
+		return renderer.AnnotationAdded
+	case Removed:
+		return renderer.AnnotationRemoved
+	case Modified:
+		return renderer.AnnotationModified
+	}
+	return renderer.AnnotationNone
+}
+
+// Result is the difference between two scans.
+type Result struct {
+	Old, New *scanner.ScanResult
+
+	// Tree is the new scan with the removed entries merged back in, each below the directory it
+	// was removed from. It is a copy, so neither scan changes; removed entries are virtual.
+	Tree *scanner.ScanResult
+
+	// Entries of Tree by change, in tree order. Added and removed directories are listed with
+	// their contents. Directories are only modified by becoming files or the reverse; the old
+	// contents of a directory that became a file are not in Tree, having nowhere to go.
+	Added    []*scanner.TreeNode
+	Removed  []*scanner.TreeNode
+	Modified []*scanner.TreeNode
+
+	changes map[string]Change // By path in Tree
+}
+
+// Diff compares the older scan with the newer one, matching entries by their path relative to
+// each root; both must have a tree. Files count as modified when their sizes, modification times
+// or hashes differ, each only where both scans collected them, or when a link points elsewhere.
+func Diff(older, newer *scanner.ScanResult) *Result {
+	d := &Result{Old: older, New: newer, changes: make(map[string]Change)}
+	facts := comparison{
+		sizes:  older.HasSizes && newer.HasSizes,
+		times:  older.HasTimes && newer.HasTimes,
+		hashes: older.HasHashes && newer.HasHashes,
+	}
+
+	root := copyNode(newer.Root, newer.RootPath, nil)
+	d.Tree = &scanner.ScanResult{
+		RootPath:        newer.RootPath,
+		Root:            root,
+		HasSizes:        newer.HasSizes,
+		HasTimes:        newer.HasTimes,
+		HasModes:        newer.HasModes,
+		HasHashes:       newer.HasHashes,
+		HasXattrs:       newer.HasXattrs,
+		HasLines:        newer.HasLines,
+		HasTokens:       newer.HasTokens,
+		IncludePatterns: newer.IncludePatterns,
+		ExcludePatterns: newer.ExcludePatterns,
+		LanguageTable:   newer.LanguageTable,
+		TotalSize:       newer.TotalSize,
+		TotalTokens:     newer.TotalTokens,
+		Partial:         older.Partial || newer.Partial,
+		CountsPartial:   older.CountsPartial || newer.CountsPartial,
+	}
+	if older.Root != nil && newer.Root != nil {
+		d.mergeDir(root, older.Root, newer.Root, facts)
+	}
+	d.Tree.NodeCount = 1 + countBelow(root)
+	scanner.Tally(d.Tree)
+	return d
+}
+
+// Change returns how the entry of Tree at path changed.
+func (d *Result) Change(path string) Change {
+	return d.changes[path]
+}
+
+// Annotation returns the annotation of the change of node, for renderer.NewDiffRenderer. Nodes of
+// copies of Tree, such as a view with entries excluded, are matched by path.
+func (d *Result) Annotation(node *scanner.TreeNode) renderer.Annotation {
+	return d.Change(node.Path).Annotation()
+}
+
+// Empty reports whether the scans hold the same entries with the same facts.
+func (d *Result) Empty() bool {
+	return len(d.changes) == 0
+}
+
+// comparison says which facts of files both scans collected.
+type comparison struct {
+	sizes, times, hashes bool
+}
+
+// modified reports whether the file old changed into cur.
+func (c comparison) modified(old, cur *scanner.TreeNode) bool {
+	switch {
+	case old.IsDir != cur.IsDir:
+		return true
+	case cur.IsDir:
+		return false // Sizes and times of directories follow from their contents
+	case old.IsSymlink != cur.IsSymlink || old.LinkTarget != cur.LinkTarget:
+		return true
+	case c.sizes && !old.SizeUnknown && !cur.SizeUnknown && old.Size != cur.Size:
+		return true
+	case c.times && !old.ModTime.IsZero() && !cur.ModTime.IsZero() && !old.ModTime.Equal(cur.ModTime):
+		return true
+	}
+	return c.hashes && old.Hash != "" && cur.Hash != "" && old.Hash != cur.Hash
+}
+
+// mergeDir compares the children of the directory old with those of cur, whose copy in Tree is
+// merged, marking the copies of cur's children and inserting copies of the removed ones. Each
+// removed entry follows the entry it followed in old, so the merged order reads like both.
+func (d *Result) mergeDir(merged, old, cur *scanner.TreeNode, facts comparison) {
+	before := make(map[string]*scanner.TreeNode, len(old.Children))
+	for _, child := range old.Children {
+		before[child.Name] = child
+	}
+	present := make(map[string]bool, len(cur.Children))
+	for _, child := range cur.Children {
+		present[child.Name] = true
+	}
+
+	// Removed entries by the name of the entry before them in old, "" for the first ones
+	removedAfter := make(map[string][]*scanner.TreeNode)
+	anchor := ""
+	for _, child := range old.Children {
+		if present[child.Name] {
+			anchor = child.Name
+		} else {
+			removedAfter[anchor] = append(removedAfter[anchor], child)
+		}
+	}
+
+	copies := merged.Children
+	merged.Children = nil
+	d.insertRemoved(merged, removedAfter[""])
+	for i, child := range cur.Children {
+		mergedChild := copies[i]
+		merged.Children = append(merged.Children, mergedChild)
+		if previous := before[child.Name]; previous == nil {
+			d.markAll(mergedChild, Added, &d.Added)
+		} else {
+			if facts.modified(previous, child) {
+				d.mark(mergedChild, Modified, &d.Modified)
+			}
+			switch {
+			case previous.IsDir && child.IsDir:
+				d.mergeDir(mergedChild, previous, child, facts)
+			case child.IsDir:
+				// A file became this directory, so all it holds is new
+				for _, grandchild := range mergedChild.Children {
+					d.markAll(grandchild, Added, &d.Added)
+				}
+			case previous.IsDir:
+				for _, gone := range previous.Children {
+					d.Removed = appendAll(d.Removed, gone)
+				}
+			}
+		}
+		d.insertRemoved(merged, removedAfter[child.Name])
+	}
+}
+
+// insertRemoved appends copies of the removed entries to the children of merged.
+func (d *Result) insertRemoved(merged *scanner.TreeNode, removed []*scanner.TreeNode) {
+	for _, gone := range removed {
+		copied := copyNode(gone, filepath.Join(merged.Path, gone.Name), merged)
+		markVirtual(copied)
+		merged.Children = append(merged.Children, copied)
+		d.markAll(copied, Removed, &d.Removed)
+	}
+}
+
+// mark records the change of node, adding it to list.
+func (d *Result) mark(node *scanner.TreeNode, change Change, list *[]*scanner.TreeNode) {
+	d.changes[node.Path] = change
+	*list = append(*list, node)
+}
+
+// markAll records the change of node and everything below it.
+func (d *Result) markAll(node *scanner.TreeNode, change Change, list *[]*scanner.TreeNode) {
+	d.mark(node, change, list)
+	for _, child := range node.Children {
+		d.markAll(child, change, list)
+	}
+}
+
+// copyNode returns a copy of node and everything below it at path, below parent.
+func copyNode(node *scanner.TreeNode, path string, parent *scanner.TreeNode) *scanner.TreeNode {
+	copied := *node
+	copied.Path = path
+	copied.Parent = parent
+	copied.Children = make([]*scanner.TreeNode, 0, len(node.Children))
+	for _, child := range node.Children {
+		copied.Children = append(copied.Children, copyNode(child, filepath.Join(path, child.Name), &copied))
+	}
+	return &copied
+}
+
+// markVirtual marks node and everything below it as not on disk.
+func markVirtual(node *scanner.TreeNode) {
+	node.IsVirtual = true
+	for _, child := range node.Children {
+		markVirtual(child)
+	}
+}
+
+// appendAll appends node and everything below it to list.
+func appendAll(list []*scanner.TreeNode, node *scanner.TreeNode) []*scanner.TreeNode {
+	list = append(list, node)
+	for _, child := range node.Children {
+		list = appendAll(list, child)
+	}
+	return list
+}
+
+// countBelow returns the number of entries below node.
+func countBelow(node *scanner.TreeNode) int {
+	count := 0
+	for _, child := range node.Children {
+		count += 1 + countBelow(child)
+	}
+	return count
+}
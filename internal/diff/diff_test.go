@@ -0,0 +1,149 @@
+package diff
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// scan returns a result with sizes rooted at /work/project holding children, with paths and
+// parents set.
+func scan(children ...*scanner.TreeNode) *scanner.ScanResult {
+	root := &scanner.TreeNode{Name: "project", IsDir: true, Children: children}
+	var link func(node *scanner.TreeNode, path string)
+	link = func(node *scanner.TreeNode, path string) {
+		node.Path = path
+		for _, child := range node.Children {
+			child.Parent = node
+			link(child, filepath.Join(path, child.Name))
+		}
+	}
+	rootPath := filepath.FromSlash("/work/project")
+	link(root, rootPath)
+	return &scanner.ScanResult{RootPath: rootPath, Root: root, HasSizes: true}
+}
+
+func dir(name string, children ...*scanner.TreeNode) *scanner.TreeNode {
+	return &scanner.TreeNode{Name: name, IsDir: true, Children: children}
+}
+
+func file(name string, size int64) *scanner.TreeNode {
+	return &scanner.TreeNode{Name: name, Size: size}
+}
+
+// sampleDiff compares two scans in which b.txt grew, src/new.go and added.md appeared and
+// gone/ with its file disappeared.
+func sampleDiff() *Result {
+	older := scan(file("a.txt", 10), file("b.txt", 5), dir("src", file("main.go", 1)), dir("gone", file("x.log", 3)))
+	newer := scan(file("a.txt", 10), file("b.txt", 7), dir("src", file("main.go", 1), file("new.go", 2)), file("added.md", 4))
+	return Diff(older, newer)
+}
+
+// renderDiff renders d with style, without a header.
+func renderDiff(d *Result, style renderer.AnnotationStyle) string {
+	opts := renderer.DefaultOptions()
+	opts.Header = ""
+	opts.Style = style
+	return renderer.NewDiffRenderer(opts, d.Annotation).RenderTree(d.Tree.Root)
+}
+
+func TestDiffChanges(t *testing.T) {
+	d := sampleDiff()
+	names := func(nodes []*scanner.TreeNode) []string {
+		var out []string
+		for _, node := range nodes {
+			out = append(out, node.Name)
+		}
+		return out
+	}
+	for _, tt := range []struct {
+		change Change
+		got    []string
+		want   []string
+	}{
+		{Added, names(d.Added), []string{"new.go", "added.md"}},
+		{Removed, names(d.Removed), []string{"gone", "x.log"}},
+		{Modified, names(d.Modified), []string{"b.txt"}},
+	} {
+		if !equal(tt.got, tt.want) {
+			t.Errorf("%s %q, want %q", tt.change, tt.got, tt.want)
+		}
+	}
+	if d.Empty() {
+		t.Error("diff with changes reported empty")
+	}
+	if same := Diff(d.New, d.New); !same.Empty() {
+		t.Errorf("a scan differs from itself: %v", same.changes)
+	}
+}
+
+func TestDiffGoldenColors(t *testing.T) {
+	want := "  📄 a.txt\n" +
+		"~ ├── 📄 b.txt\n" +
+		"  ├── 📁 src/\n" +
+		"  │   ├── 📄 main.go\n" +
+		"+ │   └── 📄 new.go\n" +
+		"- ├── 📁 gone/\n" +
+		"- │   └── 📄 x.log\n" +
+		"+ └── 📄 added.md\n"
+	if got := renderDiff(sampleDiff(), renderer.AnnotationStyle{}); got != want {
+		t.Errorf("diff tree:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffGoldenSymbols(t *testing.T) {
+	want := "  📄 a.txt\n" +
+		"≠ ├── 📄 b.txt\n" +
+		"  ├── 📁 src/\n" +
+		"  │   ├── 📄 main.go\n" +
+		"+ │   └── 📄 new.go\n" +
+		"− ├── 📁 gone/\n" +
+		"− │   └── 📄 x.log\n" +
+		"+ └── 📄 added.md\n"
+	if got := renderDiff(sampleDiff(), renderer.AnnotationStyle{Symbols: true}); got != want {
+		t.Errorf("diff tree with symbols:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestChangeStyles(t *testing.T) {
+	colors, symbols := renderer.AnnotationStyle{}, renderer.AnnotationStyle{Symbols: true}
+	for _, tt := range []struct {
+		change       Change
+		color        string
+		mark, symbol string
+	}{
+		{Added, "\x1b[32m", "+", "+"},
+		{Removed, "\x1b[31m", "-", "−"},
+		{Modified, "\x1b[33m", "~", "≠"},
+		{Unchanged, "", " ", " "},
+	} {
+		a := tt.change.Annotation()
+		if got := colors.Color(a); got != tt.color {
+			t.Errorf("%q colored %q, want %q", tt.change, got, tt.color)
+		}
+		if got := symbols.Color(a); got != "" {
+			t.Errorf("%q colored %q with symbols", tt.change, got)
+		}
+		if got := colors.Mark(a); got != tt.mark {
+			t.Errorf("%q marked %q, want %q", tt.change, got, tt.mark)
+		}
+		if got := symbols.Mark(a); got != tt.symbol {
+			t.Errorf("%q marked %q with symbols, want %q", tt.change, got, tt.symbol)
+		}
+	}
+}
+
+// equal reports whether a and b hold the same strings in the same order.
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package exporter
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// FileExporter defines the interface for exporters that write directly to a file path.
+type FileExporter interface {
+	Export(ctx context.Context, result *scanner.ScanResult, path string) error
+}
+
+// ForPath returns the file exporter responsible for path's extension, or nil for plain text output.
+func ForPath(path string) FileExporter {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".sqlite", ".db":
+		return &SQLiteExporter{}
+	case ".csv":
+		return &CSVExporter{}
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Names of paged inventory files written into the target directory.
+const (
+	inventoryPartFormat = "inventory_%03d%s"
+	inventoryIndexName  = "inventory_index.csv"
+)
+
+// csvHeader is the first row of every CSV inventory file.
+var csvHeader = []string{"path", "type", "size", "depth"}
+
+// inventoryRow is one entry of a flat inventory.
+type inventoryRow struct {
+	path  string // Slash-separated path relative to the scan root; directories end in "/"
+	node  *scanner.TreeNode
+	depth int
+}
+
+// inventoryFormat writes rows in one file format.
+type inventoryFormat interface {
+	ext() string
+	open(file *os.File) inventoryWriter
+}
+
+// inventoryWriter writes the rows of a single file.
+type inventoryWriter interface {
+	write(row inventoryRow) error
+	close() error
+}
+
+// CSVExporter writes a flat CSV inventory with one row per entry. With RowsPerFile set,
+// Export treats its path as a directory and writes numbered parts plus an index.
+type CSVExporter struct {
+	RowsPerFile int
+	Written     []string // Files written by the last Export
+}
+
+// Export implements FileExporter.
+func (e *CSVExporter) Export(ctx context.Context, result *scanner.ScanResult, path string) error {
+	var err error
+	e.Written, err = exportInventory(ctx, result, path, e.RowsPerFile, csvFormat{})
+	return err
+}
+
+// FlatExporter writes a plain list of relative paths, one per line. With RowsPerFile set,
+// Export treats its path as a directory and writes numbered parts plus an index.
+type FlatExporter struct {
+	RowsPerFile int
+	Written     []string // Files written by the last Export
+}
+
+// Export implements FileExporter.
+func (e *FlatExporter) Export(ctx context.Context, result *scanner.ScanResult, path string) error {
+	var err error
+	e.Written, err = exportInventory(ctx, result, path, e.RowsPerFile, flatFormat{})
+	return err
+}
+
+// exportInventory writes result's rows to path, or to numbered parts inside path when
+// rowsPerFile > 0, and returns the files written.
+func exportInventory(ctx context.Context, result *scanner.ScanResult, path string, rowsPerFile int, format inventoryFormat) ([]string, error) {
+	if result == nil || result.Root == nil {
+		return nil, fmt.Errorf("no scan result to export")
+	}
+	rows := inventoryRows(result.Root)
+
+	if rowsPerFile <= 0 {
+		if err := writeInventoryFile(ctx, path, format, rows); err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory %q: %w", path, err)
+	}
+
+	// Parts hold whole rows only, so no row is ever split between files
+	var written []string
+	index := [][]string{{"file", "rows", "first_path", "last_path"}}
+	for part, start := 1, 0; start < len(rows); part, start = part+1, start+rowsPerFile {
+		end := min(start+rowsPerFile, len(rows))
+		name := fmt.Sprintf(inventoryPartFormat, part, format.ext())
+		partPath := filepath.Join(path, name)
+		if err := writeInventoryFile(ctx, partPath, format, rows[start:end]); err != nil {
+			return written, err
+		}
+		written = append(written, partPath)
+		index = append(index, []string{name, strconv.Itoa(end - start), rows[start].path, rows[end-1].path})
+	}
+
+	indexPath := filepath.Join(path, inventoryIndexName)
+	if err := writeCSVFile(indexPath, index); err != nil {
+		return written, err
+	}
+	return append(written, indexPath), nil
+}
+
+// inventoryRows lists the tree's entries below root in depth-first order.
+func inventoryRows(root *scanner.TreeNode) []inventoryRow {
+	var rows []inventoryRow
+	var walk func(node *scanner.TreeNode, rel string, depth int)
+	walk = func(node *scanner.TreeNode, rel string, depth int) {
+		for _, child := range node.Children {
+			childRel := child.Name
+			if rel != "" {
+				childRel = rel + "/" + child.Name
+			}
+			row := inventoryRow{path: childRel, node: child, depth: depth + 1}
+			if child.IsDir {
+				row.path += "/"
+			}
+			rows = append(rows, row)
+			walk(child, childRel, depth+1)
+		}
+	}
+	walk(root, "", 0)
+	return rows
+}
+
+// writeInventoryFile writes rows to a single file at path.
+func writeInventoryFile(ctx context.Context, path string, format inventoryFormat, rows []inventoryRow) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", path, err)
+	}
+	defer file.Close()
+
+	writer := format.open(file)
+	for i, row := range rows {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if err := writer.write(row); err != nil {
+			return fmt.Errorf("failed to write %q: %w", path, err)
+		}
+	}
+	if err := writer.close(); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	return file.Close()
+}
+
+// writeCSVFile writes records to a new CSV file at path.
+func writeCSVFile(path string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", path, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	return file.Close()
+}
+
+// csvFormat writes CSV rows with a header.
+type csvFormat struct{}
+
+// ext implements inventoryFormat.
+func (csvFormat) ext() string { return ".csv" }
+
+// open implements inventoryFormat.
+func (csvFormat) open(file *os.File) inventoryWriter {
+	return &csvRowWriter{writer: csv.NewWriter(file)}
+}
+
+// csvRowWriter writes the header before the first row, or alone into an empty file.
+type csvRowWriter struct {
+	writer  *csv.Writer
+	started bool
+}
+
+// start writes the header once.
+func (w *csvRowWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.writer.Write(csvHeader)
+}
+
+// write implements inventoryWriter.
+func (w *csvRowWriter) write(row inventoryRow) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	// Sizes are left blank for directories and when they were not collected
+	kind, size := "file", ""
+	if row.node.IsDir {
+		kind = "dir"
+	} else if row.node.Size != 0 || row.node.DiskSize != 0 {
+		size = strconv.FormatInt(row.node.Size, 10)
+	}
+	return w.writer.Write([]string{row.path, kind, size, strconv.Itoa(row.depth)})
+}
+
+// close implements inventoryWriter.
+func (w *csvRowWriter) close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// flatFormat writes one path per line, quoting paths that a line-based reader would misread,
+// including those with a backslash, which listings may use as a separator.
+type flatFormat struct{}
+
+// ext implements inventoryFormat.
+func (flatFormat) ext() string { return ".txt" }
+
+// open implements inventoryFormat.
+func (flatFormat) open(file *os.File) inventoryWriter {
+	return &flatRowWriter{writer: bufio.NewWriter(file)}
+}
+
+// flatRowWriter buffers path lines.
+type flatRowWriter struct {
+	writer *bufio.Writer
+}
+
+// write implements inventoryWriter.
+func (w *flatRowWriter) write(row inventoryRow) error {
+	_, err := w.writer.WriteString(renderer.QuoteName(row.path, `\`) + "\n")
+	return err
+}
+
+// close implements inventoryWriter.
+func (w *flatRowWriter) close() error {
+	return w.writer.Flush()
+}
@@ -0,0 +1,131 @@
+package exporter
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/importer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// adversarialNames are names a line-based reader could misread as separators, structure,
+// quoting or whitespace to trim.
+var adversarialNames = []string{
+	"plain.txt",
+	"├── fake entry",
+	"│ inside",
+	" leading space",
+	"trailing space ",
+	"line\nbreak",
+	`"quoted"`,
+	"comma, and \"quotes\"",
+	`back\slash`,
+	"arrow -> target",
+	"naïve café",
+}
+
+// inventoryFixture returns a result holding every adversarial name as a file and as a directory
+// with a file inside, and the inventory paths of its entries, sorted.
+func inventoryFixture() (*scanner.ScanResult, []string) {
+	root := &scanner.TreeNode{Name: "root", Path: "/data/root", IsDir: true}
+	var paths []string
+	add := func(parent *scanner.TreeNode, rel, name string, dir bool) *scanner.TreeNode {
+		node := &scanner.TreeNode{Name: name, Path: parent.Path + "/" + name, IsDir: dir, Parent: parent}
+		parent.Children = append(parent.Children, node)
+		rel = path.Join(rel, name)
+		if dir {
+			paths = append(paths, rel+"/")
+		} else {
+			paths = append(paths, rel)
+		}
+		return node
+	}
+	for i, name := range adversarialNames {
+		add(root, "", name, false)
+		dir := add(root, "", "dir "+string(rune('a'+i))+" "+name, true)
+		add(dir, dir.Name, name, false)
+	}
+	sort.Strings(paths)
+	return &scanner.ScanResult{Root: root, RootPath: root.Path}, paths
+}
+
+// listedPaths returns the inventory paths of the entries below root, sorted.
+func listedPaths(root *scanner.TreeNode) []string {
+	var paths []string
+	var walk func(node *scanner.TreeNode, rel string)
+	walk = func(node *scanner.TreeNode, rel string) {
+		for _, child := range node.Children {
+			childRel := path.Join(rel, child.Name)
+			if child.IsDir {
+				paths = append(paths, childRel+"/")
+			} else {
+				paths = append(paths, childRel)
+			}
+			walk(child, childRel)
+		}
+	}
+	walk(root, "")
+	sort.Strings(paths)
+	return paths
+}
+
+// samePaths reports the differences between got and want.
+func samePaths(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d paths, want %d:\n%q\nwant:\n%q", what, len(got), len(want), got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%s: path %q, want %q", what, got[i], want[i])
+		}
+	}
+}
+
+func TestFlatInventoryRoundTrip(t *testing.T) {
+	result, want := inventoryFixture()
+	out := filepath.Join(t.TempDir(), "inventory.txt")
+	if err := (&FlatExporter{}).Export(context.Background(), result, out); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	imported, err := importer.FromListing(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samePaths(t, "flat inventory", listedPaths(imported.Root), want)
+}
+
+func TestCSVInventoryRoundTrip(t *testing.T) {
+	result, want := inventoryFixture()
+	out := filepath.Join(t.TempDir(), "inventory.csv")
+	if err := (&CSVExporter{}).Export(context.Background(), result, out); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, record := range records[1:] {
+		got = append(got, record[0])
+	}
+	sort.Strings(got)
+	samePaths(t, "CSV inventory", got, want)
+}
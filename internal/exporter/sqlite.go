@@ -0,0 +1,216 @@
+//go:build !js
+
+package exporter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // pure-Go driver registered as "sqlite"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+func init() {
+	capability.Register(capability.SQLiteExport)
+}
+
+// sqliteBatchSize is the number of rows inserted per transaction.
+const sqliteBatchSize = 5000
+
+const sqliteSchema = `
+CREATE TABLE scans (
+	id         INTEGER PRIMARY KEY,
+	root_path  TEXT NOT NULL,
+	scanned_at TEXT,
+	node_count INTEGER NOT NULL
+);
+CREATE TABLE nodes (
+	id        INTEGER PRIMARY KEY,
+	scan_id   INTEGER NOT NULL REFERENCES scans(id),
+	parent_id INTEGER REFERENCES nodes(id),
+	name      TEXT NOT NULL,
+	path      TEXT NOT NULL,
+	is_dir    INTEGER NOT NULL,
+	size      INTEGER,
+	mtime     TEXT,
+	depth     INTEGER NOT NULL
+);
+CREATE INDEX idx_nodes_parent_id ON nodes(parent_id);
+CREATE INDEX idx_nodes_path ON nodes(path);
+`
+
+// SQLiteExporter writes a scanned tree into a SQLite database file.
+type SQLiteExporter struct{}
+
+// Export writes result into a new database at path, replacing any existing file once the new one
+// is complete. Sizes are NULL unless the scan collected them, and so are times.
+func (e *SQLiteExporter) Export(ctx context.Context, result *scanner.ScanResult, path string) error {
+	if result == nil || result.Root == nil {
+		return fmt.Errorf("no scan result to export")
+	}
+
+	// The database is written next to path and renamed over it, so a failed export leaves the old file
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", path, err)
+	}
+	tempPath := temp.Name()
+	temp.Chmod(0o644) // CreateTemp makes the file private to the user
+	temp.Close()
+
+	if err := writeDatabase(ctx, result, tempPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to replace %q: %w", path, err)
+	}
+	return nil
+}
+
+// writeDatabase writes result into the empty database file at path.
+func writeDatabase(ctx context.Context, result *scanner.ScanResult, path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open database %q: %w", path, err)
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	const scanID = 1
+	var scannedAt any // NULL for imported trees, which do not say when they were scanned
+	if !result.ScannedAt.IsZero() {
+		scannedAt = result.ScannedAt.UTC().Format(time.RFC3339)
+	}
+	_, err = db.ExecContext(ctx,
+		"INSERT INTO scans (id, root_path, scanned_at, node_count) VALUES (?, ?, ?, ?)",
+		scanID, result.RootPath, scannedAt, result.NodeCount)
+	if err != nil {
+		return fmt.Errorf("failed to write scan metadata: %w", err)
+	}
+
+	writer := &sqliteNodeWriter{ctx: ctx, db: db, scanID: scanID, sizes: result.HasSizes, times: result.HasTimes}
+	if err := writer.writeNode(result.Root, 0, 0); err != nil {
+		writer.rollback()
+		return err
+	}
+	if err := writer.commit(); err != nil {
+		return err
+	}
+	if err := db.Close(); err != nil {
+		return fmt.Errorf("failed to close database %q: %w", path, err)
+	}
+	return nil
+}
+
+// sqliteNodeWriter streams nodes into the database in fixed-size transactions.
+type sqliteNodeWriter struct {
+	ctx    context.Context
+	db     *sql.DB
+	scanID int64
+	sizes  bool // Write sizes; they are NULL when the scan did not collect them
+	times  bool // Write modification times, likewise
+	nextID int64
+	tx     *sql.Tx
+	stmt   *sql.Stmt
+	rows   int
+}
+
+// writeNode inserts node and its descendants, returning the first error encountered.
+func (w *sqliteNodeWriter) writeNode(node *scanner.TreeNode, parentID int64, depth int) error {
+	if err := w.ensureTx(); err != nil {
+		return err
+	}
+
+	w.nextID++
+	id := w.nextID
+
+	var parent any
+	if parentID != 0 {
+		parent = parentID
+	}
+
+	var size, mtime any
+	if w.sizes {
+		size = node.Size
+	}
+	if w.times && !node.ModTime.IsZero() {
+		mtime = node.ModTime.UTC().Format(time.RFC3339Nano)
+	}
+
+	_, err := w.stmt.ExecContext(w.ctx, id, w.scanID, parent, node.Name, node.Path, node.IsDir, size, mtime, depth)
+	if err != nil {
+		return fmt.Errorf("failed to insert %q: %w", node.Path, err)
+	}
+
+	w.rows++
+	if w.rows >= sqliteBatchSize {
+		if err := w.commit(); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range node.Children {
+		if err := w.writeNode(child, id, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureTx starts a new transaction and prepared statement if none is open.
+func (w *sqliteNodeWriter) ensureTx() error {
+	if w.tx != nil {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(w.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	stmt, err := tx.PrepareContext(w.ctx,
+		"INSERT INTO nodes (id, scan_id, parent_id, name, path, is_dir, size, mtime, depth) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+
+	w.tx = tx
+	w.stmt = stmt
+	w.rows = 0
+	return nil
+}
+
+// commit commits the open transaction, if any.
+func (w *sqliteNodeWriter) commit() error {
+	if w.tx == nil {
+		return nil
+	}
+	w.stmt.Close()
+	err := w.tx.Commit()
+	w.tx, w.stmt = nil, nil
+	if err != nil {
+		return fmt.Errorf("failed to commit nodes: %w", err)
+	}
+	return nil
+}
+
+// rollback aborts the open transaction, if any.
+func (w *sqliteNodeWriter) rollback() {
+	if w.tx == nil {
+		return
+	}
+	w.stmt.Close()
+	w.tx.Rollback()
+	w.tx, w.stmt = nil, nil
+}
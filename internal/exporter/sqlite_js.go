@@ -0,0 +1,18 @@
+//go:build js
+
+package exporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// SQLiteExporter is unavailable in web builds because the SQLite driver does not support js/wasm.
+type SQLiteExporter struct{}
+
+// Export always fails in web builds.
+func (e *SQLiteExporter) Export(ctx context.Context, result *scanner.ScanResult, path string) error {
+	return fmt.Errorf("SQLite export is not supported on this platform")
+}
@@ -0,0 +1,265 @@
+//go:build !js
+
+package exporter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// sqliteFixture returns a small result with sizes and times, including an empty directory and an
+// empty file.
+func sqliteFixture() *scanner.ScanResult {
+	stamp := time.Date(2024, 3, 1, 12, 30, 45, 500, time.UTC)
+	root := &scanner.TreeNode{Name: "root", Path: "/data/root", IsDir: true}
+	add := func(parent *scanner.TreeNode, name string, dir bool, size int64) *scanner.TreeNode {
+		node := &scanner.TreeNode{
+			Name:    name,
+			Path:    parent.Path + "/" + name,
+			IsDir:   dir,
+			Size:    size,
+			ModTime: stamp.Add(time.Duration(size) * time.Second),
+			Parent:  parent,
+		}
+		parent.Children = append(parent.Children, node)
+		return node
+	}
+	src := add(root, "src", true, 0)
+	add(src, "main.go", false, 120)
+	add(src, "empty.txt", false, 0)
+	add(root, "empty", true, 0)
+	add(root, "README.md", false, 42)
+	src.Size = 120
+	root.Size = 162
+	root.ModTime = stamp
+
+	return &scanner.ScanResult{
+		Root:      root,
+		RootPath:  root.Path,
+		NodeCount: 6,
+		ScannedAt: time.Date(2024, 3, 2, 9, 15, 0, 0, time.FixedZone("CET", 3600)),
+		HasSizes:  true,
+		HasTimes:  true,
+	}
+}
+
+// sqliteRow is a row of the nodes table.
+type sqliteRow struct {
+	id, parent int64
+	name, path string
+	isDir      bool
+	size       sql.NullInt64
+	mtime      sql.NullString
+	depth      int
+}
+
+// readSQLiteTree rebuilds the tree stored at path, checking each node's depth on the way.
+func readSQLiteTree(t *testing.T, path string) *scanner.TreeNode {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, COALESCE(parent_id, 0), name, path, is_dir, size, mtime, depth FROM nodes ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	nodes := make(map[int64]*scanner.TreeNode)
+	depths := make(map[int64]int)
+	var root *scanner.TreeNode
+	for rows.Next() {
+		var row sqliteRow
+		if err := rows.Scan(&row.id, &row.parent, &row.name, &row.path, &row.isDir, &row.size, &row.mtime, &row.depth); err != nil {
+			t.Fatal(err)
+		}
+		node := &scanner.TreeNode{Name: row.name, Path: row.path, IsDir: row.isDir, Size: row.size.Int64}
+		if !row.size.Valid {
+			node.Size = -1
+		}
+		if row.mtime.Valid {
+			if node.ModTime, err = time.Parse(time.RFC3339Nano, row.mtime.String); err != nil {
+				t.Fatalf("mtime of %s: %v", row.path, err)
+			}
+		}
+		nodes[row.id], depths[row.id] = node, row.depth
+		if row.parent == 0 {
+			if root != nil {
+				t.Fatalf("second root %s", row.path)
+			}
+			root = node
+			continue
+		}
+		parent, ok := nodes[row.parent]
+		if !ok {
+			t.Fatalf("%s: parent %d not written before it", row.path, row.parent)
+		}
+		if depths[row.id] != depths[row.parent]+1 {
+			t.Errorf("%s: depth %d under parent of depth %d", row.path, row.depth, depths[row.parent])
+		}
+		node.Parent = parent
+		parent.Children = append(parent.Children, node)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if root == nil {
+		t.Fatal("no root node")
+	}
+	return root
+}
+
+// compareTrees reports where got differs from want.
+func compareTrees(t *testing.T, got, want *scanner.TreeNode) {
+	t.Helper()
+	if got.Name != want.Name || got.Path != want.Path || got.IsDir != want.IsDir {
+		t.Errorf("got %q (%s, dir %v), want %q (%s, dir %v)", got.Name, got.Path, got.IsDir, want.Name, want.Path, want.IsDir)
+	}
+	if got.Size != want.Size {
+		t.Errorf("%s: size %d, want %d", want.Path, got.Size, want.Size)
+	}
+	if !got.ModTime.Equal(want.ModTime) {
+		t.Errorf("%s: mtime %v, want %v", want.Path, got.ModTime, want.ModTime)
+	}
+	if len(got.Children) != len(want.Children) {
+		t.Fatalf("%s: %d children, want %d", want.Path, len(got.Children), len(want.Children))
+	}
+	for i := range want.Children {
+		compareTrees(t, got.Children[i], want.Children[i])
+	}
+}
+
+func TestSQLiteExportRoundTrip(t *testing.T) {
+	result := sqliteFixture()
+	path := filepath.Join(t.TempDir(), "tree.sqlite")
+	if err := (&SQLiteExporter{}).Export(context.Background(), result, path); err != nil {
+		t.Fatal(err)
+	}
+	compareTrees(t, readSQLiteTree(t, path), result.Root)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var rootPath, scannedAt string
+	var count int
+	if err := db.QueryRow("SELECT root_path, scanned_at, node_count FROM scans").Scan(&rootPath, &scannedAt, &count); err != nil {
+		t.Fatal(err)
+	}
+	if rootPath != result.RootPath || count != result.NodeCount {
+		t.Errorf("scan row (%q, %d), want (%q, %d)", rootPath, count, result.RootPath, result.NodeCount)
+	}
+	if want := "2024-03-02T08:15:00Z"; scannedAt != want {
+		t.Errorf("scanned at %q, want the scan's time %q", scannedAt, want)
+	}
+}
+
+func TestSQLiteExportWithoutScanTime(t *testing.T) {
+	result := sqliteFixture()
+	result.ScannedAt = time.Time{} // As for an imported tree
+	path := filepath.Join(t.TempDir(), "tree.db")
+	if err := (&SQLiteExporter{}).Export(context.Background(), result, path); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var scannedAt sql.NullString
+	if err := db.QueryRow("SELECT scanned_at FROM scans").Scan(&scannedAt); err != nil {
+		t.Fatal(err)
+	}
+	if scannedAt.Valid {
+		t.Errorf("scanned at %q, want NULL for a result without a scan time", scannedAt.String)
+	}
+}
+
+func TestSQLiteExportWithoutSizesOrTimes(t *testing.T) {
+	result := sqliteFixture()
+	result.HasSizes, result.HasTimes = false, false
+	path := filepath.Join(t.TempDir(), "tree.db")
+	if err := (&SQLiteExporter{}).Export(context.Background(), result, path); err != nil {
+		t.Fatal(err)
+	}
+
+	var walk func(node *scanner.TreeNode)
+	walk = func(node *scanner.TreeNode) {
+		if node.Size != -1 {
+			t.Errorf("%s: size %d, want NULL", node.Path, node.Size)
+		}
+		if !node.ModTime.IsZero() {
+			t.Errorf("%s: mtime %v, want NULL", node.Path, node.ModTime)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(readSQLiteTree(t, path))
+}
+
+func TestSQLiteExportLargeTreeSpansBatches(t *testing.T) {
+	root := &scanner.TreeNode{Name: "root", Path: "/root", IsDir: true}
+	for i := 0; i < sqliteBatchSize*2+17; i++ {
+		name := fmt.Sprintf("f%05d", i)
+		root.Children = append(root.Children, &scanner.TreeNode{Name: name, Path: "/root/" + name, Parent: root})
+	}
+	result := &scanner.ScanResult{Root: root, RootPath: root.Path, NodeCount: len(root.Children) + 1}
+	path := filepath.Join(t.TempDir(), "large.sqlite")
+	if err := (&SQLiteExporter{}).Export(context.Background(), result, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := readSQLiteTree(t, path); len(got.Children) != len(root.Children) {
+		t.Errorf("%d children read back, want %d", len(got.Children), len(root.Children))
+	}
+}
+
+func TestSQLiteExportFailureKeepsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tree.sqlite")
+	if err := os.WriteFile(path, []byte("previous export"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := (&SQLiteExporter{}).Export(ctx, sqliteFixture(), path); err == nil {
+		t.Fatal("export with a cancelled context succeeded")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "previous export" {
+		t.Errorf("existing file = %q, %v; want it untouched", data, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("%d files left in the folder, want only the existing export", len(entries))
+	}
+}
+
+func TestSQLiteExportReplacesExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.sqlite")
+	if err := os.WriteFile(path, []byte("previous export"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result := sqliteFixture()
+	if err := (&SQLiteExporter{}).Export(context.Background(), result, path); err != nil {
+		t.Fatal(err)
+	}
+	compareTrees(t, readSQLiteTree(t, path), result.Root)
+}
@@ -0,0 +1,123 @@
+// Package fingerprint computes order-independent hashes of scanned trees, so two folders can be
+// compared for identical structure without a full diff.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Version identifies the hashing algorithm. It is part of every fingerprint, and fingerprints of
+// different versions are reported as incomparable rather than different.
+const Version = 1
+
+// Modes of a fingerprint: structure covers names and types, sizes also covers file sizes.
+const (
+	ModeStructure = "structure"
+	ModeSizes     = "sizes"
+)
+
+// Fingerprint is a parsed fingerprint string such as "fts1-structure-<hex>".
+type Fingerprint struct {
+	Version int
+	Mode    string
+	Hash    string
+}
+
+// String formats the fingerprint for display and pasting.
+func (f Fingerprint) String() string {
+	return fmt.Sprintf("fts%d-%s-%s", f.Version, f.Mode, f.Hash)
+}
+
+// Compute returns the fingerprint of the tree below root, including apparent file sizes when
+// sizes is set. The root's own name is not hashed, so renamed or moved copies still match.
+func Compute(root *scanner.TreeNode, sizes bool) Fingerprint {
+	mode := ModeStructure
+	if sizes {
+		mode = ModeSizes
+	}
+	sum := hashChildren(root, sizes)
+	return Fingerprint{Version: Version, Mode: mode, Hash: hex.EncodeToString(sum[:])}
+}
+
+// Parse reads a fingerprint string, ignoring surrounding whitespace.
+func Parse(text string) (Fingerprint, error) {
+	text = strings.TrimSpace(text)
+	parts := strings.Split(text, "-")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "fts") {
+		return Fingerprint{}, fmt.Errorf("%q is not a fingerprint", text)
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(parts[0], "fts"))
+	if err != nil {
+		return Fingerprint{}, fmt.Errorf("%q is not a fingerprint: bad version", text)
+	}
+	if parts[1] != ModeStructure && parts[1] != ModeSizes {
+		return Fingerprint{}, fmt.Errorf("%q is not a fingerprint: unknown mode %q", text, parts[1])
+	}
+	if _, err := hex.DecodeString(parts[2]); err != nil || len(parts[2]) != 2*sha256.Size {
+		return Fingerprint{}, fmt.Errorf("%q is not a fingerprint: bad hash", text)
+	}
+	return Fingerprint{Version: version, Mode: parts[1], Hash: strings.ToLower(parts[2])}, nil
+}
+
+// Comparable returns an error explaining why f and other cannot be compared, or nil.
+func (f Fingerprint) Comparable(other Fingerprint) error {
+	if f.Version != other.Version {
+		return fmt.Errorf("fingerprints use algorithm versions %d and %d; recompute both with the same version", f.Version, other.Version)
+	}
+	if f.Mode != other.Mode {
+		return fmt.Errorf("fingerprints cover %s and %s; recompute both with sizes collected or both without", f.Mode, other.Mode)
+	}
+	return nil
+}
+
+// hashNode hashes a node's type, name, size and children.
+func hashNode(node *scanner.TreeNode, sizes bool) [sha256.Size]byte {
+	h := sha256.New()
+	switch {
+	case node.IsDir:
+		h.Write([]byte("d"))
+	case node.IsSymlink:
+		h.Write([]byte("l"))
+	default:
+		h.Write([]byte("f"))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(node.Name))
+	h.Write([]byte{0})
+	if sizes && !node.IsDir {
+		if node.SizeUnknown {
+			h.Write([]byte("?"))
+		} else {
+			h.Write([]byte(strconv.FormatInt(node.Size, 10)))
+		}
+	}
+	h.Write([]byte{0})
+	children := hashChildren(node, sizes)
+	h.Write(children[:])
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// hashChildren hashes node's children in name order, independent of scan order.
+func hashChildren(node *scanner.TreeNode, sizes bool) [sha256.Size]byte {
+	children := append([]*scanner.TreeNode(nil), node.Children...)
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+
+	h := sha256.New()
+	for _, child := range children {
+		sum := hashNode(child, sizes)
+		h.Write(sum[:])
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
@@ -0,0 +1,313 @@
+// Package folderopts keeps scan options saved for particular folders, such as unlimited depth for
+// a photo archive or extra excludes for a monorepo. Whenever a saved folder is scanned, from the
+// GUI or the command line, its options are merged over the settings in effect.
+//
+// Merging is explicit: an option saved for the folder wins over the one in effect, and an option
+// left unset keeps it. A saved pattern list either replaces the list in effect, which an empty
+// list clears, or is appended to it, skipping patterns already there.
+package folderopts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+// fileName is the file in the per-user configuration directory holding the saved options.
+const fileName = "folder-options.json"
+
+// ListMode says how a saved pattern list combines with the list in effect.
+type ListMode string
+
+// Ways a saved pattern list is merged.
+const (
+	Replace ListMode = "replace" // The saved patterns are used instead; the default
+	Append  ListMode = "append"  // The saved patterns follow those in effect
+)
+
+// Patterns is a saved pattern list with how it merges.
+type Patterns struct {
+	Mode     ListMode `json:"mode"`
+	Patterns []string `json:"patterns"`
+}
+
+// Options are the scan options saved for a folder. Nil fields keep the option in effect.
+type Options struct {
+	MaxDepth         *int      `json:"maxDepth,omitempty"` // -1 for unlimited
+	MaxNodes         *int      `json:"maxNodes,omitempty"` // 0 for no limit
+	ShowSize         *bool     `json:"showSize,omitempty"`
+	CollectTimes     *bool     `json:"collectTimes,omitempty"`
+	CountLines       *bool     `json:"countLines,omitempty"`
+	EstimateTokens   *bool     `json:"estimateTokens,omitempty"`
+	ComputeHashes    *bool     `json:"computeHashes,omitempty"`
+	RespectGitignore *bool     `json:"respectGitignore,omitempty"`
+	FollowSymlinks   *bool     `json:"followSymlinks,omitempty"`
+	OneFileSystem    *bool     `json:"oneFileSystem,omitempty"`
+	PruneEmptyDirs   *bool     `json:"pruneEmptyDirs,omitempty"`
+	Exclude          *Patterns `json:"exclude,omitempty"`
+	Include          *Patterns `json:"include,omitempty"`
+}
+
+// Folder is a folder with saved options.
+type Folder struct {
+	Path    string    `json:"path"` // Absolute path, as saved; the key is derived from it
+	Saved   time.Time `json:"saved"`
+	Options Options   `json:"options"`
+}
+
+// file is the layout of the options file.
+type file struct {
+	Version int               `json:"version"`
+	Folders map[string]Folder `json:"folders"` // By Key of the folder's path
+}
+
+// fileVersion is the version of the options file written.
+const fileVersion = 1
+
+// Apply merges o over cfg: set options win and unset ones keep cfg's. Pattern lists are
+// replaced or appended to by their mode, without changing the slices cfg held.
+func (o Options) Apply(cfg *config.Config) {
+	setInt(&cfg.MaxDepth, o.MaxDepth)
+	setInt(&cfg.MaxNodes, o.MaxNodes)
+	setBool(&cfg.ShowSize, o.ShowSize)
+	setBool(&cfg.CollectTimes, o.CollectTimes)
+	setBool(&cfg.CountLines, o.CountLines)
+	setBool(&cfg.EstimateTokens, o.EstimateTokens)
+	setBool(&cfg.ComputeHashes, o.ComputeHashes)
+	setBool(&cfg.RespectGitignore, o.RespectGitignore)
+	setBool(&cfg.FollowSymlinks, o.FollowSymlinks)
+	setBool(&cfg.OneFileSystem, o.OneFileSystem)
+	setBool(&cfg.PruneEmptyDirs, o.PruneEmptyDirs)
+	cfg.ExcludePatterns = o.Exclude.merge(cfg.ExcludePatterns)
+	cfg.IncludePatterns = o.Include.merge(cfg.IncludePatterns)
+}
+
+// merge returns the patterns in effect combined with p; a nil p keeps them.
+func (p *Patterns) merge(current []string) []string {
+	if p == nil {
+		return current
+	}
+	if p.Mode != Append {
+		return slices.Clone(p.Patterns)
+	}
+	merged := slices.Clone(current)
+	for _, pattern := range p.Patterns {
+		if !slices.Contains(merged, pattern) {
+			merged = append(merged, pattern)
+		}
+	}
+	return merged
+}
+
+// setInt sets *dst to *v when v is set.
+func setInt(dst *int, v *int) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// setBool sets *dst to *v when v is set.
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// Empty reports whether o sets no option.
+func (o Options) Empty() bool {
+	return o == Options{}
+}
+
+// Summary describes the options o sets, like "depth unlimited, sizes on, exclude + node_modules".
+func (o Options) Summary() string {
+	var parts []string
+	if o.MaxDepth != nil {
+		if *o.MaxDepth < 0 {
+			parts = append(parts, "depth unlimited")
+		} else {
+			parts = append(parts, fmt.Sprintf("depth %d", *o.MaxDepth))
+		}
+	}
+	if o.MaxNodes != nil {
+		if *o.MaxNodes == 0 {
+			parts = append(parts, "no item limit")
+		} else {
+			parts = append(parts, fmt.Sprintf("at most %d items", *o.MaxNodes))
+		}
+	}
+	for _, flag := range []struct {
+		name  string
+		value *bool
+	}{
+		{"sizes", o.ShowSize}, {"dates", o.CollectTimes}, {"lines", o.CountLines},
+		{"tokens", o.EstimateTokens}, {"duplicates", o.ComputeHashes}, {".gitignore", o.RespectGitignore},
+		{"follow links", o.FollowSymlinks}, {"one filesystem", o.OneFileSystem}, {"prune empty", o.PruneEmptyDirs},
+	} {
+		if flag.value != nil {
+			state := "off"
+			if *flag.value {
+				state = "on"
+			}
+			parts = append(parts, flag.name+" "+state)
+		}
+	}
+	parts = append(parts, o.Exclude.summary("exclude")...)
+	parts = append(parts, o.Include.summary("include")...)
+	return strings.Join(parts, ", ")
+}
+
+// summary describes p as the list named name, or nothing for a nil p.
+func (p *Patterns) summary(name string) []string {
+	switch {
+	case p == nil:
+		return nil
+	case p.Mode == Append:
+		return []string{name + " + " + strings.Join(p.Patterns, " ")}
+	case len(p.Patterns) == 0:
+		return []string{name + " cleared"}
+	default:
+		return []string{name + " = " + strings.Join(p.Patterns, " ")}
+	}
+}
+
+// Key returns the key the options of the folder at path are saved under: the hex SHA-256 of its
+// absolute, cleaned path, lower-cased on Windows, where paths ignore case.
+func Key(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	if runtime.GOOS == "windows" {
+		abs = strings.ToLower(abs)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Store is a file of saved folder options. Its methods are safe for concurrent use within the
+// process; each call reads the file anew, so changes made by other processes are seen.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns the store kept in the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultStore returns the per-user store shared by the GUI and the command line.
+func DefaultStore() (*Store, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return NewStore(filepath.Join(dir, "file-tree-scanner", fileName)), nil
+}
+
+// Lookup returns the options saved for the folder at path, or nil if there are none.
+func (s *Store) Lookup(path string) (*Folder, error) {
+	key, err := Key(path)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	folder, ok := f.Folders[key]
+	if !ok {
+		return nil, nil
+	}
+	return &folder, nil
+}
+
+// Save saves opts for the folder at path, replacing what was saved for it; empty options remove it.
+func (s *Store) Save(path string, opts Options) error {
+	key, err := Key(path)
+	if err != nil {
+		return err
+	}
+	abs, _ := filepath.Abs(path) // Cannot fail once Key did
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	if opts.Empty() {
+		delete(f.Folders, key)
+	} else {
+		f.Folders[key] = Folder{Path: abs, Saved: time.Now().UTC(), Options: opts}
+	}
+	return s.write(f)
+}
+
+// Remove forgets the options saved for the folder at path.
+func (s *Store) Remove(path string) error {
+	return s.Save(path, Options{})
+}
+
+// read loads the file, which holds no folders until it is first written. Called with mu held.
+func (s *Store) read() (*file, error) {
+	f := &file{Version: fileVersion, Folders: make(map[string]Folder)}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read folder options: %w", err)
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to read folder options from %s: %w", s.path, err)
+	}
+	if f.Version > fileVersion {
+		return nil, fmt.Errorf("folder options in %s are from a newer version (%d)", s.path, f.Version)
+	}
+	if f.Folders == nil {
+		f.Folders = make(map[string]Folder)
+	}
+	return f, nil
+}
+
+// write saves f, replacing the file at once so a crash cannot leave it half written. Called with
+// mu held.
+func (s *Store) write(f *file) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode folder options: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save folder options: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(s.path), ".folder-options-*")
+	if err != nil {
+		return fmt.Errorf("failed to save folder options: %w", err)
+	}
+	defer os.Remove(temp.Name()) // Fails once renamed
+	if _, err := temp.Write(append(data, '\n')); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to save folder options: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to save folder options: %w", err)
+	}
+	if err := os.Rename(temp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save folder options: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package folderopts
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		exclude []string // Exclude patterns after the merge
+		depth   int
+		sizes   bool
+	}{
+		{"unset keeps", Options{}, []string{"*.log", "build"}, 3, true},
+		{"override wins", Options{MaxDepth: ptr(-1), ShowSize: ptr(false)}, []string{"*.log", "build"}, -1, false},
+		{"zero values win", Options{MaxDepth: ptr(0)}, []string{"*.log", "build"}, 0, true},
+		{"replace", Options{Exclude: &Patterns{Mode: Replace, Patterns: []string{"node_modules"}}}, []string{"node_modules"}, 3, true},
+		{"no mode replaces", Options{Exclude: &Patterns{Patterns: []string{"dist"}}}, []string{"dist"}, 3, true},
+		{"empty replace clears", Options{Exclude: &Patterns{Mode: Replace}}, []string{}, 3, true},
+		{"append", Options{Exclude: &Patterns{Mode: Append, Patterns: []string{"dist"}}}, []string{"*.log", "build", "dist"}, 3, true},
+		{"append skips duplicates", Options{Exclude: &Patterns{Mode: Append, Patterns: []string{"build", "dist", "dist"}}}, []string{"*.log", "build", "dist"}, 3, true},
+		{"empty append keeps", Options{Exclude: &Patterns{Mode: Append}}, []string{"*.log", "build"}, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.MaxDepth, cfg.ShowSize = 3, true
+			current := []string{"*.log", "build"}
+			cfg.ExcludePatterns = current
+			tt.opts.Apply(cfg)
+
+			if !slices.Equal(cfg.ExcludePatterns, tt.exclude) {
+				t.Errorf("exclude %q, want %q", cfg.ExcludePatterns, tt.exclude)
+			}
+			if cfg.MaxDepth != tt.depth || cfg.ShowSize != tt.sizes {
+				t.Errorf("depth %d and sizes %v, want %d and %v", cfg.MaxDepth, cfg.ShowSize, tt.depth, tt.sizes)
+			}
+			if !slices.Equal(current, []string{"*.log", "build"}) {
+				t.Errorf("merge changed the slice in effect to %q", current)
+			}
+			if cfg.IncludePatterns != nil {
+				t.Errorf("unset include list became %q", cfg.IncludePatterns)
+			}
+		})
+	}
+}
+
+func TestApplyAppendDoesNotAlias(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ExcludePatterns = make([]string, 1, 10)
+	cfg.ExcludePatterns[0] = "a"
+	held := cfg.ExcludePatterns
+	Options{Exclude: &Patterns{Mode: Append, Patterns: []string{"b"}}}.Apply(cfg)
+	if held = held[:2]; held[1] == "b" {
+		t.Error("appending wrote into the spare capacity of the slice in effect")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	opts := Options{
+		MaxDepth: ptr(-1),
+		ShowSize: ptr(true),
+		Exclude:  &Patterns{Mode: Append, Patterns: []string{"node_modules"}},
+		Include:  &Patterns{Mode: Replace},
+	}
+	if got, want := opts.Summary(), "depth unlimited, sizes on, exclude + node_modules, include cleared"; got != want {
+		t.Errorf("summary %q, want %q", got, want)
+	}
+	if !(Options{}).Empty() || opts.Empty() {
+		t.Error("Empty disagrees with the options set")
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", fileName))
+	dir := t.TempDir()
+	if folder, err := store.Lookup(dir); err != nil || folder != nil {
+		t.Fatalf("lookup before saving returned %v, %v", folder, err)
+	}
+	if err := store.Save(dir, Options{MaxDepth: ptr(7)}); err != nil {
+		t.Fatal(err)
+	}
+	folder, err := store.Lookup(dir)
+	if err != nil || folder == nil || folder.Options.MaxDepth == nil || *folder.Options.MaxDepth != 7 {
+		t.Fatalf("lookup after saving returned %+v, %v", folder, err)
+	}
+	if err := store.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if folder, err := store.Lookup(dir); err != nil || folder != nil {
+		t.Errorf("lookup after removing returned %v, %v", folder, err)
+	}
+}
@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+func init() {
+	capability.Register(capability.Archives)
+}
+
+// archiveSuffixes are the file name endings FromArchive can read.
+var archiveSuffixes = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+// IsArchive reports whether path names an archive FromArchive can read, judging by its name.
+func IsArchive(path string) bool {
+	name := strings.ToLower(path)
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveEntry is one member of an archive.
+type archiveEntry struct {
+	name       string // Slash-separated path inside the archive
+	isDir      bool
+	size       int64
+	modTime    time.Time
+	linkTarget string // Target of a symbolic link, "" for other entries
+}
+
+// FromArchive builds a virtual tree from the entries of a zip or tar archive, optionally
+// gzip-compressed, without extracting it. The archive is the root; directories implied by entry
+// paths are created, and entries climbing out of the archive with ".." are skipped. Every other
+// member is listed, as scan filters do not apply. When ctx is cancelled or times out, the entries
+// read until then are returned along with the error, marked Partial and Truncated with the
+// reason taken from the context, as a scan does.
+func FromArchive(ctx context.Context, path string) (*scanner.ScanResult, error) {
+	builder := NewTreeBuilder()
+	scannedAt := time.Now()
+	add := func(entry archiveEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		parts := strings.Split(entry.name, "/")
+		for _, part := range parts {
+			if part == ".." {
+				return nil
+			}
+		}
+		node := builder.insert(parts, entry.isDir)
+		if node == nil {
+			return nil
+		}
+		node.ModTime = entry.modTime
+		if !node.IsDir {
+			node.Size, node.DiskSize = entry.size, entry.size
+		}
+		if entry.linkTarget != "" {
+			node.IsSymlink, node.LinkTarget = true, entry.linkTarget
+		}
+		return nil
+	}
+
+	var err error
+	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+		err = readZip(path, add)
+	} else {
+		err = readTar(path, add)
+	}
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	root := builder.root
+	root.Path = path
+	root.Name = filepath.Base(path)
+	assignFilePaths(root)
+	scanner.SortTree(root)
+	sumDirSizes(root)
+
+	result := &scanner.ScanResult{
+		RootPath:  path,
+		NodeCount: builder.count + 1,
+		Root:      root,
+		ScannedAt: scannedAt,
+		Skipped:   make(scanner.SkipStats),
+		HasSizes:  true,
+		HasTimes:  true,
+		TotalSize: root.Size,
+	}
+	scanner.Tally(result)
+	if err != nil {
+		stop := scanner.StopCause(ctx)
+		result.Error = err
+		result.Partial = true
+		result.CountsPartial = true
+		result.Truncated = true
+		result.TruncatedReason, result.TruncatedLimit = stop.Reason, stop.Limit
+		return result, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return result, nil
+}
+
+// readZip passes each member of the zip archive at path to add.
+func readZip(path string, add func(archiveEntry) error) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %q: %w", path, err)
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		info := file.FileInfo()
+		entry := archiveEntry{
+			name:    file.Name,
+			isDir:   info.IsDir(),
+			size:    int64(file.UncompressedSize64),
+			modTime: file.Modified,
+		}
+		if err := add(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTar passes each member of the tar archive at path, gzip-compressed when its name says so,
+// to add.
+func readTar(path string, add func(archiveEntry) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %q: %w", path, err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if name := strings.ToLower(path); strings.HasSuffix(name, ".gz") || strings.HasSuffix(name, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to decompress archive %q: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive %q: %w", path, err)
+		}
+		entry := archiveEntry{name: header.Name, size: header.Size, modTime: header.ModTime}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			entry.isDir = true
+		case tar.TypeSymlink:
+			entry.linkTarget = header.Linkname
+		case tar.TypeXGlobalHeader:
+			continue // Metadata, not a member
+		}
+		if err := add(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// assignFilePaths sets Path on every descendant of node, joining names with the OS separator
+// below the archive's own path.
+func assignFilePaths(node *scanner.TreeNode) {
+	for _, child := range node.Children {
+		child.Path = filepath.Join(node.Path, child.Name)
+		assignFilePaths(child)
+	}
+}
+
+// sumDirSizes sets the sizes of every directory below and including node to the total of its
+// files, returning node's size.
+func sumDirSizes(node *scanner.TreeNode) int64 {
+	if !node.IsDir {
+		return node.Size
+	}
+	node.Size = 0
+	for _, child := range node.Children {
+		node.Size += sumDirSizes(child)
+	}
+	node.DiskSize = node.Size
+	return node.Size
+}
@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// archiveMembers are the members written to test archives, with their contents.
+var archiveMembers = []struct{ name, data string }{
+	{"project/", ""},
+	{"project/README.md", "# Project\n"},
+	{"project/src/main.go", "package main\n"},
+	{"project/src/util/strings.go", "package util\n"},
+	{"../escape.txt", "outside"},
+}
+
+// writeZip writes archiveMembers to a zip file in dir.
+func writeZip(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "project.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	w := zip.NewWriter(file)
+	for _, member := range archiveMembers {
+		f, err := w.Create(member.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(member.data))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeTarGz writes archiveMembers to a gzip-compressed tar file in dir.
+func writeTarGz(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "project.tar.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	w := tar.NewWriter(gz)
+	for _, member := range archiveMembers {
+		header := &tar.Header{Name: member.name, Mode: 0o644, Size: int64(len(member.data)), ModTime: time.Unix(1700000000, 0)}
+		if strings.HasSuffix(member.name, "/") {
+			header.Typeflag, header.Mode = tar.TypeDir, 0o755
+		}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(member.data))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFromArchive(t *testing.T) {
+	for name, write := range map[string]func(*testing.T, string) string{"zip": writeZip, "tar.gz": writeTarGz} {
+		t.Run(name, func(t *testing.T) {
+			path := write(t, t.TempDir())
+			result, err := FromArchive(context.Background(), path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.DirCount != 3 || result.FileCount != 3 {
+				t.Errorf("%d directories, %d files; want 3, 3 without the entry climbing out", result.DirCount, result.FileCount)
+			}
+			if result.Partial || result.Truncated {
+				t.Error("a complete archive is marked partial")
+			}
+			if result.TotalSize != int64(len("# Project\n")+len("package main\n")+len("package util\n")) {
+				t.Errorf("total size %d", result.TotalSize)
+			}
+		})
+	}
+}
+
+func TestFromArchiveOutOfTime(t *testing.T) {
+	path := writeZip(t, t.TempDir())
+	ctx, cancel := context.WithTimeoutCause(context.Background(), time.Nanosecond, scanner.Stop(scanner.ReasonTimeout, "1ns"))
+	defer cancel()
+	<-ctx.Done()
+
+	result, err := FromArchive(ctx, path)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want the deadline", err)
+	}
+	if result == nil || result.Root == nil {
+		t.Fatal("no partial result")
+	}
+	if !result.Partial || !result.Truncated || result.TruncatedReason != scanner.ReasonTimeout {
+		t.Errorf("partial %v, truncated %v (%q); want a partial result cut by the timeout", result.Partial, result.Truncated, result.TruncatedReason)
+	}
+}
+
+func TestFromArchiveUnreadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.zip")
+	if err := os.WriteFile(path, []byte("not a zip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := FromArchive(context.Background(), path); err == nil || result != nil {
+		t.Errorf("FromArchive of a broken archive = %v, %v; want only an error", result, err)
+	}
+}
@@ -0,0 +1,166 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// listingRootName is shown when the listed paths share no common directory.
+const listingRootName = "Imported listing"
+
+// FromListing builds a virtual tree from newline-separated paths such as `find` output.
+// Both '/' and '\' are accepted as separators, duplicate lines are ignored and the
+// deepest directory shared by all paths becomes the root.
+func FromListing(r io.Reader) (*scanner.ScanResult, error) {
+	builder := NewTreeBuilder()
+
+	lines := bufio.NewScanner(r)
+	lines.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lines.Scan() {
+		builder.Add(lines.Text())
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read listing: %w", err)
+	}
+
+	result := builder.Result()
+	if result == nil {
+		return nil, fmt.Errorf("listing contains no paths")
+	}
+	return result, nil
+}
+
+// TreeBuilder assembles a virtual tree from individual paths, creating intermediate
+// directories on demand.
+type TreeBuilder struct {
+	root     *scanner.TreeNode
+	index    map[*scanner.TreeNode]map[string]*scanner.TreeNode
+	absolute bool
+	count    int
+}
+
+// NewTreeBuilder creates an empty TreeBuilder.
+func NewTreeBuilder() *TreeBuilder {
+	return &TreeBuilder{
+		root:  &scanner.TreeNode{IsDir: true, IsVirtual: true},
+		index: make(map[*scanner.TreeNode]map[string]*scanner.TreeNode),
+	}
+}
+
+// Add inserts a single path; a trailing separator marks it as a directory. Quoted paths,
+// as written by flat inventories, are unquoted first; they are slash-separated, so a
+// backslash in them is part of a name.
+func (b *TreeBuilder) Add(line string) {
+	line = strings.TrimSpace(line)
+	if unquoted := renderer.UnquoteName(line); unquoted != line {
+		line = unquoted
+	} else {
+		line = strings.ReplaceAll(line, "\\", "/")
+	}
+	if line == "" {
+		return
+	}
+	if strings.HasPrefix(line, "/") {
+		b.absolute = true
+	}
+
+	b.insert(strings.Split(line, "/"), strings.HasSuffix(line, "/"))
+}
+
+// insert adds the path made of parts below the root, creating the directories above it, and
+// returns its node, or nil when parts name no entry. Empty and "." parts are skipped.
+func (b *TreeBuilder) insert(parts []string, isDir bool) *scanner.TreeNode {
+	var names []string
+	for _, part := range parts {
+		if part != "" && part != "." {
+			names = append(names, part)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	node := b.root
+	for i, name := range names {
+		last := i == len(names)-1
+		node = b.child(node, name, !last || isDir)
+	}
+	return node
+}
+
+// child returns the named child of parent, creating it if needed.
+func (b *TreeBuilder) child(parent *scanner.TreeNode, name string, isDir bool) *scanner.TreeNode {
+	children := b.index[parent]
+	if existing, ok := children[name]; ok {
+		if isDir && !existing.IsDir {
+			existing.IsDir, existing.Kind = true, ""
+		}
+		return existing
+	}
+	if children == nil {
+		children = make(map[string]*scanner.TreeNode)
+		b.index[parent] = children
+	}
+
+	node := &scanner.TreeNode{Name: name, IsDir: isDir, IsVirtual: true, Parent: parent}
+	if !isDir {
+		node.Kind = scanner.Classify(name, nil)
+	}
+	parent.Children = append(parent.Children, node)
+	children[name] = node
+	b.count++
+	return node
+}
+
+// Result returns the assembled tree rooted at the common prefix, or nil if nothing was added.
+func (b *TreeBuilder) Result() *scanner.ScanResult {
+	if b.count == 0 {
+		return nil
+	}
+
+	// Descend through the chain of single-directory nodes shared by every path
+	root := b.root
+	var prefix []string
+	for len(root.Children) == 1 && root.Children[0].IsDir {
+		root = root.Children[0]
+		prefix = append(prefix, root.Name)
+	}
+
+	root.Parent = nil
+	root.Path = path.Join(prefix...)
+	if b.absolute {
+		root.Path = "/" + root.Path
+	}
+	if root.Path == "" {
+		root.Path = "."
+	}
+	root.Name = path.Base(root.Path)
+	if len(prefix) == 0 {
+		root.Name = listingRootName
+	}
+
+	assignPaths(root)
+	scanner.SortTree(root)
+
+	result := &scanner.ScanResult{
+		RootPath:  root.Path,
+		NodeCount: b.count - len(prefix) + 1,
+		Root:      root,
+	}
+	scanner.Tally(result)
+	return result
+}
+
+// assignPaths sets Path on every descendant of node from its name and parent path.
+func assignPaths(node *scanner.TreeNode) {
+	for _, child := range node.Children {
+		child.Path = path.Join(node.Path, child.Name)
+		assignPaths(child)
+	}
+}
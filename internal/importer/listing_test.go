@@ -0,0 +1,145 @@
+package importer
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// treeShape lists the paths of every node below root, slash-separated and relative to
+// it, with a trailing '/' on directories.
+func treeShape(root *scanner.TreeNode) []string {
+	var shape []string
+	var walk func(node *scanner.TreeNode, prefix string)
+	walk = func(node *scanner.TreeNode, prefix string) {
+		for _, child := range node.Children {
+			entry := prefix + child.Name
+			if child.IsDir {
+				entry += "/"
+			}
+			shape = append(shape, entry)
+			walk(child, prefix+child.Name+"/")
+		}
+	}
+	walk(root, "")
+	sort.Strings(shape)
+	return shape
+}
+
+func TestFromListing(t *testing.T) {
+	tests := []struct {
+		name     string
+		listing  string
+		rootPath string
+		rootName string
+		shape    []string
+	}{
+		{
+			name:     "find output",
+			listing:  ".\n./src\n./src/main.go\n./src/util/strings.go\n./README.md\n",
+			rootPath: ".", rootName: listingRootName,
+			shape: []string{"README.md", "src/", "src/main.go", "src/util/", "src/util/strings.go"},
+		},
+		{
+			name:     "absolute POSIX paths share a prefix",
+			listing:  "/home/me/project/go.mod\n/home/me/project/cmd/app/main.go\n",
+			rootPath: "/home/me/project", rootName: "project",
+			shape: []string{"cmd/", "cmd/app/", "cmd/app/main.go", "go.mod"},
+		},
+		{
+			name:     "Windows dir /s /b output",
+			listing:  "C:\\Users\\me\\project\\docs\r\nC:\\Users\\me\\project\\docs\\guide.md\r\nC:\\Users\\me\\project\\main.go\r\n",
+			rootPath: "C:/Users/me/project", rootName: "project",
+			shape: []string{"docs/", "docs/guide.md", "main.go"},
+		},
+		{
+			name:     "mixed separators and duplicates",
+			listing:  "project/src\\main.go\nproject\\src/main.go\n  project/src/main.go  \n\nproject/test/\n",
+			rootPath: "project", rootName: "project",
+			shape: []string{"src/", "src/main.go", "test/"},
+		},
+		{
+			name:     "quoted flat inventory paths keep backslashes and edge spaces",
+			listing:  "\"src/back\\\\slash.go\"\nsrc/main.go\n\" lead/trail \"\n",
+			rootPath: ".", rootName: listingRootName,
+			shape: []string{" lead/", " lead/trail ", "src/", "src/back\\slash.go", "src/main.go"},
+		},
+		{
+			name:     "no common directory",
+			listing:  "a.txt\nb/c.txt\n",
+			rootPath: ".", rootName: listingRootName,
+			shape: []string{"a.txt", "b/", "b/c.txt"},
+		},
+		{
+			name:     "file listed before its children",
+			listing:  "pkg/lib\npkg/lib/x.go\npkg/y.go\n",
+			rootPath: "pkg", rootName: "pkg",
+			shape: []string{"lib/", "lib/x.go", "y.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := FromListing(strings.NewReader(tt.listing))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.RootPath != tt.rootPath || result.Root.Name != tt.rootName {
+				t.Errorf("root %q named %q, want %q named %q", result.RootPath, result.Root.Name, tt.rootPath, tt.rootName)
+			}
+			if got := treeShape(result.Root); strings.Join(got, " ") != strings.Join(tt.shape, " ") {
+				t.Errorf("tree %v, want %v", got, tt.shape)
+			}
+
+			var dirs, files int
+			for _, entry := range tt.shape {
+				if strings.HasSuffix(entry, "/") {
+					dirs++
+				} else {
+					files++
+				}
+			}
+			if result.DirCount != dirs || result.FileCount != files || result.NodeCount != len(tt.shape)+1 {
+				t.Errorf("counts %d directories, %d files, %d nodes; want %d, %d, %d",
+					result.DirCount, result.FileCount, result.NodeCount, dirs, files, len(tt.shape)+1)
+			}
+		})
+	}
+}
+
+func TestFromListingNodes(t *testing.T) {
+	result, err := FromListing(strings.NewReader("/srv/app/src/main.go\n/srv/app/README.md\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var walk func(node *scanner.TreeNode, parent *scanner.TreeNode)
+	walk = func(node *scanner.TreeNode, parent *scanner.TreeNode) {
+		if !node.IsVirtual {
+			t.Errorf("%s is not marked virtual", node.Path)
+		}
+		if node.Parent != parent {
+			t.Errorf("%s has the wrong parent", node.Path)
+		}
+		if parent != nil && node.Path != parent.Path+"/"+node.Name {
+			t.Errorf("%s is not below its parent %s", node.Path, parent.Path)
+		}
+		for _, child := range node.Children {
+			walk(child, node)
+		}
+	}
+	walk(result.Root, nil)
+
+	if first := result.Root.Children[0]; !first.IsDir || first.Name != "src" {
+		t.Errorf("first child %q, want the src directory sorted ahead of files", first.Name)
+	}
+}
+
+func TestFromListingEmpty(t *testing.T) {
+	for _, listing := range []string{"", "\n\n", " . \n./\n"} {
+		if _, err := FromListing(strings.NewReader(listing)); err == nil {
+			t.Errorf("listing %q: expected an error", listing)
+		}
+	}
+}
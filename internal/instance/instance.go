@@ -0,0 +1,126 @@
+// Package instance keeps a single GUI instance per user: a second launch hands its folder to the
+// running instance over a local socket and exits.
+package instance
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Flag is the command line flag that starts an independent instance.
+const Flag = "new-instance"
+
+// handOffTimeout bounds connecting to, and talking with, the running instance.
+const handOffTimeout = 2 * time.Second
+
+// accepted is the running instance's reply once it has taken a path.
+const accepted = "ok"
+
+// ErrRunning is returned by Listen when another instance already listens.
+var ErrRunning = errors.New("another instance is running")
+
+// socketPath returns the per-user socket the running instance listens on.
+func socketPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	dir = filepath.Join(dir, "file-tree-scanner")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return filepath.Join(dir, "instance.sock"), nil
+}
+
+// Hand passes path to the running instance, reporting whether one took it. An empty path only
+// brings the running instance to the front.
+func Hand(path string) bool {
+	socket, err := socketPath()
+	if err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("unix", socket, handOffTimeout)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(handOffTimeout))
+
+	if _, err := fmt.Fprintln(conn, strconv.Quote(path)); err != nil {
+		return false
+	}
+	// Wait for the reply, so an instance that is shutting down doesn't swallow the path
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	return err == nil && reply == accepted+"\n"
+}
+
+// Listener receives the paths handed over by later launches.
+type Listener struct {
+	listener net.Listener
+}
+
+// Listen makes this process the running instance, calling open on its own goroutine with each
+// path handed over until the listener is closed. A socket left by a crashed session is removed.
+func Listen(open func(path string)) (*Listener, error) {
+	socket, err := socketPath()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		if conn, dialErr := net.DialTimeout("unix", socket, handOffTimeout); dialErr == nil {
+			conn.Close()
+			return nil, ErrRunning
+		}
+		// Nobody answers, so the socket is stale
+		os.Remove(socket)
+		if listener, err = net.Listen("unix", socket); err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", socket, err)
+		}
+	}
+
+	l := &Listener{listener: listener}
+	go l.serve(open)
+	return l, nil
+}
+
+// Close stops listening and removes the socket.
+func (l *Listener) Close() error {
+	return l.listener.Close()
+}
+
+// serve accepts hand-offs until the listener is closed.
+func (l *Listener) serve(open func(path string)) {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			return
+		}
+		go receive(conn, open)
+	}
+}
+
+// receive reads one handed over path, acknowledges it and opens it.
+func receive(conn net.Conn, open func(path string)) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(handOffTimeout))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+	path, err := strconv.Unquote(line[:len(line)-1])
+	if err != nil {
+		return
+	}
+	if _, err := fmt.Fprintln(conn, accepted); err != nil {
+		return
+	}
+	open(path)
+}
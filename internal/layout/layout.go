@@ -0,0 +1,184 @@
+// Package layout checks a scanned tree against an expected structure, such as the template a
+// family of repositories is meant to follow.
+package layout
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+func init() {
+	capability.Register(capability.LayoutCheck)
+}
+
+// AnyName is the entry that allows anything, files and directories at any depth, in its
+// directory.
+const AnyName = "**"
+
+// Entry is one expected entry of a layout. Its name is either literal or a wildcard in the syntax
+// of path.Match; literal entries must be present unless optional, wildcard entries allow extras
+// and must match at least one entry unless optional.
+type Entry struct {
+	Name     string
+	Dir      bool
+	Optional bool
+	Children []*Entry // Expected contents of a directory; a directory without any is not looked into
+	Line     int      // Line of the entry in a text layout, 0 otherwise
+
+	literal bool // Name is taken literally even if it holds wildcard characters, as in tree files
+}
+
+// Wildcard reports whether the entry's name is a pattern rather than a literal name.
+func (e *Entry) Wildcard() bool {
+	return !e.literal && strings.ContainsAny(e.Name, "*?[")
+}
+
+// display returns the entry's name with "/" after a directory.
+func (e *Entry) display() string {
+	if e.Dir && e.Name != AnyName {
+		return e.Name + "/"
+	}
+	return e.Name
+}
+
+// matches reports whether node is allowed by the wildcard entry e.
+func (e *Entry) matches(node *scanner.TreeNode) bool {
+	if e.Name == AnyName {
+		return true
+	}
+	if node.IsDir != e.Dir {
+		return false
+	}
+	ok, _ := path.Match(e.Name, node.Name) // Patterns are validated when parsed
+	return ok
+}
+
+// Layout is an expected structure: the entries expected in the root directory.
+type Layout struct {
+	Entries []*Entry
+}
+
+// Kind is the kind of a violation.
+type Kind string
+
+// Kinds of violations.
+const (
+	KindMissing    Kind = "missing"
+	KindUnexpected Kind = "unexpected"
+	KindWrongType  Kind = "wrong type"
+	KindUnchecked  Kind = "not checked"
+)
+
+// Violation is one way a tree departs from its layout.
+type Violation struct {
+	Kind   Kind
+	Path   string            // Relative to the root with "/" separators and "/" after directories; for a missing entry, its expected name or pattern
+	Node   *scanner.TreeNode // The offending entry, or the directory a missing entry belongs in
+	Detail string            // What was expected, when the kind alone does not say
+}
+
+// String formats the violation for a report, e.g. "missing: docs/README.md".
+func (v Violation) String() string {
+	if v.Detail == "" {
+		return fmt.Sprintf("%s: %s", v.Kind, v.Path)
+	}
+	return fmt.Sprintf("%s: %s (%s)", v.Kind, v.Path, v.Detail)
+}
+
+// Check compares the tree below root with l and returns its violations in tree order. Entries
+// of a directory listed in the layout are checked against the entries listed for it; those a
+// wildcard allows are checked against the wildcard's children. Directories the scan did not
+// fully read are reported as not checked instead of having their missing entries reported.
+func Check(root *scanner.TreeNode, l *Layout) []Violation {
+	if root == nil || l == nil {
+		return nil
+	}
+	var violations []Violation
+	checkDir(root, "", l.Entries, &violations)
+	return violations
+}
+
+// checkDir checks the children of node, whose relative path with trailing "/" is rel, against
+// entries.
+func checkDir(node *scanner.TreeNode, rel string, entries []*Entry, violations *[]Violation) {
+	literal := make(map[string]*Entry)
+	var wildcards []*Entry
+	for _, entry := range entries {
+		if entry.Wildcard() || entry.Name == AnyName {
+			wildcards = append(wildcards, entry)
+		} else {
+			literal[entry.Name] = entry
+		}
+	}
+
+	found := make(map[*Entry]bool)
+	for _, child := range node.Children {
+		childRel := rel + child.Name
+		if child.IsDir {
+			childRel += "/"
+		}
+
+		if entry := literal[child.Name]; entry != nil {
+			found[entry] = true
+			if child.IsDir != entry.Dir {
+				*violations = append(*violations, Violation{Kind: KindWrongType, Path: childRel, Node: child, Detail: expectedType(entry.Dir)})
+				continue
+			}
+			if child.IsDir && len(entry.Children) > 0 {
+				checkDir(child, childRel, entry.Children, violations)
+			}
+			continue
+		}
+
+		entry := matchWildcard(wildcards, child)
+		if entry == nil {
+			*violations = append(*violations, Violation{Kind: KindUnexpected, Path: childRel, Node: child})
+			continue
+		}
+		found[entry] = true
+		if child.IsDir && entry.Name != AnyName && len(entry.Children) > 0 {
+			checkDir(child, childRel, entry.Children, violations)
+		}
+	}
+
+	if node.Unreadable || node.Truncated || node.NotRead || node.Omitted > 0 {
+		dir := rel
+		if dir == "" {
+			dir = "./"
+		}
+		*violations = append(*violations, Violation{Kind: KindUnchecked, Path: dir, Node: node, Detail: "the folder was not fully read"})
+		return
+	}
+	for _, entry := range entries {
+		if found[entry] || entry.Optional || entry.Name == AnyName {
+			continue
+		}
+		detail := ""
+		if entry.Wildcard() {
+			detail = "nothing matches"
+		}
+		*violations = append(*violations, Violation{Kind: KindMissing, Path: rel + entry.display(), Node: node, Detail: detail})
+	}
+}
+
+// matchWildcard returns the first of wildcards that allows node, or nil.
+func matchWildcard(wildcards []*Entry, node *scanner.TreeNode) *Entry {
+	for _, entry := range wildcards {
+		if entry.matches(node) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// expectedType describes the type an entry was expected to have.
+func expectedType(dir bool) string {
+	if dir {
+		return "expected a directory"
+	}
+	return "expected a file"
+}
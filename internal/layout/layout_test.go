@@ -0,0 +1,138 @@
+package layout
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// buildTree returns a tree holding paths, slash-separated with "/" after directories; parent
+// directories must come first.
+func buildTree(paths ...string) *scanner.TreeNode {
+	root := &scanner.TreeNode{Name: "root", IsDir: true}
+	dirs := map[string]*scanner.TreeNode{"": root}
+	for _, p := range paths {
+		dir := strings.HasSuffix(p, "/")
+		p = strings.TrimSuffix(p, "/")
+		parentPath, name := "", p
+		if i := strings.LastIndexByte(p, '/'); i >= 0 {
+			parentPath, name = p[:i], p[i+1:]
+		}
+		parent := dirs[parentPath]
+		node := &scanner.TreeNode{Name: name, IsDir: dir, Parent: parent}
+		parent.Children = append(parent.Children, node)
+		if dir {
+			dirs[p] = node
+		}
+	}
+	return root
+}
+
+// check parses layout and returns the violations of the tree holding paths as strings.
+func check(t *testing.T, layout string, paths ...string) []string {
+	t.Helper()
+	l, err := ParseText(strings.NewReader(layout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range Check(buildTree(paths...), l) {
+		got = append(got, v.String())
+	}
+	return got
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		paths  []string
+		want   []string
+	}{
+		{"matches", "README.md\nsrc/\n  main.go\n", []string{"README.md", "src/", "src/main.go"}, nil},
+		{"missing", "README.md\nsrc/\n  main.go\n", []string{"src/"}, []string{"missing: src/main.go", "missing: README.md"}},
+		{"unexpected", "README.md\n", []string{"README.md", "notes.txt", "tmp/"}, []string{"unexpected: notes.txt", "unexpected: tmp/"}},
+		{"directory not looked into without children", "vendor/\n", []string{"vendor/", "vendor/x.go"}, nil},
+
+		// Optional markers
+		{"optional missing", "README.md\ndocs/?\nLICENSE?\n", []string{"README.md"}, nil},
+		{"optional present is checked", "docs/?\n  index.md\n", []string{"docs/", "docs/other.md"}, []string{"unexpected: docs/other.md", "missing: docs/index.md"}},
+		{"optional wildcard", "*.md?\n", nil, nil},
+
+		// Wildcards
+		{"wildcard allows extras", "*.go\n", []string{"a.go", "b.go", "c.txt"}, []string{"unexpected: c.txt"}},
+		{"wildcard matches nothing", "*.go\n", []string{"c.txt"}, []string{"unexpected: c.txt", "missing: *.go (nothing matches)"}},
+		{"character class", "v[0-9]/\n", []string{"v1/", "vx/"}, []string{"unexpected: vx/"}},
+		{"wildcard children", "cmd/\n  */\n    main.go\n", []string{"cmd/", "cmd/a/", "cmd/a/main.go", "cmd/b/"}, []string{"missing: cmd/b/main.go"}},
+		{"literal wins over wildcard", "*/\nsrc\n", []string{"src/", "lib/"}, []string{"wrong type: src/ (expected a file)"}},
+		{"anything at any depth", "src/\n  **\n", []string{"src/", "src/a/", "src/a/b/", "src/a/b/c.go", "src/x"}, nil},
+		{"anything, even nothing", "**\n", nil, nil},
+
+		// Type mismatches
+		{"file expected", "README.md\n", []string{"README.md/"}, []string{"wrong type: README.md/ (expected a file)"}},
+		{"directory expected", "docs/\n  index.md\n", []string{"docs"}, []string{"wrong type: docs (expected a directory)"}},
+		{"wildcard file does not match a directory", "*.go\n", []string{"x.go/", "y.go"}, []string{"unexpected: x.go/"}},
+		{"wildcard directory does not match a file", "*/\n", []string{"file"}, []string{"unexpected: file", "missing: */ (nothing matches)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := check(t, tt.layout, tt.paths...); !slices.Equal(got, tt.want) {
+				t.Errorf("violations %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckUnreadDirectory(t *testing.T) {
+	l, err := ParseText(strings.NewReader("src/\n  main.go\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := buildTree("src/")
+	root.Children[0].Unreadable = true
+	got := Check(root, l)
+	if len(got) != 1 || got[0].Kind != KindUnchecked || got[0].Path != "src/" {
+		t.Errorf("violations %v, want src/ not checked and nothing missing", got)
+	}
+}
+
+func TestParseMarkers(t *testing.T) {
+	tests := []struct {
+		text     string
+		name     string
+		dir      bool
+		optional bool
+		wildcard bool
+	}{
+		{"docs/", "docs", true, false, false},
+		{"docs/?", "docs", true, true, false},
+		{"LICENSE?", "LICENSE", false, true, false},
+		{"a??", "a?", false, true, true},
+		{"?", "?", false, false, true},
+		{"*.go", "*.go", false, false, true},
+		{"**", "**", false, false, true},
+		{"**/", "**", false, false, true}, // Anything is neither a file nor a directory
+	}
+	for _, tt := range tests {
+		entry, err := parseName(tt.text)
+		if err != nil {
+			t.Errorf("%q: %v", tt.text, err)
+			continue
+		}
+		if entry.Name != tt.name || entry.Dir != tt.dir || entry.Optional != tt.optional || entry.Wildcard() != tt.wildcard {
+			t.Errorf("%q parsed as %q dir %v optional %v wildcard %v, want %q %v %v %v", tt.text,
+				entry.Name, entry.Dir, entry.Optional, entry.Wildcard(), tt.name, tt.dir, tt.optional, tt.wildcard)
+		}
+	}
+
+	for _, bad := range []string{"src/main.go", "a**", "[", "..", "./", "/"} {
+		if _, err := parseName(bad); err == nil {
+			t.Errorf("%q parsed without an error", bad)
+		}
+	}
+	if _, err := ParseText(strings.NewReader("**\n  x\n")); err == nil {
+		t.Error("entries below ** parsed without an error")
+	}
+}
@@ -0,0 +1,228 @@
+package layout
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+)
+
+// tabWidth is the number of columns a tab indents a text layout by.
+const tabWidth = 4
+
+// Parse reads a layout in either format: JSON when it starts with "[" or "{", indented text
+// otherwise.
+//
+// The text format lists one name per line, indented below its directory:
+//
+//	src/
+//	  *.go
+//	  internal/?
+//	    **
+//	docs/?
+//	README.md
+//	# comment
+//
+// A trailing "/" marks a directory and a trailing "?" an optional entry. A name holding *, ? or
+// [ is a wildcard matching names as path.Match does, and "**" allows anything in its directory.
+//
+// The JSON format is a list of {"name", "dir", "optional", "children"} objects using the same
+// names, or a tree file written by this application, every entry of which is then required.
+func Parse(r io.Reader) (*Layout, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return ParseJSON(trimmed)
+	}
+	return ParseText(bytes.NewReader(data))
+}
+
+// ParseText reads a layout in the indented text format described at Parse.
+func ParseText(r io.Reader) (*Layout, error) {
+	type level struct {
+		indent int
+		entry  *Entry
+	}
+	layout := &Layout{}
+	var stack []level
+
+	lines := bufio.NewScanner(r)
+	lineNo := 0
+	for lines.Scan() {
+		lineNo++
+		line := strings.TrimRight(lines.Text(), " \t\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		text := strings.TrimLeft(line, " \t")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		indent := indentWidth(line[:len(line)-len(text)])
+
+		entry, err := parseName(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		entry.Line = lineNo
+
+		popped := -1
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			popped = stack[len(stack)-1].indent
+			stack = stack[:len(stack)-1]
+		}
+		if popped > indent {
+			return nil, fmt.Errorf("line %d: indentation does not match any enclosing entry", lineNo)
+		}
+		if len(stack) == 0 {
+			layout.Entries = append(layout.Entries, entry)
+		} else {
+			parent := stack[len(stack)-1].entry
+			if err := adopt(parent, entry); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+		}
+		stack = append(stack, level{indent: indent, entry: entry})
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+	return layout, nil
+}
+
+// indentWidth returns the width of leading whitespace, with tabs counting tabWidth columns.
+func indentWidth(space string) int {
+	width := 0
+	for _, c := range space {
+		if c == '\t' {
+			width += tabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// jsonEntry is an entry of a JSON layout.
+type jsonEntry struct {
+	Name     string       `json:"name"`
+	Dir      bool         `json:"dir,omitempty"`
+	Optional bool         `json:"optional,omitempty"`
+	Children []*jsonEntry `json:"children,omitempty"`
+}
+
+// ParseJSON reads a layout in the JSON format described at Parse.
+func ParseJSON(data []byte) (*Layout, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseTreeFile(trimmed)
+	}
+
+	var entries []*jsonEntry
+	if err := json.Unmarshal(trimmed, &entries); err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+	layout := &Layout{}
+	for _, je := range entries {
+		entry, err := je.entry()
+		if err != nil {
+			return nil, err
+		}
+		layout.Entries = append(layout.Entries, entry)
+	}
+	return layout, nil
+}
+
+// entry converts a JSON entry and its children.
+func (je *jsonEntry) entry() (*Entry, error) {
+	if je == nil {
+		return nil, fmt.Errorf("layout entry is null")
+	}
+	entry, err := parseName(je.Name)
+	if err != nil {
+		return nil, err
+	}
+	entry.Dir = entry.Dir || je.Dir
+	entry.Optional = entry.Optional || je.Optional
+	for _, jc := range je.Children {
+		child, err := jc.entry()
+		if err != nil {
+			return nil, err
+		}
+		if err := adopt(entry, child); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// parseTreeFile reads a tree file as a layout requiring every entry it holds.
+func parseTreeFile(data []byte) (*Layout, error) {
+	doc, err := report.ReadTree(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	layout := &Layout{}
+	for _, child := range doc.Root.Children {
+		layout.Entries = append(layout.Entries, treeEntry(child))
+	}
+	return layout, nil
+}
+
+// treeEntry converts an entry of a tree file and its children.
+func treeEntry(te *report.TreeEntry) *Entry {
+	entry := &Entry{Name: te.Name, Dir: te.Dir, literal: true}
+	for _, child := range te.Children {
+		entry.Children = append(entry.Children, treeEntry(child))
+	}
+	return entry
+}
+
+// parseName parses an entry name with its markers.
+func parseName(text string) (*Entry, error) {
+	entry := &Entry{}
+	name := strings.TrimSpace(text)
+	if trimmed, ok := strings.CutSuffix(name, "?"); ok && trimmed != "" {
+		name, entry.Optional = trimmed, true
+	}
+	if trimmed, ok := strings.CutSuffix(name, "/"); ok {
+		name, entry.Dir = trimmed, true
+	}
+	entry.Name = name
+
+	switch {
+	case name == "":
+		return nil, fmt.Errorf("empty entry name")
+	case name == "." || name == "..":
+		return nil, fmt.Errorf("entry name %q is not allowed", name)
+	case name == AnyName:
+		entry.Dir = false
+		return entry, nil
+	case strings.Contains(name, "/"):
+		return nil, fmt.Errorf("entry %q holds a path: list one name per line, indenting each below its directory", text)
+	case strings.Contains(name, "**"):
+		return nil, fmt.Errorf("entry %q: ** must stand alone", text)
+	}
+	if _, err := path.Match(name, ""); err != nil {
+		return nil, fmt.Errorf("entry %q: %w", text, err)
+	}
+	return entry, nil
+}
+
+// adopt adds child to the entries expected in parent, which makes parent a directory.
+func adopt(parent, child *Entry) error {
+	if parent.Name == AnyName {
+		return fmt.Errorf("entries below %s are never checked", AnyName)
+	}
+	parent.Dir = true
+	parent.Children = append(parent.Children, child)
+	return nil
+}
@@ -0,0 +1,165 @@
+package locale
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Portable is the locale name that forces C-locale formatting: no digit grouping,
+// '.' as decimal separator and RFC 3339 dates, so exports diff cleanly.
+const Portable = "C"
+
+// dateLayouts maps a base language to its short date-time layout.
+var dateLayouts = map[string]string{
+	"en": "Jan 2, 2006 15:04",
+	"de": "02.01.2006 15:04",
+	"fr": "02/01/2006 15:04",
+	"es": "02/01/2006 15:04",
+	"it": "02/01/2006 15:04",
+	"pt": "02/01/2006 15:04",
+	"nl": "02-01-2006 15:04",
+	"pl": "02.01.2006 15:04",
+	"ru": "02.01.2006 15:04",
+	"ja": "2006/01/02 15:04",
+	"zh": "2006/01/02 15:04",
+}
+
+// Formatter formats numbers and dates for one locale.
+type Formatter struct {
+	tag     language.Tag
+	printer *message.Printer
+}
+
+// New returns a formatter for a BCP 47 tag or POSIX locale name such as "de_DE.UTF-8".
+// An empty name uses the environment's locale; Portable or an unparsable name yields C formatting.
+func New(name string) *Formatter {
+	if name == "" {
+		name = Detect()
+	}
+	name = normalize(name)
+	if name == Portable {
+		return &Formatter{}
+	}
+
+	tag, err := language.Parse(name)
+	if err != nil {
+		return &Formatter{}
+	}
+	return &Formatter{tag: tag, printer: message.NewPrinter(tag)}
+}
+
+// Detect returns the locale named by LC_ALL, LC_NUMERIC or LANG, or Portable if none is set.
+func Detect() string {
+	for _, key := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return Portable
+}
+
+// normalize turns a POSIX locale name into a BCP 47 tag, e.g. "de_DE.UTF-8@euro" into "de-DE".
+func normalize(name string) string {
+	if i := strings.IndexAny(name, ".@"); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" || name == "POSIX" {
+		return Portable
+	}
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// IsPortable reports whether the formatter uses C-locale formatting.
+func (f *Formatter) IsPortable() bool {
+	return f.printer == nil
+}
+
+// Name returns the formatter's language tag, or Portable.
+func (f *Formatter) Name() string {
+	if f.IsPortable() {
+		return Portable
+	}
+	return f.tag.String()
+}
+
+// Int formats n with the locale's digit grouping, e.g. "48,112" or "48.112".
+func (f *Formatter) Int(n int) string {
+	if f.IsPortable() {
+		return strconv.Itoa(n)
+	}
+	return f.printer.Sprint(number.Decimal(n))
+}
+
+// Decimal formats v with exactly one fractional digit using the locale's separators.
+func (f *Formatter) Decimal(v float64) string {
+	if f.IsPortable() {
+		return strconv.FormatFloat(v, 'f', 1, 64)
+	}
+	return f.printer.Sprint(number.Decimal(v, number.MinFractionDigits(1), number.MaxFractionDigits(1)))
+}
+
+// Compact formats n rounded to thousands or millions, e.g. "950", "4.8k", "48k" or "1.2M", with one
+// fractional digit below ten of the unit.
+func (f *Formatter) Compact(n int) string {
+	units := []struct {
+		size   int
+		suffix string
+	}{{1_000_000, "M"}, {1_000, "k"}}
+	for _, unit := range units {
+		if n < unit.size {
+			continue
+		}
+		if n < 10*unit.size {
+			return f.Decimal(float64(n)/float64(unit.size)) + unit.suffix
+		}
+		return f.Int((n+unit.size/2)/unit.size) + unit.suffix
+	}
+	return f.Int(n)
+}
+
+// Size formats a byte count using binary units, e.g. "12.4 KB" or "12,4 KB".
+func (f *Formatter) Size(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return f.Int(int(bytes)) + " B"
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return f.Decimal(float64(bytes)/float64(div)) + " " + string("KMGTPE"[exp]) + "B"
+}
+
+// Age formats how long ago something happened in the largest whole unit, e.g. "2h ago".
+func (f *Formatter) Age(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return f.Int(int(d/time.Minute)) + "m ago"
+	case d < 24*time.Hour:
+		return f.Int(int(d/time.Hour)) + "h ago"
+	}
+	return f.Int(int(d/(24*time.Hour))) + "d ago"
+}
+
+// Date formats t in the locale's short date-time layout; portable formatting uses RFC 3339 in UTC.
+func (f *Formatter) Date(t time.Time) string {
+	if f.IsPortable() {
+		return t.UTC().Format(time.RFC3339)
+	}
+	base, _ := f.tag.Base()
+	layout, ok := dateLayouts[base.String()]
+	if !ok {
+		layout = "2006-01-02 15:04"
+	}
+	return t.Format(layout)
+}
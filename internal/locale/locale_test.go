@@ -0,0 +1,90 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatter(t *testing.T) {
+	date := time.Date(2026, 3, 7, 14, 5, 0, 0, time.UTC)
+	tests := []struct {
+		locale  string
+		name    string
+		integer string
+		decimal string
+		size    string
+		compact string
+		date    string
+	}{
+		{"en", "en", "48,112", "1,234.6", "1.5 KB", "4.8k", "Mar 7, 2026 14:05"},
+		{"de", "de", "48.112", "1.234,6", "1,5 KB", "4,8k", "07.03.2026 14:05"},
+		{"fr", "fr", "48\u00a0112", "1\u00a0234,6", "1,5 KB", "4,8k", "07/03/2026 14:05"},
+		{"de_DE.UTF-8@euro", "de-DE", "48.112", "1.234,6", "1,5 KB", "4,8k", "07.03.2026 14:05"},
+		{"fr_CA", "fr-CA", "48\u00a0112", "1\u00a0234,6", "1,5 KB", "4,8k", "07/03/2026 14:05"},
+		{"ko", "ko", "48,112", "1,234.6", "1.5 KB", "4.8k", "2026-03-07 14:05"},
+		{Portable, Portable, "48112", "1234.6", "1.5 KB", "4.8k", "2026-03-07T14:05:00Z"},
+		{"POSIX", Portable, "48112", "1234.6", "1.5 KB", "4.8k", "2026-03-07T14:05:00Z"},
+		{"not a locale!", Portable, "48112", "1234.6", "1.5 KB", "4.8k", "2026-03-07T14:05:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			f := New(tt.locale)
+			for _, check := range []struct{ what, got, want string }{
+				{"Name", f.Name(), tt.name},
+				{"Int", f.Int(48112), tt.integer},
+				{"Decimal", f.Decimal(1234.56), tt.decimal},
+				{"Size", f.Size(1536), tt.size},
+				{"Compact", f.Compact(4800), tt.compact},
+				{"Date", f.Date(date), tt.date},
+			} {
+				if check.got != check.want {
+					t.Errorf("%s = %q, want %q", check.what, check.got, check.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompact(t *testing.T) {
+	f := New(Portable)
+	for n, want := range map[int]string{950: "950", 4_849: "4.8k", 48_112: "48k", 999_999: "1000k", 1_234_567: "1.2M", 48_500_000: "49M"} {
+		if got := f.Compact(n); got != want {
+			t.Errorf("Compact(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestSize(t *testing.T) {
+	f := New(Portable)
+	for bytes, want := range map[int64]string{0: "0 B", 1023: "1023 B", 1024: "1.0 KB", 5 << 20: "5.0 MB", 3 << 40: "3.0 TB"} {
+		if got := f.Size(bytes); got != want {
+			t.Errorf("Size(%d) = %q, want %q", bytes, got, want)
+		}
+	}
+	if got := New("de").Size(1023); got != "1.023 B" {
+		t.Errorf("Size(1023) in de = %q, want grouped digits", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_NUMERIC", "")
+	t.Setenv("LANG", "")
+	if got := Detect(); got != Portable {
+		t.Errorf("Detect() = %q without locale variables, want %q", got, Portable)
+	}
+
+	t.Setenv("LANG", "fr_FR.UTF-8")
+	if got := New("").Name(); got != "fr-FR" {
+		t.Errorf("New(\"\") with LANG=fr_FR.UTF-8 uses %q", got)
+	}
+	t.Setenv("LC_NUMERIC", "de_DE.UTF-8")
+	if got := New("").Name(); got != "de-DE" {
+		t.Errorf("LC_NUMERIC does not take precedence over LANG: %q", got)
+	}
+	t.Setenv("LC_ALL", "C")
+	if !New("").IsPortable() {
+		t.Error("LC_ALL=C does not force portable formatting")
+	}
+}
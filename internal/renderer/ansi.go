@@ -0,0 +1,65 @@
+package renderer
+
+import (
+	"io"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// ansiReset ends the color started by an AnnotationStyle color sequence.
+const ansiReset = "\x1b[0m"
+
+// ANSITreeRenderer renders the standard tree layout with terminal colors: directories
+// bold blue, symlinks cyan (red when broken) and executables green. With RendererOptions.Style
+// set to symbols, links and executables get glyphs instead. It is meant for terminals only;
+// clipboard and file output always use StandardTreeRenderer.
+type ANSITreeRenderer struct {
+	opts RendererOptions
+}
+
+// NewANSITreeRenderer creates an ANSITreeRenderer using opts.
+func NewANSITreeRenderer(opts RendererOptions) *ANSITreeRenderer {
+	return &ANSITreeRenderer{opts: opts}
+}
+
+// RenderTree renders a tree structure with ANSI color sequences.
+func (r *ANSITreeRenderer) RenderTree(root *scanner.TreeNode) string {
+	var builder strings.Builder
+	r.WriteTree(&builder, root) // Writing to a builder cannot fail
+	return builder.String()
+}
+
+// WriteTree renders root as with RenderTree, writing each line to w as it is drawn.
+func (r *ANSITreeRenderer) WriteTree(w io.Writer, root *scanner.TreeNode) error {
+	if root == nil {
+		return nil
+	}
+
+	opts := &r.opts
+	label := func(node *scanner.TreeNode) string {
+		icon, name := opts.iconAndName(node, root)
+		annotation := annotationOf(node)
+		if color := opts.Style.Color(annotation); color != "" {
+			name = color + name + ansiReset
+		}
+		return opts.modePrefix(node) + opts.Style.Prefix(annotation) + entry(icon, name+opts.details(node))
+	}
+
+	return writeTree(w, opts, root, nil, label)
+}
+
+// annotationOf returns the annotation of a node's type, or AnnotationNone for plain files.
+func annotationOf(node *scanner.TreeNode) Annotation {
+	switch {
+	case node.LinkBroken:
+		return AnnotationBrokenLink
+	case node.IsSymlink:
+		return AnnotationSymlink
+	case node.IsDir:
+		return AnnotationDirectory
+	case node.Executable:
+		return AnnotationExecutable
+	}
+	return AnnotationNone
+}
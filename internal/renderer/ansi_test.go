@@ -0,0 +1,100 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// defaultHeader is the header of DefaultOptions for fixtureTree.
+const defaultHeader = "File Tree for: /work/project\n" + "==================================================\n\n"
+
+func TestANSITreeRenderer(t *testing.T) {
+	want := defaultHeader +
+		"📁 \x1b[1;34msrc/\x1b[0m\n" +
+		"├── 📄 main.go\n" +
+		"├── 📄 \x1b[32mrun.sh\x1b[0m\n" +
+		"└── 📁 \x1b[1;34mlib/\x1b[0m\n" +
+		"    └── 📄 util.go\n" +
+		"├── 📄 \x1b[36mdocs -> src\x1b[0m\n" +
+		"├── 📄 \x1b[31mold -> gone\x1b[0m (broken)\n" +
+		"└── 📄 README.md\n"
+	if got := NewANSITreeRenderer(DefaultOptions()).RenderTree(fixtureTree()); got != want {
+		t.Errorf("colored tree:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestANSITreeRendererSymbols(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Style.Symbols = true
+	want := defaultHeader +
+		"📁 src/\n" +
+		"├── 📄 main.go\n" +
+		"├── * 📄 run.sh\n" +
+		"└── 📁 lib/\n" +
+		"    └── 📄 util.go\n" +
+		"├── ↪ 📄 docs -> src\n" +
+		"├── ✗ 📄 old -> gone (broken)\n" +
+		"└── 📄 README.md\n"
+	if got := NewANSITreeRenderer(opts).RenderTree(fixtureTree()); got != want {
+		t.Errorf("tree with symbols:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestPlainOutputHasNoColor(t *testing.T) {
+	root := fixtureTree()
+	for name, r := range map[string]TreeRenderer{
+		"standard": NewStandardTreeRenderer(DefaultOptions()),
+		"ascii":    NewStandardTreeRenderer(ASCIIOptions()),
+		"html":     NewHTMLTreeRenderer(DefaultOptions(), true),
+		"opml":     NewOPMLTreeRenderer(DefaultOptions()),
+		"outline":  NewOutlineRenderer(DefaultOptions()),
+	} {
+		if out := r.RenderTree(root); strings.Contains(out, "\x1b") {
+			t.Errorf("%s output holds an escape sequence:\n%q", name, out)
+		}
+	}
+	// Without the escapes, colored output is the plain tree
+	colored := NewANSITreeRenderer(DefaultOptions()).RenderTree(root)
+	plain := NewStandardTreeRenderer(DefaultOptions()).RenderTree(root)
+	for _, sequence := range []string{ansiReset, "\x1b[1;34m", "\x1b[32m", "\x1b[36m", "\x1b[31m"} {
+		colored = strings.ReplaceAll(colored, sequence, "")
+	}
+	if colored != plain {
+		t.Errorf("colored output differs from the plain tree beyond its colors:\n%q\n%q", colored, plain)
+	}
+}
+
+func TestHTMLDepthColors(t *testing.T) {
+	root := fixtureTree()
+	out := NewHTMLTreeRenderer(DefaultOptions(), true).RenderTree(root)
+	for _, want := range []string{
+		".depth-0 { color: #1a1a1a; }\n",
+		".depth-7 { color: #9c9c9c; }\n",
+		"<li class=\"depth-0\">📁 src/\n",
+		"<li class=\"depth-1\">📄 main.go</li>\n",
+		"<li class=\"depth-2\">📄 util.go</li>\n",
+		"<li class=\"depth-0\">📄 docs -&gt; src</li>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML lacks %q:\n%s", want, out)
+		}
+	}
+
+	// Levels past the last shade reuse it
+	deep := root.Children[0].Children[2] // src/lib
+	for i := 0; i < len(htmlDepthShades); i++ {
+		child := &scanner.TreeNode{Name: "d", IsDir: true, Path: deep.Path + "/d", Parent: deep}
+		deep.Children = append(deep.Children, child)
+		deep = child
+	}
+	out = NewHTMLTreeRenderer(DefaultOptions(), true).RenderTree(root)
+	if strings.Contains(out, "depth-8") || !strings.Contains(out, "<li class=\"depth-7\">📁 d/") {
+		t.Error("depths past the last shade do not reuse it")
+	}
+
+	if plain := NewHTMLTreeRenderer(DefaultOptions(), false).RenderTree(root); strings.Contains(plain, "depth-") {
+		t.Error("depth classes written without depth colors")
+	}
+}
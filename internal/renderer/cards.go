@@ -0,0 +1,218 @@
+package renderer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// cardExtensions is the number of extensions listed as a directory's dominant ones.
+const cardExtensions = 3
+
+// readmeBytes bounds how much of a README is read to find its first line.
+const readmeBytes = 4096
+
+// readmeLineWidth is the number of characters of a README's first line shown on a card.
+const readmeLineWidth = 120
+
+// CardsRenderer renders a Markdown overview with one card per top-level directory: its counts,
+// size, dominant extensions, newest file and the first line of its README. Directories the scan
+// did not read in full, or that are excluded, keep their card with a note saying so.
+// Only ShowSizes, SizeBasis, ShowTimes, HideExportIgnored and Redactor of the options apply.
+type CardsRenderer struct {
+	opts     RendererOptions
+	excluded map[string]bool
+}
+
+// NewCardsRenderer creates a CardsRenderer using opts. excluded names top-level directories
+// left out of the view, which are described all the same.
+func NewCardsRenderer(opts RendererOptions, excluded []string) *CardsRenderer {
+	r := &CardsRenderer{opts: opts, excluded: make(map[string]bool)}
+	for _, name := range excluded {
+		r.excluded[name] = true
+	}
+	return r
+}
+
+// cardTotals aggregates what a card shows about a directory.
+type cardTotals struct {
+	dirs, files int
+	extensions  map[string]int
+	newest      *scanner.TreeNode
+	unreadable  int  // Directories that could not be listed
+	omitted     int  // Entries left out by the per-directory cap
+	depthCut    bool // Some directory was not read because of the depth limit
+}
+
+// RenderTree renders the cards for root's top-level directories as a Markdown document.
+func (r *CardsRenderer) RenderTree(root *scanner.TreeNode) string {
+	if root == nil {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Directory cards: " + r.opts.Redactor.Apply(root.Path) + "\n")
+	files := 0
+	for _, child := range root.Children {
+		if !child.IsDir {
+			files++
+			continue
+		}
+		builder.WriteString("\n")
+		r.writeCard(&builder, child)
+	}
+	if files > 0 {
+		builder.WriteString(fmt.Sprintf("\n%d files at the top level are not shown as cards.\n", files))
+	}
+	return builder.String()
+}
+
+// writeCard writes the section for one top-level directory.
+func (r *CardsRenderer) writeCard(builder *strings.Builder, dir *scanner.TreeNode) {
+	totals := cardTotals{extensions: make(map[string]int)}
+	for _, child := range dir.Children {
+		totals.add(child)
+	}
+	totals.note(dir)
+
+	builder.WriteString("## " + r.opts.Redactor.Apply(dir.Name) + "/\n\n")
+	builder.WriteString(fmt.Sprintf("- **Contents:** %d directories, %d files\n", totals.dirs, totals.files))
+	if r.opts.ShowSizes {
+		size := FormatSize(dir.SizeFor(r.opts.SizeBasis))
+		if dir.SizeUnknown {
+			size = "at least " + size
+		}
+		builder.WriteString("- **Size:** " + size + "\n")
+	}
+	if extensions := dominantExtensions(totals.extensions); extensions != "" {
+		builder.WriteString("- **Main extensions:** " + extensions + "\n")
+	}
+	if r.opts.ShowTimes && totals.newest != nil {
+		rel, err := filepath.Rel(dir.Path, totals.newest.Path)
+		if err != nil {
+			rel = totals.newest.Name
+		}
+		builder.WriteString(fmt.Sprintf("- **Last modified:** %s (%s)\n", totals.newest.ModTime.Format("2006-01-02"), r.opts.Redactor.Apply(filepath.ToSlash(rel))))
+	}
+	if line := readmeLine(dir); line != "" {
+		builder.WriteString("- **README:** " + r.opts.Redactor.Apply(line) + "\n")
+	}
+	for _, note := range r.notes(dir, &totals) {
+		builder.WriteString("- **Note:** " + note + "\n")
+	}
+}
+
+// add counts node and everything below it.
+func (t *cardTotals) add(node *scanner.TreeNode) {
+	if node.IsDir {
+		t.dirs++
+		t.note(node)
+		for _, child := range node.Children {
+			t.add(child)
+		}
+		return
+	}
+	t.files++
+	ext := strings.ToLower(filepath.Ext(node.Name))
+	if ext == "" || ext == node.Name {
+		ext = "(none)"
+	}
+	t.extensions[ext]++
+	if !node.ModTime.IsZero() && (t.newest == nil || node.ModTime.After(t.newest.ModTime)) {
+		t.newest = node
+	}
+}
+
+// note records what the scan left out of the directory node.
+func (t *cardTotals) note(node *scanner.TreeNode) {
+	if node.Unreadable {
+		t.unreadable++
+	}
+	t.omitted += node.Omitted
+	t.depthCut = t.depthCut || node.Truncated
+}
+
+// notes returns the annotations for a directory the overview cannot fully describe.
+func (r *CardsRenderer) notes(dir *scanner.TreeNode, totals *cardTotals) []string {
+	var notes []string
+	if r.excluded[dir.Name] {
+		notes = append(notes, "excluded from the view")
+	}
+	if dir.ExportIgnore {
+		note := "marked export-ignore in .gitattributes"
+		if r.opts.HideExportIgnored {
+			note += ", so it is left out of exported trees"
+		}
+		notes = append(notes, note)
+	}
+	if totals.unreadable > 0 {
+		notes = append(notes, fmt.Sprintf("%d directories could not be read", totals.unreadable))
+	}
+	if totals.omitted > 0 {
+		notes = append(notes, fmt.Sprintf("%d entries were left out by the per-directory limit", totals.omitted))
+	}
+	if totals.depthCut {
+		notes = append(notes, "the depth limit stopped the scan inside it")
+	}
+	if len(notes) == 0 && dir.SizeUnknown {
+		notes = append(notes, "not fully read, so the counts are a lower bound")
+	}
+	return notes
+}
+
+// dominantExtensions formats the most common extensions with their file counts, most common first.
+func dominantExtensions(counts map[string]int) string {
+	extensions := make([]string, 0, len(counts))
+	for ext := range counts {
+		extensions = append(extensions, ext)
+	}
+	sort.Slice(extensions, func(i, j int) bool {
+		if counts[extensions[i]] != counts[extensions[j]] {
+			return counts[extensions[i]] > counts[extensions[j]]
+		}
+		return extensions[i] < extensions[j]
+	})
+	if len(extensions) > cardExtensions {
+		extensions = extensions[:cardExtensions]
+	}
+	parts := make([]string, len(extensions))
+	for i, ext := range extensions {
+		parts[i] = fmt.Sprintf("%s (%d)", ext, counts[ext])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// readmeLine returns the first non-blank line of the README directly inside dir, without
+// Markdown heading marks, or "" when there is none or it cannot be read.
+func readmeLine(dir *scanner.TreeNode) string {
+	for _, child := range dir.Children {
+		name := strings.ToLower(child.Name)
+		if child.IsDir || (name != "readme" && !strings.HasPrefix(name, "readme.")) {
+			continue
+		}
+		file, err := os.Open(child.Path)
+		if err != nil {
+			return ""
+		}
+		defer file.Close()
+		lines := bufio.NewScanner(io.LimitReader(file, readmeBytes))
+		for lines.Scan() {
+			line := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(lines.Text()), "#"))
+			if line == "" {
+				continue
+			}
+			if runes := []rune(line); len(runes) > readmeLineWidth {
+				line = string(runes[:readmeLineWidth]) + "…"
+			}
+			return line
+		}
+		return ""
+	}
+	return ""
+}
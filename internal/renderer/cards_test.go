@@ -0,0 +1,147 @@
+package renderer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// cardsFixture returns a tree below a temporary folder with a described app directory, whose
+// README is on disk, a vendor directory the scan could not read in full, an empty directory and
+// a file at the top level.
+func cardsFixture(t *testing.T) *scanner.TreeNode {
+	t.Helper()
+	root := &scanner.TreeNode{Name: "project", Path: t.TempDir(), IsDir: true}
+	day := func(month, day int) time.Time { return time.Date(2024, time.Month(month), day, 12, 0, 0, 0, time.UTC) }
+	add := func(parent *scanner.TreeNode, name string, dir bool, size int64, modTime time.Time) *scanner.TreeNode {
+		node := &scanner.TreeNode{Name: name, Path: filepath.Join(parent.Path, name), IsDir: dir, Size: size, ModTime: modTime, Parent: parent}
+		parent.Children = append(parent.Children, node)
+		return node
+	}
+
+	app := add(root, "app", true, 3100, time.Time{})
+	add(app, "README.md", false, 100, day(1, 1))
+	add(app, "main.go", false, 1000, day(5, 1))
+	add(app, "util.go", false, 900, day(3, 1))
+	add(app, "Makefile", false, 100, day(2, 1))
+	lib := add(app, "lib", true, 1000, time.Time{})
+	add(lib, "a.go", false, 500, day(6, 2))
+	add(lib, "b.js", false, 300, day(4, 1))
+	add(lib, "c.css", false, 200, day(4, 1))
+	if err := os.MkdirAll(app.Path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	readme := "\n# Billing service for the ACME shop\n\nMore text.\n"
+	if err := os.WriteFile(filepath.Join(app.Path, "README.md"), []byte(readme), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	vendor := add(root, "vendor", true, 2048, time.Time{})
+	vendor.ExportIgnore = true
+	vendor.SizeUnknown = true
+	locked := add(vendor, "locked", true, 0, time.Time{})
+	locked.Unreadable = true
+	big := add(vendor, "big", true, 2048, time.Time{})
+	big.Omitted = 7
+	add(big, "x.js", false, 2048, day(1, 2))
+	deep := add(big, "deep", true, 0, time.Time{})
+	deep.Truncated = true
+
+	add(root, "empty", true, 0, time.Time{})
+	add(root, "go.mod", false, 30, day(1, 1))
+	root.Size = 5178
+	return root
+}
+
+func TestCardsGolden(t *testing.T) {
+	root := cardsFixture(t)
+	opts := DefaultOptions()
+	opts.ShowSizes = true
+	opts.ShowTimes = true
+	opts.HideExportIgnored = true
+	got := NewCardsRenderer(opts, []string{"vendor"}).RenderTree(root)
+	got = strings.ReplaceAll(got, root.Path, "{root}")
+
+	want := "# Directory cards: {root}\n" +
+		"\n" +
+		"## app/\n" +
+		"\n" +
+		"- **Contents:** 1 directories, 7 files\n" +
+		"- **Size:** 3.0 KB\n" +
+		"- **Main extensions:** .go (3), (none) (1), .css (1)\n" +
+		"- **Last modified:** 2024-06-02 (lib/a.go)\n" +
+		"- **README:** Billing service for the ACME shop\n" +
+		"\n" +
+		"## vendor/\n" +
+		"\n" +
+		"- **Contents:** 3 directories, 1 files\n" +
+		"- **Size:** at least 2.0 KB\n" +
+		"- **Main extensions:** .js (1)\n" +
+		"- **Last modified:** 2024-01-02 (big/x.js)\n" +
+		"- **Note:** excluded from the view\n" +
+		"- **Note:** marked export-ignore in .gitattributes, so it is left out of exported trees\n" +
+		"- **Note:** 1 directories could not be read\n" +
+		"- **Note:** 7 entries were left out by the per-directory limit\n" +
+		"- **Note:** the depth limit stopped the scan inside it\n" +
+		"\n" +
+		"## empty/\n" +
+		"\n" +
+		"- **Contents:** 0 directories, 0 files\n" +
+		"- **Size:** 0 B\n" +
+		"\n" +
+		"1 files at the top level are not shown as cards.\n"
+	if got != want {
+		t.Errorf("cards:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCardsWithoutSizesOrTimes(t *testing.T) {
+	root := cardsFixture(t)
+	got := NewCardsRenderer(DefaultOptions(), nil).RenderTree(root)
+	for _, absent := range []string{"**Size:**", "**Last modified:**", "excluded from the view", "so it is left out"} {
+		if strings.Contains(got, absent) {
+			t.Errorf("cards contain %q:\n%s", absent, got)
+		}
+	}
+	if !strings.Contains(got, "- **Note:** marked export-ignore in .gitattributes\n") {
+		t.Errorf("cards lack the export-ignore note:\n%s", got)
+	}
+}
+
+func TestCardsLowerBoundNote(t *testing.T) {
+	root := &scanner.TreeNode{Name: "project", Path: "project", IsDir: true}
+	partial := &scanner.TreeNode{Name: "partial", Path: "project/partial", IsDir: true, SizeUnknown: true, Parent: root}
+	root.Children = []*scanner.TreeNode{partial}
+	got := NewCardsRenderer(DefaultOptions(), nil).RenderTree(root)
+	if !strings.Contains(got, "- **Note:** not fully read, so the counts are a lower bound\n") {
+		t.Errorf("cards of a directory not read in full:\n%s", got)
+	}
+}
+
+func TestCardsReadmeLine(t *testing.T) {
+	dir := t.TempDir()
+	long := strings.Repeat("é", readmeLineWidth+5)
+	tests := []struct {
+		name, content, want string
+	}{
+		{"README", "plain first line\n", "plain first line"},
+		{"readme.txt", "\n\n  ## Heading  \n", "Heading"},
+		{"Readme.md", long + "\n", strings.Repeat("é", readmeLineWidth) + "…"},
+		{"README.md", "\n\n", ""},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		node := &scanner.TreeNode{Name: "dir", Path: dir, IsDir: true, Children: []*scanner.TreeNode{{Name: tt.name, Path: path}}}
+		if got := readmeLine(node); got != tt.want {
+			t.Errorf("%s: README line %q, want %q", tt.name, got, tt.want)
+		}
+		os.Remove(path)
+	}
+}
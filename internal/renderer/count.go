@@ -0,0 +1,75 @@
+package renderer
+
+import (
+	"bytes"
+	"io"
+)
+
+// OutputStats describes output as it was written.
+type OutputStats struct {
+	Bytes int64
+	Lines int // Lines written; a final line without a newline counts
+}
+
+// CountingWriter passes writes through to an underlying writer, counting bytes and lines
+// on the way. The first write error is kept and later writes are dropped.
+type CountingWriter struct {
+	w        io.Writer
+	stats    OutputStats
+	openLine bool // The last byte written was not a newline
+	err      error
+}
+
+// NewCountingWriter creates a CountingWriter writing to w.
+func NewCountingWriter(w io.Writer) *CountingWriter {
+	return &CountingWriter{w: w}
+}
+
+// Write implements io.Writer.
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.count(p[:n])
+	c.err = err
+	return n, err
+}
+
+// WriteString implements io.StringWriter.
+func (c *CountingWriter) WriteString(s string) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := io.WriteString(c.w, s)
+	c.count([]byte(s[:n]))
+	c.err = err
+	return n, err
+}
+
+// count adds p to the totals.
+func (c *CountingWriter) count(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	newlines := bytes.Count(p, []byte{'\n'})
+	if c.openLine {
+		c.stats.Lines--
+	}
+	c.openLine = p[len(p)-1] != '\n'
+	c.stats.Lines += newlines
+	if c.openLine {
+		c.stats.Lines++
+	}
+	c.stats.Bytes += int64(len(p))
+}
+
+// Stats returns what has been written so far.
+func (c *CountingWriter) Stats() OutputStats {
+	return c.stats
+}
+
+// Err returns the first write error, if any.
+func (c *CountingWriter) Err() error {
+	return c.err
+}
@@ -0,0 +1,44 @@
+package renderer
+
+import (
+	"io"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// DiffRenderer renders a tree merged from two scans in the standard layout, with each line
+// starting with how its entry changed, like a unified diff: "+" for added, "-" for removed,
+// "~" for modified and a space for unchanged, or the glyphs of RendererOptions.Style.
+type DiffRenderer struct {
+	opts   RendererOptions
+	change func(*scanner.TreeNode) Annotation
+}
+
+// NewDiffRenderer creates a DiffRenderer using opts, with change returning how each entry
+// changed, such as diff.Result.Annotation.
+func NewDiffRenderer(opts RendererOptions, change func(*scanner.TreeNode) Annotation) *DiffRenderer {
+	return &DiffRenderer{opts: opts, change: change}
+}
+
+// RenderTree renders the merged tree below root with its marks.
+func (r *DiffRenderer) RenderTree(root *scanner.TreeNode) string {
+	var builder strings.Builder
+	r.WriteTree(&builder, root) // Writing to a builder cannot fail
+	return builder.String()
+}
+
+// WriteTree renders root as with RenderTree, writing each line to w as it is drawn.
+func (r *DiffRenderer) WriteTree(w io.Writer, root *scanner.TreeNode) error {
+	if root == nil {
+		return nil
+	}
+
+	opts := &r.opts
+	label := func(node *scanner.TreeNode) string {
+		icon, name := opts.iconAndName(node, root)
+		return opts.modePrefix(node) + entry(icon, name+opts.details(node))
+	}
+	mark := func(node *scanner.TreeNode) string { return opts.Style.Mark(r.change(node)) }
+	return writeTree(w, opts, root, mark, label)
+}
@@ -0,0 +1,22 @@
+package renderer
+
+import (
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Footer returns the summary appended to rendered output, formatted for f: directory and file
+// counts like the tree command, then the language breakdown and the scan date.
+func Footer(result *scanner.ScanResult, f *locale.Formatter) string {
+	footer := "\n" + f.Int(result.DirCount) + " directories, " + f.Int(result.FileCount) + " files"
+	if result.CountsPartial {
+		footer += " (partial)"
+	}
+	if len(result.Languages) > 0 {
+		footer += "\n" + scanner.FormatLanguages(result.Languages)
+	}
+	if !result.ScannedAt.IsZero() {
+		footer += "\nScanned on " + f.Date(result.ScannedAt)
+	}
+	return footer + "\n"
+}
@@ -0,0 +1,92 @@
+package renderer
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// htmlDepthShades are the text colors for successive depths, darkest first;
+// deeper levels reuse the last shade.
+var htmlDepthShades = []string{"#1a1a1a", "#333333", "#4d4d4d", "#5f5f5f", "#707070", "#808080", "#8f8f8f", "#9c9c9c"}
+
+// HTMLTreeRenderer renders a tree as a standalone HTML page of nested lists.
+// Connector options do not apply; the header's first line becomes the page title.
+type HTMLTreeRenderer struct {
+	opts        RendererOptions
+	depthColors bool
+}
+
+// NewHTMLTreeRenderer creates an HTMLTreeRenderer using opts, muting the text color
+// progressively with depth when depthColors is set.
+func NewHTMLTreeRenderer(opts RendererOptions, depthColors bool) *HTMLTreeRenderer {
+	return &HTMLTreeRenderer{opts: opts, depthColors: depthColors}
+}
+
+// RenderTree renders a tree structure as an HTML document.
+func (r *HTMLTreeRenderer) RenderTree(root *scanner.TreeNode) string {
+	if root == nil {
+		return ""
+	}
+
+	title, _, _ := strings.Cut(r.opts.expand(r.opts.Header, root), "\n")
+	title = html.EscapeString(title)
+
+	var builder strings.Builder
+	builder.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	builder.WriteString("<title>" + title + "</title>\n<style>\n")
+	builder.WriteString("ul.tree, ul.tree ul { list-style: none; padding-left: 1.5em; font-family: monospace; }\n")
+	if r.depthColors {
+		for depth, shade := range htmlDepthShades {
+			builder.WriteString(fmt.Sprintf(".depth-%d { color: %s; }\n", depth, shade))
+		}
+	}
+	builder.WriteString("</style>\n</head>\n<body>\n")
+	builder.WriteString("<h1>" + title + "</h1>\n<ul class=\"tree\">\n")
+
+	for _, child := range r.opts.children(root) {
+		r.renderNode(&builder, root, child, 1)
+	}
+	builder.WriteString("</ul>\n")
+
+	if footer := r.opts.expand(r.opts.Footer, root); footer != "" {
+		builder.WriteString("<footer>" + html.EscapeString(strings.TrimSpace(footer)) + "</footer>\n")
+	}
+	builder.WriteString("</body>\n</html>\n")
+	return builder.String()
+}
+
+// renderNode renders a node as a list item, nesting its children in a sub-list.
+func (r *HTMLTreeRenderer) renderNode(builder *strings.Builder, root, node *scanner.TreeNode, depth int) {
+	builder.WriteString("<li")
+	if r.depthColors {
+		builder.WriteString(fmt.Sprintf(" class=\"depth-%d\"", min(depth-1, len(htmlDepthShades)-1)))
+	}
+	icon, name := r.opts.iconAndName(node, root)
+	builder.WriteString(">" + html.EscapeString(r.opts.modePrefix(node)+entry(icon, name+r.opts.details(node))))
+
+	if children := r.opts.children(node); len(children) > 0 && !r.opts.beyondDepth(depth+1) {
+		builder.WriteString("\n<ul>\n")
+		for _, child := range children {
+			r.renderNode(builder, root, child, depth+1)
+		}
+		builder.WriteString("</ul>\n")
+	}
+	builder.WriteString("</li>\n")
+}
+
+// ForPath returns the renderer for a file extension that needs one other than plain text, or nil.
+func ForPath(path string, opts RendererOptions, depthColors bool) TreeRenderer {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return NewHTMLTreeRenderer(opts, depthColors)
+	case ".opml":
+		return NewOPMLTreeRenderer(opts)
+	case ".outline":
+		return NewOutlineRenderer(opts)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package renderer
+
+import (
+	"fmt"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// truncationMarker ends output cut to RendererOptions.MaxBytes.
+const truncationMarker = "#TRUNCATED items_omitted=%d\n"
+
+// OutputLimitError reports output that was cut short to stay within a byte limit.
+type OutputLimitError struct {
+	Limit   int64 // Byte limit the output was cut to
+	Omitted int   // Entries left out
+}
+
+// Error implements error.
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("output cut to %d bytes, %d items omitted", e.Limit, e.Omitted)
+}
+
+// lineBudget writes whole lines until the next one would take the output past a byte limit,
+// then drops that line and every later one, counting the entries among them. Lines that fit only
+// without the truncation marker are held back until it is known whether anything follows them.
+type lineBudget struct {
+	out       *CountingWriter
+	limited   bool
+	limit     int64 // Bytes of output allowed, counted from when the budget was created
+	reserve   int64 // Bytes kept for the truncation marker once output is cut
+	start     int64
+	cut       bool
+	held      []heldLine
+	heldBytes int64
+	omitted   int // Entries dropped; placeholders for already omitted entries are not counted
+}
+
+// heldLine is a line held back by a lineBudget, with the entry it draws.
+type heldLine struct {
+	text string
+	node *scanner.TreeNode
+}
+
+// newLineBudget returns a budget for writing root to out with opts, keeping room for the
+// truncation marker within opts.MaxBytes should the output be cut.
+func newLineBudget(out *CountingWriter, opts *RendererOptions, root *scanner.TreeNode) *lineBudget {
+	budget := &lineBudget{out: out, start: out.Stats().Bytes}
+	if opts.MaxBytes > 0 {
+		budget.limited = true
+		budget.limit = opts.MaxBytes
+		budget.reserve = int64(len(fmt.Sprintf(truncationMarker, countNodes(root))))
+	}
+	return budget
+}
+
+// write writes text, a whole number of lines, if it fits. node is the entry text draws, or
+// nil for the header and footer.
+func (b *lineBudget) write(text string, node *scanner.TreeNode) {
+	if b.cut {
+		b.skip(node)
+		return
+	}
+	if !b.limited {
+		b.out.WriteString(text)
+		return
+	}
+	used := b.out.Stats().Bytes - b.start + b.heldBytes + int64(len(text))
+	switch {
+	case used <= b.limit-b.reserve:
+		b.out.WriteString(text)
+	case used <= b.limit:
+		// Fits only if it is the end of the output
+		b.held = append(b.held, heldLine{text, node})
+		b.heldBytes += int64(len(text))
+	default:
+		for _, line := range b.held {
+			b.skip(line.node)
+		}
+		b.held, b.heldBytes = nil, 0
+		b.skip(node)
+	}
+}
+
+// skip drops the line of node, or of the header or footer when node is nil.
+func (b *lineBudget) skip(node *scanner.TreeNode) {
+	b.cut = true
+	if node != nil && !node.Placeholder {
+		b.omitted++
+	}
+}
+
+// finish ends cut output with the truncation marker, returning an *OutputLimitError if the
+// output was cut and the write error otherwise.
+func (b *lineBudget) finish(opts *RendererOptions) error {
+	if !b.cut {
+		for _, line := range b.held {
+			b.out.WriteString(line.text)
+		}
+		return b.out.Err()
+	}
+	b.out.WriteString(fmt.Sprintf(truncationMarker, b.omitted))
+	if err := b.out.Err(); err != nil {
+		return err
+	}
+	return &OutputLimitError{Limit: opts.MaxBytes, Omitted: b.omitted}
+}
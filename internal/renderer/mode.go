@@ -0,0 +1,51 @@
+package renderer
+
+import "io/fs"
+
+// ModeString formats mode the way ls -l does: "drwxr-xr-x", "crw-rw----" for a character device,
+// or "-rwsr-xr-x" for a setuid file.
+func ModeString(mode fs.FileMode) string {
+	var b [10]byte
+	switch {
+	case mode&fs.ModeDir != 0:
+		b[0] = 'd'
+	case mode&fs.ModeSymlink != 0:
+		b[0] = 'l'
+	case mode&fs.ModeNamedPipe != 0:
+		b[0] = 'p'
+	case mode&fs.ModeSocket != 0:
+		b[0] = 's'
+	case mode&fs.ModeCharDevice != 0:
+		b[0] = 'c'
+	case mode&fs.ModeDevice != 0:
+		b[0] = 'b'
+	case mode&fs.ModeIrregular != 0:
+		b[0] = '?'
+	default:
+		b[0] = '-'
+	}
+
+	const rwx = "rwxrwxrwx"
+	for i := range rwx {
+		b[i+1] = '-'
+		if mode&(1<<(8-i)) != 0 {
+			b[i+1] = rwx[i]
+		}
+	}
+	special(&b[3], mode&fs.ModeSetuid != 0, 's')
+	special(&b[6], mode&fs.ModeSetgid != 0, 's')
+	special(&b[9], mode&fs.ModeSticky != 0, 't')
+	return string(b[:])
+}
+
+// special replaces an execute position with letter when set, or its upper case when the execute
+// bit beneath it is clear.
+func special(position *byte, set bool, letter byte) {
+	if !set {
+		return
+	}
+	if *position == '-' {
+		letter -= 'a' - 'A'
+	}
+	*position = letter
+}
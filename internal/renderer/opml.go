@@ -0,0 +1,83 @@
+package renderer
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// opmlDocument is an OPML 2.0 document.
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutline is one entry of the tree. Kind is a custom attribute, "directory", "file" or
+// "omitted"; Unreadable marks directories that could not be listed, with MarkUnreadable.
+type opmlOutline struct {
+	Text       string        `xml:"text,attr"`
+	Kind       string        `xml:"kind,attr"`
+	Size       string        `xml:"size,attr,omitempty"`
+	Unreadable bool          `xml:"unreadable,attr,omitempty"`
+	Children   []opmlOutline `xml:"outline"`
+}
+
+// OPMLTreeRenderer renders a tree as an OPML 2.0 outline for outliners and mind-mapping tools.
+// Icons, connectors, header and footer do not apply; the root path becomes the title.
+type OPMLTreeRenderer struct {
+	opts RendererOptions
+}
+
+// NewOPMLTreeRenderer creates an OPMLTreeRenderer using opts.
+func NewOPMLTreeRenderer(opts RendererOptions) *OPMLTreeRenderer {
+	return &OPMLTreeRenderer{opts: opts}
+}
+
+// RenderTree renders a tree structure as an OPML document.
+func (r *OPMLTreeRenderer) RenderTree(root *scanner.TreeNode) string {
+	if root == nil {
+		return ""
+	}
+
+	doc := opmlDocument{Version: "2.0", Title: r.opts.Redactor.Apply(root.Path), Body: r.outlines(root, 1)}
+	// Characters XML cannot represent are replaced, so marshaling cannot fail on names
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(xml.Header)
+	builder.Write(out)
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// outlines returns the outline elements for node's children at depth.
+func (r *OPMLTreeRenderer) outlines(node *scanner.TreeNode, depth int) []opmlOutline {
+	if r.opts.beyondDepth(depth) {
+		return nil
+	}
+	children := r.opts.children(node)
+	outlines := make([]opmlOutline, 0, len(children))
+	for _, child := range children {
+		outline := opmlOutline{Text: r.opts.Redactor.Apply(child.Name), Kind: "file"}
+		if child.Placeholder {
+			outlines = append(outlines, opmlOutline{Text: child.Name, Kind: "omitted"})
+			continue
+		}
+		if child.IsDir {
+			outline.Kind = "directory"
+			outline.Unreadable = r.opts.MarkUnreadable && child.Unreadable
+			outline.Children = r.outlines(child, depth+1)
+		}
+		if r.opts.ShowSizes && !child.SizeUnknown {
+			outline.Size = strconv.FormatInt(child.SizeFor(r.opts.SizeBasis), 10)
+		}
+		outlines = append(outlines, outline)
+	}
+	return outlines
+}
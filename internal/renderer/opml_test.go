@@ -0,0 +1,232 @@
+package renderer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// parsedOPML is an OPML document read back with a strict parser.
+type parsedOPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    *struct {
+		Title string `xml:"title"`
+	} `xml:"head"`
+	Body *struct {
+		Outlines []parsedOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+// parsedOutline is an outline element read back.
+type parsedOutline struct {
+	Text       *string         `xml:"text,attr"`
+	Kind       string          `xml:"kind,attr"`
+	Size       string          `xml:"size,attr"`
+	Unreadable string          `xml:"unreadable,attr"`
+	Children   []parsedOutline `xml:"outline"`
+}
+
+// parseOPML checks that doc is well-formed OPML 2.0, with a head holding a title, a body and
+// a text attribute on every outline, and returns it.
+func parseOPML(t *testing.T, doc string) *parsedOPML {
+	t.Helper()
+	if !strings.HasPrefix(doc, xml.Header) {
+		t.Errorf("document does not start with the XML declaration:\n%s", doc)
+	}
+
+	// Every element is one OPML defines
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	decoder.Strict = true
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err.Error() == "EOF" {
+				break
+			}
+			t.Fatalf("not well-formed: %v\n%s", err, doc)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "opml", "head", "title", "body", "outline":
+			default:
+				t.Errorf("unexpected element <%s>", start.Name.Local)
+			}
+		}
+	}
+
+	var parsed parsedOPML
+	if err := xml.Unmarshal([]byte(doc), &parsed); err != nil {
+		t.Fatalf("failed to decode: %v\n%s", err, doc)
+	}
+	if parsed.Version != "2.0" {
+		t.Errorf("version %q, want 2.0", parsed.Version)
+	}
+	if parsed.Head == nil || parsed.Body == nil {
+		t.Fatalf("head or body missing:\n%s", doc)
+	}
+	var check func(outlines []parsedOutline)
+	check = func(outlines []parsedOutline) {
+		for _, outline := range outlines {
+			if outline.Text == nil {
+				t.Errorf("outline without a text attribute:\n%s", doc)
+			}
+			check(outline.Children)
+		}
+	}
+	check(parsed.Body.Outlines)
+	return &parsed
+}
+
+// outlineShape lists outlines as "text [kind]" lines indented by depth, with size and
+// unreadable attributes when set.
+func outlineShape(outlines []parsedOutline) string {
+	var shape strings.Builder
+	var walk func(outlines []parsedOutline, indent string)
+	walk = func(outlines []parsedOutline, indent string) {
+		for _, outline := range outlines {
+			fmt.Fprintf(&shape, "%s%s [%s]", indent, *outline.Text, outline.Kind)
+			if outline.Size != "" {
+				fmt.Fprintf(&shape, " size=%s", outline.Size)
+			}
+			if outline.Unreadable != "" {
+				fmt.Fprintf(&shape, " unreadable=%s", outline.Unreadable)
+			}
+			shape.WriteString("\n")
+			walk(outline.Children, indent+"  ")
+		}
+	}
+	walk(outlines, "")
+	return shape.String()
+}
+
+func TestOPMLDocument(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ShowSizes = true
+	parsed := parseOPML(t, NewOPMLTreeRenderer(opts).RenderTree(fixtureTree()))
+
+	if parsed.Head.Title != "/work/project" {
+		t.Errorf("title %q, want the root path", parsed.Head.Title)
+	}
+	want := "src [directory] size=2160\n" +
+		"  main.go [file] size=12\n" +
+		"  run.sh [file] size=2048\n" +
+		"  lib [directory] size=100\n" +
+		"    util.go [file] size=100\n" +
+		"docs [file] size=0\n" +
+		"old [file] size=0\n" +
+		"README.md [file] size=300\n"
+	if got := outlineShape(parsed.Body.Outlines); got != want {
+		t.Errorf("outlines:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOPMLEscaping(t *testing.T) {
+	names := []string{
+		`<script>alert("x")</script>`,
+		"fish & chips",
+		`"double" and 'single'`,
+		"]]> ends CDATA",
+		"tab\there",
+		"line\nbreak",
+		"&amp; already escaped",
+		"naïve café ✓",
+	}
+	root := &scanner.TreeNode{Name: "root", Path: `/tmp/<a & b>`, IsDir: true}
+	for _, name := range names {
+		root.Children = append(root.Children, &scanner.TreeNode{Name: name, Parent: root})
+	}
+	parsed := parseOPML(t, NewOPMLTreeRenderer(DefaultOptions()).RenderTree(root))
+
+	if parsed.Head.Title != root.Path {
+		t.Errorf("title %q, want %q", parsed.Head.Title, root.Path)
+	}
+	if len(parsed.Body.Outlines) != len(names) {
+		t.Fatalf("%d outlines, want %d", len(parsed.Body.Outlines), len(names))
+	}
+	for i, name := range names {
+		if got := *parsed.Body.Outlines[i].Text; got != name {
+			t.Errorf("name %q read back as %q", name, got)
+		}
+	}
+}
+
+func TestOPMLInvalidCharacters(t *testing.T) {
+	// XML 1.0 cannot hold most control characters; they are replaced rather than breaking the document
+	root := &scanner.TreeNode{Name: "root", Path: "/tmp/root", IsDir: true}
+	root.Children = []*scanner.TreeNode{{Name: "bell\a\x01", Parent: root}}
+	parsed := parseOPML(t, NewOPMLTreeRenderer(DefaultOptions()).RenderTree(root))
+	if got := *parsed.Body.Outlines[0].Text; got != "bell��" {
+		t.Errorf("name read back as %q, want the control characters replaced", got)
+	}
+}
+
+func TestOPMLEmptyTree(t *testing.T) {
+	root := &scanner.TreeNode{Name: "empty", Path: "/tmp/empty", IsDir: true}
+	parsed := parseOPML(t, NewOPMLTreeRenderer(DefaultOptions()).RenderTree(root))
+	if len(parsed.Body.Outlines) != 0 {
+		t.Errorf("%d outlines for an empty folder", len(parsed.Body.Outlines))
+	}
+}
+
+func TestOPMLOptions(t *testing.T) {
+	root := fixtureTree()
+	src := root.Children[0]
+	src.Children[2].Unreadable = true // lib
+	src.Children[0].ExportIgnore = true
+	root.Children[3].Name = "secret-README.md"
+
+	redactor, err := NewRedactor([]string{"secret", "work"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.MaxDepth = 2
+	opts.HideExportIgnored = true
+	opts.MarkUnreadable = true
+	opts.Redactor = redactor
+	parsed := parseOPML(t, NewOPMLTreeRenderer(opts).RenderTree(root))
+
+	if strings.Contains(parsed.Head.Title, "work") {
+		t.Errorf("title %q is not redacted", parsed.Head.Title)
+	}
+	want := "src [directory]\n" +
+		"  run.sh [file]\n" +
+		"  lib [directory] unreadable=true\n" +
+		"docs [file]\n" +
+		"old [file]\n" +
+		redactor.Apply("secret") + "-README.md [file]\n"
+	if got := outlineShape(parsed.Body.Outlines); got != want {
+		t.Errorf("outlines:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOPMLPlaceholders(t *testing.T) {
+	root := &scanner.TreeNode{Name: "root", Path: "/tmp/root", IsDir: true, Omitted: 3, TruncateReason: scanner.TruncateUnreadable}
+	root.Children = []*scanner.TreeNode{{Name: "kept.txt", Parent: root}}
+	parsed := parseOPML(t, NewOPMLTreeRenderer(DefaultOptions()).RenderTree(root))
+	want := "kept.txt [file]\n… 3 more entries not shown (" + string(scanner.TruncateUnreadable) + ") [omitted]\n"
+	if got := outlineShape(parsed.Body.Outlines); got != want {
+		t.Errorf("outlines:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestForPath(t *testing.T) {
+	tests := map[string]string{
+		"tree.opml":    "*renderer.OPMLTreeRenderer",
+		"TREE.OPML":    "*renderer.OPMLTreeRenderer",
+		"tree.html":    "*renderer.HTMLTreeRenderer",
+		"tree.htm":     "*renderer.HTMLTreeRenderer",
+		"tree.outline": "*renderer.OutlineRenderer",
+		"tree.txt":     "<nil>",
+		"tree":         "<nil>",
+	}
+	for path, want := range tests {
+		if got := fmt.Sprintf("%T", ForPath(path, DefaultOptions(), false)); got != want {
+			t.Errorf("ForPath(%q) is %s, want %s", path, got, want)
+		}
+	}
+}
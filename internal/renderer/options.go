@@ -0,0 +1,315 @@
+package renderer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// RendererOptions controls how renderers draw a tree. Renderers copy the options
+// they are constructed with, so a value can be shared between goroutines.
+type RendererOptions struct {
+	FolderIcon string // Prefix for directories ("" for none)
+	FileIcon   string // Prefix for files ("" for none)
+
+	KindIcons map[scanner.Kind]string // Prefixes for files of some kinds, replacing FileIcon (nil for none)
+
+	Branch     string // Connector before a child that has later siblings
+	LastBranch string // Connector before the last child
+	Vertical   string // Indentation below a child that has later siblings
+	Spacing    string // Indentation below the last child
+
+	// Header and Footer surround the tree; {path}, {name} and {count} are replaced
+	// with the root path, root name and total number of nodes, and {filters} with
+	// " (including: ...; excluding: ...)" listing the patterns, or nothing when there are none.
+	Header string
+	Footer string
+
+	IncludePatterns []string // Patterns the tree was limited to, for the {filters} placeholder
+	ExcludePatterns []string // Patterns the tree was filtered by, for the {filters} placeholder
+
+	RelativePaths bool   // Show each entry's path relative to the root instead of its name
+	ShowSizes     bool   // Append sizes, with directories showing the sum of their contents
+	SizeBasis     string // config.SizeApparent (default) or config.SizeAllocated
+	ShowCounts    bool   // Append the number of direct children to directories
+	ShowTimes     bool   // Append modification dates, where they were collected
+	ShowModes     bool   // Prefix entries with their type and permissions, like "drwxr-xr-x"; needs collected modes
+	ShowTokens    bool   // Append the estimated tokens of the contents of directories that were read; needs estimated tokens
+	MaxDepth      int    // Deepest level drawn, the root's children being level 1 (0 = unlimited)
+	OutlineIndent string // Indentation per level of OutlineRenderer output ("" = DefaultOutlineIndent)
+
+	HideExportIgnored bool // Leave out entries marked export-ignore in .gitattributes
+	QuoteNames        bool // Quote names that contain connectors, icons or edge whitespace (see QuoteName)
+	MarkUnreadable    bool // Append unreadableMark to directories that could not be listed
+	OnlyXattrs        bool // Draw only entries with extended attributes and the directories leading to them
+
+	Style AnnotationStyle // How colored and diff output marks entries
+
+	// Redactor masks sensitive text in names, link targets, annotations, the header and the
+	// footer of every renderer (nil for none)
+	Redactor *Redactor
+
+	// MaxBytes cuts plain and colored text output before the first line that would not fit,
+	// ending it with a "#TRUNCATED items_omitted=N" line within the limit (0 = no limit)
+	MaxBytes int64
+}
+
+// unreadableMark follows directories that could not be listed, with RendererOptions.MarkUnreadable.
+const unreadableMark = "⚠"
+
+// MountMark follows directories on another filesystem that were not read, with
+// config.Config.OneFileSystem.
+const MountMark = "[mount]"
+
+// DefaultOptions returns the options for the standard output format.
+func DefaultOptions() RendererOptions {
+	return RendererOptions{
+		FolderIcon: folderIcon,
+		FileIcon:   fileIcon,
+		Branch:     treeBranch + " ",
+		LastBranch: treeLastBranch + " ",
+		Vertical:   treeConnection,
+		Spacing:    treeSpacing,
+		Header:     "File Tree for: {path}{filters}\n" + strings.Repeat("=", 50) + "\n\n",
+	}
+}
+
+// DefaultKindIcons returns the icons for RendererOptions.KindIcons that tell files of different
+// kinds apart; plain text and other files keep FileIcon.
+func DefaultKindIcons() map[scanner.Kind]string {
+	return map[scanner.Kind]string{
+		scanner.KindCode:     "⚙",
+		scanner.KindImage:    "🖼",
+		scanner.KindDocument: "📝",
+		scanner.KindArchive:  "📦",
+		scanner.KindBinary:   "💾",
+	}
+}
+
+// ASCIIOptions returns the standard options drawn with plain ASCII and without icons.
+func ASCIIOptions() RendererOptions {
+	opts := DefaultOptions()
+	opts.FolderIcon = ""
+	opts.FileIcon = ""
+	opts.Branch = "|-- "
+	opts.LastBranch = "`-- "
+	opts.Vertical = "|   "
+	return opts
+}
+
+// expand fills the {path}, {name}, {count} and {filters} placeholders of a header or footer template.
+func (o *RendererOptions) expand(template string, root *scanner.TreeNode) string {
+	if strings.Contains(template, "{count}") {
+		template = strings.ReplaceAll(template, "{count}", fmt.Sprint(countNodes(root)))
+	}
+	return o.Redactor.Apply(strings.NewReplacer("{path}", root.Path, "{name}", root.Name, "{filters}", o.filters()).Replace(template))
+}
+
+// filters describes the include and exclude patterns for the header, or returns "" when there are none.
+func (o *RendererOptions) filters() string {
+	var parts []string
+	if len(o.IncludePatterns) > 0 {
+		parts = append(parts, "including: "+strings.Join(o.IncludePatterns, ", "))
+	}
+	if len(o.ExcludePatterns) > 0 {
+		parts = append(parts, "excluding: "+strings.Join(o.ExcludePatterns, ", "))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, "; ") + ")"
+}
+
+// countNodes returns the number of nodes in the tree rooted at node.
+func countNodes(node *scanner.TreeNode) int {
+	count := 1
+	for _, child := range node.Children {
+		count += countNodes(child)
+	}
+	return count
+}
+
+// iconAndName returns a node's icon and display name, with a trailing slash for directories
+// and " -> target" after symbolic links.
+func (o *RendererOptions) iconAndName(node, root *scanner.TreeNode) (string, string) {
+	if node.Placeholder {
+		return "", node.Name
+	}
+	name := node.Name
+	if o.RelativePaths {
+		if rel, err := filepath.Rel(root.Path, node.Path); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+	}
+	name = o.Redactor.Apply(name)
+	if o.QuoteNames {
+		name = QuoteName(name, o.markers()...)
+	}
+	icon := o.FileIcon
+	if kindIcon, ok := o.KindIcons[node.Kind]; ok {
+		icon = kindIcon
+	}
+	if node.IsDir {
+		icon, name = o.FolderIcon, name+"/"
+	}
+	if node.IsSymlink && node.ShownTarget() != "" {
+		target := o.Redactor.Apply(node.ShownTarget())
+		if o.QuoteNames {
+			target = QuoteName(target, o.markers()...)
+		}
+		name += linkArrow + target
+	}
+	return icon, name
+}
+
+// details returns the optional size or count suffix and the annotations for a node, including a leading space.
+func (o *RendererOptions) details(node *scanner.TreeNode) string {
+	if node.Placeholder {
+		return ""
+	}
+	var parts []string
+	if node.LinkBroken && node.ShownTarget() != "" {
+		parts = append(parts, "broken") // After the target: "link -> target (broken)"
+	} else if node.LinkBroken {
+		parts = append(parts, "broken link")
+	}
+	if o.ShowCounts && node.IsDir {
+		parts = append(parts, itemsLabel(len(node.Children)))
+	}
+	if o.ShowSizes {
+		parts = append(parts, SizeLabel(node, o.SizeBasis, FormatSize))
+	}
+	if o.ShowTimes && !node.ModTime.IsZero() {
+		parts = append(parts, node.ModTime.Format("2006-01-02"))
+	}
+	if node.Lines > 0 {
+		parts = append(parts, LinesLabel(node.Lines))
+	}
+	if o.ShowTokens && node.IsDir && !node.NotRead && !node.Truncated {
+		parts = append(parts, TokensLabel(node.Tokens, locale.New(locale.Portable)))
+	}
+	if len(node.Xattrs) > 0 {
+		parts = append(parts, "xattrs: "+strings.Join(node.Xattrs, ", "))
+	}
+	suffix := ""
+	if len(parts) > 0 {
+		suffix = " (" + strings.Join(parts, ", ") + ")"
+	}
+	if node.MountPoint {
+		suffix += " " + MountMark
+	}
+	if o.MarkUnreadable && node.Unreadable {
+		suffix += " " + unreadableMark
+	}
+	return suffix + o.Redactor.Apply(annotate.Suffix(node))
+}
+
+// itemsLabel returns a directory's number of children for display, like "3 items".
+func itemsLabel(items int) string {
+	if items == 1 {
+		return "1 item"
+	}
+	return fmt.Sprintf("%d items", items)
+}
+
+// LinesLabel returns a file's line count for display, like "342 lines".
+func LinesLabel(lines int) string {
+	if lines == 1 {
+		return "1 line"
+	}
+	return fmt.Sprintf("%d lines", lines)
+}
+
+// TokensLabel returns an estimated token count for display, like "≈ 48k tokens".
+func TokensLabel(tokens int, f *locale.Formatter) string {
+	return "≈ " + f.Compact(tokens) + " tokens"
+}
+
+// modePrefix returns the permissions leading a node's entry with ShowModes, or "".
+func (o *RendererOptions) modePrefix(node *scanner.TreeNode) string {
+	if !o.ShowModes || node.Placeholder {
+		return ""
+	}
+	return ModeString(node.Mode) + " "
+}
+
+// entry joins an icon and the remaining entry text, omitting the space when there is no icon.
+func entry(icon, text string) string {
+	if icon == "" {
+		return text
+	}
+	return icon + " " + text
+}
+
+// beyondDepth reports whether a node at depth is below the depth limit.
+func (o *RendererOptions) beyondDepth(depth int) bool {
+	return o.MaxDepth > 0 && depth > o.MaxDepth
+}
+
+// children returns the node's children that should be drawn, followed by placeholders for
+// what the scan left out of it.
+func (o *RendererOptions) children(node *scanner.TreeNode) []*scanner.TreeNode {
+	placeholders := Placeholders(node)
+	if o.OnlyXattrs {
+		placeholders = nil // They stand in for entries whose attributes are unknown
+	}
+	if !o.HideExportIgnored && !o.OnlyXattrs && len(placeholders) == 0 {
+		return node.Children
+	}
+	visible := make([]*scanner.TreeNode, 0, len(node.Children)+len(placeholders))
+	for _, child := range node.Children {
+		if o.HideExportIgnored && child.ExportIgnore {
+			continue
+		}
+		if o.OnlyXattrs && !hasXattrs(child) {
+			continue
+		}
+		visible = append(visible, child)
+	}
+	return append(visible, placeholders...)
+}
+
+// hasXattrs reports whether node or any entry below it has extended attributes.
+func hasXattrs(node *scanner.TreeNode) bool {
+	if len(node.Xattrs) > 0 {
+		return true
+	}
+	for _, child := range node.Children {
+		if hasXattrs(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// Placeholders returns leaves standing in for what the scan left out of node, so a directory
+// cut short never reads as empty: a count of the entries omitted, or a line naming why its
+// contents are missing, such as "… (depth limit)".
+func Placeholders(node *scanner.TreeNode) []*scanner.TreeNode {
+	var names []string
+	switch {
+	case node.Omitted > 0 && node.TruncateReason != "":
+		names = append(names, fmt.Sprintf("… %d more entries not shown (%s)", node.Omitted, node.TruncateReason))
+	case node.Omitted > 0:
+		names = append(names, fmt.Sprintf("… %d more entries not shown", node.Omitted))
+	case node.TruncateReason != "":
+		names = append(names, "… ("+node.TruncateReason+")")
+	case node.Truncated:
+		names = append(names, "… (depth cap)") // Trees saved before reasons were recorded
+	}
+	placeholders := make([]*scanner.TreeNode, len(names))
+	for i, name := range names {
+		placeholders[i] = &scanner.TreeNode{
+			Path:        filepath.Join(node.Path, name),
+			Name:        name,
+			IsVirtual:   true,
+			Placeholder: true,
+			Parent:      node,
+		}
+	}
+	return placeholders
+}
@@ -0,0 +1,78 @@
+package renderer
+
+import (
+	"io"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// DefaultOutlineIndent indents each level of an outline when RendererOptions.OutlineIndent is empty.
+const DefaultOutlineIndent = "  "
+
+// OutlineIndent returns the indentation per outline level: one tab with tabs, otherwise width
+// spaces, at least one.
+func OutlineIndent(width int, tabs bool) string {
+	if tabs {
+		return "\t"
+	}
+	return strings.Repeat(" ", max(width, 1))
+}
+
+// OutlineRenderer renders a tree as a plain outline for tools that cannot show box-drawing
+// characters: one entry per line, indented by RendererOptions.OutlineIndent per level, with a
+// slash after directories. Icons, connectors, header and footer do not apply.
+type OutlineRenderer struct {
+	opts RendererOptions
+}
+
+// NewOutlineRenderer creates an OutlineRenderer using opts.
+func NewOutlineRenderer(opts RendererOptions) *OutlineRenderer {
+	opts.FolderIcon, opts.FileIcon, opts.KindIcons = "", "", nil
+	if opts.OutlineIndent == "" {
+		opts.OutlineIndent = DefaultOutlineIndent
+	}
+	return &OutlineRenderer{opts: opts}
+}
+
+// RenderTree renders the entries below root as an outline.
+func (r *OutlineRenderer) RenderTree(root *scanner.TreeNode) string {
+	if root == nil {
+		return ""
+	}
+
+	var builder strings.Builder
+	r.WriteTree(&builder, root) // Writing to a builder cannot fail
+	return builder.String()
+}
+
+// WriteTree renders root as with RenderTree, writing each line to w as it is drawn.
+func (r *OutlineRenderer) WriteTree(w io.Writer, root *scanner.TreeNode) error {
+	if root == nil {
+		return nil
+	}
+	counter, ok := w.(*CountingWriter)
+	if !ok {
+		counter = NewCountingWriter(w)
+	}
+	lines := newLineBudget(counter, &r.opts, root)
+	r.writeChildren(lines, root, root, 1)
+	return lines.finish(&r.opts)
+}
+
+// writeChildren writes the entries below node, which are at depth, and their contents.
+func (r *OutlineRenderer) writeChildren(lines *lineBudget, root, node *scanner.TreeNode, depth int) {
+	if r.opts.beyondDepth(depth) {
+		return
+	}
+	indent := strings.Repeat(r.opts.OutlineIndent, depth-1)
+	for _, child := range r.opts.children(node) {
+		if lines.cut {
+			lines.skip(child)
+		} else {
+			_, name := r.opts.iconAndName(child, root)
+			lines.write(indent+r.opts.modePrefix(child)+name+r.opts.details(child)+"\n", child)
+		}
+		r.writeChildren(lines, root, child, depth+1)
+	}
+}
@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// outlineTree returns a tree nested five levels deep beside a chain of single-child directories:
+//
+//	root
+//	├── a/b/c/d/deep.txt
+//	├── chain/only/child/leaf.go
+//	└── top.md
+func outlineTree() *scanner.TreeNode {
+	dir := func(name string, children ...*scanner.TreeNode) *scanner.TreeNode {
+		return &scanner.TreeNode{Name: name, IsDir: true, Children: children}
+	}
+	file := func(name string) *scanner.TreeNode {
+		return &scanner.TreeNode{Name: name}
+	}
+	return dir("root",
+		dir("a", dir("b", dir("c", dir("d", file("deep.txt"))), file("b.txt"))),
+		dir("chain", dir("only", dir("child", file("leaf.go")))),
+		file("top.md"),
+	)
+}
+
+// outlineGolden is the outline of outlineTree with "\t" standing for one level of indentation.
+const outlineGolden = "a/\n" +
+	"\tb/\n" +
+	"\t\tc/\n" +
+	"\t\t\td/\n" +
+	"\t\t\t\tdeep.txt\n" +
+	"\t\tb.txt\n" +
+	"chain/\n" +
+	"\tonly/\n" +
+	"\t\tchild/\n" +
+	"\t\t\tleaf.go\n" +
+	"top.md\n"
+
+func TestOutlineGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		indent string
+	}{
+		{"default", ""},
+		{"two spaces", OutlineIndent(2, false)},
+		{"four spaces", OutlineIndent(4, false)},
+		{"tabs", OutlineIndent(4, true)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.OutlineIndent = tt.indent
+			unit := tt.indent
+			if unit == "" {
+				unit = DefaultOutlineIndent
+			}
+			want := strings.ReplaceAll(outlineGolden, "\t", unit)
+			if got := NewOutlineRenderer(opts).RenderTree(outlineTree()); got != want {
+				t.Errorf("outline:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestOutlineDepthLimit(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MaxDepth = 2
+	want := "a/\n  b/\nchain/\n  only/\ntop.md\n"
+	if got := NewOutlineRenderer(opts).RenderTree(outlineTree()); got != want {
+		t.Errorf("outline to depth 2:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOutlineIndent(t *testing.T) {
+	for _, tt := range []struct {
+		width int
+		tabs  bool
+		want  string
+	}{
+		{2, false, "  "},
+		{4, false, "    "},
+		{0, false, " "},
+		{-3, false, " "},
+		{8, true, "\t"},
+	} {
+		if got := OutlineIndent(tt.width, tt.tabs); got != tt.want {
+			t.Errorf("OutlineIndent(%d, %v) = %q, want %q", tt.width, tt.tabs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package renderer
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// linkArrow separates a symbolic link from its target in rendered output.
+const linkArrow = " -> "
+
+// QuoteName returns name as a double-quoted Go string literal when it could be misread in
+// line-based output: when it contains one of markers, the link arrow, a non-printable
+// character, leading or trailing whitespace, or starts with a quote. Other names are
+// returned unchanged. UnquoteName reverses it.
+func QuoteName(name string, markers ...string) string {
+	if needsQuoting(name, markers) {
+		return strconv.Quote(name)
+	}
+	return name
+}
+
+// UnquoteName reverses QuoteName, returning text unchanged when it is not a quoted name.
+func UnquoteName(text string) string {
+	if !strings.HasPrefix(text, `"`) {
+		return text
+	}
+	if name, err := strconv.Unquote(text); err == nil {
+		return name
+	}
+	return text
+}
+
+// needsQuoting reports whether QuoteName must quote name.
+func needsQuoting(name string, markers []string) bool {
+	if name == "" || strings.HasPrefix(name, `"`) || strings.Contains(name, linkArrow) {
+		return true
+	}
+	if strings.TrimSpace(name) != name {
+		return true
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	for _, marker := range markers {
+		if marker != "" && strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// markers returns the strings whose presence in a name makes it ambiguous with these options:
+// the connectors and icons, without their padding.
+func (o *RendererOptions) markers() []string {
+	var markers []string
+	for _, s := range []string{o.Branch, o.LastBranch, o.Vertical, o.Spacing, o.FolderIcon, o.FileIcon} {
+		if trimmed := strings.TrimSpace(s); trimmed != "" {
+			markers = append(markers, trimmed)
+		}
+	}
+	for _, icon := range o.KindIcons {
+		if trimmed := strings.TrimSpace(icon); trimmed != "" {
+			markers = append(markers, trimmed)
+		}
+	}
+	return markers
+}
@@ -0,0 +1,200 @@
+package renderer
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// adversarialNames are names that read as tree structure, icons, link arrows or quotes when
+// drawn unquoted.
+var adversarialNames = []string{
+	"│ inside",
+	"├── fake entry",
+	"└── fake last",
+	"|-- ascii entry",
+	"`-- ascii last",
+	"|   ascii vertical",
+	"📁 not a folder",
+	"📄 not a file",
+	" leading space",
+	"trailing space ",
+	"    four spaces",
+	"\ttab first",
+	"line\nbreak",
+	"bell\a",
+	`"starts with a quote`,
+	`ends with a quote"`,
+	"arrow -> target",
+	"a/b? no, a\\b",
+	"naïve café ✓",
+}
+
+func TestQuoteNameRoundTrip(t *testing.T) {
+	markers := DefaultOptions()
+	for _, name := range append(adversarialNames, "plain.txt", "with space.md", "") {
+		quoted := QuoteName(name, markers.markers()...)
+		if got := UnquoteName(quoted); got != name {
+			t.Errorf("%q quoted as %q unquotes to %q", name, quoted, got)
+		}
+	}
+}
+
+func TestQuoteName(t *testing.T) {
+	defaults, ascii := DefaultOptions(), ASCIIOptions()
+	tests := []struct {
+		name    string
+		markers []string
+		want    bool
+	}{
+		{"plain.txt", defaults.markers(), false},
+		{"with space.md", defaults.markers(), false},
+		{"", nil, true},
+		{"├── fake entry", defaults.markers(), true},
+		{"├── fake entry", ascii.markers(), false}, // Only the active connectors are ambiguous
+		{"|-- ascii entry", ascii.markers(), true},
+		{"|-- ascii entry", defaults.markers(), false},
+		{"📁 not a folder", defaults.markers(), true},
+		{"📁 not a folder", ascii.markers(), false},
+		{" leading space", nil, true},
+		{"trailing space ", nil, true},
+		{"line\nbreak", nil, true},
+		{`"starts with a quote`, nil, true},
+		{`ends with a quote"`, nil, false},
+		{"arrow -> target", nil, true},
+		{"arrow->target", nil, false},
+	}
+	for _, tt := range tests {
+		quoted := QuoteName(tt.name, tt.markers...)
+		if got := quoted != tt.name; got != tt.want {
+			t.Errorf("QuoteName(%q) = %q; quoted %v, want %v", tt.name, quoted, got, tt.want)
+		}
+		if tt.want && quoted != strconv.Quote(tt.name) {
+			t.Errorf("QuoteName(%q) = %q, want a Go string literal", tt.name, quoted)
+		}
+	}
+	if got := UnquoteName(`"unterminated`); got != `"unterminated` {
+		t.Errorf("UnquoteName of a malformed literal = %q, want it unchanged", got)
+	}
+}
+
+// parsedEntry is an entry read back from drawn text.
+type parsedEntry struct {
+	depth  int
+	name   string
+	isDir  bool
+	target string
+}
+
+// parseTree reads entries back from text drawn with opts and QuoteNames, using only what a
+// consumer of the text has: the connectors, icons and quoting. The root's first child is drawn
+// without a connector, so it is read as the first entry of depth 1.
+func parseTree(t *testing.T, text string, opts RendererOptions) []parsedEntry {
+	t.Helper()
+	units := []string{opts.Branch, opts.LastBranch, opts.Vertical, opts.Spacing}
+	var entries []parsedEntry
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		depth := 0
+		for stripped := true; stripped; {
+			stripped = false
+			for _, unit := range units {
+				if rest, ok := strings.CutPrefix(line, unit); ok {
+					line, depth, stripped = rest, depth+1, true
+					break
+				}
+			}
+		}
+		entry := parsedEntry{depth: max(depth, 1)}
+		if rest, ok := strings.CutPrefix(line, opts.FolderIcon+" "); ok && opts.FolderIcon != "" {
+			line, entry.isDir = rest, true
+		} else if rest, ok := strings.CutPrefix(line, opts.FileIcon+" "); ok && opts.FileIcon != "" {
+			line = rest
+		}
+
+		var rest string
+		if strings.HasPrefix(line, `"`) {
+			literal, err := strconv.QuotedPrefix(line)
+			if err != nil {
+				t.Fatalf("malformed quoted name in %q: %v", line, err)
+			}
+			entry.name, rest = UnquoteName(literal), line[len(literal):]
+		} else {
+			entry.name, rest, _ = strings.Cut(line, linkArrow)
+			if rest != "" {
+				rest = linkArrow + rest
+			}
+			if name, ok := strings.CutSuffix(entry.name, "/"); ok {
+				entry.name, rest = name, "/"+rest
+			}
+		}
+		if after, ok := strings.CutPrefix(rest, "/"); ok {
+			entry.isDir, rest = true, after
+		}
+		if target, ok := strings.CutPrefix(rest, linkArrow); ok {
+			entry.target = UnquoteName(target)
+		} else if rest != "" {
+			t.Fatalf("unexpected %q after the name in %q", rest, line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// adversarialTree returns a tree whose entries below the first have adversarial names, in
+// directories of their own and as link targets, with the entries it holds in drawing order.
+func adversarialTree() (*scanner.TreeNode, []parsedEntry) {
+	root := &scanner.TreeNode{Name: "project", Path: "/work/project", IsDir: true}
+	var want []parsedEntry
+	add := func(parent *scanner.TreeNode, depth int, node *scanner.TreeNode) *scanner.TreeNode {
+		node.Parent, node.Path = parent, path.Join(parent.Path, node.Name)
+		parent.Children = append(parent.Children, node)
+		want = append(want, parsedEntry{depth: depth, name: node.Name, isDir: node.IsDir, target: node.LinkTarget})
+		return node
+	}
+	add(root, 1, &scanner.TreeNode{Name: "README.md"})
+	for _, name := range adversarialNames {
+		dir := add(root, 1, &scanner.TreeNode{Name: name, IsDir: true})
+		add(dir, 2, &scanner.TreeNode{Name: name})
+		add(dir, 2, &scanner.TreeNode{Name: "link", IsSymlink: true, LinkTarget: name})
+	}
+	add(root, 1, &scanner.TreeNode{Name: "last.txt"})
+	return root, want
+}
+
+func TestQuotedNamesRoundTrip(t *testing.T) {
+	for name, opts := range map[string]RendererOptions{"default": DefaultOptions(), "ASCII": ASCIIOptions()} {
+		t.Run(name, func(t *testing.T) {
+			opts.Header = ""
+			opts.QuoteNames = true
+			root, want := adversarialTree()
+			text := NewStandardTreeRenderer(opts).RenderTree(root)
+
+			got := parseTree(t, text, opts)
+			if len(got) != len(want) {
+				t.Fatalf("read %d entries back, want %d:\n%s", len(got), len(want), text)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("entry %d read back as %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUnquotedNamesAreAmbiguous(t *testing.T) {
+	// Without quoting the same text cannot be read back, which is what QuoteNames is for
+	opts := DefaultOptions()
+	opts.Header = ""
+	root, want := adversarialTree()
+	text := NewStandardTreeRenderer(opts).RenderTree(root)
+	if lines := strings.Count(text, "\n"); lines == len(want) {
+		t.Errorf("%d lines for %d entries, want the line break to add one", lines, len(want))
+	}
+	if !strings.Contains(text, "\n├── 📁 ├── fake entry/\n") {
+		t.Errorf("unquoted output does not hold the name as is:\n%s", text)
+	}
+}
@@ -0,0 +1,129 @@
+package renderer
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RedactionMask replaces redacted text unless pseudonyms are used.
+const RedactionMask = "▇▇▇"
+
+// regexRulePrefix marks a redaction rule as a regular expression rather than literal text.
+const regexRulePrefix = "re:"
+
+// pseudonymLength is the number of hex digits of a pseudonym's hash.
+const pseudonymLength = 8
+
+// Redactor masks sensitive text, such as user or client names, in rendered output. A nil
+// Redactor leaves text unchanged.
+type Redactor struct {
+	rules      []*regexp.Regexp
+	pseudonyms bool
+}
+
+// NewRedactor creates a Redactor for rules, each literal text or, with a "re:" prefix, a regular
+// expression. A regular expression with capturing groups masks only what its groups match, so
+// "re:/home/([^/]+)" keeps "/home/" visible. With pseudonyms, each distinct match is replaced
+// with a stable name like "anon-1f2e3d4c" instead of RedactionMask. Blank rules are ignored,
+// and NewRedactor returns nil when none remain.
+func NewRedactor(rules []string, pseudonyms bool) (*Redactor, error) {
+	r := &Redactor{pseudonyms: pseudonyms}
+	for _, rule := range rules {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		expr := regexp.QuoteMeta(rule)
+		if pattern, ok := strings.CutPrefix(rule, regexRulePrefix); ok {
+			expr = pattern
+		}
+		compiled, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction rule %q: %w", rule, err)
+		}
+		r.rules = append(r.rules, compiled)
+	}
+	if len(r.rules) == 0 {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// Apply returns text with every match of the redactor's rules replaced. Each rule matches on
+// its own, so its quantifiers keep their meaning; text matched by more than one rule is
+// replaced once, as a single span covering all of their matches.
+func (r *Redactor) Apply(text string) string {
+	if r == nil {
+		return text
+	}
+	var spans [][2]int
+	for _, rule := range r.rules {
+		for _, match := range rule.FindAllStringSubmatchIndex(text, -1) {
+			spans = append(spans, ruleSpans(match)...)
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, span := range mergeSpans(spans) {
+		builder.WriteString(text[last:span[0]])
+		builder.WriteString(r.replacement(text[span[0]:span[1]]))
+		last = span[1]
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
+
+// ruleSpans returns the non-empty ranges of a rule's match to replace, in order: those of the
+// rule's groups when it has any, the whole match otherwise. Groups nested in an earlier group
+// are covered by it.
+func ruleSpans(match []int) [][2]int {
+	if len(match) == 2 {
+		if match[0] == match[1] {
+			return nil
+		}
+		return [][2]int{{match[0], match[1]}}
+	}
+	var spans [][2]int
+	end := -1
+	for group := 1; group < len(match)/2; group++ {
+		start, stop := match[2*group], match[2*group+1]
+		if start < 0 || start == stop || start < end {
+			continue
+		}
+		spans = append(spans, [2]int{start, stop})
+		end = stop
+	}
+	return spans
+}
+
+// mergeSpans sorts spans and joins those that overlap. Spans that only touch stay apart.
+func mergeSpans(spans [][2]int) [][2]int {
+	slices.SortFunc(spans, func(a, b [2]int) int { return cmp.Compare(a[0], b[0]) })
+	merged := spans[:1]
+	for _, span := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if span[0] < last[1] {
+			last[1] = max(last[1], span[1])
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// replacement returns what replaces matched text.
+func (r *Redactor) replacement(matched string) string {
+	if !r.pseudonyms {
+		return RedactionMask
+	}
+	sum := sha256.Sum256([]byte(matched))
+	return "anon-" + hex.EncodeToString(sum[:])[:pseudonymLength]
+}
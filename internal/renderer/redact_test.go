@@ -0,0 +1,71 @@
+package renderer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactorApply(t *testing.T) {
+	const m = RedactionMask
+	tests := []struct {
+		name  string
+		rules []string
+		text  string
+		want  string
+	}{
+		{"literal", []string{"alice"}, "/home/alice/alice.txt", "/home/" + m + "/" + m + ".txt"},
+		{"literal with metacharacters", []string{"a.b"}, "a.b axb", m + " axb"},
+		{"no match", []string{"bob"}, "/home/alice", "/home/alice"},
+		{"lazy quantifier stays lazy", []string{`re:/home/(.+?)/`}, "/home/alice/projects/x/y", "/home/" + m + "/projects/x/y"},
+		{"greedy quantifier", []string{`re:/home/(.+)/`}, "/home/alice/projects/x/y", "/home/" + m + "/y"},
+		{"longer rule covers shorter", []string{"alice", "alice-smith"}, "alice-smith/notes", m + "/notes"},
+		{"shorter rule listed last", []string{"alice-smith", "alice"}, "alice-smith/notes", m + "/notes"},
+		{"partial overlap joins", []string{"acme-co", "co-op"}, "acme-co-op.txt", m + ".txt"},
+		{"touching matches stay apart", []string{"ab", "cd"}, "abcd", m + m},
+		{"groups mask only themselves", []string{`re:/home/([^/]+)/(\w+)`}, "/home/alice/work/x", "/home/" + m + "/" + m + "/x"},
+		{"nested group inside its parent", []string{`re:/u/((a)lice)`}, "/u/alice", "/u/" + m},
+		{"group that did not take part", []string{`re:user-(\d+)?x`}, "user-x user-7x", "user-x user-" + m + "x"},
+		{"group beside another rule", []string{`re:/home/([^/]+)`, "home"}, "/home/alice", "/" + m + "/" + m},
+		{"group overlapping another rule", []string{`re:/home/([^/]+)`, "ice/wo"}, "/home/alice/work", "/home/" + m + "rk"},
+		{"blank rules ignored", []string{"", "  ", "alice"}, "alice", m},
+	}
+	for _, tt := range tests {
+		r, err := NewRedactor(tt.rules, false)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := r.Apply(tt.text); got != tt.want {
+			t.Errorf("%s: %q gives %q, want %q", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRedactorPseudonyms(t *testing.T) {
+	r, err := NewRedactor([]string{`re:/home/([^/]+)`}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := r.Apply("/home/alice/a")
+	if strings.Contains(first, "alice") || !strings.HasPrefix(first, "/home/anon-") {
+		t.Fatalf("pseudonym not applied: %q", first)
+	}
+	if again := r.Apply("/home/alice/b"); again[:len(again)-1] != first[:len(first)-1] {
+		t.Errorf("the same name gave %q and %q", first, again)
+	}
+	if other := r.Apply("/home/bob/a"); other == first {
+		t.Errorf("different names share the pseudonym %q", other)
+	}
+}
+
+func TestNewRedactorRules(t *testing.T) {
+	if r, err := NewRedactor([]string{"", " "}, false); r != nil || err != nil {
+		t.Errorf("blank rules gave %v, %v; want nil", r, err)
+	}
+	if _, err := NewRedactor([]string{"re:(unclosed"}, false); err == nil || !strings.Contains(err.Error(), "re:(unclosed") {
+		t.Errorf("invalid expression gave %v, want an error naming the rule", err)
+	}
+	var r *Redactor
+	if got := r.Apply("alice"); got != "alice" {
+		t.Errorf("nil redactor changed the text to %q", got)
+	}
+}
@@ -1,19 +1,23 @@
 package renderer
 
 import (
-	"fmt"
+	"io"
 	"strings"
 
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
 	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
 )
 
+func init() {
+	capability.Register(capability.Render)
+}
+
 const (
 	// Icons
 	folderIcon = "📁"
 	fileIcon   = "📄"
 
 	// Tree drawing characters
-	treeVertical   = "│"
 	treeBranch     = "├──"
 	treeLastBranch = "└──"
 	treeSpacing    = "    "
@@ -25,8 +29,30 @@ type TreeRenderer interface {
 	RenderTree(root *scanner.TreeNode) string
 }
 
+// TreeWriter is implemented by renderers that can write a tree line by line as it is drawn.
+type TreeWriter interface {
+	WriteTree(w io.Writer, root *scanner.TreeNode) error
+}
+
 // StandardTreeRenderer implements TreeRenderer for standard tree visualization.
-type StandardTreeRenderer struct{}
+// The zero value renders with DefaultOptions.
+type StandardTreeRenderer struct {
+	opts *RendererOptions
+}
+
+// NewStandardTreeRenderer creates a StandardTreeRenderer using opts.
+func NewStandardTreeRenderer(opts RendererOptions) *StandardTreeRenderer {
+	return &StandardTreeRenderer{opts: &opts}
+}
+
+// options returns the renderer's options, falling back to the defaults.
+func (r *StandardTreeRenderer) options() *RendererOptions {
+	if r.opts == nil {
+		opts := DefaultOptions()
+		return &opts
+	}
+	return r.opts
+}
 
 // RenderTree renders a tree structure as a formatted string.
 func (r *StandardTreeRenderer) RenderTree(root *scanner.TreeNode) string {
@@ -35,42 +61,72 @@ func (r *StandardTreeRenderer) RenderTree(root *scanner.TreeNode) string {
 	}
 
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("File Tree for: %s\n", root.Path))
-	builder.WriteString(strings.Repeat("=", 50) + "\n\n")
+	r.WriteTree(&builder, root) // Writing to a builder cannot fail
+	return builder.String()
+}
 
-	r.renderNode(&builder, root, "", true)
+// WriteTree renders root as with RenderTree, writing each line to w as it is drawn.
+func (r *StandardTreeRenderer) WriteTree(w io.Writer, root *scanner.TreeNode) error {
+	if root == nil {
+		return nil
+	}
+	opts := r.options()
+	label := func(node *scanner.TreeNode) string {
+		icon, name := opts.iconAndName(node, root)
+		return opts.modePrefix(node) + entry(icon, name+opts.details(node))
+	}
+	return writeTree(w, opts, root, nil, label)
+}
 
-	return builder.String()
+// writeTree writes the header, the lines of the tree and the footer to w. Each entry's line
+// starts with its mark and a space when mark is set. With RendererOptions.MaxBytes, output that
+// would not fit is cut at a line boundary and ended with a marker line, and an
+// *OutputLimitError is returned.
+func writeTree(w io.Writer, opts *RendererOptions, root *scanner.TreeNode, mark, label func(*scanner.TreeNode) string) error {
+	counter, ok := w.(*CountingWriter)
+	if !ok {
+		counter = NewCountingWriter(w)
+	}
+	lines := newLineBudget(counter, opts, root)
+	lines.write(opts.expand(opts.Header, root), nil)
+	renderLines(lines, opts, root, "", "", true, 0, mark, label)
+	lines.write(opts.expand(opts.Footer, root), nil)
+	return lines.finish(opts)
 }
 
-// renderNode recursively renders a tree node.
-func (r *StandardTreeRenderer) renderNode(builder *strings.Builder, node *scanner.TreeNode, prefix string, isRoot bool) {
+// renderLines recursively draws node after lead, its connector, and its children, using label
+// for each entry's text and mark, if set, for what starts its line.
+func renderLines(lines *lineBudget, opts *RendererOptions, node *scanner.TreeNode, lead, prefix string, isRoot bool, depth int, mark, label func(*scanner.TreeNode) string) {
 	if !isRoot {
-		icon := fileIcon
-		name := node.Name
-		if node.IsDir {
-			icon = folderIcon
-			name += "/"
+		switch {
+		case lines.cut:
+			lines.skip(node) // Labels of entries that cannot be written are not worth drawing
+		case mark != nil:
+			lines.write(mark(node)+" "+lead+label(node)+"\n", node)
+		default:
+			lines.write(lead+label(node)+"\n", node)
 		}
-		builder.WriteString(fmt.Sprintf("%s %s\n", icon, name))
+	}
+	if opts.beyondDepth(depth + 1) {
+		return
 	}
 
-	for i, child := range node.Children {
-		isLast := i == len(node.Children)-1
+	children := opts.children(node)
+	for i, child := range children {
+		isLast := i == len(children)-1
 
 		var connector, nextPrefix string
 		if isRoot && i == 0 {
 			connector = ""
 			nextPrefix = ""
 		} else if isLast {
-			connector = treeLastBranch + " "
-			nextPrefix = prefix + treeSpacing
+			connector = opts.LastBranch
+			nextPrefix = prefix + opts.Spacing
 		} else {
-			connector = treeBranch + " "
-			nextPrefix = prefix + treeConnection
+			connector = opts.Branch
+			nextPrefix = prefix + opts.Vertical
 		}
 
-		builder.WriteString(prefix + connector)
-		r.renderNode(builder, child, nextPrefix, false)
+		renderLines(lines, opts, child, prefix+connector, nextPrefix, false, depth+1, mark, label)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,199 @@
+package renderer
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// fixtureTree returns a small tree holding a directory, an executable, a symbolic link and a
+// broken one, with parents and paths set:
+//
+//	/work/project
+//	├── src/        main.go (12 B), run.sh (executable, 2 KB), lib/util.go
+//	├── docs -> src
+//	├── old -> gone (broken)
+//	└── README.md   (300 B)
+func fixtureTree() *scanner.TreeNode {
+	file := func(name string, size int64) *scanner.TreeNode {
+		return &scanner.TreeNode{Name: name, Size: size, DiskSize: size}
+	}
+	dir := func(name string, children ...*scanner.TreeNode) *scanner.TreeNode {
+		return &scanner.TreeNode{Name: name, IsDir: true, Children: children}
+	}
+	run := file("run.sh", 2048)
+	run.Executable = true
+	root := dir("project",
+		dir("src", file("main.go", 12), run, dir("lib", file("util.go", 100))),
+		&scanner.TreeNode{Name: "docs", IsSymlink: true, LinkTarget: "src"},
+		&scanner.TreeNode{Name: "old", IsSymlink: true, LinkTarget: "gone", LinkBroken: true},
+		file("README.md", 300),
+	)
+	root.Path = "/work/project"
+	var link func(node *scanner.TreeNode)
+	link = func(node *scanner.TreeNode) {
+		for _, child := range node.Children {
+			child.Parent = node
+			child.Path = path.Join(node.Path, child.Name)
+			link(child)
+		}
+		if node.IsDir {
+			for _, child := range node.Children {
+				node.Size += child.Size
+				node.DiskSize += child.DiskSize
+			}
+		}
+	}
+	link(root)
+	return root
+}
+
+func TestDefaultOptions(t *testing.T) {
+	want := defaultHeader +
+		"📁 src/\n" +
+		"├── 📄 main.go\n" +
+		"├── 📄 run.sh\n" +
+		"└── 📁 lib/\n" +
+		"    └── 📄 util.go\n" +
+		"├── 📄 docs -> src\n" +
+		"├── 📄 old -> gone (broken)\n" +
+		"└── 📄 README.md\n"
+	root := fixtureTree()
+	if got := NewStandardTreeRenderer(DefaultOptions()).RenderTree(root); got != want {
+		t.Errorf("default output:\n%q\nwant:\n%q", got, want)
+	}
+	var zero StandardTreeRenderer
+	if got := zero.RenderTree(root); got != want {
+		t.Errorf("zero renderer output differs from the defaults:\n%q", got)
+	}
+}
+
+func TestCustomOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(opts *RendererOptions)
+		want      string
+	}{
+		{
+			name:      "ASCII",
+			configure: func(opts *RendererOptions) { *opts = ASCIIOptions() },
+			want: defaultHeader +
+				"src/\n" +
+				"|-- main.go\n" +
+				"|-- run.sh\n" +
+				"`-- lib/\n" +
+				"    `-- util.go\n" +
+				"|-- docs -> src\n" +
+				"|-- old -> gone (broken)\n" +
+				"`-- README.md\n",
+		},
+		{
+			name: "header and footer templates",
+			configure: func(opts *RendererOptions) {
+				opts.Header = "{name}: {count} nodes{filters}\n"
+				opts.Footer = "-- end of {path} --\n"
+				opts.ExcludePatterns = []string{"*.tmp"}
+				opts.MaxDepth = 1
+			},
+			want: "project: 9 nodes (excluding: *.tmp)\n" +
+				"📁 src/\n" +
+				"├── 📄 docs -> src\n" +
+				"├── 📄 old -> gone (broken)\n" +
+				"└── 📄 README.md\n" +
+				"-- end of /work/project --\n",
+		},
+		{
+			name: "relative paths, sizes and counts without icons",
+			configure: func(opts *RendererOptions) {
+				opts.FolderIcon, opts.FileIcon = "", ""
+				opts.Header = ""
+				opts.RelativePaths = true
+				opts.ShowSizes = true
+				opts.ShowCounts = true
+			},
+			want: "src/ (3 items, 2.1 KB)\n" +
+				"├── src/main.go (12 B)\n" +
+				"├── src/run.sh (2.0 KB)\n" +
+				"└── src/lib/ (1 item, 100 B)\n" +
+				"    └── src/lib/util.go (100 B)\n" +
+				"├── docs -> src (0 B)\n" +
+				"├── old -> gone (broken, 0 B)\n" +
+				"└── README.md (300 B)\n",
+		},
+		{
+			name: "custom connectors",
+			configure: func(opts *RendererOptions) {
+				opts.Header = ""
+				opts.Branch, opts.LastBranch = "+ ", "\\ "
+				opts.Vertical, opts.Spacing = ": ", "  "
+				opts.MaxDepth = 2
+			},
+			want: "📁 src/\n" +
+				"+ 📄 main.go\n" +
+				"+ 📄 run.sh\n" +
+				"\\ 📁 lib/\n" +
+				"+ 📄 docs -> src\n" +
+				"+ 📄 old -> gone (broken)\n" +
+				"\\ 📄 README.md\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			tt.configure(&opts)
+			if got := NewStandardTreeRenderer(opts).RenderTree(fixtureTree()); got != tt.want {
+				t.Errorf("output:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionsAreCopied(t *testing.T) {
+	opts := DefaultOptions()
+	r := NewStandardTreeRenderer(opts)
+	before := r.RenderTree(fixtureTree())
+	opts.FolderIcon, opts.Header = "D", "changed\n"
+	if after := r.RenderTree(fixtureTree()); after != before {
+		t.Error("changing the options after construction changed the output")
+	}
+}
+
+func TestOtherRenderersHonorOptions(t *testing.T) {
+	opts := DefaultOptions()
+	opts.FolderIcon, opts.FileIcon = "", ""
+	opts.RelativePaths = true
+	opts.MaxDepth = 1
+	want := "src/\n" +
+		"docs -> src\n" +
+		"old -> gone (broken)\n" +
+		"README.md\n"
+	if got := NewOutlineRenderer(opts).RenderTree(fixtureTree()); got != want {
+		t.Errorf("outline:\n%q\nwant:\n%q", got, want)
+	}
+
+	html := NewHTMLTreeRenderer(opts, false).RenderTree(fixtureTree())
+	if strings.Contains(html, "main.go") || !strings.Contains(html, "<li>src/</li>") {
+		t.Errorf("HTML does not honor the depth limit and icons:\n%s", html)
+	}
+}
+
+func TestLinksShowNormalizedTarget(t *testing.T) {
+	root := fixtureTree()
+	for _, child := range root.Children {
+		switch child.Name {
+		case "docs":
+			child.LinkTarget, child.LinkNormalized = "./src/../src", "src"
+		case "old":
+			child.LinkTarget, child.LinkNormalized = "/work/project/gone", "gone"
+		}
+	}
+	got := NewStandardTreeRenderer(DefaultOptions()).RenderTree(root)
+	for _, want := range []string{"├── 📄 docs -> src\n", "├── 📄 old -> gone (broken)\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output lacks %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package renderer
+
+import (
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// unknownSize is shown for entries whose size could not be read.
+const unknownSize = "?"
+
+// FormatSize formats a byte count with C-locale separators using binary units, e.g. "12.4 KB".
+func FormatSize(bytes int64) string {
+	return locale.New(locale.Portable).Size(bytes)
+}
+
+// SizeLabel returns a node's size in basis formatted with format, or "?" when it could not be read.
+func SizeLabel(node *scanner.TreeNode, basis string, format func(int64) string) string {
+	if node.SizeUnknown {
+		return unknownSize
+	}
+	return format(node.SizeFor(basis))
+}
@@ -0,0 +1,90 @@
+package renderer
+
+// Annotation is something about an entry that output sets apart from the rest, by color or by a
+// glyph.
+type Annotation int
+
+// Annotations output sets apart.
+const (
+	AnnotationNone Annotation = iota
+	AnnotationDirectory
+	AnnotationSymlink
+	AnnotationBrokenLink
+	AnnotationExecutable
+	AnnotationAdded    // Entry only in the newer of two scans
+	AnnotationRemoved  // Entry only in the older of two scans
+	AnnotationModified // Entry that differs between two scans
+	AnnotationFlagged  // Entry needing attention, such as one breaking the expected layout
+)
+
+// annotationColors are the ANSI sequences of the annotations colored in terminal output.
+var annotationColors = map[Annotation]string{
+	AnnotationDirectory:  "\x1b[1;34m", // Bold blue
+	AnnotationSymlink:    "\x1b[36m",   // Cyan
+	AnnotationBrokenLink: "\x1b[31m",   // Red
+	AnnotationExecutable: "\x1b[32m",   // Green
+	AnnotationAdded:      "\x1b[32m",
+	AnnotationRemoved:    "\x1b[31m",
+	AnnotationModified:   "\x1b[33m", // Yellow
+	AnnotationFlagged:    "\x1b[31m",
+}
+
+// annotationMarks are the one-character marks of changes in a diff tree, which show without color.
+var annotationMarks = map[Annotation]string{
+	AnnotationAdded:    "+",
+	AnnotationRemoved:  "-",
+	AnnotationModified: "~",
+}
+
+// annotationGlyphs replace colors with Symbols. Each annotation has its own glyph, so none relies
+// on telling two colors apart.
+var annotationGlyphs = map[Annotation]string{
+	AnnotationSymlink:    "↪",
+	AnnotationBrokenLink: "✗",
+	AnnotationExecutable: "*",
+	AnnotationAdded:      "+",
+	AnnotationRemoved:    "−",
+	AnnotationModified:   "≠",
+	AnnotationFlagged:    "●",
+}
+
+// AnnotationStyle decides how output sets annotations apart. Every output marking entries by
+// color, on screen or in a terminal, goes through it, so Symbols applies to all of them at once.
+type AnnotationStyle struct {
+	Symbols bool // Mark annotations with distinct glyphs instead of colors, for color-blind readers
+}
+
+// Color returns the ANSI sequence coloring a in terminal output, or "" when a is not colored or
+// Symbols is set.
+func (s AnnotationStyle) Color(a Annotation) string {
+	if s.Symbols {
+		return ""
+	}
+	return annotationColors[a]
+}
+
+// Colored reports whether on-screen output may set annotations apart by color, such as a tinted row.
+func (s AnnotationStyle) Colored() bool {
+	return !s.Symbols
+}
+
+// Prefix returns the glyph and a space to put before an entry marked with a, or "" unless
+// Symbols is set. Directories keep their icon and trailing slash.
+func (s AnnotationStyle) Prefix(a Annotation) string {
+	if !s.Symbols || annotationGlyphs[a] == "" {
+		return ""
+	}
+	return annotationGlyphs[a] + " "
+}
+
+// Mark returns the one-character mark starting a line of a diff tree: "+", "-" and "~" for added,
+// removed and modified entries, or with Symbols "+", "−" and "≠". It is a space for other entries.
+func (s AnnotationStyle) Mark(a Annotation) string {
+	if annotationMarks[a] == "" {
+		return " "
+	}
+	if s.Symbols {
+		return annotationGlyphs[a]
+	}
+	return annotationMarks[a]
+}
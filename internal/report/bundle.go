@@ -0,0 +1,240 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Platform describes the environment the application is running in.
+type Platform struct {
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	GoVersion string `json:"go_version"`
+	NumCPU    int    `json:"num_cpu"`
+}
+
+// Bundle is a diagnostic document attached to bug reports.
+type Bundle struct {
+	Report     *Envelope      `json:"report"`
+	LogTail    []string       `json:"log_tail"`
+	Platform   Platform       `json:"platform"`
+	Config     *config.Config `json:"config"`
+	Anonymized bool           `json:"anonymized"`
+}
+
+// CurrentPlatform returns information about the running platform.
+func CurrentPlatform() Platform {
+	return Platform{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		NumCPU:    runtime.NumCPU(),
+	}
+}
+
+// NewBundle assembles a diagnostic bundle, optionally replacing every path component with a stable
+// hash: in the tree, the errors and log lines, and the paths and patterns of the configuration.
+func NewBundle(result *scanner.ScanResult, cfg *config.Config, logTail []string, anonymize bool) *Bundle {
+	bundle := &Bundle{
+		LogTail:    logTail,
+		Platform:   CurrentPlatform(),
+		Config:     cfg,
+		Anonymized: anonymize,
+	}
+
+	if !anonymize {
+		bundle.Report = NewEnvelope(result, cfg)
+		return bundle
+	}
+
+	// The report is built from the real result, so facts such as the volume are read from the
+	// real root, and only its text is anonymized
+	anon := NewAnonymizer()
+	report := NewEnvelope(result, nil)
+	var roots []string // Paths the log and errors may mention
+	if cfg != nil {
+		roots = append(roots, cfg.ExportPaths...)
+		anon.Known(cfg.ExportPaths...)
+		bundle.Config = anon.Config(cfg)
+	}
+	report.Options = bundle.Config
+	if result != nil {
+		roots = append(roots, result.RootPath)
+		anon.Known(result.RootPath)
+		report.RootPath = anon.Path(result.RootPath)
+		report.Tree = ""
+		if result.Root != nil {
+			report.Tree = renderer.NewStandardTreeRenderer(renderer.DefaultOptions()).RenderTree(anon.Tree(result.Root, nil))
+		}
+		for _, e := range report.ScanErrors {
+			anon.Known(e.Path)
+		}
+	}
+
+	report.Errors = anon.Lines(report.Errors, roots...)
+	for i, e := range report.ScanErrors {
+		report.ScanErrors[i].Path = anon.Path(e.Path)
+		report.ScanErrors[i].Error = anon.Lines([]string{e.Error}, roots...)[0]
+	}
+	bundle.Report = report
+	bundle.LogTail = anon.Lines(logTail, roots...)
+	return bundle
+}
+
+// JSON returns the bundle as an indented JSON document.
+func (b *Bundle) JSON() (string, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Anonymizer replaces path components with short hashes; equal components always map to equal hashes.
+type Anonymizer struct {
+	cache map[string]string
+	known map[string]bool // Paths Lines can find in text, even with spaces in their names
+}
+
+// NewAnonymizer creates a new Anonymizer.
+func NewAnonymizer() *Anonymizer {
+	return &Anonymizer{cache: make(map[string]string), known: make(map[string]bool)}
+}
+
+// Known records paths that Lines should anonymize whole wherever they appear. Tree records the
+// paths of the nodes it copies.
+func (a *Anonymizer) Known(paths ...string) {
+	for _, path := range paths {
+		if path != "" {
+			a.known[path] = true
+		}
+	}
+}
+
+// Component returns the anonymized form of a single path component.
+func (a *Anonymizer) Component(name string) string {
+	if name == "" {
+		return ""
+	}
+	if hashed, ok := a.cache[name]; ok {
+		return hashed
+	}
+	sum := sha256.Sum256([]byte(name))
+	hashed := hex.EncodeToString(sum[:])[:12]
+	a.cache[name] = hashed
+	return hashed
+}
+
+// Path anonymizes every component of path while keeping its separators.
+func (a *Anonymizer) Path(path string) string {
+	var builder strings.Builder
+	start := 0
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' || path[i] == '\\' {
+			builder.WriteString(a.Component(path[start:i]))
+			builder.WriteByte(path[i])
+			start = i + 1
+		}
+	}
+	builder.WriteString(a.Component(path[start:]))
+	return builder.String()
+}
+
+// Pattern anonymizes the names in a glob pattern, keeping components made only of "*" such as
+// "**" so the pattern's shape stays readable.
+func (a *Anonymizer) Pattern(pattern string) string {
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if strings.Trim(part, "*") != "" {
+			parts[i] = a.Component(part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Config returns a copy of cfg whose paths, patterns and names are anonymized.
+func (a *Anonymizer) Config(cfg *config.Config) *config.Config {
+	clone := cfg.Clone()
+	for i, path := range clone.ExportPaths {
+		clone.ExportPaths[i] = a.Path(path)
+	}
+	for i, path := range clone.SkipPaths {
+		clone.SkipPaths[i] = a.Path(path)
+	}
+	for i, pattern := range clone.ExcludePatterns {
+		clone.ExcludePatterns[i] = a.Pattern(pattern)
+	}
+	for i, pattern := range clone.IncludePatterns {
+		clone.IncludePatterns[i] = a.Pattern(pattern)
+	}
+	for i, name := range clone.AlwaysShowNames {
+		clone.AlwaysShowNames[i] = a.Pattern(name)
+	}
+	return clone
+}
+
+// Tree returns an anonymized copy of the subtree rooted at node.
+func (a *Anonymizer) Tree(node *scanner.TreeNode, parent *scanner.TreeNode) *scanner.TreeNode {
+	a.Known(node.Path)
+	clone := &scanner.TreeNode{
+		Path:   a.Path(node.Path),
+		Name:   a.Component(node.Name),
+		IsDir:  node.IsDir,
+		Kind:   node.Kind,
+		Parent: parent,
+	}
+	for _, child := range node.Children {
+		clone.Children = append(clone.Children, a.Tree(child, clone))
+	}
+	return clone
+}
+
+// Lines anonymizes every path at or below one of roots that appears in the given text lines.
+// Where a line mentions a path recorded by Known or Tree, the longest such path is anonymized
+// whole; other names are assumed to end at the next space, quote or colon.
+func (a *Anonymizer) Lines(lines []string, roots ...string) []string {
+	out := slices.Clone(lines)
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		for i, line := range out {
+			out[i] = a.line(line, root)
+		}
+	}
+	return out
+}
+
+// line anonymizes the paths at or below root in line.
+func (a *Anonymizer) line(line, root string) string {
+	var builder strings.Builder
+	for {
+		idx := strings.Index(line, root)
+		if idx < 0 {
+			break
+		}
+		end := idx + len(root)
+		for known := len(line); known > end; known-- {
+			if a.known[line[idx:known]] {
+				end = known
+				break
+			}
+		}
+		for end < len(line) && !strings.ContainsRune(" \t\"':", rune(line[end])) {
+			end++
+		}
+		builder.WriteString(line[:idx])
+		builder.WriteString(a.Path(line[idx:end]))
+		line = line[end:]
+	}
+	builder.WriteString(line)
+	return builder.String()
+}
@@ -0,0 +1,186 @@
+package report
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/volume"
+)
+
+// privateNames are the names in bundleFixture that an anonymized bundle must not reveal.
+var privateNames = []string{
+	"alice", "acme-secret", "payroll", "salaries.xlsx", "notes.md",
+	"private-drafts", "quarterly", "tax-returns", "exports", "tree.txt", ".confidential",
+	"Docs", "plan.txt",
+}
+
+// bundleFixture returns a result, configuration and log tail full of private names.
+func bundleFixture() (*scanner.ScanResult, *config.Config, []string) {
+	root := &scanner.TreeNode{Name: "acme-secret", Path: "/home/alice/acme-secret", IsDir: true}
+	dir := &scanner.TreeNode{Name: "payroll", Path: "/home/alice/acme-secret/payroll", IsDir: true, Parent: root}
+	file := &scanner.TreeNode{Name: "salaries.xlsx", Path: "/home/alice/acme-secret/payroll/salaries.xlsx", Parent: dir}
+	notes := &scanner.TreeNode{Name: "notes.md", Path: "/home/alice/acme-secret/notes.md", Parent: root}
+	docs := &scanner.TreeNode{Name: "My Docs", Path: "/home/alice/acme-secret/My Docs", IsDir: true, Parent: root}
+	plan := &scanner.TreeNode{Name: "secret plan.txt", Path: "/home/alice/acme-secret/My Docs/secret plan.txt", Parent: docs}
+	dir.Children = []*scanner.TreeNode{file}
+	docs.Children = []*scanner.TreeNode{plan}
+	root.Children = []*scanner.TreeNode{docs, dir, notes}
+
+	result := &scanner.ScanResult{
+		Root:      root,
+		RootPath:  root.Path,
+		NodeCount: 6,
+		TreeText:  "acme-secret/\n├── My Docs/\n│   └── secret plan.txt\n├── payroll/\n│   └── salaries.xlsx\n└── notes.md\n",
+		Error:     errors.New(`failed to read "/home/alice/acme-secret/payroll": permission denied`),
+		Skipped:   scanner.SkipStats{scanner.HiddenRule: 3, scanner.ExcludePatternRule: 2},
+		Errors: []scanner.ScanError{
+			{Path: dir.Path, Op: scanner.ScanOpRead, Err: &fs.PathError{Op: "open", Path: dir.Path, Err: fs.ErrPermission}},
+			{Path: plan.Path, Op: scanner.ScanOpStat, Err: &fs.PathError{Op: "lstat", Path: plan.Path, Err: fs.ErrPermission}},
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ExcludePatterns = []string{"private-drafts/**", "*.quarterly"}
+	cfg.IncludePatterns = []string{"tax-returns/**"}
+	cfg.SkipPaths = append(cfg.SkipPaths, "payroll")
+	cfg.AlwaysShowNames = append(cfg.AlwaysShowNames, ".confidential")
+	cfg.ExportPaths = []string{"/home/alice/exports/tree.txt"}
+
+	logTail := []string{
+		"Scanning /home/alice/acme-secret",
+		`Warning: failed to read directory "/home/alice/acme-secret/payroll": permission denied`,
+		"Saved /home/alice/exports/tree.txt",
+		"open /home/alice/acme-secret/My Docs/secret plan.txt: permission denied",
+	}
+	return result, cfg, logTail
+}
+
+func TestBundleAnonymizedHidesNames(t *testing.T) {
+	result, cfg, logTail := bundleFixture()
+	text, err := NewBundle(result, cfg, logTail, true).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range privateNames {
+		if strings.Contains(text, name) {
+			t.Errorf("anonymized bundle contains %q", name)
+		}
+	}
+}
+
+func TestBundleAnonymizedWithoutResult(t *testing.T) {
+	_, cfg, logTail := bundleFixture()
+	text, err := NewBundle(nil, cfg, logTail[2:3], true).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range privateNames {
+		if strings.Contains(text, name) {
+			t.Errorf("anonymized bundle without a result contains %q", name)
+		}
+	}
+}
+
+func TestBundleAnonymizedKeepsOriginals(t *testing.T) {
+	result, cfg, logTail := bundleFixture()
+	before := cfg.Clone()
+	NewBundle(result, cfg, logTail, true)
+	if cfg.ExportPaths[0] != before.ExportPaths[0] || cfg.ExcludePatterns[0] != before.ExcludePatterns[0] {
+		t.Error("anonymizing changed the application's configuration")
+	}
+	if result.Root.Children[1].Name != "payroll" || logTail[0] != "Scanning /home/alice/acme-secret" {
+		t.Error("anonymizing changed the result or log")
+	}
+}
+
+func TestBundlePlainKeepsNames(t *testing.T) {
+	result, cfg, logTail := bundleFixture()
+	text, err := NewBundle(result, cfg, logTail, false).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"acme-secret", "salaries.xlsx", "private-drafts/**", "/home/alice/exports/tree.txt"} {
+		if !strings.Contains(text, name) {
+			t.Errorf("bundle lacks %q", name)
+		}
+	}
+}
+
+func TestBundleSkipsAndScanErrors(t *testing.T) {
+	result, cfg, logTail := bundleFixture()
+	for _, anonymize := range []bool{false, true} {
+		report := NewBundle(result, cfg, logTail, anonymize).Report
+		if !reflect.DeepEqual(report.Stats.Skipped, result.Skipped) {
+			t.Errorf("anonymize %v: skipped %v, want %v", anonymize, report.Stats.Skipped, result.Skipped)
+		}
+		if len(report.ScanErrors) != len(result.Errors) {
+			t.Fatalf("anonymize %v: %d scan errors, want %d", anonymize, len(report.ScanErrors), len(result.Errors))
+		}
+		for i, e := range result.Errors {
+			got := report.ScanErrors[i]
+			path, message := e.Path, e.Err.Error()
+			if anonymize {
+				anon := NewAnonymizer()
+				path = anon.Path(e.Path)
+				message = strings.Replace(message, e.Path, path, 1)
+			}
+			if got.Path != path || got.Op != e.Op || got.Error != message {
+				t.Errorf("anonymize %v: scan error %+v, want %q %s %q", anonymize, got, path, e.Op, message)
+			}
+		}
+	}
+}
+
+func TestBundleAnonymizedKeepsVolume(t *testing.T) {
+	root := t.TempDir()
+	if _, err := volume.Stat(root); err != nil {
+		t.Skipf("volume figures unavailable: %v", err)
+	}
+	result := &scanner.ScanResult{RootPath: root, Root: &scanner.TreeNode{Name: "root", Path: root, IsDir: true}}
+	report := NewBundle(result, nil, nil, true).Report
+	if report.RootPath == root {
+		t.Errorf("root %q was not anonymized", root)
+	}
+	if report.Stats.Volume == nil {
+		t.Error("anonymized report lost the volume figures of the root")
+	}
+}
+
+func TestAnonymizerLinesWithSpaces(t *testing.T) {
+	anon := NewAnonymizer()
+	path := "/root/My Docs/secret plan.txt"
+	anon.Known("/root/My Docs", path)
+	lines := []string{
+		"open " + path + ": permission denied",
+		`failed to read "/root/My Docs": permission denied`,
+		"Scanning /root/My Docs/other file.txt",
+	}
+	want := []string{
+		"open " + anon.Path(path) + ": permission denied",
+		`failed to read "` + anon.Path("/root/My Docs") + `": permission denied`,
+		"Scanning " + anon.Path("/root/My Docs/other") + " file.txt", // Unknown names end at the space
+	}
+	if got := anon.Lines(lines, "/root"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines = %q, want %q", got, want)
+	}
+}
+
+func TestAnonymizerIsConsistent(t *testing.T) {
+	anon := NewAnonymizer()
+	a := anon.Path("/home/alice/src/app")
+	b := anon.Path("/home/alice/docs")
+	if strings.Split(a, "/")[2] != strings.Split(b, "/")[2] {
+		t.Errorf("%q and %q hash alice differently", a, b)
+	}
+	if got := anon.Pattern("build/**"); got != anon.Component("build")+"/**" {
+		t.Errorf("Pattern(build/**) = %q", got)
+	}
+	if got := anon.Config(&config.Config{ExcludePatterns: []string{"src/*"}}).ExcludePatterns[0]; got != anon.Component("src")+"/*" {
+		t.Errorf("anonymized pattern %q does not match the anonymized tree", got)
+	}
+}
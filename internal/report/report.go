@@ -0,0 +1,67 @@
+package report
+
+import (
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/volume"
+)
+
+// EnvelopeVersion is bumped whenever the JSON layout of Envelope changes incompatibly.
+const EnvelopeVersion = 1
+
+// Stats summarizes a scan for reports.
+type Stats struct {
+	NodeCount   int                     `json:"node_count"`
+	Languages   []scanner.LanguageShare `json:"languages,omitempty"`    // Languages of the files, largest first
+	LanguagesBy string                  `json:"languages_by,omitempty"` // What languages are weighed by: lines, size or files
+	Skipped     scanner.SkipStats       `json:"skipped,omitempty"`      // Entries left out by each filter rule
+	Volume      *volume.Info            `json:"volume,omitempty"`       // File system holding the root, when it could be queried
+}
+
+// Envelope is the self-describing JSON report of a single scan: options, stats, errors and the rendered tree.
+type Envelope struct {
+	Version     int            `json:"version"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	RootPath    string         `json:"root_path"`
+	Options     *config.Config `json:"options,omitempty"`
+	Stats       Stats          `json:"stats"`
+	Errors      []string       `json:"errors,omitempty"`
+	ScanErrors  []TreeError    `json:"scan_errors,omitempty"` // Entries that could not be read; the scan carried on past them
+	Tree        string         `json:"tree"`
+
+	// Session state, written by NewSessionEnvelope; paths are relative to RootPath
+	Entries    []string `json:"entries,omitempty"`    // Every entry, directories ending in "/"
+	Exclusions []string `json:"exclusions,omitempty"` // Entries hidden from the view
+	Selection  []string `json:"selection,omitempty"`  // Entries selected in the view
+}
+
+// NewEnvelope builds a report envelope from a scan result and the options it was produced with.
+func NewEnvelope(result *scanner.ScanResult, cfg *config.Config) *Envelope {
+	env := &Envelope{
+		Version:     EnvelopeVersion,
+		GeneratedAt: time.Now(),
+		Options:     cfg,
+	}
+	if result == nil {
+		return env
+	}
+
+	env.RootPath = result.RootPath
+	env.Stats = Stats{NodeCount: result.NodeCount, Languages: result.Languages, Skipped: result.Skipped}
+	if len(result.Languages) > 0 {
+		env.Stats.LanguagesBy = result.LanguagesBy
+	}
+	if info, err := volume.Stat(result.RootPath); err == nil {
+		env.Stats.Volume = info
+	}
+	env.Tree = result.TreeText
+	if result.Error != nil {
+		env.Errors = append(env.Errors, result.Error.Error())
+	}
+	for _, e := range result.Errors {
+		env.ScanErrors = append(env.ScanErrors, TreeError{Path: e.Path, Op: e.Op, Error: e.Err.Error()})
+	}
+	return env
+}
@@ -0,0 +1,125 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// NewSessionEnvelope builds a report that can be reopened later: besides the rendered tree it
+// lists every entry of result, and the entries excluded from and selected in the view. Paths
+// are relative to the root with forward slashes.
+func NewSessionEnvelope(result *scanner.ScanResult, tree string, cfg *config.Config, exclusions, selection []string) *Envelope {
+	env := NewEnvelope(result, cfg)
+	env.Tree = tree
+	env.Exclusions = exclusions
+	env.Selection = selection
+	if result != nil && result.Root != nil {
+		env.Entries = Entries(result.Root)
+	}
+	return env
+}
+
+// Entries lists the paths below root relative to it, directories with a trailing slash.
+func Entries(root *scanner.TreeNode) []string {
+	var entries []string
+	var walk func(node *scanner.TreeNode)
+	walk = func(node *scanner.TreeNode) {
+		for _, child := range node.Children {
+			entries = append(entries, RelPath(root.Path, child))
+			walk(child)
+		}
+	}
+	walk(root)
+	return entries
+}
+
+// RelPath returns node's path relative to root with forward slashes, and a trailing slash for directories.
+func RelPath(root string, node *scanner.TreeNode) string {
+	rel, err := filepath.Rel(root, node.Path)
+	if err != nil {
+		rel = node.Path
+	}
+	rel = filepath.ToSlash(rel)
+	if node.IsDir {
+		rel += "/"
+	}
+	return rel
+}
+
+// ReadEnvelope decodes a report written by WriteEnvelope.
+func ReadEnvelope(r io.Reader) (*Envelope, error) {
+	var env Envelope
+	if err := json.NewDecoder(r).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	if env.Version > EnvelopeVersion {
+		return nil, fmt.Errorf("report version %d is newer than this application supports (%d)", env.Version, EnvelopeVersion)
+	}
+	return &env, nil
+}
+
+// WriteEnvelope encodes a report as indented JSON.
+func WriteEnvelope(w io.Writer, env *Envelope) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(env)
+}
+
+// Restore rebuilds the listed tree as a virtual result rooted at RootPath.
+func (e *Envelope) Restore() (*scanner.ScanResult, error) {
+	if len(e.Entries) == 0 {
+		return nil, fmt.Errorf("report has no entries to restore")
+	}
+
+	root := &scanner.TreeNode{Path: e.RootPath, Name: filepath.Base(e.RootPath), IsDir: true, IsVirtual: true}
+	nodes := map[string]*scanner.TreeNode{"": root}
+	count := 1
+	for _, entry := range e.Entries {
+		rel := strings.TrimSuffix(entry, "/")
+		if rel == "" || nodes[rel] != nil {
+			continue
+		}
+		dir, name := cutLast(rel)
+		parent := nodes[dir]
+		if parent == nil {
+			return nil, fmt.Errorf("report lists %q before its directory", entry)
+		}
+		node := &scanner.TreeNode{
+			Path:      filepath.Join(e.RootPath, filepath.FromSlash(rel)),
+			Name:      name,
+			IsDir:     strings.HasSuffix(entry, "/"),
+			IsVirtual: true,
+			Parent:    parent,
+		}
+		if !node.IsDir {
+			node.Kind = scanner.Classify(name, nil)
+		}
+		parent.Children = append(parent.Children, node)
+		nodes[rel] = node
+		count++
+	}
+
+	result := &scanner.ScanResult{
+		RootPath:  e.RootPath,
+		NodeCount: count,
+		Root:      root,
+		ScannedAt: e.GeneratedAt,
+	}
+	scanner.Tally(result)
+	return result, nil
+}
+
+// cutLast splits a slash-separated path into its directory ("" at the top) and base name.
+func cutLast(rel string) (string, string) {
+	i := strings.LastIndex(rel, "/")
+	if i < 0 {
+		return "", rel
+	}
+	return rel[:i], rel[i+1:]
+}
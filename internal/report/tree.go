@@ -0,0 +1,360 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// TreeFormat identifies tree documents among other JSON files.
+const TreeFormat = "file-tree-scanner/tree"
+
+// TreeVersion is bumped whenever the JSON layout of TreeDocument changes incompatibly.
+const TreeVersion = 1
+
+// TreeDocument is the structured JSON form of a scanned tree, for opening it on another machine.
+// Paths are not stored; each entry's path follows from RootPath and the names above it, and
+// parents are not stored either, as Result links each node to the one it is listed under.
+type TreeDocument struct {
+	Format    string     `json:"format"`
+	Version   int        `json:"version"`
+	RootPath  string     `json:"root_path"`
+	ScannedAt time.Time  `json:"scanned_at"`
+	HasSizes  bool       `json:"has_sizes,omitempty"`
+	HasTimes  bool       `json:"has_times,omitempty"`
+	HasModes  bool       `json:"has_modes,omitempty"`
+	HasLines  bool       `json:"has_lines,omitempty"`
+	HasTokens bool       `json:"has_tokens,omitempty"`
+	HasHashes bool       `json:"has_hashes,omitempty"`
+	HasXattrs bool       `json:"has_xattrs,omitempty"`
+	Root      *TreeEntry `json:"root"`
+
+	// How the scan went, so a reopened snapshot reads like the scan it was saved from
+	Options         *config.Config       `json:"options,omitempty"` // Options the tree was scanned with, when saved as a snapshot
+	Partial         bool                 `json:"partial,omitempty"`
+	Truncated       bool                 `json:"truncated,omitempty"`
+	TruncatedReason scanner.CancelReason `json:"truncated_reason,omitempty"`
+	TruncatedLimit  string               `json:"truncated_limit,omitempty"`
+	CountsPartial   bool                 `json:"counts_partial,omitempty"`
+	DuplicateDirMin int                  `json:"duplicate_dir_min,omitempty"`
+	Errors          []TreeError          `json:"errors,omitempty"`
+
+	// Languages of the files, largest first, weighed by LanguagesBy; recomputed when the tree is read
+	Languages   []scanner.LanguageShare `json:"languages,omitempty"`
+	LanguagesBy string                  `json:"languages_by,omitempty"`
+
+	ItemsOmitted int `json:"items_omitted,omitempty"` // Entries left out by LimitTree
+}
+
+// TreeError is a path the scan could not read.
+type TreeError struct {
+	Path  string `json:"path"`
+	Op    string `json:"op"` // scanner.ScanOpRead, ScanOpStat or ScanOpXattrs
+	Error string `json:"error"`
+}
+
+// TreeEntry is one node of a TreeDocument. Optional facts are omitted when unset.
+type TreeEntry struct {
+	Name           string       `json:"name"`
+	Dir            bool         `json:"dir,omitempty"`
+	Size           int64        `json:"size,omitempty"`
+	DiskSize       int64        `json:"disk_size,omitempty"`
+	SizeUnknown    bool         `json:"size_unknown,omitempty"`
+	ModTime        *time.Time   `json:"mod_time,omitempty"`
+	Mode           fs.FileMode  `json:"mode,omitempty"` // Go fs.FileMode bits, when HasModes
+	Symlink        bool         `json:"symlink,omitempty"`
+	LinkTarget     string       `json:"link_target,omitempty"`
+	LinkBroken     bool         `json:"link_broken,omitempty"`
+	Kind           string       `json:"kind,omitempty"` // scanner.Kind of a file; classified from the name when absent
+	Executable     bool         `json:"executable,omitempty"`
+	Xattrs         []string     `json:"xattrs,omitempty"` // Extended attributes or alternate data streams, when they were listed
+	Hash           string       `json:"hash,omitempty"`   // Hex SHA-256 of a file's content, when HasHashes
+	Lines          int          `json:"lines,omitempty"`  // Lines of a text file, when HasLines
+	Tokens         int          `json:"tokens,omitempty"` // Estimated tokens, summed for directories, when HasTokens
+	ExportIgnore   bool         `json:"export_ignore,omitempty"`
+	Unreadable     bool         `json:"unreadable,omitempty"`
+	Omitted        int          `json:"omitted,omitempty"`       // Children left out by the scan, as scanner.TreeNode.Omitted
+	LimitOmitted   int          `json:"limit_omitted,omitempty"` // Children left out by LimitTree, counted apart from Omitted
+	Truncated      bool         `json:"truncated,omitempty"`
+	NotRead        bool         `json:"not_read,omitempty"`
+	MountPoint     bool         `json:"mount_point,omitempty"`
+	TruncateReason string       `json:"truncate_reason,omitempty"` // Why a directory's contents are missing or incomplete
+	Children       []*TreeEntry `json:"children,omitempty"`
+}
+
+// NewTreeDocument builds the tree document of result.
+func NewTreeDocument(result *scanner.ScanResult) *TreeDocument {
+	doc := &TreeDocument{
+		Format:    TreeFormat,
+		Version:   TreeVersion,
+		RootPath:  result.RootPath,
+		ScannedAt: result.ScannedAt,
+		HasSizes:  result.HasSizes,
+		HasTimes:  result.HasTimes,
+		HasModes:  result.HasModes,
+		HasLines:  result.HasLines,
+		HasTokens: result.HasTokens,
+		HasHashes: result.HasHashes,
+		HasXattrs: result.HasXattrs,
+		Languages: result.Languages,
+
+		Partial:         result.Partial,
+		Truncated:       result.Truncated,
+		TruncatedReason: result.TruncatedReason,
+		TruncatedLimit:  result.TruncatedLimit,
+		CountsPartial:   result.CountsPartial,
+		DuplicateDirMin: result.DuplicateDirMin,
+	}
+	if len(result.Languages) > 0 {
+		doc.LanguagesBy = result.LanguagesBy
+	}
+	for _, scanErr := range result.Errors {
+		doc.Errors = append(doc.Errors, TreeError{Path: scanErr.Path, Op: scanErr.Op, Error: scanErr.Err.Error()})
+	}
+	if result.Root != nil {
+		doc.Root = newTreeEntry(result.Root)
+	}
+	return doc
+}
+
+// newTreeEntry converts node and its children.
+func newTreeEntry(node *scanner.TreeNode) *TreeEntry {
+	entry := &TreeEntry{
+		Name:           node.Name,
+		Dir:            node.IsDir,
+		Size:           node.Size,
+		DiskSize:       node.DiskSize,
+		SizeUnknown:    node.SizeUnknown,
+		Symlink:        node.IsSymlink,
+		LinkTarget:     node.LinkTarget,
+		LinkBroken:     node.LinkBroken,
+		Kind:           string(node.Kind),
+		Executable:     node.Executable,
+		Xattrs:         node.Xattrs,
+		Hash:           node.Hash,
+		ExportIgnore:   node.ExportIgnore,
+		Unreadable:     node.Unreadable,
+		Omitted:        node.Omitted,
+		Truncated:      node.Truncated,
+		NotRead:        node.NotRead,
+		MountPoint:     node.MountPoint,
+		TruncateReason: node.TruncateReason,
+		Mode:           node.Mode,
+		Lines:          node.Lines,
+		Tokens:         node.Tokens,
+	}
+	if !node.ModTime.IsZero() {
+		modTime := node.ModTime
+		entry.ModTime = &modTime
+	}
+	for _, child := range node.Children {
+		entry.Children = append(entry.Children, newTreeEntry(child))
+	}
+	return entry
+}
+
+// WriteTree encodes a tree document as indented JSON.
+func WriteTree(w io.Writer, doc *TreeDocument) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// LimitTree returns a copy of doc whose JSON encoding takes at most limit bytes, or doc itself
+// when it already fits. Entries are kept in tree order; each directory counts the entries left
+// out of it in LimitOmitted, apart from what the scan left out, and ItemsOmitted holds the total
+// including their contents. It returns nil when not even the root entry fits.
+func LimitTree(doc *TreeDocument, limit int64) *TreeDocument {
+	if encodedSize(doc) <= limit {
+		return doc
+	}
+
+	// Find the most entries that fit; the root is always kept
+	total := countEntries(doc.Root)
+	low, high := 1, total-1
+	var fitting *TreeDocument
+	for low <= high {
+		keep := (low + high) / 2
+		candidate := doc.firstEntries(keep)
+		if encodedSize(candidate) <= limit {
+			fitting = candidate
+			low = keep + 1
+		} else {
+			high = keep - 1
+		}
+	}
+	return fitting
+}
+
+// firstEntries returns a copy of the document keeping only its first keep entries in tree order.
+func (d *TreeDocument) firstEntries(keep int) *TreeDocument {
+	limited := *d
+	limited.ItemsOmitted = 0
+	var copyEntry func(entry *TreeEntry) *TreeEntry
+	copyEntry = func(entry *TreeEntry) *TreeEntry {
+		keep--
+		copied := *entry
+		copied.Children = nil
+		for _, child := range entry.Children {
+			if keep > 0 {
+				copied.Children = append(copied.Children, copyEntry(child))
+				continue
+			}
+			copied.LimitOmitted++
+			limited.ItemsOmitted += countEntries(child)
+		}
+		return &copied
+	}
+	limited.Root = copyEntry(d.Root)
+	return &limited
+}
+
+// countEntries returns the number of entries in the tree rooted at entry.
+func countEntries(entry *TreeEntry) int {
+	count := 1
+	for _, child := range entry.Children {
+		count += countEntries(child)
+	}
+	return count
+}
+
+// encodedSize returns the number of bytes WriteTree writes for doc.
+func encodedSize(doc *TreeDocument) int64 {
+	counter := renderer.NewCountingWriter(io.Discard)
+	WriteTree(counter, doc) // Counting cannot fail
+	return counter.Stats().Bytes
+}
+
+// ReadTree decodes a tree document written by WriteTree.
+func ReadTree(r io.Reader) (*TreeDocument, error) {
+	var doc TreeDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to read tree file: %w", err)
+	}
+	if doc.Format != TreeFormat {
+		return nil, fmt.Errorf("not a tree file (format %q, expected %q)", doc.Format, TreeFormat)
+	}
+	if doc.Version > TreeVersion {
+		return nil, fmt.Errorf("tree file version %d is newer than this application supports (%d)", doc.Version, TreeVersion)
+	}
+	if doc.Version < 1 {
+		return nil, fmt.Errorf("tree file has invalid version %d", doc.Version)
+	}
+	if doc.Root == nil {
+		return nil, fmt.Errorf("tree file has no root entry")
+	}
+	return &doc, nil
+}
+
+// Result rebuilds the tree as a virtual result: its paths need not exist on this machine.
+func (d *TreeDocument) Result() (*scanner.ScanResult, error) {
+	root, count, err := d.node(d.Root, d.RootPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := &scanner.ScanResult{
+		RootPath:  d.RootPath,
+		NodeCount: count,
+		Root:      root,
+		ScannedAt: d.ScannedAt,
+		HasSizes:  d.HasSizes,
+		HasTimes:  d.HasTimes,
+		HasModes:  d.HasModes,
+		HasLines:  d.HasLines,
+		HasTokens: d.HasTokens,
+		HasHashes: d.HasHashes,
+		HasXattrs: d.HasXattrs,
+		TotalSize: root.Size,
+
+		Partial:         d.Partial,
+		Truncated:       d.Truncated,
+		TruncatedReason: d.TruncatedReason,
+		TruncatedLimit:  d.TruncatedLimit,
+		CountsPartial:   d.CountsPartial,
+		DuplicateDirMin: d.DuplicateDirMin,
+	}
+	if d.Options != nil {
+		result.IncludePatterns = d.Options.IncludePatterns
+		result.ExcludePatterns = d.Options.ExcludePatterns
+	}
+	for _, treeErr := range d.Errors {
+		result.Errors = append(result.Errors, scanner.ScanError{Path: treeErr.Path, Op: treeErr.Op, Err: errors.New(treeErr.Error)})
+	}
+	if result.HasTokens {
+		result.TotalTokens = scanner.SumTokens(root)
+	}
+	if result.HasHashes {
+		result.Duplicates = scanner.FindDuplicates(root)
+	}
+	if result.DuplicateDirMin > 0 && !result.Partial {
+		result.DuplicateDirs = scanner.FindDuplicateDirs(root, result.DuplicateDirMin)
+	}
+	scanner.Tally(result)
+	scanner.NormalizeLinks(result.Root)
+	return result, nil
+}
+
+// node converts entry, found at path, and its children, returning the number of nodes built.
+func (d *TreeDocument) node(entry *TreeEntry, path string, parent *scanner.TreeNode) (*scanner.TreeNode, int, error) {
+	if parent != nil && (entry.Name == "" || entry.Name == "." || entry.Name == ".." || strings.ContainsAny(entry.Name, `/\`)) {
+		return nil, 0, fmt.Errorf("tree file has invalid entry name %q in %q", entry.Name, parent.Path)
+	}
+	node := &scanner.TreeNode{
+		Path:           path,
+		Name:           entry.Name,
+		IsDir:          entry.Dir,
+		IsVirtual:      true,
+		IsSymlink:      entry.Symlink,
+		LinkTarget:     entry.LinkTarget,
+		LinkBroken:     entry.LinkBroken,
+		Kind:           scanner.Kind(entry.Kind),
+		Executable:     entry.Executable,
+		Xattrs:         entry.Xattrs,
+		Hash:           entry.Hash,
+		ExportIgnore:   entry.ExportIgnore,
+		SizeUnknown:    entry.SizeUnknown,
+		Unreadable:     entry.Unreadable,
+		Omitted:        entry.Omitted + entry.LimitOmitted, // Either way not shown
+		Truncated:      entry.Truncated,
+		NotRead:        entry.NotRead,
+		MountPoint:     entry.MountPoint,
+		TruncateReason: entry.TruncateReason,
+		Mode:           entry.Mode,
+		Lines:          entry.Lines,
+		Tokens:         entry.Tokens,
+		Size:           entry.Size,
+		DiskSize:       entry.DiskSize,
+		Parent:         parent,
+	}
+	if entry.ModTime != nil {
+		node.ModTime = *entry.ModTime
+	}
+	if !node.IsDir && node.Kind == "" {
+		node.Kind = scanner.Classify(node.Name, nil)
+	}
+	count := 1
+	for _, child := range entry.Children {
+		if !entry.Dir {
+			return nil, 0, fmt.Errorf("tree file lists children under file %q", path)
+		}
+		if child == nil {
+			return nil, 0, fmt.Errorf("tree file has an empty entry in %q", path)
+		}
+		childNode, childCount, err := d.node(child, filepath.Join(path, child.Name), node)
+		if err != nil {
+			return nil, 0, err
+		}
+		node.Children = append(node.Children, childNode)
+		count += childCount
+	}
+	return node, count, nil
+}
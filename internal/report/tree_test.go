@@ -0,0 +1,277 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// scannedTree scans a new folder with every optional fact collected.
+func scannedTree(t *testing.T) *scanner.ScanResult {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "project")
+	files := map[string]string{
+		"src/main.go":        "package main\n\nfunc main() {}\n",
+		"src/copy/main.go":   "package main\n\nfunc main() {}\n",
+		"docs/guide.md":      "# Guide\n\nText.\n",
+		"docs/naïve café.md": "ünïcödé\n",
+		"run.sh":             "#!/bin/sh\necho hi\n",
+		"empty/.keep":        "",
+		"data.bin":           "\x00\x01\x02",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(root, "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.Symlink("src/main.go", filepath.Join(root, "link.go")) // Not supported everywhere
+
+	cfg := config.DefaultConfig()
+	cfg.ShowSize = true
+	cfg.CollectTimes = true
+	cfg.CollectMode = true
+	cfg.ComputeHashes = true
+	cfg.CountLines = true
+	cfg.EstimateTokens = true
+	cfg.DuplicateDirMinItems = 1
+	cfg.ExcludePatterns = []string{"*.tmp"}
+	result, err := scanner.NewFileTreeScanner(cfg).ScanDirectory(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// cutShortTree returns a result that stopped early, with the markers of every way a directory
+// can be left incomplete.
+func cutShortTree() *scanner.ScanResult {
+	root := &scanner.TreeNode{Name: "root", Path: "/srv/root", IsDir: true}
+	add := func(parent *scanner.TreeNode, node *scanner.TreeNode) *scanner.TreeNode {
+		node.Parent = parent
+		node.Path = parent.Path + "/" + node.Name
+		if !node.IsDir {
+			node.Kind = scanner.Classify(node.Name, nil)
+		}
+		parent.Children = append(parent.Children, node)
+		return node
+	}
+	add(root, &scanner.TreeNode{Name: "locked", IsDir: true, Unreadable: true, TruncateReason: scanner.TruncateUnreadable})
+	add(root, &scanner.TreeNode{Name: "deep", IsDir: true, Truncated: true, TruncateReason: scanner.TruncateHardDepth})
+	add(root, &scanner.TreeNode{Name: "mnt", IsDir: true, NotRead: true, MountPoint: true, TruncateReason: scanner.TruncateMount})
+	big := add(root, &scanner.TreeNode{Name: "big", IsDir: true, Omitted: 42, TruncateReason: scanner.TruncateEntries})
+	add(big, &scanner.TreeNode{Name: "first.txt", SizeUnknown: true})
+	add(root, &scanner.TreeNode{Name: "gone", IsSymlink: true, LinkTarget: "nowhere", LinkBroken: true})
+	add(root, &scanner.TreeNode{Name: "release.tar", ExportIgnore: true, Xattrs: []string{"user.origin"}})
+
+	result := &scanner.ScanResult{
+		RootPath:        root.Path,
+		Root:            root,
+		ScannedAt:       time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC),
+		HasXattrs:       true,
+		Partial:         true,
+		Truncated:       true,
+		TruncatedReason: scanner.ReasonTimeout,
+		TruncatedLimit:  "30s",
+		CountsPartial:   true,
+		Errors: []scanner.ScanError{
+			{Path: "/srv/root/locked", Op: scanner.ScanOpRead, Err: errors.New("permission denied")},
+		},
+	}
+	scanner.Tally(result)
+	return result
+}
+
+// exportTree writes the tree document of result.
+func exportTree(t *testing.T, result *scanner.ScanResult) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteTree(&buf, NewTreeDocument(result)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// importTree reads a tree document back into a result.
+func importTree(t *testing.T, data []byte) *scanner.ScanResult {
+	t.Helper()
+	doc, err := ReadTree(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := doc.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestTreeRoundTrip(t *testing.T) {
+	for name, result := range map[string]*scanner.ScanResult{
+		"scanned":   scannedTree(t),
+		"cut short": cutShortTree(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			exported := exportTree(t, result)
+			imported := importTree(t, exported)
+			if again := exportTree(t, imported); !bytes.Equal(again, exported) {
+				t.Errorf("export → import → export changed the file:\n%s\nwant:\n%s", again, exported)
+			}
+
+			// The reopened tree reads like the scanned one
+			opts := renderer.DefaultOptions()
+			opts.ShowSizes = true
+			opts.ShowTimes = true
+			opts.ShowModes = true
+			text := func(result *scanner.ScanResult) string {
+				return renderer.NewStandardTreeRenderer(opts).RenderTree(result.Root)
+			}
+			if got, want := text(imported), text(result); got != want {
+				t.Errorf("imported tree renders as:\n%s\nwant:\n%s", got, want)
+			}
+			if imported.NodeCount != countNodes(result.Root) || imported.DirCount != result.DirCount ||
+				imported.FileCount != result.FileCount || imported.TotalSize != result.TotalSize ||
+				imported.TotalLines != result.TotalLines || imported.TotalTokens != result.TotalTokens {
+				t.Errorf("imported statistics %d nodes, %d dirs, %d files, %d bytes, %d lines, %d tokens; want %d, %d, %d, %d, %d, %d",
+					imported.NodeCount, imported.DirCount, imported.FileCount, imported.TotalSize, imported.TotalLines, imported.TotalTokens,
+					countNodes(result.Root), result.DirCount, result.FileCount, result.TotalSize, result.TotalLines, result.TotalTokens)
+			}
+			if len(imported.Duplicates) != len(result.Duplicates) || len(imported.DuplicateDirs) != len(result.DuplicateDirs) {
+				t.Errorf("imported %d duplicate files and %d duplicate folders, want %d and %d",
+					len(imported.Duplicates), len(imported.DuplicateDirs), len(result.Duplicates), len(result.DuplicateDirs))
+			}
+		})
+	}
+}
+
+func TestTreeImportIsVirtual(t *testing.T) {
+	result := scannedTree(t)
+	imported := importTree(t, exportTree(t, result))
+	var walk func(node, want *scanner.TreeNode)
+	walk = func(node, want *scanner.TreeNode) {
+		if !node.IsVirtual {
+			t.Errorf("%s is not virtual", node.Path)
+		}
+		if node.Path != want.Path {
+			t.Errorf("path %q, want %q", node.Path, want.Path)
+		}
+		for i, child := range node.Children {
+			if child.Parent != node {
+				t.Errorf("%s is not linked to its parent", child.Path)
+			}
+			walk(child, want.Children[i])
+		}
+	}
+	walk(imported.Root, result.Root)
+}
+
+// countNodes returns the number of nodes in the tree rooted at node.
+func countNodes(node *scanner.TreeNode) int {
+	count := 1
+	for _, child := range node.Children {
+		count += countNodes(child)
+	}
+	return count
+}
+
+func TestReadTreeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string // Part of the error
+	}{
+		{"not JSON", "root/\n├── a\n", "failed to read tree file"},
+		{"truncated", `{"format": "file-tree-scanner/tree", "version": 1, "root": {"name": "r"`, "failed to read tree file"},
+		{"wrong type", `{"format": "file-tree-scanner/tree", "version": "1"}`, "failed to read tree file"},
+		{"other JSON", `{"name": "package.json"}`, "not a tree file"},
+		{"newer version", `{"format": "file-tree-scanner/tree", "version": 2, "root": {"name": "r", "dir": true}}`, "version 2 is newer"},
+		{"no version", `{"format": "file-tree-scanner/tree", "root": {"name": "r", "dir": true}}`, "invalid version 0"},
+		{"no root", `{"format": "file-tree-scanner/tree", "version": 1}`, "no root entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadTree(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTreeResultErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		root string
+		want string
+	}{
+		{"slash in a name", `{"name": "r", "dir": true, "children": [{"name": "a/b"}]}`, `invalid entry name "a/b"`},
+		{"parent name", `{"name": "r", "dir": true, "children": [{"name": ".."}]}`, `invalid entry name ".."`},
+		{"empty name", `{"name": "r", "dir": true, "children": [{"name": ""}]}`, `invalid entry name ""`},
+		{"children of a file", `{"name": "r", "dir": true, "children": [{"name": "f", "children": [{"name": "x"}]}]}`, "children under file"},
+		{"null entry", `{"name": "r", "dir": true, "children": [null]}`, "empty entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ReadTree(strings.NewReader(`{"format": "file-tree-scanner/tree", "version": 1, "root_path": "/r", "root": ` + tt.root + `}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := doc.Result(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitTree(t *testing.T) {
+	doc := NewTreeDocument(cutShortTree())
+	total := countEntries(doc.Root)
+	full := encodedSize(doc)
+	if LimitTree(doc, full) != doc {
+		t.Fatal("a document that fits was copied")
+	}
+	for limit := full - 1; limit > 0; limit -= 16 {
+		limited := LimitTree(doc, limit)
+		if limited == nil {
+			break // Not even the root fits from here on
+		}
+		if size := encodedSize(limited); size > limit {
+			t.Fatalf("limit %d: document takes %d bytes", limit, size)
+		}
+		if kept := countEntries(limited.Root); kept+limited.ItemsOmitted != total {
+			t.Errorf("limit %d: %d entries kept and %d omitted, want %d in all", limit, kept, limited.ItemsOmitted, total)
+		}
+		// Entries cut by the limit are counted apart from those the scan left out
+		var walk func(entry *TreeEntry)
+		walk = func(entry *TreeEntry) {
+			if entry.Name == "big" && entry.Omitted != 42 {
+				t.Errorf("limit %d: omitted of big became %d, want the scan's 42", limit, entry.Omitted)
+			}
+			for _, child := range entry.Children {
+				walk(child)
+			}
+		}
+		walk(limited.Root)
+		if limited.Root.LimitOmitted+len(limited.Root.Children) != len(doc.Root.Children) {
+			t.Errorf("limit %d: root keeps %d children and counts %d cut, want %d", limit, len(limited.Root.Children), limited.Root.LimitOmitted, len(doc.Root.Children))
+		}
+	}
+	if doc.Root.LimitOmitted != 0 || doc.ItemsOmitted != 0 {
+		t.Error("limiting changed the original document")
+	}
+}
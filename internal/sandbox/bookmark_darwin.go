@@ -0,0 +1,100 @@
+//go:build darwin && cgo
+
+package sandbox
+
+/*
+#cgo CFLAGS: -x objective-c -fno-objc-arc
+#cgo LDFLAGS: -framework Foundation
+#import <Foundation/Foundation.h>
+#include <stdlib.h>
+
+// createBookmark returns security-scoped bookmark data for the folder at path, to be freed with
+// free, or NULL with the failure in *message, also to be freed.
+static void *createBookmark(const char *path, int *length, char **message) {
+	@autoreleasepool {
+		NSURL *url = [NSURL fileURLWithPath:[NSString stringWithUTF8String:path] isDirectory:YES];
+		NSError *error = nil;
+		NSData *data = [url bookmarkDataWithOptions:NSURLBookmarkCreationWithSecurityScope
+			includingResourceValuesForKeys:nil relativeToURL:nil error:&error];
+		if (data == nil) {
+			*message = strdup([[error localizedDescription] UTF8String]);
+			return NULL;
+		}
+		*length = (int)[data length];
+		void *bytes = malloc([data length]);
+		memcpy(bytes, [data bytes], [data length]);
+		return bytes;
+	}
+}
+
+// startAccess resolves bookmark data and starts access to its folder, returning the retained URL
+// to pass to stopAccess, or NULL with the failure in *message, to be freed.
+static void *startAccess(const void *bytes, int length, int *stale, char **message) {
+	@autoreleasepool {
+		NSData *data = [NSData dataWithBytes:bytes length:length];
+		BOOL isStale = NO;
+		NSError *error = nil;
+		NSURL *url = [NSURL URLByResolvingBookmarkData:data
+			options:NSURLBookmarkResolutionWithSecurityScope
+			relativeToURL:nil bookmarkDataIsStale:&isStale error:&error];
+		if (url == nil) {
+			*message = strdup([[error localizedDescription] UTF8String]);
+			return NULL;
+		}
+		if (![url startAccessingSecurityScopedResource]) {
+			*message = strdup("access was refused");
+			return NULL;
+		}
+		*stale = isStale ? 1 : 0;
+		return [url retain];
+	}
+}
+
+// stopAccess ends access started by startAccess.
+static void stopAccess(void *handle) {
+	NSURL *url = (NSURL *)handle;
+	[url stopAccessingSecurityScopedResource];
+	[url release];
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+// createBookmark returns a security-scoped bookmark of the folder at path.
+func createBookmark(path string) ([]byte, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	var length C.int
+	var message *C.char
+	bytes := C.createBookmark(cpath, &length, &message)
+	if bytes == nil {
+		defer C.free(unsafe.Pointer(message))
+		return nil, errors.New(C.GoString(message))
+	}
+	defer C.free(bytes)
+	return C.GoBytes(bytes, length), nil
+}
+
+// startAccess starts access to the folder of a bookmark, reporting whether the bookmark is stale
+// and should be created again.
+func startAccess(data []byte) (release func(), stale bool, err error) {
+	if len(data) == 0 {
+		return nil, false, errors.New("empty bookmark")
+	}
+	bytes := C.CBytes(data)
+	defer C.free(bytes)
+	var cstale C.int
+	var message *C.char
+	handle := C.startAccess(bytes, C.int(len(data)), &cstale, &message)
+	if handle == nil {
+		defer C.free(unsafe.Pointer(message))
+		return nil, false, errors.New(C.GoString(message))
+	}
+	var once sync.Once
+	return func() { once.Do(func() { C.stopAccess(handle) }) }, cstale != 0, nil
+}
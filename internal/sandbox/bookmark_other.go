@@ -0,0 +1,13 @@
+//go:build !darwin || !cgo
+
+package sandbox
+
+// createBookmark is unsupported here.
+func createBookmark(string) ([]byte, error) {
+	return nil, ErrUnsupported
+}
+
+// startAccess is unsupported here.
+func startAccess([]byte) (release func(), stale bool, err error) {
+	return nil, false, ErrUnsupported
+}
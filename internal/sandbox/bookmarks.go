@@ -0,0 +1,151 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrUnsupported is returned for bookmarks on platforms without security-scoped bookmarks.
+var ErrUnsupported = errors.New("security-scoped bookmarks are not supported on this platform")
+
+// bookmarksFileName is the name of the bookmarks file in the configuration directory.
+const bookmarksFileName = "bookmarks.json"
+
+// bookmarksVersion is the version of the bookmarks file written.
+const bookmarksVersion = 1
+
+// bookmarksFile is the JSON layout of the bookmarks file.
+type bookmarksFile struct {
+	Version   int               `json:"version"`
+	Bookmarks map[string][]byte `json:"bookmarks"` // By absolute path
+}
+
+// Bookmarks is a file of security-scoped bookmarks, which let the macOS App Sandbox reopen
+// folders the user chose in an earlier launch, such as recent folders. Its methods are safe for
+// concurrent use.
+type Bookmarks struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewBookmarks returns the bookmarks kept in the file at path.
+func NewBookmarks(path string) *Bookmarks {
+	return &Bookmarks{path: path}
+}
+
+// DefaultBookmarks returns the per-user bookmarks. Inside the sandbox the configuration
+// directory is in the application's container.
+func DefaultBookmarks() (*Bookmarks, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return NewBookmarks(filepath.Join(dir, "file-tree-scanner", bookmarksFileName)), nil
+}
+
+// Remember bookmarks the folder at path, which the process must be able to read now, typically
+// as the user just chose it.
+func (b *Bookmarks) Remember(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	data, err := createBookmark(abs)
+	if err != nil {
+		return fmt.Errorf("failed to bookmark %s: %w", abs, err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f, err := b.read()
+	if err != nil {
+		return err
+	}
+	f.Bookmarks[abs] = data
+	return b.write(f)
+}
+
+// Access starts access to the folder at path through its bookmark, returning the function that
+// ends it. Without a bookmark it returns a no-op, as the folder may be readable anyway. Stale
+// bookmarks are renewed.
+func (b *Bookmarks) Access(path string) (release func(), err error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	b.mu.Lock()
+	f, err := b.read()
+	b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	data, ok := f.Bookmarks[abs]
+	if !ok {
+		return func() {}, nil
+	}
+	release, stale, err := startAccess(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the bookmark of %s: %w", abs, err)
+	}
+	if stale {
+		if err := b.Remember(abs); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// read loads the file, which holds no bookmarks until it is first written. Called with mu held.
+func (b *Bookmarks) read() (*bookmarksFile, error) {
+	f := &bookmarksFile{Version: bookmarksVersion, Bookmarks: make(map[string][]byte)}
+	data, err := os.ReadFile(b.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks from %s: %w", b.path, err)
+	}
+	if f.Version > bookmarksVersion {
+		return nil, fmt.Errorf("bookmarks in %s are from a newer version (%d)", b.path, f.Version)
+	}
+	if f.Bookmarks == nil {
+		f.Bookmarks = make(map[string][]byte)
+	}
+	return f, nil
+}
+
+// write saves f, replacing the file at once so a crash cannot leave it half written. Called with
+// mu held.
+func (b *Bookmarks) write(f *bookmarksFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bookmarks: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save bookmarks: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(b.path), ".bookmarks-*")
+	if err != nil {
+		return fmt.Errorf("failed to save bookmarks: %w", err)
+	}
+	defer os.Remove(temp.Name()) // Fails once renamed
+	if _, err := temp.Write(append(data, '\n')); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to save bookmarks: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to save bookmarks: %w", err)
+	}
+	if err := os.Rename(temp.Name(), b.path); err != nil {
+		return fmt.Errorf("failed to save bookmarks: %w", err)
+	}
+	return nil
+}
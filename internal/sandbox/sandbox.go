@@ -0,0 +1,59 @@
+// Package sandbox lets the application read folders from inside the sandboxes of packaged
+// builds: Flatpak, Snap and the macOS App Sandbox. There only folders the user chose, or granted
+// through a portal, can be read; any other folder reads as missing or empty.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+)
+
+// Kind is a sandbox the application can run in.
+type Kind string
+
+// Sandboxes that are detected.
+const (
+	None    Kind = ""
+	Flatpak Kind = "Flatpak"
+	Snap    Kind = "Snap"
+	MacOS   Kind = "macOS App Sandbox"
+)
+
+// Detect returns the sandbox the process runs in, from what its launcher sets up.
+func Detect() Kind {
+	switch {
+	case os.Getenv("FLATPAK_ID") != "" || exists("/.flatpak-info"):
+		return Flatpak
+	case os.Getenv("SNAP_NAME") != "" && os.Getenv("SNAP") != "":
+		return Snap
+	case runtime.GOOS == "darwin" && os.Getenv("APP_SANDBOX_CONTAINER_ID") != "":
+		return MacOS
+	}
+	return None
+}
+
+// exists reports whether there is a file at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// AccessHint tells how to let the application read a folder in sandbox k, or is "" outside one.
+func (k Kind) AccessHint() string {
+	if k == None {
+		return ""
+	}
+	return "This build runs in the " + string(k) + " sandbox, which can only read folders you choose: grant access via the folder picker."
+}
+
+// Explain adds the access hint to err when it may come from sandbox k withholding a folder,
+// which then reads as missing or forbidden. Other errors are returned as they are.
+func (k Kind) Explain(err error) error {
+	if k == None || err == nil || !(errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist)) {
+		return err
+	}
+	return fmt.Errorf("%w\n\n%s", err, k.AccessHint())
+}
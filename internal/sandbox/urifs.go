@@ -0,0 +1,198 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// URIFS returns the folder at root as an fs.FS read through Fyne's storage repositories, for
+// folders a picker hands out as URIs with no path the process can open. Storage reports neither
+// sizes nor times, so entries have none.
+func URIFS(root fyne.ListableURI) fs.FS {
+	return uriFS{root: root}
+}
+
+// ScanURI scans the folder at root through URIFS. The tree is virtual, as its paths, which start
+// with the folder's name, cannot be opened.
+func ScanURI(ctx context.Context, cfg *config.Config, root fyne.ListableURI) (*scanner.ScanResult, error) {
+	result, err := scanner.NewFileTreeScannerFS(cfg, URIFS(root), root.Name()).ScanDirectory(ctx, root.Name())
+	if result != nil && result.Root != nil {
+		markVirtual(result.Root)
+	}
+	return result, err
+}
+
+// markVirtual marks node and everything below it as not on disk.
+func markVirtual(node *scanner.TreeNode) {
+	node.IsVirtual = true
+	for _, child := range node.Children {
+		markVirtual(child)
+	}
+}
+
+// uriFS implements fs.FS, fs.StatFS and fs.ReadDirFS over storage.
+type uriFS struct {
+	root fyne.URI
+}
+
+// uri returns the URI of the entry named name, a slash-separated path below the root.
+func (f uriFS) uri(op, name string) (fyne.URI, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	u := f.root
+	if name == "." {
+		return u, nil
+	}
+	for _, part := range strings.Split(name, "/") {
+		child, err := storage.Child(u, part)
+		if err != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		u = child
+	}
+	return u, nil
+}
+
+// Open implements fs.FS.
+func (f uriFS) Open(name string) (fs.File, error) {
+	u, err := f.uri("open", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := stat(u, "open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &uriDir{fsys: f, name: name, info: info}, nil
+	}
+	reader, err := storage.Reader(u)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &uriFile{ReadCloser: reader, info: info}, nil
+}
+
+// Stat implements fs.StatFS.
+func (f uriFS) Stat(name string) (fs.FileInfo, error) {
+	u, err := f.uri("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return stat(u, "stat", name)
+}
+
+// ReadDir implements fs.ReadDirFS, listing the entries sorted by name.
+func (f uriFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	u, err := f.uri("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	children, err := storage.List(u)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, child := range children {
+		dir, err := storage.CanList(child)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: fmt.Errorf("%s: %w", child.Name(), err)}
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(uriInfo{name: child.Name(), dir: dir}))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// stat describes the entry at u, named name in the fs.FS, for op.
+func stat(u fyne.URI, op, name string) (fs.FileInfo, error) {
+	exists, err := storage.Exists(u)
+	if err == nil && !exists {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	dir, err := storage.CanList(u)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return uriInfo{name: u.Name(), dir: dir}, nil
+}
+
+// uriInfo describes an entry of a uriFS: only its name and whether it is a directory are known.
+type uriInfo struct {
+	name string
+	dir  bool
+}
+
+func (i uriInfo) Name() string       { return i.name }
+func (i uriInfo) Size() int64        { return 0 }
+func (i uriInfo) ModTime() time.Time { return time.Time{} }
+func (i uriInfo) IsDir() bool        { return i.dir }
+func (i uriInfo) Sys() any           { return nil }
+
+// Mode implements fs.FileInfo; permissions are unknown.
+func (i uriInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+// uriFile is an open file of a uriFS.
+type uriFile struct {
+	io.ReadCloser
+	info fs.FileInfo
+}
+
+func (f *uriFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+// uriDir is an open directory of a uriFS; its entries are listed on the first ReadDir.
+type uriDir struct {
+	fsys    uriFS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	listed  bool
+}
+
+func (d *uriDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *uriDir) Close() error               { return nil }
+
+// Read fails, as for any directory.
+func (d *uriDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *uriDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.listed = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
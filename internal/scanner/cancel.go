@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+)
+
+// CancelReason says why a scan stopped before covering the whole tree.
+type CancelReason string
+
+// Reasons a scan can stop early. Limits stop descending and keep the partial tree; the
+// others cancel the scan's context.
+const (
+	ReasonUser        CancelReason = "cancelled"    // The user pressed Cancel
+	ReasonTimeout     CancelReason = "timeout"      // The scan took longer than allowed
+	ReasonSuperseded  CancelReason = "superseded"   // A newer scan or import replaced this one
+	ReasonMemoryLimit CancelReason = "memory limit" // The heap grew past Config.MaxHeapBytes
+	ReasonNodeLimit   CancelReason = "node limit"   // The tree grew past a node limit
+)
+
+// Message returns a user-facing sentence for the reason, naming the limit when one is given.
+func (r CancelReason) Message(limit string) string {
+	var message string
+	switch r {
+	case ReasonUser:
+		return "Scan cancelled"
+	case ReasonSuperseded:
+		return "Scan replaced by a newer one"
+	case ReasonTimeout:
+		message = "Scan timed out"
+		if limit != "" {
+			message += " after " + limit
+		}
+		return message
+	case ReasonMemoryLimit:
+		message = "Scan stopped at the memory limit"
+	case ReasonNodeLimit:
+		message = "Scan stopped at the node limit"
+	default:
+		message = "Scan stopped early (" + string(r) + ")"
+	}
+	if limit != "" {
+		message += " of " + limit
+	}
+	return message
+}
+
+// StopError is the cause recorded when a scan's context is cancelled for a known reason.
+type StopError struct {
+	Reason CancelReason
+	Limit  string // The limit that was reached, formatted for display; "" if none applies
+}
+
+// Error implements error.
+func (e *StopError) Error() string {
+	return e.Reason.Message(e.Limit)
+}
+
+// Stop returns the cause to cancel a scan's context with, for context.WithCancelCause or
+// context.WithTimeoutCause.
+func Stop(reason CancelReason, limit string) error {
+	return &StopError{Reason: reason, Limit: limit}
+}
+
+// StopCause returns why ctx was cancelled, or nil if it was not. Contexts cancelled without a
+// StopError cause count as cancelled by the user, or timed out past a deadline.
+func StopCause(ctx context.Context) *StopError {
+	if ctx.Err() == nil {
+		return nil
+	}
+	var stop *StopError
+	if errors.As(context.Cause(ctx), &stop) {
+		return stop
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &StopError{Reason: ReasonTimeout}
+	}
+	return &StopError{Reason: ReasonUser}
+}
+
+// Reasons a directory's contents are missing or incomplete, for TreeNode.TruncateReason. Scans
+// stopped by a limit or cancelled inside a directory record the CancelReason instead.
+const (
+	TruncateDepth      = "depth limit"       // Past Config.MaxDepth
+	TruncateHardDepth  = "depth cap"         // Past Config.HardDepthLimit
+	TruncateEntries    = "entry limit"       // Cut to Config.MaxEntriesPerDir
+	TruncateMount      = "other file system" // Not read with Config.OneFileSystem
+	TruncateRepeat     = "already listed"    // Reached again through a link or bind mount
+	TruncateUnreadable = "unreadable"        // Listing failed; see ScanResult.Errors
+	TruncateTimeout    = "timed out"         // Listing took longer than Config.DirTimeout
+)
@@ -0,0 +1,196 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/testtree"
+)
+
+func TestCancelReasonMessage(t *testing.T) {
+	tests := []struct {
+		reason CancelReason
+		limit  string
+		want   string
+	}{
+		{ReasonUser, "", "Scan cancelled"},
+		{ReasonUser, "ignored", "Scan cancelled"},
+		{ReasonSuperseded, "", "Scan replaced by a newer one"},
+		{ReasonTimeout, "", "Scan timed out"},
+		{ReasonTimeout, "30s", "Scan timed out after 30s"},
+		{ReasonMemoryLimit, "", "Scan stopped at the memory limit"},
+		{ReasonMemoryLimit, "512 MiB", "Scan stopped at the memory limit of 512 MiB"},
+		{ReasonNodeLimit, "", "Scan stopped at the node limit"},
+		{ReasonNodeLimit, "1000 items", "Scan stopped at the node limit of 1000 items"},
+		{"disk quota", "", "Scan stopped early (disk quota)"},
+		{"disk quota", "1 GB", "Scan stopped early (disk quota) of 1 GB"},
+	}
+	for _, tt := range tests {
+		if got := tt.reason.Message(tt.limit); got != tt.want {
+			t.Errorf("%q with limit %q: got %q, want %q", tt.reason, tt.limit, got, tt.want)
+		}
+		if got := Stop(tt.reason, tt.limit).Error(); got != tt.want {
+			t.Errorf("Stop(%q, %q).Error() = %q, want %q", tt.reason, tt.limit, got, tt.want)
+		}
+	}
+
+	// Each reason explains itself differently
+	seen := make(map[string]CancelReason)
+	for _, reason := range []CancelReason{ReasonUser, ReasonTimeout, ReasonSuperseded, ReasonMemoryLimit, ReasonNodeLimit} {
+		message := reason.Message("")
+		if other, ok := seen[message]; ok {
+			t.Errorf("%q and %q share the message %q", reason, other, message)
+		}
+		seen[message] = reason
+	}
+}
+
+func TestStopCause(t *testing.T) {
+	expired := func(cause error) context.Context {
+		ctx, cancel := context.WithTimeoutCause(context.Background(), -time.Second, cause)
+		t.Cleanup(cancel)
+		return ctx
+	}
+	cancelled := func(cause error) context.Context {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(cause)
+		return ctx
+	}
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		reason CancelReason
+		limit  string
+	}{
+		{"cancelled by the user", cancelled(Stop(ReasonUser, "")), ReasonUser, ""},
+		{"superseded", cancelled(Stop(ReasonSuperseded, "")), ReasonSuperseded, ""},
+		{"wrapped cause", cancelled(fmt.Errorf("window closed: %w", Stop(ReasonSuperseded, ""))), ReasonSuperseded, ""},
+		{"cancelled without a cause", cancelled(nil), ReasonUser, ""},
+		{"cancelled with another cause", cancelled(errors.New("shutting down")), ReasonUser, ""},
+		{"time limit", expired(Stop(ReasonTimeout, "5s")), ReasonTimeout, "5s"},
+		{"deadline without a cause", expired(nil), ReasonTimeout, ""},
+	}
+	for _, tt := range tests {
+		stop := StopCause(tt.ctx)
+		if stop == nil {
+			t.Errorf("%s: no cause", tt.name)
+			continue
+		}
+		if stop.Reason != tt.reason || stop.Limit != tt.limit {
+			t.Errorf("%s: got %q with limit %q, want %q with %q", tt.name, stop.Reason, stop.Limit, tt.reason, tt.limit)
+		}
+	}
+	if stop := StopCause(context.Background()); stop != nil {
+		t.Errorf("a live context has the cause %v", stop)
+	}
+}
+
+func TestScanStopReasons(t *testing.T) {
+	fsys := testtree.Small().MapFS()
+	tests := []struct {
+		name      string
+		ctx       func() context.Context
+		maxNodes  int
+		reason    CancelReason
+		limit     string
+		cancelled bool
+	}{
+		{
+			name:     "node limit",
+			ctx:      context.Background,
+			maxNodes: 3,
+			reason:   ReasonNodeLimit, limit: "3 items",
+		},
+		{
+			name: "cancelled by the user",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				cancel(Stop(ReasonUser, ""))
+				return ctx
+			},
+			reason: ReasonUser, cancelled: true,
+		},
+		{
+			name: "superseded",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				cancel(Stop(ReasonSuperseded, ""))
+				return ctx
+			},
+			reason: ReasonSuperseded, cancelled: true,
+		},
+		{
+			name: "time limit",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithTimeoutCause(context.Background(), -time.Second, Stop(ReasonTimeout, "1m0s"))
+				t.Cleanup(cancel)
+				return ctx
+			},
+			reason: ReasonTimeout, limit: "1m0s", cancelled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(io.Discard)
+
+			cfg := fixtureConfig()
+			cfg.MaxNodes = tt.maxNodes
+			result, err := NewFileTreeScannerFS(cfg, fsys, fixtureRoot).ScanDirectory(tt.ctx(), fixtureRoot)
+			if tt.cancelled != (err != nil) {
+				t.Fatalf("error %v, want one: %v", err, tt.cancelled)
+			}
+			if result == nil {
+				t.Fatal("no result")
+			}
+			if !result.Truncated || result.TruncatedReason != tt.reason || result.TruncatedLimit != tt.limit {
+				t.Errorf("truncated %v with %q and limit %q, want %q with %q",
+					result.Truncated, result.TruncatedReason, result.TruncatedLimit, tt.reason, tt.limit)
+			}
+			if result.Partial != tt.cancelled {
+				t.Errorf("partial %v, want %v", result.Partial, tt.cancelled)
+			}
+			if !tt.cancelled {
+				// Limits log their message once, naming the limit
+				want := "Warning: " + tt.reason.Message(tt.limit)
+				if n := bytes.Count(logs.Bytes(), []byte(want+"\n")); n != 1 {
+					t.Errorf("%q logged %d times:\n%s", want, n, logs.String())
+				}
+			}
+		})
+	}
+}
+
+func TestNodeLimitCutsShort(t *testing.T) {
+	cfg := fixtureConfig()
+	cfg.MaxNodes = 3
+	result := scanFixture(t, cfg, testtree.Small().MapFS())
+
+	if got := result.DirCount + result.FileCount; got < 3 {
+		t.Errorf("%d entries kept, want at least the limit of 3", got)
+	}
+	omitted := 0
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		if node.Omitted > 0 {
+			omitted += node.Omitted
+			if node.TruncateReason != string(ReasonNodeLimit) {
+				t.Errorf("%s: cut short with reason %q, want %q", node.Path, node.TruncateReason, ReasonNodeLimit)
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(result.Root)
+	if omitted == 0 {
+		t.Error("no directory records the entries left out at the node limit")
+	}
+}
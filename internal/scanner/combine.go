@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Combine joins results of separately scanned folders into one result. Its root is a virtual
+// directory at the folders' common parent, with each folder below it named by its path relative
+// to that parent. A combined result contributes the results it was made from, and a later result
+// for the same folder replaces an earlier one, so combining again refreshes that folder.
+func Combine(results ...*ScanResult) *ScanResult {
+	var parts []*ScanResult
+	for _, result := range results {
+		if result == nil || result.Root == nil {
+			continue
+		}
+		if len(result.Roots) > 0 {
+			parts = appendRoots(parts, result.Roots...)
+		} else {
+			parts = appendRoots(parts, result)
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+
+	paths := make([]string, len(parts))
+	for i, part := range parts {
+		paths[i] = part.RootPath
+	}
+	common := commonParent(paths)
+
+	root := &TreeNode{Path: common, Name: filepath.Base(common), IsDir: true, IsVirtual: true}
+	combined := &ScanResult{
+		RootPath: common,
+		Root:     root,
+		Roots:    parts,
+		Skipped:  make(SkipStats),
+		HasSizes: true,
+		HasTimes: true,
+		HasModes: true,
+	}
+	combined.NodeCount = 1
+	for _, part := range parts {
+		// The part's own tree is shared; only its root is copied to hang it below the combined root
+		top := *part.Root
+		top.Name = part.RootPath
+		if within(common, part.RootPath) {
+			top.Name, _ = filepath.Rel(common, part.RootPath)
+		}
+		top.Parent = root
+		root.Children = append(root.Children, &top)
+		root.Size += top.Size
+		root.DiskSize += top.DiskSize
+		root.Tokens += top.Tokens
+		root.SizeUnknown = root.SizeUnknown || top.SizeUnknown
+
+		combined.NodeCount += part.NodeCount
+		combined.HasSizes = combined.HasSizes && part.HasSizes
+		combined.HasTimes = combined.HasTimes && part.HasTimes
+		combined.HasModes = combined.HasModes && part.HasModes
+		combined.HasHashes = combined.HasHashes || part.HasHashes
+		combined.HasXattrs = combined.HasXattrs || part.HasXattrs
+		combined.HasLines = combined.HasLines || part.HasLines
+		combined.HasTokens = combined.HasTokens || part.HasTokens
+		combined.DuplicateDirMin = max(combined.DuplicateDirMin, part.DuplicateDirMin)
+		combined.TotalSize += part.TotalSize
+		combined.TotalTokens += part.TotalTokens
+		for rule, count := range part.Skipped {
+			combined.Skipped[rule] += count
+		}
+		if part.Latest != nil && (combined.Latest == nil || part.Latest.ModTime.After(combined.Latest.ModTime)) {
+			combined.Latest = part.Latest
+		}
+		if part.Truncated && !combined.Truncated {
+			combined.Truncated = true
+			combined.TruncatedReason, combined.TruncatedLimit = part.TruncatedReason, part.TruncatedLimit
+		}
+		combined.CountsPartial = combined.CountsPartial || part.CountsPartial
+		combined.Background = combined.Background || part.Background
+		combined.BackgroundKept = combined.BackgroundKept || part.BackgroundKept
+		combined.Partial = combined.Partial || part.Partial
+		combined.Errors = append(combined.Errors, part.Errors...)
+		combined.TruncatedDirs = append(combined.TruncatedDirs, part.TruncatedDirs...)
+
+		// The most recent scan's patterns are the ones a refresh would use
+		combined.IncludePatterns, combined.ExcludePatterns = part.IncludePatterns, part.ExcludePatterns
+		combined.LanguageTable = part.LanguageTable
+	}
+	Tally(combined)
+	if combined.HasHashes {
+		// Duplicates across the folders count too
+		combined.Duplicates = FindDuplicates(root)
+	}
+	if combined.DuplicateDirMin > 0 && !combined.Partial {
+		combined.DuplicateDirs = FindDuplicateDirs(root, combined.DuplicateDirMin)
+	}
+	return combined
+}
+
+// appendRoots appends results to parts; a result for a folder already in parts takes its place.
+func appendRoots(parts []*ScanResult, results ...*ScanResult) []*ScanResult {
+	for _, result := range results {
+		replaced := false
+		for i, part := range parts {
+			if filepath.Clean(part.RootPath) == filepath.Clean(result.RootPath) {
+				parts[i] = result
+				replaced = true
+			}
+		}
+		if !replaced {
+			parts = append(parts, result)
+		}
+	}
+	return parts
+}
+
+// commonParent returns the deepest directory strictly containing every path. Folders without
+// one, such as on different Windows volumes, fall back to the bare separator.
+func commonParent(paths []string) string {
+	dir := filepath.Dir(filepath.Clean(paths[0]))
+	for _, path := range paths {
+		for !within(dir, filepath.Clean(path)) {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return string(filepath.Separator)
+			}
+			dir = parent
+		}
+	}
+	return dir
+}
+
+// within reports whether path lies below dir.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
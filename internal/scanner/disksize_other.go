@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package scanner
+
+import "io/fs"
+
+// allocatedSize is not available on this platform; callers fall back to the apparent size.
+func allocatedSize(path string, info fs.FileInfo) (int64, bool) {
+	return 0, false
+}
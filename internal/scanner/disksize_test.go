@@ -0,0 +1,18 @@
+package scanner
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileSizesFallBackToApparent(t *testing.T) {
+	// A FileInfo without platform data, for a path the platform cannot query
+	info, err := fs.Stat(fstest.MapFS{"file": {Data: make([]byte, 1234)}}, "file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, diskSize := fileSizes("missing/file", info); size != 1234 || diskSize != 1234 {
+		t.Errorf("fileSizes = %d, %d; want the apparent size 1234 for both", size, diskSize)
+	}
+}
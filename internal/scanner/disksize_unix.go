@@ -0,0 +1,23 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.AllocatedSize)
+}
+
+// allocatedSize returns the bytes allocated on disk for a file, from st_blocks.
+func allocatedSize(path string, info fs.FileInfo) (int64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int64(stat.Blocks) * 512, true
+}
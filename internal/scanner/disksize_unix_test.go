@@ -0,0 +1,108 @@
+//go:build unix
+
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+// sparseSize is the apparent size of the sparse test file.
+const sparseSize = 8 << 20
+
+// writeSizeFixture creates a folder holding a sparse file of sparseSize bytes with nothing
+// allocated and a 64 KiB file written in full, skipping the test where the file system
+// allocates the sparse file anyway.
+func writeSizeFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	sparse, err := os.Create(filepath.Join(dir, "sparse.img"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sparse.Close()
+	if err := sparse.Truncate(sparseSize); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dense.bin"), make([]byte, 64<<10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := sparse.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); !ok || stat.Blocks*512 >= sparseSize {
+		t.Skip("the file system does not keep files sparse")
+	}
+	return dir
+}
+
+func TestAllocatedSizeOfSparseFiles(t *testing.T) {
+	dir := writeSizeFixture(t)
+	cfg := fixtureConfig()
+	cfg.ShowSize = true
+	result, err := NewFileTreeScanner(cfg).ScanDirectory(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sparse := findChild(t, result.Root, "sparse.img")
+	if sparse.Size != sparseSize || sparse.DiskSize >= sparse.Size {
+		t.Errorf("sparse file: size %d on disk %d, want %d with less on disk", sparse.Size, sparse.DiskSize, sparseSize)
+	}
+	dense := findChild(t, result.Root, "dense.bin")
+	if dense.Size != 64<<10 || dense.DiskSize < dense.Size {
+		t.Errorf("dense file: size %d on disk %d, want at least %d on disk", dense.Size, dense.DiskSize, 64<<10)
+	}
+	if result.Root.DiskSize != sparse.DiskSize+dense.DiskSize || result.Root.Size != sparse.Size+dense.Size {
+		t.Errorf("root sizes %d / %d are not the sums of its files'", result.Root.Size, result.Root.DiskSize)
+	}
+	if sparse.SizeFor(config.SizeAllocated) != sparse.DiskSize || sparse.SizeFor(config.SizeApparent) != sparse.Size {
+		t.Error("SizeFor does not pick the size of its basis")
+	}
+}
+
+func TestSortBySizeBasis(t *testing.T) {
+	dir := writeSizeFixture(t)
+	for _, tt := range []struct {
+		basis    string
+		showSize bool
+		first    string
+	}{
+		{config.SizeApparent, true, "sparse.img"},
+		{config.SizeAllocated, true, "dense.bin"},
+		{config.SizeApparent, false, "sparse.img"},
+		{config.SizeAllocated, false, "dense.bin"},
+	} {
+		cfg := fixtureConfig()
+		cfg.ShowSize = tt.showSize
+		cfg.SizeBasis = tt.basis
+		cfg.SortBy = config.SortBySize
+		cfg.SortDescending = true
+		result, err := NewFileTreeScanner(cfg).ScanDirectory(context.Background(), dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first := result.Root.Children[0].Name; first != tt.first {
+			t.Errorf("%s sizes (collected %v): %s sorted first, want %s", tt.basis, tt.showSize, first, tt.first)
+		}
+	}
+}
+
+// findChild returns the child of node named name, failing the test if there is none.
+func findChild(t *testing.T, node *TreeNode, name string) *TreeNode {
+	t.Helper()
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	t.Fatalf("%s has no child %s", node.Path, name)
+	return nil
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package scanner
+
+import (
+	"io/fs"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.AllocatedSize)
+}
+
+// invalidFileSize is the INVALID_FILE_SIZE sentinel returned by GetCompressedFileSizeW.
+const invalidFileSize = 0xFFFFFFFF
+
+var procGetCompressedFileSizeW = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetCompressedFileSizeW")
+
+// allocatedSize returns the bytes a file occupies on disk, accounting for NTFS compression and sparse files.
+func allocatedSize(path string, info fs.FileInfo) (int64, bool) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, false
+	}
+
+	var high uint32
+	low, _, callErr := procGetCompressedFileSizeW.Call(uintptr(unsafe.Pointer(name)), uintptr(unsafe.Pointer(&high)))
+	if uint32(low) == invalidFileSize && callErr != windows.ERROR_SUCCESS {
+		return 0, false
+	}
+	return int64(high)<<32 | int64(uint32(low)), true
+}
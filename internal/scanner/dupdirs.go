@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// DirGroup is a set of directories with identical contents.
+type DirGroup struct {
+	Dirs  []*TreeNode // In tree order
+	Items int         // Entries below each directory
+	Size  int64       // Apparent size of each directory, with HasSizes
+}
+
+// Waste returns the apparent size taken up by every copy in the group but one.
+func (g DirGroup) Waste() int64 {
+	return int64(len(g.Dirs)-1) * g.Size
+}
+
+// dirPrint is the structural fingerprint of a directory and the number of entries below it.
+type dirPrint struct {
+	hash  string
+	items int
+}
+
+// FindDuplicateDirs groups directories below root whose contents are copies of each other and
+// which hold at least minItems entries. A directory's fingerprint hashes the sorted names and
+// sizes of its entries, the content hashes of its files when they were computed, and the
+// fingerprints of its subdirectories, so its own name does not matter. Directories that were not
+// read in full never match. A group whose directories all lie in directories that are copies of
+// each other is left out, as the enclosing group covers it. Groups are ordered by the space they
+// waste, largest first. It only walks the tree, which must be complete; minItems below 1 is
+// treated as 1, since empty directories are all alike.
+func FindDuplicateDirs(root *TreeNode, minItems int) []DirGroup {
+	if root == nil {
+		return nil
+	}
+	minItems = max(minItems, 1)
+	prints := make(map[*TreeNode]dirPrint)
+	fingerprintDir(root, prints)
+
+	// Parents are tracked by the walk, as the roots combined into one tree keep their own
+	byHash := make(map[string][]*TreeNode)
+	parents := make(map[*TreeNode]*TreeNode)
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		for _, child := range node.Children {
+			if !child.IsDir {
+				continue
+			}
+			parents[child] = node
+			if fp, ok := prints[child]; ok && fp.items >= minItems {
+				byHash[fp.hash] = append(byHash[fp.hash], child)
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+
+	var groups []DirGroup
+	for _, dirs := range byHash {
+		if len(dirs) < 2 || withinCopies(dirs, parents, prints) {
+			continue
+		}
+		groups = append(groups, DirGroup{Dirs: dirs, Items: prints[dirs[0]].items, Size: dirs[0].Size})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if a, b := groups[i].Waste(), groups[j].Waste(); a != b {
+			return a > b
+		}
+		if groups[i].Items != groups[j].Items {
+			return groups[i].Items > groups[j].Items
+		}
+		return groups[i].Dirs[0].Path < groups[j].Dirs[0].Path
+	})
+	return groups
+}
+
+// fingerprintDir records the fingerprints of node and the directories below it in prints and
+// reports whether node has one; directories not read in full, and those containing them, have none.
+func fingerprintDir(node *TreeNode, prints map[*TreeNode]dirPrint) bool {
+	complete := !node.Unreadable && !node.Truncated && !node.NotRead && !node.SizeUnknown && node.Omitted == 0
+	entries := make([]string, 0, len(node.Children))
+	items := 0
+	for _, child := range node.Children {
+		items++
+		switch {
+		case child.Placeholder:
+			complete = false
+		case child.IsDir:
+			if !fingerprintDir(child, prints) {
+				complete = false
+				continue
+			}
+			entries = append(entries, fmt.Sprintf("d\x00%s\x00%s", child.Name, prints[child].hash))
+			items += prints[child].items
+		default:
+			entries = append(entries, fmt.Sprintf("f\x00%s\x00%d\x00%s\x00%t", child.Name, child.Size, child.Hash, child.IsSymlink))
+		}
+	}
+	if !complete {
+		return false
+	}
+
+	sort.Strings(entries)
+	hash := sha256.New()
+	for _, entry := range entries {
+		hash.Write([]byte(entry))
+		hash.Write([]byte{'\n'})
+	}
+	prints[node] = dirPrint{hash: hex.EncodeToString(hash.Sum(nil)), items: items}
+	return true
+}
+
+// withinCopies reports whether dirs all lie in distinct directories that are copies of each other.
+func withinCopies(dirs []*TreeNode, parents map[*TreeNode]*TreeNode, prints map[*TreeNode]dirPrint) bool {
+	seen := make(map[*TreeNode]bool)
+	var hash string
+	for _, dir := range dirs {
+		parent := parents[dir]
+		fp, ok := prints[parent]
+		if !ok || seen[parent] || (hash != "" && fp.hash != hash) {
+			return false
+		}
+		seen[parent] = true
+		hash = fp.hash
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/applog"
+)
+
+// Operations a ScanError can record.
+const (
+	ScanOpRead = "read" // Listing a directory
+	ScanOpStat = "stat" // Reading an entry's size, mode or time
+
+	ScanOpParse = "parse" // Reading the patterns of a .treeignore file
+
+	ScanOpXattrs = "list attributes of" // Listing extended attributes or alternate data streams
+)
+
+// ErrDirTimeout records a directory whose listing took longer than the configured DirTimeout,
+// as on a stalled network share. The directory is skipped and the scan carries on.
+var ErrDirTimeout = errors.New("listing timed out")
+
+// sampledWarnings is the number of warnings of each class a scan logs in full; the rest are
+// summarized once it ends, with every error kept in ScanResult.Errors.
+const sampledWarnings = 3
+
+// Classes of per-directory warnings.
+var (
+	cycleWarning   = applog.Class{Problem: "directory cycle", Items: "directories"}
+	depthWarning   = applog.Class{Problem: "depth limit reached", Items: "directories"}
+	entriesWarning = applog.Class{Problem: "entry limit reached", Items: "directories"}
+)
+
+// readWarning returns the class of a failure to list a directory, by its cause.
+func readWarning(err error) applog.Class {
+	problem := err.Error()
+	var pathErr *fs.PathError
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		problem = "permission denied"
+	case errors.Is(err, fs.ErrNotExist):
+		problem = "not found"
+	case errors.Is(err, ErrDirTimeout):
+		problem = "timed out"
+	case errors.As(err, &pathErr):
+		problem = pathErr.Err.Error()
+	}
+	return applog.Class{Problem: problem, Items: "directories"}
+}
+
+// ScanError records a path the scan could not read. The scan carries on past it.
+type ScanError struct {
+	Path string
+	Op   string // ScanOpRead, ScanOpStat, ScanOpParse or ScanOpXattrs
+	Err  error
+}
+
+// Error implements error.
+func (e ScanError) Error() string {
+	return fmt.Sprintf("failed to %s %q: %v", e.Op, e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e ScanError) Unwrap() error {
+	return e.Err
+}
+
+// UnreadableDirs returns the number of directories that could not be listed.
+func (r *ScanResult) UnreadableDirs() int {
+	count := 0
+	for _, e := range r.Errors {
+		if e.Op == ScanOpRead {
+			count++
+		}
+	}
+	return count
+}
+
+// fail records that node could not be read.
+func (st *scanState) fail(node *TreeNode, op string, err error) {
+	st.errors = append(st.errors, ScanError{Path: node.Path, Op: op, Err: err})
+	if op == ScanOpRead {
+		node.Unreadable = true
+	}
+}
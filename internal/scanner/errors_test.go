@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanSamplesWarnings(t *testing.T) {
+	// Eight directories over the entry limit, each worth a warning
+	fsys := fstest.MapFS{}
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 9; j++ {
+			fsys[fmt.Sprintf("data/d%d/f%d.txt", i, j)] = &fstest.MapFile{}
+		}
+	}
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(io.Discard) })
+
+	cfg := fixtureConfig()
+	cfg.MaxEntriesPerDir = 8
+	scanFixture(t, cfg, fsys)
+
+	var warnings, summaries []string
+	for _, line := range strings.Split(strings.TrimSpace(logged.String()), "\n") {
+		switch {
+		case strings.Contains(line, "limiting to first 8"):
+			warnings = append(warnings, line)
+		case strings.Contains(line, "entry limit reached"):
+			summaries = append(summaries, line)
+		}
+	}
+	if len(warnings) != sampledWarnings {
+		t.Errorf("logged %d warnings in full, want %d:\n%s", len(warnings), sampledWarnings, logged.String())
+	}
+	want := fmt.Sprintf("entry limit reached on 8 directories under %s (first %d logged above)", filepath.Join(fixtureRoot, "data"), sampledWarnings)
+	if len(summaries) != 1 || !strings.HasSuffix(summaries[0], want) {
+		t.Errorf("summaries %q, want one ending %q", summaries, want)
+	}
+}
@@ -0,0 +1,47 @@
+package scanner
+
+import (
+	"path/filepath"
+	"regexp"
+)
+
+// exportNamePattern matches the default names of files saved by the application,
+// e.g. file_tree_2024-05-01_12-30-00.txt.
+var exportNamePattern = regexp.MustCompile(`^file_tree_\d{4}-\d{2}-\d{2}_\d{2}-\d{2}-\d{2}\.[A-Za-z0-9]+$`)
+
+// exportRule skips files the application saved itself. Recorded paths match exactly;
+// the default export name only matches in a directory that holds a recorded export,
+// so unrelated files that happen to share the naming scheme are kept.
+type exportRule struct {
+	paths map[string]bool
+	dirs  map[string]bool
+}
+
+// newExportRule creates an exportRule for the recorded export paths.
+func newExportRule(paths []string) exportRule {
+	rule := exportRule{paths: make(map[string]bool), dirs: make(map[string]bool)}
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		rule.paths[path] = true
+		rule.dirs[filepath.Dir(path)] = true
+	}
+	return rule
+}
+
+// Name implements FilterRule.
+func (exportRule) Name() string { return "own-export" }
+
+// Match implements FilterRule.
+func (r exportRule) Match(entry EntryInfo) (RuleMatch, bool) {
+	if entry.IsDir {
+		return RuleMatch{}, false
+	}
+	path := filepath.Clean(entry.Path)
+	if r.paths[path] {
+		return RuleMatch{Rule: r.Name(), Pattern: "saved by this app"}, true
+	}
+	if r.dirs[filepath.Dir(path)] && exportNamePattern.MatchString(entry.Name) {
+		return RuleMatch{Rule: r.Name(), Pattern: exportNamePattern.String()}, true
+	}
+	return RuleMatch{}, false
+}
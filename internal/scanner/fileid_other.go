@@ -0,0 +1,10 @@
+//go:build !unix
+
+package scanner
+
+import "io/fs"
+
+// identify is not available on this platform; directories are then never recognized as revisited.
+func identify(info fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package scanner
+
+import (
+	"io/fs"
+	"syscall"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.OneFileSystem)
+}
+
+// identify returns the device and inode of a file.
+func identify(info fs.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
@@ -0,0 +1,141 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileSystem is the file access a scan makes. Paths are the scanner's own node paths.
+type fileSystem interface {
+	Stat(path string) (fs.FileInfo, error)
+	Lstat(path string) (fs.FileInfo, error)
+	ReadDir(path string) ([]fs.DirEntry, error)
+	Open(path string) (fs.File, error)
+	Readlink(path string) (string, error)
+	EvalSymlinks(path string) (string, error)
+	Hidden(path string) bool // Hidden by a file attribute rather than its name
+	Xattrs(path string) ([]string, error)
+}
+
+// diskFileSystem reads the operating system's file system; each method is the os or filepath
+// function of the same name.
+type diskFileSystem struct{}
+
+func (diskFileSystem) Stat(path string) (fs.FileInfo, error)      { return os.Stat(path) }
+func (diskFileSystem) Lstat(path string) (fs.FileInfo, error)     { return os.Lstat(path) }
+func (diskFileSystem) ReadDir(path string) ([]fs.DirEntry, error) { return os.ReadDir(path) }
+func (diskFileSystem) Readlink(path string) (string, error)       { return os.Readlink(path) }
+func (diskFileSystem) EvalSymlinks(path string) (string, error)   { return filepath.EvalSymlinks(path) }
+func (diskFileSystem) Hidden(path string) bool                    { return hiddenAttribute(path) }
+func (diskFileSystem) Xattrs(path string) ([]string, error)       { return listXattrs(path) }
+
+// Open returns os.Open's file as an fs.File, keeping a failed open a nil interface.
+func (diskFileSystem) Open(path string) (fs.File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// readDir lists the directory at path, giving up after the configured DirTimeout with
+// ErrDirTimeout, or when ctx is done. A listing given up on still finishes in the background,
+// since a stalled read cannot be interrupted; its entries are dropped.
+func (s *FileTreeScanner) readDir(ctx context.Context, path string) ([]fs.DirEntry, error) {
+	limit := s.config.DirTimeout
+	if limit <= 0 {
+		return s.files.ReadDir(path)
+	}
+	type listing struct {
+		entries []fs.DirEntry
+		err     error
+	}
+	done := make(chan listing, 1)
+	go func() {
+		entries, err := s.files.ReadDir(path)
+		done <- listing{entries, err}
+	}()
+
+	timeout, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+	select {
+	case l := <-done:
+		return l.entries, l.err
+	case <-timeout.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &fs.PathError{Op: "readdir", Path: path, Err: fmt.Errorf("%w after %s", ErrDirTimeout, limit)}
+	}
+}
+
+// ioFileSystem reads an fs.FS whose root directory has the node path root. An fs.FS has no
+// symbolic links to resolve, and entries it lists as links stay leaves.
+type ioFileSystem struct {
+	fsys fs.FS
+	root string
+}
+
+// name returns the fs.FS name of path, failing for paths outside root.
+func (f ioFileSystem) name(op, path string) (string, error) {
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Stat implements fileSystem.
+func (f ioFileSystem) Stat(path string) (fs.FileInfo, error) {
+	name, err := f.name("stat", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.Stat(f.fsys, name)
+}
+
+// Lstat is Stat, as an fs.FS follows no links.
+func (f ioFileSystem) Lstat(path string) (fs.FileInfo, error) {
+	return f.Stat(path)
+}
+
+// ReadDir implements fileSystem.
+func (f ioFileSystem) ReadDir(path string) ([]fs.DirEntry, error) {
+	name, err := f.name("readdir", path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(f.fsys, name)
+}
+
+// Open implements fileSystem.
+func (f ioFileSystem) Open(path string) (fs.File, error) {
+	name, err := f.name("open", path)
+	if err != nil {
+		return nil, err
+	}
+	return f.fsys.Open(name)
+}
+
+// Readlink always fails; an fs.FS cannot read link targets.
+func (f ioFileSystem) Readlink(path string) (string, error) {
+	return "", &fs.PathError{Op: "readlink", Path: path, Err: fs.ErrInvalid}
+}
+
+// EvalSymlinks returns path itself once it exists.
+func (f ioFileSystem) EvalSymlinks(path string) (string, error) {
+	if _, err := f.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Hidden always reports false; an fs.FS has no file attributes.
+func (f ioFileSystem) Hidden(path string) bool { return false }
+
+// Xattrs always reports none; an fs.FS has no extended attributes.
+func (f ioFileSystem) Xattrs(path string) ([]string, error) { return nil, nil }
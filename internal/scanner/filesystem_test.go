@@ -0,0 +1,63 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDirTimeout(t *testing.T) {
+	for _, workers := range []int{1, 5} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			// Listing stalled blocks like a dead network share until the test ends
+			stalled := make(chan struct{})
+			defer close(stalled)
+			fsys := hookFS{
+				FS: fstest.MapFS{
+					"stalled/lost.txt": &fstest.MapFile{},
+					"ok/a/b.txt":       &fstest.MapFile{},
+					"ok/c.txt":         &fstest.MapFile{},
+					"top.txt":          &fstest.MapFile{},
+				},
+				hook: func(name string) {
+					if name == "stalled" {
+						<-stalled
+					}
+				},
+			}
+			cfg := fixtureConfig()
+			cfg.DirTimeout = 20 * time.Millisecond
+			cfg.ConcurrentOps = workers
+
+			started := time.Now()
+			result := scanFixture(t, cfg, fsys)
+			if elapsed := time.Since(started); elapsed > 5*time.Second {
+				t.Errorf("scan took %s waiting on the stalled directory", elapsed)
+			}
+
+			want := []string{"ok", "ok/a", "ok/a/b.txt", "ok/c.txt", "stalled", "top.txt"}
+			if got := scannedPaths(result); !reflect.DeepEqual(got, want) {
+				t.Errorf("scanned %q, want %q", got, want)
+			}
+			if len(result.Errors) != 1 {
+				t.Fatalf("errors %v, want the stalled directory alone", result.Errors)
+			}
+			scanErr := result.Errors[0]
+			if scanErr.Path != filepath.Join(fixtureRoot, "stalled") || scanErr.Op != ScanOpRead || !errors.Is(scanErr.Err, ErrDirTimeout) {
+				t.Errorf("error %+v, want %s timing out", scanErr, filepath.Join(fixtureRoot, "stalled"))
+			}
+			if !result.CountsPartial || result.Partial {
+				t.Errorf("counts partial %v and scan partial %v, want only the counts partial", result.CountsPartial, result.Partial)
+			}
+			for _, child := range result.Root.Children {
+				if child.Name == "stalled" && child.TruncateReason != TruncateTimeout {
+					t.Errorf("stalled directory marked %q, want %q", child.TruncateReason, TruncateTimeout)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// EntryInfo describes a directory entry considered for inclusion in the tree.
+type EntryInfo struct {
+	Path  string
+	Name  string
+	IsDir bool
+}
+
+// RuleMatch describes why a rule excluded an entry.
+type RuleMatch struct {
+	Rule    string // Name of the rule that matched
+	Pattern string // Pattern or condition that matched
+	Source  string // "file:line" the pattern came from; empty for built-in rules
+}
+
+// String formats the match like `git check-ignore -v`.
+func (m RuleMatch) String() string {
+	source := m.Source
+	if source == "" {
+		source = "<" + m.Rule + ">"
+	}
+	return source + ":" + m.Pattern
+}
+
+// FilterRule is one named stage of the scanner's exclusion pipeline.
+type FilterRule interface {
+	Name() string
+	Match(entry EntryInfo) (RuleMatch, bool)
+}
+
+// filterPipeline evaluates rules in order; the first matching rule excludes the entry.
+type filterPipeline []FilterRule
+
+// excluded returns the first rule matching entry.
+func (p filterPipeline) excluded(entry EntryInfo) (RuleMatch, bool) {
+	for _, rule := range p {
+		if match, ok := rule.Match(entry); ok {
+			return match, true
+		}
+	}
+	return RuleMatch{}, false
+}
+
+// Filters returns the exclusion rules for scanning root with the scanner's configuration, in precedence order.
+func (s *FileTreeScanner) Filters(root string) []FilterRule {
+	var rules filterPipeline
+	if len(s.config.SkipPaths) > 0 {
+		rules = append(rules, newSystemPathRule(s.config.SkipPaths))
+	}
+	if !s.config.ShowHidden {
+		rules = append(rules, hiddenRule{files: s.files, always: s.config.AlwaysShowNames})
+	}
+	if s.config.RespectGitignore {
+		rules = append(rules, newGitignoreRule(s.files))
+	}
+	if rule := newExcludePatternRule(root, s.config.ExcludePatterns); rule != nil {
+		rules = append(rules, rule)
+	}
+	if s.config.TreeIgnore {
+		rules = append(rules, newTreeIgnoreRule(s.files, root))
+	}
+	if !s.config.ShowExports && len(s.config.ExportPaths) > 0 {
+		rules = append(rules, newExportRule(s.config.ExportPaths))
+	}
+	return rules
+}
+
+// scanFilters returns the exclusion rules of a scan of root with ctx: Filters, without the hidden
+// rule under WithShowHidden, and with the names of WithExcludedNames last.
+func (s *FileTreeScanner) scanFilters(ctx context.Context, root string) []FilterRule {
+	filters := withoutHidden(ctx, s.Filters(root))
+	if rule := newExcludedNamesRule(ctx, root); rule != nil {
+		filters = append(filters, rule)
+	}
+	return filters
+}
+
+// SkipStats counts the entries excluded by each filter rule, keyed by rule name.
+type SkipStats map[string]int
+
+// filterEntries drops the entries of dir excluded by the pipeline, counting them in skipped.
+func (s *FileTreeScanner) filterEntries(pipeline filterPipeline, skipped SkipStats, dir string, entries []os.DirEntry) []os.DirEntry {
+	filtered := make([]os.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		info := EntryInfo{Path: filepath.Join(dir, entry.Name()), Name: entry.Name(), IsDir: entry.IsDir()}
+		if match, excluded := pipeline.excluded(info); excluded {
+			skipped[match.Rule]++
+			continue
+		}
+		if pipeline.shownAnyway(info) {
+			skipped[AlwaysShownRule]++
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// SystemPathRule is the filter rule name under which Config.SkipPaths exclusions are counted.
+const SystemPathRule = "system-path"
+
+// systemPathRule skips operating system paths that commonly cause permission problems. A path
+// matches when its trailing components equal those of a skip path, so a folder merely named
+// like a part of one, or containing one in its name, is kept. A skip path starting with a
+// separator or a drive such as "C:" is anchored: only the path from the volume root matches.
+type systemPathRule struct {
+	paths []skipPath
+	names []string // Skip paths as configured, for reporting
+}
+
+// skipPath is a parsed Config.SkipPaths entry.
+type skipPath struct {
+	components []string
+	anchored   bool   // Matched from the volume root rather than on trailing components
+	volume     string // Drive the anchored path is on, such as "C:"; "" for any volume
+}
+
+// newSystemPathRule returns a rule skipping paths, which may use either separator.
+func newSystemPathRule(paths []string) systemPathRule {
+	rule := systemPathRule{}
+	for _, path := range paths {
+		volume, rest := splitDrive(path)
+		skip := skipPath{
+			components: pathComponents(rest),
+			anchored:   volume != "" || strings.HasPrefix(rest, "/") || strings.HasPrefix(rest, `\`),
+			volume:     volume,
+		}
+		if len(skip.components) > 0 {
+			rule.paths = append(rule.paths, skip)
+			rule.names = append(rule.names, path)
+		}
+	}
+	return rule
+}
+
+// splitDrive splits a leading drive letter such as "C:" from path, on every system, since skip
+// paths name Windows locations wherever they are configured.
+func splitDrive(path string) (drive, rest string) {
+	if len(path) >= 2 && path[1] == ':' && ('a' <= path[0]|0x20 && path[0]|0x20 <= 'z') {
+		return path[:2], path[2:]
+	}
+	return "", path
+}
+
+// pathComponents splits path at both forward and back slashes, dropping empty components.
+func pathComponents(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' })
+}
+
+// Name implements FilterRule.
+func (systemPathRule) Name() string { return SystemPathRule }
+
+// Match implements FilterRule.
+func (r systemPathRule) Match(entry EntryInfo) (RuleMatch, bool) {
+	volume := filepath.VolumeName(entry.Path)
+	components := pathComponents(entry.Path[len(volume):])
+	for i, skip := range r.paths {
+		var match bool
+		if skip.anchored {
+			match = len(components) == len(skip.components) &&
+				(skip.volume == "" || strings.EqualFold(volume, skip.volume)) &&
+				endsWithComponents(components, skip.components)
+		} else {
+			match = endsWithComponents(components, skip.components)
+		}
+		if match {
+			return RuleMatch{Rule: r.Name(), Pattern: r.names[i]}, true
+		}
+	}
+	return RuleMatch{}, false
+}
+
+// endsWithComponents reports whether path ends with the components of suffix. Windows paths
+// compare case-insensitively.
+func endsWithComponents(path, suffix []string) bool {
+	if len(suffix) > len(path) {
+		return false
+	}
+	tail := path[len(path)-len(suffix):]
+	for i := range suffix {
+		if tail[i] != suffix[i] && !(runtime.GOOS == "windows" && strings.EqualFold(tail[i], suffix[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// HiddenRule is the filter rule name under which hidden entries are counted.
+const HiddenRule = "hidden"
+
+// AlwaysShownRule counts the hidden entries kept for matching Config.AlwaysShowNames, which
+// shows the hidden filter is otherwise active. Unlike other keys of SkipStats, these entries
+// are in the tree.
+const AlwaysShownRule = "always-shown"
+
+// hiddenRule skips dot-prefixed names, and on Windows entries with the hidden attribute, when hidden
+// files are not shown. Names matching always are kept; rules after it, such as exclude patterns,
+// can still leave them out.
+type hiddenRule struct {
+	files  fileSystem // Where the attribute is read
+	always []string   // Config.AlwaysShowNames
+}
+
+// Name implements FilterRule.
+func (hiddenRule) Name() string { return HiddenRule }
+
+// showHiddenKey is the context key for WithShowHidden.
+type showHiddenKey struct{}
+
+// WithShowHidden returns a context whose scans include hidden entries regardless of Config.ShowHidden.
+func WithShowHidden(ctx context.Context) context.Context {
+	return context.WithValue(ctx, showHiddenKey{}, true)
+}
+
+// withoutHidden drops the hidden rule from rules when ctx overrides Config.ShowHidden.
+func withoutHidden(ctx context.Context, rules []FilterRule) []FilterRule {
+	if show, _ := ctx.Value(showHiddenKey{}).(bool); !show {
+		return rules
+	}
+	kept := rules[:0]
+	for _, rule := range rules {
+		if rule.Name() != HiddenRule {
+			kept = append(kept, rule)
+		}
+	}
+	return kept
+}
+
+// Match implements FilterRule.
+func (r hiddenRule) Match(entry EntryInfo) (RuleMatch, bool) {
+	match, hidden := r.hidden(entry)
+	if !hidden || r.alwaysShown(entry.Name) {
+		return RuleMatch{}, false
+	}
+	return match, true
+}
+
+// hidden reports whether entry is hidden, and how.
+func (r hiddenRule) hidden(entry EntryInfo) (RuleMatch, bool) {
+	if strings.HasPrefix(entry.Name, ".") {
+		return RuleMatch{Rule: r.Name(), Pattern: ".*"}, true
+	}
+	// The attribute lookup costs a system call, so it only runs for names the prefix check lets through
+	if r.files.Hidden(entry.Path) {
+		return RuleMatch{Rule: r.Name(), Pattern: "FILE_ATTRIBUTE_HIDDEN"}, true
+	}
+	return RuleMatch{}, false
+}
+
+// alwaysShown reports whether name matches one of the names or globs always shown.
+func (r hiddenRule) alwaysShown(name string) bool {
+	for _, pattern := range r.always {
+		if matched, err := filepath.Match(pattern, name); pattern == name || (err == nil && matched) {
+			return true
+		}
+	}
+	return false
+}
+
+// shownAnyway reports whether entry, kept by the pipeline, is hidden and only kept for being
+// always shown.
+func (p filterPipeline) shownAnyway(entry EntryInfo) bool {
+	for _, rule := range p {
+		if r, ok := rule.(hiddenRule); ok && r.alwaysShown(entry.Name) {
+			_, hidden := r.hidden(entry)
+			return hidden
+		}
+	}
+	return false
+}
+
+// Verdict is the outcome of a single rule for a path.
+type Verdict struct {
+	Rule     string
+	Excluded bool
+	Match    RuleMatch
+}
+
+// Explanation reports how the filter pipeline treats a path below a scan root.
+type Explanation struct {
+	Path        string
+	ExcludedBy  *RuleMatch // Deciding rule, nil if the path is included
+	ExcludedVia string     // Excluded ancestor directory, when the path itself is not matched
+	BeyondDepth bool       // Path lies deeper than MaxDepth allows
+	Verdicts    []Verdict  // Every rule evaluated against the path itself, in precedence order, pruning last
+}
+
+// Excluded reports whether the path would be missing from a scan of the root.
+func (e *Explanation) Excluded() bool {
+	return e.ExcludedBy != nil || e.BeyondDepth
+}
+
+// Explain evaluates the filter pipeline for path as if root were being scanned with ctx, which
+// carries the same overrides as the scan's, such as WithExcludedNames. Directories are read
+// below path when include patterns or empty-folder pruning decide whether it is kept.
+func (s *FileTreeScanner) Explain(ctx context.Context, root, path string) (*Explanation, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot relate %q to %q: %w", path, root, err)
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path %q is not inside the scan root %q", path, root)
+	}
+
+	pipeline := filterPipeline(s.scanFilters(ctx, root))
+	parts := strings.Split(rel, string(filepath.Separator))
+	explanation := &Explanation{Path: path}
+
+	// An excluded ancestor hides everything below it
+	current := root
+	for _, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		if match, ok := pipeline.excluded(EntryInfo{Path: current, Name: part, IsDir: true}); ok {
+			explanation.ExcludedBy = &match
+			explanation.ExcludedVia = current
+			break
+		}
+	}
+
+	isDir := false
+	if info, err := s.files.Lstat(path); err == nil {
+		isDir = info.IsDir()
+	}
+	entry := EntryInfo{Path: path, Name: parts[len(parts)-1], IsDir: isDir}
+	for _, rule := range pipeline {
+		match, ok := rule.Match(entry)
+		explanation.Verdicts = append(explanation.Verdicts, Verdict{Rule: rule.Name(), Excluded: ok, Match: match})
+		if ok && explanation.ExcludedBy == nil {
+			explanation.ExcludedBy = &match
+		}
+	}
+
+	// Entries at depth MaxDepth+1 are listed, but their own contents are not read
+	if s.config.MaxDepth >= 0 && len(parts) > s.config.MaxDepth+1 {
+		explanation.BeyondDepth = true
+	}
+
+	// What the scan prunes once the tree is read
+	include := newIncludeFilter(root, s.config.IncludePatterns)
+	if include != nil {
+		kept := include.includes(path)
+		if isDir {
+			kept = s.holdsKept(pipeline, include, path, len(parts))
+		}
+		explanation.pruned(IncludePatternRule, "matches no include pattern", kept)
+	}
+	if s.config.PruneEmptyDirs && isDir {
+		explanation.pruned(EmptyDirRule, "nothing left inside", s.holdsKept(pipeline, nil, path, len(parts)))
+	}
+	return explanation, nil
+}
+
+// pruned adds the verdict of rule, which prunes the path once the tree is read unless kept.
+func (e *Explanation) pruned(rule, reason string, kept bool) {
+	verdict := Verdict{Rule: rule, Excluded: !kept}
+	if !kept {
+		verdict.Match = RuleMatch{Rule: rule, Pattern: reason}
+		if e.ExcludedBy == nil {
+			e.ExcludedBy = &verdict.Match
+		}
+	}
+	e.Verdicts = append(e.Verdicts, verdict)
+}
+
+// holdsKept reports whether the directory at dir, at depth below the root, ends up in the tree
+// for holding a file that pipeline and include keep. Without include, a directory whose
+// contents are not read is kept, as PruneEmpty keeps it; with include it is pruned.
+func (s *FileTreeScanner) holdsKept(pipeline filterPipeline, include *includeFilter, dir string, depth int) bool {
+	unknown := include == nil
+	if (s.config.MaxDepth >= 0 && depth > s.config.MaxDepth) || (s.config.HardDepthLimit > 0 && depth > s.config.HardDepthLimit) {
+		return unknown
+	}
+	entries, err := s.files.ReadDir(dir)
+	if err != nil {
+		return unknown
+	}
+	for _, entry := range entries {
+		info := EntryInfo{Path: filepath.Join(dir, entry.Name()), Name: entry.Name(), IsDir: entry.IsDir()}
+		if _, excluded := pipeline.excluded(info); excluded {
+			continue
+		}
+		if info.IsDir {
+			if s.holdsKept(pipeline, include, info.Path, depth+1) {
+				return true
+			}
+		} else if include == nil || include.includes(info.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExplainingScanner is implemented by scanners that can explain their exclusion decisions.
+type ExplainingScanner interface {
+	Explain(ctx context.Context, root, path string) (*Explanation, error)
+}
@@ -0,0 +1,478 @@
+package scanner
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+// filterFixture is a tree in which several rules compete for the same entries.
+func filterFixture() fstest.MapFS {
+	file := &fstest.MapFile{Data: []byte("x"), Mode: 0o644}
+	return fstest.MapFS{
+		".hidden/file.txt":         file,
+		".github/workflows/ci.yml": file,
+		".treeignore":              {Data: []byte("# private\nsecret.txt\nbuild/\n"), Mode: 0o644},
+		"build/out.o":              file,
+		"docs/readme.md":           file,
+		"empty":                    {Mode: fs.ModeDir | 0o755},
+		"logs/app.log":             file,
+		"secret.txt":               file,
+		"src/gen/api.go":           file,
+		"src/main.go":              file,
+		"src/main_test.go":         file,
+		"sub/logs/keep.go":         file,
+		"sub/only.log":             file,
+	}
+}
+
+// explainFixture explains the fixture path rel, slash-separated, with cfg and ctx.
+func explainFixture(t *testing.T, ctx context.Context, cfg *config.Config, rel string) *Explanation {
+	t.Helper()
+	s := NewFileTreeScannerFS(cfg, filterFixture(), fixtureRoot)
+	explanation, err := s.Explain(ctx, fixtureRoot, filepath.Join(fixtureRoot, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return explanation
+}
+
+func TestExplainPrecedence(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+		ctx       func(ctx context.Context) context.Context
+		path      string
+		rule      string // Deciding rule, "" if included
+		pattern   string
+		via       string // Excluded ancestor, slash-separated
+	}{
+		{
+			name: "hidden before exclude patterns",
+			configure: func(cfg *config.Config) {
+				cfg.ShowHidden = false
+				cfg.ExcludePatterns = []string{".hidden/**"}
+			},
+			path: ".hidden/file.txt", rule: HiddenRule, pattern: ".*", via: ".hidden",
+		},
+		{
+			name: "always shown names still meet exclude patterns",
+			configure: func(cfg *config.Config) {
+				cfg.ShowHidden = false
+				cfg.ExcludePatterns = []string{"workflows/"}
+			},
+			path: ".github/workflows", rule: ExcludePatternRule, pattern: "workflows/",
+		},
+		{
+			name:      "always shown names pass the hidden rule",
+			configure: func(cfg *config.Config) { cfg.ShowHidden = false },
+			path:      ".github/workflows/ci.yml",
+		},
+		{
+			name:      "show hidden override",
+			configure: func(cfg *config.Config) { cfg.ShowHidden = false },
+			ctx:       WithShowHidden,
+			path:      ".hidden/file.txt",
+		},
+		{
+			name:      "exclude patterns before .treeignore",
+			configure: func(cfg *config.Config) { cfg.ExcludePatterns = []string{"secret.*"} },
+			path:      "secret.txt", rule: ExcludePatternRule, pattern: "secret.*",
+		},
+		{
+			name:      ".treeignore pattern",
+			configure: func(cfg *config.Config) {},
+			path:      "build/out.o", rule: TreeIgnoreRule, pattern: "build/", via: "build",
+		},
+		{
+			name:      ".treeignore hides itself",
+			configure: func(cfg *config.Config) {},
+			path:      TreeIgnoreFile, rule: TreeIgnoreRule, pattern: TreeIgnoreFile,
+		},
+		{
+			name:      "file matching no include pattern",
+			configure: func(cfg *config.Config) { cfg.IncludePatterns = []string{"*.go"} },
+			path:      "docs/readme.md", rule: IncludePatternRule,
+		},
+		{
+			name:      "folder holding no included file",
+			configure: func(cfg *config.Config) { cfg.IncludePatterns = []string{"*.go"} },
+			path:      "docs", rule: IncludePatternRule,
+		},
+		{
+			name:      "folder holding an included file",
+			configure: func(cfg *config.Config) { cfg.IncludePatterns = []string{"*.go"} },
+			path:      "src",
+		},
+		{
+			name: "exclude patterns before include patterns",
+			configure: func(cfg *config.Config) {
+				cfg.IncludePatterns = []string{"*.go"}
+				cfg.ExcludePatterns = []string{"*_test.go"}
+			},
+			path: "src/main_test.go", rule: ExcludePatternRule, pattern: "*_test.go",
+		},
+		{
+			name: "folder whose included files are all excluded",
+			configure: func(cfg *config.Config) {
+				cfg.IncludePatterns = []string{"*.go"}
+				cfg.ExcludePatterns = []string{"keep.go"}
+			},
+			path: "sub", rule: IncludePatternRule,
+		},
+		{
+			name:      "pre-scan exclusion at the top level",
+			configure: func(cfg *config.Config) {},
+			ctx:       func(ctx context.Context) context.Context { return WithExcludedNames(ctx, []string{"logs"}) },
+			path:      "logs/app.log", rule: "pre-scan", pattern: "logs", via: "logs",
+		},
+		{
+			name:      "pre-scan exclusion spares deeper names",
+			configure: func(cfg *config.Config) {},
+			ctx:       func(ctx context.Context) context.Context { return WithExcludedNames(ctx, []string{"logs"}) },
+			path:      "sub/logs/keep.go",
+		},
+		{
+			name:      "empty folder pruned",
+			configure: func(cfg *config.Config) { cfg.PruneEmptyDirs = true },
+			path:      "empty", rule: EmptyDirRule,
+		},
+		{
+			name: "folder left empty by filtering",
+			configure: func(cfg *config.Config) {
+				cfg.PruneEmptyDirs = true
+				cfg.ExcludePatterns = []string{"*.log", "logs/"}
+			},
+			path: "sub", rule: EmptyDirRule,
+		},
+		{
+			name: "empty folders kept without pruning",
+			path: "empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fixtureConfig()
+			if tt.configure != nil {
+				tt.configure(cfg)
+			}
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+			e := explainFixture(t, ctx, cfg, tt.path)
+
+			if tt.rule == "" {
+				if e.Excluded() {
+					t.Fatalf("excluded by %v, want included", e.ExcludedBy)
+				}
+				return
+			}
+			if e.ExcludedBy == nil {
+				t.Fatalf("included, want excluded by %s", tt.rule)
+			}
+			if e.ExcludedBy.Rule != tt.rule || (tt.pattern != "" && e.ExcludedBy.Pattern != tt.pattern) {
+				t.Errorf("excluded by %s (%q), want %s (%q)", e.ExcludedBy.Rule, e.ExcludedBy.Pattern, tt.rule, tt.pattern)
+			}
+			wantVia := ""
+			if tt.via != "" {
+				wantVia = filepath.Join(fixtureRoot, filepath.FromSlash(tt.via))
+			}
+			if e.ExcludedVia != wantVia {
+				t.Errorf("excluded via %q, want %q", e.ExcludedVia, wantVia)
+			}
+		})
+	}
+}
+
+func TestExplainTreeIgnoreSource(t *testing.T) {
+	e := explainFixture(t, context.Background(), fixtureConfig(), "secret.txt")
+	want := filepath.Join(fixtureRoot, TreeIgnoreFile) + ":2"
+	if e.ExcludedBy == nil || e.ExcludedBy.Source != want {
+		t.Fatalf("excluded by %+v, want the pattern from %s", e.ExcludedBy, want)
+	}
+}
+
+func TestExplainVerdictsInOrder(t *testing.T) {
+	cfg := fixtureConfig()
+	cfg.ShowHidden = false
+	cfg.ExcludePatterns = []string{"*.md"}
+	cfg.IncludePatterns = []string{"*.go"}
+	cfg.PruneEmptyDirs = true
+	cfg.SkipPaths = []string{`\Recovery`}
+	e := explainFixture(t, context.Background(), cfg, "docs")
+
+	var rules []string
+	for _, v := range e.Verdicts {
+		rules = append(rules, v.Rule)
+	}
+	want := []string{SystemPathRule, HiddenRule, ExcludePatternRule, TreeIgnoreRule, IncludePatternRule, EmptyDirRule}
+	if strings.Join(rules, " ") != strings.Join(want, " ") {
+		t.Errorf("verdicts %v, want %v", rules, want)
+	}
+}
+
+func TestExplainDepth(t *testing.T) {
+	cfg := fixtureConfig()
+	cfg.MaxDepth = 0
+	if e := explainFixture(t, context.Background(), cfg, "src/main.go"); !e.BeyondDepth || !e.Excluded() {
+		t.Error("an entry below an unread folder is not reported beyond the depth")
+	}
+	if e := explainFixture(t, context.Background(), cfg, "src"); e.Excluded() {
+		t.Error("an entry of the root is reported beyond depth 0")
+	}
+}
+
+// TestExplainAgreesWithScan checks every entry of the fixture: Explain must report it excluded
+// exactly when a scan with the same configuration and context leaves it out.
+func TestExplainAgreesWithScan(t *testing.T) {
+	configs := map[string]func(cfg *config.Config){
+		"defaults": func(cfg *config.Config) {},
+		"hidden off": func(cfg *config.Config) {
+			cfg.ShowHidden = false
+		},
+		"patterns": func(cfg *config.Config) {
+			cfg.ExcludePatterns = []string{"*_test.go", "logs/"}
+			cfg.IncludePatterns = []string{"*.go", "docs/**"}
+		},
+		"pruning": func(cfg *config.Config) {
+			cfg.ExcludePatterns = []string{"*.log"}
+			cfg.PruneEmptyDirs = true
+		},
+		"depth": func(cfg *config.Config) {
+			cfg.MaxDepth = 1
+			cfg.IncludePatterns = []string{"*.go"}
+		},
+	}
+	contexts := map[string]func(ctx context.Context) context.Context{
+		"plain":    func(ctx context.Context) context.Context { return ctx },
+		"pre-scan": func(ctx context.Context) context.Context { return WithExcludedNames(ctx, []string{"src", "sub"}) },
+		"show all": WithShowHidden,
+		"both ways": func(ctx context.Context) context.Context {
+			return WithShowHidden(WithExcludedNames(ctx, []string{"docs"}))
+		},
+	}
+
+	fixture := filterFixture()
+	var all []string
+	fs.WalkDir(fixture, ".", func(p string, d fs.DirEntry, err error) error {
+		if p != "." {
+			all = append(all, p)
+		}
+		return err
+	})
+
+	for configName, configure := range configs {
+		for ctxName, withCtx := range contexts {
+			t.Run(configName+"/"+ctxName, func(t *testing.T) {
+				cfg := fixtureConfig()
+				configure(cfg)
+				ctx := withCtx(context.Background())
+				s := NewFileTreeScannerFS(cfg, fixture, fixtureRoot)
+				result, err := s.ScanDirectory(ctx, fixtureRoot)
+				if err != nil {
+					t.Fatal(err)
+				}
+				scanned := make(map[string]bool)
+				for _, p := range scannedPaths(result) {
+					scanned[p] = true
+				}
+				for _, p := range all {
+					e, err := s.Explain(ctx, fixtureRoot, filepath.Join(fixtureRoot, filepath.FromSlash(p)))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if e.Excluded() == scanned[p] {
+						t.Errorf("%s: Explain says excluded %v (%v), scan has it %v", p, e.Excluded(), e.ExcludedBy, scanned[p])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSystemPathRule(t *testing.T) {
+	rule := newSystemPathRule([]string{`\Recovery`, `\Windows\System32\config`, "node_modules/.cache", `C:\Hiberfil`})
+	tests := []struct {
+		path    string
+		pattern string // Skip path that matches, "" if kept
+	}{
+		{"/Recovery", `\Recovery`},
+		{"/home/u/projects/Recovery", ""},
+		{"/home/u/Recovery/notes", ""},
+		{"/Windows/System32/config", `\Windows\System32\config`},
+		{"/backup/Windows/System32/config", ""},
+		{"/Windows/System32", ""},
+		{"/src/app/node_modules/.cache", "node_modules/.cache"},
+		{"/src/app/node_modules", ""},
+		{"/src/node_modules.cache", ""},
+		{"/Hiberfil", ""}, // Anchored to a drive this path is not on
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests, []struct {
+			path    string
+			pattern string
+		}{
+			{`C:\Recovery`, `\Recovery`},
+			{`D:\Recovery`, `\Recovery`},
+			{`D:\Users\u\Recovery`, ""},
+			{`\\server\share\Recovery`, `\Recovery`},
+			{`c:\hiberfil`, `C:\Hiberfil`},
+			{`D:\Hiberfil`, ""},
+		}...)
+	}
+	for _, tt := range tests {
+		path := filepath.FromSlash(tt.path)
+		match, skipped := rule.Match(EntryInfo{Path: path, Name: filepath.Base(path), IsDir: true})
+		if skipped != (tt.pattern != "") || match.Pattern != tt.pattern {
+			t.Errorf("%s: skipped %v by %q, want %q", path, skipped, match.Pattern, tt.pattern)
+		}
+	}
+}
+
+func TestDefaultSkipPathsKeepUserFolders(t *testing.T) {
+	root := filepath.FromSlash("/home/u/projects")
+	fsys := fstest.MapFS{
+		"Recovery/backup.tar":         {Data: []byte("x")},
+		"$Recycle.Bin/note.txt":       {Data: []byte("x")},
+		"System Volume Information/x": {Data: []byte("x")},
+	}
+	cfg := fixtureConfig()
+	cfg.SkipPaths = config.DefaultSkipPaths()
+	result, err := NewFileTreeScannerFS(cfg, fsys, root).ScanDirectory(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := result.Skipped[SystemPathRule]; n != 0 {
+		t.Errorf("%d folders below %s skipped as system paths", n, root)
+	}
+	if len(result.Root.Children) != 3 {
+		t.Errorf("%d of 3 folders kept", len(result.Root.Children))
+	}
+}
+
+func TestAlwaysShownNames(t *testing.T) {
+	file := &fstest.MapFile{Data: []byte("x"), Mode: 0o644}
+	fixture := fstest.MapFS{
+		".env":                     file,
+		".github/workflows/ci.yml": file,
+		".gitignore":               file,
+		"src/.gitkeep":             file,
+		"src/main.go":              file,
+	}
+	tests := []struct {
+		name     string
+		always   []string
+		excludes []string
+		want     []string
+		shown    int // Skipped[AlwaysShownRule]
+		excluded int // Skipped[ExcludePatternRule]
+	}{
+		{
+			name:   "names",
+			always: []string{".github"},
+			want:   []string{".github", ".github/workflows", ".github/workflows/ci.yml", "src", "src/main.go"},
+			shown:  1,
+		},
+		{
+			name:     "excludes win over names",
+			always:   []string{".github", ".gitignore"},
+			excludes: []string{".github"},
+			want:     []string{".gitignore", "src", "src/main.go"},
+			shown:    1,
+			excluded: 1,
+		},
+		{
+			name:   "globs keep matching dotfiles",
+			always: []string{".git*"},
+			want:   []string{".github", ".github/workflows", ".github/workflows/ci.yml", ".gitignore", "src", "src/.gitkeep", "src/main.go"},
+			shown:  3,
+		},
+		{
+			name:     "excludes win over globs",
+			always:   []string{".git*"},
+			excludes: []string{".gitkeep", ".github/"},
+			want:     []string{".gitignore", "src", "src/main.go"},
+			shown:    1,
+			excluded: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fixtureConfig()
+			cfg.ShowHidden = false
+			cfg.AlwaysShowNames = tt.always
+			cfg.ExcludePatterns = tt.excludes
+			result := scanFixture(t, cfg, fixture)
+			if got := scannedPaths(result); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("scanned %q, want %q", got, tt.want)
+			}
+			if got := result.Skipped[AlwaysShownRule]; got != tt.shown {
+				t.Errorf("%d entries counted as always shown, want %d", got, tt.shown)
+			}
+			if got := result.Skipped[ExcludePatternRule]; got != tt.excluded {
+				t.Errorf("%d entries excluded by patterns, want %d", got, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestRelSlash(t *testing.T) {
+	root := filepath.FromSlash("/work/tree")
+	tests := []struct {
+		path string
+		rel  string
+		ok   bool
+	}{
+		{"/work/tree/src/main.go", "src/main.go", true},
+		{"/work/tree/..cache", "..cache", true},
+		{"/work/tree/...", "...", true},
+		{"/work/tree/src/..old/a.go", "src/..old/a.go", true},
+		{"/work/tree", "", false},
+		{"/work", "", false},
+		{"/work/other/a.go", "", false},
+		{"/work/tree/../other", "", false},
+	}
+	for _, tt := range tests {
+		rel, ok := relSlash(root, filepath.FromSlash(tt.path))
+		if rel != tt.rel || ok != tt.ok {
+			t.Errorf("relSlash(%q) = %q, %v; want %q, %v", tt.path, rel, ok, tt.rel, tt.ok)
+		}
+	}
+}
+
+func TestPatternsMatchDotDotNames(t *testing.T) {
+	file := &fstest.MapFile{Data: []byte("x"), Mode: 0o644}
+	fixture := fstest.MapFS{
+		"..cache/blob":   file,
+		"...":            file,
+		"src/..old.go":   file,
+		"src/main.go":    file,
+		"src/..bak/a.go": file,
+	}
+	tests := []struct {
+		name               string
+		excludes, includes []string
+		want               []string
+	}{
+		{"excluded", []string{"..cache/", "src/..*"}, nil, []string{"...", "src", "src/main.go"}},
+		{"included", nil, []string{"..cache/**", "src/..bak/*.go"}, []string{"..cache", "..cache/blob", "src", "src/..bak", "src/..bak/a.go"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fixtureConfig()
+			cfg.ExcludePatterns = tt.excludes
+			cfg.IncludePatterns = tt.includes
+			if got := scannedPaths(scanFixture(t, cfg, fixture)); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("scanned %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package scanner
+
+import (
+	"bufio"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// gitAttributesFile is the per-directory attributes file git reads.
+const gitAttributesFile = ".gitattributes"
+
+// attrRule is a .gitattributes line that sets or unsets export-ignore.
+type attrRule struct {
+	pattern *regexp.Regexp
+	dirOnly bool // Pattern ended in a slash
+	ignore  bool // export-ignore is set rather than unset
+}
+
+// attrScope holds the rules of one .gitattributes file and the directory they apply to.
+type attrScope struct {
+	dir   string
+	rules []attrRule
+}
+
+// readGitAttributes parses the export-ignore rules of dir's .gitattributes file.
+func readGitAttributes(files fileSystem, dir string) attrScope {
+	scope := attrScope{dir: dir}
+	path := filepath.Join(dir, gitAttributesFile)
+	file, err := files.Open(path)
+	if err != nil {
+		log.Printf("Warning: failed to read %q: %v", path, err)
+		return scope
+	}
+	defer file.Close()
+
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		if rule, ok := parseAttrLine(lines.Text()); ok {
+			scope.rules = append(scope.rules, rule)
+		}
+	}
+	return scope
+}
+
+// parseAttrLine returns the export-ignore rule on a .gitattributes line, if any.
+// Negative patterns are not allowed by git and are skipped, as are macro definitions.
+// Like `git archive`, only the bare set form ignores; giving the attribute a value,
+// even "true", overrides earlier rules without ignoring.
+func parseAttrLine(line string) (attrRule, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "!") || strings.HasPrefix(fields[0], "[attr]") {
+		return attrRule{}, false
+	}
+
+	rule := attrRule{}
+	found := false
+	for _, attr := range fields[1:] {
+		switch attr {
+		case "export-ignore":
+			rule.ignore, found = true, true
+		case "-export-ignore", "!export-ignore":
+			rule.ignore, found = false, true
+		default:
+			if strings.HasPrefix(attr, "export-ignore=") {
+				rule.ignore, found = false, true
+			}
+		}
+	}
+	if !found {
+		return attrRule{}, false
+	}
+
+	pattern := fields[0]
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	rule.pattern = compileGitPattern(pattern)
+	return rule, rule.pattern != nil
+}
+
+// compileGitPattern translates a git wildmatch pattern into a regular expression over
+// slash-separated paths relative to the directory of the .gitattributes or .gitignore
+// file. Patterns without a slash match the base name at any depth; others are anchored.
+func compileGitPattern(pattern string) *regexp.Regexp {
+	re, err := regexp.Compile(gitPatternExpr(pattern))
+	if err != nil {
+		log.Printf("Warning: ignoring invalid git pattern %q: %v", pattern, err)
+		return nil
+	}
+	return re
+}
+
+// gitPatternExpr returns the regular expression source for a git wildmatch pattern.
+func gitPatternExpr(pattern string) string {
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("(^|/)")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "/**/"):
+			expr.WriteString("(/.*)?/") // Zero or more directories in between
+			i += 3
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**"):
+			expr.WriteString("/.*")
+			i += 2
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		case pattern[i] == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	return expr.String()
+}
+
+// exportIgnored reports whether path is marked export-ignore by the scopes in effect,
+// ordered from the root down. Deeper files and later lines take precedence.
+func exportIgnored(scopes []attrScope, path string, isDir bool) bool {
+	for i := len(scopes) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(scopes[i].dir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue // Not below the directory of this file
+		}
+		rel = filepath.ToSlash(rel)
+		rules := scopes[i].rules
+		for j := len(rules) - 1; j >= 0; j-- {
+			if rules[j].dirOnly && !isDir {
+				continue
+			}
+			if rules[j].pattern.MatchString(rel) {
+				return rules[j].ignore
+			}
+		}
+	}
+	return false
+}
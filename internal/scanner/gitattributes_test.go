@@ -0,0 +1,171 @@
+package scanner
+
+import (
+	"bufio"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// attrScopeOf parses the .gitattributes text of the fixture directory rel, slash-separated.
+func attrScopeOf(rel, text string) attrScope {
+	scope := attrScope{dir: filepath.Join(fixtureRoot, filepath.FromSlash(rel))}
+	lines := bufio.NewScanner(strings.NewReader(text))
+	for lines.Scan() {
+		if rule, ok := parseAttrLine(lines.Text()); ok {
+			scope.rules = append(scope.rules, rule)
+		}
+	}
+	return scope
+}
+
+// Cases follow git's t0003-attributes and t5000-tar-tree.
+func TestExportIgnored(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"base name at the root", "ignored export-ignore", "ignored", false, true},
+		{"base name at any depth", "ignored export-ignore", "a/b/ignored", false, true},
+		{"base name is whole", "ignored export-ignore", "ignored.txt", false, false},
+		{"base name matches directories", "ignored export-ignore", "a/ignored", true, true},
+		{"leading slash anchors", "/top export-ignore", "top", false, true},
+		{"leading slash only at the root", "/top export-ignore", "sub/top", false, false},
+		{"inner slash anchors", "docs/*.md export-ignore", "docs/a.md", false, true},
+		{"inner slash anchors below", "docs/*.md export-ignore", "sub/docs/a.md", false, false},
+		{"star stays within a component", "docs/*.md export-ignore", "docs/x/a.md", false, false},
+		{"leading double star", "**/gen export-ignore", "gen", true, true},
+		{"leading double star at depth", "**/gen export-ignore", "a/b/gen", true, true},
+		{"inner double star", "a/**/z export-ignore", "a/z", false, true},
+		{"inner double star spans levels", "a/**/z export-ignore", "a/b/c/z", false, true},
+		{"trailing double star matches inside", "vendor/** export-ignore", "vendor/lib/x.go", false, true},
+		{"trailing double star not the directory", "vendor/** export-ignore", "vendor", true, false},
+		{"trailing slash matches directories", "build/ export-ignore", "build", true, true},
+		{"trailing slash skips files", "build/ export-ignore", "build", false, false},
+		{"question mark", "?.c export-ignore", "a.c", false, true},
+		{"question mark is one character", "?.c export-ignore", "ab.c", false, false},
+		{"bracket range", "file[0-9].txt export-ignore", "file3.txt", false, true},
+		{"negated bracket range", "file[!0-9].txt export-ignore", "file3.txt", false, false},
+		{"negated bracket range matches others", "file[!0-9].txt export-ignore", "fileA.txt", false, true},
+		{"escaped character", `\#hash export-ignore`, "#hash", false, true},
+		{"dot is literal", "*.log export-ignore", "alog", false, false},
+		{"later line unsets", "*.log export-ignore\nkeep.log -export-ignore", "keep.log", false, false},
+		{"later line leaves others", "*.log export-ignore\nkeep.log -export-ignore", "app.log", false, true},
+		{"bang unsets", "*.log export-ignore\nkeep.log !export-ignore", "keep.log", false, false},
+		{"later line sets again", "keep.log -export-ignore\n*.log export-ignore", "keep.log", false, true},
+		{"last attribute on a line wins", "onoff export-ignore -export-ignore", "onoff", false, false},
+		{"last attribute on a line wins again", "offon -export-ignore export-ignore", "offon", false, true},
+		{"value is not set", "valued export-ignore=true", "valued", false, false},
+		{"value overrides", "*.txt export-ignore\nvalued.txt export-ignore=yes", "valued.txt", false, false},
+		{"other attributes", "*.txt text eol=lf", "a.txt", false, false},
+		{"comment", "# ignored export-ignore", "ignored", false, false},
+		{"negative pattern forbidden", "!neg export-ignore", "neg", false, false},
+		{"macro definition", "[attr]macro export-ignore", "macro", false, false},
+		{"unmatched bracket is literal", "a[b export-ignore", "a[b", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scopes := []attrScope{attrScopeOf("", tt.attrs)}
+			path := filepath.Join(fixtureRoot, filepath.FromSlash(tt.path))
+			if got := exportIgnored(scopes, path, tt.isDir); got != tt.want {
+				t.Errorf("%q with %q: got %v, want %v", tt.path, tt.attrs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportIgnoredScopes(t *testing.T) {
+	scopes := []attrScope{
+		attrScopeOf("", "*.log export-ignore\n/gen export-ignore\n"),
+		attrScopeOf("src", "keep.log -export-ignore\n/gen export-ignore\n"),
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"app.log", true},
+		{"src/app.log", true},       // Falls through to the root file
+		{"src/keep.log", false},     // The deeper file wins
+		{"keep.log", true},          // Deeper rules do not reach up
+		{"gen", true},               // Anchored at the root
+		{"src/gen", true},           // Anchored at src
+		{"src/deeper/gen", false},   // Anchored at neither
+		{"other/src/gen", false},    // Not below the src scope
+		{"src/sub/keep.log", false}, // Base names match at any depth below the scope
+	}
+	for _, tt := range tests {
+		path := filepath.Join(fixtureRoot, filepath.FromSlash(tt.path))
+		if got := exportIgnored(scopes, path, strings.HasSuffix(tt.path, "gen")); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// exportIgnoreFixture is a tree with .gitattributes files at the root and in src.
+func exportIgnoreFixture() fstest.MapFS {
+	file := &fstest.MapFile{Data: []byte("x"), Mode: 0o644}
+	return fstest.MapFS{
+		".gitattributes":     {Data: []byte("/docs export-ignore\n*.log export-ignore\nvendor/** export-ignore\n"), Mode: 0o644},
+		"README.md":          file,
+		"docs/guide.md":      file,
+		"src/.gitattributes": {Data: []byte("*_test.go export-ignore\n/gen export-ignore\nkeep.log -export-ignore\n"), Mode: 0o644},
+		"src/app.log":        file,
+		"src/gen/api.go":     file,
+		"src/keep.log":       file,
+		"src/main.go":        file,
+		"src/main_test.go":   file,
+		"vendor/lib/x.go":    file,
+	}
+}
+
+func TestScanMarksExportIgnored(t *testing.T) {
+	want := map[string]bool{
+		".gitattributes":     false,
+		"README.md":          false,
+		"docs":               true,
+		"docs/guide.md":      true, // Inherited from the directory
+		"src":                false,
+		"src/.gitattributes": false,
+		"src/app.log":        true,
+		"src/gen":            true,
+		"src/gen/api.go":     true,
+		"src/keep.log":       false,
+		"src/main.go":        false,
+		"src/main_test.go":   true,
+		"vendor":             false,
+		"vendor/lib":         true,
+		"vendor/lib/x.go":    true,
+	}
+
+	for _, showHidden := range []bool{true, false} {
+		cfg := fixtureConfig()
+		cfg.ShowHidden = showHidden // Hidden attributes files still apply
+		result := scanFixture(t, cfg, exportIgnoreFixture())
+
+		got := make(map[string]bool)
+		var walk func(node *TreeNode)
+		walk = func(node *TreeNode) {
+			for _, child := range node.Children {
+				rel, _ := filepath.Rel(fixtureRoot, child.Path)
+				got[filepath.ToSlash(rel)] = child.ExportIgnore
+				walk(child)
+			}
+		}
+		walk(result.Root)
+
+		for rel, ignored := range want {
+			if strings.Contains(rel, ".gitattributes") && !showHidden {
+				continue
+			}
+			if seen, ok := got[rel]; !ok {
+				t.Errorf("ShowHidden %v: %s missing from the tree", showHidden, rel)
+			} else if seen != ignored {
+				t.Errorf("ShowHidden %v: %s ExportIgnore = %v, want %v", showHidden, rel, seen, ignored)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GitignoreRule is the filter rule name under which .gitignore exclusions are counted.
+const GitignoreRule = "gitignore"
+
+// gitIgnoreFile is the per-directory ignore file git reads.
+const gitIgnoreFile = ".gitignore"
+
+// ignorePattern is one pattern line of a .gitignore file.
+type ignorePattern struct {
+	pattern *regexp.Regexp
+	text    string
+	line    int
+	dirOnly bool // Pattern ended in a slash
+	negate  bool // Pattern started with "!" and re-includes matches
+}
+
+// ignoreScope holds the patterns of one .gitignore file and the directory they apply to.
+type ignoreScope struct {
+	dir      string
+	source   string
+	patterns []ignorePattern
+}
+
+// gitignoreRule excludes entries matched by .gitignore files in their directory and its
+// ancestors, up to the enclosing repository root. Files are read once per rule.
+type gitignoreRule struct {
+	files  fileSystem
+	chains map[string][]*ignoreScope // Directory to the scopes in effect there, outermost first
+}
+
+// newGitignoreRule creates a rule reading files, with an empty cache.
+func newGitignoreRule(files fileSystem) *gitignoreRule {
+	return &gitignoreRule{files: files, chains: make(map[string][]*ignoreScope)}
+}
+
+// Name implements FilterRule.
+func (r *gitignoreRule) Name() string { return GitignoreRule }
+
+// Match implements FilterRule. Deeper files and later lines take precedence, so a
+// nested .gitignore can re-include what a parent excluded and vice versa.
+func (r *gitignoreRule) Match(entry EntryInfo) (RuleMatch, bool) {
+	// Git never tracks its own directory
+	if entry.Name == ".git" {
+		return RuleMatch{Rule: r.Name(), Pattern: ".git"}, true
+	}
+	chain := r.chain(filepath.Dir(entry.Path))
+	for i := len(chain) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(chain[i].dir, entry.Path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		patterns := chain[i].patterns
+		for j := len(patterns) - 1; j >= 0; j-- {
+			p := patterns[j]
+			if p.dirOnly && !entry.IsDir {
+				continue
+			}
+			if p.pattern.MatchString(rel) {
+				if p.negate {
+					return RuleMatch{}, false
+				}
+				return RuleMatch{Rule: r.Name(), Pattern: p.text, Source: fmt.Sprintf("%s:%d", chain[i].source, p.line)}, true
+			}
+		}
+	}
+	return RuleMatch{}, false
+}
+
+// chain returns the scopes in effect in dir, reading .gitignore files as needed.
+func (r *gitignoreRule) chain(dir string) []*ignoreScope {
+	if chain, ok := r.chains[dir]; ok {
+		return chain
+	}
+
+	var chain []*ignoreScope
+	// A repository root ends the chain; patterns from outside the repository do not apply
+	if _, err := r.files.Lstat(filepath.Join(dir, ".git")); err != nil {
+		if parent := filepath.Dir(dir); parent != dir {
+			chain = r.chain(parent)
+		}
+	}
+	if scope := readGitignore(r.files, dir); scope != nil {
+		chain = append(chain[:len(chain):len(chain)], scope)
+	}
+	r.chains[dir] = chain
+	return chain
+}
+
+// readGitignore parses dir's .gitignore file, returning nil if there is none.
+func readGitignore(files fileSystem, dir string) *ignoreScope {
+	path := filepath.Join(dir, gitIgnoreFile)
+	file, err := files.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	scope := &ignoreScope{dir: dir, source: path}
+	lines := bufio.NewScanner(file)
+	for n := 1; lines.Scan(); n++ {
+		if pattern, ok := parseIgnoreLine(lines.Text()); ok {
+			pattern.line = n
+			scope.patterns = append(scope.patterns, pattern)
+		}
+	}
+	return scope
+}
+
+// parseIgnoreLine parses a .gitignore line, skipping blanks and comments.
+func parseIgnoreLine(line string) (ignorePattern, bool) {
+	// Trailing spaces are ignored unless escaped with a backslash
+	trimmed := strings.TrimRight(line, " \t\r")
+	if strings.HasSuffix(trimmed, `\`) && len(trimmed) < len(line) {
+		trimmed += " "
+	}
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return ignorePattern{}, false
+	}
+
+	p := ignorePattern{text: trimmed}
+	pattern := trimmed
+	if strings.HasPrefix(pattern, "!") {
+		p.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		p.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if pattern == "" {
+		return ignorePattern{}, false
+	}
+	p.pattern = compileGitPattern(pattern)
+	return p, p.pattern != nil
+}
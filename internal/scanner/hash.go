@@ -0,0 +1,89 @@
+package scanner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.Hashes)
+}
+
+// hashChunk is how much of a file is hashed between cancellation checks.
+const hashChunk = 256 << 10
+
+// emptyHash is the hash of empty content, which FindDuplicates does not group.
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// hashFile sets node.Hash to the hex SHA-256 of its content when it is a regular file of at most
+// Config.HashMaxBytes. Links, larger and unreadable files, and files whose hashing is cancelled
+// get no hash.
+func (s *FileTreeScanner) hashFile(ctx context.Context, state *scanState, node *TreeNode) {
+	if node.IsSymlink {
+		return
+	}
+	file, err := state.source.Open(node.Path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() || (s.config.HashMaxBytes > 0 && info.Size() > s.config.HashMaxBytes) {
+		return
+	}
+
+	hash := sha256.New()
+	buf := make([]byte, hashChunk)
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		n, err := file.Read(buf)
+		hash.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return
+		}
+	}
+	node.Hash = hex.EncodeToString(hash.Sum(nil))
+}
+
+// FindDuplicates groups the hashed files below root by content, keeping groups of two or more
+// in tree order. Empty files are not grouped.
+func FindDuplicates(root *TreeNode) map[string][]*TreeNode {
+	groups := make(map[string][]*TreeNode)
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		if node.Hash != "" && node.Hash != emptyHash {
+			groups[node.Hash] = append(groups[node.Hash], node)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+	for hash, nodes := range groups {
+		if len(nodes) < 2 {
+			delete(groups, hash)
+		}
+	}
+	return groups
+}
+
+// DuplicateWaste returns the number of redundant copies among r.Duplicates, every file of a
+// group but one, and the apparent size they take up, which is only known with HasSizes.
+func (r *ScanResult) DuplicateWaste() (files int, bytes int64) {
+	for _, nodes := range r.Duplicates {
+		files += len(nodes) - 1
+		bytes += int64(len(nodes)-1) * nodes[0].Size
+	}
+	return files, bytes
+}
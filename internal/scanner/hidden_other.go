@@ -0,0 +1,8 @@
+//go:build !windows
+
+package scanner
+
+// hiddenAttribute always reports false; outside Windows only the dot-prefix marks an entry hidden.
+func hiddenAttribute(path string) bool {
+	return false
+}
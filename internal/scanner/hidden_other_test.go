@@ -0,0 +1,23 @@
+//go:build !windows
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHiddenAttributeOutsideWindows(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".profile", "desktop.ini"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Only the name hides a dotfile here, which the hidden rule checks before the attribute
+		if hiddenAttribute(path) {
+			t.Errorf("%s reported hidden by an attribute", name)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// attributeFS is a fixture file system whose entries in hidden carry the hidden attribute, as
+// on Windows. It records the paths whose attribute was looked up.
+type attributeFS struct {
+	ioFileSystem
+	hidden map[string]bool // Slash-separated paths below the root
+
+	mu     sync.Mutex
+	looked []string
+}
+
+// Hidden implements fileSystem.
+func (f *attributeFS) Hidden(path string) bool {
+	name, err := f.name("hidden", path)
+	if err != nil {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.looked = append(f.looked, name)
+	return f.hidden[name]
+}
+
+// newAttributeScanner returns a scanner of fsys in which the paths in hidden carry the hidden attribute.
+func newAttributeScanner(showHidden bool, fsys fstest.MapFS, hidden ...string) (*FileTreeScanner, *attributeFS) {
+	cfg := fixtureConfig()
+	cfg.ShowHidden = showHidden
+	cfg.AlwaysShowNames = []string{"keep.ini"}
+	files := &attributeFS{ioFileSystem: ioFileSystem{fsys: fsys, root: fixtureRoot}, hidden: make(map[string]bool)}
+	for _, name := range hidden {
+		files.hidden[name] = true
+	}
+	s := NewFileTreeScannerFS(cfg, fsys, fixtureRoot)
+	s.files = files
+	return s, files
+}
+
+// attributeFixture holds dotfiles and files hidden by attribute only.
+func attributeFixture() fstest.MapFS {
+	file := &fstest.MapFile{Data: []byte("x"), Mode: 0o644}
+	return fstest.MapFS{
+		".git/config":         file,
+		"desktop.ini":         file,
+		"keep.ini":            file,
+		"AppData/Local/x.dat": file,
+		"src/main.go":         file,
+		"src/Thumbs.db":       file,
+	}
+}
+
+func TestHiddenRule(t *testing.T) {
+	s, _ := newAttributeScanner(false, attributeFixture(), "desktop.ini", "keep.ini", "AppData", "src/Thumbs.db")
+	var rule FilterRule
+	for _, r := range s.Filters(fixtureRoot) {
+		if r.Name() == HiddenRule {
+			rule = r
+		}
+	}
+	tests := []struct {
+		name    string
+		hidden  bool
+		pattern string
+	}{
+		{".git", true, ".*"},
+		{"desktop.ini", true, "FILE_ATTRIBUTE_HIDDEN"},
+		{"AppData", true, "FILE_ATTRIBUTE_HIDDEN"},
+		{"src/Thumbs.db", true, "FILE_ATTRIBUTE_HIDDEN"},
+		{"keep.ini", false, ""}, // Always shown
+		{"src/main.go", false, ""},
+		{"src", false, ""},
+	}
+	for _, tt := range tests {
+		entry := EntryInfo{Path: filepath.Join(fixtureRoot, filepath.FromSlash(tt.name)), Name: filepath.Base(tt.name)}
+		match, hidden := rule.Match(entry)
+		if hidden != tt.hidden || match.Pattern != tt.pattern {
+			t.Errorf("%s: hidden %v by %q, want %v by %q", tt.name, hidden, match.Pattern, tt.hidden, tt.pattern)
+		}
+	}
+}
+
+func TestScanSkipsHiddenAttribute(t *testing.T) {
+	s, files := newAttributeScanner(false, attributeFixture(), "desktop.ini", "AppData", "src/Thumbs.db")
+	result, err := s.ScanDirectory(context.Background(), fixtureRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(scannedPaths(result), " "), "keep.ini src src/main.go"; got != want {
+		t.Errorf("scanned %s, want %s", got, want)
+	}
+	if result.Skipped[HiddenRule] != 4 {
+		t.Errorf("%d entries skipped as hidden, want 4", result.Skipped[HiddenRule])
+	}
+
+	// Dot names are hidden by the name alone, and skipped folders are not looked into
+	for _, name := range files.looked {
+		if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "AppData/") {
+			t.Errorf("looked up the attribute of %s", name)
+		}
+	}
+	if len(files.looked) == 0 {
+		t.Error("no attribute was looked up")
+	}
+}
+
+func TestShowHiddenSkipsAttributeLookups(t *testing.T) {
+	for name, ctx := range map[string]context.Context{
+		"configured": context.Background(),
+		"override":   WithShowHidden(context.Background()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			s, files := newAttributeScanner(name == "configured", attributeFixture(), "desktop.ini")
+			result, err := s.ScanDirectory(ctx, fixtureRoot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(scannedPaths(result)) != 10 {
+				t.Errorf("scanned %v, want every entry", scannedPaths(result))
+			}
+			if len(files.looked) > 0 {
+				t.Errorf("looked up attributes of %v while showing hidden entries", files.looked)
+			}
+		})
+	}
+}
+
+func TestExplainHiddenAttribute(t *testing.T) {
+	s, _ := newAttributeScanner(false, attributeFixture(), "AppData")
+	e, err := s.Explain(context.Background(), fixtureRoot, filepath.Join(fixtureRoot, "AppData", "Local", "x.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ExcludedBy == nil || e.ExcludedBy.Rule != HiddenRule || e.ExcludedBy.Pattern != "FILE_ATTRIBUTE_HIDDEN" ||
+		e.ExcludedVia != filepath.Join(fixtureRoot, "AppData") {
+		t.Errorf("excluded by %+v via %q, want the hidden attribute of AppData", e.ExcludedBy, e.ExcludedVia)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package scanner
+
+import "golang.org/x/sys/windows"
+
+// hiddenAttribute reports whether path carries FILE_ATTRIBUTE_HIDDEN, as desktop.ini and Thumbs.db usually do.
+func hiddenAttribute(path string) bool {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	attrs, err := windows.GetFileAttributes(name)
+	if err != nil {
+		return false
+	}
+	return attrs&windows.FILE_ATTRIBUTE_HIDDEN != 0
+}
@@ -0,0 +1,79 @@
+//go:build windows
+
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/windows"
+)
+
+// setHidden gives path the hidden attribute.
+func setHidden(t *testing.T, path string) {
+	t.Helper()
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := windows.GetFileAttributes(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := windows.SetFileAttributes(name, attrs|windows.FILE_ATTRIBUTE_HIDDEN); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHiddenAttribute(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"desktop.ini", "plain.txt", ".profile", "Hidden Folder"} {
+		path := filepath.Join(dir, name)
+		var err error
+		if name == "Hidden Folder" {
+			err = os.Mkdir(path, 0o755)
+		} else {
+			err = os.WriteFile(path, nil, 0o644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	setHidden(t, filepath.Join(dir, "desktop.ini"))
+	setHidden(t, filepath.Join(dir, "Hidden Folder"))
+
+	tests := map[string]bool{
+		"desktop.ini":   true,
+		"Hidden Folder": true,
+		"plain.txt":     false,
+		".profile":      false, // Hidden by its name, not an attribute
+		"missing":       false,
+	}
+	for name, want := range tests {
+		if got := hiddenAttribute(filepath.Join(dir, name)); got != want {
+			t.Errorf("%s: hidden attribute %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestScanSkipsHiddenAttributeOnDisk(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"desktop.ini", "main.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setHidden(t, filepath.Join(dir, "desktop.ini"))
+
+	cfg := fixtureConfig()
+	cfg.ShowHidden = false
+	result, err := NewFileTreeScanner(cfg).ScanDirectory(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Root.Children) != 1 || result.Root.Children[0].Name != "main.go" {
+		t.Errorf("scanned %d entries, want main.go only", len(result.Root.Children))
+	}
+}
@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind classifies a file by what it holds, judged from its name alone.
+type Kind string
+
+// File kinds. Directories have no kind.
+const (
+	KindCode     Kind = "code"
+	KindImage    Kind = "image"
+	KindDocument Kind = "document"
+	KindArchive  Kind = "archive"
+	KindBinary   Kind = "binary" // Compiled code, data files and media
+	KindText     Kind = "text"   // Prose, configuration and data in plain text
+	KindOther    Kind = "other"  // Anything not in the table
+)
+
+// Kinds lists the file kinds in the order settings and help text show them.
+var Kinds = []Kind{KindCode, KindImage, KindDocument, KindArchive, KindBinary, KindText, KindOther}
+
+// kindsByExtension maps lower-case extensions, including compound ones like ".tar.gz", to kinds.
+var kindsByExtension = map[string]Kind{
+	// Source code, scripts, markup and stylesheets
+	".go": KindCode, ".py": KindCode, ".js": KindCode, ".mjs": KindCode, ".cjs": KindCode,
+	".jsx": KindCode, ".ts": KindCode, ".tsx": KindCode, ".java": KindCode, ".kt": KindCode,
+	".kts": KindCode, ".scala": KindCode, ".groovy": KindCode, ".gradle": KindCode, ".c": KindCode,
+	".h": KindCode, ".cpp": KindCode, ".cc": KindCode, ".cxx": KindCode, ".hpp": KindCode,
+	".cs": KindCode, ".fs": KindCode, ".vb": KindCode, ".rb": KindCode, ".php": KindCode,
+	".rs": KindCode, ".swift": KindCode, ".m": KindCode, ".mm": KindCode, ".dart": KindCode,
+	".lua": KindCode, ".pl": KindCode, ".r": KindCode, ".jl": KindCode, ".ex": KindCode,
+	".exs": KindCode, ".erl": KindCode, ".hs": KindCode, ".clj": KindCode, ".zig": KindCode,
+	".sh": KindCode, ".bash": KindCode, ".zsh": KindCode, ".fish": KindCode, ".ps1": KindCode,
+	".bat": KindCode, ".cmd": KindCode, ".sql": KindCode, ".html": KindCode, ".htm": KindCode,
+	".css": KindCode, ".scss": KindCode, ".sass": KindCode, ".less": KindCode, ".vue": KindCode,
+	".svelte": KindCode, ".asm": KindCode, ".proto": KindCode,
+
+	// Images
+	".png": KindImage, ".jpg": KindImage, ".jpeg": KindImage, ".gif": KindImage, ".bmp": KindImage,
+	".svg": KindImage, ".webp": KindImage, ".ico": KindImage, ".tif": KindImage, ".tiff": KindImage,
+	".heic": KindImage, ".heif": KindImage, ".avif": KindImage, ".psd": KindImage, ".raw": KindImage,
+
+	// Documents
+	".pdf": KindDocument, ".doc": KindDocument, ".docx": KindDocument, ".odt": KindDocument,
+	".rtf": KindDocument, ".xls": KindDocument, ".xlsx": KindDocument, ".ods": KindDocument,
+	".ppt": KindDocument, ".pptx": KindDocument, ".odp": KindDocument, ".epub": KindDocument,
+	".pages": KindDocument, ".key": KindDocument, ".numbers": KindDocument,
+
+	// Archives and packages
+	".zip": KindArchive, ".tar": KindArchive, ".gz": KindArchive, ".tgz": KindArchive,
+	".tar.gz": KindArchive, ".bz2": KindArchive, ".tar.bz2": KindArchive, ".xz": KindArchive,
+	".tar.xz": KindArchive, ".zst": KindArchive, ".7z": KindArchive, ".rar": KindArchive,
+	".jar": KindArchive, ".war": KindArchive, ".iso": KindArchive, ".dmg": KindArchive,
+	".deb": KindArchive, ".rpm": KindArchive, ".apk": KindArchive, ".whl": KindArchive,
+
+	// Compiled code, data files and media
+	".exe": KindBinary, ".dll": KindBinary, ".so": KindBinary, ".dylib": KindBinary, ".o": KindBinary,
+	".obj": KindBinary, ".a": KindBinary, ".lib": KindBinary, ".class": KindBinary, ".pyc": KindBinary,
+	".wasm": KindBinary, ".bin": KindBinary, ".dat": KindBinary, ".db": KindBinary,
+	".sqlite": KindBinary, ".mp3": KindBinary, ".wav": KindBinary, ".flac": KindBinary,
+	".ogg": KindBinary, ".mp4": KindBinary, ".mkv": KindBinary, ".mov": KindBinary,
+	".avi": KindBinary, ".webm": KindBinary, ".ttf": KindBinary, ".otf": KindBinary,
+	".woff": KindBinary, ".woff2": KindBinary,
+
+	// Plain text
+	".txt": KindText, ".md": KindText, ".markdown": KindText, ".rst": KindText, ".adoc": KindText,
+	".tex": KindText, ".json": KindText, ".yaml": KindText, ".yml": KindText, ".toml": KindText,
+	".ini": KindText, ".cfg": KindText, ".conf": KindText, ".xml": KindText, ".csv": KindText,
+	".tsv": KindText, ".log": KindText, ".env": KindText, ".properties": KindText, ".lock": KindText,
+	".gitignore": KindText, ".gitattributes": KindText, ".editorconfig": KindText, ".mod": KindText,
+	".sum": KindText,
+}
+
+// kindsByName maps lower-case names of well-known files without an extension to kinds.
+var kindsByName = map[string]Kind{
+	"makefile": KindCode, "dockerfile": KindCode, "jenkinsfile": KindCode, "rakefile": KindCode,
+	"gemfile": KindCode, "readme": KindText, "license": KindText, "copying": KindText,
+	"authors": KindText, "changelog": KindText, "notice": KindText,
+}
+
+// Classify returns the kind of a file named name. Extensions are matched longest first, so
+// "backup.tar.gz" is an archive, each first in overrides (lower-case extensions with their dot
+// mapped to kind names, as in Config.FileKinds) and then in the built-in table. Only the name is
+// looked at; files of unknown extensions are KindOther.
+func Classify(name string, overrides map[string]string) Kind {
+	lower := strings.ToLower(name)
+	for i := 0; i < len(lower); i++ {
+		if lower[i] != '.' {
+			continue
+		}
+		ext := lower[i:]
+		if kind, ok := overrides[ext]; ok {
+			return Kind(kind)
+		}
+		if kind, ok := kindsByExtension[ext]; ok {
+			return kind
+		}
+	}
+	if kind, ok := kindsByName[lower]; ok {
+		return kind
+	}
+	return KindOther
+}
+
+// ValidateKinds reports the first override that Classify could not use: an extension that is
+// not lower case or lacks its leading dot, or an unknown kind.
+func ValidateKinds(overrides map[string]string) error {
+	exts := make([]string, 0, len(overrides))
+	for ext := range overrides {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	for _, ext := range exts {
+		if !strings.HasPrefix(ext, ".") || len(ext) < 2 || ext != strings.ToLower(ext) {
+			return fmt.Errorf("invalid extension %q: use a lower-case extension with its dot, like \".proto\"", ext)
+		}
+		if !knownKind(Kind(overrides[ext])) {
+			return fmt.Errorf("unknown kind %q for %s", overrides[ext], ext)
+		}
+	}
+	return nil
+}
+
+// ParseKinds parses comma-separated "extension=kind" overrides, e.g. ".proto=code,.dat=text",
+// lower-casing the extensions and adding missing dots.
+func ParseKinds(text string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, pair := range ParsePatterns(text) {
+		ext, kind, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid kind override %q: use extension=kind", pair)
+		}
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		overrides[ext] = strings.TrimSpace(kind)
+	}
+	if err := ValidateKinds(overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// knownKind reports whether kind is one of Kinds.
+func knownKind(kind Kind) bool {
+	for _, known := range Kinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
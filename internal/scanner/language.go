@@ -0,0 +1,199 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Languages summarizes at most maxLanguages languages by name, folding the rest into OtherLanguage.
+const (
+	maxLanguages  = 4
+	OtherLanguage = "Other"
+)
+
+// Weights a language breakdown can be measured in, best first.
+const (
+	WeighByLines = "lines" // With counted lines (Config.CountLines)
+	WeighBySize  = "size"  // With collected sizes (Config.ShowSize)
+	WeighByFiles = "files" // Otherwise
+)
+
+// languagesByExtension maps lower-case extensions to the language of the files having them.
+var languagesByExtension = map[string]string{
+	".go": "Go", ".py": "Python", ".pyi": "Python", ".js": "JavaScript", ".mjs": "JavaScript",
+	".cjs": "JavaScript", ".jsx": "JavaScript", ".ts": "TypeScript", ".tsx": "TypeScript",
+	".mts": "TypeScript", ".java": "Java", ".kt": "Kotlin", ".kts": "Kotlin", ".scala": "Scala",
+	".groovy": "Groovy", ".gradle": "Gradle", ".c": "C", ".h": "C", ".cpp": "C++", ".cc": "C++",
+	".cxx": "C++", ".hpp": "C++", ".hh": "C++", ".cs": "C#", ".fs": "F#", ".vb": "Visual Basic",
+	".rb": "Ruby", ".php": "PHP", ".rs": "Rust", ".swift": "Swift", ".m": "Objective-C",
+	".mm": "Objective-C++", ".dart": "Dart", ".lua": "Lua", ".pl": "Perl", ".pm": "Perl", ".r": "R",
+	".jl": "Julia", ".ex": "Elixir", ".exs": "Elixir", ".erl": "Erlang", ".hs": "Haskell",
+	".clj": "Clojure", ".zig": "Zig", ".ml": "OCaml", ".nim": "Nim", ".sh": "Shell", ".bash": "Shell",
+	".zsh": "Shell", ".fish": "Fish", ".ps1": "PowerShell", ".bat": "Batchfile", ".cmd": "Batchfile",
+	".sql": "SQL", ".html": "HTML", ".htm": "HTML", ".css": "CSS", ".scss": "SCSS", ".sass": "Sass",
+	".less": "Less", ".vue": "Vue", ".svelte": "Svelte", ".asm": "Assembly", ".s": "Assembly",
+	".proto": "Protocol Buffers", ".tf": "HCL", ".hcl": "HCL", ".nix": "Nix", ".yaml": "YAML",
+	".yml": "YAML", ".json": "JSON", ".toml": "TOML", ".xml": "XML", ".md": "Markdown",
+	".markdown": "Markdown", ".rst": "reStructuredText", ".tex": "TeX",
+}
+
+// languagesByName maps lower-case file names without a telling extension to their language.
+var languagesByName = map[string]string{
+	"makefile": "Makefile", "gnumakefile": "Makefile", "dockerfile": "Dockerfile",
+	"jenkinsfile": "Groovy", "rakefile": "Ruby", "gemfile": "Ruby", "cmakelists.txt": "CMake",
+}
+
+// LanguageShare is one language's part of a breakdown.
+type LanguageShare struct {
+	Name    string `json:"name"`
+	Weight  int64  `json:"weight"`  // Lines, bytes or files, by the breakdown's weight
+	Percent int    `json:"percent"` // Rounded so the shares of a breakdown add up to 100
+}
+
+// LanguageOf returns the language of a file named name, or "" for files in no known language.
+// Extensions are matched longest first, each first in overrides (lower-case extensions with their
+// dot mapped to language names, as in Config.Languages, where "" leaves the extension out) and
+// then in the built-in table; a few names without an extension, like Makefile, are known too.
+func LanguageOf(name string, overrides map[string]string) string {
+	lower := strings.ToLower(name)
+	if language, ok := languagesByName[lower]; ok {
+		return language
+	}
+	for i := 0; i < len(lower); i++ {
+		if lower[i] != '.' {
+			continue
+		}
+		ext := lower[i:]
+		if language, ok := overrides[ext]; ok {
+			return language
+		}
+		if language, ok := languagesByExtension[ext]; ok {
+			return language
+		}
+	}
+	return ""
+}
+
+// ParseLanguages parses comma-separated "extension=language" overrides, e.g.
+// ".tpl=Go Template,.inc=PHP,.txt=", lower-casing the extensions and adding missing dots.
+func ParseLanguages(text string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, pair := range ParsePatterns(text) {
+		ext, language, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid language override %q: use extension=language", pair)
+		}
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if len(ext) < 2 {
+			return nil, fmt.Errorf("invalid language override %q: the extension is empty", pair)
+		}
+		overrides[ext] = strings.TrimSpace(language)
+	}
+	return overrides, nil
+}
+
+// LanguageBreakdown measures the languages of the files below root by lines when they were
+// counted, by size when sizes were collected, and by number of files otherwise, returning the
+// weight used. Files in no known language and entries that stand in for unread contents are
+// not counted, nor, as they are not in the tree, are those left out by filters. At most
+// maxLanguages languages are named, largest first, with the rest as OtherLanguage; percentages
+// are rounded by largest remainder, so they always add up to 100. Without any file in a known
+// language the breakdown is nil.
+func LanguageBreakdown(root *TreeNode, overrides map[string]string, hasLines, hasSizes bool) ([]LanguageShare, string) {
+	by := WeighByFiles
+	switch {
+	case hasLines:
+		by = WeighByLines
+	case hasSizes:
+		by = WeighBySize
+	}
+
+	weights := make(map[string]int64)
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		for _, child := range node.Children {
+			if child.IsDir {
+				walk(child)
+				continue
+			}
+			if child.Placeholder || child.IsSymlink {
+				continue
+			}
+			language := LanguageOf(child.Name, overrides)
+			if language == "" {
+				continue
+			}
+			switch by {
+			case WeighByLines:
+				weights[language] += int64(child.Lines)
+			case WeighBySize:
+				weights[language] += child.Size
+			default:
+				weights[language]++
+			}
+		}
+	}
+	if root != nil {
+		walk(root)
+	}
+
+	shares := make([]LanguageShare, 0, len(weights))
+	var total int64
+	for name, weight := range weights {
+		if weight > 0 {
+			shares = append(shares, LanguageShare{Name: name, Weight: weight})
+			total += weight
+		}
+	}
+	if total == 0 {
+		return nil, by
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Weight != shares[j].Weight {
+			return shares[i].Weight > shares[j].Weight
+		}
+		return shares[i].Name < shares[j].Name
+	})
+	if len(shares) > maxLanguages+1 {
+		other := LanguageShare{Name: OtherLanguage}
+		for _, share := range shares[maxLanguages:] {
+			other.Weight += share.Weight
+		}
+		shares = append(shares[:maxLanguages], other)
+	}
+	roundShares(shares, total)
+	return shares, by
+}
+
+// roundShares sets the percentages of shares, which sum to total, rounding down and handing the
+// points left over to the largest remainders, earlier shares first among equal remainders.
+func roundShares(shares []LanguageShare, total int64) {
+	remainders := make([]int64, len(shares))
+	left := 100
+	for i := range shares {
+		shares[i].Percent = int(shares[i].Weight * 100 / total)
+		remainders[i] = shares[i].Weight * 100 % total
+		left -= shares[i].Percent
+	}
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for _, i := range order[:left] {
+		shares[i].Percent++
+	}
+}
+
+// FormatLanguages returns a breakdown as one line, like "Go 62%, TypeScript 21%, Other 17%".
+func FormatLanguages(shares []LanguageShare) string {
+	parts := make([]string, len(shares))
+	for i, share := range shares {
+		parts[i] = fmt.Sprintf("%s %d%%", share.Name, share.Percent)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,198 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// languageTree returns a directory holding files, nested one level down so breakdowns walk
+// into directories.
+func languageTree(files ...*TreeNode) *TreeNode {
+	dir := &TreeNode{Name: "src", IsDir: true, Children: files}
+	return &TreeNode{Name: "root", IsDir: true, Children: []*TreeNode{dir}}
+}
+
+// sourceFiles returns count files named after ext, each weighing lines lines and size bytes.
+func sourceFiles(ext string, count, lines int, size int64) []*TreeNode {
+	var files []*TreeNode
+	for i := 0; i < count; i++ {
+		files = append(files, &TreeNode{Name: string(rune('a'+i)) + ext, Lines: lines, Size: size})
+	}
+	return files
+}
+
+// percents returns the names and percentages of shares.
+func percents(shares []LanguageShare) map[string]int {
+	out := make(map[string]int)
+	for _, share := range shares {
+		out[share.Name] = share.Percent
+	}
+	return out
+}
+
+// shareNames returns the names of shares in order.
+func shareNames(shares []LanguageShare) []string {
+	var out []string
+	for _, share := range shares {
+		out = append(out, share.Name)
+	}
+	return out
+}
+
+func TestLanguageBreakdownRounding(t *testing.T) {
+	tests := []struct {
+		name  string
+		files [][]*TreeNode
+		want  []LanguageShare
+	}{
+		{
+			name:  "three equal shares",
+			files: [][]*TreeNode{sourceFiles(".rs", 1, 0, 0), sourceFiles(".go", 1, 0, 0), sourceFiles(".py", 1, 0, 0)},
+			// The point left over goes to the first of equal remainders
+			want: []LanguageShare{{"Go", 1, 34}, {"Python", 1, 33}, {"Rust", 1, 33}},
+		},
+		{
+			name:  "two to one",
+			files: [][]*TreeNode{sourceFiles(".go", 2, 0, 0), sourceFiles(".py", 1, 0, 0)},
+			want:  []LanguageShare{{"Go", 2, 67}, {"Python", 1, 33}},
+		},
+		{
+			name:  "single language",
+			files: [][]*TreeNode{sourceFiles(".go", 3, 0, 0)},
+			want:  []LanguageShare{{"Go", 3, 100}},
+		},
+		{
+			name: "tiny languages folded into Other",
+			files: [][]*TreeNode{
+				sourceFiles(".go", 200, 0, 0), sourceFiles(".c", 1, 0, 0), sourceFiles(".rb", 1, 0, 0),
+				sourceFiles(".lua", 1, 0, 0), sourceFiles(".zig", 1, 0, 0), sourceFiles(".nim", 1, 0, 0),
+				sourceFiles(".hs", 1, 0, 0),
+			},
+			// The two points left over go to the largest remainders, of 100/206 against 94/206 for Other
+			want: []LanguageShare{{"Go", 200, 97}, {"C", 1, 1}, {"Haskell", 1, 1}, {"Lua", 1, 0}, {OtherLanguage, 3, 1}},
+		},
+		{
+			name: "five languages are all named",
+			files: [][]*TreeNode{
+				sourceFiles(".go", 1, 0, 0), sourceFiles(".c", 1, 0, 0), sourceFiles(".rb", 1, 0, 0),
+				sourceFiles(".lua", 1, 0, 0), sourceFiles(".zig", 1, 0, 0),
+			},
+			want: []LanguageShare{{"C", 1, 20}, {"Go", 1, 20}, {"Lua", 1, 20}, {"Ruby", 1, 20}, {"Zig", 1, 20}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []*TreeNode
+			for _, group := range tt.files {
+				files = append(files, group...)
+			}
+			shares, by := LanguageBreakdown(languageTree(files...), nil, false, false)
+			if by != WeighByFiles {
+				t.Errorf("weighed by %q, want %q", by, WeighByFiles)
+			}
+			if !reflect.DeepEqual(shares, tt.want) {
+				t.Errorf("breakdown %v, want %v", shares, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundSharesAddUpTo100(t *testing.T) {
+	for a := int64(1); a <= 12; a++ {
+		for b := int64(0); b <= 12; b++ {
+			for c := int64(0); c <= 12; c++ {
+				shares := []LanguageShare{{Name: "a", Weight: a}, {Name: "b", Weight: b}, {Name: "c", Weight: c}}
+				roundShares(shares, a+b+c)
+				sum := 0
+				for _, share := range shares {
+					sum += share.Percent
+				}
+				if sum != 100 {
+					t.Fatalf("weights %d, %d, %d rounded to %v, adding up to %d", a, b, c, shares, sum)
+				}
+			}
+		}
+	}
+}
+
+func TestLanguageBreakdownWeights(t *testing.T) {
+	files := append(sourceFiles(".go", 1, 30, 100), sourceFiles(".py", 2, 10, 200)...)
+	files = append(files,
+		&TreeNode{Name: "notes.txt", Lines: 1000, Size: 1000},                  // No known language
+		&TreeNode{Name: "link.go", Lines: 1000, Size: 1000, IsSymlink: true},   // Counted where it points
+		&TreeNode{Name: "more.go", Lines: 1000, Size: 1000, Placeholder: true}, // Stands in for unread entries
+	)
+	root := languageTree(files...)
+	tests := []struct {
+		hasLines, hasSizes bool
+		by                 string
+		want               map[string]int
+	}{
+		{true, true, WeighByLines, map[string]int{"Go": 60, "Python": 40}},
+		{false, true, WeighBySize, map[string]int{"Python": 80, "Go": 20}},
+		{false, false, WeighByFiles, map[string]int{"Python": 67, "Go": 33}},
+	}
+	for _, tt := range tests {
+		shares, by := LanguageBreakdown(root, nil, tt.hasLines, tt.hasSizes)
+		if by != tt.by {
+			t.Errorf("lines %v, sizes %v: weighed by %q, want %q", tt.hasLines, tt.hasSizes, by, tt.by)
+		}
+		if got := percents(shares); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("by %s: %v, want %v", tt.by, got, tt.want)
+		}
+	}
+	if shares, _ := LanguageBreakdown(root, nil, false, true); !reflect.DeepEqual(shareNames(shares), []string{"Python", "Go"}) {
+		t.Errorf("by size, languages in order %q, want the largest first", shareNames(shares))
+	}
+}
+
+func TestLanguageBreakdownEmpty(t *testing.T) {
+	if shares, _ := LanguageBreakdown(languageTree(&TreeNode{Name: "notes.txt"}), nil, false, false); shares != nil {
+		t.Errorf("breakdown without known languages %v, want nil", shares)
+	}
+	if shares, _ := LanguageBreakdown(languageTree(sourceFiles(".go", 1, 0, 0)...), nil, true, false); shares != nil {
+		t.Errorf("breakdown of files without lines %v, want nil", shares)
+	}
+	if shares, _ := LanguageBreakdown(nil, nil, false, false); shares != nil {
+		t.Errorf("breakdown of no tree %v, want nil", shares)
+	}
+}
+
+func TestLanguageBreakdownOverrides(t *testing.T) {
+	root := languageTree(&TreeNode{Name: "page.tpl"}, &TreeNode{Name: "data.json"}, &TreeNode{Name: "main.go"})
+	shares, _ := LanguageBreakdown(root, map[string]string{".tpl": "Go Template", ".json": ""}, false, false)
+	if got := percents(shares); !reflect.DeepEqual(got, map[string]int{"Go": 50, "Go Template": 50}) {
+		t.Errorf("breakdown with overrides %v", got)
+	}
+}
+
+func TestLanguageBreakdownSkipsFiltered(t *testing.T) {
+	file := &fstest.MapFile{Data: []byte("x"), Mode: 0o644}
+	fixture := fstest.MapFS{
+		"main.go":             file,
+		"vendor/lib/a.js":     file,
+		"vendor/lib/b.js":     file,
+		"vendor/lib/c.js":     file,
+		"node_modules/x/y.ts": file,
+	}
+	cfg := fixtureConfig()
+	if shares, _ := LanguageBreakdown(scanFixture(t, cfg, fixture).Root, nil, false, false); percents(shares)["JavaScript"] != 60 {
+		t.Fatalf("breakdown with vendored files %v", shares)
+	}
+	cfg.ExcludePatterns = []string{"vendor/", "node_modules/"}
+	shares, _ := LanguageBreakdown(scanFixture(t, cfg, fixture).Root, nil, false, false)
+	if got := percents(shares); !reflect.DeepEqual(got, map[string]int{"Go": 100}) {
+		t.Errorf("breakdown without vendored files %v, want only Go", got)
+	}
+}
+
+func TestFormatLanguages(t *testing.T) {
+	shares := []LanguageShare{{"Go", 62, 62}, {"TypeScript", 21, 21}, {OtherLanguage, 17, 17}}
+	if got, want := FormatLanguages(shares), "Go 62%, TypeScript 21%, Other 17%"; got != want {
+		t.Errorf("FormatLanguages = %q, want %q", got, want)
+	}
+	if got := FormatLanguages(nil); got != "" {
+		t.Errorf("FormatLanguages(nil) = %q, want empty", got)
+	}
+}
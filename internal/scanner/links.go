@@ -0,0 +1,71 @@
+package scanner
+
+import "path/filepath"
+
+// NormalizeLinks sets LinkNormalized for the symbolic links below root from their literal
+// targets. A target inside root becomes slash-separated and relative to the link's directory,
+// like "../shared/assets", however the link spells it; any other becomes an absolute path.
+// Relative targets are resolved from where the link really is, which differs from its path in
+// the tree below a followed link to a directory.
+func NormalizeLinks(root *TreeNode) {
+	if root == nil {
+		return
+	}
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		for _, child := range node.Children {
+			if child.IsSymlink {
+				child.LinkNormalized = normalizeLink(root, child)
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+// normalizeLink returns the normalized target of node, a link below root, or "" when its target
+// is unknown.
+func normalizeLink(root *TreeNode, node *TreeNode) string {
+	if node.LinkTarget == "" {
+		return ""
+	}
+	dir := filepath.Dir(node.Path)
+	realDir := dir
+	if node.Parent != nil && node.Parent.realPath != "" {
+		realDir = node.Parent.realPath
+	}
+	target := filepath.FromSlash(node.LinkTarget)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(realDir, target)
+	}
+	target = filepath.Clean(target)
+
+	// The target's place in the tree, found from the root's real path or the path it was scanned at
+	shown := ""
+	for _, base := range []string{root.realPath, root.Path} {
+		if base == "" || (target != filepath.Clean(base) && !within(base, target)) {
+			continue
+		}
+		if rel, err := filepath.Rel(base, target); err == nil {
+			shown = filepath.Join(root.Path, rel)
+			break
+		}
+	}
+	if shown == "" {
+		return target
+	}
+	rel, err := filepath.Rel(dir, shown)
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ShownTarget returns the target of a symbolic link as output shows it: the normalized form
+// when there is one, else the literal target.
+func (n *TreeNode) ShownTarget() string {
+	if n.LinkNormalized != "" {
+		return n.LinkNormalized
+	}
+	return n.LinkTarget
+}
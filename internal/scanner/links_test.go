@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// symlink creates a symbolic link at link, relative to dir, pointing at target, skipping the
+// test where links cannot be made.
+func symlink(t *testing.T, dir, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link))); err != nil {
+		t.Skipf("cannot create symbolic links: %v", err)
+	}
+}
+
+// mkfile creates the file at rel below dir with its parent directories.
+func mkfile(t *testing.T, dir, rel string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// linkTargets scans root with cfg and returns the shown target of each link below it by its
+// slash-separated path relative to root, marking broken links with a trailing " (broken)".
+func linkTargets(t *testing.T, root string, follow bool) map[string]string {
+	t.Helper()
+	cfg := fixtureConfig()
+	cfg.FollowSymlinks = follow
+	result, err := NewFileTreeScanner(cfg).ScanDirectory(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets := make(map[string]string)
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		for _, child := range node.Children {
+			if child.IsSymlink {
+				rel, _ := filepath.Rel(root, child.Path)
+				target := child.ShownTarget()
+				if child.LinkBroken {
+					target += " (broken)"
+				}
+				targets[filepath.ToSlash(rel)] = target
+			}
+			walk(child)
+		}
+	}
+	walk(result.Root)
+	return targets
+}
+
+func TestNormalizeLinks(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	mkfile(t, root, "b/file.txt")
+	mkfile(t, root, "a/keep")
+	mkfile(t, outside, "x.txt")
+
+	symlink(t, root, "../b/file.txt", "a/relative")
+	symlink(t, root, "./../a/../b/./file.txt", "a/roundabout")
+	symlink(t, root, filepath.Join(root, "b", "file.txt"), "a/absolute-inside")
+	symlink(t, root, filepath.Join(outside, "x.txt"), "a/absolute-outside")
+	symlink(t, root, "../../outside/x.txt", "a/relative-outside")
+	symlink(t, root, "b", "dir-link")
+	symlink(t, root, "hop", "chain")
+	symlink(t, root, "b/file.txt", "hop")
+	symlink(t, root, "missing.txt", "a/dead")
+	symlink(t, root, filepath.Join(outside, "gone.txt"), "a/dead-outside")
+
+	want := map[string]string{
+		"a/relative":         "../b/file.txt",
+		"a/roundabout":       "../b/file.txt",
+		"a/absolute-inside":  "../b/file.txt",
+		"a/absolute-outside": filepath.Join(outside, "x.txt"),
+		"a/relative-outside": filepath.Join(outside, "x.txt"),
+		"dir-link":           "b",
+		"chain":              "hop", // Each link shows its own hop, not the end of the chain
+		"hop":                "b/file.txt",
+		"a/dead":             "missing.txt (broken)",
+		"a/dead-outside":     filepath.Join(outside, "gone.txt") + " (broken)",
+	}
+	got := linkTargets(t, root, false)
+	for link, target := range want {
+		if got[link] != target {
+			t.Errorf("%s -> %q, want %q", link, got[link], target)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("%d links found, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestNormalizeLinksBelowFollowedLink(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(base, "root")
+	outside := filepath.Join(base, "outside")
+	mkfile(t, root, "b/file.txt")
+	mkfile(t, outside, "x.txt")
+	mkfile(t, outside, "dir/keep")
+
+	// Inside root/ext the links really sit in outside/dir
+	symlink(t, root, filepath.Join(outside, "dir"), "ext")
+	symlink(t, outside, "../x.txt", "dir/up")
+	symlink(t, outside, filepath.Join(root, "b", "file.txt"), "dir/back")
+
+	got := linkTargets(t, root, true)
+	if want := filepath.Join(outside, "x.txt"); got["ext/up"] != want {
+		t.Errorf("ext/up -> %q, want %q outside the root", got["ext/up"], want)
+	}
+	if got["ext/back"] != "../b/file.txt" {
+		t.Errorf("ext/back -> %q, want %q", got["ext/back"], "../b/file.txt")
+	}
+}
+
+func TestNormalizeLinksScannedThroughLink(t *testing.T) {
+	base, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(base, "real")
+	mkfile(t, real, "b/file.txt")
+	mkfile(t, real, "a/keep")
+	symlink(t, base, "real", "alias")
+	// Spelled with the real path while the scan goes through the alias
+	symlink(t, real, filepath.Join(real, "b", "file.txt"), "a/absolute")
+
+	got := linkTargets(t, filepath.Join(base, "alias"), false)
+	if got["a/absolute"] != "../b/file.txt" {
+		t.Errorf("a/absolute -> %q, want %q", got["a/absolute"], "../b/file.txt")
+	}
+}
@@ -0,0 +1,34 @@
+package scanner
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// memoryCheckInterval is the number of directories read between heap checks.
+const memoryCheckInterval = 256
+
+// heapInUse reports the bytes of allocated heap objects.
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// checkMemory stops the scan from descending further once the heap exceeds the configured ceiling.
+// The heap is only sampled every memoryCheckInterval directories to keep the check cheap.
+// state.mu must be held.
+func (s *FileTreeScanner) checkMemory(state *scanState) {
+	if s.config.MaxHeapBytes == 0 || state.stopped {
+		return
+	}
+
+	state.dirsRead++
+	if state.dirsRead%memoryCheckInterval != 0 {
+		return
+	}
+
+	if s.readHeap() > s.config.MaxHeapBytes {
+		state.stop(ReasonMemoryLimit, fmt.Sprintf("%d MiB", s.config.MaxHeapBytes>>20))
+	}
+}
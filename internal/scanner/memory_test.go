@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/testtree"
+)
+
+// memorySpec is a tree of 585 directories, enough for two heap checks.
+var memorySpec = testtree.Spec{Seed: 2, Depth: 3, FanOut: 8, Files: 2}
+
+// fakeHeap returns a heap reader reporting heaps in turn, repeating the last, and a pointer to
+// the number of reads.
+func fakeHeap(heaps ...uint64) (func() uint64, *int) {
+	reads := 0
+	return func() uint64 {
+		heap := heaps[min(reads, len(heaps)-1)]
+		reads++
+		return heap
+	}, &reads
+}
+
+// scanWithHeap scans memorySpec one directory at a time with the heap ceiling and readHeap.
+func scanWithHeap(t *testing.T, ceiling uint64, readHeap func() uint64) *ScanResult {
+	t.Helper()
+	cfg := fixtureConfig()
+	cfg.ConcurrentOps = 1
+	cfg.MaxHeapBytes = ceiling
+	s := NewFileTreeScannerFS(cfg, memorySpec.FS(), fixtureRoot)
+	s.readHeap = readHeap
+	result, err := s.ScanDirectory(context.Background(), fixtureRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestMemoryLimitStopsDescending(t *testing.T) {
+	readHeap, reads := fakeHeap(512<<10, 2<<20)
+	result := scanWithHeap(t, 1<<20, readHeap)
+
+	if *reads != 2 {
+		t.Errorf("heap read %d times, want 2", *reads)
+	}
+	if !result.Truncated || result.TruncatedReason != ReasonMemoryLimit || result.TruncatedLimit != "1 MiB" {
+		t.Fatalf("Truncated %v (%q, %q), want stopped at the memory limit of 1 MiB",
+			result.Truncated, result.TruncatedReason, result.TruncatedLimit)
+	}
+	if !result.CountsPartial {
+		t.Error("counts not marked partial")
+	}
+
+	// Directories left unread and entries left out are marked with the reason
+	counts := memorySpec.Count()
+	var unread, omitted int
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		if node.NotRead || node.Omitted > 0 {
+			if node.TruncateReason != string(ReasonMemoryLimit) {
+				t.Errorf("%s: cut short with reason %q", node.Path, node.TruncateReason)
+			}
+		}
+		if node.NotRead {
+			unread++
+		}
+		omitted += node.Omitted
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(result.Root)
+	if unread == 0 || omitted == 0 {
+		t.Errorf("%d directories unread and %d entries omitted, want some of each", unread, omitted)
+	}
+	if result.DirCount >= counts.Dirs || result.FileCount >= counts.Files {
+		t.Errorf("%d directories and %d files listed, want fewer than the %d and %d in the tree",
+			result.DirCount, result.FileCount, counts.Dirs, counts.Files)
+	}
+}
+
+func TestMemoryBelowLimit(t *testing.T) {
+	readHeap, reads := fakeHeap(1 << 20)
+	result := scanWithHeap(t, 1<<20, readHeap)
+
+	// 585 directories: one check after every memoryCheckInterval of them
+	if want := (memorySpec.Count().Dirs + 1) / memoryCheckInterval; *reads != want {
+		t.Errorf("heap read %d times, want %d", *reads, want)
+	}
+	if result.Truncated {
+		t.Errorf("scan stopped (%s) at a heap equal to the ceiling", result.TruncatedReason)
+	}
+}
+
+func TestMemoryLimitDisabled(t *testing.T) {
+	readHeap, reads := fakeHeap(1 << 40)
+	result := scanWithHeap(t, 0, readHeap)
+	if *reads != 0 || result.Truncated {
+		t.Errorf("heap read %d times, truncated %v; want no checks without a ceiling", *reads, result.Truncated)
+	}
+}
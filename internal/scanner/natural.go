@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NaturalLess reports whether name a sorts before b in natural order: runs of ASCII digits
+// compare by their numeric value, so "file2" comes before "file10", and everything else
+// compares letter by letter ignoring case. Names that are equal under these rules, such as
+// "File" and "file" or "07" and "7", fall back to plain byte order so the order stays total.
+func NaturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			endA, endB := digitRun(a, i), digitRun(b, j)
+			if c := compareNumbers(a[i:endA], b[j:endB]); c != 0 {
+				return c < 0
+			}
+			i, j = endA, endB
+			continue
+		}
+
+		ra, sizeA := utf8.DecodeRuneInString(a[i:])
+		rb, sizeB := utf8.DecodeRuneInString(b[j:])
+		if la, lb := unicode.ToLower(ra), unicode.ToLower(rb); la != lb {
+			return la < lb
+		}
+		i += sizeA
+		j += sizeB
+	}
+	if rest := (len(a) - i) - (len(b) - j); rest != 0 {
+		return rest < 0 // A name that is a prefix of the other comes first
+	}
+	return a < b
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// digitRun returns the end of the run of digits starting at s[start].
+func digitRun(s string, start int) int {
+	end := start
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	return end
+}
+
+// compareNumbers compares two runs of digits by value, however long they are.
+func compareNumbers(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	long := strings.Repeat("9", 40) // Far past what fits in an integer
+	tests := []struct {
+		a, b string
+		less bool
+	}{
+		{"file2", "file10", true},
+		{"file10", "file2", false},
+		{"file", "file1", true},
+		{"File2", "file10", true},
+
+		// Leading zeros compare by value, then fall back to byte order
+		{"file007", "file8", true},
+		{"file08", "file7", false},
+		{"07", "7", true},
+		{"7", "07", false},
+		{"a0", "a00", true},
+
+		// Very long runs of digits compare by value
+		{"v" + long, "v1" + long, true},
+		{"v1" + long, "v" + long, false},
+		{long + "8", long + "9", true},
+		{"0000" + long, "1" + long, true},
+
+		// Names of digits alone
+		{"9", "10", true},
+		{"100", "99", false},
+		{"00", "0", false},
+		{"1.2", "1.10", true},
+
+		// Letters ignore case, everything else compares by code point
+		{"apple", "Banana", true},
+		{"Banana", "apple", false},
+		{"File", "file", true},
+		{"Äpfel", "äpfel", true},
+		{"zebra", "Äpfel", true},
+		{"日本2", "日本10", true},
+		{"naïve", "naive", false},
+		{"file-1", "file_1", true},
+		{"x", "x", false},
+	}
+	for _, tt := range tests {
+		if got := NaturalLess(tt.a, tt.b); got != tt.less {
+			t.Errorf("NaturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.less)
+		}
+	}
+}
+
+func TestNaturalLessTotalOrder(t *testing.T) {
+	names := []string{
+		"", "0", "00", "000", "1", "01", "001", "10", "010", "9", "a", "A", "a1", "a01", "A1", "a001b",
+		"a1b", "a10", "a2", "ab", "aB", "b", "file", "File", "file2", "file02", "file10", "File10",
+		"x" + strings.Repeat("0", 30) + "1", "x1", "ä", "Ä", "z", "日本", "日本1", "-", "_", ".hidden",
+	}
+	for _, a := range names {
+		if NaturalLess(a, a) {
+			t.Errorf("%q sorts before itself", a)
+		}
+		for _, b := range names {
+			if a != b && NaturalLess(a, b) == NaturalLess(b, a) {
+				t.Errorf("%q and %q are not ordered one way: %v both ways", a, b, NaturalLess(a, b))
+			}
+			for _, c := range names {
+				if NaturalLess(a, b) && NaturalLess(b, c) && !NaturalLess(a, c) {
+					t.Errorf("%q < %q < %q, but not %q < %q", a, b, c, a, c)
+				}
+			}
+		}
+	}
+
+	// Any starting order sorts the same
+	sorted := slices.Clone(names)
+	slices.SortFunc(sorted, naturalCompare)
+	reversed := slices.Clone(names)
+	slices.Reverse(reversed)
+	slices.SortFunc(reversed, naturalCompare)
+	if !slices.Equal(sorted, reversed) {
+		t.Errorf("sorting depends on the starting order:\n%q\n%q", sorted, reversed)
+	}
+}
+
+// naturalCompare orders names by NaturalLess, for slices.SortFunc.
+func naturalCompare(a, b string) int {
+	switch {
+	case NaturalLess(a, b):
+		return -1
+	case NaturalLess(b, a):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,202 @@
+package scanner
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// dirTask is a directory waiting to be read by a parallel scan.
+type dirTask struct {
+	node     *TreeNode
+	realPath string
+	depth    int
+	scopes   []attrScope // .gitattributes files of the directories above node
+}
+
+// dirQueue hands directories to the workers of a parallel scan. Only reading a directory queues
+// new ones, so the queue is drained once it is empty and no task is being read.
+type dirQueue struct {
+	mu      sync.Mutex
+	ready   *sync.Cond
+	tasks   []dirTask
+	pending int // Tasks queued or being read
+}
+
+// newDirQueue creates an empty queue.
+func newDirQueue() *dirQueue {
+	q := &dirQueue{}
+	q.ready = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues a task.
+func (q *dirQueue) push(task dirTask) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.tasks = append(q.tasks, task)
+	q.pending++
+	q.ready.Signal()
+}
+
+// pop takes the most recently queued task, which keeps the queue short by finishing deep
+// branches first. It waits while other workers may still queue tasks, returning false once
+// the queue is drained.
+func (q *dirQueue) pop() (dirTask, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.tasks) == 0 && q.pending > 0 {
+		q.ready.Wait()
+	}
+	if len(q.tasks) == 0 {
+		return dirTask{}, false
+	}
+	task := q.tasks[len(q.tasks)-1]
+	q.tasks = q.tasks[:len(q.tasks)-1]
+	return task, true
+}
+
+// done marks a popped task as read.
+func (q *dirQueue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending--
+	if q.pending == 0 {
+		q.ready.Broadcast()
+	}
+}
+
+// scanParallel scans the tree below root with workers reading directories concurrently, each
+// on a locked thread of its own as in onScanThread. A directory's children are added in sorted
+// order by the worker that read it, so the tree matches a sequential scan; only which path is
+// read of a directory reached twice can differ. Sizes are summed once every directory is read.
+func (s *FileTreeScanner) scanParallel(ctx context.Context, state *scanState, root *TreeNode, realPath string, workers int) (int, error) {
+	queue := newDirQueue()
+	queue.push(dirTask{node: root, realPath: realPath})
+
+	var (
+		mu        sync.Mutex
+		nodeCount int
+		abandoned []*TreeNode
+		panicked  any
+		wg        sync.WaitGroup
+	)
+	// A worker whose lowered thread cannot be raised again exits, taking its locked thread with
+	// it, and a new worker on a fresh thread takes its place
+	var worker func()
+	worker = func() {
+		defer wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				mu.Lock()
+				panicked = r
+				mu.Unlock()
+			}
+		}()
+		runtime.LockOSThread()
+
+		thread := threadPriority{replaceable: true}
+		for {
+			if !s.restorePriority(&thread) {
+				wg.Add(1)
+				go worker()
+				return
+			}
+			task, ok := queue.pop()
+			if !ok {
+				return
+			}
+			func() {
+				defer queue.done()
+				count, stopped := s.scanTask(ctx, state, &thread, queue, task)
+				mu.Lock()
+				nodeCount += count
+				if stopped {
+					abandoned = append(abandoned, task.node)
+				}
+				mu.Unlock()
+			}()
+		}
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go worker()
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+
+	// Directories left by cancellation leave their ancestors incomplete too
+	if s.config.ShowSize {
+		for _, node := range abandoned {
+			for parent := node.Parent; parent != nil; parent = parent.Parent {
+				parent.SizeUnknown = true
+			}
+		}
+		sumTreeSizes(root)
+	}
+
+	// Workers finish in no particular order
+	sort.Slice(state.errors, func(i, j int) bool { return state.errors[i].Path < state.errors[j].Path })
+	sort.Strings(state.truncatedDirs)
+	return nodeCount, ctx.Err()
+}
+
+// scanTask reads one directory of a parallel scan, queueing its subdirectories. It returns the
+// nodes it adds to the count and whether cancellation left the directory incomplete.
+func (s *FileTreeScanner) scanTask(ctx context.Context, state *scanState, thread *threadPriority, queue *dirQueue, task dirTask) (int, bool) {
+	node := task.node
+	listing, count, err := s.openDir(ctx, state, thread, node, task.realPath, task.depth, task.scopes)
+	if listing == nil {
+		return count, err != nil
+	}
+
+	count = 1 // Count current node
+	for i, entry := range listing.entries {
+		if ctx.Err() != nil {
+			s.abandon(ctx, node)
+			return count, true
+		}
+		if state.isStopped() {
+			s.cutShort(state, node, len(listing.entries)-i)
+			break
+		}
+
+		// Brief pause every 100 entries to allow cancellation
+		if i > 0 && i%100 == 0 {
+			time.Sleep(1 * time.Millisecond)
+			if err := s.gate.wait(ctx); err != nil {
+				s.abandon(ctx, node)
+				return count, true
+			}
+		}
+
+		child, childRealPath := s.addChild(state, listing, node, entry, task.realPath, task.depth)
+		if child.IsDir {
+			queue.push(dirTask{node: child, realPath: childRealPath, depth: task.depth + 1, scopes: listing.scopes})
+		} else {
+			if s.config.ComputeHashes {
+				s.hashFile(ctx, state, child)
+			}
+			if s.config.CountLines || s.config.EstimateTokens {
+				s.measureText(ctx, state, child)
+			}
+			count++
+		}
+	}
+	return count, false
+}
+
+// sumTreeSizes sets the sizes of every directory below and including node to the total of its
+// children's, deepest first.
+func sumTreeSizes(node *TreeNode) {
+	for _, child := range node.Children {
+		if child.IsDir {
+			sumTreeSizes(child)
+		}
+	}
+	sumSizes(node)
+}
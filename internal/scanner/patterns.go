@@ -0,0 +1,213 @@
+package scanner
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Skip statistics keys for the pattern filters.
+const (
+	ExcludePatternRule = "exclude-pattern" // Entries matched by Config.ExcludePatterns
+	IncludePatternRule = "include-pattern" // Entries pruned for matching no Config.IncludePatterns
+)
+
+// globPattern is one compiled entry of Config.ExcludePatterns or Config.IncludePatterns.
+type globPattern struct {
+	text    string
+	pattern *regexp.Regexp
+	dirOnly bool   // Pattern ended in a slash or "/**"
+	source  string // "file:line" the pattern was read from; "" for configured patterns
+}
+
+// excludePatternRule excludes entries matched by Config.ExcludePatterns, relative to the scan root.
+// An excluded directory is not read, so its whole subtree is left out.
+type excludePatternRule struct {
+	root     string
+	patterns []globPattern
+	name     string // Rule name; ExcludePatternRule unless the patterns come from a file
+}
+
+// ParsePatterns splits a comma-separated pattern list, dropping blank items.
+func ParsePatterns(text string) []string {
+	var patterns []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			patterns = append(patterns, item)
+		}
+	}
+	return patterns
+}
+
+// ValidatePatterns returns an error describing the first pattern that cannot be used.
+func ValidatePatterns(patterns []string) error {
+	for _, text := range patterns {
+		if _, err := compileGlobPattern(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileGlobPattern compiles a doublestar-style pattern. Patterns without a slash match a
+// name at any depth, others are anchored at the scan root; "**" spans directories, and a
+// trailing "/**" also matches the directory itself.
+func compileGlobPattern(text string) (globPattern, error) {
+	p := globPattern{text: text}
+	pattern := filepath.ToSlash(strings.TrimSpace(text))
+	if pattern == "" {
+		return p, fmt.Errorf("empty pattern")
+	}
+	if strings.HasPrefix(pattern, "!") {
+		return p, fmt.Errorf("invalid pattern %q: negation is not supported", text)
+	}
+	if strings.Count(pattern, "[") != strings.Count(pattern, "]") {
+		return p, fmt.Errorf("invalid pattern %q: unbalanced brackets", text)
+	}
+
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	switch {
+	case pattern == "**" || pattern == "**/":
+		return p, fmt.Errorf("invalid pattern %q: it matches everything", text)
+	case strings.HasSuffix(pattern, "/**"):
+		p.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/**")
+	case strings.HasSuffix(pattern, "/"):
+		p.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if anchored && !strings.HasPrefix(pattern, "/") {
+		pattern = "/" + pattern
+	}
+
+	re, err := regexp.Compile(gitPatternExpr(pattern))
+	if err != nil {
+		return p, fmt.Errorf("invalid pattern %q: %w", text, err)
+	}
+	p.pattern = re
+	return p, nil
+}
+
+// newExcludePatternRule returns the rule for patterns below root, or nil when there are none.
+// Invalid patterns are skipped with a warning; scans validate them up front.
+func newExcludePatternRule(root string, patterns []string) FilterRule {
+	rule := &excludePatternRule{root: filepath.Clean(root), name: ExcludePatternRule}
+	for _, text := range patterns {
+		p, err := compileGlobPattern(text)
+		if err != nil {
+			log.Printf("Warning: skipping %v", err)
+			continue
+		}
+		rule.patterns = append(rule.patterns, p)
+	}
+	if len(rule.patterns) == 0 {
+		return nil
+	}
+	return rule
+}
+
+// Name implements FilterRule.
+func (r *excludePatternRule) Name() string { return r.name }
+
+// Match implements FilterRule.
+func (r *excludePatternRule) Match(entry EntryInfo) (RuleMatch, bool) {
+	rel, ok := relSlash(r.root, entry.Path)
+	if !ok {
+		return RuleMatch{}, false
+	}
+	if p, ok := matchPattern(r.patterns, rel, entry.IsDir); ok {
+		return RuleMatch{Rule: r.Name(), Pattern: p.text, Source: p.source}, true
+	}
+	return RuleMatch{}, false
+}
+
+// includeFilter limits a scanned tree to the files matched by Config.IncludePatterns. Files
+// below a directory matched by a directory pattern ("docs/", "docs/**") are all kept.
+type includeFilter struct {
+	root     string
+	patterns []globPattern
+}
+
+// newIncludeFilter returns the filter for patterns below root, or nil when there are none.
+func newIncludeFilter(root string, patterns []string) *includeFilter {
+	filter := &includeFilter{root: filepath.Clean(root)}
+	for _, text := range patterns {
+		p, err := compileGlobPattern(text)
+		if err != nil {
+			log.Printf("Warning: skipping %v", err)
+			continue
+		}
+		filter.patterns = append(filter.patterns, p)
+	}
+	if len(filter.patterns) == 0 {
+		return nil
+	}
+	return filter
+}
+
+// prune removes the files below node that no pattern includes and then every directory left
+// empty, returning the number of directories and files removed. Directory sizes are summed
+// again when sizes is set.
+func (f *includeFilter) prune(node *TreeNode, included, sizes bool) (dirs, files int) {
+	kept := node.Children[:0]
+	for _, child := range node.Children {
+		rel, _ := relSlash(f.root, child.Path)
+		_, matched := matchPattern(f.patterns, rel, child.IsDir)
+		if child.IsDir {
+			childDirs, childFiles := f.prune(child, included || matched, sizes)
+			dirs, files = dirs+childDirs, files+childFiles
+			if len(child.Children) == 0 {
+				dirs++
+				continue
+			}
+		} else if !included && !matched {
+			files++
+			continue
+		}
+		kept = append(kept, child)
+	}
+	clear(node.Children[len(kept):])
+	node.Children = kept
+	if sizes {
+		sumSizes(node)
+	}
+	return dirs, files
+}
+
+// includes reports whether prune keeps the file at path: a pattern matches it or a directory
+// above it.
+func (f *includeFilter) includes(path string) bool {
+	for dir, isDir := path, false; ; dir, isDir = filepath.Dir(dir), true {
+		rel, ok := relSlash(f.root, dir)
+		if !ok {
+			return false
+		}
+		if _, matched := matchPattern(f.patterns, rel, isDir); matched {
+			return true
+		}
+	}
+}
+
+// matchPattern returns the first pattern matching a slash-separated path relative to the root.
+func matchPattern(patterns []globPattern, rel string, isDir bool) (globPattern, bool) {
+	for _, p := range patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.pattern.MatchString(rel) {
+			return p, true
+		}
+	}
+	return globPattern{}, false
+}
+
+// relSlash returns path relative to root with forward slashes, or false when path is not below root.
+func relSlash(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
@@ -0,0 +1,120 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PausableScanner is implemented by scanners that can suspend a scan in progress.
+type PausableScanner interface {
+	FileSystemScanner
+	Pause()
+	Resume()
+	Paused() bool
+}
+
+// pauseGate blocks the scan loop while paused. The zero value is an open gate.
+type pauseGate struct {
+	mu       sync.Mutex
+	closed   chan struct{} // non-nil while paused, closed on resume
+	pausedAt time.Time     // When the current pause began
+	total    time.Duration // Time spent in earlier pauses
+}
+
+// pause closes the gate; subsequent waits block until resume.
+func (g *pauseGate) pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed == nil {
+		g.closed = make(chan struct{})
+		g.pausedAt = time.Now()
+	}
+}
+
+// resume opens the gate and releases all waiters.
+func (g *pauseGate) resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed != nil {
+		close(g.closed)
+		g.closed = nil
+		g.total += time.Since(g.pausedAt)
+	}
+}
+
+// paused reports whether the gate is closed.
+func (g *pauseGate) paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.closed != nil
+}
+
+// state returns the channel closed on resume, nil while open, and the time spent in pauses
+// that have ended.
+func (g *pauseGate) state() (chan struct{}, time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.closed, g.total
+}
+
+// wait blocks while the gate is closed, returning early if ctx is cancelled.
+func (g *pauseGate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	closed := g.closed
+	g.mu.Unlock()
+
+	if closed == nil {
+		return nil
+	}
+
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithScanBudget returns a context cancelled with a ReasonTimeout cause once s has been
+// scanning for limit. The clock stops while s is paused, so a long pause does not time the
+// scan out; scanners that cannot pause get a plain timeout.
+func WithScanBudget(ctx context.Context, s FileSystemScanner, limit time.Duration) (context.Context, context.CancelFunc) {
+	cause := Stop(ReasonTimeout, limit.String())
+	fts, ok := s.(*FileTreeScanner)
+	if !ok {
+		return context.WithTimeoutCause(ctx, limit, cause)
+	}
+
+	budgetCtx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		started := time.Now()
+		_, before := fts.gate.state()
+		timer := time.NewTimer(limit)
+		defer timer.Stop()
+		for {
+			select {
+			case <-budgetCtx.Done():
+				return
+			case <-timer.C:
+			}
+			// Pauses that are still going on hold the clock until they end
+			closed, paused := fts.gate.state()
+			for closed != nil {
+				select {
+				case <-budgetCtx.Done():
+					return
+				case <-closed:
+				}
+				closed, paused = fts.gate.state()
+			}
+			left := limit - (time.Since(started) - (paused - before))
+			if left <= 0 {
+				cancel(cause)
+				return
+			}
+			timer.Reset(left)
+		}
+	}()
+	return budgetCtx, func() { cancel(nil) }
+}
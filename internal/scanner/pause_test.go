@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/testtree"
+)
+
+// hookFS is an fs.FS that calls hook before listing each directory.
+type hookFS struct {
+	fs.FS
+	hook func(name string)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (f hookFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.hook(name)
+	return fs.ReadDir(f.FS, name)
+}
+
+// pauseAfter returns a file system over fsys that pauses s once n directories have been listed,
+// closing the returned channel when it does, and the number of directories listed so far.
+func pauseAfter(fsys fs.FS, n int, s func() *FileTreeScanner) (fs.FS, <-chan struct{}, func() int) {
+	var mu sync.Mutex
+	listed := 0
+	paused := make(chan struct{})
+	hooked := hookFS{FS: fsys, hook: func(string) {
+		mu.Lock()
+		defer mu.Unlock()
+		listed++
+		if listed == n {
+			s().Pause()
+			close(paused)
+		}
+	}}
+	return hooked, paused, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return listed
+	}
+}
+
+func TestPauseResumeKeepsEveryDirectory(t *testing.T) {
+	fsys := testtree.Small().MapFS()
+	for _, workers := range []int{1, 5} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			cfg := fixtureConfig()
+			cfg.ConcurrentOps = workers
+			want := scannedPaths(scanFixture(t, cfg, fsys))
+
+			var s *FileTreeScanner
+			hooked, paused, listed := pauseAfter(fsys, 3, func() *FileTreeScanner { return s })
+			s = NewFileTreeScannerFS(cfg, hooked, fixtureRoot)
+
+			type outcome struct {
+				result *ScanResult
+				err    error
+			}
+			done := make(chan outcome, 1)
+			go func() {
+				result, err := s.ScanDirectory(context.Background(), fixtureRoot)
+				done <- outcome{result, err}
+			}()
+
+			<-paused
+			if !s.Paused() {
+				t.Fatal("scanner does not report being paused")
+			}
+			// Listings already under way when the pause came may still finish
+			if workers > 1 {
+				time.Sleep(20 * time.Millisecond)
+			}
+			before := listed()
+			select {
+			case <-done:
+				t.Fatal("scan finished while paused")
+			case <-time.After(50 * time.Millisecond):
+			}
+			if after := listed(); after != before {
+				t.Errorf("%d directories listed while paused", after-before)
+			}
+
+			s.Resume()
+			if s.Paused() {
+				t.Error("scanner still paused after Resume")
+			}
+			got := <-done
+			if got.err != nil {
+				t.Fatal(got.err)
+			}
+			if got.result.Partial || got.result.Truncated {
+				t.Errorf("resumed scan is partial %v, truncated %v", got.result.Partial, got.result.Truncated)
+			}
+			// Directories lost or listed twice show up as a different set of paths
+			if paths := scannedPaths(got.result); !reflect.DeepEqual(paths, want) {
+				t.Errorf("resumed scan found %d entries, unpaused scan %d", len(paths), len(want))
+			}
+		})
+	}
+}
+
+func TestCancelWhilePaused(t *testing.T) {
+	var s *FileTreeScanner
+	hooked, paused, _ := pauseAfter(testtree.Small().MapFS(), 2, func() *FileTreeScanner { return s })
+	s = NewFileTreeScannerFS(fixtureConfig(), hooked, fixtureRoot)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	done := make(chan error, 1)
+	var result *ScanResult
+	go func() {
+		var err error
+		result, err = s.ScanDirectory(ctx, fixtureRoot)
+		done <- err
+	}()
+
+	<-paused
+	cancel(Stop(ReasonUser, ""))
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelled while paused: got %v, want context.Canceled", err)
+		}
+		if result == nil || !result.Partial {
+			t.Error("cancelled scan kept no partial tree")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("scan still waiting a second after being cancelled while paused")
+	}
+	s.Resume()
+}
+
+func TestScanBudgetStopsWhilePaused(t *testing.T) {
+	s := NewFileTreeScannerFS(fixtureConfig(), testtree.Small().MapFS(), fixtureRoot)
+	s.Pause()
+
+	ctx, stop := WithScanBudget(context.Background(), s, 20*time.Millisecond)
+	defer stop()
+	select {
+	case <-ctx.Done():
+		t.Fatal("budget ran out while paused")
+	case <-time.After(80 * time.Millisecond):
+	}
+
+	s.Resume()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("budget did not run out after resuming")
+	}
+	if cause := StopCause(ctx); cause == nil || cause.Reason != ReasonTimeout || cause.Limit != "20ms" {
+		t.Errorf("budget stopped with %v, want a 20ms timeout", cause)
+	}
+}
+
+func TestScanBudgetIgnoresEarlierPauses(t *testing.T) {
+	s := NewFileTreeScannerFS(fixtureConfig(), testtree.Small().MapFS(), fixtureRoot)
+	s.Pause()
+	time.Sleep(30 * time.Millisecond)
+	s.Resume()
+
+	// A pause before the budget started leaves none of it spent
+	started := time.Now()
+	ctx, stop := WithScanBudget(context.Background(), s, 20*time.Millisecond)
+	defer stop()
+	<-ctx.Done()
+	if elapsed := time.Since(started); elapsed < 20*time.Millisecond {
+		t.Errorf("budget of 20ms ran out after %s", elapsed)
+	}
+}
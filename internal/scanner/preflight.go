@@ -0,0 +1,88 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// Preflight is a quick look at a directory before the full scan.
+type Preflight struct {
+	Entries  []EntryInfo // Immediate children that pass the filters, directories first
+	Estimate int         // Entries within two levels; a lower bound on the full scan
+}
+
+// PreflightScanner is implemented by scanners that can look at a directory before scanning it.
+type PreflightScanner interface {
+	FileSystemScanner
+	Preflight(ctx context.Context, path string) (*Preflight, error)
+}
+
+// Preflight lists the filtered children of path and counts their own children,
+// reading at most one directory per child.
+func (s *FileTreeScanner) Preflight(ctx context.Context, path string) (*Preflight, error) {
+	entries, err := s.files.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %q: %w", path, err)
+	}
+
+	pipeline := filterPipeline(s.Filters(path))
+	entries = s.filterEntries(pipeline, make(SkipStats), path, entries)
+	s.sortEntries(path, entries)
+
+	preflight := &Preflight{Estimate: len(entries)}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		info := EntryInfo{Path: filepath.Join(path, entry.Name()), Name: entry.Name(), IsDir: entry.IsDir()}
+		preflight.Entries = append(preflight.Entries, info)
+		if info.IsDir {
+			if children, err := s.files.ReadDir(info.Path); err == nil {
+				preflight.Estimate += len(children)
+			}
+		}
+	}
+	return preflight, nil
+}
+
+// excludedNamesKey is the context key for WithExcludedNames.
+type excludedNamesKey struct{}
+
+// WithExcludedNames returns a context whose scans leave out the named entries of the scanned
+// directory itself. Deeper entries with the same names are kept.
+func WithExcludedNames(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, excludedNamesKey{}, names)
+}
+
+// excludedNamesRule excludes the entries of root chosen before the scan.
+type excludedNamesRule struct {
+	root  string
+	names map[string]bool
+}
+
+// newExcludedNamesRule returns the rule for the names in ctx, or nil when there are none.
+func newExcludedNamesRule(ctx context.Context, root string) FilterRule {
+	names, _ := ctx.Value(excludedNamesKey{}).([]string)
+	if len(names) == 0 {
+		return nil
+	}
+	rule := excludedNamesRule{root: filepath.Clean(root), names: make(map[string]bool)}
+	for _, name := range names {
+		rule.names[name] = true
+	}
+	return rule
+}
+
+// Name implements FilterRule.
+func (r excludedNamesRule) Name() string {
+	return "pre-scan"
+}
+
+// Match implements FilterRule.
+func (r excludedNamesRule) Match(entry EntryInfo) (RuleMatch, bool) {
+	if !r.names[entry.Name] || filepath.Dir(entry.Path) != r.root {
+		return RuleMatch{}, false
+	}
+	return RuleMatch{Rule: r.Name(), Pattern: entry.Name}, true
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"log"
+	"runtime"
+	"time"
+)
+
+// PriorityScanner is implemented by scanners that can read at background priority.
+// The priority can change while a scan runs and applies from the next directory on.
+type PriorityScanner interface {
+	FileSystemScanner
+	SetBackgroundPriority(background bool)
+	BackgroundPriority() bool
+}
+
+// backgroundYield is the pause before each directory at background priority where the
+// platform cannot lower the thread's priority itself.
+const backgroundYield = 2 * time.Millisecond
+
+// SetBackgroundPriority lowers or restores the priority of scans, including the one running.
+func (s *FileTreeScanner) SetBackgroundPriority(background bool) {
+	s.background.Store(background)
+}
+
+// BackgroundPriority reports whether scans read at background priority.
+func (s *FileTreeScanner) BackgroundPriority() bool {
+	return s.background.Load()
+}
+
+// threadPriority tracks the priority of one scanning thread.
+type threadPriority struct {
+	lowered     bool // The thread currently runs at background priority
+	failed      bool // The platform refused a change; no further changes are tried
+	replaceable bool // A worker of a parallel scan, replaced by a fresh thread rather than raised again; see restorePriority
+}
+
+// adjustPriority brings the scanning thread to the requested priority before a directory is read.
+func (s *FileTreeScanner) adjustPriority(state *scanState, thread *threadPriority) {
+	background := s.background.Load()
+	if background {
+		state.mu.Lock()
+		state.background = true
+		state.mu.Unlock()
+	}
+	if background != thread.lowered && !thread.failed && (background || !thread.replaceable) {
+		if err := s.setPriority(background); err != nil {
+			log.Printf("Warning: %v", err)
+			thread.failed = true
+			if !background {
+				state.mu.Lock()
+				state.kept = true
+				state.mu.Unlock()
+			}
+		} else {
+			thread.lowered = background
+		}
+	}
+	if background && !thread.lowered {
+		time.Sleep(backgroundYield)
+	}
+}
+
+// restorePriority raises a lowered thread back to normal priority once background priority is
+// turned off, reporting false when the platform refuses. Linux lets only privileged processes
+// raise a thread's priority again, so parallel workers then hand over to a fresh thread.
+func (s *FileTreeScanner) restorePriority(thread *threadPriority) bool {
+	if !thread.lowered || s.background.Load() {
+		return true
+	}
+	if err := s.setPriority(false); err != nil {
+		return false
+	}
+	thread.lowered = false
+	return true
+}
+
+// onScanThread runs scan on a locked thread of its own. Priorities are set per thread, and
+// the thread is never unlocked, so it exits with the scan rather than carrying a lowered
+// priority back into the pool. Panics are re-raised on the calling goroutine.
+func onScanThread(scan func()) {
+	var panicked any
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { panicked = recover() }()
+		runtime.LockOSThread()
+		scan()
+	}()
+	<-done
+	if panicked != nil {
+		panic(panicked)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package scanner
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.BackgroundPriority)
+}
+
+// ioprio_set arguments; see ioprio_set(2).
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	ioprioClassNone  = 0 // Derive the I/O priority from the nice value
+	ioprioClassIdle  = 3 // Only use the disk when no one else does
+)
+
+// backgroundNice is the nice value of a scanning thread at background priority.
+const backgroundNice = 19
+
+// setThreadBackground moves the calling thread to the idle I/O class and the lowest CPU
+// priority, or back to the process's own. Going back needs CAP_SYS_NICE or a high enough
+// RLIMIT_NICE, which ordinary users lack; the I/O class is restored even then.
+func setThreadBackground(background bool) error {
+	tid := syscall.Gettid()
+	nice, class := backgroundNice, ioprioClassIdle
+	if !background {
+		// The raw syscall returns 20 minus the nice value
+		prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, syscall.Getpid())
+		if err != nil {
+			return fmt.Errorf("failed to read process priority: %w", err)
+		}
+		nice, class = 20-prio, ioprioClassNone
+	}
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(class<<ioprioClassShift)); errno != 0 {
+		return fmt.Errorf("failed to set scan I/O priority: %w", errno)
+	}
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, nice); err != nil {
+		return fmt.Errorf("failed to set scan thread priority: %w", err)
+	}
+	return nil
+}
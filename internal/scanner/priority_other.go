@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package scanner
+
+import "errors"
+
+// setThreadBackground is not available on this platform; background scans yield between directories instead.
+func setThreadBackground(background bool) error {
+	if !background {
+		return nil
+	}
+	return errors.New("background priority is not supported on this platform, yielding between directories instead")
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/testtree"
+)
+
+// fakePriority stands in for setThreadBackground, counting the threads lowered and the
+// attempts to raise one again, which it refuses like Linux does for ordinary users.
+type fakePriority struct {
+	mu      sync.Mutex
+	lowered int
+	raises  int
+	refuse  bool
+}
+
+func (f *fakePriority) set(background bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if background {
+		f.lowered++
+		return nil
+	}
+	f.raises++
+	if f.refuse {
+		return errors.New("failed to set scan thread priority: operation not permitted")
+	}
+	return nil
+}
+
+func TestBackgroundTurnedOffMidScan(t *testing.T) {
+	fsys := testtree.Small().MapFS()
+	tests := []struct {
+		workers int
+		refuse  bool
+		kept    bool // BackgroundKept
+	}{
+		{workers: 1, refuse: false, kept: false},
+		{workers: 1, refuse: true, kept: true}, // A sequential scan cannot change threads
+		{workers: 5, refuse: false, kept: false},
+		{workers: 5, refuse: true, kept: false}, // Workers hand over to fresh threads
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d workers, refused %v", tt.workers, tt.refuse), func(t *testing.T) {
+			cfg := fixtureConfig()
+			cfg.ConcurrentOps = tt.workers
+			want := scannedPaths(scanFixture(t, cfg, fsys))
+
+			cfg.BackgroundPriority = true
+			var s *FileTreeScanner
+			var turnOff sync.Once
+			var listed atomic.Int32
+			hooked := hookFS{FS: fsys, hook: func(string) {
+				if listed.Add(1) >= 3 {
+					turnOff.Do(func() { s.SetBackgroundPriority(false) })
+				}
+			}}
+			fake := &fakePriority{refuse: tt.refuse}
+			s = NewFileTreeScannerFS(cfg, hooked, fixtureRoot)
+			s.setPriority = fake.set
+
+			result, err := s.ScanDirectory(context.Background(), fixtureRoot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Background {
+				t.Error("scan not marked as read at background priority")
+			}
+			if result.BackgroundKept != tt.kept {
+				t.Errorf("BackgroundKept %v, want %v", result.BackgroundKept, tt.kept)
+			}
+			if fake.lowered == 0 || fake.raises == 0 {
+				t.Errorf("%d threads lowered and %d raised, want some of each", fake.lowered, fake.raises)
+			}
+			if tt.workers == 1 && fake.raises != 1 {
+				t.Errorf("refused thread raised %d times, want once", fake.raises)
+			}
+			if paths := scannedPaths(result); !reflect.DeepEqual(paths, want) {
+				t.Errorf("scan found %d entries, want %d", len(paths), len(want))
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+//go:build windows
+
+package scanner
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.BackgroundPriority)
+}
+
+// SetThreadPriority modes that lower CPU, I/O and memory priority together.
+const (
+	threadModeBackgroundBegin = 0x00010000
+	threadModeBackgroundEnd   = 0x00020000
+)
+
+var procSetThreadPriority = windows.NewLazySystemDLL("kernel32.dll").NewProc("SetThreadPriority")
+
+// setThreadBackground enters or leaves THREAD_MODE_BACKGROUND for the calling thread.
+func setThreadBackground(background bool) error {
+	mode := uintptr(threadModeBackgroundEnd)
+	if background {
+		mode = threadModeBackgroundBegin
+	}
+	if ok, _, err := procSetThreadPriority.Call(uintptr(windows.CurrentThread()), mode); ok == 0 {
+		return fmt.Errorf("failed to set scan thread priority: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"context"
+	"time"
+)
+
+// progressInterval is the minimum time between two progress reports.
+const progressInterval = 100 * time.Millisecond
+
+// ScanProgress is a snapshot of a running scan.
+type ScanProgress struct {
+	Items   int           // Nodes discovered so far
+	Current string        // Directory being read
+	Elapsed time.Duration // Time since the scan started
+	Done    bool          // Final report, sent once when the scan ends
+}
+
+// ProgressFunc receives progress reports on a scanning goroutine, one at a time; it must return quickly.
+type ProgressFunc func(ScanProgress)
+
+// ProgressScanner is implemented by scanners that can report progress while scanning.
+type ProgressScanner interface {
+	FileSystemScanner
+	ScanDirectoryWithProgress(ctx context.Context, path string, progress ProgressFunc) (*ScanResult, error)
+	ScanDirectoriesWithProgress(ctx context.Context, paths []string, progress ProgressFunc) (*ScanResult, error)
+}
+
+// progressTracker throttles progress reports for a single scan.
+type progressTracker struct {
+	report     ProgressFunc
+	items      int
+	started    time.Time
+	lastReport time.Time
+}
+
+// newProgressTracker creates a tracker; report may be nil.
+func newProgressTracker(report ProgressFunc) *progressTracker {
+	return &progressTracker{report: report, started: time.Now()}
+}
+
+// add counts n newly discovered nodes.
+func (p *progressTracker) add(n int) {
+	p.items += n
+}
+
+// tick reports progress if the last report is older than progressInterval.
+func (p *progressTracker) tick(current string) {
+	if p.report == nil {
+		return
+	}
+	now := time.Now()
+	if now.Sub(p.lastReport) < progressInterval {
+		return
+	}
+	p.lastReport = now
+	p.report(ScanProgress{Items: p.items, Current: current, Elapsed: now.Sub(p.started)})
+}
+
+// finish sends the final report.
+func (p *progressTracker) finish() {
+	if p.report == nil {
+		return
+	}
+	p.report(ScanProgress{Items: p.items, Elapsed: time.Since(p.started), Done: true})
+}
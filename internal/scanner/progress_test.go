@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/testtree"
+)
+
+func TestProgressTrackerThrottles(t *testing.T) {
+	var reports []ScanProgress
+	tracker := newProgressTracker(func(p ScanProgress) { reports = append(reports, p) })
+	for i := 0; i < 100; i++ {
+		tracker.add(1)
+		tracker.tick("dir")
+	}
+	tracker.finish()
+
+	// The first tick reports at once; the rest fall within progressInterval of it
+	if len(reports) != 2 {
+		t.Fatalf("%d reports, want the first tick and the final one", len(reports))
+	}
+	if reports[0].Items != 1 || reports[0].Current != "dir" || reports[0].Done {
+		t.Errorf("first report %+v", reports[0])
+	}
+	if final := reports[1]; final.Items != 100 || !final.Done || final.Current != "" {
+		t.Errorf("final report %+v, want 100 items and Done", final)
+	}
+}
+
+func TestProgressReports(t *testing.T) {
+	spec := testtree.Small()
+	for _, tt := range []struct {
+		name      string
+		cancelled bool
+	}{{"completed", false}, {"cancelled", true}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			var reports []ScanProgress
+			s := NewFileTreeScannerFS(fixtureConfig(), spec.FS(), fixtureRoot)
+			result, _ := s.ScanDirectoryWithProgress(ctx, fixtureRoot, func(p ScanProgress) {
+				reports = append(reports, p)
+			})
+
+			// Exactly one final report, sent last, even when the scan is cancelled
+			if len(reports) == 0 || !reports[len(reports)-1].Done {
+				t.Fatalf("reports %+v do not end with the final one", reports)
+			}
+			for _, p := range reports[:len(reports)-1] {
+				if p.Done {
+					t.Errorf("final report sent before the end: %+v", p)
+				}
+			}
+			if !tt.cancelled {
+				if final := reports[len(reports)-1]; final.Items != result.NodeCount {
+					t.Errorf("final report counts %d items, want the %d nodes scanned", final.Items, result.NodeCount)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package scanner
+
+// EmptyDirRule is the skip statistics key for directories removed by Config.PruneEmptyDirs.
+const EmptyDirRule = "empty-directory"
+
+// PruneEmpty removes every directory below root that holds nothing, deepest first, so a chain
+// of folders left empty by filtering disappears entirely, and returns the number removed.
+// Directories whose contents are not known, being unreadable or cut short by a limit, are kept,
+// as is root itself. It lets a shown tree drop its empty folders without scanning it again.
+func PruneEmpty(root *TreeNode) int {
+	if root == nil {
+		return 0
+	}
+	removed := 0
+	kept := root.Children[:0]
+	for _, child := range root.Children {
+		if child.IsDir {
+			removed += PruneEmpty(child)
+			if len(child.Children) == 0 && contentsKnown(child) {
+				removed++
+				continue
+			}
+		}
+		kept = append(kept, child)
+	}
+	clear(root.Children[len(kept):])
+	root.Children = kept
+	return removed
+}
+
+// contentsKnown reports whether every entry of the directory node was read.
+func contentsKnown(node *TreeNode) bool {
+	return !node.Unreadable && !node.Truncated && !node.NotRead && node.Omitted == 0
+}
@@ -0,0 +1,87 @@
+package scanner
+
+import (
+	"slices"
+	"testing"
+)
+
+// pruneTree returns a directory named name holding children, with their parents set.
+func pruneTree(name string, children ...*TreeNode) *TreeNode {
+	dir := &TreeNode{Name: name, IsDir: true, Children: children}
+	for _, child := range children {
+		child.Parent = dir
+	}
+	return dir
+}
+
+// treeNames returns the names below node in tree order, directories ending in "/".
+func treeNames(node *TreeNode) []string {
+	var names []string
+	for _, child := range node.Children {
+		if child.IsDir {
+			names = append(names, child.Name+"/")
+			names = append(names, treeNames(child)...)
+		} else {
+			names = append(names, child.Name)
+		}
+	}
+	return names
+}
+
+func TestPruneEmptyChains(t *testing.T) {
+	root := pruneTree("root",
+		pruneTree("a", pruneTree("b", pruneTree("c"))), // A chain left empty by filtering
+		pruneTree("src", pruneTree("empty"), &TreeNode{Name: "main.go"}, pruneTree("gen", pruneTree("out"))),
+		&TreeNode{Name: "README.md"},
+		pruneTree("z"),
+	)
+	if removed := PruneEmpty(root); removed != 7 {
+		t.Errorf("removed %d directories, want 7", removed)
+	}
+	if got, want := treeNames(root), []string{"src/", "main.go", "README.md"}; !slices.Equal(got, want) {
+		t.Errorf("kept %q, want %q", got, want)
+	}
+}
+
+func TestPruneEmptyKeepsNonEmpty(t *testing.T) {
+	root := pruneTree("root",
+		pruneTree("src", &TreeNode{Name: "main.go"}, pruneTree("lib", &TreeNode{Name: "util.go"})),
+		&TreeNode{Name: "go.mod"},
+	)
+	before := treeNames(root)
+	if removed := PruneEmpty(root); removed != 0 {
+		t.Errorf("removed %d directories from a tree without empty ones", removed)
+	}
+	if after := treeNames(root); !slices.Equal(after, before) {
+		t.Errorf("tree changed from %q to %q", before, after)
+	}
+}
+
+func TestPruneEmptyKeepsUnknownContents(t *testing.T) {
+	root := pruneTree("root",
+		&TreeNode{Name: "locked", IsDir: true, Unreadable: true},
+		&TreeNode{Name: "deep", IsDir: true, Truncated: true},
+		&TreeNode{Name: "mnt", IsDir: true, NotRead: true},
+		&TreeNode{Name: "big", IsDir: true, Omitted: 3},
+		pruneTree("holder", &TreeNode{Name: "locked", IsDir: true, Unreadable: true}),
+	)
+	if removed := PruneEmpty(root); removed != 0 {
+		t.Errorf("removed %d directories whose contents are unknown", removed)
+	}
+	if got := len(root.Children); got != 5 {
+		t.Errorf("kept %d directories, want 5", got)
+	}
+}
+
+func TestPruneEmptyRoot(t *testing.T) {
+	root := pruneTree("root", pruneTree("empty"))
+	if removed := PruneEmpty(root); removed != 1 || len(root.Children) != 0 {
+		t.Errorf("removed %d and kept %d children, want the one removed", removed, len(root.Children))
+	}
+	if removed := PruneEmpty(root); removed != 0 {
+		t.Errorf("an empty root was counted as removed %d times", removed)
+	}
+	if removed := PruneEmpty(nil); removed != 0 {
+		t.Errorf("a nil root removed %d", removed)
+	}
+}
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VerifyRelocation checks that dir plausibly is the scanned root after a move:
+// it must be a directory containing every top-level entry of root with the same type.
+func VerifyRelocation(root *TreeNode, dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to stat %q: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path %q is not a directory", dir)
+	}
+
+	for _, child := range root.Children {
+		childInfo, err := os.Lstat(filepath.Join(dir, child.Name))
+		if err != nil {
+			return fmt.Errorf("%q does not look like the scanned folder: %s is missing", dir, child.Name)
+		}
+		if childInfo.IsDir() != child.IsDir {
+			return fmt.Errorf("%q does not look like the scanned folder: %s changed type", dir, child.Name)
+		}
+	}
+	return nil
+}
+
+// RebaseTree rewrites the path of every node below root, replacing root's path prefix with newRoot.
+func RebaseTree(root *TreeNode, newRoot string) {
+	oldRoot := root.Path
+	var rebase func(node *TreeNode)
+	rebase = func(node *TreeNode) {
+		node.Path = rebasePath(node.Path, oldRoot, newRoot)
+		node.realPath = "" // Resolved at the old location
+		for _, child := range node.Children {
+			rebase(child)
+		}
+	}
+	rebase(root)
+	root.Name = filepath.Base(newRoot)
+}
+
+// RebaseResult moves result to newRoot: its tree, root path and the paths of its errors and
+// truncated directories.
+func RebaseResult(result *ScanResult, newRoot string) {
+	oldRoot := result.RootPath
+	if result.Root != nil {
+		RebaseTree(result.Root, newRoot)
+	}
+	result.RootPath = newRoot
+	for i := range result.Errors {
+		result.Errors[i].Path = rebasePath(result.Errors[i].Path, oldRoot, newRoot)
+	}
+	for i, dir := range result.TruncatedDirs {
+		result.TruncatedDirs[i] = rebasePath(dir, oldRoot, newRoot)
+	}
+}
+
+// rebasePath replaces the oldRoot prefix of path with newRoot. Paths outside oldRoot, such as
+// a sibling sharing its name as a prefix, are returned unchanged.
+func rebasePath(path, oldRoot, newRoot string) string {
+	if path == oldRoot {
+		return newRoot
+	}
+	prefix := oldRoot
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	if rest, ok := strings.CutPrefix(path, prefix); ok {
+		return filepath.Join(newRoot, rest)
+	}
+	return path
+}
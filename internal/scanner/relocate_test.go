@@ -0,0 +1,157 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/testtree"
+)
+
+// scanMoved materializes spec in a temporary folder, scans it with at most limit entries per
+// directory and moves the folder, returning the scan and the folder's new path.
+func scanMoved(t *testing.T, spec testtree.Spec, limit int) (*ScanResult, string) {
+	t.Helper()
+	dir := t.TempDir()
+	oldRoot, newRoot := filepath.Join(dir, "project"), filepath.Join(dir, "moved", "project-2")
+	if err := spec.Materialize(oldRoot); err != nil {
+		t.Fatal(err)
+	}
+	cfg := fixtureConfig()
+	cfg.MaxEntriesPerDir = limit
+	result, err := NewFileTreeScanner(cfg).ScanDirectory(context.Background(), oldRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(newRoot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(oldRoot, newRoot); err != nil {
+		t.Fatal(err)
+	}
+	return result, newRoot
+}
+
+func TestRebaseResult(t *testing.T) {
+	spec := testtree.Small()
+	result, newRoot := scanMoved(t, spec, 3)
+	oldRoot := result.RootPath
+	if len(result.TruncatedDirs) == 0 {
+		t.Fatal("fixture scan truncated no directory")
+	}
+	result.Errors = append(result.Errors,
+		ScanError{Path: filepath.Join(oldRoot, "dir_000"), Op: ScanOpRead},
+		ScanError{Path: oldRoot + "-backup", Op: ScanOpRead})
+
+	if err := VerifyRelocation(result.Root, newRoot); err != nil {
+		t.Fatalf("the moved folder was not recognized: %v", err)
+	}
+	RebaseResult(result, newRoot)
+
+	if result.RootPath != newRoot || result.Root.Path != newRoot || result.Root.Name != "project-2" {
+		t.Errorf("root %q (%q, named %q), want %q", result.RootPath, result.Root.Path, result.Root.Name, newRoot)
+	}
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		if node.Parent != nil && node.Path != filepath.Join(node.Parent.Path, node.Name) {
+			t.Errorf("%s is not below its parent %s", node.Path, node.Parent.Path)
+		}
+		if _, err := os.Lstat(node.Path); err != nil {
+			t.Errorf("rebound node does not exist on disk: %v", err)
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(result.Root)
+
+	for _, dir := range result.TruncatedDirs {
+		if !strings.HasPrefix(dir, newRoot) {
+			t.Errorf("truncated directory %s not rebound", dir)
+		}
+	}
+	if got := result.Errors[len(result.Errors)-2].Path; got != filepath.Join(newRoot, "dir_000") {
+		t.Errorf("error path %s not rebound", got)
+	}
+	if got := result.Errors[len(result.Errors)-1].Path; got != oldRoot+"-backup" {
+		t.Errorf("path %s outside the root was rewritten", got)
+	}
+}
+
+func TestVerifyRelocation(t *testing.T) {
+	spec := testtree.Spec{Seed: 4, Depth: 1, FanOut: 2, Files: 2}
+	result, newRoot := scanMoved(t, spec, 0)
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T) string
+		wantErr string
+	}{
+		{
+			name:    "moved folder",
+			prepare: func(t *testing.T) string { return newRoot },
+		},
+		{
+			name:    "missing folder",
+			prepare: func(t *testing.T) string { return filepath.Join(t.TempDir(), "absent") },
+			wantErr: "failed to stat",
+		},
+		{
+			name: "file instead of a folder",
+			prepare: func(t *testing.T) string {
+				path := filepath.Join(t.TempDir(), "file")
+				os.WriteFile(path, nil, 0o644)
+				return path
+			},
+			wantErr: "is not a directory",
+		},
+		{
+			name:    "unrelated folder",
+			prepare: func(t *testing.T) string { return t.TempDir() },
+			wantErr: "is missing",
+		},
+		{
+			name: "entry changed type",
+			prepare: func(t *testing.T) string {
+				dir := t.TempDir()
+				if err := spec.Materialize(dir); err != nil {
+					t.Fatal(err)
+				}
+				os.RemoveAll(filepath.Join(dir, "dir_000"))
+				os.WriteFile(filepath.Join(dir, "dir_000"), nil, 0o644)
+				return dir
+			},
+			wantErr: "dir_000 changed type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyRelocation(result.Root, tt.prepare(t))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRebasePath(t *testing.T) {
+	oldRoot, newRoot := filepath.FromSlash("/data/project"), filepath.FromSlash("/archive/project")
+	for _, tt := range []struct{ path, want string }{
+		{"/data/project", "/archive/project"},
+		{"/data/project/src/main.go", "/archive/project/src/main.go"},
+		{"/data/project-old/main.go", "/data/project-old/main.go"},
+		{"/elsewhere", "/elsewhere"},
+	} {
+		if got := rebasePath(filepath.FromSlash(tt.path), oldRoot, newRoot); got != filepath.FromSlash(tt.want) {
+			t.Errorf("rebasePath(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ScanDirectories scans each of paths in turn and joins the results with Combine, so the root is
+// a virtual directory with one child per folder; a single path gives that folder's own result.
+// Folders that cannot be scanned are recorded in Errors, and the call fails only when none can.
+// When ctx is cancelled or times out, the folders read so far are returned, marked Partial,
+// along with the error.
+func (s *FileTreeScanner) ScanDirectories(ctx context.Context, paths []string) (*ScanResult, error) {
+	return s.ScanDirectoriesWithProgress(ctx, paths, nil)
+}
+
+// ScanDirectoriesWithProgress scans like ScanDirectories, reporting progress like
+// ScanDirectoryWithProgress with the items of all folders counted together.
+func (s *FileTreeScanner) ScanDirectoriesWithProgress(ctx context.Context, paths []string, progress ProgressFunc) (*ScanResult, error) {
+	return scanEach(ctx, paths, progress, s.ScanDirectoryWithProgress)
+}
+
+// scanEach scans paths one after another with scan and combines the results as ScanDirectories
+// describes.
+func scanEach(ctx context.Context, paths []string, progress ProgressFunc, scan func(context.Context, string, ProgressFunc) (*ScanResult, error)) (*ScanResult, error) {
+	switch len(paths) {
+	case 0:
+		return nil, fmt.Errorf("no paths to scan")
+	case 1:
+		return scan(ctx, paths[0], progress)
+	}
+
+	var results []*ScanResult
+	var failures []ScanError
+	var stopErr error
+	items := 0
+	started := time.Now()
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			stopErr = err
+			break
+		}
+		var report ProgressFunc
+		if progress != nil {
+			offset := items
+			report = func(p ScanProgress) {
+				if p.Done {
+					return // Reported once, after the last folder
+				}
+				p.Items += offset
+				p.Elapsed = time.Since(started)
+				progress(p)
+			}
+		}
+		result, err := scan(ctx, path, report)
+		if result != nil && result.Root != nil {
+			results = append(results, result)
+			items += result.NodeCount
+		}
+		if err != nil && ctx.Err() != nil {
+			stopErr = err
+			break
+		}
+		if err != nil {
+			failures = append(failures, ScanError{Path: path, Op: ScanOpRead, Err: err})
+		}
+	}
+	if progress != nil {
+		progress(ScanProgress{Items: items, Elapsed: time.Since(started), Done: true})
+	}
+
+	if len(results) == 0 {
+		if stopErr != nil {
+			return nil, stopErr
+		}
+		errs := make([]error, len(failures))
+		for i, failure := range failures {
+			errs[i] = failure
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	combined := Combine(results...)
+	combined.ScannedAt = results[0].ScannedAt
+	combined.Errors = append(combined.Errors, failures...)
+	combined.CountsPartial = combined.CountsPartial || len(failures) > 0
+	if stopErr != nil {
+		stop := StopCause(ctx)
+		combined.Error = stopErr
+		combined.Partial = true
+		combined.CountsPartial = true
+		combined.Truncated = true
+		combined.TruncatedReason, combined.TruncatedLimit = stop.Reason, stop.Limit
+		return combined, fmt.Errorf("failed to scan directories: %w", stopErr)
+	}
+	return combined, nil
+}
@@ -2,43 +2,223 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Akaiko1/file-tree-scanner/internal/applog"
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
 	"github.com/Akaiko1/file-tree-scanner/internal/config"
 )
 
+func init() {
+	capability.Register(capability.Scan)
+}
+
 // TreeNode represents a node in the file tree structure.
 type TreeNode struct {
-	Path     string
-	Name     string
-	IsDir    bool
-	Children []*TreeNode
-	Parent   *TreeNode
+	Path           string
+	Name           string
+	IsDir          bool
+	IsVirtual      bool        // Built from a listing or archive rather than read from disk
+	IsSymlink      bool        // Entry is a symbolic link; links to directories are descended with Config.FollowSymlinks
+	LinkTarget     string      // Target of a symbolic link as stored in the link
+	LinkNormalized string      // LinkTarget relative to the link's directory when inside the root, else absolute; see NormalizeLinks
+	LinkBroken     bool        // Symbolic link whose target does not exist
+	Kind           Kind        // What a file holds, from its name (see Classify); "" for directories
+	Executable     bool        // Any execute bit is set; collected with Config.ShowSize or Config.MarkExecutables
+	ExportIgnore   bool        // Marked export-ignore by a .gitattributes file, directly or through a parent
+	SizeUnknown    bool        // Size could not be read, or the directory was not fully scanned
+	Unreadable     bool        // Directory could not be listed; see ScanResult.Errors
+	Omitted        int         // Entries of the directory left out by Config.MaxEntriesPerDir, or once a scan-wide limit was hit
+	Truncated      bool        // Directory was not read because Config.HardDepthLimit was reached
+	NotRead        bool        // Directory was not read: it lies past Config.MaxDepth or on another filesystem, or a scan-wide limit was hit first
+	MountPoint     bool        // Directory is on another filesystem than the root and was not read (Config.OneFileSystem)
+	TruncateReason string      // Why the directory's contents are missing or incomplete, a Truncate constant or the CancelReason stopping the scan in it; "" when read in full
+	Placeholder    bool        // Stands in for omitted entries or a cut-off directory; only created when drawing the tree
+	Size           int64       // Apparent size in bytes, collected when Config.ShowSize is set; directories sum their children
+	DiskSize       int64       // Allocated size in bytes; equals Size where the platform cannot tell
+	ModTime        time.Time   // Modification time, collected when Config.CollectTimes is set; zero if unknown
+	Mode           fs.FileMode // Type and permission bits, collected when Config.CollectMode is set; zero if unknown
+	Xattrs         []string    // Extended attributes, or alternate data streams on Windows, with Config.CollectXattrs
+	Hash           string      // Hex SHA-256 of a regular file's content, with Config.ComputeHashes; "" if not hashed
+	Lines          int         // Lines of a text file, with Config.CountLines; 0 if not counted
+	Tokens         int         // Estimated language model tokens of a text file, with Config.EstimateTokens; directories sum their contents
+	Children       []*TreeNode
+	Parent         *TreeNode
+
+	realPath string // Path with links resolved, recorded by a scan for the root and the directories holding links; see NormalizeLinks
 }
 
 // ScanResult contains the results of a directory scan operation.
 type ScanResult struct {
-	RootPath  string
-	TreeText  string
-	NodeCount int
-	Error     error
-	Root      *TreeNode // Root node of the scanned tree for UI rendering
+	RootPath        string
+	TreeText        string
+	TreeLines       int // Lines in TreeText, counted as it was rendered
+	NodeCount       int
+	Error           error
+	Root            *TreeNode              // Root node of the scanned tree for UI rendering
+	Truncated       bool                   // Scan stopped descending before covering the whole tree
+	TruncatedCount  int                    // Directories with a TruncateReason, whose contents are not all shown
+	TruncatedReason CancelReason           // Why the scan stopped early, when Truncated
+	TruncatedLimit  string                 // The limit that was reached, formatted for display
+	ScannedAt       time.Time              // When the scan started; zero for imported trees
+	Skipped         SkipStats              // Entries left out by each filter rule
+	HasSizes        bool                   // Sizes were collected (Config.ShowSize)
+	HasTimes        bool                   // Modification times were collected (Config.CollectTimes)
+	HasModes        bool                   // Permissions and types were collected (Config.CollectMode)
+	IncludePatterns []string               // Config.IncludePatterns the scan was limited to
+	ExcludePatterns []string               // Config.ExcludePatterns the scan was filtered by
+	Latest          *TreeNode              // Most recently modified file, with Config.CollectTimes; nil if unknown
+	DirCount        int                    // Directories below the root
+	FileCount       int                    // Files and other non-directories below the root
+	TotalSize       int64                  // Apparent size of the tree, with Config.ShowSize
+	MaxDepthReached int                    // Deepest level in the tree, the root's children being level 1
+	CountsPartial   bool                   // Some directories were not read in full (depth, entry or memory limits, errors)
+	Background      bool                   // Some directories were read at background priority, so the duration is not comparable
+	BackgroundKept  bool                   // Background priority was turned off during the scan, but the platform would not raise the scanning thread's priority again
+	Partial         bool                   // The scan was cancelled or timed out; Root holds what was read until then and Error why
+	Errors          []ScanError            // Directories and entries that could not be read
+	TruncatedDirs   []string               // Directories whose entries were cut to Config.MaxEntriesPerDir
+	Roots           []*ScanResult          // Results of the folders joined by Combine; nil for a single folder
+	HasHashes       bool                   // File contents were hashed (Config.ComputeHashes)
+	HasXattrs       bool                   // Extended attributes were listed (Config.CollectXattrs)
+	HasLines        bool                   // Lines of text files were counted (Config.CountLines)
+	TotalLines      int                    // Lines of the text files in the tree, with HasLines
+	HasTokens       bool                   // Tokens of text files were estimated (Config.EstimateTokens)
+	LanguageTable   map[string]string      // Config.Languages the language breakdown was made with
+	Languages       []LanguageShare        // Languages of the files in the tree; see LanguageBreakdown
+	LanguagesBy     string                 // What Languages are weighed by: WeighByLines, WeighBySize or WeighByFiles
+	TotalTokens     int                    // Estimated tokens of the tree, with HasTokens
+	Duplicates      map[string][]*TreeNode // Files of identical content by hash, with HasHashes; see FindDuplicates
+	DuplicateDirMin int                    // Config.DuplicateDirMinItems the result was searched with; 0 if it was not
+	DuplicateDirs   []DirGroup             // Directories that are copies of each other; see FindDuplicateDirs
 }
 
 // FileSystemScanner defines the interface for scanning file systems.
 type FileSystemScanner interface {
 	ScanDirectory(ctx context.Context, path string) (*ScanResult, error)
+	ScanDirectories(ctx context.Context, paths []string) (*ScanResult, error)
 }
 
 // FileTreeScanner implements FileSystemScanner for scanning directory structures.
 type FileTreeScanner struct {
-	config *config.Config
+	config      *config.Config
+	files       fileSystem
+	gate        pauseGate
+	readHeap    func() uint64               // Replaceable for tests
+	setPriority func(background bool) error // Of the calling thread; replaceable for tests
+
+	background atomic.Bool // Read at background priority; see PriorityScanner
+}
+
+// scanState holds bookkeeping for a single ScanDirectory call. Directories may be read in
+// parallel, so the fields below mu are only accessed with it held.
+type scanState struct {
+	thread threadPriority // Priority of the scanning thread of a sequential scan
+	source fileSystem     // Where directories are read
+
+	mu            sync.Mutex
+	filters       filterPipeline
+	skipped       SkipStats
+	progress      *progressTracker
+	visited       map[fileID]bool // Directories already read, to break cycles
+	rootDev       *uint64         // Device of the root with Config.OneFileSystem, when the platform tells
+	realPaths     map[string]bool // Resolved paths of the directories already read
+	latest        *TreeNode       // Most recently modified file so far
+	dirs, files   int             // Entries added below the root
+	maxDepth      int             // Deepest level added, the root's children being level 1
+	partial       bool            // Some directory was not read in full
+	dirsRead      int
+	stopped       bool
+	stoppedReason CancelReason
+	stoppedLimit  string
+	background    bool // Background priority was requested for some directory
+	kept          bool // A thread stayed at background priority after it was turned off
+
+	errors        []ScanError // Paths that could not be read
+	truncatedDirs []string    // Directories cut to Config.MaxEntriesPerDir
+	warnings      *applog.Sampler
+}
+
+// fileID identifies a directory independently of the path it was reached by.
+type fileID struct {
+	dev, ino uint64
+}
+
+// markPartial records that some directory was not read in full.
+func (st *scanState) markPartial() {
+	st.mu.Lock()
+	st.partial = true
+	st.mu.Unlock()
+}
+
+// isStopped reports whether a scan-wide limit has been hit.
+func (st *scanState) isStopped() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.stopped
+}
+
+// visit records that node is about to be read, returning false when its directory was already
+// read under another path.
+func (st *scanState) visit(node *TreeNode, realPath string) bool {
+	st.mu.Lock()
+	seen := st.realPaths[realPath]
+	st.realPaths[realPath] = true
+	st.mu.Unlock()
+	if seen {
+		st.warnings.Printf(cycleWarning, node.Path, "Warning: skipping %s, already scanned as %s (directory cycle)", node.Path, realPath)
+		return false
+	}
+
+	info, err := st.source.Stat(node.Path)
+	if err != nil {
+		return true
+	}
+	id, ok := identify(info)
+	if !ok {
+		return true
+	}
+	st.mu.Lock()
+	seen = st.visited[id]
+	st.visited[id] = true
+	st.mu.Unlock()
+	if seen {
+		st.warnings.Printf(cycleWarning, node.Path, "Warning: skipping %s, already scanned (directory cycle)", node.Path)
+		return false
+	}
+	return true
+}
+
+// onRootDevice reports whether node lies on the root's filesystem, or the device is unknown.
+func (st *scanState) onRootDevice(node *TreeNode) bool {
+	if st.rootDev == nil {
+		return true
+	}
+	info, err := st.source.Stat(node.Path)
+	if err != nil {
+		return true // Reading the directory reports the error
+	}
+	id, ok := identify(info)
+	return !ok || id.dev == *st.rootDev
+}
+
+// stop prevents the scan from descending any further; st.mu must be held.
+func (st *scanState) stop(reason CancelReason, limit string) {
+	if !st.stopped {
+		log.Printf("Warning: %s", reason.Message(limit))
+	}
+	st.stopped = true
+	st.stoppedReason = reason
+	st.stoppedLimit = limit
 }
 
 // NewFileTreeScanner creates a new FileTreeScanner with the given configuration.
@@ -46,18 +226,52 @@ func NewFileTreeScanner(cfg *config.Config) *FileTreeScanner {
 	if cfg == nil {
 		cfg = config.DefaultConfig()
 	}
-	return &FileTreeScanner{
-		config: cfg,
+	s := &FileTreeScanner{
+		config:      cfg,
+		files:       diskFileSystem{},
+		readHeap:    heapInUse,
+		setPriority: setThreadBackground,
 	}
+	s.background.Store(cfg.BackgroundPriority)
+	return s
+}
+
+// NewFileTreeScannerFS creates a FileTreeScanner reading fsys instead of the disk, such as an
+// embed.FS or fstest.MapFS. Paths name entries of fsys below rootName, which stands for its root
+// directory: ScanDirectory(ctx, rootName) scans all of fsys, and filepath.Join(rootName, "src")
+// its src directory.
+func NewFileTreeScannerFS(cfg *config.Config, fsys fs.FS, rootName string) *FileTreeScanner {
+	s := NewFileTreeScanner(cfg)
+	s.files = ioFileSystem{fsys: fsys, root: rootName}
+	return s
 }
 
 // ScanDirectory recursively scans a directory structure and returns detailed results including node count and tree representation.
 func (s *FileTreeScanner) ScanDirectory(ctx context.Context, path string) (*ScanResult, error) {
+	return s.ScanDirectoryWithProgress(ctx, path, nil)
+}
+
+// ScanDirectoryWithProgress scans like ScanDirectory, calling progress at most every 100ms and once at the end.
+// When ctx is cancelled or times out, the tree read so far is returned along with the error,
+// marked Partial and Truncated with the reason taken from the context.
+func (s *FileTreeScanner) ScanDirectoryWithProgress(ctx context.Context, path string, progress ProgressFunc) (*ScanResult, error) {
 	if path == "" {
 		return nil, fmt.Errorf("path cannot be empty")
 	}
+	if err := ValidatePatterns(s.config.ExcludePatterns); err != nil {
+		return nil, err
+	}
+	if err := ValidatePatterns(s.config.IncludePatterns); err != nil {
+		return nil, err
+	}
+	if err := ValidateKinds(s.config.FileKinds); err != nil {
+		return nil, err
+	}
+	if err := ValidateSortBy(s.config.SortBy); err != nil {
+		return nil, err
+	}
 
-	info, err := os.Stat(path)
+	info, err := s.files.Stat(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat path %q: %w", path, err)
 	}
@@ -71,152 +285,532 @@ func (s *FileTreeScanner) ScanDirectory(ctx context.Context, path string) (*Scan
 		Name:  filepath.Base(path),
 		IsDir: true,
 	}
+	if s.config.CollectMode {
+		root.Mode = info.Mode()
+	}
 
-	nodeCount, err := s.scanNode(ctx, root, 0)
+	scannedAt := time.Now()
+	filters := s.scanFilters(ctx, path)
+	state := &scanState{
+		source:    s.files,
+		filters:   filters,
+		skipped:   make(SkipStats),
+		progress:  newProgressTracker(progress),
+		visited:   make(map[fileID]bool),
+		realPaths: make(map[string]bool),
+		warnings:  applog.NewSampler(sampledWarnings),
+	}
+	for _, problem := range treeIgnoreProblems(filters) {
+		log.Printf("Warning: %v", problem)
+		state.errors = append(state.errors, problem)
+	}
+	if s.config.OneFileSystem {
+		if id, ok := identify(info); ok {
+			state.rootDev = &id.dev
+		}
+	}
+	state.progress.add(1)
+	realPath, err := s.files.EvalSymlinks(path)
 	if err != nil {
+		realPath = path
+	}
+	root.realPath = realPath
+	var nodeCount int
+	if workers := s.config.ConcurrentOps; workers > 1 {
+		nodeCount, err = s.scanParallel(ctx, state, root, realPath, workers)
+	} else {
+		onScanThread(func() {
+			nodeCount, err = s.scanNode(ctx, state, root, realPath, 0, nil)
+		})
+	}
+	state.progress.finish()
+	state.warnings.Flush()
+	if err != nil && ctx.Err() == nil {
 		return nil, fmt.Errorf("failed to scan directory: %w", err)
 	}
 
-	return &ScanResult{
-		RootPath:  path,
-		NodeCount: nodeCount,
-		Error:     nil,
-		Root:      root,
-	}, nil
-}
-
-// scanNode recursively scans a directory node, respecting depth limits and cancellation context.
-func (s *FileTreeScanner) scanNode(ctx context.Context, node *TreeNode, depth int) (int, error) {
-	// Check for cancellation more frequently
-	select {
-	case <-ctx.Done():
-		return 0, ctx.Err()
-	default:
+	// Directories are only known to lead to an included file once they have been read
+	if filter := newIncludeFilter(path, s.config.IncludePatterns); filter != nil {
+		dirs, files := filter.prune(root, false, s.config.ShowSize)
+		nodeCount -= dirs + files
+		state.dirs -= dirs
+		state.files -= files
+		state.skipped[IncludePatternRule] += dirs + files
 	}
-
-	// Enforce depth limits to prevent infinite recursion
-	if s.config.MaxDepth >= 0 && depth > s.config.MaxDepth {
-		return 0, nil
+	if s.config.PruneEmptyDirs && ctx.Err() == nil {
+		dirs := PruneEmpty(root)
+		nodeCount -= dirs
+		state.dirs -= dirs
+		state.skipped[EmptyDirRule] += dirs
 	}
 
-	// Add safety limit even when MaxDepth is unlimited
-	if depth > 50 {
-		log.Printf("Warning: stopping scan at depth %d for path %s", depth, node.Path)
-		return 1, nil
+	// Directories can only be sorted by size once their contents are summed
+	if s.config.SortDirs && s.config.SortBy == config.SortBySize && s.config.ShowSize {
+		SortTreeBy(root, OrderOf(s.config))
 	}
 
-	entries, err := os.ReadDir(node.Path)
+	NormalizeLinks(root)
+	result := &ScanResult{
+		RootPath:        path,
+		NodeCount:       nodeCount,
+		Error:           nil,
+		Root:            root,
+		Truncated:       state.stopped,
+		TruncatedReason: state.stoppedReason,
+		TruncatedLimit:  state.stoppedLimit,
+		TruncatedCount:  countTruncated(root),
+		ScannedAt:       scannedAt,
+		Skipped:         state.skipped,
+		HasSizes:        s.config.ShowSize,
+		HasTimes:        s.config.CollectTimes,
+		HasModes:        s.config.CollectMode,
+		IncludePatterns: s.config.IncludePatterns,
+		ExcludePatterns: s.config.ExcludePatterns,
+		Latest:          state.latest,
+		DirCount:        state.dirs,
+		FileCount:       state.files,
+		TotalSize:       root.Size,
+		MaxDepthReached: state.maxDepth,
+		CountsPartial:   state.partial,
+		Background:      state.background,
+		BackgroundKept:  state.kept,
+		Errors:          state.errors,
+		TruncatedDirs:   state.truncatedDirs,
+		HasHashes:       s.config.ComputeHashes,
+		HasXattrs:       s.config.CollectXattrs,
+		HasLines:        s.config.CountLines,
+		HasTokens:       s.config.EstimateTokens,
+		LanguageTable:   s.config.Languages,
+		DuplicateDirMin: s.config.DuplicateDirMinItems,
+	}
+	result.tallyLanguages()
+	if result.HasLines {
+		result.TotalLines = totalLines(root)
+	}
+	if result.HasTokens {
+		result.TotalTokens = SumTokens(root)
+	}
+	if result.HasHashes {
+		result.Duplicates = FindDuplicates(root)
+	}
+	if result.DuplicateDirMin > 0 && err == nil {
+		result.DuplicateDirs = FindDuplicateDirs(root, result.DuplicateDirMin)
+	}
 	if err != nil {
-		log.Printf("Warning: failed to read directory %q: %v", node.Path, err)
-		return 1, nil // Continue with partial results
+		stop := StopCause(ctx)
+		result.Error = err
+		result.Partial = true
+		result.CountsPartial = true
+		result.Truncated = true
+		result.TruncatedReason, result.TruncatedLimit = stop.Reason, stop.Limit
+		return result, fmt.Errorf("failed to scan directory: %w", err)
 	}
+	return result, nil
+}
 
-	// Limit number of entries to prevent memory issues
-	if len(entries) > 10000 {
-		log.Printf("Warning: directory %s has %d entries, limiting to first 1000", node.Path, len(entries))
-		entries = entries[:1000]
-	}
+// Pause suspends the running scan before it reads the next directory.
+func (s *FileTreeScanner) Pause() {
+	s.gate.pause()
+}
 
-	// Filter hidden files if configured
-	if !s.config.ShowHidden {
-		entries = s.filterHiddenEntries(entries)
-	}
+// Resume continues a paused scan.
+func (s *FileTreeScanner) Resume() {
+	s.gate.resume()
+}
 
-	// Sort entries if configured
-	if s.config.SortDirs {
-		s.sortEntries(entries)
+// Paused reports whether the scanner is currently paused.
+func (s *FileTreeScanner) Paused() bool {
+	return s.gate.paused()
+}
+
+// scanNode recursively scans a directory node, respecting depth limits and cancellation context.
+// realPath is the node's path with symbolic links resolved, and scopes the .gitattributes files
+// of the directories above it.
+func (s *FileTreeScanner) scanNode(ctx context.Context, state *scanState, node *TreeNode, realPath string, depth int, scopes []attrScope) (int, error) {
+	listing, count, err := s.openDir(ctx, state, &state.thread, node, realPath, depth, scopes)
+	if listing == nil {
+		return count, err
 	}
 
 	nodeCount := 1 // Count current node
 
-	for i, entry := range entries {
+	for i, entry := range listing.entries {
 		// Check for cancellation in the loop
 		select {
 		case <-ctx.Done():
+			s.abandon(ctx, node)
 			return nodeCount, ctx.Err()
 		default:
 		}
 
+		if state.isStopped() {
+			s.cutShort(state, node, len(listing.entries)-i)
+			break
+		}
+
 		// Limit processing time per directory
 		if i > 0 && i%100 == 0 {
 			// Brief pause every 100 entries to allow cancellation
 			time.Sleep(1 * time.Millisecond)
+			if err := s.gate.wait(ctx); err != nil {
+				s.abandon(ctx, node)
+				return nodeCount, err
+			}
 		}
 
-		childPath := filepath.Join(node.Path, entry.Name())
-
-		// Skip problematic paths
-		if s.isProblematicPath(childPath) {
-			continue
-		}
-
-		child := &TreeNode{
-			Path:   childPath,
-			Name:   entry.Name(),
-			IsDir:  entry.IsDir(),
-			Parent: node,
-		}
-
-		node.Children = append(node.Children, child)
-
+		child, childRealPath := s.addChild(state, listing, node, entry, realPath, depth)
 		if child.IsDir {
-			childCount, err := s.scanNode(ctx, child, depth+1)
+			childCount, err := s.scanNode(ctx, state, child, childRealPath, depth+1, listing.scopes)
 			if err != nil {
 				if err == context.Canceled || err == context.DeadlineExceeded {
-					return nodeCount, err
+					s.abandon(ctx, node)
+					return nodeCount + childCount, err
 				}
 				// Log error but continue
-				log.Printf("Error scanning subdirectory %s: %v", childPath, err)
+				log.Printf("Error scanning subdirectory %s: %v", child.Path, err)
 			}
 			nodeCount += childCount
 		} else {
+			if s.config.ComputeHashes {
+				s.hashFile(ctx, state, child)
+			}
+			if s.config.CountLines || s.config.EstimateTokens {
+				s.measureText(ctx, state, child)
+			}
 			nodeCount++
 		}
 	}
 
+	if s.config.ShowSize {
+		sumSizes(node)
+	}
 	return nodeCount, nil
 }
 
-// isProblematicPath checks if a path might cause issues and should be skipped.
-func (s *FileTreeScanner) isProblematicPath(path string) bool {
-	// Skip Windows system paths that often cause permission issues
-	problematicPaths := []string{
-		"System Volume Information",
-		"$Recycle.Bin",
-		"$WINDOWS.~BT",
-		"Recovery",
-		"ProgramData\\Microsoft\\Windows Defender",
-		"Windows\\System32\\config",
+// dirListing holds the entries of a directory that go into the tree.
+type dirListing struct {
+	entries []os.DirEntry // Filtered, capped and sorted
+	scopes  []attrScope   // .gitattributes files from the root down to the directory itself
+}
+
+// openDir runs the checks before node is read and lists its entries. When the directory is not
+// read, the listing is nil and the count and error are what scanning it amounts to.
+func (s *FileTreeScanner) openDir(ctx context.Context, state *scanState, thread *threadPriority, node *TreeNode, realPath string, depth int, scopes []attrScope) (*dirListing, int, error) {
+	// Check for cancellation more frequently
+	select {
+	case <-ctx.Done():
+		s.abandon(ctx, node)
+		return nil, 1, ctx.Err()
+	default:
+	}
+
+	// Enforce depth limits to prevent infinite recursion
+	if s.config.MaxDepth >= 0 && depth > s.config.MaxDepth {
+		node.NotRead = true
+		node.TruncateReason = TruncateDepth
+		node.SizeUnknown = s.config.ShowSize
+		state.markPartial()
+		return nil, 0, nil
+	}
+
+	// Hold here while paused; no directory is read until resumed
+	if err := s.gate.wait(ctx); err != nil {
+		s.abandon(ctx, node)
+		return nil, 1, err
+	}
+	s.adjustPriority(state, thread)
+
+	// Stop descending once a scan-wide limit has been hit
+	state.mu.Lock()
+	s.checkMemory(state)
+	stopped, reason := state.stopped, state.stoppedReason
+	state.mu.Unlock()
+	if stopped {
+		node.NotRead = true
+		node.TruncateReason = string(reason)
+		node.SizeUnknown = s.config.ShowSize
+		state.markPartial()
+		return nil, 1, nil
+	}
+
+	// Add safety limit even when MaxDepth is unlimited
+	if limit := s.config.HardDepthLimit; limit > 0 && depth > limit {
+		state.warnings.Printf(depthWarning, node.Path, "Warning: stopping scan at depth %d for path %s", depth, node.Path)
+		node.Truncated = true
+		node.TruncateReason = TruncateHardDepth
+		node.SizeUnknown = s.config.ShowSize
+		state.markPartial()
+		return nil, 1, nil
+	}
+
+	// A mount point is shown but not read, to stay on the root's filesystem
+	if depth > 0 && !state.onRootDevice(node) {
+		node.MountPoint = true
+		node.NotRead = true
+		node.TruncateReason = TruncateMount
+		node.SizeUnknown = s.config.ShowSize
+		return nil, 1, nil
 	}
 
-	for _, problematic := range problematicPaths {
-		if strings.Contains(path, problematic) {
-			return true
+	// A directory reached a second time, through a bind mount or link, is shown but not read again
+	if !state.visit(node, realPath) {
+		node.TruncateReason = TruncateRepeat
+		node.SizeUnknown = s.config.ShowSize
+		return nil, 1, nil
+	}
+
+	entries, err := s.readDir(ctx, node.Path)
+	if err != nil && ctx.Err() != nil {
+		s.abandon(ctx, node)
+		return nil, 1, ctx.Err()
+	}
+	if err != nil {
+		node.TruncateReason = TruncateUnreadable
+		if errors.Is(err, ErrDirTimeout) {
+			node.TruncateReason = TruncateTimeout
+		}
+		state.warnings.Printf(readWarning(err), node.Path, "Warning: failed to read directory %q: %v", node.Path, err)
+		state.mu.Lock()
+		state.fail(node, ScanOpRead, err)
+		state.partial = true
+		state.mu.Unlock()
+		node.SizeUnknown = s.config.ShowSize
+		return nil, 1, nil // Continue with partial results
+	}
+
+	// Attributes apply before filtering, since .gitattributes itself is usually hidden
+	for _, entry := range entries {
+		if entry.Name() == gitAttributesFile && entry.Type().IsRegular() {
+			scopes = append(scopes[:len(scopes):len(scopes)], readGitAttributes(s.files, node.Path))
+			break
 		}
 	}
 
-	return false
+	state.mu.Lock()
+
+	// Drop entries excluded by the filter pipeline (system paths, hidden files, own exports)
+	entries = s.filterEntries(state.filters, state.skipped, node.Path, entries)
+
+	// Limit number of entries to prevent memory issues
+	if limit := s.config.MaxEntriesPerDir; limit > 0 && len(entries) > limit {
+		state.warnings.Printf(entriesWarning, node.Path, "Warning: directory %s has %d entries, limiting to first %d", node.Path, len(entries), limit)
+		node.Omitted = len(entries) - limit
+		node.TruncateReason = TruncateEntries
+		entries = entries[:limit]
+		state.partial = true
+		state.truncatedDirs = append(state.truncatedDirs, node.Path)
+	}
+
+	state.progress.add(len(entries))
+	state.progress.tick(node.Path)
+	state.mu.Unlock()
+
+	// Sort entries if configured
+	if s.config.SortDirs {
+		s.sortEntries(node.Path, entries)
+	}
+	return &dirListing{entries: entries, scopes: scopes}, 0, nil
 }
 
-// filterHiddenEntries filters out hidden files and directories.
-func (s *FileTreeScanner) filterHiddenEntries(entries []os.DirEntry) []os.DirEntry {
-	filtered := make([]os.DirEntry, 0, len(entries))
-	for _, entry := range entries {
-		if !strings.HasPrefix(entry.Name(), ".") {
-			filtered = append(filtered, entry)
+// addChild adds the node for entry to node, a directory at depth, and returns it with its
+// resolved path.
+func (s *FileTreeScanner) addChild(state *scanState, listing *dirListing, node *TreeNode, entry os.DirEntry, realPath string, depth int) (*TreeNode, string) {
+	childPath := filepath.Join(node.Path, entry.Name())
+
+	child := &TreeNode{
+		Path:      childPath,
+		Name:      entry.Name(),
+		IsDir:     entry.IsDir(),
+		IsSymlink: entry.Type()&fs.ModeSymlink != 0,
+		Parent:    node,
+	}
+	childRealPath := filepath.Join(realPath, entry.Name())
+	if child.IsSymlink {
+		childRealPath = s.resolveLink(child)
+		node.realPath = realPath
+	}
+	if !child.IsDir {
+		child.Kind = Classify(child.Name, s.config.FileKinds)
+	}
+	child.ExportIgnore = node.ExportIgnore || (len(listing.scopes) > 0 && exportIgnored(listing.scopes, childPath, child.IsDir))
+
+	if s.config.CollectTimes || s.config.CollectMode || ((s.config.ShowSize || s.config.MarkExecutables) && !child.IsDir) {
+		s.collectInfo(state, child, entry)
+	}
+	if s.config.CollectXattrs {
+		s.collectXattrs(state, child)
+	}
+
+	node.Children = append(node.Children, child)
+	state.mu.Lock()
+	state.maxDepth = max(state.maxDepth, depth+1)
+	if child.IsDir {
+		state.dirs++
+	} else {
+		state.files++
+	}
+	if limit := s.config.MaxNodes; limit > 0 && state.dirs+state.files >= limit && !state.stopped {
+		state.stop(ReasonNodeLimit, fmt.Sprintf("%d items", limit))
+	}
+	state.mu.Unlock()
+	return child, childRealPath
+}
+
+// cutShort records that the last remaining entries of node were not added because a scan-wide
+// limit was hit while it was read.
+func (s *FileTreeScanner) cutShort(state *scanState, node *TreeNode, remaining int) {
+	node.Omitted += remaining
+	node.SizeUnknown = s.config.ShowSize
+	state.mu.Lock()
+	node.TruncateReason = string(state.stoppedReason)
+	state.partial = true
+	state.mu.Unlock()
+}
+
+// resolveLink records a symbolic link's target and returns its resolved path. With
+// Config.FollowSymlinks, a link to a directory becomes a directory node, keeping the link's
+// name and path. A link whose target is missing is marked broken and stays a leaf.
+func (s *FileTreeScanner) resolveLink(node *TreeNode) string {
+	if target, err := s.files.Readlink(node.Path); err == nil {
+		node.LinkTarget = target
+	}
+	realPath, err := s.files.EvalSymlinks(node.Path)
+	if err != nil {
+		node.LinkBroken = errors.Is(err, fs.ErrNotExist)
+		return ""
+	}
+	if s.config.FollowSymlinks {
+		if info, err := s.files.Stat(realPath); err == nil && info.IsDir() {
+			node.IsDir = true
 		}
 	}
-	return filtered
+	return realPath
+}
+
+// Tally sets the counts, lines, languages and depth of a result built without scanning, such as
+// an imported listing.
+func Tally(result *ScanResult) {
+	result.DirCount, result.FileCount, result.MaxDepthReached, result.TotalLines = 0, 0, 0, 0
+	result.TruncatedCount = countTruncated(result.Root)
+	var walk func(node *TreeNode, depth int)
+	walk = func(node *TreeNode, depth int) {
+		for _, child := range node.Children {
+			result.MaxDepthReached = max(result.MaxDepthReached, depth+1)
+			if child.IsDir {
+				result.DirCount++
+			} else {
+				result.FileCount++
+			}
+			result.TotalLines += child.Lines
+			walk(child, depth+1)
+		}
+	}
+	if result.Root != nil {
+		walk(result.Root, 0)
+	}
+	result.tallyLanguages()
+}
+
+// tallyLanguages sets the language breakdown of the result's tree.
+func (r *ScanResult) tallyLanguages() {
+	r.Languages, r.LanguagesBy = LanguageBreakdown(r.Root, r.LanguageTable, r.HasLines, r.HasSizes)
+}
+
+// sumSizes sets a directory's sizes to the total of its children's.
+func sumSizes(node *TreeNode) {
+	node.Size, node.DiskSize = 0, 0
+	for _, child := range node.Children {
+		node.Size += child.Size
+		node.DiskSize += child.DiskSize
+	}
+}
+
+// countTruncated returns the number of directories at or below node with a TruncateReason.
+func countTruncated(node *TreeNode) int {
+	if node == nil {
+		return 0
+	}
+	count := 0
+	if node.TruncateReason != "" {
+		count++
+	}
+	for _, child := range node.Children {
+		count += countTruncated(child)
+	}
+	return count
 }
 
-// sortEntries sorts directory entries with directories first, then files.
-func (s *FileTreeScanner) sortEntries(entries []os.DirEntry) {
-	sort.Slice(entries, func(i, j int) bool {
-		// Directories come first
-		if entries[i].IsDir() != entries[j].IsDir() {
-			return entries[i].IsDir()
+// abandon marks node as not fully read when the scan is cancelled inside it, keeping the
+// sizes of what was read.
+func (s *FileTreeScanner) abandon(ctx context.Context, node *TreeNode) {
+	if stop := StopCause(ctx); stop != nil {
+		node.TruncateReason = string(stop.Reason)
+	}
+	if s.config.ShowSize {
+		node.SizeUnknown = true
+		sumSizes(node)
+	}
+}
+
+// collectInfo fills the modification time and mode, and for files the sizes and executable flag,
+// from a single stat. A failed stat leaves them unset.
+func (s *FileTreeScanner) collectInfo(state *scanState, node *TreeNode, entry os.DirEntry) {
+	info, err := entry.Info()
+	if err != nil {
+		state.mu.Lock()
+		state.fail(node, ScanOpStat, err)
+		state.mu.Unlock()
+		node.SizeUnknown = s.config.ShowSize && !node.IsDir
+		return
+	}
+	if s.config.CollectTimes {
+		node.ModTime = info.ModTime()
+		state.mu.Lock()
+		if !node.IsDir && (state.latest == nil || node.ModTime.After(state.latest.ModTime)) {
+			state.latest = node
 		}
-		// Then sort alphabetically
-		return entries[i].Name() < entries[j].Name()
-	})
-}
\ No newline at end of file
+		state.mu.Unlock()
+	}
+	if s.config.CollectMode {
+		node.Mode = info.Mode()
+	}
+	if node.IsDir {
+		return
+	}
+	node.Executable = info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+	if !s.config.ShowSize {
+		return
+	}
+	node.Size, node.DiskSize = fileSizes(node.Path, info)
+}
+
+// fileSizes returns the apparent and allocated sizes of the file at path, the allocated size
+// falling back to the apparent one where the platform cannot tell.
+func fileSizes(path string, info fs.FileInfo) (size, diskSize int64) {
+	size = info.Size()
+	if allocated, ok := allocatedSize(path, info); ok {
+		return size, allocated
+	}
+	return size, size
+}
+
+// collectXattrs records the names of node's extended attributes, noting when they cannot be listed.
+func (s *FileTreeScanner) collectXattrs(state *scanState, node *TreeNode) {
+	names, err := state.source.Xattrs(node.Path)
+	if err != nil {
+		state.mu.Lock()
+		state.fail(node, ScanOpXattrs, err)
+		state.mu.Unlock()
+		return
+	}
+	sort.Strings(names)
+	node.Xattrs = names
+}
+
+// SizeFor returns the node's size in the given basis (config.SizeApparent or config.SizeAllocated).
+func (n *TreeNode) SizeFor(basis string) int64 {
+	if basis == config.SizeAllocated {
+		return n.DiskSize
+	}
+	return n.Size
+}
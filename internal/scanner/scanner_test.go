@@ -0,0 +1,340 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/testtree"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard) // Scans log every limit they hit
+	os.Exit(m.Run())
+}
+
+// fixtureRoot is the node path standing for the root of fixture file systems.
+const fixtureRoot = "tree"
+
+// scanFixture scans fsys with cfg, failing the test on error.
+func scanFixture(t testing.TB, cfg *config.Config, fsys fs.FS) *ScanResult {
+	t.Helper()
+	result, err := NewFileTreeScannerFS(cfg, fsys, fixtureRoot).ScanDirectory(context.Background(), fixtureRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// scannedPaths returns the slash-separated paths below the root of result's tree, sorted.
+func scannedPaths(result *ScanResult) []string {
+	var paths []string
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		for _, child := range node.Children {
+			rel, _ := filepath.Rel(fixtureRoot, child.Path)
+			paths = append(paths, filepath.ToSlash(rel))
+			walk(child)
+		}
+	}
+	walk(result.Root)
+	sort.Strings(paths)
+	return paths
+}
+
+// expectedPaths returns the paths of the entries of spec that keep reports true for, sorted,
+// with the numbers of directories and files among them.
+func expectedPaths(spec testtree.Spec, keep func(entry testtree.Entry) bool) (paths []string, dirs, files int) {
+	for _, entry := range spec.Entries() {
+		if !keep(entry) {
+			continue
+		}
+		paths = append(paths, entry.Path)
+		if entry.IsDir {
+			dirs++
+		} else {
+			files++
+		}
+	}
+	sort.Strings(paths)
+	return paths, dirs, files
+}
+
+// visible reports whether no component of p is hidden by name.
+func visible(p string) bool {
+	for _, part := range strings.Split(p, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// levels returns the number of components of p, the root's children having one.
+func levels(p string) int {
+	return strings.Count(p, "/") + 1
+}
+
+// fixtureConfig returns the default configuration showing everything, for cases to narrow.
+func fixtureConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.ShowHidden = true
+	cfg.MaxDepth = -1
+	return cfg
+}
+
+func TestScanFixtures(t *testing.T) {
+	spec := testtree.Small()
+	tests := []struct {
+		name      string
+		configure func(cfg *config.Config)
+		keep      func(entry testtree.Entry) bool
+	}{
+		{
+			name:      "everything",
+			configure: func(cfg *config.Config) {},
+			keep:      func(testtree.Entry) bool { return true },
+		},
+		{
+			name:      "hidden entries left out",
+			configure: func(cfg *config.Config) { cfg.ShowHidden = false },
+			keep:      func(e testtree.Entry) bool { return visible(e.Path) },
+		},
+		{
+			name:      "depth 0 lists only the root",
+			configure: func(cfg *config.Config) { cfg.MaxDepth = 0 },
+			keep:      func(e testtree.Entry) bool { return levels(e.Path) <= 1 },
+		},
+		{
+			name:      "depth 1",
+			configure: func(cfg *config.Config) { cfg.MaxDepth = 1 },
+			keep:      func(e testtree.Entry) bool { return levels(e.Path) <= 2 },
+		},
+		{
+			name:      "hard depth limit",
+			configure: func(cfg *config.Config) { cfg.HardDepthLimit = 2 },
+			keep:      func(e testtree.Entry) bool { return levels(e.Path) <= 3 },
+		},
+		{
+			name:      "name pattern at any depth",
+			configure: func(cfg *config.Config) { cfg.ExcludePatterns = []string{"file_001.txt"} },
+			keep:      func(e testtree.Entry) bool { return path.Base(e.Path) != "file_001.txt" },
+		},
+		{
+			name:      "anchored directory pattern",
+			configure: func(cfg *config.Config) { cfg.ExcludePatterns = []string{"dir_000/**"} },
+			keep: func(e testtree.Entry) bool {
+				return e.Path != "dir_000" && !strings.HasPrefix(e.Path, "dir_000/")
+			},
+		},
+		{
+			name: "exclusions and hidden entries together",
+			configure: func(cfg *config.Config) {
+				cfg.ShowHidden = false
+				cfg.ExcludePatterns = []string{"file_00[0-2].txt"}
+			},
+			keep: func(e testtree.Entry) bool {
+				base := path.Base(e.Path)
+				return visible(e.Path) && !(strings.HasPrefix(base, "file_00") && base < "file_003")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fixtureConfig()
+			tt.configure(cfg)
+			result := scanFixture(t, cfg, spec.MapFS())
+
+			want, dirs, files := expectedPaths(spec, tt.keep)
+			got := scannedPaths(result)
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("scanned %d entries, want %d\ngot:  %v\nwant: %v", len(got), len(want), got, want)
+			}
+			if result.DirCount != dirs || result.FileCount != files {
+				t.Errorf("counts %d directories, %d files; want %d, %d", result.DirCount, result.FileCount, dirs, files)
+			}
+		})
+	}
+}
+
+func TestScanFixtureIncludePatterns(t *testing.T) {
+	spec := testtree.Small()
+	cfg := fixtureConfig()
+	cfg.IncludePatterns = []string{"file_000.txt"}
+	result := scanFixture(t, cfg, spec.MapFS())
+
+	// Matching files and the directories holding them
+	kept := make(map[string]bool)
+	for _, entry := range spec.Entries() {
+		if entry.IsDir || path.Base(entry.Path) != "file_000.txt" {
+			continue
+		}
+		for p := entry.Path; p != "."; p = path.Dir(p) {
+			kept[p] = true
+		}
+	}
+	want, dirs, files := expectedPaths(spec, func(e testtree.Entry) bool { return kept[e.Path] })
+	if got := scannedPaths(result); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("scanned %v\nwant %v", got, want)
+	}
+	if result.DirCount != dirs || result.FileCount != files {
+		t.Errorf("counts %d directories, %d files; want %d, %d", result.DirCount, result.FileCount, dirs, files)
+	}
+}
+
+func TestScanFixtureTruncation(t *testing.T) {
+	spec := testtree.Spec{Seed: 3, Depth: 2, FanOut: 4, Files: 6}
+	perDir := spec.FanOut + spec.Files
+	tests := []struct {
+		name  string
+		limit int
+	}{
+		{"no limit", 0},
+		{"limit above the largest directory", perDir + 1},
+		{"limit equal to the largest directory", perDir},
+		{"limit cuts every directory", 3},
+		{"single entry", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := fixtureConfig()
+			cfg.MaxEntriesPerDir = tt.limit
+			result := scanFixture(t, cfg, spec.MapFS())
+
+			cut := tt.limit > 0 && tt.limit < perDir
+			var truncated int
+			var walk func(node *TreeNode)
+			walk = func(node *TreeNode) {
+				if !node.IsDir {
+					return
+				}
+				// Directories above the deepest level hold subdirectories as well as files
+				full := perDir
+				if rel, _ := filepath.Rel(fixtureRoot, node.Path); rel != "." && levels(filepath.ToSlash(rel)) >= spec.Depth {
+					full = spec.Files
+				}
+				want := full
+				if tt.limit > 0 && full > tt.limit {
+					want = tt.limit
+					truncated++
+					if node.Omitted != full-tt.limit || node.TruncateReason != TruncateEntries {
+						t.Errorf("%s: omitted %d (%q), want %d (%q)", node.Path, node.Omitted, node.TruncateReason, full-tt.limit, TruncateEntries)
+					}
+				}
+				if len(node.Children) != want {
+					t.Errorf("%s: %d children, want %d", node.Path, len(node.Children), want)
+				}
+				for _, child := range node.Children {
+					walk(child)
+				}
+			}
+			walk(result.Root)
+
+			if len(result.TruncatedDirs) != truncated {
+				t.Errorf("%d truncated directories listed, want %d", len(result.TruncatedDirs), truncated)
+			}
+			if result.CountsPartial != cut {
+				t.Errorf("CountsPartial = %v, want %v", result.CountsPartial, cut)
+			}
+		})
+	}
+}
+
+func TestScanFixtureSizes(t *testing.T) {
+	spec := testtree.Small()
+	cfg := fixtureConfig()
+	cfg.ShowSize = true
+	result := scanFixture(t, cfg, spec.FS())
+
+	var total int64
+	for _, entry := range spec.Entries() {
+		total += entry.Size
+	}
+	if !result.HasSizes || result.TotalSize != total || result.Root.Size != total {
+		t.Errorf("total size %d (root %d, HasSizes %v), want %d", result.TotalSize, result.Root.Size, result.HasSizes, total)
+	}
+}
+
+func TestScanFixtureSizesSkipHidden(t *testing.T) {
+	spec := testtree.Small()
+	cfg := fixtureConfig()
+	cfg.ShowHidden = false
+	cfg.ShowSize = true
+	result := scanFixture(t, cfg, spec.FS())
+
+	var total int64
+	for _, entry := range spec.Entries() {
+		if visible(entry.Path) {
+			total += entry.Size
+		}
+	}
+	if result.TotalSize != total {
+		t.Errorf("total size %d, want %d of the visible files", result.TotalSize, total)
+	}
+}
+
+// benchSpec is a tree of about 100,000 files: 4,680 directories of 20 files each.
+var benchSpec = testtree.Spec{Seed: 1, Depth: 4, FanOut: 8, Files: 20, MaxSize: 64 << 10}
+
+// benchmarkScan scans the tree at root of fsys, or of the disk when fsys is nil, with each
+// number of concurrent directory reads.
+func benchmarkScan(b *testing.B, fsys fs.FS, root string) {
+	for _, ops := range []struct {
+		name string
+		n    int
+	}{{"sequential", 1}, {"parallel", 5}, {"parallel-16", 16}} {
+		b.Run(ops.name, func(b *testing.B) {
+			cfg := fixtureConfig()
+			cfg.ConcurrentOps = ops.n
+			cfg.MaxEntriesPerDir = 0
+			s := NewFileTreeScanner(cfg)
+			if fsys != nil {
+				s = NewFileTreeScannerFS(cfg, fsys, root)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.ScanDirectory(context.Background(), root); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkScanMemory(b *testing.B) {
+	benchmarkScan(b, benchSpec.FS(), fixtureRoot)
+}
+
+func BenchmarkScanDisk(b *testing.B) {
+	dir := b.TempDir()
+	if err := benchSpec.Materialize(dir); err != nil {
+		b.Fatal(err)
+	}
+	benchmarkScan(b, nil, dir)
+}
+
+func BenchmarkScanSizes(b *testing.B) {
+	fsys := benchSpec.FS()
+	cfg := fixtureConfig()
+	cfg.ShowSize = true
+	cfg.MaxEntriesPerDir = 0
+	s := NewFileTreeScannerFS(cfg, fsys, fixtureRoot)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.ScanDirectory(context.Background(), fixtureRoot); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+// ScriptEnv names the environment variable that switches New to a ScriptedScanner.
+// It is a developer aid for reproducing slow, failing or panicking scans on demand.
+const ScriptEnv = "FILE_TREE_SCANNER_SCRIPT"
+
+// New returns the scanner the application should use: a FileTreeScanner, or a
+// ScriptedScanner driven by the script named in ScriptEnv when that is set.
+func New(cfg *config.Config) FileSystemScanner {
+	path := os.Getenv(ScriptEnv)
+	if path == "" {
+		return NewFileTreeScanner(cfg)
+	}
+	scripted, err := NewScriptedScanner(cfg, path)
+	if err != nil {
+		log.Printf("Warning: ignoring %s: %v", ScriptEnv, err)
+		return NewFileTreeScanner(cfg)
+	}
+	log.Printf("Warning: developer mode, scans follow the script %s", path)
+	return scripted
+}
+
+// scriptStep is one parsed script line.
+type scriptStep struct {
+	line  int
+	op    string
+	arg   string
+	delay time.Duration
+	limit int
+}
+
+// ScriptedScanner wraps a FileTreeScanner and misbehaves as a script tells it to.
+//
+// A script is a list of steps, one per line, run in order on each scan. Lines
+// holding only "---" separate the scripts of successive scans; the last one repeats.
+//
+//	delay 2s        wait, returning early if the scan is cancelled
+//	hang            wait until the scan is cancelled or times out
+//	error <message> fail with message; "canceled" and "timeout" give the context errors
+//	panic <message> panic with message
+//	partial <n>     run the real scan and keep only its first n nodes, marked truncated
+//	scan            run the real scan
+//
+// A script that ends without returning runs the real scan. Blank lines and lines
+// starting with # are ignored.
+type ScriptedScanner struct {
+	*FileTreeScanner
+
+	mu      sync.Mutex
+	scripts [][]scriptStep
+	calls   int
+}
+
+// NewScriptedScanner creates a ScriptedScanner from the script file at path.
+func NewScriptedScanner(cfg *config.Config, path string) (*ScriptedScanner, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scan script: %w", err)
+	}
+	defer file.Close()
+
+	scripts, err := parseScript(bufio.NewScanner(file))
+	if err != nil {
+		return nil, fmt.Errorf("scan script %s: %w", path, err)
+	}
+	return &ScriptedScanner{FileTreeScanner: NewFileTreeScanner(cfg), scripts: scripts}, nil
+}
+
+// parseScript reads script steps, grouped per scan.
+func parseScript(lines *bufio.Scanner) ([][]scriptStep, error) {
+	scripts := [][]scriptStep{nil}
+	for n := 1; lines.Scan(); n++ {
+		text := strings.TrimSpace(lines.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if text == "---" {
+			scripts = append(scripts, nil)
+			continue
+		}
+
+		op, arg, _ := strings.Cut(text, " ")
+		step := scriptStep{line: n, op: op, arg: strings.TrimSpace(arg)}
+		switch op {
+		case "delay":
+			delay, err := time.ParseDuration(step.arg)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			step.delay = delay
+		case "partial":
+			limit, err := strconv.Atoi(step.arg)
+			if err != nil || limit < 1 {
+				return nil, fmt.Errorf("line %d: partial needs a positive node count", n)
+			}
+			step.limit = limit
+		case "hang", "error", "panic", "scan":
+		default:
+			return nil, fmt.Errorf("line %d: unknown step %q", n, op)
+		}
+		scripts[len(scripts)-1] = append(scripts[len(scripts)-1], step)
+	}
+	return scripts, lines.Err()
+}
+
+// ScanDirectory runs the next script.
+func (s *ScriptedScanner) ScanDirectory(ctx context.Context, path string) (*ScanResult, error) {
+	return s.ScanDirectoryWithProgress(ctx, path, nil)
+}
+
+// ScanDirectoryWithProgress runs the next script, passing progress to any real scan it makes.
+func (s *ScriptedScanner) ScanDirectoryWithProgress(ctx context.Context, path string, progress ProgressFunc) (*ScanResult, error) {
+	s.mu.Lock()
+	script := s.scripts[min(s.calls, len(s.scripts)-1)]
+	s.calls++
+	s.mu.Unlock()
+
+	for _, step := range script {
+		log.Printf("Scan script line %d: %s", step.line, strings.TrimSpace(step.op+" "+step.arg))
+		switch step.op {
+		case "delay":
+			select {
+			case <-time.After(step.delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		case "hang":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "error":
+			return nil, scriptError(step.arg)
+		case "panic":
+			panic(step.arg)
+		case "partial":
+			result, err := s.FileTreeScanner.ScanDirectoryWithProgress(ctx, path, progress)
+			if err != nil {
+				return nil, err
+			}
+			result.NodeCount = keepFirst(result.Root, step.limit)
+			result.Truncated = true
+			result.TruncatedReason = ReasonNodeLimit
+			result.TruncatedLimit = fmt.Sprintf("%d nodes", step.limit)
+			return result, nil
+		case "scan":
+			return s.FileTreeScanner.ScanDirectoryWithProgress(ctx, path, progress)
+		}
+	}
+	return s.FileTreeScanner.ScanDirectoryWithProgress(ctx, path, progress)
+}
+
+// ScanDirectories runs the next script for each folder in turn, combining them like
+// FileTreeScanner.ScanDirectories.
+func (s *ScriptedScanner) ScanDirectories(ctx context.Context, paths []string) (*ScanResult, error) {
+	return s.ScanDirectoriesWithProgress(ctx, paths, nil)
+}
+
+// ScanDirectoriesWithProgress runs the next script for each folder in turn, reporting progress
+// like FileTreeScanner.ScanDirectoriesWithProgress.
+func (s *ScriptedScanner) ScanDirectoriesWithProgress(ctx context.Context, paths []string, progress ProgressFunc) (*ScanResult, error) {
+	return scanEach(ctx, paths, progress, s.ScanDirectoryWithProgress)
+}
+
+// scriptError returns the error an "error" step names.
+func scriptError(message string) error {
+	switch message {
+	case "canceled", "cancelled":
+		return context.Canceled
+	case "timeout":
+		return context.DeadlineExceeded
+	case "":
+		return errors.New("scripted failure")
+	}
+	return errors.New(message)
+}
+
+// keepFirst prunes the tree below root to its first limit nodes in depth-first order,
+// returning the number kept.
+func keepFirst(root *TreeNode, limit int) int {
+	kept := 0
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		kept++
+		children := node.Children
+		node.Children = nil
+		for _, child := range children {
+			if kept >= limit {
+				return
+			}
+			node.Children = append(node.Children, child)
+			walk(child)
+		}
+	}
+	walk(root)
+	return kept
+}
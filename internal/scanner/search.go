@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SearchMode is how FindNodes matches its query.
+type SearchMode string
+
+// Search modes.
+const (
+	SearchSubstring SearchMode = "substring" // The query appears anywhere in the text
+	SearchGlob      SearchMode = "glob"      // Wildcards like ExcludePatterns: *, ?, [...] and ** spanning directories
+	SearchRegex     SearchMode = "regex"     // A Go regular expression found anywhere in the text
+)
+
+// SearchOptions adjust FindNodes. The zero value finds entries whose names contain the query,
+// ignoring case.
+type SearchOptions struct {
+	Mode          SearchMode // "" searches for a substring
+	CaseSensitive bool
+	FilesOnly     bool
+	DirsOnly      bool
+	MatchPath     bool // Match the slash-separated path relative to the root instead of the name
+}
+
+// FindNodes returns the entries below root matching query, in tree order. A glob matches the
+// whole name, or with MatchPath a path like "src/**/*.go", anchored at the root when it holds a
+// slash. Invalid queries and options return an error rather than no matches.
+func FindNodes(root *TreeNode, query string, opts SearchOptions) ([]*TreeNode, error) {
+	match, err := compileSearch(query, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.FilesOnly && opts.DirsOnly {
+		return nil, fmt.Errorf("search for files only or directories only, not both")
+	}
+	if root == nil {
+		return nil, nil
+	}
+
+	var found []*TreeNode
+	var walk func(node *TreeNode, rel string)
+	walk = func(node *TreeNode, rel string) {
+		for _, child := range node.Children {
+			childRel := child.Name
+			if rel != "" {
+				childRel = rel + "/" + child.Name
+			}
+			text := child.Name
+			if opts.MatchPath {
+				text = childRel
+			}
+			if !(opts.FilesOnly && child.IsDir) && !(opts.DirsOnly && !child.IsDir) && match(text) {
+				found = append(found, child)
+			}
+			walk(child, childRel)
+		}
+	}
+	walk(root, "")
+	return found, nil
+}
+
+// compileSearch returns the function matching the text of an entry against query.
+func compileSearch(query string, opts SearchOptions) (func(string) bool, error) {
+	if query == "" {
+		return nil, fmt.Errorf("empty search query")
+	}
+	switch opts.Mode {
+	case "", SearchSubstring:
+		if opts.CaseSensitive {
+			return func(text string) bool { return strings.Contains(text, query) }, nil
+		}
+		query = strings.ToLower(query)
+		return func(text string) bool { return strings.Contains(strings.ToLower(text), query) }, nil
+	case SearchGlob:
+		pattern := filepath.ToSlash(query)
+		if strings.Count(pattern, "[") != strings.Count(pattern, "]") {
+			return nil, fmt.Errorf("invalid glob %q: unbalanced brackets", query)
+		}
+		return compileSearchExpr(gitPatternExpr(pattern), query, "glob", opts)
+	case SearchRegex:
+		return compileSearchExpr(query, query, "regular expression", opts)
+	}
+	return nil, fmt.Errorf("unknown search mode %q", opts.Mode)
+}
+
+// compileSearchExpr compiles the regular expression expr, made from query, ignoring case unless
+// opts say otherwise.
+func compileSearchExpr(expr, query, what string, opts SearchOptions) (func(string) bool, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", what, query, err)
+	}
+	if !opts.CaseSensitive {
+		re = regexp.MustCompile("(?i)" + expr) // Valid once expr is
+	}
+	return re.MatchString, nil
+}
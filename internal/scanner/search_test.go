@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// searchFixture scans a small project with names differing only in case and a nested src
+// folder. Its tree lists directories first.
+func searchFixture(t *testing.T) *TreeNode {
+	file := &fstest.MapFile{Data: []byte("x"), Mode: 0o644}
+	return scanFixture(t, fixtureConfig(), fstest.MapFS{
+		"Makefile":            file,
+		"README.md":           file,
+		"docs/guide.md":       file,
+		"docs/src/index.md":   file,
+		"src/Main_test.go":    file,
+		"src/main.go":         file,
+		"src/util/strings.go": file,
+	}).Root
+}
+
+// foundPaths returns the slash-separated paths of nodes relative to the fixture root.
+func foundPaths(nodes []*TreeNode) []string {
+	var paths []string
+	for _, node := range nodes {
+		rel, _ := filepath.Rel(fixtureRoot, node.Path)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+func TestFindNodes(t *testing.T) {
+	root := searchFixture(t)
+	tests := []struct {
+		name  string
+		query string
+		opts  SearchOptions
+		want  []string
+	}{
+		{"substring ignores case", "main", SearchOptions{}, []string{"src/main.go", "src/Main_test.go"}},
+		{"substring with case", "Main", SearchOptions{CaseSensitive: true}, []string{"src/Main_test.go"}},
+		{"substring of names only", "docs", SearchOptions{}, []string{"docs"}},
+		{"substring of paths", "docs/", SearchOptions{MatchPath: true}, []string{"docs/src", "docs/src/index.md", "docs/guide.md"}},
+		{"glob of whole names", "*.go", SearchOptions{Mode: SearchGlob}, []string{"src/util/strings.go", "src/main.go", "src/Main_test.go"}},
+		{"glob not found within names", "ain", SearchOptions{Mode: SearchGlob}, nil},
+		{"glob with case", "main*", SearchOptions{Mode: SearchGlob, CaseSensitive: true}, []string{"src/main.go"}},
+		{"glob of paths anchored at the root", "src/**/*.go", SearchOptions{Mode: SearchGlob, MatchPath: true}, []string{"src/util/strings.go", "src/main.go", "src/Main_test.go"}},
+		{"glob of names at any depth", "src", SearchOptions{Mode: SearchGlob}, []string{"docs/src", "src"}},
+		{"regex ignores case", `^m`, SearchOptions{Mode: SearchRegex}, []string{"src/main.go", "src/Main_test.go", "Makefile"}},
+		{"regex with case", `^[A-Z]`, SearchOptions{Mode: SearchRegex, CaseSensitive: true}, []string{"src/Main_test.go", "Makefile", "README.md"}},
+		{"regex of paths", `^src/.*\.go$`, SearchOptions{Mode: SearchRegex, MatchPath: true}, []string{"src/util/strings.go", "src/main.go", "src/Main_test.go"}},
+		{"files only", "src", SearchOptions{MatchPath: true, FilesOnly: true}, []string{"docs/src/index.md", "src/util/strings.go", "src/main.go", "src/Main_test.go"}},
+		{"directories only", "s", SearchOptions{DirsOnly: true}, []string{"docs", "docs/src", "src"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := FindNodes(root, tt.query, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := foundPaths(found); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FindNodes(%q, %+v) = %q, want %q", tt.query, tt.opts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindNodesTreeOrder(t *testing.T) {
+	root := searchFixture(t)
+	var all []string
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		for _, child := range node.Children {
+			all = append(all, child.Path)
+			walk(child)
+		}
+	}
+	walk(root)
+
+	found, err := FindNodes(root, ".", SearchOptions{Mode: SearchRegex})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, node := range found {
+		got = append(got, node.Path)
+	}
+	if strings.Join(got, ",") != strings.Join(all, ",") {
+		t.Errorf("found %q, want the tree's order %q", got, all)
+	}
+}
+
+func TestFindNodesErrors(t *testing.T) {
+	root := searchFixture(t)
+	tests := []struct {
+		name  string
+		query string
+		opts  SearchOptions
+	}{
+		{"invalid regex", "(", SearchOptions{Mode: SearchRegex}},
+		{"invalid regex ignoring case", "a[", SearchOptions{Mode: SearchRegex}},
+		{"unbalanced glob", "[a", SearchOptions{Mode: SearchGlob}},
+		{"empty query", "", SearchOptions{}},
+		{"unknown mode", "a", SearchOptions{Mode: "fuzzy"}},
+		{"files and directories only", "a", SearchOptions{FilesOnly: true, DirsOnly: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if found, err := FindNodes(root, tt.query, tt.opts); err == nil {
+				t.Errorf("FindNodes(%q, %+v) = %d entries, want an error", tt.query, tt.opts, len(found))
+			}
+		})
+	}
+}
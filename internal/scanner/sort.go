@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+// SortOrder says how the entries of a directory are ordered: directories first, then files,
+// each by the key By, with ties going by name.
+type SortOrder struct {
+	By         string // config.SortByName, SortBySize, SortByModTime or SortByExtension
+	Descending bool   // Reverse the key By; ties still go by ascending name
+	Natural    bool   // Compare names in natural order (see NaturalLess) rather than by byte value
+	SizeBasis  string // Size compared with SortBySize: config.SizeApparent (default) or config.SizeAllocated
+}
+
+// OrderOf returns the sort order cfg asks for.
+func OrderOf(cfg *config.Config) SortOrder {
+	return SortOrder{By: cfg.SortBy, Descending: cfg.SortDescending, Natural: cfg.NaturalSort, SizeBasis: cfg.SizeBasis}
+}
+
+// ValidateSortBy reports a sort key SortOrder does not know. An empty key sorts by name.
+func ValidateSortBy(by string) error {
+	switch by {
+	case "", config.SortByName, config.SortBySize, config.SortByModTime, config.SortByExtension:
+		return nil
+	}
+	return fmt.Errorf("unknown sort key %q: use name, size, time or extension", by)
+}
+
+// less reports whether a comes before b.
+func (o SortOrder) less(a, b *TreeNode) bool {
+	if a.IsDir != b.IsDir {
+		return a.IsDir
+	}
+	if c := o.compare(a, b); c != 0 {
+		if o.Descending {
+			return c > 0
+		}
+		return c < 0
+	}
+	return o.compareNames(a.Name, b.Name) < 0
+}
+
+// compare compares a and b by the key By alone.
+func (o SortOrder) compare(a, b *TreeNode) int {
+	switch o.By {
+	case config.SortBySize:
+		return compareInts(a.SizeFor(o.SizeBasis), b.SizeFor(o.SizeBasis))
+	case config.SortByModTime:
+		return a.ModTime.Compare(b.ModTime)
+	case config.SortByExtension:
+		if a.IsDir {
+			return o.compareNames(a.Name, b.Name)
+		}
+		return strings.Compare(strings.ToLower(filepath.Ext(a.Name)), strings.ToLower(filepath.Ext(b.Name)))
+	default:
+		return o.compareNames(a.Name, b.Name)
+	}
+}
+
+// compareNames compares two names in natural or byte order.
+func (o SortOrder) compareNames(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case o.Natural && NaturalLess(a, b), !o.Natural && a < b:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// compareInts compares two sizes.
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// SortTree sorts the children of every node below root with directories first, then files,
+// each in natural order (see NaturalLess).
+func SortTree(root *TreeNode) {
+	SortTreeBy(root, SortOrder{By: config.SortByName, Natural: true})
+}
+
+// SortTreeBy sorts the children of every node below root in order, using what the nodes hold:
+// sizes and times sort only where they were collected, directories by size only once summed.
+// It lets a shown tree be sorted again without scanning it.
+func SortTreeBy(root *TreeNode, order SortOrder) {
+	if root == nil {
+		return
+	}
+	sort.SliceStable(root.Children, func(i, j int) bool {
+		return order.less(root.Children[i], root.Children[j])
+	})
+	for _, child := range root.Children {
+		SortTreeBy(child, order)
+	}
+}
+
+// entrySorter sorts directory entries along with the nodes standing in for them.
+type entrySorter struct {
+	entries []os.DirEntry
+	keys    []*TreeNode
+	order   SortOrder
+}
+
+func (s entrySorter) Len() int           { return len(s.entries) }
+func (s entrySorter) Less(i, j int) bool { return s.order.less(s.keys[i], s.keys[j]) }
+func (s entrySorter) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// sortEntries sorts the entries of dir in the order Config asks for. Sorting by size or time
+// reads each entry's FileInfo; directories have no size yet and go by name until
+// ScanDirectoryWithProgress sorts them again once their sizes are summed.
+func (s *FileTreeScanner) sortEntries(dir string, entries []os.DirEntry) {
+	order := OrderOf(s.config)
+	needInfo := order.By == config.SortBySize || order.By == config.SortByModTime
+	keys := make([]*TreeNode, len(entries))
+	for i, entry := range entries {
+		keys[i] = &TreeNode{Name: entry.Name(), IsDir: entry.IsDir()}
+		if !needInfo || (entry.IsDir() && order.By == config.SortBySize) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			keys[i].ModTime = info.ModTime()
+			if order.By == config.SortBySize {
+				keys[i].Size, keys[i].DiskSize = fileSizes(filepath.Join(dir, entry.Name()), info)
+			}
+		}
+	}
+	sort.Sort(entrySorter{entries: entries, keys: keys, order: order})
+}
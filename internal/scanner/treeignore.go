@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// TreeIgnoreRule is the filter rule name under which .treeignore exclusions are counted.
+const TreeIgnoreRule = "treeignore"
+
+// TreeIgnoreFile is the file at the scan root whose patterns are excluded like
+// Config.ExcludePatterns, with Config.TreeIgnore.
+const TreeIgnoreFile = ".treeignore"
+
+// treeIgnoreRule excludes the entries matched by the .treeignore file at the scan root, and the
+// file itself. Lines it could not use are kept as problems for ScanResult.Errors.
+type treeIgnoreRule struct {
+	excludePatternRule
+	file     string
+	problems []ScanError
+}
+
+// newTreeIgnoreRule reads the .treeignore file at root: one pattern per line, as in
+// Config.ExcludePatterns, with blank lines and lines starting with # skipped.
+func newTreeIgnoreRule(files fileSystem, root string) *treeIgnoreRule {
+	root = filepath.Clean(root)
+	rule := &treeIgnoreRule{
+		excludePatternRule: excludePatternRule{root: root, name: TreeIgnoreRule},
+		file:               filepath.Join(root, TreeIgnoreFile),
+	}
+	file, err := files.Open(rule.file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return rule
+	}
+	if err != nil {
+		rule.problem(err)
+		return rule
+	}
+	defer file.Close()
+
+	lines := bufio.NewScanner(file)
+	for n := 1; lines.Scan(); n++ {
+		text := strings.TrimSpace(lines.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		p, err := compileGlobPattern(text)
+		if err != nil {
+			rule.problem(fmt.Errorf("line %d: %w", n, err))
+			continue
+		}
+		p.source = fmt.Sprintf("%s:%d", rule.file, n)
+		rule.patterns = append(rule.patterns, p)
+	}
+	if err := lines.Err(); err != nil {
+		rule.problem(err)
+	}
+	return rule
+}
+
+// problem records a failure to read or use the file.
+func (r *treeIgnoreRule) problem(err error) {
+	r.problems = append(r.problems, ScanError{Path: r.file, Op: ScanOpParse, Err: err})
+}
+
+// Match implements FilterRule, leaving out the file itself as well.
+func (r *treeIgnoreRule) Match(entry EntryInfo) (RuleMatch, bool) {
+	if !entry.IsDir && filepath.Clean(entry.Path) == r.file {
+		return RuleMatch{Rule: r.Name(), Pattern: TreeIgnoreFile}, true
+	}
+	return r.excludePatternRule.Match(entry)
+}
+
+// treeIgnoreProblems returns the problems of the .treeignore rule among rules, if any.
+func treeIgnoreProblems(rules []FilterRule) []ScanError {
+	for _, rule := range rules {
+		if r, ok := rule.(*treeIgnoreRule); ok {
+			return r.problems
+		}
+	}
+	return nil
+}
@@ -0,0 +1,438 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/applog"
+)
+
+// ChangeOp says how an entry below a watched directory changed.
+type ChangeOp string
+
+// Changes reported by WatchDirectory.
+const (
+	ChangeAdded    ChangeOp = "added"
+	ChangeRemoved  ChangeOp = "removed"
+	ChangeModified ChangeOp = "modified" // Content written, or permissions changed with Config.CollectMode
+)
+
+// TreeChange is one change to an entry below a watched directory.
+type TreeChange struct {
+	Op    ChangeOp
+	Path  string
+	IsDir bool // For removals, only known of directories that were watched
+}
+
+// watchBuffer is how many changes may wait for the receiver before the watcher holds back.
+const watchBuffer = 256
+
+// WatchingScanner is implemented by scanners that can keep a scanned tree up to date.
+type WatchingScanner interface {
+	FileSystemScanner
+	WatchDirectory(ctx context.Context, path string) (<-chan TreeChange, error)
+	ApplyChanges(ctx context.Context, result *ScanResult, changes []TreeChange) []*TreeNode
+}
+
+// dirWatcher turns the file system notifications for a watched directory into tree changes.
+// Its fields are only used by the goroutine running it once WatchDirectory returns.
+type dirWatcher struct {
+	s       *FileTreeScanner
+	root    string
+	filters filterPipeline
+	watcher *fsnotify.Watcher
+	dirs    map[string]bool // Directories being watched
+	changes chan TreeChange
+}
+
+// WatchDirectory watches path, a directory on disk, and sends the changes to the entries below
+// it until ctx is done, when the channel is closed. Only directories a scan would read are
+// watched: those within Config.MaxDepth and Config.HardDepthLimit and not left out by the
+// filters, including those of ctx as for ScanDirectory. Directories created later are watched
+// as they appear, and the entries found in them are sent as added, since they may have been
+// created before the watch. Notifications come as fast as the file system makes them, so
+// receivers showing them should batch them.
+func (s *FileTreeScanner) WatchDirectory(ctx context.Context, path string) (<-chan TreeChange, error) {
+	if _, ok := s.files.(diskFileSystem); !ok {
+		return nil, fmt.Errorf("cannot watch %q: only folders on disk can be watched", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path %q: %w", path, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path %q is not a directory", path)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch %q: %w", path, err)
+	}
+	if err := watcher.Add(path); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch %q: %w", path, err)
+	}
+	filters := s.scanFilters(ctx, path)
+	w := &dirWatcher{
+		s:       s,
+		root:    path,
+		filters: filters,
+		watcher: watcher,
+		dirs:    map[string]bool{path: true},
+		changes: make(chan TreeChange, watchBuffer),
+	}
+	w.watchEntries(ctx, path, 0, false)
+	go w.run(ctx)
+	return w.changes, nil
+}
+
+// run handles notifications until ctx is done or the watcher fails.
+func (w *dirWatcher) run(ctx context.Context) {
+	defer close(w.changes)
+	defer w.watcher.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok || !w.handle(ctx, event) {
+				return
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Warning: watching %q: %v", w.root, err)
+		}
+	}
+}
+
+// handle sends the change a notification stands for, returning false once ctx is done.
+func (w *dirWatcher) handle(ctx context.Context, event fsnotify.Event) bool {
+	path := event.Name
+	if path == w.root {
+		return true // The folder itself going away is noticed when it is shown
+	}
+	switch {
+	case event.Has(fsnotify.Create):
+		info, err := os.Lstat(path)
+		if err != nil || w.excluded(path, info.IsDir()) {
+			return true // Gone again, or left out
+		}
+		if !w.send(ctx, TreeChange{Op: ChangeAdded, Path: path, IsDir: info.IsDir()}) {
+			return false
+		}
+		if depth := pathDepth(w.root, path); info.IsDir() && w.s.readsDepth(depth) {
+			return w.watchTree(ctx, path, depth, true)
+		}
+	case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+		// A renamed entry is created again under its new name, if that is below the root
+		isDir := w.dirs[path]
+		w.unwatch(path)
+		if !w.excluded(path, isDir) {
+			return w.send(ctx, TreeChange{Op: ChangeRemoved, Path: path, IsDir: isDir})
+		}
+	case event.Has(fsnotify.Write) || (event.Has(fsnotify.Chmod) && w.s.config.CollectMode):
+		if isDir := w.dirs[path]; !w.excluded(path, isDir) {
+			return w.send(ctx, TreeChange{Op: ChangeModified, Path: path, IsDir: isDir})
+		}
+	}
+	return true
+}
+
+// watchTree watches dir, at depth below the root, and the directories below it, returning
+// false once ctx is done. With announce, the entries found are sent as added.
+func (w *dirWatcher) watchTree(ctx context.Context, dir string, depth int, announce bool) bool {
+	if err := w.watcher.Add(dir); err != nil {
+		log.Printf("Warning: cannot watch %q: %v", dir, err)
+		return true
+	}
+	w.dirs[dir] = true
+	return w.watchEntries(ctx, dir, depth, announce)
+}
+
+// watchEntries watches the directories in dir, which is at depth and watched itself, that a
+// scan would read, returning false once ctx is done. With announce, the entries found are sent
+// as added.
+func (w *dirWatcher) watchEntries(ctx context.Context, dir string, depth int, announce bool) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return true
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if w.excluded(path, entry.IsDir()) {
+			continue
+		}
+		if announce && !w.send(ctx, TreeChange{Op: ChangeAdded, Path: path, IsDir: entry.IsDir()}) {
+			return false
+		}
+		if entry.IsDir() && w.s.readsDepth(depth+1) && !w.watchTree(ctx, path, depth+1, announce) {
+			return false
+		}
+	}
+	return true
+}
+
+// unwatch stops watching path and the directories below it.
+func (w *dirWatcher) unwatch(path string) {
+	prefix := path + string(filepath.Separator)
+	for dir := range w.dirs {
+		if dir == path || strings.HasPrefix(dir, prefix) {
+			w.watcher.Remove(dir) // Fails for directories already gone, which are no longer watched
+			delete(w.dirs, dir)
+		}
+	}
+}
+
+// excluded reports whether the filters leave the entry at path out.
+func (w *dirWatcher) excluded(path string, isDir bool) bool {
+	_, excluded := w.filters.excluded(EntryInfo{Path: path, Name: filepath.Base(path), IsDir: isDir})
+	return excluded
+}
+
+// send queues change for the receiver, returning false once ctx is done.
+func (w *dirWatcher) send(ctx context.Context, change TreeChange) bool {
+	select {
+	case w.changes <- change:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// readsDepth reports whether a scan reads directories at depth below its root.
+func (s *FileTreeScanner) readsDepth(depth int) bool {
+	return (s.config.MaxDepth < 0 || depth <= s.config.MaxDepth) &&
+		(s.config.HardDepthLimit <= 0 || depth <= s.config.HardDepthLimit)
+}
+
+// pathDepth returns how many levels path lies below root, which must contain it.
+func pathDepth(root, path string) int {
+	rel, _ := relSlash(root, path)
+	return strings.Count(rel, "/") + 1
+}
+
+// ApplyChanges updates result, a scan of a single directory, with changes from WatchDirectory:
+// entries are added, removed and read again as the scan would read them, and the sizes, token
+// estimates, counts, totals, languages and duplicates of the result are worked out again. Added
+// directories start out empty, as their entries come as changes of their own. Changes outside
+// the tree, or below directories it does not read, are ignored. TreeText is left as it is.
+//
+// It returns the nodes whose rows changed, each once: directories whose entries were added or
+// removed, and entries read again. The directories above them changed too when sizes or tokens
+// are shown.
+func (s *FileTreeScanner) ApplyChanges(ctx context.Context, result *ScanResult, changes []TreeChange) []*TreeNode {
+	root := result.Root
+	if root == nil || root.IsVirtual {
+		return nil
+	}
+	state := &scanState{source: s.files, warnings: applog.NewSampler(sampledWarnings)}
+	include := newIncludeFilter(result.RootPath, s.config.IncludePatterns)
+
+	var changed []*TreeNode
+	regrouped := make(map[*TreeNode]bool) // Directories whose entries changed
+	mark := func(node *TreeNode) {
+		if !slices.Contains(changed, node) {
+			changed = append(changed, node)
+		}
+	}
+	for _, change := range changes {
+		parent := nodeAt(root, filepath.Dir(change.Path))
+		if parent == nil && change.Op == ChangeAdded && include != nil && !change.IsDir && include.includes(change.Path) {
+			// Directories only appear with included files in them
+			parent = s.addDirs(state, result, filepath.Dir(change.Path), regrouped, mark)
+		}
+		if parent == nil || !parent.IsDir || parent.NotRead || parent.Truncated || parent.MountPoint {
+			continue
+		}
+		node := childNamed(parent, filepath.Base(change.Path))
+
+		if change.Op == ChangeRemoved {
+			if node != nil {
+				parent.Children = slices.DeleteFunc(parent.Children, func(child *TreeNode) bool { return child == node })
+				result.NodeCount -= countNodes(node)
+				regrouped[parent] = true
+				mark(parent)
+			}
+			continue
+		}
+		info, err := s.files.Lstat(change.Path)
+		if err != nil {
+			continue // Gone again; its removal follows
+		}
+		if node == nil {
+			if change.Op != ChangeAdded || (include != nil && (info.IsDir() || !include.includes(change.Path))) {
+				continue // Not in the tree, or pruned from it
+			}
+			node = s.addNode(state, parent, change.Path, info, pathDepth(result.RootPath, change.Path))
+			result.NodeCount++
+			regrouped[parent] = true
+			mark(parent)
+		} else {
+			s.readNode(state, node, info)
+			mark(node)
+		}
+		if !node.IsDir {
+			s.measureNode(ctx, state, node)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	order := OrderOf(s.config)
+	for dir := range regrouped {
+		if s.config.SortDirs {
+			sort.SliceStable(dir.Children, func(i, j int) bool { return order.less(dir.Children[i], dir.Children[j]) })
+		}
+	}
+	if result.HasSizes {
+		for _, node := range changed {
+			if regrouped[node] {
+				sumSizes(node)
+			}
+			for dir := node.Parent; dir != nil; dir = dir.Parent {
+				sumSizes(dir)
+			}
+		}
+	}
+	if latest := state.latest; latest != nil && (result.Latest == nil || latest.ModTime.After(result.Latest.ModTime)) {
+		result.Latest = latest
+	}
+	Tally(result)
+	NormalizeLinks(root)
+	result.TotalSize = root.Size
+	if result.HasTokens {
+		result.TotalTokens = SumTokens(root)
+	}
+	if result.HasHashes {
+		result.Duplicates = FindDuplicates(root)
+	}
+	if result.DuplicateDirMin > 0 {
+		result.DuplicateDirs = FindDuplicateDirs(root, result.DuplicateDirMin)
+	}
+	return changed
+}
+
+// addNode adds the node for the entry at path, at depth below the root, to parent, and returns it.
+func (s *FileTreeScanner) addNode(state *scanState, parent *TreeNode, path string, info fs.FileInfo, depth int) *TreeNode {
+	node := &TreeNode{
+		Path:         path,
+		Name:         filepath.Base(path),
+		IsDir:        info.IsDir(),
+		IsSymlink:    info.Mode()&fs.ModeSymlink != 0,
+		ExportIgnore: parent.ExportIgnore,
+		Parent:       parent,
+	}
+	if node.IsSymlink {
+		s.resolveLink(node)
+		if parent.realPath == "" {
+			if realPath, err := s.files.EvalSymlinks(parent.Path); err == nil {
+				parent.realPath = realPath
+			}
+		}
+	}
+	if !node.IsDir {
+		node.Kind = Classify(node.Name, s.config.FileKinds)
+	} else if !s.readsDepth(depth) {
+		node.NotRead = true
+		node.TruncateReason = TruncateDepth
+		node.SizeUnknown = s.config.ShowSize
+	}
+	s.readNode(state, node, info)
+	parent.Children = append(parent.Children, node)
+	return node
+}
+
+// addDirs adds the directories missing from the tree down to dir, which lies below the root,
+// and returns dir's node, or nil where the tree does not read that far.
+func (s *FileTreeScanner) addDirs(state *scanState, result *ScanResult, dir string, regrouped map[*TreeNode]bool, mark func(*TreeNode)) *TreeNode {
+	if node := nodeAt(result.Root, dir); node != nil {
+		return node
+	}
+	depth := pathDepth(result.RootPath, dir)
+	if depth < 1 {
+		return nil
+	}
+	parent := s.addDirs(state, result, filepath.Dir(dir), regrouped, mark)
+	if parent == nil || !parent.IsDir || parent.NotRead || parent.Truncated || parent.MountPoint {
+		return nil
+	}
+	info, err := s.files.Lstat(dir)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+	node := s.addNode(state, parent, dir, info, depth)
+	result.NodeCount++
+	regrouped[parent] = true
+	mark(parent)
+	return node
+}
+
+// readNode fills what a scan collects about node from info, as for an entry of a directory.
+func (s *FileTreeScanner) readNode(state *scanState, node *TreeNode, info fs.FileInfo) {
+	if s.config.CollectTimes || s.config.CollectMode || ((s.config.ShowSize || s.config.MarkExecutables) && !node.IsDir) {
+		s.collectInfo(state, node, fs.FileInfoToDirEntry(info))
+	}
+	if s.config.CollectXattrs {
+		s.collectXattrs(state, node)
+	}
+}
+
+// measureNode hashes, counts and estimates the contents of a file again, as configured.
+func (s *FileTreeScanner) measureNode(ctx context.Context, state *scanState, node *TreeNode) {
+	node.Hash, node.Lines, node.Tokens = "", 0, 0
+	if s.config.ComputeHashes {
+		s.hashFile(ctx, state, node)
+	}
+	if s.config.CountLines || s.config.EstimateTokens {
+		s.measureText(ctx, state, node)
+	}
+}
+
+// nodeAt returns the node for path in the tree below root, or nil if the tree has none.
+func nodeAt(root *TreeNode, path string) *TreeNode {
+	if path == root.Path {
+		return root
+	}
+	rel, ok := relSlash(root.Path, path)
+	if !ok {
+		return nil
+	}
+	node := root
+	for _, name := range strings.Split(rel, "/") {
+		if node = childNamed(node, name); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// childNamed returns the child of node called name, or nil.
+func childNamed(node *TreeNode, name string) *TreeNode {
+	for _, child := range node.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// countNodes returns the number of nodes in the tree rooted at node.
+func countNodes(node *TreeNode) int {
+	count := 1
+	for _, child := range node.Children {
+		count += countNodes(child)
+	}
+	return count
+}
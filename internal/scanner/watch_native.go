@@ -0,0 +1,9 @@
+//go:build darwin || dragonfly || freebsd || openbsd || linux || netbsd || solaris || illumos || windows
+
+package scanner
+
+import "github.com/Akaiko1/file-tree-scanner/internal/capability"
+
+func init() {
+	capability.Register(capability.Watch)
+}
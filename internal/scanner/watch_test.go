@@ -0,0 +1,174 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+// watchWait bounds how long a watch may take to report a change.
+const watchWait = 5 * time.Second
+
+// watchFolder creates the slash-separated files and, with a trailing slash, directories of
+// entries in a temporary folder and returns it.
+func watchFolder(t *testing.T, entries ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, entry := range entries {
+		path := filepath.Join(root, filepath.FromSlash(entry))
+		if strings.HasSuffix(entry, "/") {
+			mkdir(t, path)
+		} else {
+			mkdir(t, filepath.Dir(path))
+			writeFile(t, path, "x")
+		}
+	}
+	return root
+}
+
+func mkdir(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startWatch watches root with cfg until the test ends.
+func startWatch(t *testing.T, cfg *config.Config, root string) <-chan TreeChange {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	changes, err := NewFileTreeScanner(cfg).WatchDirectory(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return changes
+}
+
+// expectChanges receives changes until each of want has come, and returns all it received.
+// Paths in want are slash-separated below root.
+func expectChanges(t *testing.T, changes <-chan TreeChange, root string, want ...TreeChange) []TreeChange {
+	t.Helper()
+	missing := make(map[TreeChange]bool)
+	for _, change := range want {
+		change.Path = filepath.Join(root, filepath.FromSlash(change.Path))
+		missing[change] = true
+	}
+	var received []TreeChange
+	timeout := time.After(watchWait)
+	for len(missing) > 0 {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				t.Fatalf("watch ended before %v came", missing)
+			}
+			received = append(received, change)
+			delete(missing, change)
+		case <-timeout:
+			t.Fatalf("no %v after %v; received %v", missing, watchWait, received)
+		}
+	}
+	return received
+}
+
+func TestWatchChanges(t *testing.T) {
+	root := watchFolder(t, "a.txt", "sub/b.txt")
+	changes := startWatch(t, fixtureConfig(), root)
+	path := func(rel string) string { return filepath.Join(root, filepath.FromSlash(rel)) }
+
+	writeFile(t, path("new.txt"), "x")
+	expectChanges(t, changes, root, TreeChange{Op: ChangeAdded, Path: "new.txt"})
+
+	writeFile(t, path("..cache"), "x") // Below the root despite its leading dots
+	expectChanges(t, changes, root, TreeChange{Op: ChangeAdded, Path: "..cache"})
+
+	writeFile(t, path("a.txt"), "changed")
+	expectChanges(t, changes, root, TreeChange{Op: ChangeModified, Path: "a.txt"})
+
+	if err := os.Rename(path("new.txt"), path("sub/renamed.txt")); err != nil {
+		t.Fatal(err)
+	}
+	expectChanges(t, changes, root,
+		TreeChange{Op: ChangeRemoved, Path: "new.txt"},
+		TreeChange{Op: ChangeAdded, Path: "sub/renamed.txt"})
+
+	// Directories created later are watched, with what they hold sent as added
+	mkdir(t, path("sub/inner"))
+	expectChanges(t, changes, root, TreeChange{Op: ChangeAdded, Path: "sub/inner", IsDir: true})
+	writeFile(t, path("sub/inner/c.txt"), "x")
+	expectChanges(t, changes, root, TreeChange{Op: ChangeAdded, Path: "sub/inner/c.txt"})
+
+	if err := os.Remove(path("a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	expectChanges(t, changes, root, TreeChange{Op: ChangeRemoved, Path: "a.txt"})
+
+	if err := os.RemoveAll(path("sub")); err != nil {
+		t.Fatal(err)
+	}
+	expectChanges(t, changes, root, TreeChange{Op: ChangeRemoved, Path: "sub", IsDir: true})
+}
+
+func TestWatchSkipsUnreadDirectories(t *testing.T) {
+	root := watchFolder(t, "build/", "a/b/", "logs/")
+	cfg := fixtureConfig()
+	cfg.MaxDepth = 1
+	cfg.ExcludePatterns = []string{"build/", "*.log"}
+	changes := startWatch(t, cfg, root)
+
+	writeFile(t, filepath.Join(root, "build", "out.o"), "x")     // Excluded directory
+	writeFile(t, filepath.Join(root, "a", "b", "deep.txt"), "x") // Below MaxDepth
+	writeFile(t, filepath.Join(root, "logs", "app.log"), "x")    // Excluded file
+	writeFile(t, filepath.Join(root, "a", "shown.txt"), "x")
+	writeFile(t, filepath.Join(root, "done.txt"), "x")
+	received := expectChanges(t, changes, root,
+		TreeChange{Op: ChangeAdded, Path: "a/shown.txt"},
+		TreeChange{Op: ChangeAdded, Path: "done.txt"})
+	for _, change := range received {
+		rel, _ := filepath.Rel(root, change.Path)
+		if rel = filepath.ToSlash(rel); strings.HasPrefix(rel, "build/") || strings.HasPrefix(rel, "a/b/") || strings.HasSuffix(rel, ".log") {
+			t.Errorf("change %v below a directory that is not watched, or of an excluded entry", change)
+		}
+	}
+}
+
+func TestWatchEndsWithContext(t *testing.T) {
+	root := watchFolder(t, "a.txt")
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := NewFileTreeScanner(fixtureConfig()).WatchDirectory(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	timeout := time.After(watchWait)
+	for {
+		select {
+		case _, ok := <-changes:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("changes still open %v after cancelling", watchWait)
+		}
+	}
+}
+
+func TestWatchNeedsDisk(t *testing.T) {
+	fsys := fstest.MapFS{"a.txt": {Data: []byte("x")}}
+	if _, err := NewFileTreeScannerFS(fixtureConfig(), fsys, fixtureRoot).WatchDirectory(context.Background(), fixtureRoot); err == nil {
+		t.Error("watching a folder that is not on disk succeeded")
+	}
+}
@@ -0,0 +1,69 @@
+//go:build linux
+
+package scanner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// writeXattrFixture creates a folder holding tagged.txt, which has the attribute user.origin,
+// and plain.txt and empty/, which have none, skipping the test where the file system keeps no
+// user attributes.
+func writeXattrFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	tagged := filepath.Join(dir, "tagged.txt")
+	for _, name := range []string{tagged, filepath.Join(dir, "plain.txt")} {
+		if err := os.WriteFile(name, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Setxattr(tagged, "user.origin", []byte("download"), 0); err != nil {
+		if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EPERM) {
+			t.Skipf("the file system keeps no user attributes: %v", err)
+		}
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestXattrsListed(t *testing.T) {
+	dir := writeXattrFixture(t)
+	cfg := fixtureConfig()
+	cfg.CollectXattrs = true
+	result, err := NewFileTreeScanner(cfg).ScanDirectory(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.HasXattrs {
+		t.Error("result does not say attributes were listed")
+	}
+	for _, child := range result.Root.Children {
+		want := child.Name == "tagged.txt"
+		if got := slices.Contains(child.Xattrs, "user.origin"); got != want {
+			t.Errorf("%s has attributes %q, want user.origin: %v", child.Name, child.Xattrs, want)
+		}
+	}
+
+	// Nothing is listed unless asked for
+	cfg.CollectXattrs = false
+	result, err = NewFileTreeScanner(cfg).ScanDirectory(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, child := range result.Root.Children {
+		if len(child.Xattrs) > 0 {
+			t.Errorf("%s has attributes %q without CollectXattrs", child.Name, child.Xattrs)
+		}
+	}
+}
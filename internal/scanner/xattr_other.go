@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package scanner
+
+// listXattrs is not available on this platform; files are reported without attributes.
+func listXattrs(path string) ([]string, error) {
+	return nil, nil
+}
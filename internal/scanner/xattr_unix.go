@@ -0,0 +1,54 @@
+//go:build linux || darwin
+
+package scanner
+
+import (
+	"bytes"
+	"errors"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.Xattrs)
+}
+
+// listXattrs returns the names of the extended attributes of the file at path, without
+// following a final symbolic link. File systems without extended attributes have none.
+func listXattrs(path string) ([]string, error) {
+	for {
+		size, err := unix.Llistxattr(path, nil)
+		if err != nil {
+			return nil, xattrError(err)
+		}
+		if size == 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size)
+		size, err = unix.Llistxattr(path, buf)
+		if errors.Is(err, unix.ERANGE) {
+			continue // Attributes were added between the two calls
+		}
+		if err != nil {
+			return nil, xattrError(err)
+		}
+
+		var names []string
+		for _, name := range bytes.Split(buf[:size], []byte{0}) {
+			if len(name) > 0 {
+				names = append(names, string(name))
+			}
+		}
+		return names, nil
+	}
+}
+
+// xattrError returns nil for errors meaning the file system has no extended attributes.
+func xattrError(err error) error {
+	if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP) {
+		return nil
+	}
+	return err
+}
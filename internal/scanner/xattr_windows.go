@@ -0,0 +1,70 @@
+//go:build windows
+
+package scanner
+
+import (
+	"errors"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.Xattrs)
+}
+
+// findStreamInfoStandard is the FindStreamInfoStandard level of FindFirstStreamW.
+const findStreamInfoStandard = 0
+
+// defaultStream is the unnamed data stream every file has, which is not reported.
+const defaultStream = "::$DATA"
+
+var (
+	procFindFirstStreamW = windows.NewLazySystemDLL("kernel32.dll").NewProc("FindFirstStreamW")
+	procFindNextStreamW  = windows.NewLazySystemDLL("kernel32.dll").NewProc("FindNextStreamW")
+)
+
+// win32FindStreamData mirrors WIN32_FIND_STREAM_DATA.
+type win32FindStreamData struct {
+	StreamSize int64
+	StreamName [windows.MAX_PATH + 36]uint16
+}
+
+// listXattrs returns the names of the alternate data streams of the file at path, such as
+// "Zone.Identifier" on downloads, without the default data stream. File systems without
+// streams, like FAT, have none.
+func listXattrs(path string) ([]string, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data win32FindStreamData
+	handle, _, callErr := procFindFirstStreamW.Call(uintptr(unsafe.Pointer(name)), findStreamInfoStandard, uintptr(unsafe.Pointer(&data)), 0)
+	if windows.Handle(handle) == windows.InvalidHandle {
+		if errors.Is(callErr, windows.ERROR_HANDLE_EOF) || errors.Is(callErr, windows.ERROR_INVALID_PARAMETER) || errors.Is(callErr, windows.ERROR_NOT_SUPPORTED) {
+			return nil, nil
+		}
+		return nil, callErr
+	}
+	defer windows.FindClose(windows.Handle(handle))
+
+	var names []string
+	for {
+		stream := windows.UTF16ToString(data.StreamName[:])
+		if stream != defaultStream {
+			// Names come as ":name:$DATA"
+			names = append(names, strings.TrimSuffix(strings.TrimPrefix(stream, ":"), ":$DATA"))
+		}
+		ok, _, callErr := procFindNextStreamW.Call(handle, uintptr(unsafe.Pointer(&data)))
+		if ok == 0 {
+			if errors.Is(callErr, windows.ERROR_HANDLE_EOF) {
+				return names, nil
+			}
+			return names, callErr
+		}
+	}
+}
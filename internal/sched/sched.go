@@ -0,0 +1,218 @@
+// Package sched shares a budget of concurrent background work between the windows of the
+// application. Work waits its turn by priority, and work started by the user can preempt work
+// done ahead of time, which is cancelled and queued again to run once there is room.
+package sched
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// Priority orders waiting work; higher priorities run first, and work of the same priority runs
+// in the order it was submitted.
+type Priority int
+
+// Priorities of background work, lowest first.
+const (
+	Speculative Priority = iota // Work done ahead in case it is asked for, like pre-rendering output
+	Hashing                     // Reading file contents after a scan
+	Refresh                     // Rescanning a folder already shown
+	Interactive                 // A scan the user started
+)
+
+// Preemptible reports whether work of priority p gives way to more urgent work. Such work must
+// be able to start over, as it is cancelled and run again from the beginning.
+func (p Priority) Preemptible() bool {
+	return p < Refresh
+}
+
+// Causes of the cancellation of work by the scheduler, from context.Cause.
+var (
+	ErrPreempted = errors.New("preempted by more urgent work")
+	ErrCancelled = errors.New("cancelled with the rest of its owner's work")
+)
+
+// task is work waiting for or holding a slot of the budget.
+type task struct {
+	owner     any
+	priority  Priority
+	seq       uint64
+	parent    context.Context // Context of the Run call, which outlives the task when it is preempted
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	started   chan struct{} // Closed once the task holds a slot
+	preempted bool          // Cancelled to make room; the slot is freed when it returns
+	dropped   bool          // Cancelled with its owner's work, so never queued again
+}
+
+// Scheduler runs background work within a budget of concurrent tasks. Its methods are safe for
+// concurrent use.
+type Scheduler struct {
+	mu      sync.Mutex
+	budget  int
+	seq     uint64
+	queue   []*task // Highest priority first, then in submission order
+	running []*task
+}
+
+// New returns a scheduler running at most budget tasks at once; budget below 1 runs one.
+func New(budget int) *Scheduler {
+	return &Scheduler{budget: max(budget, 1)}
+}
+
+// Run calls fn for owner once a slot is free for priority, and returns its error. Preemptible
+// work that is preempted is queued again and fn called anew once there is room, so fn must stop
+// promptly when its context is done. Run returns the context's error without calling fn when ctx
+// is done or the owner's work is cancelled before it gets a slot.
+func (s *Scheduler) Run(ctx context.Context, owner any, priority Priority, fn func(ctx context.Context) error) error {
+	s.mu.Lock()
+	t := s.enqueue(ctx, owner, priority)
+	s.mu.Unlock()
+	for {
+		if err := s.wait(t); err != nil {
+			return err
+		}
+		next, err := s.run(t, fn)
+		if next == nil {
+			return err
+		}
+		t = next
+	}
+}
+
+// run calls fn in the slot of t and returns the task queued again in its place when it was
+// preempted, or nil. The slot is freed even when fn panics, so a failing task does not shrink
+// the budget for good.
+func (s *Scheduler) run(t *task, fn func(ctx context.Context) error) (next *task, err error) {
+	defer func() { next = s.release(t) }()
+	return nil, fn(t.ctx)
+}
+
+// CancelOwner cancels the work of owner, both running and waiting, as when its window closes.
+func (s *Scheduler) CancelOwner(owner any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = slices.DeleteFunc(s.queue, func(t *task) bool {
+		if t.owner != owner {
+			return false
+		}
+		t.cancel(ErrCancelled)
+		return true
+	})
+	for _, t := range s.running {
+		if t.owner == owner {
+			t.dropped = true
+			t.cancel(ErrCancelled)
+		}
+	}
+	s.dispatch()
+}
+
+// enqueue queues a new task in priority order and starts what there is room for. Called with
+// mu held.
+func (s *Scheduler) enqueue(ctx context.Context, owner any, priority Priority) *task {
+	t := &task{owner: owner, priority: priority, parent: ctx, started: make(chan struct{})}
+	t.ctx, t.cancel = context.WithCancelCause(ctx)
+
+	s.seq++
+	t.seq = s.seq
+	at, _ := slices.BinarySearchFunc(s.queue, t, func(queued, t *task) int {
+		if queued.priority != t.priority {
+			return cmp.Compare(t.priority, queued.priority)
+		}
+		return cmp.Compare(queued.seq, t.seq)
+	})
+	s.queue = slices.Insert(s.queue, at, t)
+	s.dispatch()
+	return t
+}
+
+// wait blocks until t holds a slot, or returns the error of its context once cancelled.
+func (s *Scheduler) wait(t *task) error {
+	select {
+	case <-t.started:
+	case <-t.ctx.Done():
+	}
+	if t.ctx.Err() == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-t.started:
+		// Started as it was cancelled; hand the slot on
+		s.running = slices.DeleteFunc(s.running, func(r *task) bool { return r == t })
+	default:
+		s.queue = slices.DeleteFunc(s.queue, func(q *task) bool { return q == t })
+	}
+	s.dispatch()
+	return t.ctx.Err()
+}
+
+// release frees the slot of a finished task. A preempted task is queued again in the same step,
+// so cancelling its owner at any point afterwards finds it, and the new task is returned; nil
+// when it is not to run again.
+func (s *Scheduler) release(t *task) *task {
+	t.cancel(nil)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = slices.DeleteFunc(s.running, func(r *task) bool { return r == t })
+	if !t.preempted || t.dropped || t.parent.Err() != nil {
+		s.dispatch()
+		return nil
+	}
+	return s.enqueue(t.parent, t.owner, t.priority)
+}
+
+// dispatch starts waiting tasks while there is room, then preempts running preemptible tasks
+// of lower priority for each waiting task that cannot be. Called with mu held.
+func (s *Scheduler) dispatch() {
+	for len(s.queue) > 0 && len(s.running) < s.budget {
+		t := s.queue[0]
+		s.queue = s.queue[1:]
+		s.running = append(s.running, t)
+		close(t.started)
+	}
+
+	// Slots already being freed for waiting urgent work
+	freeing := 0
+	for _, t := range s.running {
+		if t.preempted {
+			freeing++
+		}
+	}
+	for _, waiting := range s.queue {
+		if waiting.priority.Preemptible() {
+			break // The rest are no more urgent
+		}
+		if freeing > 0 {
+			freeing--
+			continue
+		}
+		victim := s.preemptible(waiting.priority)
+		if victim == nil {
+			return
+		}
+		victim.preempted = true
+		victim.cancel(ErrPreempted)
+	}
+}
+
+// preemptible returns the running task to preempt for work of priority: the one of lowest
+// priority below it that gives way, started last among equals, or nil if there is none.
+// Called with mu held.
+func (s *Scheduler) preemptible(priority Priority) *task {
+	var victim *task
+	for _, t := range s.running {
+		if t.preempted || !t.priority.Preemptible() || t.priority >= priority {
+			continue
+		}
+		if victim == nil || t.priority < victim.priority || (t.priority == victim.priority && t.seq > victim.seq) {
+			victim = t
+		}
+	}
+	return victim
+}
@@ -0,0 +1,274 @@
+package sched
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// queued returns the number of tasks waiting for a slot.
+func (s *Scheduler) queued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// fakeTask is work for tests that records its runs and holds its slot until released or
+// cancelled.
+type fakeTask struct {
+	name    string
+	release chan struct{}
+	started chan struct{} // Receives once per run
+	mu      sync.Mutex
+	causes  []error // Why each run's context ended, nil if it was released
+}
+
+func newFakeTask(name string) *fakeTask {
+	return &fakeTask{name: name, release: make(chan struct{}), started: make(chan struct{}, 10)}
+}
+
+// fn runs the task once, appending its name to log.
+func (f *fakeTask) fn(log *[]string, logMu *sync.Mutex) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		logMu.Lock()
+		*log = append(*log, f.name)
+		logMu.Unlock()
+		f.started <- struct{}{}
+		var cause error
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			cause = context.Cause(ctx)
+		}
+		f.mu.Lock()
+		f.causes = append(f.causes, cause)
+		f.mu.Unlock()
+		return cause
+	}
+}
+
+// runs returns the causes recorded so far.
+func (f *fakeTask) runs() []error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.causes)
+}
+
+func TestRunOrder(t *testing.T) {
+	s := New(1)
+	var log []string
+	var logMu sync.Mutex
+	blocker := newFakeTask("blocker")
+	go s.Run(context.Background(), "w", Interactive, blocker.fn(&log, &logMu))
+	<-blocker.started
+
+	// Queued one at a time so their submission order is known
+	tasks := []struct {
+		name     string
+		priority Priority
+	}{
+		{"speculative", Speculative},
+		{"refresh 1", Refresh},
+		{"hashing", Hashing},
+		{"refresh 2", Refresh},
+		{"interactive", Interactive},
+	}
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		done := newFakeTask(task.name)
+		close(done.release)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Run(context.Background(), "w", task.priority, done.fn(&log, &logMu))
+		}()
+		waitFor(t, task.name+" is queued", func() bool { return s.queued() == i+1 })
+	}
+	close(blocker.release)
+	wg.Wait()
+
+	want := []string{"blocker", "interactive", "refresh 1", "refresh 2", "hashing", "speculative"}
+	if !slices.Equal(log, want) {
+		t.Errorf("ran %q, want %q", log, want)
+	}
+}
+
+func TestBudgetRunsTogether(t *testing.T) {
+	s := New(2)
+	var log []string
+	var logMu sync.Mutex
+	a, b, c := newFakeTask("a"), newFakeTask("b"), newFakeTask("c")
+	go s.Run(context.Background(), "w", Refresh, a.fn(&log, &logMu))
+	<-a.started
+	go s.Run(context.Background(), "w", Refresh, b.fn(&log, &logMu))
+	<-b.started
+	go s.Run(context.Background(), "w", Refresh, c.fn(&log, &logMu))
+	waitFor(t, "the third task is queued", func() bool { return s.queued() == 1 })
+	select {
+	case <-c.started:
+		t.Fatal("a third task started within a budget of two")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(a.release)
+	<-c.started
+	close(b.release)
+	close(c.release)
+}
+
+func TestPreemption(t *testing.T) {
+	s := New(1)
+	var log []string
+	var logMu sync.Mutex
+	spec := newFakeTask("speculative")
+	user := newFakeTask("interactive")
+
+	specDone := make(chan error, 1)
+	go func() { specDone <- s.Run(context.Background(), "w", Speculative, spec.fn(&log, &logMu)) }()
+	<-spec.started
+
+	userDone := make(chan error, 1)
+	go func() { userDone <- s.Run(context.Background(), "w", Interactive, user.fn(&log, &logMu)) }()
+	<-user.started
+	if runs := spec.runs(); len(runs) != 1 || !errors.Is(runs[0], ErrPreempted) {
+		t.Fatalf("speculative task ended with %v, want to be preempted", runs)
+	}
+
+	// Queued again, it runs once the slot is free
+	close(user.release)
+	if err := <-userDone; err != nil {
+		t.Fatal(err)
+	}
+	<-spec.started
+	close(spec.release)
+	if err := <-specDone; err != nil {
+		t.Errorf("preempted task ended with %v once run again", err)
+	}
+	want := []string{"speculative", "interactive", "speculative"}
+	if !slices.Equal(log, want) {
+		t.Errorf("ran %q, want %q", log, want)
+	}
+}
+
+func TestUrgentWorkIsNotPreempted(t *testing.T) {
+	s := New(1)
+	var log []string
+	var logMu sync.Mutex
+	refresh := newFakeTask("refresh")
+	user := newFakeTask("interactive")
+	go s.Run(context.Background(), "w", Refresh, refresh.fn(&log, &logMu))
+	<-refresh.started
+	go s.Run(context.Background(), "w", Interactive, user.fn(&log, &logMu))
+	waitFor(t, "the interactive task is queued", func() bool { return s.queued() == 1 })
+
+	if runs := refresh.runs(); len(runs) != 0 {
+		t.Fatalf("refresh ended with %v, want it left running", runs)
+	}
+	close(refresh.release)
+	<-user.started
+	close(user.release)
+}
+
+func TestCancelOwner(t *testing.T) {
+	s := New(1)
+	var log []string
+	var logMu sync.Mutex
+	running := newFakeTask("running")
+	waiting := newFakeTask("waiting")
+	other := newFakeTask("other window")
+	close(other.release)
+
+	runningDone := make(chan error, 1)
+	go func() { runningDone <- s.Run(context.Background(), "closed", Refresh, running.fn(&log, &logMu)) }()
+	<-running.started
+	waitingDone := make(chan error, 1)
+	go func() { waitingDone <- s.Run(context.Background(), "closed", Refresh, waiting.fn(&log, &logMu)) }()
+	otherDone := make(chan error, 1)
+	go func() { otherDone <- s.Run(context.Background(), "open", Hashing, other.fn(&log, &logMu)) }()
+	waitFor(t, "both tasks are queued", func() bool { return s.queued() == 2 })
+
+	s.CancelOwner("closed")
+	if err := <-runningDone; !errors.Is(err, ErrCancelled) {
+		t.Errorf("running task ended with %v, want %v", err, ErrCancelled)
+	}
+	if err := <-waitingDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("waiting task ended with %v, want context.Canceled", err)
+	}
+	if err := <-otherDone; err != nil {
+		t.Errorf("other owner's task ended with %v", err)
+	}
+	want := []string{"running", "other window"}
+	if !slices.Equal(log, want) {
+		t.Errorf("ran %q, want %q", log, want)
+	}
+}
+
+func TestCancelOwnerOfPreemptedTask(t *testing.T) {
+	s := New(1)
+	var log []string
+	var logMu sync.Mutex
+	spec := newFakeTask("speculative")
+	user := newFakeTask("interactive")
+
+	specDone := make(chan error, 1)
+	go func() { specDone <- s.Run(context.Background(), "closed", Speculative, spec.fn(&log, &logMu)) }()
+	<-spec.started
+	go s.Run(context.Background(), "open", Interactive, user.fn(&log, &logMu))
+	<-user.started
+
+	// The preempted task is back in the queue as soon as its slot is freed
+	waitFor(t, "the preempted task is queued again", func() bool { return s.queued() == 1 })
+	s.CancelOwner("closed")
+	if err := <-specDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("preempted task ended with %v, want context.Canceled", err)
+	}
+	close(user.release)
+	if runs := spec.runs(); len(runs) != 1 {
+		t.Errorf("task of a cancelled owner ran %d times, want once", len(runs))
+	}
+}
+
+func TestCancelledContextNeverRuns(t *testing.T) {
+	s := New(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err := s.Run(ctx, "w", Interactive, func(context.Context) error { called = true; return nil })
+	if called || !errors.Is(err, context.Canceled) {
+		t.Errorf("called %v with error %v, want not called and context.Canceled", called, err)
+	}
+}
+
+func TestPanicFreesSlot(t *testing.T) {
+	s := New(1)
+	func() {
+		defer func() { recover() }()
+		s.Run(context.Background(), "w", Interactive, func(context.Context) error { panic("boom") })
+	}()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(context.Background(), "w", Interactive, func(context.Context) error { return nil })
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slot of a panicking task was never freed")
+	}
+}
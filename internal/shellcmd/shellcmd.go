@@ -0,0 +1,223 @@
+package shellcmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Shell selects the syntax of generated commands.
+type Shell string
+
+// Supported shells.
+const (
+	POSIX      Shell = "posix"
+	PowerShell Shell = "powershell"
+)
+
+// Default archive and destination names used in generated commands.
+const (
+	tarArchive  = "archive.tar.gz"
+	zipArchive  = "archive.zip"
+	rsyncTarget = "DEST/"
+)
+
+// Selection is a set of files to package, relative to the scan root.
+type Selection struct {
+	Root    string   // Absolute scan root the commands change into
+	Subject string   // What was selected, for the comment header
+	Paths   []string // Slash-separated file paths relative to Root
+	Skipped int      // Paths left out because they contain line breaks
+}
+
+// Select collects the files at or below node, relative to the scan root.
+func Select(scanRoot string, node *scanner.TreeNode) Selection {
+	sel := Selection{Root: scanRoot, Subject: relative(scanRoot, node.Path)}
+	if sel.Subject == "." {
+		sel.Subject = "the whole scan"
+	}
+
+	var walk func(n *scanner.TreeNode)
+	walk = func(n *scanner.TreeNode) {
+		if !n.IsDir {
+			rel := relative(scanRoot, n.Path)
+			if strings.ContainsAny(rel, "\r\n") {
+				sel.Skipped++
+				return
+			}
+			sel.Paths = append(sel.Paths, rel)
+		}
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(node)
+	return sel
+}
+
+// relative returns p relative to root with forward slashes.
+func relative(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		rel = p
+	}
+	return filepath.ToSlash(rel)
+}
+
+// header describes the selection as comment lines, valid in both shells.
+func (sel Selection) header(what string) string {
+	text := fmt.Sprintf("# %s: %d files from %s\n# Scan root: %s\n", what, len(sel.Paths), sel.Subject, sel.Root)
+	if sel.Skipped > 0 {
+		text += fmt.Sprintf("# Skipped %d paths containing line breaks\n", sel.Skipped)
+	}
+	return text
+}
+
+// Tar returns a command that archives the selection with tar. The POSIX command is for GNU
+// tar, which would otherwise unescape backslashes in the names it reads; Windows ships bsdtar,
+// which reads them verbatim.
+func Tar(sel Selection, shell Shell) string {
+	// tar reads a listed name starting with '-' as an option
+	paths := make([]string, len(sel.Paths))
+	for i, p := range sel.Paths {
+		if strings.HasPrefix(p, "-") {
+			p = "./" + p
+		}
+		paths[i] = p
+	}
+
+	if shell == PowerShell {
+		return sel.header("tar archive") + powerShellCd(sel.Root) +
+			powerShellList(paths) + " | tar -czf " + quotePowerShell(tarArchive) + " -T -\n"
+	}
+	return sel.header("tar archive") + posixCd(sel.Root) +
+		"tar -czf " + quotePOSIX(tarArchive) + " --verbatim-files-from --files-from=- " + heredoc(paths)
+}
+
+// Zip returns a command that archives the selection as a zip file. The PowerShell
+// variant stages the files so Compress-Archive keeps their folder structure.
+func Zip(sel Selection, shell Shell) string {
+	if shell == PowerShell {
+		var b strings.Builder
+		b.WriteString(sel.header("zip archive"))
+		b.WriteString(powerShellCd(sel.Root))
+		b.WriteString("$files = " + powerShellList(sel.Paths) + "\n")
+		b.WriteString("$stage = Join-Path ([IO.Path]::GetTempPath()) ([guid]::NewGuid())\n")
+		b.WriteString("foreach ($f in $files) {\n")
+		b.WriteString("    $dest = Join-Path $stage $f\n")
+		b.WriteString("    New-Item -ItemType Directory -Force -Path (Split-Path $dest) | Out-Null\n")
+		b.WriteString("    Copy-Item -LiteralPath $f -Destination $dest\n")
+		b.WriteString("}\n")
+		b.WriteString("Compress-Archive -Path (Join-Path $stage '*') -DestinationPath " + quotePowerShell(zipArchive) + "\n")
+		b.WriteString("Remove-Item -Recurse -Force -LiteralPath $stage\n")
+		return b.String()
+	}
+	return sel.header("zip archive") + posixCd(sel.Root) +
+		"zip " + quotePOSIX(zipArchive) + " -@ " + heredoc(sel.Paths)
+}
+
+// Rsync returns an rsync command whose include list copies exactly the selection.
+func Rsync(sel Selection, shell Shell) string {
+	includes := rsyncIncludes(sel.Paths)
+	if shell == PowerShell {
+		return sel.header("rsync include list") + powerShellCd(sel.Root) +
+			powerShellList(includes) + " | rsync -a --include-from=- --exclude='*' ./ " + quotePowerShell(rsyncTarget) + "\n"
+	}
+	return sel.header("rsync include list") + posixCd(sel.Root) +
+		"rsync -a --include-from=- --exclude='*' ./ " + quotePOSIX(rsyncTarget) + " " + heredoc(includes)
+}
+
+// rsyncIncludes returns anchored include patterns for paths and all their parent directories.
+func rsyncIncludes(paths []string) []string {
+	seen := make(map[string]bool)
+	var patterns []string
+	for _, p := range paths {
+		dir := path.Dir(p)
+		var parents []string
+		for dir != "." && !seen[dir] {
+			seen[dir] = true
+			parents = append([]string{"/" + escapeRsync(dir) + "/"}, parents...)
+			dir = path.Dir(dir)
+		}
+		patterns = append(patterns, parents...)
+		patterns = append(patterns, "/"+escapeRsync(p))
+	}
+	return patterns
+}
+
+// escapeRsync escapes rsync's wildcard characters so a pattern matches literally. rsync reads
+// a backslash as an escape only in patterns holding a wildcard, so others are left as they are.
+func escapeRsync(p string) string {
+	if !strings.ContainsAny(p, "*?[") {
+		return p
+	}
+	var b strings.Builder
+	for _, r := range p {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// quotePOSIX quotes s as a single POSIX shell word.
+func quotePOSIX(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quotePowerShell quotes s as a PowerShell verbatim string.
+func quotePowerShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// posixCd changes into the scan root, stopping if it is missing.
+func posixCd(root string) string {
+	return "cd " + quotePOSIX(root) + " && \\\n"
+}
+
+// powerShellCd changes into the scan root.
+func powerShellCd(root string) string {
+	return "Set-Location -LiteralPath " + quotePowerShell(root) + "\n"
+}
+
+// heredoc feeds lines to the preceding command on stdin, using a delimiter no line equals.
+func heredoc(lines []string) string {
+	delim := "FILES"
+	for contains(lines, delim) {
+		delim += "_"
+	}
+	return "<<'" + delim + "'\n" + joinLines(lines) + delim + "\n"
+}
+
+// powerShellList returns lines as a PowerShell array of verbatim strings.
+func powerShellList(lines []string) string {
+	var b strings.Builder
+	b.WriteString("@(\n")
+	for _, line := range lines {
+		b.WriteString("    " + quotePowerShell(line) + "\n")
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// joinLines joins lines, each followed by a newline.
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// contains reports whether lines includes s.
+func contains(lines []string, s string) bool {
+	for _, line := range lines {
+		if line == s {
+			return true
+		}
+	}
+	return false
+}
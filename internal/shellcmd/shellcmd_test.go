@@ -0,0 +1,188 @@
+package shellcmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// awkwardPaths are file names that need quoting or escaping somewhere along the way.
+var awkwardPaths = []string{
+	"plain.txt",
+	"with space.md",
+	"it's.txt",
+	`say "hi".txt`,
+	"-rf",
+	"dir/-leading.txt",
+	`back\slash.txt`,
+	`tab\t and \\ escapes.txt`,
+	"[draft].md",
+	"FILES",
+	"$HOME.txt",
+}
+
+func TestPOSIXQuoting(t *testing.T) {
+	sel := Selection{Root: "/srv/it's here", Subject: "the whole scan", Paths: awkwardPaths}
+	tests := []struct {
+		name  string
+		got   string
+		delim string   // Heredoc delimiter, which no path line equals
+		want  []string // Lines the command must hold
+	}{
+		{"tar", Tar(sel, POSIX), "FILES_", []string{
+			`cd '/srv/it'\''s here' && \`,
+			"tar -czf 'archive.tar.gz' --verbatim-files-from --files-from=- <<'FILES_'",
+			"it's.txt", `say "hi".txt`, "./-rf", "dir/-leading.txt", `back\slash.txt`, "[draft].md", "FILES", "$HOME.txt",
+			"FILES_",
+		}},
+		{"zip", Zip(sel, POSIX), "FILES_", []string{
+			"zip 'archive.zip' -@ <<'FILES_'", "-rf", `back\slash.txt`, "FILES", "FILES_",
+		}},
+		{"rsync", Rsync(sel, POSIX), "FILES", []string{
+			"rsync -a --include-from=- --exclude='*' ./ 'DEST/' <<'FILES'",
+			"/with space.md", "/it's.txt", "/-rf", "/dir/", "/dir/-leading.txt",
+			`/back\slash.txt`, `/\[draft].md`, "/FILES", "FILES",
+		}},
+	}
+	for _, tt := range tests {
+		lines := strings.Split(tt.got, "\n")
+		for _, want := range tt.want {
+			if !slices.Contains(lines, want) {
+				t.Errorf("%s: no line %q in\n%s", tt.name, want, tt.got)
+			}
+		}
+		// The heredoc ends only at its delimiter, after every path
+		if strings.Count(tt.got, "\n"+tt.delim+"\n") != 1 || !strings.HasSuffix(tt.got, "\n"+tt.delim+"\n") {
+			t.Errorf("%s: heredoc not closed once at the end:\n%s", tt.name, tt.got)
+		}
+	}
+}
+
+func TestPowerShellQuoting(t *testing.T) {
+	sel := Selection{Root: `C:\Users\it's me`, Subject: "the whole scan", Paths: awkwardPaths}
+	tests := []struct {
+		name string
+		got  string
+		want []string
+	}{
+		{"tar", Tar(sel, PowerShell), []string{
+			`Set-Location -LiteralPath 'C:\Users\it''s me'`,
+			"    'it''s.txt'", `    'say "hi".txt'`, "    './-rf'", `    'back\slash.txt'`, "    '[draft].md'", "    'FILES'", "    '$HOME.txt'",
+			") | tar -czf 'archive.tar.gz' -T -",
+		}},
+		{"zip", Zip(sel, PowerShell), []string{
+			"$files = @(", "    '-rf'", "    'it''s.txt'", "    '[draft].md'",
+			"    Copy-Item -LiteralPath $f -Destination $dest",
+			"Compress-Archive -Path (Join-Path $stage '*') -DestinationPath 'archive.zip'",
+		}},
+		{"rsync", Rsync(sel, PowerShell), []string{
+			"    '/it''s.txt'", `    '/back\slash.txt'`, `    '/\[draft].md'`, "    '/dir/'",
+			") | rsync -a --include-from=- --exclude='*' ./ 'DEST/'",
+		}},
+	}
+	for _, tt := range tests {
+		lines := strings.Split(tt.got, "\n")
+		for _, want := range tt.want {
+			if !slices.Contains(lines, want) {
+				t.Errorf("%s: no line %q in\n%s", tt.name, want, tt.got)
+			}
+		}
+		if strings.Contains(tt.got, "<<") {
+			t.Errorf("%s: PowerShell command uses a heredoc:\n%s", tt.name, tt.got)
+		}
+	}
+}
+
+func TestEscapeRsync(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"plain.txt", "plain.txt"},
+		{`back\slash.txt`, `back\slash.txt`}, // No wildcard, so the backslash is literal
+		{"[draft].md", `\[draft].md`},
+		{"what?.txt", `what\?.txt`},
+		{"*.log", `\*.log`},
+		{`a\b[1].txt`, `a\\b\[1].txt`}, // With a wildcard, backslashes are escapes too
+	}
+	for _, tt := range tests {
+		if got := escapeRsync(tt.path); got != tt.want {
+			t.Errorf("escapeRsync(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestHeredocDelimiter(t *testing.T) {
+	tests := []struct {
+		lines []string
+		delim string
+	}{
+		{[]string{"a.txt"}, "FILES"},
+		{[]string{"FILES"}, "FILES_"},
+		{[]string{"FILES", "FILES_", "FILES__x"}, "FILES__"},
+		{nil, "FILES"},
+	}
+	for _, tt := range tests {
+		got := heredoc(tt.lines)
+		if !strings.HasPrefix(got, "<<'"+tt.delim+"'\n") || !strings.HasSuffix(got, "\n"+tt.delim+"\n") {
+			t.Errorf("heredoc(%q) delimited wrongly, want %s:\n%s", tt.lines, tt.delim, got)
+		}
+	}
+}
+
+// TestTarRoundTrip runs the POSIX tar command on real files and checks the archive holds each
+// of them under its own name.
+func TestTarRoundTrip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	if out, err := exec.Command("tar", "--version").Output(); err != nil || !strings.Contains(string(out), "GNU tar") {
+		t.Skip("needs GNU tar")
+	}
+
+	root := t.TempDir()
+	for _, p := range awkwardPaths {
+		path := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(p), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("sh", "-c", Tar(Selection{Root: root, Subject: "the whole scan", Paths: awkwardPaths}, POSIX))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+
+	file, err := os.Open(filepath.Join(root, tarArchive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, strings.TrimPrefix(header.Name, "./"))
+	}
+	want := slices.Clone(awkwardPaths)
+	slices.Sort(want)
+	slices.Sort(names)
+	if !slices.Equal(names, want) {
+		t.Errorf("archive holds %q, want %q", names, want)
+	}
+}
@@ -0,0 +1,89 @@
+package snapshot
+
+import (
+	"sort"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// ChangeKind is the kind of a change between two trees.
+type ChangeKind string
+
+// Kinds of changes.
+const (
+	Added       ChangeKind = "added"
+	Removed     ChangeKind = "removed"
+	TypeChanged ChangeKind = "type changed" // A file became a directory or the reverse
+	Resized     ChangeKind = "resized"
+)
+
+// Change is one difference between an older and a newer tree.
+type Change struct {
+	Kind    ChangeKind
+	Path    string // Relative to the root with "/" separators and "/" after directories
+	Entries int    // Entries below an added or removed directory
+	OldSize int64  // Sizes of a resized file
+	NewSize int64
+}
+
+// Diff returns the changes from the tree below older to the tree below newer, by name within each
+// directory. Added and removed directories are reported once rather than with their contents.
+// File sizes are compared when sizes is set, which needs both trees to have collected them.
+func Diff(older, newer *scanner.TreeNode, sizes bool) []Change {
+	var changes []Change
+	diffDir(older, newer, "", sizes, &changes)
+	return changes
+}
+
+// diffDir compares the children of two directories whose relative path with trailing "/" is rel.
+func diffDir(older, newer *scanner.TreeNode, rel string, sizes bool, changes *[]Change) {
+	before := make(map[string]*scanner.TreeNode, len(older.Children))
+	for _, child := range older.Children {
+		before[child.Name] = child
+	}
+	after := make(map[string]*scanner.TreeNode, len(newer.Children))
+	names := make([]string, 0, len(older.Children)+len(newer.Children))
+	for _, child := range newer.Children {
+		after[child.Name] = child
+		if before[child.Name] == nil {
+			names = append(names, child.Name)
+		}
+	}
+	for _, child := range older.Children {
+		names = append(names, child.Name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		old, cur := before[name], after[name]
+		switch {
+		case old == nil:
+			*changes = append(*changes, Change{Kind: Added, Path: relPath(rel, cur), Entries: countBelow(cur)})
+		case cur == nil:
+			*changes = append(*changes, Change{Kind: Removed, Path: relPath(rel, old), Entries: countBelow(old)})
+		case old.IsDir != cur.IsDir:
+			*changes = append(*changes, Change{Kind: TypeChanged, Path: relPath(rel, cur)})
+		case cur.IsDir:
+			diffDir(old, cur, relPath(rel, cur), sizes, changes)
+		case sizes && old.Size != cur.Size:
+			*changes = append(*changes, Change{Kind: Resized, Path: relPath(rel, cur), OldSize: old.Size, NewSize: cur.Size})
+		}
+	}
+}
+
+// relPath returns the relative path of node inside the directory at rel.
+func relPath(rel string, node *scanner.TreeNode) string {
+	if node.IsDir {
+		return rel + node.Name + "/"
+	}
+	return rel + node.Name
+}
+
+// countBelow returns the number of entries below node.
+func countBelow(node *scanner.TreeNode) int {
+	count := 0
+	for _, child := range node.Children {
+		count += 1 + countBelow(child)
+	}
+	return count
+}
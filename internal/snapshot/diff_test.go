@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+func dir(name string, children ...*scanner.TreeNode) *scanner.TreeNode {
+	return &scanner.TreeNode{Name: name, IsDir: true, Children: children}
+}
+
+func file(name string, size int64) *scanner.TreeNode {
+	return &scanner.TreeNode{Name: name, Size: size}
+}
+
+// diffTrees returns an older and a newer tree differing in every kind of change, the newer
+// one listing its entries out of order.
+func diffTrees() (older, newer *scanner.TreeNode) {
+	older = dir("root",
+		file("a.txt", 1),
+		file("b.txt", 2),
+		dir("gone", file("x", 1), dir("y", file("z", 1))),
+		file("lib", 4),
+		dir("src", file("main.go", 3)),
+		dir("tmp", file("t", 1)),
+	)
+	newer = dir("root",
+		file("zz.txt", 1),
+		dir("src", file("util.go", 1), file("main.go", 3)),
+		dir("new", file("n2", 1), file("n1", 1)),
+		dir("lib", file("mod.go", 1)),
+		file("b.txt", 5),
+		file("a.txt", 1),
+		file("tmp", 1),
+	)
+	return older, newer
+}
+
+func TestDiff(t *testing.T) {
+	older, newer := diffTrees()
+	want := []Change{
+		{Kind: Resized, Path: "b.txt", OldSize: 2, NewSize: 5},
+		{Kind: Removed, Path: "gone/", Entries: 3},
+		{Kind: TypeChanged, Path: "lib/"},
+		{Kind: Added, Path: "new/", Entries: 2},
+		{Kind: Added, Path: "src/util.go"},
+		{Kind: TypeChanged, Path: "tmp"},
+		{Kind: Added, Path: "zz.txt"},
+	}
+	if got := Diff(older, newer, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Without sizes, only the structure is compared
+	withoutSizes := append(want[:0:0], want[1:]...)
+	if got := Diff(older, newer, false); !reflect.DeepEqual(got, withoutSizes) {
+		t.Errorf("Diff without sizes =\n%+v\nwant\n%+v", got, withoutSizes)
+	}
+}
+
+func TestDiffReverse(t *testing.T) {
+	older, newer := diffTrees()
+	var removed []string
+	for _, change := range Diff(newer, older, false) {
+		if change.Kind == Removed {
+			removed = append(removed, change.Path)
+		}
+	}
+	if want := []string{"new/", "src/util.go", "zz.txt"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed %q going back, want %q", removed, want)
+	}
+}
+
+func TestDiffSame(t *testing.T) {
+	older, _ := diffTrees()
+	if changes := Diff(older, older, true); len(changes) != 0 {
+		t.Errorf("a tree differs from itself: %+v", changes)
+	}
+}
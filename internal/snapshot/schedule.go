@@ -0,0 +1,101 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MinInterval is the shortest interval between scheduled snapshots.
+const MinInterval = time.Minute
+
+// pollInterval bounds how long Wait sleeps before looking at the wall clock again. Timers do not
+// run while the machine is asleep, and do not notice the clock being changed.
+const pollInterval = time.Minute
+
+// Schedule says when snapshots are due: at a fixed interval, or daily at a time of day.
+type Schedule struct {
+	every        time.Duration // Interval between snapshots; 0 for a daily schedule
+	hour, minute int           // Local time of day of a daily schedule
+}
+
+// ParseSchedule reads a schedule: an interval such as "30m" or "6h", "@hourly", "@daily" or
+// "@nightly" for midnight, or "daily HH:MM" (or just "HH:MM") for a local time of day.
+func ParseSchedule(spec string) (Schedule, error) {
+	text := strings.ToLower(strings.TrimSpace(spec))
+	switch text {
+	case "":
+		return Schedule{}, fmt.Errorf("empty schedule")
+	case "@hourly":
+		return Schedule{every: time.Hour}, nil
+	case "@daily", "@nightly", "@midnight", "daily":
+		return Schedule{}, nil
+	}
+
+	clock := strings.TrimSpace(strings.TrimPrefix(text, "daily"))
+	if at, err := time.Parse("15:04", clock); err == nil {
+		return Schedule{hour: at.Hour(), minute: at.Minute()}, nil
+	}
+	every, err := time.ParseDuration(text)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("schedule %q is not an interval such as 6h, @hourly, @daily or daily HH:MM", spec)
+	}
+	if every < MinInterval {
+		return Schedule{}, fmt.Errorf("schedule %q is shorter than %s", spec, MinInterval)
+	}
+	return Schedule{every: every}, nil
+}
+
+// String describes the schedule, e.g. "every 6h0m0s" or "daily at 02:30".
+func (s Schedule) String() string {
+	if s.every > 0 {
+		return "every " + s.every.String()
+	}
+	return fmt.Sprintf("daily at %02d:%02d", s.hour, s.minute)
+}
+
+// Next returns when the snapshot after one taken at last is due. A zero last is always due.
+func (s Schedule) Next(last time.Time) time.Time {
+	if last.IsZero() {
+		return last
+	}
+	if s.every > 0 {
+		return last.Add(s.every)
+	}
+	local := last.Local()
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.hour, s.minute, 0, 0, time.Local)
+	for !next.After(last) {
+		// Built from the date rather than adding 24h, so days of 23 or 25 hours keep the time
+		local = local.AddDate(0, 0, 1)
+		next = time.Date(local.Year(), local.Month(), local.Day(), s.hour, s.minute, 0, 0, time.Local)
+	}
+	return next
+}
+
+// Wait blocks until the snapshot after one taken at last is due, or ctx ends. It compares the
+// wall clock rather than trusting a single timer, so runs missed while the machine was off or
+// asleep are due at once, and however many were missed, they make one. If the clock was set back
+// before last, or while waiting, the schedule counts on from the new time.
+func Wait(ctx context.Context, s Schedule, last time.Time) error {
+	now := time.Now().Round(0) // Wall clock only
+	if last.After(now) {
+		last = now
+	}
+	next := s.Next(last)
+	for now.Before(next) {
+		timer := time.NewTimer(min(next.Sub(now), pollInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		polled := time.Now().Round(0)
+		if polled.Before(now) {
+			next = s.Next(polled)
+		}
+		now = polled
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package snapshot keeps timestamped tree files of a folder, taken on a schedule, and compares
+// them to show how its structure changed over time.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+func init() {
+	capability.Register(capability.Snapshots)
+}
+
+// Snapshot file names hold the time they were taken in UTC, so they sort in time order and
+// daylight saving or time zone changes cannot reorder them.
+const (
+	filePrefix = "snapshot-"
+	fileSuffix = ".json"
+	timeLayout = "20060102T150405Z"
+)
+
+// Snapshot is a tree file in a snapshot directory.
+type Snapshot struct {
+	Path string
+	Name string
+	Time time.Time // When it was taken, from its name
+	Size int64     // Size of the file in bytes
+}
+
+// List returns the snapshots in dir, oldest first. Other files are ignored; a directory that does
+// not exist yet holds no snapshots.
+func List(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		taken, ok := parseName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		snapshot := Snapshot{Path: filepath.Join(dir, entry.Name()), Name: entry.Name(), Time: taken}
+		if info, err := entry.Info(); err == nil {
+			snapshot.Size = info.Size()
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Time.Before(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+// Latest returns the time the newest snapshot in dir was taken, or the zero time if there is none.
+func Latest(dir string) (time.Time, error) {
+	snapshots, err := List(dir)
+	if err != nil || len(snapshots) == 0 {
+		return time.Time{}, err
+	}
+	return snapshots[len(snapshots)-1].Time, nil
+}
+
+// parseName returns the time a snapshot file name was taken at.
+func parseName(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, filePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp, ok = strings.CutSuffix(stamp, fileSuffix)
+	if !ok {
+		return time.Time{}, false
+	}
+	taken, err := time.Parse(timeLayout, stamp)
+	return taken, err == nil
+}
+
+// fileName returns the name of the snapshot taken at taken.
+func fileName(taken time.Time) string {
+	return filePrefix + taken.UTC().Format(timeLayout) + fileSuffix
+}
+
+// Write saves doc in dir, creating it if needed, as the snapshot taken at taken. It writes a
+// temporary file and renames it, so an interrupted run never leaves a partial snapshot. A name
+// already taken, as after the clock was set back, moves the snapshot on by a second.
+func Write(dir string, doc *report.TreeDocument, taken time.Time) (Snapshot, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	taken = taken.UTC().Truncate(time.Second)
+	path := filepath.Join(dir, fileName(taken))
+	for {
+		if _, err := os.Lstat(path); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		taken = taken.Add(time.Second)
+		path = filepath.Join(dir, fileName(taken))
+	}
+
+	temp, err := os.CreateTemp(dir, ".snapshot-*.tmp")
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	defer os.Remove(temp.Name()) // Fails harmlessly once renamed
+	if err := report.WriteTree(temp, doc); err != nil {
+		temp.Close()
+		return Snapshot{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	snapshot := Snapshot{Path: path, Name: filepath.Base(path), Time: taken}
+	if info, err := os.Stat(path); err == nil {
+		snapshot.Size = info.Size()
+	}
+	return snapshot, nil
+}
+
+// Prune deletes all but the newest keep snapshots in dir and returns those deleted; keep below 1
+// keeps them all.
+func Prune(dir string, keep int) ([]Snapshot, error) {
+	if keep < 1 {
+		return nil, nil
+	}
+	snapshots, err := List(dir)
+	if err != nil || len(snapshots) <= keep {
+		return nil, err
+	}
+	var removed []Snapshot
+	for _, snapshot := range snapshots[:len(snapshots)-keep] {
+		if err := os.Remove(snapshot.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove old snapshot: %w", err)
+		}
+		removed = append(removed, snapshot)
+	}
+	return removed, nil
+}
+
+// Load reads a snapshot as a virtual result.
+func Load(path string) (*scanner.ScanResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+	doc, err := report.ReadTree(file)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Result()
+}
@@ -0,0 +1,121 @@
+package snapshot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// scanFixture scans a small project with sizes.
+func scanFixture(t *testing.T) *scanner.ScanResult {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.ShowSize = true
+	fsys := fstest.MapFS{
+		"README.md":        {Data: []byte("# project\n")},
+		"src/main.go":      {Data: []byte("package main\n")},
+		"src/util/util.go": {Data: []byte("package util\n")},
+		"empty":            {Mode: os.ModeDir | 0o755},
+	}
+	result, err := scanner.NewFileTreeScannerFS(cfg, fsys, "project").ScanDirectory(context.Background(), "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestWriteLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	result := scanFixture(t)
+	taken := time.Date(2024, 3, 10, 14, 30, 5, 500, time.FixedZone("CET", 3600))
+	saved, err := Write(dir, report.NewTreeDocument(result), taken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "snapshot-20240310T133005Z.json"; saved.Name != want {
+		t.Errorf("snapshot named %q, want %q", saved.Name, want)
+	}
+	if !saved.Time.Equal(taken.Truncate(time.Second)) || saved.Size == 0 {
+		t.Errorf("snapshot taken %v, %d bytes", saved.Time, saved.Size)
+	}
+
+	loaded, err := Load(saved.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.HasSizes || loaded.NodeCount != result.NodeCount || loaded.TotalSize != result.TotalSize {
+		t.Errorf("loaded %d entries of %d bytes, sizes %v; want %d of %d", loaded.NodeCount, loaded.TotalSize, loaded.HasSizes, result.NodeCount, result.TotalSize)
+	}
+	if changes := Diff(result.Root, loaded.Root, true); len(changes) != 0 {
+		t.Errorf("loaded snapshot differs from the scan: %+v", changes)
+	}
+}
+
+func TestWriteMovesOnFromTakenNames(t *testing.T) {
+	dir := t.TempDir()
+	doc := report.NewTreeDocument(scanFixture(t))
+	taken := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	first, err := Write(dir, doc, taken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Write(dir, doc, taken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Time.Equal(first.Time.Add(time.Second)) {
+		t.Errorf("second snapshot taken %v, want a second after %v", second.Time, first.Time)
+	}
+}
+
+func TestListAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	doc := report.NewTreeDocument(scanFixture(t))
+	start := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, hours := range []int{2, 0, 1} {
+		if _, err := Write(dir, doc, start.Add(time.Duration(hours)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"notes.json", "snapshot-garbage.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 3 || !snapshots[0].Time.Equal(start) || !snapshots[2].Time.Equal(start.Add(2*time.Hour)) {
+		t.Fatalf("listed %+v, want the three snapshots oldest first", snapshots)
+	}
+	if latest, err := Latest(dir); err != nil || !latest.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("Latest = %v, %v", latest, err)
+	}
+
+	removed, err := Prune(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Name != snapshots[0].Name {
+		t.Errorf("pruned %+v, want the oldest", removed)
+	}
+	if left, _ := List(dir); len(left) != 2 {
+		t.Errorf("%d snapshots left, want 2", len(left))
+	}
+}
+
+func TestListMissingDir(t *testing.T) {
+	snapshots, err := List(filepath.Join(t.TempDir(), "none"))
+	if err != nil || snapshots != nil {
+		t.Errorf("List of a missing directory = %v, %v", snapshots, err)
+	}
+}
@@ -0,0 +1,140 @@
+package testtree
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// treeFS is a generated tree as a read-only file system. Each directory keeps its sorted
+// entries, so listing one does not go through the whole tree as fstest.MapFS does.
+type treeFS map[string]*treeEntry
+
+// treeEntry is a file or directory of a treeFS, serving as its FileInfo and DirEntry.
+type treeEntry struct {
+	name    string
+	dir     bool
+	size    int64
+	entries []fs.DirEntry // Of a directory, by name
+}
+
+// newTreeFS returns a treeFS holding entries, which list parents before children.
+func newTreeFS(entries []Entry) treeFS {
+	fsys := treeFS{".": {name: ".", dir: true}}
+	for _, entry := range entries {
+		node := &treeEntry{name: path.Base(entry.Path), dir: entry.IsDir, size: entry.Size}
+		fsys[entry.Path] = node
+		parent := fsys[path.Dir(entry.Path)]
+		parent.entries = append(parent.entries, node)
+	}
+	for _, node := range fsys {
+		sort.Slice(node.entries, func(i, j int) bool { return node.entries[i].Name() < node.entries[j].Name() })
+	}
+	return fsys
+}
+
+// lookup returns the entry name, failing with op as fs functions do.
+func (f treeFS) lookup(op, name string) (*treeEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	node, ok := f[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return node, nil
+}
+
+// Open implements fs.FS. Files read as zero bytes of their size.
+func (f treeFS) Open(name string) (fs.File, error) {
+	node, err := f.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if node.dir {
+		return &openDir{treeEntry: node, path: name}, nil
+	}
+	return &zeroFile{treeEntry: node, left: node.size}, nil
+}
+
+// Stat implements fs.StatFS.
+func (f treeFS) Stat(name string) (fs.FileInfo, error) {
+	return f.lookup("stat", name)
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (f treeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	node, err := f.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !node.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return append([]fs.DirEntry(nil), node.entries...), nil
+}
+
+func (e *treeEntry) Name() string               { return e.name }
+func (e *treeEntry) Size() int64                { return e.size }
+func (e *treeEntry) ModTime() time.Time         { return time.Time{} }
+func (e *treeEntry) IsDir() bool                { return e.dir }
+func (e *treeEntry) Sys() any                   { return nil }
+func (e *treeEntry) Type() fs.FileMode          { return e.Mode().Type() }
+func (e *treeEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+func (e *treeEntry) Mode() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir | 0o755
+	}
+	return 0o644
+}
+
+// openDir is an open directory of a treeFS.
+type openDir struct {
+	*treeEntry
+	path   string
+	offset int
+}
+
+func (d *openDir) Stat() (fs.FileInfo, error) { return d.treeEntry, nil }
+func (d *openDir) Close() error               { return nil }
+
+func (d *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *openDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && len(rest) > n {
+		rest = rest[:n]
+	}
+	d.offset += len(rest)
+	return append([]fs.DirEntry(nil), rest...), nil
+}
+
+// zeroFile is an open file of a treeFS, reading as left more zero bytes.
+type zeroFile struct {
+	*treeEntry
+	left int64
+}
+
+func (z *zeroFile) Stat() (fs.FileInfo, error) { return z.treeEntry, nil }
+func (z *zeroFile) Close() error               { return nil }
+
+func (z *zeroFile) Read(p []byte) (int, error) {
+	if z.left <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > z.left {
+		p = p[:z.left]
+	}
+	clear(p)
+	z.left -= int64(len(p))
+	return len(p), nil
+}
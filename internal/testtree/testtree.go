@@ -0,0 +1,156 @@
+package testtree
+
+import (
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"testing/fstest"
+)
+
+// Spec describes a synthetic directory tree. The same Spec always generates the same tree.
+type Spec struct {
+	Seed        int64
+	Depth       int     // Levels of directories below the root
+	FanOut      int     // Subdirectories per directory
+	Files       int     // Files per directory
+	DirPattern  string  // fmt pattern for directory names, given the index (default "dir_%03d")
+	FilePattern string  // fmt pattern for file names, given the index (default "file_%03d.txt")
+	MinSize     int64   // Smallest file size in bytes
+	MaxSize     int64   // Largest file size in bytes
+	HiddenRatio float64 // Fraction of entries whose name starts with a dot
+}
+
+// Entry is a generated file or directory.
+type Entry struct {
+	Path  string // Slash-separated, relative to the root
+	IsDir bool
+	Size  int64
+}
+
+// Counts summarizes a generated tree.
+type Counts struct {
+	Dirs   int
+	Files  int
+	Hidden int // Hidden entries, whether files or directories
+}
+
+// Small returns a spec for a few hundred entries.
+func Small() Spec {
+	return Spec{Seed: 1, Depth: 3, FanOut: 3, Files: 5, MaxSize: 4096, HiddenRatio: 0.1}
+}
+
+// Large returns a spec for a few hundred thousand entries, for benchmarks.
+func Large() Spec {
+	return Spec{Seed: 1, Depth: 5, FanOut: 8, Files: 6, MaxSize: 1 << 20, HiddenRatio: 0.05}
+}
+
+// Entries generates the tree's entries, parents before children.
+func (s Spec) Entries() []Entry {
+	dirPattern, filePattern := s.DirPattern, s.FilePattern
+	if dirPattern == "" {
+		dirPattern = "dir_%03d"
+	}
+	if filePattern == "" {
+		filePattern = "file_%03d.txt"
+	}
+
+	rng := rand.New(rand.NewSource(s.Seed))
+	name := func(pattern string, i int) string {
+		n := fmt.Sprintf(pattern, i)
+		if s.HiddenRatio > 0 && rng.Float64() < s.HiddenRatio {
+			n = "." + n
+		}
+		return n
+	}
+
+	var entries []Entry
+	var generate func(dir string, level int)
+	generate = func(dir string, level int) {
+		for i := 0; i < s.Files; i++ {
+			size := s.MinSize
+			if s.MaxSize > s.MinSize {
+				size += rng.Int63n(s.MaxSize - s.MinSize + 1)
+			}
+			entries = append(entries, Entry{Path: path.Join(dir, name(filePattern, i)), Size: size})
+		}
+		if level >= s.Depth {
+			return
+		}
+		for i := 0; i < s.FanOut; i++ {
+			sub := path.Join(dir, name(dirPattern, i))
+			entries = append(entries, Entry{Path: sub, IsDir: true})
+			generate(sub, level+1)
+		}
+	}
+	generate("", 0)
+	return entries
+}
+
+// Count returns the number of directories, files and hidden entries the spec generates.
+func (s Spec) Count() Counts {
+	var counts Counts
+	for _, entry := range s.Entries() {
+		if entry.IsDir {
+			counts.Dirs++
+		} else {
+			counts.Files++
+		}
+		if path.Base(entry.Path)[0] == '.' {
+			counts.Hidden++
+		}
+	}
+	return counts
+}
+
+// MapFS returns the tree as an in-memory file system of empty files, so even Large costs little
+// memory. FS keeps the generated sizes.
+func (s Spec) MapFS() fstest.MapFS {
+	fsys := make(fstest.MapFS)
+	for _, entry := range s.Entries() {
+		if entry.IsDir {
+			fsys[entry.Path] = &fstest.MapFile{Mode: fs.ModeDir | 0o755}
+			continue
+		}
+		fsys[entry.Path] = &fstest.MapFile{Mode: 0o644}
+	}
+	return fsys
+}
+
+// FS returns the tree as an in-memory file system whose files have the generated sizes. Their
+// contents are zero bytes made up as they are read, so sizes cost no memory, and directories are
+// listed without going through the whole tree, so it suits benchmarks better than MapFS.
+func (s Spec) FS() fs.FS {
+	return newTreeFS(s.Entries())
+}
+
+// Materialize creates the tree below dir. Files are sparse where the file system allows,
+// so large sizes cost little disk space.
+func (s Spec) Materialize(dir string) error {
+	for _, entry := range s.Entries() {
+		target := filepath.Join(dir, filepath.FromSlash(entry.Path))
+		if entry.IsDir {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("failed to create %q: %w", target, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create %q: %w", filepath.Dir(target), err)
+		}
+		file, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("failed to create %q: %w", target, err)
+		}
+		err = file.Truncate(entry.Size)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to size %q: %w", target, err)
+		}
+	}
+	return nil
+}
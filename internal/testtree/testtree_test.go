@@ -0,0 +1,124 @@
+package testtree
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEntriesAreReproducible(t *testing.T) {
+	a, b := Small().Entries(), Small().Entries()
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("the same spec generated two different trees")
+	}
+	other := Small()
+	other.Seed++
+	if reflect.DeepEqual(a, other.Entries()) {
+		t.Error("another seed generated the same tree")
+	}
+}
+
+func TestCount(t *testing.T) {
+	spec := Spec{Depth: 2, FanOut: 3, Files: 4}
+	counts := spec.Count()
+	// 3 + 9 directories, and 4 files in each of them and the root
+	if counts.Dirs != 12 || counts.Files != 52 || counts.Hidden != 0 {
+		t.Errorf("Count() = %+v, want 12 directories and 52 files, none hidden", counts)
+	}
+
+	spec.HiddenRatio = 1
+	if hidden := spec.Count().Hidden; hidden != 64 {
+		t.Errorf("%d hidden entries with HiddenRatio 1, want all 64", hidden)
+	}
+}
+
+func TestEntriesOrderAndNames(t *testing.T) {
+	spec := Spec{Depth: 1, FanOut: 2, Files: 1, DirPattern: "d%d", FilePattern: "f%d.go", MinSize: 10, MaxSize: 20}
+	seen := map[string]bool{"": true}
+	for _, entry := range spec.Entries() {
+		parent := filepath.ToSlash(filepath.Dir(entry.Path))
+		if parent == "." {
+			parent = ""
+		}
+		if !seen[parent] {
+			t.Errorf("%s listed before its directory", entry.Path)
+		}
+		seen[entry.Path] = true
+		if !entry.IsDir && (entry.Size < 10 || entry.Size > 20) {
+			t.Errorf("%s has size %d outside [10, 20]", entry.Path, entry.Size)
+		}
+	}
+	for _, want := range []string{"f0.go", "d0", "d0/f0.go", "d1", "d1/f0.go"} {
+		if !seen[want] {
+			t.Errorf("%s not generated", want)
+		}
+	}
+}
+
+func TestFS(t *testing.T) {
+	spec := Small()
+	fsys := spec.FS()
+	var expected []string
+	for _, entry := range spec.Entries() {
+		expected = append(expected, entry.Path)
+		if entry.IsDir {
+			continue
+		}
+		info, err := fs.Stat(fsys, entry.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != entry.Size {
+			t.Errorf("%s: size %d, want %d", entry.Path, info.Size(), entry.Size)
+		}
+	}
+	if err := fstest.TestFS(fsys, expected...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSReadsZeros(t *testing.T) {
+	spec := Spec{Files: 1, MinSize: 5000, MaxSize: 5000}
+	file, err := spec.FS().Open("file_000.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 5000 || strings.Trim(string(data), "\x00") != "" {
+		t.Errorf("read %d bytes, want 5000 zero bytes", len(data))
+	}
+}
+
+func TestMapFSHoldsNoContents(t *testing.T) {
+	for name, file := range Large().MapFS() {
+		if len(file.Data) != 0 {
+			t.Fatalf("%s holds %d bytes", name, len(file.Data))
+		}
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	spec := Small()
+	dir := t.TempDir()
+	if err := spec.Materialize(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range spec.Entries() {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.IsDir() != entry.IsDir || (!entry.IsDir && info.Size() != entry.Size) {
+			t.Errorf("%s: dir %v size %d, want dir %v size %d", entry.Path, info.IsDir(), info.Size(), entry.IsDir, entry.Size)
+		}
+	}
+}
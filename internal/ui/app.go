@@ -2,10 +2,14 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -15,14 +19,22 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
 	"github.com/Akaiko1/file-tree-scanner/internal/clipboard"
 	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/diff"
+	"github.com/Akaiko1/file-tree-scanner/internal/exporter"
+	"github.com/Akaiko1/file-tree-scanner/internal/folderopts"
+	"github.com/Akaiko1/file-tree-scanner/internal/importer"
 	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/sandbox"
 	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/sched"
 )
 
 const (
 	// UI Constants
+	appID        = "com.github.akaiko1.file-tree-scanner"
 	appTitle     = "File Tree Scanner: AI Agent helper"
 	windowWidth  = 800
 	windowHeight = 600
@@ -36,35 +48,98 @@ const (
 	timeFormat     = "2006-01-02_15-04-05"
 
 	// Messages
-	msgNoData      = "Please scan a directory first."
-	msgScanSuccess = "Directory scanned successfully!"
-	msgSaveSuccess = "File tree saved successfully!"
-	msgCopySuccess = "File tree copied to clipboard!"
-	msgScanning    = "Scanning directory..."
+	msgNoData        = "Please scan a directory first."
+	msgScanSuccess   = "Directory scanned successfully!"
+	msgScanTruncated = "%s.\nThe tree only shows part of the directory."
+	msgSaveSuccess   = "Saved %s to %s"
+	msgCopySuccess   = "Copied %s to clipboard"
+	msgScanning      = "Scanning directory..."
+
+	// backgroundBudget is how many scans and background renders of all windows run at once
+	backgroundBudget = 2
 )
 
+// background runs the scans and background renders of all windows, so one busy window cannot
+// starve the others: scans the user starts go first, and pre-rendering gives way to them.
+var background = sched.New(backgroundBudget)
+
 // FileTreeApp represents the main GUI application for directory tree scanning and visualization.
 type FileTreeApp struct {
 	// Core components
-	app    fyne.App
-	window fyne.Window
-	config *config.Config
+	app      fyne.App
+	window   fyne.Window
+	config   *config.Config
+	settings uiSettings
 
 	// Services
 	scanner   scanner.FileSystemScanner
-	renderer  renderer.TreeRenderer
 	clipboard clipboard.ClipboardManager
 
 	// UI components
-	tree        *widget.Tree
-	statusLabel *widget.Label
+	tree         *widget.Tree
+	preview      *textPreview
+	status       *statusBar
+	refreshBtn   *widget.Button
+	refreshItem  *fyne.MenuItem
+	packItem     *fyne.MenuItem
+	sourceBanner *fyne.Container
+	sourceLabel  *widget.Label
+	staleBanner  *fyne.Container
+	busyControls []fyne.Disableable // Disabled while an operation runs
+	busyItems    []*fyne.MenuItem   // Disabled while an operation runs
 
 	// State - UI thread only, no synchronization needed
 	treeData      map[string][]string
+	treeDepth     map[string]int               // Depth of every node, root = 0
+	treeNodes     map[string]*scanner.TreeNode // Node per path, only kept while annotators are registered
+	rowIndex      map[string]int               // Position among currently visible rows, for shading
 	currentResult *scanner.ScanResult
+	selectedPath  string // Path of the selected tree item, "" for none
+	sourceMissing bool   // Scanned folder was deleted or moved after the scan
+	operation     operation
+	operationSeq  int    // Incremented by every operation begun, so a superseded one cannot end its successor
+	lastFailure   string // Last scan, save or export that failed, with its error
+
+	// View exclusions - entries hidden from the view, relative to the root with "/" after directories
+	baseResult     *scanner.ScanResult // currentResult before view exclusions
+	viewExclusions []string
+
+	// Settings before the last change applied in the settings dialog, nil when there is none
+	undoSettings     *settingsSnapshot
+	undoSettingsItem *fyne.MenuItem
+
+	// Configuration last applied, and the changes applied to it since
+	appliedConfig *config.Config
+	configChanges config.Events
+
+	// Layout violations per node path, shown in the tree while layoutResult is the base result
+	layoutMarks  map[string]string
+	layoutResult *scanner.ScanResult
+
+	// Comparison with a snapshot, shown in the tree while its merged tree is the base result
+	comparison *diff.Result
+
+	// Output rendered ahead of the next copy or save
+	prerender *prerenderCache
+	lastCopy  string // copyTree, copyCards or copyOutline, "" before the first copy
+
+	// Watch keeping the shown tree up to date with auto-refresh, nil when there is none
+	watchCancel    context.CancelFunc
+	watchOverrides scanOverrides // Overrides of the last scan, which the watch filters like
+
+	// Options saved for particular folders, nil when there is no configuration directory
+	folders       *folderopts.Store
+	folderOptions *folderopts.Folder // Saved options the shown result was scanned with, nil for none
+	folderBanner  *fyne.Container
+	folderLabel   *widget.Label
+
+	// Sandbox of packaged builds, which only lets the application read folders the user chose
+	sandbox      sandbox.Kind
+	bookmarks    *sandbox.Bookmarks // Bookmarks of chosen folders, nil outside the macOS App Sandbox
+	folderAccess []func()           // Ends the access to the scanned folders started from bookmarks
 
 	// Context for cancelling operations
-	cancelFunc context.CancelFunc
+	cancelFunc context.CancelCauseFunc
 }
 
 // NewFileTreeApp creates a new FileTreeApp with the given configuration.
@@ -73,34 +148,100 @@ func NewFileTreeApp(cfg *config.Config) *FileTreeApp {
 		cfg = config.DefaultConfig()
 	}
 
-	fyneApp := app.New()
+	fyneApp := app.NewWithID(appID)
 	fyneApp.SetIcon(theme.FolderIcon())
+	return newWindow(fyneApp, cfg)
+}
+
+// newWindow creates a FileTreeApp showing its own result in a new window of fyneApp.
+func newWindow(fyneApp fyne.App, cfg *config.Config) *FileTreeApp {
+	settings := loadSettings(fyneApp.Preferences(), cfg)
+	settings.applyTo(cfg)
 
 	window := fyneApp.NewWindow(appTitle)
 	window.Resize(fyne.NewSize(windowWidth, windowHeight))
 
-	scanner := scanner.NewFileTreeScanner(cfg)
-	renderer := &renderer.StandardTreeRenderer{}
+	scanner := scanner.New(cfg)
 	clipboard := clipboard.NewFyneClipboardManager(fyneApp.Clipboard())
+	folders, err := folderopts.DefaultStore()
+	if err != nil {
+		log.Printf("Warning: folder options are off: %v", err)
+	}
+	kind := sandbox.Detect()
+	var bookmarks *sandbox.Bookmarks
+	if kind == sandbox.MacOS {
+		if bookmarks, err = sandbox.DefaultBookmarks(); err != nil {
+			log.Printf("Warning: recent folders will not reopen in the sandbox: %v", err)
+		}
+	}
 
 	return &FileTreeApp{
-		app:         fyneApp,
-		window:      window,
-		config:      cfg,
-		scanner:     scanner,
-		renderer:    renderer,
-		clipboard:   clipboard,
-		treeData:    make(map[string][]string),
-		statusLabel: widget.NewLabel("Application started. Ready to scan"),
+		app:           fyneApp,
+		window:        window,
+		config:        cfg,
+		settings:      settings,
+		appliedConfig: cfg.Clone(),
+		scanner:       scanner,
+		clipboard:     clipboard,
+		folders:       folders,
+		sandbox:       kind,
+		bookmarks:     bookmarks,
+		treeData:      make(map[string][]string),
+		treeDepth:     make(map[string]int),
+		rowIndex:      make(map[string]int),
+		status:        newStatusBar("Application started. Ready to scan"),
+		prerender:     &prerenderCache{},
 	}
 }
 
 // Run starts the application.
 func (app *FileTreeApp) Run() {
+	app.setUp()
+	app.window.ShowAndRun()
+}
+
+// ScanOnStart scans path once the application has started.
+func (app *FileTreeApp) ScanOnStart(path string) {
+	app.app.Lifecycle().SetOnStarted(func() {
+		app.startScan(path, scanOverrides{})
+	})
+}
+
+// Open brings the window to the front and opens path as a dropped folder, or only brings the
+// window forward when path is "". It may be called from any goroutine.
+func (app *FileTreeApp) Open(path string) {
+	fyne.Do(func() {
+		app.window.Show()
+		app.window.RequestFocus()
+		if path != "" {
+			app.handleDrop(path)
+		}
+	})
+}
+
+// setUp fills the window with its content and menu and starts watching the scanned folder
+// until the window is closed.
+func (app *FileTreeApp) setUp() {
 	content := app.createMainContent()
 	app.window.SetContent(content)
+	app.window.SetMainMenu(app.createMainMenu())
 	app.enableDragDrop()
-	app.window.ShowAndRun()
+	app.configChanges.Subscribe(app.configChanged)
+	if app.settings.HighContrast {
+		app.applyTheme()
+	}
+
+	closed := make(chan struct{})
+	app.window.SetOnClosed(func() {
+		// Nothing is left to show a running scan in
+		app.cancelRunningScan(scanner.ReasonUser)
+		app.prerender.stop()
+		app.stopWatching()
+		background.CancelOwner(app)
+		app.releaseFolders()
+		close(closed)
+	})
+	app.startStalePolling(closed)
 }
 
 // createMainContent creates the main UI content.
@@ -113,31 +254,182 @@ func (app *FileTreeApp) createMainContent() fyne.CanvasObject {
 	selectBtn := widget.NewButton(folderIcon+" Select Folder", app.handleSelectFolder)
 	saveBtn := widget.NewButton("💾 Save to File", app.handleSaveToFile)
 	copyBtn := widget.NewButton("📋 Copy to Clipboard", app.handleCopyToClipboard)
+	app.refreshBtn = widget.NewButton("🔄 Refresh", app.handleRefresh)
+	app.busyControls = append(app.busyControls, saveBtn, copyBtn)
 
-	buttonContainer := container.NewGridWithColumns(3,
+	buttonContainer := container.NewGridWithColumns(4,
 		selectBtn,
+		app.refreshBtn,
 		saveBtn,
 		copyBtn,
 	)
 
-	// Initialize tree
+	// Patterns apply from the next scan
+	patternRows := container.NewGridWithColumns(2,
+		app.createPatternEntry("Include", "e.g. *.go, *.md (empty = all files)", &app.settings.IncludePatterns),
+		app.createPatternEntry("Exclude", "e.g. *.log, node_modules, build/**", &app.settings.ExcludePatterns),
+	)
+
+	// Initialize tree and text preview
 	app.tree = app.createTree()
+	app.preview = newTextPreview(app.copyPreviewSelection)
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Tree", app.tree),
+		container.NewTabItem("Text", app.preview.content()),
+	)
 
 	// Main layout
-	header := container.NewVBox(title, buttonContainer, app.statusLabel)
-	content := container.NewBorder(header, nil, nil, nil, app.tree)
+	toolbar := container.NewHBox(app.createSortControls(), app.createAutoRefreshToggle())
+	header := container.NewVBox(title, buttonContainer, patternRows, toolbar, app.createSourceBanner(), app.createStaleBanner(), app.createFolderBanner())
+	content := container.NewBorder(header, app.status.content(), nil, nil, tabs)
 
 	return content
 }
 
+// createPatternEntry creates a labeled entry editing a comma-separated pattern list in the
+// settings. Typing only stages the list: Enter or the Apply button puts it into effect once it
+// is valid, so a half-typed pattern never reaches a scan or the saved settings.
+func (app *FileTreeApp) createPatternEntry(label, placeholder string, patterns *[]string) fyne.CanvasObject {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(placeholder)
+	entry.SetText(strings.Join(*patterns, ", "))
+	entry.Validator = func(text string) error {
+		return scanner.ValidatePatterns(scanner.ParsePatterns(text))
+	}
+
+	apply := widget.NewButton("Apply", nil)
+	apply.Disable()
+	staged := func(text string) bool {
+		parsed := scanner.ParsePatterns(text)
+		return scanner.ValidatePatterns(parsed) == nil && !slices.Equal(parsed, *patterns)
+	}
+	commit := func() {
+		if !staged(entry.Text) {
+			return
+		}
+		*patterns = scanner.ParsePatterns(entry.Text)
+		apply.Disable()
+		app.applySettings()
+	}
+	apply.OnTapped = commit
+	entry.OnSubmitted = func(string) { commit() }
+	entry.OnChanged = func(text string) {
+		if staged(text) {
+			apply.Enable()
+		} else {
+			apply.Disable()
+		}
+	}
+	return container.NewBorder(nil, nil, widget.NewLabel(label), apply, entry)
+}
+
+// createMainMenu creates the window's main menu.
+func (app *FileTreeApp) createMainMenu() *fyne.MainMenu {
+	app.refreshItem = fyne.NewMenuItem("Refresh", app.handleRefresh)
+	app.refreshItem.Disabled = app.sourceMissing
+	saveItem := fyne.NewMenuItem("Save to File…", app.handleSaveToFile)
+	inventoryItem := fyne.NewMenuItem("Export Inventory (CSV)…", app.handleExportInventory)
+	app.packItem = fyne.NewMenuItem("Export Context Pack…", app.handleExportContextPack)
+	exportAllItem := fyne.NewMenuItem("Export All…", app.handleExportAll)
+	app.busyItems = []*fyne.MenuItem{saveItem, inventoryItem, app.packItem, exportAllItem}
+	app.undoSettingsItem = fyne.NewMenuItem("Undo Settings Change", app.handleUndoSettings)
+	app.undoSettingsItem.Disabled = app.undoSettings == nil
+
+	fileMenu := fyne.NewMenu("File",
+		fyne.NewMenuItem("Select Folder…", app.handleSelectFolder),
+		app.refreshItem,
+		fyne.NewMenuItem("Paste Path Listing…", app.handlePasteListing),
+		fyne.NewMenuItem("Open Path Listing…", app.handleOpenListing),
+		fyne.NewMenuItem("Open Snapshot…", app.handleOpenSnapshot),
+		fyne.NewMenuItem("Open Archive…", app.handleOpenArchive),
+		fyne.NewMenuItemSeparator(),
+		saveItem,
+		fyne.NewMenuItem("Save Snapshot…", app.handleSaveSnapshot),
+		inventoryItem,
+		app.packItem,
+		exportAllItem,
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Save Session…", app.handleSaveSession),
+		fyne.NewMenuItem("Open Session…", app.handleOpenSession),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Copy to Clipboard", app.handleCopyToClipboard),
+		fyne.NewMenuItem("Copy Directory Cards", app.handleCopyDirectoryCards),
+		fyne.NewMenuItem("Copy as Outline", app.handleCopyOutline),
+	)
+	editMenu := fyne.NewMenu("Edit",
+		app.createCopyCommandMenu(),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Exclude Selected from View", app.handleExcludeSelected),
+		fyne.NewMenuItem("Restore Excluded Entries", app.handleRestoreExclusions),
+		fyne.NewMenuItem("Copy Exclusion List", app.handleCopyExclusionList),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Settings…", app.handleSettings),
+		fyne.NewMenuItem("Options for This Folder…", app.handleFolderOptions),
+		app.undoSettingsItem,
+	)
+	toolsMenu := fyne.NewMenu("Tools",
+		fyne.NewMenuItem("Statistics…", app.handleStatistics),
+		fyne.NewMenuItem("Compare Fingerprints…", app.handleCompareFingerprints),
+		fyne.NewMenuItem("Duplicate Folders…", app.handleDuplicateFolders),
+		fyne.NewMenuItem("Check Layout…", app.handleCheckLayout),
+		fyne.NewMenuItem("Snapshots…", app.handleSnapshots),
+		fyne.NewMenuItem("Compare with Snapshot…", app.handleCompareWithSnapshot),
+		fyne.NewMenuItem("Explain Exclusion…", app.handleExplainExclusion),
+		fyne.NewMenuItem("Check Source Folder", app.handleCheckSource),
+	)
+	helpMenu := fyne.NewMenu("Help",
+		fyne.NewMenuItem("Diagnostics…", app.handleDiagnostics),
+		fyne.NewMenuItem("Copy Diagnostic Bundle…", app.handleCopyDiagnosticBundle),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("About…", app.handleAbout),
+	)
+	return fyne.NewMainMenu(fileMenu, editMenu, toolsMenu, helpMenu)
+}
+
 // createTree creates the tree widget.
 func (app *FileTreeApp) createTree() *widget.Tree {
-	return widget.NewTree(
+	tree := widget.NewTree(
 		app.childUIDs,
 		app.isBranch,
 		app.createTreeNode,
 		app.updateTreeNode,
 	)
+	tree.OnBranchOpened = func(string) { app.reindexRows() }
+	tree.OnBranchClosed = func(string) { app.reindexRows() }
+	tree.OnSelected = func(uid string) { app.selectedPath = uid }
+	tree.OnUnselected = func(string) { app.selectedPath = "" }
+	return tree
+}
+
+// reindexRows recomputes the visible row order used for alternate row shading.
+// It only runs when branches open or close, keeping scrolling free of tree walks.
+func (app *FileTreeApp) reindexRows() {
+	app.rowIndex = app.visibleRows(app.getCurrentRootPath(), app.treeData)
+	if app.tree != nil {
+		app.tree.Refresh()
+	}
+}
+
+// visibleRows numbers the rows currently shown below root, given the tree's open branches.
+func (app *FileTreeApp) visibleRows(root string, treeData map[string][]string) map[string]int {
+	rows := make(map[string]int)
+	if !app.settings.TreeShading || app.tree == nil || root == "" {
+		return rows
+	}
+
+	var walk func(uid string)
+	walk = func(uid string) {
+		rows[uid] = len(rows)
+		if !app.tree.IsBranchOpen(uid) {
+			return
+		}
+		for _, child := range treeData[uid] {
+			walk(child)
+		}
+	}
+	walk(root)
+	return rows
 }
 
 // childUIDs returns child UIDs for the tree widget.
@@ -164,27 +456,56 @@ func (app *FileTreeApp) createTreeNode(branch bool) fyne.CanvasObject {
 	if branch {
 		icon = folderIcon
 	}
-	return widget.NewLabel(icon + " Item")
+	row := newTreeRow(icon + " Item")
+	row.setStyle(app.settings.TreeDensity == densityCompact, app.settings.Monospace, false)
+	return row
 }
 
 // updateTreeNode updates a tree node widget.
 func (app *FileTreeApp) updateTreeNode(uid string, branch bool, obj fyne.CanvasObject) {
-	label, ok := obj.(*widget.Label)
+	row, ok := obj.(*treeRow)
 	if !ok {
 		return
 	}
 
 	name := filepath.Base(uid)
-	if uid == app.getCurrentRootPath() {
+	if uid == app.getCurrentRootPath() || app.isCombinedRoot(uid) {
 		name = uid // Show full path for root
 	}
+	if node := app.treeNodes[uid]; node != nil && node.IsSymlink && node.ShownTarget() != "" {
+		name += " -> " + node.ShownTarget()
+		if node.LinkBroken {
+			name += " (broken)"
+		}
+	}
 
 	icon := fileIcon
 	if branch {
 		icon = folderIcon
 	}
 
-	label.SetText(icon + " " + name)
+	shaded := app.settings.TreeShading && app.rowIndex[uid]%2 == 1
+	if node := app.treeNodes[uid]; node != nil && node.Placeholder {
+		row.setStyle(app.settings.TreeDensity == densityCompact, app.settings.Monospace, true)
+		row.update(node.Name, app.treeDepth[uid], shaded, false, app.settings.TreeGuides)
+		return
+	}
+	row.setStyle(app.settings.TreeDensity == densityCompact, app.settings.Monospace, false)
+	text := app.modePrefix(app.treeNodes[uid]) + icon + " " + name + app.detailSuffix(app.treeNodes[uid]) + annotate.Suffix(app.treeNodes[uid])
+	if node := app.treeNodes[uid]; node != nil && node.MountPoint {
+		text += " " + renderer.MountMark
+	}
+	style := app.annotationStyle()
+	mark := app.layoutMark(uid)
+	if mark != "" {
+		text = style.Prefix(renderer.AnnotationFlagged) + text + "  ⚠ " + mark
+	}
+	change := app.comparisonChange(uid)
+	if change != diff.Unchanged {
+		text = style.Mark(change.Annotation()) + " " + text + "  (" + string(change) + ")"
+	}
+	flagged := style.Colored() && (mark != "" || change == diff.Removed)
+	row.update(text, app.treeDepth[uid], shaded, flagged, app.settings.TreeGuides)
 }
 
 // getCurrentRootPath returns the current root path.
@@ -206,110 +527,314 @@ func (app *FileTreeApp) handleSelectFolder() {
 			return // User cancelled
 		}
 
-		app.scanDirectoryAsync(folder.Path())
+		if folder.Scheme() != "file" {
+			app.scanFolderURI(folder)
+			return
+		}
+		app.rememberFolder(folder.Path())
+		app.startScan(folder.Path(), scanOverrides{})
 	}, app.window)
 
 	folderDialog.Show()
 }
 
-// scanDirectoryAsync scans a directory asynchronously.
-func (app *FileTreeApp) scanDirectoryAsync(path string) {
-	// Cancel any ongoing operation
-	if app.cancelFunc != nil {
-		app.cancelFunc()
+// scanOverrides adjusts a single scan without changing the saved settings.
+type scanOverrides struct {
+	excluded   []string            // Entries of the scanned directory to leave out
+	showHidden bool                // Include hidden entries even when the setting hides them
+	addTo      *scanner.ScanResult // Result the scanned directory joins as another root, nil to replace it
+	refresh    bool                // Rescans a folder already shown, so scans started anew go first
+}
+
+// overridesContext returns ctx carrying the filter overrides of a scan, for the scan itself and
+// whatever filters like it, such as the watch and Explain Exclusion.
+func (app *FileTreeApp) overridesContext(ctx context.Context, overrides scanOverrides) context.Context {
+	if len(overrides.excluded) > 0 {
+		ctx = scanner.WithExcludedNames(ctx, overrides.excluded)
+	}
+	if overrides.showHidden {
+		ctx = scanner.WithShowHidden(ctx)
 	}
+	return ctx
+}
+
+// scanDirectoryAsync scans a directory asynchronously with the given per-scan overrides.
+func (app *FileTreeApp) scanDirectoryAsync(path string, overrides scanOverrides) {
+	app.scanDirectoriesAsync([]string{path}, overrides)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// scanDirectoriesAsync scans directories asynchronously as one result, combining them when
+// there are several, with the given per-scan overrides.
+func (app *FileTreeApp) scanDirectoriesAsync(paths []string, overrides scanOverrides) {
+	path := describePaths(paths)
+
+	// Cancel any ongoing operation
+	app.cancelRunningScan(scanner.ReasonSuperseded)
+	app.prerender.stop()
+	app.stopWatching()
+	app.watchOverrides = overrides
+	folder := app.applyFolderOptions(paths, overrides)
+	app.accessFolders(paths, overrides)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
 	app.cancelFunc = cancel
+	ctx = app.overridesContext(ctx, overrides)
+
+	// A new scan never starts paused
+	app.ResumeScan()
 
 	// Create progress dialog
-	progressBar := widget.NewProgressBarInfinite()
-	progressBar.Start()
-	progress := dialog.NewCustomWithoutButtons("Scanning", progressBar, app.window)
+	progressLabel := newProgressLabel(path, app.formatter())
+	progress := dialog.NewCustomWithoutButtons("Scanning", app.createProgressContent(progressLabel, func() { cancel(scanner.Stop(scanner.ReasonUser, "")) }), app.window)
 
 	// UI updates must be dispatched to the main thread
 	fyne.Do(func() {
 		progress.Show()
-		app.statusLabel.SetText("Scanning: " + path)
 	})
+	op := app.beginOperation(opScanning, "Scanning: "+path)
 
 	go func() {
 		defer func() {
+			var failure error
 			if r := recover(); r != nil {
 				log.Printf("Panic during scan: %v", r)
-				// UI updates must use main thread dispatcher
-				fyne.Do(func() {
-					app.statusLabel.SetText("Scan failed due to panic")
-				})
+				failure = fmt.Errorf("unexpected failure: %v", r)
 			}
 			// UI updates must use main thread dispatcher
 			fyne.Do(func() {
-				progressBar.Stop()
 				progress.Hide()
+				op.end("Scan failed", failure)
 			})
-			cancel()
+			cancel(nil)
 		}()
 
-		result, err := app.scanner.ScanDirectory(ctx, path)
+		priority := sched.Interactive
+		if overrides.refresh {
+			priority = sched.Refresh
+		}
+		var result *scanner.ScanResult
+		var stop *scanner.StopError
+		err := background.Run(ctx, app, priority, func(ctx context.Context) error {
+			// Time spent waiting for the scans of other windows, or paused, does not count against
+			// the budget
+			if limit := app.config.ScanTimeout; limit > 0 {
+				var stopTimer context.CancelFunc
+				ctx, stopTimer = scanner.WithScanBudget(ctx, app.scanner, limit)
+				defer stopTimer()
+			}
+			var err error
+			if progressScanner, ok := app.scanner.(scanner.ProgressScanner); ok {
+				result, err = progressScanner.ScanDirectoriesWithProgress(ctx, paths, progressLabel.report)
+			} else {
+				result, err = app.scanner.ScanDirectories(ctx, paths)
+			}
+			stop = scanner.StopCause(ctx)
+			return err
+		})
+		if stop == nil {
+			stop = scanner.StopCause(ctx) // Cancelled while waiting
+		}
+		if result != nil && result.Root != nil && overrides.addTo != nil {
+			result = scanner.Combine(overrides.addTo, result)
+		}
 
 		// Generate tree text using renderer
 		if result != nil && result.Root != nil {
-			result.TreeText = app.renderer.RenderTree(result.Root)
+			annotate.Prepare(result.Root)
+			app.renderText(result)
 		}
 
 		// UI updates must use main thread dispatcher
 		fyne.Do(func() {
+			// Hidden first: removing an overlay removes those shown above it, so hiding it only
+			// after the dialogs below would take them down with it
+			progress.Hide()
 			if err != nil {
-				if err == context.Canceled {
-					app.statusLabel.SetText("Scan cancelled")
-					return
-				}
-				if err == context.DeadlineExceeded {
-					app.statusLabel.SetText("Scan timed out (directory too large)")
+				if stop != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+					log.Printf("Warning: %s: %s", stop.Error(), path)
+					switch {
+					case stop.Reason == scanner.ReasonSuperseded:
+						// The newer scan or import reports for itself
+					case result != nil && result.Partial:
+						// Keep what was read rather than throwing it away
+						app.showResult(result)
+						app.showFolderOptions(folder)
+						app.setSourceMissing(false)
+						app.status.setMessage(app.partialMessage(result))
+					default:
+						app.status.setMessage(stop.Error() + ": " + path)
+					}
 					return
 				}
+				err = app.sandbox.Explain(err)
 				app.showError("Scan Error", err)
-				app.statusLabel.SetText("Scan failed")
+				app.recordFailure("Scan failed", err)
+				if path == app.getCurrentRootPath() {
+					app.checkSource()
+				}
 				return
 			}
 
 			// Update tree data and UI (no locks!)
-			app.updateTreeDataSimple(result)
-			app.statusLabel.SetText(fmt.Sprintf("Scanned %d items from: %s", result.NodeCount, path))
+			app.showResult(result)
+			app.showFolderOptions(folder)
+			app.setSourceMissing(false)
+			if result.Truncated {
+				message := result.TruncatedReason.Message(result.TruncatedLimit)
+				if result.TruncatedReason == scanner.ReasonNodeLimit {
+					message = fmt.Sprintf("Stopped after %s items — raise the limit in Settings to scan more", app.formatter().Int(result.DirCount+result.FileCount))
+				}
+				app.status.setMessage(message + ": " + path)
+				dialog.ShowInformation("Partial Result", fmt.Sprintf(msgScanTruncated, message), app.window)
+				return
+			}
+			if overrides.addTo != nil {
+				app.status.setMessage("Scanned " + path + " as another root")
+			} else {
+				app.status.setMessage("Scanned " + path)
+			}
+			if hint := app.sandboxHint(result); hint != "" {
+				app.status.setMessage("Scanned " + path + " — nothing could be read")
+				dialog.ShowInformation("Empty Folder", hint, app.window)
+				return
+			}
+			if len(paths) == 1 && overrides.addTo == nil && !overrides.showHidden && !app.config.ShowHidden && mostlyHidden(result) {
+				app.offerShowHidden(path, overrides)
+				return
+			}
 			dialog.ShowInformation("Success", msgScanSuccess, app.window)
 		})
 	}()
 }
 
-// updateTreeDataSimple updates the tree data with scan results using a simpler approach.
-func (app *FileTreeApp) updateTreeDataSimple(result *scanner.ScanResult) {
-	app.currentResult = result
-	app.treeData = make(map[string][]string)
+// partialMessage describes a result kept from a cancelled or timed-out scan.
+func (app *FileTreeApp) partialMessage(result *scanner.ScanResult) string {
+	why := string(result.TruncatedReason)
+	if result.TruncatedReason == scanner.ReasonTimeout {
+		why = "timed out"
+		if result.TruncatedLimit != "" {
+			why += " after " + result.TruncatedLimit
+		}
+	}
+	return fmt.Sprintf("Partial results (%s): %s items", why, app.formatter().Int(result.NodeCount))
+}
+
+// cancelRunningScan cancels the scan in progress, if any, recording reason as the cause.
+func (app *FileTreeApp) cancelRunningScan(reason scanner.CancelReason) {
+	if app.cancelFunc != nil {
+		app.cancelFunc(scanner.Stop(reason, ""))
+	}
+}
+
+// createProgressContent creates the progress dialog body with pause and cancel controls.
+func (app *FileTreeApp) createProgressContent(progress *progressLabel, cancel context.CancelFunc) fyne.CanvasObject {
+	cancelBtn := widget.NewButton("Cancel", cancel)
+
+	content := container.NewVBox(progress.label)
+	if _, ok := app.scanner.(scanner.PriorityScanner); ok {
+		// Takes effect from the next directory the running scan reads
+		background := widget.NewCheck("Background priority", func(checked bool) {
+			app.settings.Background = checked
+			app.applySettings()
+		})
+		background.SetChecked(app.settings.Background)
+		content.Add(background)
+	}
+
+	if _, ok := app.scanner.(scanner.PausableScanner); !ok {
+		content.Add(cancelBtn)
+		return content
+	}
+
+	var pauseBtn *widget.Button
+	pauseBtn = widget.NewButton("⏸ Pause", func() {
+		if app.ScanPaused() {
+			app.ResumeScan()
+			pauseBtn.SetText("⏸ Pause")
+			return
+		}
+		app.PauseScan()
+		pauseBtn.SetText("▶ Resume")
+	})
+
+	content.Add(container.NewGridWithColumns(2, pauseBtn, cancelBtn))
+	return content
+}
+
+// PauseScan suspends the running scan, if the scanner supports it.
+func (app *FileTreeApp) PauseScan() {
+	if pausable, ok := app.scanner.(scanner.PausableScanner); ok {
+		pausable.Pause()
+		app.status.setMessage("Scan paused")
+	}
+}
+
+// ResumeScan continues a paused scan.
+func (app *FileTreeApp) ResumeScan() {
+	if pausable, ok := app.scanner.(scanner.PausableScanner); ok && pausable.Paused() {
+		pausable.Resume()
+		app.status.setMessage("Scan resumed")
+	}
+}
+
+// ScanPaused reports whether the current scan is paused.
+func (app *FileTreeApp) ScanPaused() bool {
+	pausable, ok := app.scanner.(scanner.PausableScanner)
+	return ok && pausable.Paused()
+}
 
-	// Build tree data from the complete TreeNode structure
+// updateTreeDataSimple replaces the displayed result. The new tree data is built off to the
+// side and swapped in with a single refresh, so the tree never renders a half-built map.
+// Open branches are keyed by path in the tree widget, so rescanning an unchanged folder
+// keeps its expansion and scroll position and is visually a no-op.
+func (app *FileTreeApp) updateTreeDataSimple(result *scanner.ScanResult) {
+	treeData := make(map[string][]string)
+	treeDepth := make(map[string]int)
+	var treeNodes map[string]*scanner.TreeNode
+	if annotate.Active() || result.HasSizes {
+		treeNodes = make(map[string]*scanner.TreeNode)
+	}
 	if result.Root != nil {
-		app.buildTreeDataFromTreeNode(result.Root)
+		buildTreeData(result.Root, 0, treeData, treeDepth, treeNodes)
+	}
+	rowIndex := app.visibleRows(result.RootPath, treeData)
+
+	app.currentResult = result
+	app.treeData = treeData
+	app.treeDepth = treeDepth
+	app.treeNodes = treeNodes
+	app.rowIndex = rowIndex
+	app.showResultStatus(result)
+	app.schedulePrerender()
+	if app.staleBanner != nil {
+		app.staleBanner.Hide()
 	}
 
-	// Refresh tree on UI thread
 	if app.tree != nil {
 		app.tree.Refresh()
 	}
-}
-
-// buildTreeDataFromTreeNode recursively builds tree data from TreeNode structure.
-func (app *FileTreeApp) buildTreeDataFromTreeNode(node *scanner.TreeNode) {
-	if node == nil {
-		return
+	if app.preview != nil {
+		app.preview.SetText(result.TreeText)
 	}
+}
 
+// buildTreeData recursively records the child paths and depth of every node below node,
+// and the node itself when treeNodes is not nil.
+func buildTreeData(node *scanner.TreeNode, depth int, treeData map[string][]string, treeDepth map[string]int, treeNodes map[string]*scanner.TreeNode) {
 	var children []string
 	for _, child := range node.Children {
 		children = append(children, child.Path)
-		// Recursively process children
-		app.buildTreeDataFromTreeNode(child)
+		buildTreeData(child, depth+1, treeData, treeDepth, treeNodes)
+	}
+	for _, placeholder := range renderer.Placeholders(node) {
+		children = append(children, placeholder.Path)
+		buildTreeData(placeholder, depth+1, treeData, treeDepth, treeNodes)
+	}
+	treeData[node.Path] = children
+	treeDepth[node.Path] = depth
+	if treeNodes != nil {
+		treeNodes[node.Path] = node
 	}
-	app.treeData[node.Path] = children
 }
 
 // handleSaveToFile handles saving tree to file.
@@ -331,19 +856,68 @@ func (app *FileTreeApp) handleSaveToFile() {
 		if writer == nil {
 			return // User cancelled
 		}
-		defer writer.Close()
+		app.saveResult(result, writer)
+	}, app.window)
+
+	saveDialog.SetFileName(defaultName)
+	saveDialog.Show()
+}
 
-		_, werr := writer.Write([]byte(result.TreeText))
-		if werr != nil {
-			app.showError("Save Error", werr)
+// saveResult renders result in the format named by the extension of writer's file and writes it
+// in the background. The window returns to idle however rendering or writing ends.
+func (app *FileTreeApp) saveResult(result *scanner.ScanResult, writer fyne.URIWriteCloser) {
+	path, name := writer.URI().Path(), writer.URI().Name()
+	var stats renderer.OutputStats
+	app.runOperation(opRendering, "Rendering "+name+"…", "Could not save "+name, func(o activeOperation) error {
+		var err error
+		stats, err = app.writeResult(result, path, writer, func() { o.advance(opExporting, "Saving "+name+"…") })
+		return err
+	}, func(err error) {
+		if err != nil {
+			app.showError("Save Error", err)
 			return
 		}
+		app.recordExport(path)
+		app.showSaved(stats, name)
+	})
+}
 
-		dialog.ShowInformation("Success", msgSaveSuccess, app.window)
-	}, app.window)
+// writeResult renders result in the format named by the extension of path and writes it through
+// writer, which is closed either way, calling saving once it is rendered. Database formats are
+// written by path instead.
+func (app *FileTreeApp) writeResult(result *scanner.ScanResult, path string, writer io.WriteCloser, saving func()) (renderer.OutputStats, error) {
+	var stats renderer.OutputStats
+	if fileExporter := exporter.ForPath(path); fileExporter != nil {
+		writer.Close()
+		saving()
+		if err := fileExporter.Export(context.Background(), result, path); err != nil {
+			return stats, err
+		}
+		if info, err := os.Stat(path); err == nil {
+			stats.Bytes = info.Size()
+		}
+		return stats, nil
+	}
+	defer writer.Close()
 
-	saveDialog.SetFileName(defaultName)
-	saveDialog.Show()
+	text := result.TreeText
+	if job, rendered := app.saveJob(result, path); rendered {
+		text = app.prerender.get(job)
+	}
+	saving()
+	out := renderer.NewCountingWriter(writer)
+	if _, err := out.WriteString(text); err != nil {
+		return stats, err
+	}
+	// A full disk often shows only when the file is closed
+	return out.Stats(), writer.Close()
+}
+
+// showSaved reports a saved file with what was written to it.
+func (app *FileTreeApp) showSaved(stats renderer.OutputStats, name string) {
+	message := fmt.Sprintf(msgSaveSuccess, app.describeOutput(stats), name)
+	app.status.setMessage(message)
+	dialog.ShowInformation("Success", message, app.window)
 }
 
 // handleCopyToClipboard handles copying tree to clipboard.
@@ -359,8 +933,66 @@ func (app *FileTreeApp) handleCopyToClipboard() {
 		app.showError("Clipboard Error", err)
 		return
 	}
+	app.lastCopy = copyTree
+
+	message := fmt.Sprintf(msgCopySuccess, app.describeOutput(renderer.OutputStats{Bytes: int64(len(result.TreeText)), Lines: result.TreeLines}))
+	app.status.setMessage(message)
+	dialog.ShowInformation("Success", message, app.window)
+}
+
+// handleCopyDirectoryCards copies a Markdown card for each top-level directory. Directories
+// excluded from the view keep their card, noted as excluded.
+func (app *FileTreeApp) handleCopyDirectoryCards() {
+	result := app.baseResult
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
 
-	dialog.ShowInformation("Success", msgCopySuccess, app.window)
+	cards := app.prerender.get(app.cardsJob(result, app.cardExclusions()))
+	if err := app.clipboard.SetContent(cards); err != nil {
+		app.showError("Clipboard Error", err)
+		return
+	}
+	app.lastCopy = copyCards
+	app.status.setMessage(fmt.Sprintf(msgCopySuccess, "directory cards"))
+}
+
+// handleCopyOutline copies the shown tree as a plain indented outline, without connectors or icons.
+func (app *FileTreeApp) handleCopyOutline() {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+
+	outline := app.prerender.get(app.outlineJob(result))
+	if err := app.clipboard.SetContent(outline); err != nil {
+		app.showError("Clipboard Error", err)
+		return
+	}
+	app.lastCopy = copyOutline
+	app.status.setMessage(fmt.Sprintf(msgCopySuccess, "outline"))
+}
+
+// cardExclusions returns the top-level directories excluded from the view.
+func (app *FileTreeApp) cardExclusions() []string {
+	var excluded []string
+	for _, rel := range app.viewExclusions {
+		if name, ok := strings.CutSuffix(rel, "/"); ok && !strings.Contains(name, "/") {
+			excluded = append(excluded, name)
+		}
+	}
+	return excluded
+}
+
+// copyPreviewSelection copies the text highlighted in the preview to the clipboard.
+func (app *FileTreeApp) copyPreviewSelection(text string) {
+	if err := app.clipboard.SetContent(text); err != nil {
+		app.showError("Clipboard Error", err)
+		return
+	}
+	app.status.setMessage("Selection copied to clipboard")
 }
 
 // getCurrentResult returns the current scan result.
@@ -373,25 +1005,36 @@ func (app *FileTreeApp) showError(title string, err error) {
 	dialog.ShowError(fmt.Errorf("%s: %w", title, err), app.window)
 }
 
-// enableDragDrop enables drag and drop functionality.
+// enableDragDrop enables drag and drop functionality. Several folders dropped at once are
+// scanned together; an archive is opened when it is dropped on its own.
 func (app *FileTreeApp) enableDragDrop() {
 	app.window.SetOnDropped(func(position fyne.Position, uris []fyne.URI) {
-		if len(uris) > 0 {
-			uri := uris[0] // Take first dropped item
-
+		var paths []string
+		for _, uri := range uris {
 			// Convert URI to local path
-			if uri.Scheme() == "file" {
-				path := uri.Path()
-
-				// Check if it's a directory
-				if info, err := os.Stat(path); err == nil && info.IsDir() {
-					app.scanDirectoryAsync(path)
-				} else {
-					dialog.ShowError(fmt.Errorf("please drop a folder, not a file"), app.window)
-				}
-			} else {
+			if uri.Scheme() != "file" {
 				dialog.ShowError(fmt.Errorf("invalid file path"), app.window)
+				return
 			}
+			path := uri.Path()
+
+			// Folders are scanned and archives opened like folders
+			info, err := os.Stat(path)
+			switch {
+			case err == nil && info.IsDir():
+				app.rememberFolder(path) // Dropping grants the sandbox access as choosing does
+			case err == nil && importer.IsArchive(path) && len(uris) == 1:
+			case err == nil && importer.IsArchive(path):
+				dialog.ShowError(fmt.Errorf("drop archives one at a time, not together with other items"), app.window)
+				return
+			default:
+				dialog.ShowError(fmt.Errorf("please drop a folder or an archive (.zip, .tar, .tar.gz, .tgz), not another file"), app.window)
+				return
+			}
+			paths = append(paths, path)
+		}
+		if len(paths) > 0 {
+			app.handleDrop(paths...)
 		}
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,21 @@
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+// newTestApp returns a set-up window of an application running on Fyne's test driver, with the
+// user's configuration directory in a temporary folder.
+func newTestApp(t *testing.T) *FileTreeApp {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	app := newWindow(test.NewTempApp(t), config.DefaultConfig())
+	app.setUp()
+	t.Cleanup(app.window.Close)
+	return app
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/shellcmd"
+)
+
+// createCopyCommandMenu creates the "Copy as Command" submenu item.
+func (app *FileTreeApp) createCopyCommandMenu() *fyne.MenuItem {
+	item := fyne.NewMenuItem("Copy as Command", nil)
+	item.ChildMenu = fyne.NewMenu("",
+		fyne.NewMenuItem("tar Archive", func() { app.copyCommand("tar", shellcmd.Tar) }),
+		fyne.NewMenuItem("zip Archive", func() { app.copyCommand("zip", shellcmd.Zip) }),
+		fyne.NewMenuItem("rsync Include List", func() { app.copyCommand("rsync", shellcmd.Rsync) }),
+	)
+	return item
+}
+
+// copyCommand copies a command packaging the selected tree item, or the whole scan when
+// nothing is selected, in the shell chosen in Settings.
+func (app *FileTreeApp) copyCommand(name string, build func(shellcmd.Selection, shellcmd.Shell) string) {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+
+	node := findNode(result.Root, app.selectedPath)
+	if node == nil {
+		node = result.Root
+	}
+	sel := shellcmd.Select(result.RootPath, node)
+	if len(sel.Paths) == 0 {
+		dialog.ShowInformation("Nothing to Copy", "The selection contains no files.", app.window)
+		return
+	}
+
+	if err := app.clipboard.SetContent(build(sel, shellcmd.Shell(app.settings.CommandShell))); err != nil {
+		app.showError("Clipboard Error", err)
+		return
+	}
+	app.status.setMessage(fmt.Sprintf("Copied %s command for %s files from %s", name, app.formatter().Int(len(sel.Paths)), sel.Subject))
+}
+
+// findNode returns the node below root with the given path, or nil.
+func findNode(root *scanner.TreeNode, path string) *scanner.TreeNode {
+	if path == "" {
+		return nil
+	}
+	if root.Path == path {
+		return root
+	}
+	for _, child := range root.Children {
+		if found := findNode(child, path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
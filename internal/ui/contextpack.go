@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/contextpack"
+)
+
+// handleExportContextPack saves the current tree and its text files as a single Markdown document.
+func (app *FileTreeApp) handleExportContextPack() {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+	if result.Root.IsVirtual {
+		dialog.ShowInformation("Context Pack", "Context packs need a scanned folder; imported listings have no file contents.", app.window)
+		return
+	}
+
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			app.showError("Export Error", err)
+			return
+		}
+		if writer == nil {
+			return // User cancelled
+		}
+		writer.Close()
+
+		path := writer.URI().Path()
+		opts := contextpack.DefaultOptions()
+		opts.HonorExportIgnore = app.settings.HonorExportIgnore
+		opts.Redactor = app.redactor()
+		app.runOperation(opExporting, "Exporting context pack to "+path+"…", "Could not export context pack", func(activeOperation) error {
+			return (&contextpack.Exporter{Options: opts}).Export(context.Background(), result, path)
+		}, func(err error) {
+			if err != nil {
+				app.showError("Export Error", err)
+				return
+			}
+			app.recordExport(path)
+			app.status.setMessage(fmt.Sprintf("Exported context pack to %s", path))
+		})
+	}, app.window)
+	saveDialog.SetFileName(filepath.Base(result.RootPath) + "-context.md")
+	saveDialog.Show()
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/applog"
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+	"github.com/Akaiko1/file-tree-scanner/internal/diagnose"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+)
+
+const msgBundleSuccess = "Diagnostic bundle copied to clipboard!"
+
+// handleCopyDiagnosticBundle asks for bundle options and copies the bundle to the clipboard.
+func (app *FileTreeApp) handleCopyDiagnosticBundle() {
+	anonymize := widget.NewCheck("Anonymize file names, paths and patterns", nil)
+	info := widget.NewLabel("The bundle contains the scan options, statistics, errors,\nthe rendered tree and the last log lines.")
+
+	content := container.NewVBox(info, anonymize)
+	dialog.ShowCustomConfirm("Copy Diagnostic Bundle", "Copy", "Cancel", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+
+		bundle := report.NewBundle(app.getCurrentResult(), app.config, applog.Tail(), anonymize.Checked)
+		text, err := bundle.JSON()
+		if err != nil {
+			app.showError("Diagnostic Bundle Error", err)
+			return
+		}
+		if err := app.clipboard.SetContent(text); err != nil {
+			app.showError("Clipboard Error", err)
+			return
+		}
+		dialog.ShowInformation("Success", msgBundleSuccess, app.window)
+	}, app.window)
+}
+
+// handleDiagnostics runs the environment checks and shows their results.
+func (app *FileTreeApp) handleDiagnostics() {
+	output := widget.NewLabel("Running checks…")
+	output.TextStyle.Monospace = true
+	dialog.ShowCustom("Diagnostics", "Close", container.NewVScroll(output), app.window)
+
+	go func() {
+		var builder strings.Builder
+		diagnose.Write(&builder, diagnose.Run(context.Background()))
+		fyne.Do(func() {
+			output.SetText(builder.String())
+		})
+	}()
+}
+
+// handleAbout shows the application's name and version and the optional features this build
+// supports.
+func (app *FileTreeApp) handleAbout() {
+	heading := "File Tree Scanner"
+	if version := app.app.Metadata().Version; version != "" {
+		heading += " " + version
+	}
+
+	names := capability.List()
+	features := make([]string, len(names))
+	for i, feature := range names {
+		features[i] = "  " + string(feature)
+	}
+	output := widget.NewLabel(heading + "\n\nSupported features:\n" + strings.Join(features, "\n"))
+	dialog.ShowCustom("About", "Close", container.NewVScroll(output), app.window)
+}
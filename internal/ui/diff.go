@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
+	"github.com/Akaiko1/file-tree-scanner/internal/diff"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// maxOpenedChanges is the number of changes whose branches are opened in the tree; the rest are
+// only marked.
+const maxOpenedChanges = 100
+
+// handleCompareWithSnapshot compares the scanned folder with a snapshot chosen in a file dialog,
+// such as one saved before a build, and shows the entries added, removed and modified since in
+// the tree until the next scan.
+func (app *FileTreeApp) handleCompareWithSnapshot() {
+	base := app.baseResult
+	newer := base
+	if app.comparison != nil && app.comparison.Tree == base {
+		newer = app.comparison.New // Compare the scan itself, not the merged tree shown
+	}
+	if newer == nil || newer.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			app.showError("Open Error", err)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		defer reader.Close()
+
+		doc, rerr := report.ReadTree(reader)
+		if rerr != nil {
+			app.showError("Compare with Snapshot", rerr)
+			return
+		}
+		older, rerr := doc.Result()
+		if rerr != nil {
+			app.showError("Compare with Snapshot", rerr)
+			return
+		}
+		if app.baseResult != base {
+			return // A new scan took over while the dialog was open
+		}
+		app.showComparison(diff.Diff(older, newer), reader.URI().Name())
+	}, app.window)
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	openDialog.Show()
+}
+
+// showComparison shows the merged tree of d in place of the scan, marking each change and
+// opening the branches that lead to them. Refreshing scans the folder again as usual.
+func (app *FileTreeApp) showComparison(d *diff.Result, name string) {
+	app.cancelRunningScan(scanner.ReasonSuperseded)
+	app.comparison = d
+	annotate.Prepare(d.Tree.Root)
+	app.renderText(d.Tree)
+	app.showResult(d.Tree)
+	app.setSourceMissing(false)
+
+	opened := 0
+	for _, changes := range [][]*scanner.TreeNode{d.Added, d.Removed, d.Modified} {
+		for _, node := range changes {
+			if app.tree == nil || opened >= maxOpenedChanges {
+				break
+			}
+			if d.Change(node.Path) == diff.Unchanged {
+				continue // The old contents of a directory that became a file, which Tree lacks
+			}
+			for parent := node.Parent; parent != nil; parent = parent.Parent {
+				app.tree.OpenBranch(parent.Path)
+			}
+			opened++
+		}
+	}
+	app.reindexRows()
+
+	since := name
+	if !d.Old.ScannedAt.IsZero() {
+		since = "the snapshot from " + app.formatter().Date(d.Old.ScannedAt.Local())
+	}
+	if d.Empty() {
+		app.status.setMessage("No changes since " + since)
+		return
+	}
+	f := app.formatter()
+	app.status.setMessage(fmt.Sprintf("Since %s: %s added, %s removed, %s modified", since,
+		f.Int(len(d.Added)), f.Int(len(d.Removed)), f.Int(len(d.Modified))))
+}
+
+// comparisonOf returns the comparison whose merged tree result is, or is a view of, or nil.
+func (app *FileTreeApp) comparisonOf(result *scanner.ScanResult) *diff.Result {
+	if !result.ScannedAt.IsZero() {
+		return nil // Scans, which are rendered off the UI thread, are never merged trees
+	}
+	d := app.comparison
+	if d == nil || result.RootPath != d.Tree.RootPath || (result != d.Tree && app.baseResult != d.Tree) {
+		return nil
+	}
+	return d
+}
+
+// comparisonChange returns how the entry at path changed in the comparison shown, if any. Marks
+// belong to the merged tree and disappear once another result is shown.
+func (app *FileTreeApp) comparisonChange(path string) diff.Change {
+	if app.comparison == nil || app.comparison.Tree != app.baseResult {
+		return diff.Unchanged
+	}
+	return app.comparison.Change(path)
+}
@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// What happens to a folder dropped onto a window that already shows a result.
+const (
+	dropAsk       = "ask"
+	dropReplace   = "replace"
+	dropNewWindow = "newWindow"
+	dropAddRoot   = "addRoot" // Combine with the shown result
+)
+
+// dropChoices lists the drop actions for the chooser and settings; the first entry asks each time.
+var dropChoices = []struct{ label, action string }{
+	{"Ask each time", dropAsk},
+	{"Replace the current result", dropReplace},
+	{"Open in a new window", dropNewWindow},
+	{"Add as another root", dropAddRoot},
+}
+
+// handleDrop scans dropped folders, several of them together as one combined result. Once a
+// result is shown, dropping one of its scanned folders offers to refresh it, and other folders
+// follow the remembered drop action or ask for one.
+func (app *FileTreeApp) handleDrop(paths ...string) {
+	if app.baseResult == nil {
+		app.startScans(paths, scanOverrides{})
+		return
+	}
+	if root := app.scannedRoot(paths[0]); len(paths) == 1 && root != "" {
+		dialog.ShowConfirm("Refresh", fmt.Sprintf("%s is already shown. Scan it again?", root), func(refresh bool) {
+			if refresh {
+				app.refreshRoot(root)
+			}
+		}, app.window)
+		return
+	}
+	if app.settings.DropAction == dropAsk {
+		app.showDropChooser(paths)
+		return
+	}
+	app.dropFolders(paths, app.settings.DropAction)
+}
+
+// showDropChooser asks what to do with dropped folders, optionally remembering the answer.
+func (app *FileTreeApp) showDropChooser(paths []string) {
+	var labels []string
+	for _, choice := range dropChoices[1:] {
+		labels = append(labels, choice.label)
+	}
+	actions := widget.NewRadioGroup(labels, nil)
+	actions.Required = true
+	actions.SetSelected(labels[0])
+	remember := widget.NewCheck("Always do this (can be changed in Settings)", nil)
+
+	message := widget.NewLabel(strings.Join(paths, "\n"))
+	content := container.NewVBox(message, actions, remember)
+	dialog.ShowCustomConfirm("Dropped Folder", "Open", "Cancel", content, func(open bool) {
+		if !open {
+			return
+		}
+		action := dropReplace
+		for _, choice := range dropChoices {
+			if choice.label == actions.Selected {
+				action = choice.action
+			}
+		}
+		if remember.Checked {
+			app.settings.DropAction = action
+			app.applySettings()
+		}
+		app.dropFolders(paths, action)
+	}, app.window)
+}
+
+// dropFolders carries out a drop action for paths.
+func (app *FileTreeApp) dropFolders(paths []string, action string) {
+	switch action {
+	case dropNewWindow:
+		app.openInNewWindow(paths)
+	case dropAddRoot:
+		app.startScans(paths, scanOverrides{addTo: app.baseResult})
+	default:
+		app.startScans(paths, scanOverrides{})
+	}
+}
+
+// openInNewWindow scans paths in a window of its own, leaving this window's result in place.
+func (app *FileTreeApp) openInNewWindow(paths []string) {
+	other := newWindow(app.app, app.config.Clone())
+	other.setUp()
+	other.window.Show()
+	other.startScans(paths, scanOverrides{})
+}
+
+// describePaths names scanned folders in messages: the path of a single folder, or how many
+// there are.
+func describePaths(paths []string) string {
+	if len(paths) == 1 {
+		return paths[0]
+	}
+	return fmt.Sprintf("%d folders", len(paths))
+}
+
+// scannedRoot returns the scanned folder of the shown result that path names, or "" if there
+// is none. Imported trees have no scanned folder.
+func (app *FileTreeApp) scannedRoot(path string) string {
+	result := app.baseResult
+	if result == nil || result.Root == nil {
+		return ""
+	}
+	parts := result.Roots
+	if len(parts) == 0 {
+		parts = []*scanner.ScanResult{result}
+	}
+	for _, part := range parts {
+		if !part.Root.IsVirtual && filepath.Clean(part.RootPath) == filepath.Clean(path) {
+			return part.RootPath
+		}
+	}
+	return ""
+}
+
+// isCombinedRoot reports whether uid is one of the folders of a combined result.
+func (app *FileTreeApp) isCombinedRoot(uid string) bool {
+	if app.currentResult == nil {
+		return false
+	}
+	for _, part := range app.currentResult.Roots {
+		if part.RootPath == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// selectedRoot returns the folder of a combined result holding the selected entry, or "" when
+// nothing in a scanned folder is selected. Of nested folders, the innermost wins.
+func (app *FileTreeApp) selectedRoot() string {
+	if app.currentResult == nil || app.selectedPath == "" {
+		return ""
+	}
+	root := ""
+	for _, part := range app.currentResult.Roots {
+		inside := app.selectedPath == part.RootPath || strings.HasPrefix(app.selectedPath, part.RootPath+string(filepath.Separator))
+		if inside && !part.Root.IsVirtual && len(part.RootPath) > len(root) {
+			root = part.RootPath
+		}
+	}
+	return root
+}
+
+// refreshRoot scans path again in place, as the whole result or as one folder of a combined result.
+func (app *FileTreeApp) refreshRoot(path string) {
+	if result := app.baseResult; result != nil && len(result.Roots) > 0 {
+		app.startScan(path, scanOverrides{addTo: result, refresh: true})
+		return
+	}
+	app.startScan(path, scanOverrides{refresh: true})
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// defaultDuplicateDirMin is the entry count a copied folder must reach to be listed when the
+// scan did not look for copies itself.
+const defaultDuplicateDirMin = 10
+
+// handleDuplicateFolders lists the folders of the current result that are copies of each other.
+// Results scanned without looking for them are searched now, which only walks the tree.
+func (app *FileTreeApp) handleDuplicateFolders() {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+	if result.Partial {
+		dialog.ShowInformation("Duplicate Folders", "The scan did not finish, so folders cannot be compared. Refresh to scan again.", app.window)
+		return
+	}
+
+	minItems, groups := result.DuplicateDirMin, result.DuplicateDirs
+	if minItems == 0 {
+		minItems = app.settings.DuplicateDirMin
+		if minItems == 0 {
+			minItems = defaultDuplicateDirMin
+		}
+		groups = scanner.FindDuplicateDirs(result.Root, minItems)
+	}
+
+	output := widget.NewLabel(formatDuplicateDirs(groups, minItems, result.HasSizes, app.formatter()))
+	output.TextStyle.Monospace = true
+	output.Wrapping = fyne.TextWrapOff
+
+	dupDialog := dialog.NewCustom("Duplicate Folders", "Close", container.NewScroll(output), app.window)
+	dupDialog.Resize(fyne.NewSize(windowWidth*0.8, windowHeight*0.6))
+	dupDialog.Show()
+}
+
+// formatDuplicateDirs lists groups of copied folders with their paths, and their sizes when
+// they were collected.
+func formatDuplicateDirs(groups []scanner.DirGroup, minItems int, hasSizes bool, f *locale.Formatter) string {
+	if len(groups) == 0 {
+		return fmt.Sprintf("No copied folders holding %s or more entries.", f.Int(minItems))
+	}
+
+	var builder strings.Builder
+	var waste int64
+	for _, group := range groups {
+		waste += group.Waste()
+	}
+	builder.WriteString(fmt.Sprintf("%s groups of copied folders holding %s or more entries", f.Int(len(groups)), f.Int(minItems)))
+	if hasSizes {
+		builder.WriteString(", wasting " + f.Size(waste))
+	}
+	builder.WriteString("\n")
+	for _, group := range groups {
+		builder.WriteString(fmt.Sprintf("\n%s copies of %s entries", f.Int(len(group.Dirs)), f.Int(group.Items)))
+		if hasSizes {
+			builder.WriteString(", " + f.Size(group.Size) + " each")
+		}
+		builder.WriteString(":\n")
+		for _, dir := range group.Dirs {
+			builder.WriteString("  " + dir.Path + "\n")
+		}
+	}
+	return builder.String()
+}
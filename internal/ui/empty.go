@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// pruneEmptyDirs removes the folders left empty by filtering from the shown result when prune
+// is set, without scanning again. Removed folders only come back with a refresh.
+func (app *FileTreeApp) pruneEmptyDirs(prune bool) {
+	result := app.baseResult
+	if result == nil || result.Root == nil {
+		return
+	}
+	if !prune {
+		app.status.setMessage("Empty folders show again after a refresh")
+		return
+	}
+
+	removed := scanner.PruneEmpty(result.Root)
+	if removed == 0 {
+		return
+	}
+	result.NodeCount -= removed
+	result.DirCount -= removed
+	if result.Skipped == nil {
+		result.Skipped = make(scanner.SkipStats)
+	}
+	result.Skipped[scanner.EmptyDirRule] += removed
+	if len(app.viewExclusions) == 0 {
+		app.renderText(result) // With exclusions, the view is rendered as it is rebuilt
+	}
+	app.applyViewExclusions()
+	app.status.setMessage(fmt.Sprintf("Hid %s empty folders", app.formatter().Int(removed)))
+}
@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// showResult displays a new scan or import. View exclusions carry over when the same root is
+// shown again, so a curated view survives a refresh, and with auto-refresh the folder is watched.
+func (app *FileTreeApp) showResult(result *scanner.ScanResult) {
+	if app.baseResult == nil || app.baseResult.RootPath != result.RootPath {
+		app.viewExclusions = nil
+	}
+	app.baseResult = result
+	app.showFolderOptions(nil)
+	if dropped := app.applyViewExclusions(); dropped > 0 {
+		log.Printf("Warning: dropped %d view exclusions no longer in the tree", dropped)
+	}
+	app.startWatching()
+}
+
+// applyViewExclusions displays the base result without the excluded entries. Exclusions whose
+// path is no longer in the tree are forgotten; the number dropped is returned.
+func (app *FileTreeApp) applyViewExclusions() int {
+	base := app.baseResult
+	if base == nil || base.Root == nil {
+		return 0
+	}
+	if len(app.viewExclusions) == 0 {
+		app.updateTreeDataSimple(base)
+		return 0
+	}
+
+	excluded := make(map[string]bool)
+	for _, rel := range app.viewExclusions {
+		excluded[rel] = true
+	}
+	found := make(map[string]bool)
+	root := pruneTree(base.Root, nil, base.RootPath, excluded, found)
+
+	kept := app.viewExclusions[:0]
+	for _, rel := range app.viewExclusions {
+		if found[rel] {
+			kept = append(kept, rel)
+		}
+	}
+	dropped := len(app.viewExclusions) - len(kept)
+	app.viewExclusions = kept
+
+	view := *base
+	view.Root = root
+	view.NodeCount = countTree(root)
+	scanner.Tally(&view)
+	if view.HasTokens {
+		// What is left out of the view no longer takes up context
+		view.TotalTokens = scanner.SumTokens(root)
+	}
+	app.renderText(&view)
+	app.updateTreeDataSimple(&view)
+	return dropped
+}
+
+// pruneTree returns a copy of node without the excluded entries, recording each exclusion it meets.
+// Nodes are copied shallowly, so the base tree keeps its children.
+func pruneTree(node, parent *scanner.TreeNode, root string, excluded, found map[string]bool) *scanner.TreeNode {
+	copied := *node
+	copied.Parent = parent
+	copied.Children = nil
+	for _, child := range node.Children {
+		if rel := report.RelPath(root, child); excluded[rel] {
+			found[rel] = true
+			continue
+		}
+		copied.Children = append(copied.Children, pruneTree(child, &copied, root, excluded, found))
+	}
+	return &copied
+}
+
+// countTree returns the number of nodes in the tree rooted at node.
+func countTree(node *scanner.TreeNode) int {
+	count := 1
+	for _, child := range node.Children {
+		count += countTree(child)
+	}
+	return count
+}
+
+// handleExcludeSelected hides the selected entry from the view and from everything copied or saved.
+func (app *FileTreeApp) handleExcludeSelected() {
+	if app.baseResult == nil || app.baseResult.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+	node := findNode(app.baseResult.Root, app.selectedPath)
+	if node == nil || node == app.baseResult.Root {
+		dialog.ShowInformation("Exclude", "Select an entry below the root in the tree first.", app.window)
+		return
+	}
+
+	app.viewExclusions = append(app.viewExclusions, report.RelPath(app.baseResult.RootPath, node))
+	app.selectedPath = ""
+	app.applyViewExclusions()
+	app.status.setMessage(fmt.Sprintf("Excluded %s from the view (%s excluded)", node.Name, app.formatter().Int(len(app.viewExclusions))))
+}
+
+// handleRestoreExclusions shows every excluded entry again.
+func (app *FileTreeApp) handleRestoreExclusions() {
+	if len(app.viewExclusions) == 0 {
+		dialog.ShowInformation("Restore", "No entries are excluded from the view.", app.window)
+		return
+	}
+	restored := len(app.viewExclusions)
+	app.viewExclusions = nil
+	app.applyViewExclusions()
+	app.status.setMessage(fmt.Sprintf("Restored %s excluded entries", app.formatter().Int(restored)))
+}
+
+// handleCopyExclusionList copies the view exclusions as .treeignore patterns anchored at the root.
+func (app *FileTreeApp) handleCopyExclusionList() {
+	if len(app.viewExclusions) == 0 {
+		dialog.ShowInformation("Copy Exclusion List", "No entries are excluded from the view.", app.window)
+		return
+	}
+	var patterns strings.Builder
+	for _, rel := range app.viewExclusions {
+		patterns.WriteString("/" + rel + "\n")
+	}
+	if err := app.clipboard.SetContent(patterns.String()); err != nil {
+		app.showError("Clipboard Error", err)
+		return
+	}
+	app.status.setMessage(fmt.Sprintf("Copied %s exclusion patterns", app.formatter().Int(len(app.viewExclusions))))
+}
+
+// handleSaveSession saves the current result with its exclusions and selection as a JSON report.
+func (app *FileTreeApp) handleSaveSession() {
+	result := app.getCurrentResult()
+	if result == nil || app.baseResult == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+
+	var selection []string
+	if node := findNode(app.baseResult.Root, app.selectedPath); node != nil {
+		selection = append(selection, report.RelPath(app.baseResult.RootPath, node))
+	}
+	env := report.NewSessionEnvelope(app.baseResult, result.TreeText, app.config, app.viewExclusions, selection)
+
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			app.showError("Save Error", err)
+			return
+		}
+		if writer == nil {
+			return // User cancelled
+		}
+		defer writer.Close()
+
+		if werr := report.WriteEnvelope(writer, env); werr != nil {
+			app.showError("Save Error", werr)
+			return
+		}
+		app.recordExport(writer.URI().Path())
+		app.status.setMessage("Saved session to " + writer.URI().Path())
+	}, app.window)
+	saveDialog.SetFileName("file_tree_session.json")
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	saveDialog.Show()
+}
+
+// handleOpenSession restores a saved session, re-applying its exclusions and selection.
+func (app *FileTreeApp) handleOpenSession() {
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			app.showError("Open Error", err)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		defer reader.Close()
+
+		env, rerr := report.ReadEnvelope(reader)
+		if rerr != nil {
+			app.showError("Open Error", rerr)
+			return
+		}
+		result, rerr := env.Restore()
+		if rerr != nil {
+			app.showError("Open Error", rerr)
+			return
+		}
+
+		// A restored session supersedes any scan still running
+		app.cancelRunningScan(scanner.ReasonSuperseded)
+		app.stopWatching()
+		app.showFolderOptions(nil)
+		annotate.Prepare(result.Root)
+		app.renderText(result)
+		app.baseResult = result
+		app.viewExclusions = append([]string(nil), env.Exclusions...)
+		dropped := app.applyViewExclusions()
+		app.setSourceMissing(false)
+
+		for _, rel := range env.Selection {
+			path := filepath.Join(result.RootPath, filepath.FromSlash(strings.TrimSuffix(rel, "/")))
+			if findNode(app.getCurrentResult().Root, path) != nil && app.tree != nil {
+				app.tree.Select(path)
+			}
+		}
+
+		message := "Opened session " + reader.URI().Name()
+		if dropped > 0 {
+			message += fmt.Sprintf(" (%s exclusions no longer in the tree were dropped)", app.formatter().Int(dropped))
+		}
+		app.status.setMessage(message)
+	}, app.window)
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	openDialog.Show()
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// handleExplainExclusion shows a dialog reporting which filter rule excludes a path.
+func (app *FileTreeApp) handleExplainExclusion() {
+	result := app.getCurrentResult()
+	if result == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+	explainer, ok := app.scanner.(scanner.ExplainingScanner)
+	if !ok {
+		dialog.ShowInformation("Not Supported", "The active scanner cannot explain exclusions.", app.window)
+		return
+	}
+
+	output := widget.NewLabel("")
+	output.TextStyle.Monospace = true
+
+	pathEntry := widget.NewEntry()
+	pathEntry.SetText(result.RootPath + string(filepath.Separator))
+	explain := func(path string) {
+		explanation, err := explainer.Explain(app.overridesContext(context.Background(), app.watchOverrides), result.RootPath, path)
+		if err != nil {
+			output.SetText(err.Error())
+			return
+		}
+		output.SetText(formatExplanation(explanation))
+	}
+	pathEntry.OnSubmitted = explain
+
+	explainBtn := widget.NewButton("Explain", func() { explain(pathEntry.Text) })
+	pickBtn := widget.NewButton("Pick…", func() {
+		dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			reader.Close()
+			pathEntry.SetText(reader.URI().Path())
+			explain(reader.URI().Path())
+		}, app.window)
+	})
+
+	inputRow := container.NewBorder(nil, nil, nil, container.NewHBox(pickBtn, explainBtn), pathEntry)
+	content := container.NewBorder(inputRow, nil, nil, nil, container.NewVScroll(output))
+
+	explainDialog := dialog.NewCustom("Explain Exclusion", "Close", content, app.window)
+	explainDialog.Resize(fyne.NewSize(windowWidth*0.8, windowHeight*0.6))
+	explainDialog.Show()
+}
+
+// formatExplanation renders an explanation rule by rule.
+func formatExplanation(e *scanner.Explanation) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Path: %s\n", e.Path))
+
+	switch {
+	case e.ExcludedBy != nil && e.ExcludedVia != "":
+		builder.WriteString(fmt.Sprintf("Excluded: parent %s matched %s\n", e.ExcludedVia, e.ExcludedBy))
+	case e.ExcludedBy != nil:
+		builder.WriteString(fmt.Sprintf("Excluded: %s\n", e.ExcludedBy))
+	case e.BeyondDepth:
+		builder.WriteString("Excluded: deeper than the maximum scan depth\n")
+	default:
+		builder.WriteString("Included: no rule matches\n")
+	}
+
+	builder.WriteString("\nRules in order of precedence:\n")
+	for _, verdict := range e.Verdicts {
+		mark, detail := "  ", "no match"
+		if verdict.Excluded {
+			mark, detail = "✗ ", verdict.Match.String()
+		}
+		builder.WriteString(fmt.Sprintf("%s%-16s %s\n", mark, verdict.Rule, detail))
+	}
+	return builder.String()
+}
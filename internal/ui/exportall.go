@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// exportFormat is a file format Export All offers.
+type exportFormat struct {
+	label string
+	ext   string
+}
+
+// exportFormats are the formats Export All offers, in the order listed.
+var exportFormats = []exportFormat{
+	{"Text tree (.txt)", ".txt"},
+	{"HTML page (.html)", ".html"},
+	{"Outline (.outline)", ".outline"},
+	{"OPML outline (.opml)", ".opml"},
+	{"JSON snapshot (.json)", ".json"},
+	{"CSV inventory (.csv)", ".csv"},
+	{"SQLite database (.sqlite)", ".sqlite"},
+}
+
+// Marks of the files in the Export All progress list.
+const (
+	exportPending = "•"
+	exportRunning = "…"
+	exportDone    = "✓"
+	exportFailed  = "✗"
+	exportSkipped = "–"
+)
+
+// handleExportAll writes every shown result, each folder of a combined scan or the single
+// result, to its own file in a folder the user picks, in one format.
+func (app *FileTreeApp) handleExportAll() {
+	targets := app.exportTargets()
+	if len(targets) == 0 {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+
+	labels := make([]string, len(exportFormats))
+	for i, format := range exportFormats {
+		labels[i] = format.label
+	}
+	formatSelect := widget.NewSelect(labels, nil)
+	formatSelect.SetSelectedIndex(app.lastExportFormat())
+
+	content := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Write %s files, one for each scanned folder, in:", app.formatter().Int(len(targets)))),
+		formatSelect,
+	)
+	dialog.ShowCustomConfirm("Export All", "Choose Folder…", "Cancel", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		format := exportFormats[formatSelect.SelectedIndex()]
+		dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+			if err != nil {
+				app.showError("Export Error", err)
+				return
+			}
+			if folder == nil {
+				return // User cancelled
+			}
+			app.exportAll(targets, folder.Path(), format.ext)
+		}, app.window)
+	}, app.window)
+}
+
+// lastExportFormat returns the index in exportFormats of the format last saved, or of plain text.
+func (app *FileTreeApp) lastExportFormat() int {
+	if exports := app.settings.ExportPaths; len(exports) > 0 {
+		ext := strings.ToLower(filepath.Ext(exports[len(exports)-1]))
+		for i, format := range exportFormats {
+			if format.ext == ext {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// exportTargets returns the results Export All writes: each folder of a combined result, without
+// the entries excluded from view, or the shown result. They are copies whose text is rendered
+// anew.
+func (app *FileTreeApp) exportTargets() []*scanner.ScanResult {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		return nil
+	}
+	if len(result.Roots) == 0 {
+		view := *result
+		return []*scanner.ScanResult{&view}
+	}
+
+	shown := make(map[string]*scanner.TreeNode)
+	for _, top := range result.Root.Children {
+		shown[top.Path] = top
+	}
+	var targets []*scanner.ScanResult
+	for _, part := range result.Roots {
+		top, ok := shown[part.RootPath]
+		if !ok {
+			continue // Excluded from view as a whole
+		}
+		view := *part
+		if len(app.viewExclusions) > 0 {
+			// The combined tree names the folder by its relative path; its own file names it as scanned
+			root := *top
+			root.Name, root.Parent = part.Root.Name, nil
+			view.Root = &root
+			view.NodeCount = countTree(&root)
+			scanner.Tally(&view)
+		}
+		targets = append(targets, &view)
+	}
+	return targets
+}
+
+// exportFileNames returns the names of the files for targets in dir: the base name of each
+// folder with the time and ext, numbered where they would clash with each other or a file there.
+func exportFileNames(targets []*scanner.ScanResult, dir, ext string) []string {
+	stamp := time.Now().Format(timeFormat)
+	used := make(map[string]bool)
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		base := filepath.Base(target.RootPath)
+		if base == "." || base == string(filepath.Separator) || base == "" {
+			base = "file_tree"
+		}
+		name := fmt.Sprintf("%s_%s%s", base, stamp, ext)
+		for n := 2; used[name] || exists(filepath.Join(dir, name)); n++ {
+			name = fmt.Sprintf("%s_%s-%d%s", base, stamp, n, ext)
+		}
+		used[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// exists reports whether there is a file at path.
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// exportAll writes targets to files in dir in the format of ext, one after another, listing each
+// file with its outcome. A failed file does not stop the rest; cancelling stops before the next.
+func (app *FileTreeApp) exportAll(targets []*scanner.ScanResult, dir, ext string) {
+	names := exportFileNames(targets, dir, ext)
+	rows := make([]*widget.Label, len(names))
+	list := container.NewVBox()
+	for i, name := range names {
+		rows[i] = widget.NewLabel(exportPending + " " + name)
+		list.Add(rows[i])
+	}
+	summary := widget.NewLabel(fmt.Sprintf("Exporting to %s…", dir))
+	summary.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(480, 240))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var exportDialog dialog.Dialog
+	button := widget.NewButton("Cancel", nil)
+	button.OnTapped = func() {
+		cancel()
+		button.Disable() // Enabled again as Close once the file being written is done
+	}
+	exportDialog = dialog.NewCustomWithoutButtons("Export All", container.NewBorder(nil, container.NewVBox(summary, button), nil, nil, scroll), app.window)
+	exportDialog.Show()
+
+	setRow := func(i int, mark, detail string) {
+		fyne.Do(func() {
+			text := mark + " " + names[i]
+			if detail != "" {
+				text += " — " + detail
+			}
+			rows[i].SetText(text)
+		})
+	}
+
+	var written []string
+	var failed int
+	app.runOperation(opExporting, fmt.Sprintf("Exporting %s files to %s…", app.formatter().Int(len(targets)), dir), "Could not export all results", func(activeOperation) error {
+		for i, target := range targets {
+			if ctx.Err() != nil {
+				for j := i; j < len(targets); j++ {
+					setRow(j, exportSkipped, "cancelled")
+				}
+				break
+			}
+			setRow(i, exportRunning, "")
+			path := filepath.Join(dir, names[i])
+			stats, err := app.exportTarget(target, path)
+			if err != nil {
+				failed++
+				setRow(i, exportFailed, err.Error())
+				continue
+			}
+			written = append(written, path)
+			setRow(i, exportDone, app.describeOutput(stats))
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d files failed", failed, len(targets))
+		}
+		return nil
+	}, func(error) {
+		cancel()
+		for _, path := range written {
+			app.recordExport(path)
+		}
+		message := fmt.Sprintf("Exported %s of %s files to %s", app.formatter().Int(len(written)), app.formatter().Int(len(targets)), dir)
+		if failed > 0 {
+			message += fmt.Sprintf("; %s failed", app.formatter().Int(failed))
+		}
+		if skipped := len(targets) - len(written) - failed; skipped > 0 {
+			message += fmt.Sprintf("; cancelled before %s", app.formatter().Int(skipped))
+		}
+		summary.SetText(message)
+		app.status.setMessage(message)
+		button.SetText("Close")
+		button.OnTapped = exportDialog.Hide
+		button.Enable()
+	})
+}
+
+// exportTarget renders target with the current options and writes it to a new file at path.
+func (app *FileTreeApp) exportTarget(target *scanner.ScanResult, path string) (renderer.OutputStats, error) {
+	app.renderText(target)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return renderer.OutputStats{}, fmt.Errorf("%s already exists", filepath.Base(path))
+		}
+		return renderer.OutputStats{}, err
+	}
+	return app.writeResult(target, path, file, func() {})
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/fingerprint"
+)
+
+// currentFingerprint returns the fingerprint of the current result, including sizes when they were collected.
+func (app *FileTreeApp) currentFingerprint() (fingerprint.Fingerprint, bool) {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		return fingerprint.Fingerprint{}, false
+	}
+	return fingerprint.Compute(result.Root, result.HasSizes), true
+}
+
+// handleCompareFingerprints compares the current result's fingerprint with a pasted one.
+func (app *FileTreeApp) handleCompareFingerprints() {
+	own, ok := app.currentFingerprint()
+	if !ok {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+
+	ownEntry := widget.NewEntry()
+	ownEntry.SetText(own.String())
+	ownEntry.Disable()
+	copyBtn := widget.NewButton("Copy", func() {
+		if err := app.clipboard.SetContent(own.String()); err != nil {
+			app.showError("Clipboard Error", err)
+			return
+		}
+		app.status.setMessage("Fingerprint copied to clipboard")
+	})
+
+	other := widget.NewEntry()
+	other.SetPlaceHolder("Paste a fingerprint, e.g. fts1-structure-…")
+	other.Validator = func(text string) error {
+		_, err := fingerprint.Parse(text)
+		return err
+	}
+
+	content := container.NewVBox(
+		widget.NewLabel("This tree:"),
+		container.NewBorder(nil, nil, nil, copyBtn, ownEntry),
+		widget.NewLabel("Compare with:"),
+		other,
+	)
+	compare := dialog.NewCustomConfirm("Compare Fingerprints", "Compare", "Close", content, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		theirs, err := fingerprint.Parse(other.Text)
+		if err != nil {
+			app.showError("Compare Fingerprints", err)
+			return
+		}
+		if err := own.Comparable(theirs); err != nil {
+			app.showError("Compare Fingerprints", err)
+			return
+		}
+		if own.Hash == theirs.Hash {
+			dialog.ShowInformation("Compare Fingerprints", "The trees are identical in "+own.Mode+".", app.window)
+			return
+		}
+		dialog.ShowInformation("Compare Fingerprints", "The trees differ. Use a full diff to find out where.", app.window)
+	}, app.window)
+	compare.Resize(fyne.NewSize(windowWidth*0.6, 0))
+	compare.Show()
+}
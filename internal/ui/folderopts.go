@@ -0,0 +1,285 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/folderopts"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Choices of the folder options dialog for an option left to the settings.
+const (
+	inheritChoice = "As in settings"
+	onChoice      = "On"
+	offChoice     = "Off"
+	replaceChoice = "Replace with"
+	appendChoice  = "Add to settings"
+)
+
+// createFolderBanner creates the hidden banner shown while the result was scanned with options
+// saved for its folder.
+func (app *FileTreeApp) createFolderBanner() fyne.CanvasObject {
+	app.folderLabel = widget.NewLabel("")
+	app.folderLabel.Wrapping = fyne.TextWrapWord
+
+	editBtn := widget.NewButton("Edit…", app.handleFolderOptions)
+	removeBtn := widget.NewButton("Remove", app.handleRemoveFolderOptions)
+	app.folderBanner = container.NewBorder(nil, nil, nil, container.NewHBox(editBtn, removeBtn), app.folderLabel)
+	app.folderBanner.Hide()
+	return app.folderBanner
+}
+
+// applyFolderOptions puts the settings back into the configuration and merges over them the
+// options saved for the folder about to be scanned, which it returns; nil when none apply.
+// Scans of several folders, and folders added as another root, use the settings alone.
+func (app *FileTreeApp) applyFolderOptions(paths []string, overrides scanOverrides) *folderopts.Folder {
+	app.settings.applyTo(app.config)
+	if app.folders == nil || len(paths) != 1 || overrides.addTo != nil {
+		return nil
+	}
+	folder, err := app.folders.Lookup(paths[0])
+	if err != nil {
+		log.Printf("Warning: ignoring saved folder options: %v", err)
+		return nil
+	}
+	if folder == nil {
+		return nil
+	}
+	folder.Options.Apply(app.config)
+	for _, patterns := range [][]string{app.config.IncludePatterns, app.config.ExcludePatterns} {
+		if err := scanner.ValidatePatterns(patterns); err != nil {
+			// Only a hand-edited file gets here, as the dialog saves valid patterns only
+			log.Printf("Warning: ignoring saved options for %s: %v", folder.Path, err)
+			app.settings.applyTo(app.config)
+			return nil
+		}
+	}
+	return folder
+}
+
+// showFolderOptions shows the banner for the saved options folder was scanned with, or hides it
+// for nil.
+func (app *FileTreeApp) showFolderOptions(folder *folderopts.Folder) {
+	app.folderOptions = folder
+	if app.folderBanner == nil {
+		return
+	}
+	if folder == nil {
+		app.folderBanner.Hide()
+		app.status.setBadge(badgeProfile, "")
+		return
+	}
+	app.folderLabel.SetText("Using saved options for this folder: " + folder.Options.Summary())
+	app.folderBanner.Show()
+	app.status.setBadge(badgeProfile, "📁 Folder options")
+}
+
+// folderOptionsRoot returns the folder of the shown result whose options can be saved, showing
+// why when there is none.
+func (app *FileTreeApp) folderOptionsRoot() (string, bool) {
+	result := app.baseResult
+	switch {
+	case result == nil || result.Root == nil:
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+	case len(result.Roots) > 0:
+		dialog.ShowInformation("Folder Options", "Options can only be saved for a scan of a single folder.", app.window)
+	case result.Root.IsVirtual || result.ScannedAt.IsZero():
+		dialog.ShowInformation("Folder Options", "Imported trees have no folder to save options for.", app.window)
+	case app.folders == nil:
+		dialog.ShowInformation("Folder Options", "Folder options cannot be saved: no configuration directory was found.", app.window)
+	default:
+		return result.RootPath, true
+	}
+	return "", false
+}
+
+// handleFolderOptions edits the options saved for the scanned folder, rescanning it with them
+// once saved.
+func (app *FileTreeApp) handleFolderOptions() {
+	root, ok := app.folderOptionsRoot()
+	if !ok {
+		return
+	}
+	var opts folderopts.Options
+	folder, err := app.folders.Lookup(root)
+	if err != nil {
+		app.showError("Folder Options", err)
+		return
+	}
+	if folder != nil {
+		opts = folder.Options
+	}
+
+	maxDepth := optionalIntEntry(opts.MaxDepth, -1, "enter -1 for unlimited, or 0 or more")
+	maxDepth.SetPlaceHolder(inheritChoice + " (-1 = unlimited)")
+	maxNodes := optionalIntEntry(opts.MaxNodes, 0, "enter 0 or a positive number")
+	maxNodes.SetPlaceHolder(inheritChoice + " (0 = no limit)")
+
+	flags := []struct {
+		label string
+		value **bool
+	}{
+		{"Show file sizes", &opts.ShowSize},
+		{"Collect modification dates", &opts.CollectTimes},
+		{"Count lines", &opts.CountLines},
+		{"Estimate tokens", &opts.EstimateTokens},
+		{"Find duplicate files", &opts.ComputeHashes},
+		{"Respect .gitignore", &opts.RespectGitignore},
+		{"Follow symbolic links", &opts.FollowSymlinks},
+		{"Stay on one file system", &opts.OneFileSystem},
+		{"Prune empty folders", &opts.PruneEmptyDirs},
+	}
+	selects := make([]*widget.Select, len(flags))
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Maximum depth", maxDepth),
+		widget.NewFormItem("Item limit", maxNodes),
+	}
+	for i, flag := range flags {
+		selects[i] = widget.NewSelect([]string{inheritChoice, onChoice, offChoice}, nil)
+		selects[i].SetSelected(boolChoice(*flag.value))
+		items = append(items, widget.NewFormItem(flag.label, selects[i]))
+	}
+	excludeMode, exclude := patternsEditor(opts.Exclude)
+	includeMode, include := patternsEditor(opts.Include)
+	items = append(items,
+		widget.NewFormItem("Exclude", container.NewBorder(nil, nil, excludeMode, nil, exclude)),
+		widget.NewFormItem("Include", container.NewBorder(nil, nil, includeMode, nil, include)),
+	)
+
+	form := dialog.NewForm("Options for "+root, "Save and Rescan", "Cancel", items, func(save bool) {
+		if !save {
+			return
+		}
+		opts.MaxDepth = optionalInt(maxDepth.Text)
+		opts.MaxNodes = optionalInt(maxNodes.Text)
+		for i, flag := range flags {
+			*flag.value = choiceBool(selects[i].Selected)
+		}
+		opts.Exclude = choicePatterns(excludeMode.Selected, exclude.Text)
+		opts.Include = choicePatterns(includeMode.Selected, include.Text)
+
+		if err := app.folders.Save(root, opts); err != nil {
+			app.showError("Folder Options", err)
+			return
+		}
+		if opts.Empty() {
+			app.status.setMessage("Removed the saved options for " + root)
+		} else {
+			app.status.setMessage("Saved options for " + root)
+		}
+		app.handleRefresh()
+	}, app.window)
+	form.Resize(fyne.NewSize(560, 0))
+	form.Show()
+}
+
+// handleRemoveFolderOptions forgets the options saved for the scanned folder. The shown result
+// keeps them until the next scan.
+func (app *FileTreeApp) handleRemoveFolderOptions() {
+	root, ok := app.folderOptionsRoot()
+	if !ok {
+		return
+	}
+	if err := app.folders.Remove(root); err != nil {
+		app.showError("Folder Options", err)
+		return
+	}
+	app.showFolderOptions(nil)
+	app.status.setMessage("Removed the saved options for " + root + " — refresh to scan with your settings")
+}
+
+// optionalIntEntry creates an entry for an optional number no less than least, blank when unset.
+func optionalIntEntry(value *int, least int, hint string) *widget.Entry {
+	entry := widget.NewEntry()
+	if value != nil {
+		entry.SetText(strconv.Itoa(*value))
+	}
+	entry.Validator = func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(text)); err != nil || n < least {
+			return fmt.Errorf("%s, or leave blank to use the settings", hint)
+		}
+		return nil
+	}
+	return entry
+}
+
+// optionalInt returns the number in text, or nil when it is blank.
+func optionalInt(text string) *int {
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// boolChoice returns the choice showing value.
+func boolChoice(value *bool) string {
+	switch {
+	case value == nil:
+		return inheritChoice
+	case *value:
+		return onChoice
+	default:
+		return offChoice
+	}
+}
+
+// choiceBool returns the value of choice, nil when it is left to the settings.
+func choiceBool(choice string) *bool {
+	if choice == inheritChoice {
+		return nil
+	}
+	value := choice == onChoice
+	return &value
+}
+
+// patternsEditor creates the mode choice and entry editing a saved pattern list.
+func patternsEditor(patterns *folderopts.Patterns) (*widget.Select, *widget.Entry) {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("e.g. *.log, node_modules")
+	entry.Validator = func(text string) error {
+		return scanner.ValidatePatterns(scanner.ParsePatterns(text))
+	}
+	mode := widget.NewSelect([]string{inheritChoice, replaceChoice, appendChoice}, func(choice string) {
+		if choice == inheritChoice {
+			entry.Disable()
+		} else {
+			entry.Enable()
+		}
+	})
+	switch {
+	case patterns == nil:
+		mode.SetSelected(inheritChoice)
+	case patterns.Mode == folderopts.Append:
+		mode.SetSelected(appendChoice)
+	default:
+		mode.SetSelected(replaceChoice)
+	}
+	if patterns != nil {
+		entry.SetText(strings.Join(patterns.Patterns, ", "))
+	}
+	return mode, entry
+}
+
+// choicePatterns returns the saved pattern list for a mode choice and the patterns typed.
+func choicePatterns(choice, text string) *folderopts.Patterns {
+	switch choice {
+	case replaceChoice:
+		return &folderopts.Patterns{Mode: folderopts.Replace, Patterns: scanner.ParsePatterns(text)}
+	case appendChoice:
+		return &folderopts.Patterns{Mode: folderopts.Append, Patterns: scanner.ParsePatterns(text)}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2/lang"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/volume"
+)
+
+// formatter returns the number and date formatter for on-screen text.
+func (app *FileTreeApp) formatter() *locale.Formatter {
+	if app.config.Locale == "" {
+		return locale.New(string(lang.SystemLocale()))
+	}
+	return locale.New(app.config.Locale)
+}
+
+// outputFormatter returns the formatter for saved and copied output.
+func (app *FileTreeApp) outputFormatter() *locale.Formatter {
+	if app.config.PortableOutput {
+		return locale.New(locale.Portable)
+	}
+	return app.formatter()
+}
+
+// renderOptions returns the renderer options for on-screen and saved output of result.
+func (app *FileTreeApp) renderOptions(result *scanner.ScanResult) renderer.RendererOptions {
+	opts := renderer.DefaultOptions()
+	opts.HideExportIgnored = app.settings.HideExportIgnored
+	opts.QuoteNames = app.settings.QuoteNames
+	opts.MarkUnreadable = app.settings.MarkUnreadable
+	opts.OnlyXattrs = app.settings.OnlyXattrs
+	if app.settings.KindIcons {
+		opts.KindIcons = renderer.DefaultKindIcons()
+	}
+	opts.IncludePatterns = result.IncludePatterns
+	opts.ExcludePatterns = result.ExcludePatterns
+	opts.ShowSizes = result.HasSizes
+	opts.ShowTimes = result.HasTimes
+	opts.ShowModes = result.HasModes
+	opts.ShowTokens = result.HasTokens
+	opts.SizeBasis = app.config.SizeBasis
+	opts.OutlineIndent = app.settings.OutlineIndent
+	opts.Redactor = app.redactor()
+	opts.Style = app.annotationStyle()
+	return opts
+}
+
+// redactor returns the redactor for saved and copied output, or nil when no rules are set.
+func (app *FileTreeApp) redactor() *renderer.Redactor {
+	redactor, err := renderer.NewRedactor(app.settings.Redactions, app.settings.Pseudonyms)
+	if err != nil {
+		log.Printf("Warning: redaction disabled: %v", err)
+		return nil
+	}
+	return redactor
+}
+
+// detailSuffix returns a tree row's size, line count and, for folders, token estimate suffix, or ""
+// when the result has none of them for it.
+func (app *FileTreeApp) detailSuffix(node *scanner.TreeNode) string {
+	result := app.getCurrentResult()
+	if node == nil || result == nil {
+		return ""
+	}
+	var parts []string
+	if result.HasSizes {
+		parts = append(parts, renderer.SizeLabel(node, app.config.SizeBasis, app.formatter().Size))
+	}
+	if node.Lines == 1 {
+		parts = append(parts, "1 line")
+	} else if node.Lines > 1 {
+		parts = append(parts, app.formatter().Int(node.Lines)+" lines")
+	}
+	if result.HasTokens && node.IsDir && !node.NotRead && !node.Truncated {
+		parts = append(parts, renderer.TokensLabel(node.Tokens, app.formatter()))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// modePrefix returns a tree row's permissions, like "drwxr-xr-x ", or "" when the result has none.
+func (app *FileTreeApp) modePrefix(node *scanner.TreeNode) string {
+	result := app.getCurrentResult()
+	if node == nil || result == nil || !result.HasModes {
+		return ""
+	}
+	return renderer.ModeString(node.Mode) + " "
+}
+
+// renderText renders result's output text, including the footer when enabled, into
+// TreeText and TreeLines.
+func (app *FileTreeApp) renderText(result *scanner.ScanResult) {
+	var text strings.Builder
+	out := renderer.NewCountingWriter(&text)
+	var tree renderer.TreeWriter = renderer.NewStandardTreeRenderer(app.renderOptions(result))
+	if d := app.comparisonOf(result); d != nil {
+		tree = renderer.NewDiffRenderer(app.renderOptions(result), d.Annotation)
+	}
+	tree.WriteTree(out, result.Root)
+	if app.config.OutputFooter {
+		out.WriteString(renderer.Footer(result, app.outputFormatter()))
+	}
+	result.TreeText = text.String()
+	result.TreeLines = out.Stats().Lines
+}
+
+// volumeSummary describes a file system, e.g. "Volume: 931.5 GB, 402.1 GB used, 529.4 GB free",
+// or returns "" for nil.
+func (app *FileTreeApp) volumeSummary(info *volume.Info) string {
+	if info == nil {
+		return ""
+	}
+	f := app.formatter()
+	summary := fmt.Sprintf("Volume: %s, %s used, %s free", f.Size(int64(info.Total)), f.Size(int64(info.Used)), f.Size(int64(info.Free)))
+	if info.HasInodes() {
+		summary += fmt.Sprintf("; %s of %s inodes free", f.Int(int(info.FreeInodes)), f.Int(int(info.Inodes)))
+	}
+	return summary
+}
+
+// describeOutput returns the size and line count of output for messages, e.g. "1.2 MB (14,302 lines)".
+func (app *FileTreeApp) describeOutput(stats renderer.OutputStats) string {
+	f := app.formatter()
+	if stats.Lines == 0 {
+		return f.Size(stats.Bytes)
+	}
+	return fmt.Sprintf("%s (%s lines)", f.Size(stats.Bytes), f.Int(stats.Lines))
+}
+
+// rerenderOutput re-renders the current result after an output setting changed.
+func (app *FileTreeApp) rerenderOutput() {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		return
+	}
+	app.renderText(result)
+	app.preview.SetText(result.TreeText)
+	app.showResultStatus(result)
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// hiddenPromptRatio is the share of hidden entries above which a scan offers to show them.
+const hiddenPromptRatio = 0.95
+
+// mostlyHidden reports whether hidden entries were left out of result and the folder is mostly
+// dotfiles: the scanned folder is itself hidden, or hidden entries make up more than
+// hiddenPromptRatio of everything found below it.
+func mostlyHidden(result *scanner.ScanResult) bool {
+	hidden := result.Skipped[scanner.HiddenRule]
+	if hidden == 0 {
+		return false
+	}
+	if strings.HasPrefix(filepath.Base(result.RootPath), ".") {
+		return true
+	}
+	visible := result.NodeCount - 1 // The root itself is not one of its entries
+	return float64(hidden) > hiddenPromptRatio*float64(hidden+visible)
+}
+
+// offerShowHidden asks whether to scan path again with hidden entries, leaving the setting unchanged.
+func (app *FileTreeApp) offerShowHidden(path string, overrides scanOverrides) {
+	dialog.ShowConfirm("Hidden Files", "Most entries are hidden — show hidden files for this scan?", func(show bool) {
+		if !show {
+			return
+		}
+		overrides.showHidden = true
+		app.scanDirectoryAsync(path, overrides)
+	}, app.window)
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+func TestMostlyHidden(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    string
+		hidden  int
+		visible int
+		want    bool
+	}{
+		{"nothing hidden", "/home/me/project", 0, 10, false},
+		{"nothing hidden in a hidden folder", "/home/me/.config", 0, 10, false},
+		{"hidden folder", "/home/me/.config", 1, 50, true},
+		{"only hidden entries", "/home/me/dotfiles", 3, 0, true},
+		{"at the threshold", "/home/me/dotfiles", 19, 1, false},
+		{"just above the threshold", "/home/me/dotfiles", 20, 1, true},
+		{"well below the threshold", "/home/me/project", 5, 5, false},
+		{"many visible entries", "/home/me/project", 94, 6, false},
+		{"few visible entries", "/home/me/project", 960, 40, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &scanner.ScanResult{
+				RootPath:  tt.root,
+				NodeCount: tt.visible + 1,
+				Skipped:   scanner.SkipStats{scanner.HiddenRule: tt.hidden, scanner.GitignoreRule: 1000},
+			}
+			if got := mostlyHidden(result); got != tt.want {
+				t.Errorf("%d hidden and %d visible below %s: got %v, want %v", tt.hidden, tt.visible, tt.root, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeDotfiles creates a folder named name holding hidden files and the given visible ones.
+func writeDotfiles(t *testing.T, name string, visible ...string) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), name)
+	for _, file := range append([]string{".bashrc", ".vimrc", ".gitconfig", ".config/git/ignore"}, visible...) {
+		path := filepath.Join(root, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// waitScanned waits until the window is idle showing a result for root.
+func waitScanned(t *testing.T, app *FileTreeApp, root string) *scanner.ScanResult {
+	t.Helper()
+	waitFor(t, "the scan of "+root, func() bool {
+		result := app.getCurrentResult()
+		return result != nil && result.RootPath == root && app.operation == opIdle
+	})
+	return app.getCurrentResult()
+}
+
+func TestShowHiddenPrompt(t *testing.T) {
+	app := newTestApp(t)
+	root := writeDotfiles(t, "dotfiles")
+	app.startScan(root, scanOverrides{})
+	if result := waitScanned(t, app, root); len(result.Root.Children) != 0 {
+		t.Fatalf("%d entries shown with hidden files off", len(result.Root.Children))
+	}
+
+	waitFor(t, "the prompt", func() bool { return overlayButton(app, "Yes") != nil })
+	test.Tap(overlayButton(app, "Yes"))
+
+	waitFor(t, "the rescan with hidden entries", func() bool {
+		result := app.getCurrentResult()
+		return result != nil && len(result.Root.Children) == 4 && app.operation == opIdle
+	})
+	if app.config.ShowHidden {
+		t.Error("showing hidden files for one scan changed the setting")
+	}
+	if !app.watchOverrides.showHidden {
+		t.Error("the rescan does not carry the show-hidden override")
+	}
+}
+
+func TestShowHiddenPromptDeclined(t *testing.T) {
+	app := newTestApp(t)
+	root := writeDotfiles(t, ".dotfiles", "README.md")
+	app.startScan(root, scanOverrides{})
+	waitScanned(t, app, root)
+
+	waitFor(t, "the prompt", func() bool { return overlayButton(app, "No") != nil })
+	test.Tap(overlayButton(app, "No"))
+	if result := app.getCurrentResult(); len(result.Root.Children) != 1 {
+		t.Errorf("%d entries shown after declining, want only README.md", len(result.Root.Children))
+	}
+}
+
+func TestNoShowHiddenPrompt(t *testing.T) {
+	app := newTestApp(t)
+	root := writeDotfiles(t, "project", "README.md", "main.go", "go.mod", "src/a.go", "src/b.go")
+	app.startScan(root, scanOverrides{})
+	waitScanned(t, app, root)
+	if overlayButton(app, "Yes") != nil {
+		t.Error("prompted to show hidden files for a mostly visible folder")
+	}
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
+	"github.com/Akaiko1/file-tree-scanner/internal/importer"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// handlePasteListing shows a dialog for pasting a path listing and imports it.
+func (app *FileTreeApp) handlePasteListing() {
+	entry := widget.NewMultiLineEntry()
+	entry.SetPlaceHolder("Paste one path per line, e.g. the output of `find .`")
+	entry.SetMinRowsVisible(12)
+
+	listingDialog := dialog.NewCustomConfirm("Paste Path Listing", "Import", "Cancel", entry, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		app.importListing(strings.NewReader(entry.Text), "pasted listing")
+	}, app.window)
+	listingDialog.Resize(fyne.NewSize(windowWidth*0.8, windowHeight*0.7))
+	listingDialog.Show()
+}
+
+// handleOpenListing imports a path listing from a text file.
+func (app *FileTreeApp) handleOpenListing() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			app.showError("Import Error", err)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		defer reader.Close()
+
+		app.importListing(reader, reader.URI().Name())
+	}, app.window)
+}
+
+// handleOpenSnapshot opens a snapshot or other JSON tree file saved here or by the CLI's
+// --format json, typically on another machine. The tree is virtual, so actions that read the
+// disk are unavailable.
+func (app *FileTreeApp) handleOpenSnapshot() {
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			app.showError("Open Error", err)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		defer reader.Close()
+
+		doc, rerr := report.ReadTree(reader)
+		if rerr != nil {
+			app.showError("Open Error", rerr)
+			return
+		}
+		result, rerr := doc.Result()
+		if rerr != nil {
+			app.showError("Open Error", rerr)
+			return
+		}
+		app.showSnapshot(result)
+	}, app.window)
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	openDialog.Show()
+}
+
+// handleOpenArchive opens a zip or tar archive chosen in a file dialog as if it were a folder.
+func (app *FileTreeApp) handleOpenArchive() {
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			app.showError("Open Error", err)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		reader.Close() // The archive is read from its path
+		app.startScan(reader.URI().Path(), scanOverrides{})
+	}, app.window)
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".zip", ".tar", ".gz", ".tgz"}))
+	openDialog.Show()
+}
+
+// openArchive reads the entries of the archive at path in the background and shows them like a
+// scanned folder, joining the result given by overrides.addTo if set.
+func (app *FileTreeApp) openArchive(path string, overrides scanOverrides) {
+	// Opening an archive supersedes any scan still running
+	app.cancelRunningScan(scanner.ReasonSuperseded)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	app.cancelFunc = cancel
+
+	var result *scanner.ScanResult
+	app.runOperation(opScanning, "Opening archive: "+path, "Could not open archive "+path, func(activeOperation) error {
+		defer cancel(nil)
+		var err error
+		result, err = importer.FromArchive(ctx, path)
+		if err != nil {
+			result = nil
+			if ctx.Err() != nil {
+				return nil // Superseded; the newer scan or import reports for itself
+			}
+			return err
+		}
+		if overrides.addTo != nil {
+			result = scanner.Combine(overrides.addTo, result)
+		}
+		annotate.Prepare(result.Root)
+		app.renderText(result)
+		return nil
+	}, func(err error) {
+		if err != nil {
+			app.showError("Archive Error", err)
+			return
+		}
+		if result == nil {
+			return
+		}
+		app.showResult(result)
+		app.setSourceMissing(false)
+		app.status.setMessage("Opened archive " + path)
+	})
+}
+
+// importListing builds a virtual tree from r and displays it like a scan result.
+func (app *FileTreeApp) importListing(r io.Reader, source string) {
+	result, err := importer.FromListing(r)
+	if err != nil {
+		app.showError("Import Error", err)
+		return
+	}
+	app.showImportedResult(result, source)
+}
+
+// showImportedResult renders an imported result and replaces the current one.
+func (app *FileTreeApp) showImportedResult(result *scanner.ScanResult, source string) {
+	// An import supersedes any scan still running
+	app.cancelRunningScan(scanner.ReasonSuperseded)
+
+	annotate.Prepare(result.Root)
+	app.renderText(result)
+	app.showResult(result)
+	app.setSourceMissing(false)
+	app.status.setMessage("Imported " + source)
+}
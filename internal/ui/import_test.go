@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+)
+
+func TestOpenTreeFile(t *testing.T) {
+	app := newTestApp(t)
+	root := showScannedFolder(t, app)
+	scanned := app.currentResult
+	app.renderText(scanned)
+	var saved bytes.Buffer
+	if err := report.WriteTree(&saved, report.NewTreeDocument(scanned)); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := report.ReadTree(bytes.NewReader(saved.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := doc.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.showImportedResult(result, "tree.json")
+
+	if app.currentResult != result || app.getCurrentRootPath() != root {
+		t.Fatalf("showing %q, want the imported %q", app.getCurrentRootPath(), root)
+	}
+	if got, want := app.currentResult.TreeText, scanned.TreeText; got != want {
+		t.Errorf("imported tree reads:\n%s\nwant:\n%s", got, want)
+	}
+	if len(app.treeData[root]) != len(scanned.Root.Children) {
+		t.Errorf("tree widget lists %v below the root", app.treeData[root])
+	}
+	if !app.refreshBtn.Disabled() || !app.refreshItem.Disabled || !app.packItem.Disabled {
+		t.Error("refresh or context pack left enabled for an imported tree")
+	}
+
+	// A scan brings the disk actions back
+	showScannedFolder(t, app)
+	app.checkSource()
+	if app.refreshBtn.Disabled() || app.refreshItem.Disabled || app.packItem.Disabled {
+		t.Error("refresh or context pack left disabled after a scan")
+	}
+}
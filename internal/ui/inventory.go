@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/exporter"
+)
+
+// defaultInventoryName is the suggested file name for single-file inventories.
+const defaultInventoryName = "inventory.csv"
+
+// handleExportInventory saves a flat CSV inventory of the current result. When a row
+// limit is set in Settings, the user picks a folder that receives numbered parts and an index.
+func (app *FileTreeApp) handleExportInventory() {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+
+	export := func(path string) {
+		csvExporter := &exporter.CSVExporter{RowsPerFile: app.settings.InventoryRows}
+		app.runOperation(opExporting, "Exporting inventory to "+path+"…", "Could not export inventory", func(activeOperation) error {
+			return csvExporter.Export(context.Background(), result, path)
+		}, func(err error) {
+			// Parts written before a failure are still the app's own files
+			for _, written := range csvExporter.Written {
+				app.recordExport(written)
+			}
+			if err != nil {
+				app.showError("Export Error", err)
+				return
+			}
+			app.status.setMessage(fmt.Sprintf("Exported inventory to %s (%s files)", path, app.formatter().Int(len(csvExporter.Written))))
+		})
+	}
+
+	if app.settings.InventoryRows > 0 {
+		dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+			if err != nil {
+				app.showError("Export Error", err)
+				return
+			}
+			if folder == nil {
+				return // User cancelled
+			}
+			export(folder.Path())
+		}, app.window)
+		return
+	}
+
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			app.showError("Export Error", err)
+			return
+		}
+		if writer == nil {
+			return // User cancelled
+		}
+		writer.Close()
+		export(writer.URI().Path())
+	}, app.window)
+	saveDialog.SetFileName(defaultInventoryName)
+	saveDialog.Show()
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/layout"
+)
+
+// maxOpenedViolations is the number of violations whose branches are opened in the tree; the
+// rest are only listed and marked.
+const maxOpenedViolations = 100
+
+// handleCheckLayout checks the current result against an expected layout chosen in a file
+// dialog, lists the violations and marks them in the tree until the next scan.
+func (app *FileTreeApp) handleCheckLayout() {
+	result := app.baseResult
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+	if result.Partial {
+		dialog.ShowInformation("Check Layout", "The scan did not finish, so entries would be reported missing. Refresh to scan again.", app.window)
+		return
+	}
+
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			app.showError("Open Error", err)
+			return
+		}
+		if reader == nil {
+			return // User cancelled
+		}
+		defer reader.Close()
+
+		l, lerr := layout.Parse(reader)
+		if lerr != nil {
+			app.showError("Check Layout", fmt.Errorf("%s: %w", reader.URI().Name(), lerr))
+			return
+		}
+		if app.baseResult != result {
+			return // A new scan took over while the dialog was open
+		}
+		app.showLayoutViolations(layout.Check(result.Root, l), reader.URI().Name())
+	}, app.window)
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".txt", ".json"}))
+	openDialog.Show()
+}
+
+// showLayoutViolations marks violations in the tree, opening the branches that lead to them, and
+// lists them in a dialog.
+func (app *FileTreeApp) showLayoutViolations(violations []layout.Violation, name string) {
+	app.layoutMarks = make(map[string]string)
+	app.layoutResult = app.baseResult
+	for i, violation := range violations {
+		node := violation.Node
+		if mark := app.layoutMarks[node.Path]; mark != "" {
+			app.layoutMarks[node.Path] = mark + "; " + violationLabel(violation)
+		} else {
+			app.layoutMarks[node.Path] = violationLabel(violation)
+		}
+		if app.tree == nil || i >= maxOpenedViolations {
+			continue
+		}
+		if violation.Kind == layout.KindMissing {
+			app.tree.OpenBranch(node.Path)
+		}
+		for parent := node.Parent; parent != nil; parent = parent.Parent {
+			app.tree.OpenBranch(parent.Path)
+		}
+	}
+	app.reindexRows()
+
+	if len(violations) == 0 {
+		app.status.setMessage("The tree matches " + name)
+		dialog.ShowInformation("Check Layout", "The tree matches "+name+".", app.window)
+		return
+	}
+	app.status.setMessage(fmt.Sprintf("%s violations of %s marked in the tree", app.formatter().Int(len(violations)), name))
+
+	lines := make([]string, len(violations))
+	for i, violation := range violations {
+		lines[i] = violation.String()
+	}
+	output := widget.NewLabel(strings.Join(lines, "\n"))
+	output.TextStyle.Monospace = true
+	output.Wrapping = fyne.TextWrapOff
+
+	layoutDialog := dialog.NewCustom("Check Layout", "Close", container.NewScroll(output), app.window)
+	layoutDialog.Resize(fyne.NewSize(windowWidth*0.8, windowHeight*0.6))
+	layoutDialog.Show()
+}
+
+// violationLabel describes a violation on the row it marks: the offending entry itself, or the
+// directory a missing entry belongs in.
+func violationLabel(violation layout.Violation) string {
+	switch violation.Kind {
+	case layout.KindMissing:
+		name := path.Base(strings.TrimSuffix(violation.Path, "/"))
+		if strings.HasSuffix(violation.Path, "/") {
+			name += "/"
+		}
+		return "missing " + name
+	case layout.KindWrongType:
+		return violation.Detail
+	default:
+		return string(violation.Kind)
+	}
+}
+
+// layoutMark returns the layout violations marked on the node at path, if any. Marks belong to
+// the result they were checked against and disappear once another is shown.
+func (app *FileTreeApp) layoutMark(path string) string {
+	if app.layoutResult == nil || app.layoutResult != app.baseResult {
+		return ""
+	}
+	return app.layoutMarks[path]
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+)
+
+// operation is what the window is busy with. While it is not opIdle, the controls that would
+// start a refresh, save or export are disabled.
+type operation string
+
+// Operations the window can be busy with.
+const (
+	opIdle      operation = ""
+	opScanning  operation = "scanning"
+	opRendering operation = "rendering"
+	opExporting operation = "exporting"
+)
+
+// activeOperation identifies a running operation, so one that was superseded by a newer one
+// cannot end it.
+type activeOperation struct {
+	app *FileTreeApp
+	seq int
+}
+
+// beginOperation enters op, showing message in the status bar, and returns the operation to end
+// on every path once it is over; runOperation does so for work done in the background. UI
+// thread only.
+func (app *FileTreeApp) beginOperation(op operation, message string) activeOperation {
+	app.operationSeq++
+	app.operation = op
+	app.status.setMessage(message)
+	app.updateControls()
+	return activeOperation{app: app, seq: app.operationSeq}
+}
+
+// current reports whether o is still the window's operation.
+func (o activeOperation) current() bool {
+	return o.app.operationSeq == o.seq && o.app.operation != opIdle
+}
+
+// advance moves o on to op, e.g. from rendering to writing, unless it was superseded. It may be
+// called from any goroutine.
+func (o activeOperation) advance(op operation, message string) {
+	fyne.Do(func() {
+		if o.current() {
+			o.app.operation = op
+			o.app.status.setMessage(message)
+		}
+	})
+}
+
+// end returns the window to opIdle unless a newer operation took over. A non-nil err is recorded
+// as the last failure, described by failure. UI thread only.
+func (o activeOperation) end(failure string, err error) {
+	if err != nil {
+		o.app.recordFailure(failure, err)
+	}
+	if !o.current() {
+		return
+	}
+	o.app.operation = opIdle
+	o.app.updateControls()
+}
+
+// runOperation runs work in the background as op and, once the window is idle again, calls done
+// on the UI thread with its error. A panic in work ends the operation with an error as well.
+func (app *FileTreeApp) runOperation(op operation, message, failure string, work func(o activeOperation) error, done func(err error)) {
+	o := app.beginOperation(op, message)
+	go func() {
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Panic during %s: %v", op, r)
+				err = fmt.Errorf("unexpected failure: %v", r)
+			}
+			fyne.Do(func() {
+				o.end(failure, err)
+				done(err)
+			})
+		}()
+		err = work(o)
+	}()
+}
+
+// recordFailure keeps err as the last failure, described by failure, in the status bar and the
+// log, where diagnostic bundles pick it up.
+func (app *FileTreeApp) recordFailure(failure string, err error) {
+	app.lastFailure = failure + ": " + err.Error()
+	log.Printf("Warning: %s", app.lastFailure)
+	app.status.setMessage(app.lastFailure)
+}
+
+// updateControls enables the controls that start a refresh, save or export when the window is
+// idle; refreshing also needs the scanned folder, and neither it nor a context pack is possible
+// for an imported tree.
+func (app *FileTreeApp) updateControls() {
+	busy := app.operation != opIdle
+	imported := app.importedTree()
+	for _, control := range app.busyControls {
+		if busy {
+			control.Disable()
+		} else {
+			control.Enable()
+		}
+	}
+	if app.refreshBtn != nil {
+		if busy || app.sourceMissing || imported {
+			app.refreshBtn.Disable()
+		} else {
+			app.refreshBtn.Enable()
+		}
+	}
+
+	if app.window.MainMenu() == nil {
+		return
+	}
+	for _, item := range app.busyItems {
+		item.Disabled = busy
+	}
+	if app.refreshItem != nil {
+		app.refreshItem.Disabled = busy || app.sourceMissing || imported
+	}
+	if app.packItem != nil {
+		app.packItem.Disabled = busy || imported
+	}
+	app.window.MainMenu().Refresh()
+}
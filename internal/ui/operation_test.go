@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// failingWriter is a file being saved whose writes fail, as on a full disk.
+type failingWriter struct {
+	uri    fyne.URI
+	closed bool
+}
+
+func (w *failingWriter) Write([]byte) (int, error) { return 0, errors.New("no space left on device") }
+func (w *failingWriter) Close() error              { w.closed = true; return nil }
+func (w *failingWriter) URI() fyne.URI             { return w.uri }
+
+// savedResult returns a small result to save.
+func savedResult() *scanner.ScanResult {
+	root := &scanner.TreeNode{Name: "project", Path: "/work/project", IsDir: true}
+	root.Children = []*scanner.TreeNode{{Name: "main.go", Path: "/work/project/main.go", Parent: root}}
+	return &scanner.ScanResult{RootPath: root.Path, Root: root, TreeText: "project/\n└── main.go\n"}
+}
+
+// checkFailedOperation checks that the window is idle again with its controls enabled and
+// failure recorded as the last one.
+func checkFailedOperation(t *testing.T, app *FileTreeApp, failure string) {
+	t.Helper()
+	waitIdle(t, app)
+	for _, control := range app.busyControls {
+		if control.Disabled() {
+			t.Errorf("control %T is still disabled", control)
+		}
+	}
+	for _, item := range app.busyItems {
+		if item.Disabled {
+			t.Errorf("menu item %q is still disabled", item.Label)
+		}
+	}
+	if !strings.HasPrefix(app.lastFailure, failure+": ") {
+		t.Errorf("last failure %q, want it to start with %q", app.lastFailure, failure)
+	}
+	if app.status.message.Text != app.lastFailure {
+		t.Errorf("status %q does not show the failure", app.status.message.Text)
+	}
+}
+
+func TestSaveWriteFails(t *testing.T) {
+	app := newTestApp(t)
+	writer := &failingWriter{uri: storage.NewFileURI(filepath.Join(t.TempDir(), "tree.txt"))}
+	app.saveResult(savedResult(), writer)
+	checkFailedOperation(t, app, "Could not save tree.txt")
+	if !strings.Contains(app.lastFailure, "no space left") {
+		t.Errorf("last failure %q lacks the write error", app.lastFailure)
+	}
+	if !writer.closed {
+		t.Error("the file was left open")
+	}
+}
+
+func TestSaveExportFails(t *testing.T) {
+	app := newTestApp(t)
+	// The database is written by path, into a folder that does not exist
+	path := filepath.Join(t.TempDir(), "missing", "tree.db")
+	app.saveResult(savedResult(), &failingWriter{uri: storage.NewFileURI(path)})
+	checkFailedOperation(t, app, "Could not save tree.db")
+}
+
+func TestRenderPanics(t *testing.T) {
+	app := newTestApp(t)
+	var doneErr error
+	app.runOperation(opRendering, "Rendering tree.html…", "Could not save tree.html", func(o activeOperation) error {
+		if !o.current() {
+			t.Error("operation is not current while it runs")
+		}
+		panic("renderer failed")
+	}, func(err error) { doneErr = err })
+	checkFailedOperation(t, app, "Could not save tree.html")
+	if doneErr == nil || !strings.Contains(doneErr.Error(), "renderer failed") {
+		t.Errorf("done called with %v, want the panic", doneErr)
+	}
+}
+
+func TestOperationDisablesControls(t *testing.T) {
+	app := newTestApp(t)
+	release := make(chan struct{})
+	app.runOperation(opExporting, "Exporting…", "Could not export", func(activeOperation) error {
+		<-release
+		return errors.New("export failed")
+	}, func(error) {})
+	if app.operation != opExporting {
+		t.Fatalf("operation %q while exporting", app.operation)
+	}
+	for _, control := range app.busyControls {
+		if !control.Disabled() {
+			t.Errorf("control %T is enabled while exporting", control)
+		}
+	}
+	close(release)
+	checkFailedOperation(t, app, "Could not export")
+}
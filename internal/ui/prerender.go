@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/sched"
+)
+
+// prerenderDelay is how long a shown result must stay unchanged before its likely next output
+// is rendered in the background.
+const prerenderDelay = 750 * time.Millisecond
+
+// Copy actions, remembered to pick what to render ahead.
+const (
+	copyTree    = "tree" // TreeText, rendered with every result
+	copyCards   = "cards"
+	copyOutline = "outline"
+)
+
+// outlineIndents lists the outline indentations offered in the settings; the first is the default.
+var outlineIndents = []struct{ label, indent string }{
+	{"2 spaces", renderer.DefaultOutlineIndent},
+	{"4 spaces", renderer.OutlineIndent(4, false)},
+	{"Tabs", renderer.OutlineIndent(0, true)},
+}
+
+// renderJob renders one output of a result. key identifies the format, the result and every
+// option the output depends on, so a changed option never matches an earlier render.
+type renderJob struct {
+	key    string
+	render func() string
+}
+
+// prerenderCache holds output rendered ahead of a copy or save. It is filled by one background
+// worker at a time and read on the UI thread.
+type prerenderCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+	cancel  context.CancelFunc
+}
+
+// get returns the output of job, from the cache when it was rendered ahead.
+func (c *prerenderCache) get(job renderJob) string {
+	c.mu.Lock()
+	text, ok := c.entries[job.key]
+	c.mu.Unlock()
+	if ok {
+		return text
+	}
+	return job.render()
+}
+
+// stop cancels the background worker, if any, and forgets what it rendered.
+func (c *prerenderCache) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.entries = nil
+}
+
+// start replaces the cache contents with the outputs of jobs, rendered one after another for owner
+// once prerenderDelay has passed without another call to start or stop. The renders give way to
+// scans of any window and pick up after the last one finished once there is room again.
+func (c *prerenderCache) start(owner any, jobs []renderJob) {
+	c.stop()
+	if len(jobs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mu.Lock()
+	c.cancel = cancel
+	c.entries = make(map[string]string)
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Warning: pre-rendering failed: %v", r)
+			}
+		}()
+		select {
+		case <-time.After(prerenderDelay):
+		case <-ctx.Done():
+			return
+		}
+		background.Run(ctx, owner, sched.Speculative, func(slot context.Context) error {
+			for _, job := range jobs {
+				if slot.Err() != nil {
+					return slot.Err()
+				}
+				c.mu.Lock()
+				_, done := c.entries[job.key]
+				c.mu.Unlock()
+				if done {
+					continue // Rendered before it was preempted
+				}
+				text := job.render()
+				c.mu.Lock()
+				if ctx.Err() != nil {
+					c.mu.Unlock()
+					return ctx.Err()
+				}
+				c.entries[job.key] = text
+				c.mu.Unlock()
+			}
+			return nil
+		})
+	}()
+}
+
+// renderKey identifies an output by its format, the result it shows and the options it is
+// rendered with.
+func renderKey(format string, result *scanner.ScanResult, opts renderer.RendererOptions, extra ...any) string {
+	opts.Redactor = nil // Built anew for every render; the rules are in extra
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%#v %#v", opts, extra)))
+	return fmt.Sprintf("%s %p %s", format, result, hex.EncodeToString(sum[:8]))
+}
+
+// saveJob returns the job rendering result for a file with the extension of path, or false when
+// saving it writes TreeText or goes through an exporter.
+func (app *FileTreeApp) saveJob(result *scanner.ScanResult, path string) (renderJob, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".json" {
+		return renderJob{
+			key: renderKey(ext, result, renderer.RendererOptions{}),
+			render: func() string {
+				var builder strings.Builder
+				report.WriteTree(&builder, report.NewTreeDocument(result)) // Writing to a builder cannot fail
+				return builder.String()
+			},
+		}, true
+	}
+
+	opts := app.renderOptions(result)
+	fileRenderer := renderer.ForPath(path, opts, app.settings.DepthColors)
+	if fileRenderer == nil {
+		return renderJob{}, false
+	}
+	return renderJob{
+		key:    renderKey(ext, result, opts, app.settings.DepthColors, app.settings.Redactions, app.settings.Pseudonyms),
+		render: func() string { return fileRenderer.RenderTree(result.Root) },
+	}, true
+}
+
+// cardsJob returns the job rendering the directory cards of result, noting excluded top-level
+// directories.
+func (app *FileTreeApp) cardsJob(result *scanner.ScanResult, excluded []string) renderJob {
+	opts := app.renderOptions(result)
+	cards := renderer.NewCardsRenderer(opts, excluded)
+	return renderJob{
+		key:    renderKey(copyCards, result, opts, excluded, app.settings.Redactions, app.settings.Pseudonyms),
+		render: func() string { return cards.RenderTree(result.Root) },
+	}
+}
+
+// outlineJob returns the job rendering result as a plain outline.
+func (app *FileTreeApp) outlineJob(result *scanner.ScanResult) renderJob {
+	opts := app.renderOptions(result)
+	outline := renderer.NewOutlineRenderer(opts)
+	return renderJob{
+		key:    renderKey(copyOutline, result, opts, app.settings.Redactions, app.settings.Pseudonyms),
+		render: func() string { return outline.RenderTree(result.Root) },
+	}
+}
+
+// schedulePrerender renders the outputs the user is likely to ask for next in the background:
+// the shown result in the format of the last file saved, and the directory cards or outline when
+// those were copied last. Copying the tree needs nothing, as TreeText comes with every result.
+func (app *FileTreeApp) schedulePrerender() {
+	result := app.currentResult
+	if result == nil || result.Root == nil {
+		app.prerender.stop()
+		return
+	}
+	var jobs []renderJob
+	if exports := app.settings.ExportPaths; len(exports) > 0 {
+		if job, ok := app.saveJob(result, exports[len(exports)-1]); ok {
+			jobs = append(jobs, job)
+		}
+	}
+	if app.lastCopy == copyCards && app.baseResult != nil && app.baseResult.Root != nil {
+		jobs = append(jobs, app.cardsJob(app.baseResult, app.cardExclusions()))
+	}
+	if app.lastCopy == copyOutline {
+		jobs = append(jobs, app.outlineJob(result))
+	}
+	app.prerender.start(app, jobs)
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/importer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/volume"
+)
+
+// Pre-scan dialog defaults.
+const (
+	defaultPrescanMinEntries = 20
+	prescanLargeEstimate     = 10000 // Entries within two levels that always warrant the dialog
+	preflightTimeout         = 5 * time.Second
+)
+
+// startScan scans path with overrides, first offering to leave out some of its entries when
+// it looks large: more entries than the configured minimum, or a large two-level estimate.
+// Invalid include or exclude patterns are reported instead of scanning. An archive file is
+// opened as if it were a folder.
+func (app *FileTreeApp) startScan(path string, overrides scanOverrides) {
+	if importer.IsArchive(path) {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			app.openArchive(path, overrides)
+			return
+		}
+	}
+	if !app.validPatterns() {
+		return
+	}
+
+	preflighter, ok := app.scanner.(scanner.PreflightScanner)
+	if !ok || !app.settings.PrescanDialog {
+		app.scanDirectoryAsync(path, overrides)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+		defer cancel()
+		preflight, err := preflighter.Preflight(ctx, path)
+		volumeInfo, verr := volume.Stat(path)
+		if verr != nil && !errors.Is(verr, volume.ErrUnsupported) {
+			log.Printf("Warning: %v", verr)
+		}
+
+		fyne.Do(func() {
+			if err != nil {
+				log.Printf("Warning: skipping pre-scan listing: %v", err)
+				app.scanDirectoryAsync(path, overrides)
+				return
+			}
+			if len(preflight.Entries) <= app.settings.PrescanMinEntries && preflight.Estimate < prescanLargeEstimate {
+				app.scanDirectoryAsync(path, overrides)
+				return
+			}
+			app.showPrescan(path, preflight, volumeInfo, overrides)
+		})
+	}()
+}
+
+// startScans scans paths together as one combined result, or a single path through startScan.
+// Several folders are scanned without the pre-scan dialog.
+func (app *FileTreeApp) startScans(paths []string, overrides scanOverrides) {
+	if len(paths) == 1 {
+		app.startScan(paths[0], overrides)
+		return
+	}
+	if app.validPatterns() {
+		app.scanDirectoriesAsync(paths, overrides)
+	}
+}
+
+// validPatterns reports whether the include and exclude patterns are valid, showing the error
+// when they are not.
+func (app *FileTreeApp) validPatterns() bool {
+	for _, patterns := range [][]string{app.config.IncludePatterns, app.config.ExcludePatterns} {
+		if err := scanner.ValidatePatterns(patterns); err != nil {
+			app.showError("Invalid Pattern", err)
+			return false
+		}
+	}
+	return true
+}
+
+// showPrescan lists the entries of path with checkboxes; unchecked entries are left out of the scan.
+// volumeInfo describes the file system holding path, if known.
+func (app *FileTreeApp) showPrescan(path string, preflight *scanner.Preflight, volumeInfo *volume.Info, overrides scanOverrides) {
+	checks := make([]*widget.Check, len(preflight.Entries))
+	list := container.NewVBox()
+	for i, entry := range preflight.Entries {
+		label := fileIcon + " " + entry.Name
+		if entry.IsDir {
+			label = folderIcon + " " + entry.Name + "/"
+		}
+		checks[i] = widget.NewCheck(label, nil)
+		checks[i].SetChecked(true)
+		list.Add(checks[i])
+	}
+
+	setAll := func(checked bool) {
+		for _, check := range checks {
+			check.SetChecked(checked)
+		}
+	}
+	f := app.formatter()
+	text := fmt.Sprintf("%s entries, at least %s items within two levels. Uncheck entries to leave them out of this scan.",
+		f.Int(len(preflight.Entries)), f.Int(preflight.Estimate))
+	if summary := app.volumeSummary(volumeInfo); summary != "" {
+		text += "\n" + summary
+	}
+	summary := widget.NewLabel(text)
+	summary.Wrapping = fyne.TextWrapWord
+	buttons := container.NewHBox(
+		widget.NewButton("Check All", func() { setAll(true) }),
+		widget.NewButton("Uncheck All", func() { setAll(false) }),
+	)
+	scroll := container.NewVScroll(list)
+	scroll.SetMinSize(fyne.NewSize(400, 300))
+	content := container.NewBorder(container.NewVBox(summary, buttons), nil, nil, nil, scroll)
+
+	confirm := dialog.NewCustomConfirm("Choose What to Scan", "Scan", "Cancel", content, func(scan bool) {
+		if !scan {
+			return
+		}
+		overrides.excluded = nil
+		for i, check := range checks {
+			if !check.Checked {
+				overrides.excluded = append(overrides.excluded, preflight.Entries[i].Name)
+			}
+		}
+		app.scanDirectoryAsync(path, overrides)
+	}, app.window)
+	confirm.Show()
+}
@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// textPreview is a read-only, virtualized view of the rendered output with its own find bar.
+// Only visible rows are materialized, so multi-megabyte renders stay responsive.
+type textPreview struct {
+	lines      []string
+	lowerLines []string // lazily built for case-insensitive find
+
+	list       *widget.List
+	findEntry  *widget.Entry
+	matchLabel *widget.Label
+	copyBtn    *widget.Button
+
+	matches  []int
+	isMatch  map[int]bool
+	current  int
+	selStart int
+	selEnd   int
+	onCopy   func(text string)
+}
+
+// newTextPreview creates an empty preview; onCopy receives the selected text.
+func newTextPreview(onCopy func(text string)) *textPreview {
+	p := &textPreview{
+		isMatch:  make(map[int]bool),
+		current:  -1,
+		selStart: -1,
+		selEnd:   -1,
+		onCopy:   onCopy,
+	}
+
+	p.list = widget.NewList(
+		func() int { return len(p.lines) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle.Monospace = true
+			return label
+		},
+		p.updateRow,
+	)
+	p.list.OnSelected = p.handleSelected
+
+	p.findEntry = widget.NewEntry()
+	p.findEntry.SetPlaceHolder("Find in output…")
+	p.findEntry.OnChanged = p.find
+	p.findEntry.OnSubmitted = func(string) { p.next() }
+
+	p.matchLabel = widget.NewLabel("")
+	p.copyBtn = widget.NewButton("Copy selection", p.copySelection)
+	p.copyBtn.Disable()
+
+	return p
+}
+
+// content returns the preview's canvas object: find bar on top, text below.
+func (p *textPreview) content() fyne.CanvasObject {
+	prevBtn := widget.NewButton("▲", p.previous)
+	nextBtn := widget.NewButton("▼", p.next)
+	controls := container.NewHBox(p.matchLabel, prevBtn, nextBtn, p.copyBtn)
+	findBar := container.NewBorder(nil, nil, nil, controls, p.findEntry)
+	return container.NewBorder(findBar, nil, nil, nil, p.list)
+}
+
+// SetText replaces the previewed text and re-runs the active search.
+func (p *textPreview) SetText(text string) {
+	p.lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if text == "" {
+		p.lines = nil
+	}
+	p.lowerLines = nil
+	p.clearSelection()
+	p.find(p.findEntry.Text)
+	p.list.Refresh()
+}
+
+// updateRow renders a single visible line.
+func (p *textPreview) updateRow(id widget.ListItemID, obj fyne.CanvasObject) {
+	label := obj.(*widget.Label)
+	label.Importance = widget.MediumImportance
+	switch {
+	case p.inSelection(id):
+		label.Importance = widget.HighImportance
+	case p.isMatch[id]:
+		label.Importance = widget.WarningImportance
+	}
+	label.SetText(p.lines[id])
+}
+
+// find recomputes matches for query (case-insensitive) and jumps to the first one.
+func (p *textPreview) find(query string) {
+	p.matches = nil
+	p.isMatch = make(map[int]bool)
+	p.current = -1
+
+	query = strings.ToLower(query)
+	if query != "" {
+		if p.lowerLines == nil {
+			p.lowerLines = make([]string, len(p.lines))
+			for i, line := range p.lines {
+				p.lowerLines[i] = strings.ToLower(line)
+			}
+		}
+		for i, line := range p.lowerLines {
+			if strings.Contains(line, query) {
+				p.matches = append(p.matches, i)
+				p.isMatch[i] = true
+			}
+		}
+	}
+
+	p.updateMatchLabel()
+	p.list.Refresh()
+	if len(p.matches) > 0 {
+		p.jumpTo(0)
+	}
+}
+
+// next moves to the following match, wrapping around.
+func (p *textPreview) next() {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.jumpTo((p.current + 1) % len(p.matches))
+}
+
+// previous moves to the preceding match, wrapping around.
+func (p *textPreview) previous() {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.jumpTo((p.current - 1 + len(p.matches)) % len(p.matches))
+}
+
+// jumpTo selects the i-th match and scrolls it into view.
+func (p *textPreview) jumpTo(i int) {
+	p.current = i
+	p.selStart, p.selEnd = p.matches[i], p.matches[i]
+	p.copyBtn.Enable()
+	p.updateMatchLabel()
+	p.list.ScrollTo(p.matches[i])
+	p.list.Refresh()
+}
+
+// updateMatchLabel shows the current match position.
+func (p *textPreview) updateMatchLabel() {
+	switch {
+	case p.findEntry.Text == "":
+		p.matchLabel.SetText("")
+	case len(p.matches) == 0:
+		p.matchLabel.SetText("No matches")
+	default:
+		p.matchLabel.SetText(fmt.Sprintf("%d of %d", p.current+1, len(p.matches)))
+	}
+}
+
+// handleSelected highlights the selected line together with the block nested below it.
+func (p *textPreview) handleSelected(id widget.ListItemID) {
+	p.selStart = id
+	p.selEnd = blockEnd(p.lines, id)
+	p.copyBtn.Enable()
+
+	// The highlighted range replaces the list's own single-row selection
+	p.list.Unselect(id)
+	p.list.Refresh()
+}
+
+// clearSelection removes the highlighted range.
+func (p *textPreview) clearSelection() {
+	p.selStart, p.selEnd = -1, -1
+	p.copyBtn.Disable()
+}
+
+// inSelection reports whether line id is part of the highlighted range.
+func (p *textPreview) inSelection(id int) bool {
+	return p.selStart >= 0 && id >= p.selStart && id <= p.selEnd
+}
+
+// copySelection passes the highlighted lines to the copy callback.
+func (p *textPreview) copySelection() {
+	if p.selStart < 0 || p.onCopy == nil {
+		return
+	}
+	p.onCopy(strings.Join(p.lines[p.selStart:p.selEnd+1], "\n") + "\n")
+}
+
+// blockEnd returns the last line of the block that starts at line start, i.e. the
+// run of following lines whose tree content is indented deeper than start's.
+func blockEnd(lines []string, start int) int {
+	indent := treeIndent(lines[start])
+	end := start
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || treeIndent(lines[i]) <= indent {
+			break
+		}
+		end = i
+	}
+	return end
+}
+
+// treeIndent returns the width of the tree-drawing prefix of a rendered line.
+func treeIndent(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ', '│', '├', '└', '─':
+			width++
+		default:
+			return width
+		}
+	}
+	return width
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// progressPathWidth is the number of characters of the current directory shown while scanning.
+const progressPathWidth = 48
+
+// progressLabel shows live progress of a scan. Reports arrive on the scanning goroutine; only the
+// latest is kept and at most one update waits for the main thread, so a busy UI never holds up
+// the scan.
+type progressLabel struct {
+	label   *widget.Label
+	format  *locale.Formatter
+	latest  atomic.Pointer[scanner.ScanProgress]
+	pending atomic.Bool
+}
+
+// newProgressLabel creates a label announcing a scan of path until the first report arrives.
+func newProgressLabel(path string, format *locale.Formatter) *progressLabel {
+	return &progressLabel{label: widget.NewLabel("Scanning " + shortenPath(path)), format: format}
+}
+
+// report is the scanner.ProgressFunc updating the label.
+func (p *progressLabel) report(progress scanner.ScanProgress) {
+	if progress.Done {
+		return // The dialog closes with the scan
+	}
+	p.latest.Store(&progress)
+	if !p.pending.CompareAndSwap(false, true) {
+		return // The queued update will show this report
+	}
+	fyne.Do(func() {
+		p.pending.Store(false)
+		latest := p.latest.Load()
+		p.label.SetText(fmt.Sprintf("Scanning %s — %s items", shortenPath(latest.Current), p.format.Int(latest.Items)))
+	})
+}
+
+// shortenPath keeps the end of path, which names the directory being read.
+func shortenPath(path string) string {
+	runes := []rune(path)
+	if len(runes) <= progressPathWidth {
+		return path
+	}
+	return "…" + string(runes[len(runes)-progressPathWidth:])
+}
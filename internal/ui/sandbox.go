@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"context"
+	"log"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
+	"github.com/Akaiko1/file-tree-scanner/internal/sandbox"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// scanFolderURI scans a folder the picker handed out as a URI with no path, as document portals
+// and other storage repositories do, through Fyne's storage. The result is virtual, like an
+// import: it cannot be refreshed or watched.
+func (app *FileTreeApp) scanFolderURI(folder fyne.ListableURI) {
+	if !app.validPatterns() {
+		return
+	}
+	app.cancelRunningScan(scanner.ReasonSuperseded)
+	app.settings.applyTo(app.config)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	app.cancelFunc = cancel
+
+	name := folder.Name()
+	var result *scanner.ScanResult
+	app.runOperation(opScanning, "Scanning: "+name, "Scan failed", func(activeOperation) error {
+		defer cancel(nil)
+		var err error
+		result, err = sandbox.ScanURI(ctx, app.config, folder)
+		if err != nil {
+			result = nil
+			if ctx.Err() != nil {
+				return nil // Superseded; the newer scan or import reports for itself
+			}
+			return app.sandbox.Explain(err)
+		}
+		annotate.Prepare(result.Root)
+		app.renderText(result)
+		return nil
+	}, func(err error) {
+		if err != nil {
+			app.showError("Scan Error", err)
+			return
+		}
+		if result == nil {
+			return
+		}
+		app.showResult(result)
+		app.setSourceMissing(false)
+		app.status.setMessage("Scanned " + folder.String())
+	})
+}
+
+// rememberFolder bookmarks the folder at path, just chosen by the user, so the macOS App Sandbox
+// lets later launches reopen it.
+func (app *FileTreeApp) rememberFolder(path string) {
+	if app.bookmarks == nil {
+		return
+	}
+	if err := app.bookmarks.Remember(path); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// accessFolders starts access to the bookmarked folders among paths, keeping it until the next
+// scan replaces them or the window closes. Scans adding another root keep access to the others.
+func (app *FileTreeApp) accessFolders(paths []string, overrides scanOverrides) {
+	if app.bookmarks == nil {
+		return
+	}
+	if overrides.addTo == nil {
+		app.releaseFolders()
+	}
+	for _, path := range paths {
+		release, err := app.bookmarks.Access(path)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		app.folderAccess = append(app.folderAccess, release)
+	}
+}
+
+// releaseFolders ends the access started by accessFolders.
+func (app *FileTreeApp) releaseFolders() {
+	for _, release := range app.folderAccess {
+		release()
+	}
+	app.folderAccess = nil
+}
+
+// sandboxHint returns the access hint when result, scanned inside a sandbox, read as empty: the
+// sandbox may have withheld the folder. It is "" otherwise.
+func (app *FileTreeApp) sandboxHint(result *scanner.ScanResult) string {
+	if app.sandbox == sandbox.None || result.Root == nil || len(result.Root.Children) > 0 {
+		return ""
+	}
+	return app.sandbox.AccessHint()
+}
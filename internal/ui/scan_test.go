@@ -0,0 +1,313 @@
+package ui
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// scanWait bounds how long a scripted scan flow may take to settle.
+const scanWait = 10 * time.Second
+
+// logBuffer collects log output written from any goroutine.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newScriptedApp returns a test application whose scans follow script, set up through the
+// developer-mode variable like a real run, and the log it writes.
+func newScriptedApp(t *testing.T, script string) (*FileTreeApp, *logBuffer) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scan.script")
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(scanner.ScriptEnv, path)
+
+	logs := &logBuffer{}
+	log.SetOutput(logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	app := newTestApp(t)
+	if _, ok := app.scanner.(*scanner.ScriptedScanner); !ok {
+		t.Fatalf("scanner is %T, want the scripted scanner", app.scanner)
+	}
+	t.Cleanup(func() { app.cancelRunningScan(scanner.ReasonUser) })
+	return app, logs
+}
+
+// scanFolder creates a folder holding a file, for scripted scans to read.
+func scanFolder(t *testing.T, name string) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), name)
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// waitFor polls until done reports true, failing the test with what after scanWait.
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(scanWait)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitIdle waits until the window has finished its operation.
+func waitIdle(t *testing.T, app *FileTreeApp) {
+	t.Helper()
+	waitFor(t, "the window to be idle", func() bool { return app.operation == opIdle })
+}
+
+// findButton returns the first button labelled text below obj, or nil.
+func findButton(obj fyne.CanvasObject, text string) *widget.Button {
+	if button, ok := obj.(*widget.Button); ok {
+		if button.Text == text {
+			return button
+		}
+		return nil
+	}
+	var children []fyne.CanvasObject
+	switch obj := obj.(type) {
+	case *fyne.Container:
+		children = obj.Objects
+	case fyne.Widget:
+		children = test.WidgetRenderer(obj).Objects()
+	}
+	for _, child := range children {
+		if button := findButton(child, text); button != nil {
+			return button
+		}
+	}
+	return nil
+}
+
+// overlayButton returns the first button labelled text in a shown dialog, or nil.
+func overlayButton(app *FileTreeApp, text string) *widget.Button {
+	for _, overlay := range app.window.Canvas().Overlays().List() {
+		if overlay.Visible() {
+			if button := findButton(overlay, text); button != nil {
+				return button
+			}
+		}
+	}
+	return nil
+}
+
+// progressCancel returns the Cancel button of the shown progress dialog, or nil.
+func progressCancel(app *FileTreeApp) *widget.Button {
+	return overlayButton(app, "Cancel")
+}
+
+// scriptReached reports whether the log shows the scan script reached text, like "1: hang".
+func scriptReached(logs *logBuffer, text string) func() bool {
+	return func() bool { return strings.Contains(logs.String(), "Scan script line "+text) }
+}
+
+func TestScanSucceeds(t *testing.T) {
+	app, _ := newScriptedApp(t, "delay 10ms\nscan\n")
+	root := scanFolder(t, "project")
+	app.startScan(root, scanOverrides{})
+	waitFor(t, "the scan to start", func() bool { return app.operation == opScanning || app.getCurrentResult() != nil })
+	waitIdle(t, app)
+
+	result := app.getCurrentResult()
+	if result == nil || result.RootPath != root {
+		t.Fatalf("shown result %v, want the scan of %s", result, root)
+	}
+	if got := app.status.message.Text; got != "Scanned "+root {
+		t.Errorf("status %q, want Scanned %s", got, root)
+	}
+	if progressCancel(app) != nil {
+		t.Error("the progress dialog is still shown")
+	}
+	if overlayButton(app, "OK") == nil {
+		t.Error("the success dialog went away with the progress dialog")
+	}
+}
+
+func TestScanCancelFromProgressDialog(t *testing.T) {
+	app, logs := newScriptedApp(t, "hang\n")
+	root := scanFolder(t, "project")
+	app.startScan(root, scanOverrides{})
+	waitFor(t, "the scan to hang", scriptReached(logs, "1: hang"))
+
+	cancel := progressCancel(app)
+	if cancel == nil {
+		t.Fatal("no progress dialog with a Cancel button while scanning")
+	}
+	test.Tap(cancel)
+	waitIdle(t, app)
+
+	if got, want := app.status.message.Text, "Scan cancelled: "+root; got != want {
+		t.Errorf("status %q, want %q", got, want)
+	}
+	if app.getCurrentResult() != nil {
+		t.Error("a cancelled scan showed a result")
+	}
+	if app.lastFailure != "" {
+		t.Errorf("cancelling recorded the failure %q", app.lastFailure)
+	}
+	if progressCancel(app) != nil {
+		t.Error("the progress dialog is still shown")
+	}
+}
+
+func TestScanOverlap(t *testing.T) {
+	app, logs := newScriptedApp(t, "hang\n---\nscan\n")
+	first, second := scanFolder(t, "first"), scanFolder(t, "second")
+
+	app.startScan(first, scanOverrides{})
+	waitFor(t, "the first scan to hang", scriptReached(logs, "1: hang"))
+	app.startScan(second, scanOverrides{})
+	waitFor(t, "the second scan to finish", func() bool {
+		result := app.getCurrentResult()
+		return result != nil && result.RootPath == second && app.operation == opIdle
+	})
+	waitFor(t, "the first scan to stop", func() bool { return strings.Contains(logs.String(), "Scan replaced by a newer one: "+first) })
+
+	// The superseded scan reports nothing of its own over the newer one
+	if got := app.status.message.Text; got != "Scanned "+second {
+		t.Errorf("status %q, want Scanned %s", got, second)
+	}
+	if app.lastFailure != "" {
+		t.Errorf("the superseded scan recorded the failure %q", app.lastFailure)
+	}
+	if progressCancel(app) != nil {
+		t.Error("a progress dialog is still shown")
+	}
+}
+
+func TestScanTimeout(t *testing.T) {
+	app, _ := newScriptedApp(t, "hang\n")
+	app.settings.TimeLimit = 1 // Seconds, applied to each scan like the Settings dialog does
+	root := scanFolder(t, "project")
+	app.startScan(root, scanOverrides{})
+	waitFor(t, "the scan to time out", func() bool { return strings.HasPrefix(app.status.message.Text, "Scan timed out") })
+	waitIdle(t, app)
+
+	if got, want := app.status.message.Text, "Scan timed out after 1s: "+root; got != want {
+		t.Errorf("status %q, want %q", got, want)
+	}
+	if app.getCurrentResult() != nil {
+		t.Error("a scan that timed out before reading anything showed a result")
+	}
+	if progressCancel(app) != nil {
+		t.Error("the progress dialog is still shown")
+	}
+}
+
+func TestScanError(t *testing.T) {
+	app, _ := newScriptedApp(t, "error disk on fire\n---\nscan\n")
+	root := scanFolder(t, "project")
+	app.startScan(root, scanOverrides{})
+	waitFor(t, "the scan to fail", func() bool { return app.lastFailure != "" })
+	waitIdle(t, app)
+
+	if got, want := app.lastFailure, "Scan failed: disk on fire"; got != want {
+		t.Errorf("last failure %q, want %q", got, want)
+	}
+	if progressCancel(app) != nil {
+		t.Error("the progress dialog is still shown")
+	}
+	if overlayButton(app, "OK") == nil {
+		t.Error("the error dialog went away with the progress dialog")
+	}
+
+	// The next scan runs normally
+	app.startScan(root, scanOverrides{})
+	waitFor(t, "the rescan", func() bool { return app.getCurrentResult() != nil && app.operation == opIdle })
+}
+
+func TestScanPanicRecovery(t *testing.T) {
+	// More panics than the scheduler has slots: each must give its slot back
+	script := strings.Repeat("panic scanner exploded\n---\n", backgroundBudget+1) + "scan\n"
+	app, _ := newScriptedApp(t, script)
+	root := scanFolder(t, "project")
+
+	for i := 0; i <= backgroundBudget; i++ {
+		app.lastFailure = ""
+		app.startScan(root, scanOverrides{})
+		waitFor(t, "the scan to fail", func() bool { return app.lastFailure != "" })
+		waitIdle(t, app)
+		if got, want := app.lastFailure, "Scan failed: unexpected failure: scanner exploded"; got != want {
+			t.Fatalf("scan %d: last failure %q, want %q", i+1, got, want)
+		}
+		if progressCancel(app) != nil {
+			t.Fatalf("scan %d: the progress dialog is still shown", i+1)
+		}
+	}
+
+	app.startScan(root, scanOverrides{})
+	waitFor(t, "a scan after the panics", func() bool {
+		result := app.getCurrentResult()
+		return result != nil && result.RootPath == root && app.operation == opIdle
+	})
+}
+
+func TestScanPartial(t *testing.T) {
+	app, _ := newScriptedApp(t, "partial 1\n")
+	root := scanFolder(t, "project")
+	app.startScan(root, scanOverrides{})
+	waitFor(t, "the partial result", func() bool { return app.getCurrentResult() != nil && app.operation == opIdle })
+
+	result := app.getCurrentResult()
+	if !result.Truncated || len(result.Root.Children) != 0 {
+		t.Errorf("truncated %v with %d entries, want only the root of a truncated result", result.Truncated, len(result.Root.Children))
+	}
+	if got := app.status.message.Text; !strings.HasPrefix(got, "Stopped after") || !strings.HasSuffix(got, root) {
+		t.Errorf("status %q, want the node limit message for %s", got, root)
+	}
+}
+
+func TestPartialMessage(t *testing.T) {
+	app := newTestApp(t)
+	tests := []struct {
+		reason scanner.CancelReason
+		limit  string
+		want   string
+	}{
+		{scanner.ReasonUser, "", "Partial results (cancelled): 1,234 items"},
+		{scanner.ReasonTimeout, "", "Partial results (timed out): 1,234 items"},
+		{scanner.ReasonTimeout, "30s", "Partial results (timed out after 30s): 1,234 items"},
+		{scanner.ReasonSuperseded, "", "Partial results (superseded): 1,234 items"},
+	}
+	for _, tt := range tests {
+		result := &scanner.ScanResult{NodeCount: 1234, Partial: true, TruncatedReason: tt.reason, TruncatedLimit: tt.limit}
+		if got := app.partialMessage(result); got != tt.want {
+			t.Errorf("%q with limit %q: got %q, want %q", tt.reason, tt.limit, got, tt.want)
+		}
+	}
+}
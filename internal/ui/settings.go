@@ -0,0 +1,1096 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/contextpack"
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/shellcmd"
+)
+
+// Preference keys for persisted UI settings.
+const (
+	prefTreeGuides  = "tree.guideLines"
+	prefTreeShading = "tree.rowShading"
+	prefTreeDensity = "tree.density"
+	prefMonospace   = "tree.monospace"
+	prefShowSize    = "scan.showSize"
+	prefSizeBasis   = "scan.sizeBasis"
+	prefTimes       = "scan.collectTimes"
+	prefModes       = "scan.collectMode"
+	prefHashes      = "scan.computeHashes"
+	prefLines       = "scan.countLines"
+	prefTokens      = "scan.estimateTokens"
+	prefDupDirs     = "scan.duplicateDirMinItems"
+	prefXattrs      = "scan.collectXattrs"
+	prefNatural     = "scan.naturalSort"
+	prefSortBy      = "tree.sortBy"
+	prefSortDesc    = "tree.sortDescending"
+	prefOnlyXattrs  = "output.onlyXattrs"
+	prefLocale      = "i18n.locale"
+	prefPortable    = "output.portable"
+	prefFooter      = "output.footer"
+	prefShell       = "commands.shell"
+	prefDepthColors = "output.depthColors"
+	prefExportPaths = "exports.paths"
+	prefShowExports = "exports.show"
+	prefInventory   = "exports.rowsPerFile"
+	prefOutline     = "output.outlineIndent"
+	prefHonorIgnore = "exports.honorExportIgnore"
+	prefHideIgnored = "output.hideExportIgnored"
+	prefPrescan     = "scan.prescanDialog"
+	prefPrescanMin  = "scan.prescanMinEntries"
+	prefGitignore   = "scan.respectGitignore"
+	prefFollowLinks = "scan.followSymlinks"
+	prefTreeIgnore  = "scan.treeIgnore"
+	prefOneFS       = "scan.oneFileSystem"
+	prefInclude     = "scan.includePatterns"
+	prefExclude     = "scan.excludePatterns"
+	prefQuoteNames  = "output.quoteNames"
+	prefBackground  = "scan.backgroundPriority"
+	prefUnreadable  = "output.markUnreadable"
+	prefKindIcons   = "output.kindIcons"
+	prefMaxDepth    = "scan.maxDepth"
+	prefHardDepth   = "scan.hardDepthLimit"
+	prefMaxNodes    = "scan.maxNodes"
+	prefTimeLimit   = "scan.timeLimit"  // Seconds
+	prefDirTimeout  = "scan.dirTimeout" // Seconds
+	prefPruneEmpty  = "scan.pruneEmptyDirs"
+	prefAutoRefresh = "scan.autoRefresh"
+	prefDropAction  = "drop.action"
+	prefRedactions  = "output.redactions"
+	prefPseudonyms  = "output.pseudonyms"
+	prefSkipPaths   = "scan.skipPaths"       // One path per line; a list preference cannot be saved empty
+	prefAlwaysShow  = "scan.alwaysShowNames" // One name per line, like prefSkipPaths
+	prefSnapshotDir = "snapshots.dir"
+	prefSymbols     = "accessibility.symbols"
+	prefContrast    = "accessibility.highContrast"
+)
+
+// maxRecordedExports bounds the saved-file history kept in preferences.
+const maxRecordedExports = 500
+
+// Labels for the command shell selector.
+const (
+	shellPOSIXLabel      = "POSIX shell"
+	shellPowerShellLabel = "PowerShell"
+)
+
+// localeChoices lists the selectable formatting locales; the first entry follows the system.
+var localeChoices = []struct{ label, tag string }{
+	{"System default", ""},
+	{"English (US)", "en-US"},
+	{"English (UK)", "en-GB"},
+	{"Deutsch", "de-DE"},
+	{"Français", "fr-FR"},
+	{"Español", "es-ES"},
+	{"Italiano", "it-IT"},
+	{"Nederlands", "nl-NL"},
+	{"Polski", "pl-PL"},
+	{"Русский", "ru-RU"},
+	{"日本語", "ja-JP"},
+	{"中文", "zh-CN"},
+}
+
+// Tree row densities.
+const (
+	densityComfortable = "comfortable"
+	densityCompact     = "compact" // Less padding around each row, down to minRowHeight
+)
+
+// Labels for the row density selector.
+const (
+	densityComfortableLabel = "Comfortable"
+	densityCompactLabel     = "Compact"
+)
+
+// Labels for the size basis selector.
+const (
+	sizeBasisApparentLabel = "Apparent size"
+	sizeBasisOnDiskLabel   = "Size on disk"
+)
+
+// uiSettings holds display options that persist across launches.
+type uiSettings struct {
+	TreeGuides  bool
+	TreeShading bool
+	TreeDensity string // densityComfortable or densityCompact
+	Monospace   bool   // Draw tree rows in a monospace face so sizes line up
+	ShowSize    bool
+	SizeBasis   string
+	Locale      string
+	Portable    bool
+	Footer      bool
+	DepthColors bool // Depth-muted text colors in HTML exports
+
+	CommandShell string // shellcmd.POSIX or shellcmd.PowerShell
+
+	ExportPaths []string // Files saved by the app, most recent last
+	SnapshotDir string   // Folder of scheduled snapshots browsed under Tools → Snapshots
+	ShowExports bool
+
+	InventoryRows int    // Rows per CSV inventory part; 0 writes a single file
+	OutlineIndent string // Indentation per level of outline output; one of outlineIndents
+
+	HonorExportIgnore bool // Leave export-ignore paths out of context packs
+	HideExportIgnored bool // Also leave them out of the rendered output
+
+	PrescanDialog     bool // Offer to leave out top-level entries of large folders before scanning
+	PrescanMinEntries int  // Entry count above which the pre-scan dialog appears
+	RespectGitignore  bool
+	TreeIgnore        bool // Skip entries matched by a .treeignore file at the scan root
+	FollowSymlinks    bool
+	OneFileSystem     bool // Do not read folders on other filesystems, such as mounted shares
+	CollectTimes      bool
+	CollectMode       bool   // Show permissions like "drwxr-xr-x" before each entry
+	ComputeHashes     bool   // Hash file contents to find duplicates
+	CountLines        bool   // Count the lines of text files
+	EstimateTokens    bool   // Estimate the language model tokens of text files
+	DuplicateDirMin   int    // Entries a copied folder must hold to be reported (0 = off)
+	CollectXattrs     bool   // List extended attributes or alternate data streams
+	NaturalSort       bool   // Sort numbers in names by value
+	SortBy            string // config.SortByName, SortBySize, SortByModTime or SortByExtension
+	SortDescending    bool
+	Background        bool     // Scan at background priority; also toggled from the progress dialog
+	MaxDepth          int      // Deepest level scanned (-1 = unlimited)
+	HardDepthLimit    int      // Depth never scanned past, even when MaxDepth is unlimited (0 = no cap)
+	MaxNodes          int      // Entries found before the scan stops (0 = no limit)
+	TimeLimit         int      // Seconds a scan may take before it stops with what it has (0 = no limit)
+	DirTimeout        int      // Seconds to wait for one directory before skipping it (0 = no limit)
+	IncludePatterns   []string // Include and exclude patterns are edited in the main window
+	ExcludePatterns   []string
+	SkipPaths         []string // System paths never scanned; empty scans everything
+	AlwaysShowNames   []string // Hidden names or globs shown even when hidden files are not
+	PruneEmptyDirs    bool     // Leave out directories with no entries after filtering
+	AutoRefresh       bool     // Keep the shown tree up to date as the folder changes; toggled in the main window
+
+	QuoteNames     bool // Quote names that could be mistaken for tree structure in the output
+	MarkUnreadable bool // Mark directories that could not be read in the output
+	KindIcons      bool // Mark files with icons for their kind in the output
+	OnlyXattrs     bool // Leave entries without extended attributes out of the output
+
+	Redactions []string // Text masked in saved and copied output, one rule per line in preferences
+	Pseudonyms bool     // Replace redacted text with stable pseudonyms instead of a mask
+
+	DropAction string // What a folder dropped onto a shown result does; one of dropChoices
+
+	Symbols      bool // Mark changes, flagged entries and terminal colors with glyphs instead of colors
+	HighContrast bool // Draw the window with the high-contrast theme
+}
+
+// defaultSettings returns the settings in effect before any are saved, taking scan defaults
+// from cfg. Sections of the settings dialog are reverted to these with cfg at its defaults.
+func defaultSettings(cfg *config.Config) uiSettings {
+	return uiSettings{
+		TreeDensity: densityComfortable,
+		ShowSize:    cfg.ShowSize,
+		SizeBasis:   cfg.SizeBasis,
+		Locale:      cfg.Locale,
+		Portable:    cfg.PortableOutput,
+		Footer:      cfg.OutputFooter,
+
+		CommandShell: string(defaultShell()),
+
+		ExportPaths: cfg.ExportPaths,
+		ShowExports: cfg.ShowExports,
+
+		HonorExportIgnore: contextpack.DefaultOptions().HonorExportIgnore,
+
+		OutlineIndent: renderer.DefaultOutlineIndent,
+
+		PrescanDialog:     true,
+		PrescanMinEntries: defaultPrescanMinEntries,
+		RespectGitignore:  cfg.RespectGitignore,
+		TreeIgnore:        cfg.TreeIgnore,
+		FollowSymlinks:    cfg.FollowSymlinks,
+		OneFileSystem:     cfg.OneFileSystem,
+		CollectTimes:      cfg.CollectTimes,
+		CollectMode:       cfg.CollectMode,
+		ComputeHashes:     cfg.ComputeHashes,
+		CountLines:        cfg.CountLines,
+		EstimateTokens:    cfg.EstimateTokens,
+		DuplicateDirMin:   cfg.DuplicateDirMinItems,
+		CollectXattrs:     cfg.CollectXattrs,
+		NaturalSort:       cfg.NaturalSort,
+		SortBy:            cfg.SortBy,
+		SortDescending:    cfg.SortDescending,
+		Background:        cfg.BackgroundPriority,
+		MaxDepth:          cfg.MaxDepth,
+		HardDepthLimit:    cfg.HardDepthLimit,
+		MaxNodes:          cfg.MaxNodes,
+		TimeLimit:         int(cfg.ScanTimeout / time.Second),
+		DirTimeout:        int(cfg.DirTimeout / time.Second),
+		IncludePatterns:   cfg.IncludePatterns,
+		ExcludePatterns:   cfg.ExcludePatterns,
+		SkipPaths:         cfg.SkipPaths,
+		AlwaysShowNames:   cfg.AlwaysShowNames,
+		PruneEmptyDirs:    cfg.PruneEmptyDirs,
+
+		DropAction: dropAsk,
+	}
+}
+
+// loadSettings reads UI settings from the application preferences, using cfg for scan defaults.
+func loadSettings(prefs fyne.Preferences, cfg *config.Config) uiSettings {
+	d := defaultSettings(cfg)
+	return uiSettings{
+		TreeGuides:  prefs.BoolWithFallback(prefTreeGuides, d.TreeGuides),
+		TreeShading: prefs.BoolWithFallback(prefTreeShading, d.TreeShading),
+		TreeDensity: prefs.StringWithFallback(prefTreeDensity, d.TreeDensity),
+		Monospace:   prefs.BoolWithFallback(prefMonospace, d.Monospace),
+		ShowSize:    prefs.BoolWithFallback(prefShowSize, d.ShowSize),
+		SizeBasis:   prefs.StringWithFallback(prefSizeBasis, d.SizeBasis),
+		Locale:      prefs.StringWithFallback(prefLocale, d.Locale),
+		Portable:    prefs.BoolWithFallback(prefPortable, d.Portable),
+		Footer:      prefs.BoolWithFallback(prefFooter, d.Footer),
+		DepthColors: prefs.BoolWithFallback(prefDepthColors, d.DepthColors),
+
+		CommandShell: prefs.StringWithFallback(prefShell, d.CommandShell),
+
+		ExportPaths: prefs.StringListWithFallback(prefExportPaths, d.ExportPaths),
+		SnapshotDir: prefs.StringWithFallback(prefSnapshotDir, d.SnapshotDir),
+		ShowExports: prefs.BoolWithFallback(prefShowExports, d.ShowExports),
+
+		InventoryRows: prefs.IntWithFallback(prefInventory, d.InventoryRows),
+		OutlineIndent: prefs.StringWithFallback(prefOutline, d.OutlineIndent),
+
+		HonorExportIgnore: prefs.BoolWithFallback(prefHonorIgnore, d.HonorExportIgnore),
+		HideExportIgnored: prefs.BoolWithFallback(prefHideIgnored, d.HideExportIgnored),
+
+		PrescanDialog:     prefs.BoolWithFallback(prefPrescan, d.PrescanDialog),
+		PrescanMinEntries: prefs.IntWithFallback(prefPrescanMin, d.PrescanMinEntries),
+		RespectGitignore:  prefs.BoolWithFallback(prefGitignore, d.RespectGitignore),
+		TreeIgnore:        prefs.BoolWithFallback(prefTreeIgnore, d.TreeIgnore),
+		FollowSymlinks:    prefs.BoolWithFallback(prefFollowLinks, d.FollowSymlinks),
+		OneFileSystem:     prefs.BoolWithFallback(prefOneFS, d.OneFileSystem),
+		CollectTimes:      prefs.BoolWithFallback(prefTimes, d.CollectTimes),
+		CollectMode:       prefs.BoolWithFallback(prefModes, d.CollectMode),
+		ComputeHashes:     prefs.BoolWithFallback(prefHashes, d.ComputeHashes),
+		CountLines:        prefs.BoolWithFallback(prefLines, d.CountLines),
+		EstimateTokens:    prefs.BoolWithFallback(prefTokens, d.EstimateTokens),
+		DuplicateDirMin:   prefs.IntWithFallback(prefDupDirs, d.DuplicateDirMin),
+		CollectXattrs:     prefs.BoolWithFallback(prefXattrs, d.CollectXattrs),
+		NaturalSort:       prefs.BoolWithFallback(prefNatural, d.NaturalSort),
+		SortBy:            prefs.StringWithFallback(prefSortBy, d.SortBy),
+		SortDescending:    prefs.BoolWithFallback(prefSortDesc, d.SortDescending),
+		Background:        prefs.BoolWithFallback(prefBackground, d.Background),
+		MaxDepth:          prefs.IntWithFallback(prefMaxDepth, d.MaxDepth),
+		HardDepthLimit:    prefs.IntWithFallback(prefHardDepth, d.HardDepthLimit),
+		MaxNodes:          prefs.IntWithFallback(prefMaxNodes, d.MaxNodes),
+		TimeLimit:         prefs.IntWithFallback(prefTimeLimit, d.TimeLimit),
+		DirTimeout:        prefs.IntWithFallback(prefDirTimeout, d.DirTimeout),
+		IncludePatterns:   prefs.StringListWithFallback(prefInclude, d.IncludePatterns),
+		ExcludePatterns:   prefs.StringListWithFallback(prefExclude, d.ExcludePatterns),
+		SkipPaths:         parseSkipPaths(prefs.StringWithFallback(prefSkipPaths, strings.Join(d.SkipPaths, "\n"))),
+		AlwaysShowNames:   parseSkipPaths(prefs.StringWithFallback(prefAlwaysShow, strings.Join(d.AlwaysShowNames, "\n"))),
+		PruneEmptyDirs:    prefs.BoolWithFallback(prefPruneEmpty, d.PruneEmptyDirs),
+		AutoRefresh:       prefs.BoolWithFallback(prefAutoRefresh, d.AutoRefresh),
+
+		QuoteNames:     prefs.BoolWithFallback(prefQuoteNames, d.QuoteNames),
+		MarkUnreadable: prefs.BoolWithFallback(prefUnreadable, d.MarkUnreadable),
+		KindIcons:      prefs.BoolWithFallback(prefKindIcons, d.KindIcons),
+		OnlyXattrs:     prefs.BoolWithFallback(prefOnlyXattrs, d.OnlyXattrs),
+
+		Redactions: parseSkipPaths(prefs.StringWithFallback(prefRedactions, strings.Join(d.Redactions, "\n"))),
+		Pseudonyms: prefs.BoolWithFallback(prefPseudonyms, d.Pseudonyms),
+
+		DropAction: prefs.StringWithFallback(prefDropAction, d.DropAction),
+
+		Symbols:      prefs.BoolWithFallback(prefSymbols, d.Symbols),
+		HighContrast: prefs.BoolWithFallback(prefContrast, d.HighContrast),
+	}
+}
+
+// save writes UI settings to the application preferences.
+func (s uiSettings) save(prefs fyne.Preferences) {
+	prefs.SetBool(prefTreeGuides, s.TreeGuides)
+	prefs.SetBool(prefTreeShading, s.TreeShading)
+	prefs.SetString(prefTreeDensity, s.TreeDensity)
+	prefs.SetBool(prefMonospace, s.Monospace)
+	prefs.SetBool(prefShowSize, s.ShowSize)
+	prefs.SetString(prefSizeBasis, s.SizeBasis)
+	prefs.SetString(prefLocale, s.Locale)
+	prefs.SetBool(prefPortable, s.Portable)
+	prefs.SetBool(prefFooter, s.Footer)
+	prefs.SetBool(prefDepthColors, s.DepthColors)
+	prefs.SetString(prefShell, s.CommandShell)
+	prefs.SetStringList(prefExportPaths, s.ExportPaths)
+	prefs.SetString(prefSnapshotDir, s.SnapshotDir)
+	prefs.SetBool(prefShowExports, s.ShowExports)
+	prefs.SetInt(prefInventory, s.InventoryRows)
+	prefs.SetString(prefOutline, s.OutlineIndent)
+	prefs.SetBool(prefHonorIgnore, s.HonorExportIgnore)
+	prefs.SetBool(prefHideIgnored, s.HideExportIgnored)
+	prefs.SetBool(prefPrescan, s.PrescanDialog)
+	prefs.SetInt(prefPrescanMin, s.PrescanMinEntries)
+	prefs.SetBool(prefGitignore, s.RespectGitignore)
+	prefs.SetBool(prefTreeIgnore, s.TreeIgnore)
+	prefs.SetBool(prefFollowLinks, s.FollowSymlinks)
+	prefs.SetBool(prefOneFS, s.OneFileSystem)
+	prefs.SetBool(prefTimes, s.CollectTimes)
+	prefs.SetBool(prefModes, s.CollectMode)
+	prefs.SetBool(prefHashes, s.ComputeHashes)
+	prefs.SetBool(prefLines, s.CountLines)
+	prefs.SetBool(prefTokens, s.EstimateTokens)
+	prefs.SetInt(prefDupDirs, s.DuplicateDirMin)
+	prefs.SetBool(prefXattrs, s.CollectXattrs)
+	prefs.SetBool(prefNatural, s.NaturalSort)
+	prefs.SetString(prefSortBy, s.SortBy)
+	prefs.SetBool(prefSortDesc, s.SortDescending)
+	prefs.SetBool(prefBackground, s.Background)
+	prefs.SetInt(prefMaxDepth, s.MaxDepth)
+	prefs.SetInt(prefHardDepth, s.HardDepthLimit)
+	prefs.SetInt(prefMaxNodes, s.MaxNodes)
+	prefs.SetInt(prefTimeLimit, s.TimeLimit)
+	prefs.SetInt(prefDirTimeout, s.DirTimeout)
+	prefs.SetStringList(prefInclude, s.IncludePatterns)
+	prefs.SetStringList(prefExclude, s.ExcludePatterns)
+	prefs.SetString(prefSkipPaths, strings.Join(s.SkipPaths, "\n"))
+	prefs.SetString(prefAlwaysShow, strings.Join(s.AlwaysShowNames, "\n"))
+	prefs.SetBool(prefPruneEmpty, s.PruneEmptyDirs)
+	prefs.SetBool(prefAutoRefresh, s.AutoRefresh)
+	prefs.SetBool(prefQuoteNames, s.QuoteNames)
+	prefs.SetBool(prefUnreadable, s.MarkUnreadable)
+	prefs.SetBool(prefKindIcons, s.KindIcons)
+	prefs.SetBool(prefOnlyXattrs, s.OnlyXattrs)
+	prefs.SetString(prefRedactions, strings.Join(s.Redactions, "\n"))
+	prefs.SetBool(prefPseudonyms, s.Pseudonyms)
+	prefs.SetString(prefDropAction, s.DropAction)
+	prefs.SetBool(prefSymbols, s.Symbols)
+	prefs.SetBool(prefContrast, s.HighContrast)
+}
+
+// defaultShell returns the shell native to the running platform.
+func defaultShell() shellcmd.Shell {
+	if runtime.GOOS == "windows" {
+		return shellcmd.PowerShell
+	}
+	return shellcmd.POSIX
+}
+
+// applyTo copies the scan-related settings into cfg.
+func (s uiSettings) applyTo(cfg *config.Config) {
+	cfg.ShowSize = s.ShowSize
+	cfg.SizeBasis = s.SizeBasis
+	cfg.Locale = s.Locale
+	cfg.PortableOutput = s.Portable
+	cfg.OutputFooter = s.Footer
+	cfg.ExportPaths = s.ExportPaths
+	cfg.ShowExports = s.ShowExports
+	cfg.RespectGitignore = s.RespectGitignore
+	cfg.TreeIgnore = s.TreeIgnore
+	cfg.FollowSymlinks = s.FollowSymlinks
+	cfg.OneFileSystem = s.OneFileSystem
+	cfg.CollectTimes = s.CollectTimes
+	cfg.CollectMode = s.CollectMode
+	cfg.ComputeHashes = s.ComputeHashes
+	cfg.CountLines = s.CountLines
+	cfg.EstimateTokens = s.EstimateTokens
+	cfg.DuplicateDirMinItems = s.DuplicateDirMin
+	cfg.CollectXattrs = s.CollectXattrs
+	cfg.NaturalSort = s.NaturalSort
+	cfg.SortBy = s.SortBy
+	cfg.SortDescending = s.SortDescending
+	cfg.BackgroundPriority = s.Background
+	cfg.MaxDepth = s.MaxDepth
+	cfg.HardDepthLimit = s.HardDepthLimit
+	cfg.MaxNodes = s.MaxNodes
+	cfg.ScanTimeout = time.Duration(s.TimeLimit) * time.Second
+	cfg.DirTimeout = time.Duration(s.DirTimeout) * time.Second
+	cfg.IncludePatterns = s.IncludePatterns
+	cfg.ExcludePatterns = s.ExcludePatterns
+	cfg.SkipPaths = s.SkipPaths
+	cfg.AlwaysShowNames = s.AlwaysShowNames
+	cfg.PruneEmptyDirs = s.PruneEmptyDirs
+}
+
+// parseSkipPaths splits text into one skip path, redaction rule or always shown name per
+// non-blank line.
+func parseSkipPaths(text string) []string {
+	var paths []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
+
+// handleSettings shows the settings dialog. Edits change a draft, which Apply validates and puts
+// into effect and Cancel drops; each section can be reverted to its defaults before applying.
+func (app *FileTreeApp) handleSettings() {
+	draft := app.settings
+	defaults := defaultSettings(config.DefaultConfig())
+
+	// Controls only edit the draft, so setting their state never has side effects; showDraft
+	// sets it once they all exist
+	guides := widget.NewCheck("Indentation guide lines", func(checked bool) {
+		draft.TreeGuides = checked
+	})
+
+	shading := widget.NewCheck("Alternate row shading", func(checked bool) {
+		draft.TreeShading = checked
+	})
+
+	density := widget.NewRadioGroup([]string{densityComfortableLabel, densityCompactLabel}, func(selected string) {
+		draft.TreeDensity = densityComfortable
+		if selected == densityCompactLabel {
+			draft.TreeDensity = densityCompact
+		}
+	})
+	density.Horizontal = true
+	density.Required = true
+
+	monospace := widget.NewCheck("Monospace names", func(checked bool) {
+		draft.Monospace = checked
+	})
+
+	showSize := widget.NewCheck("Collect file sizes (slower on large trees)", func(checked bool) {
+		draft.ShowSize = checked
+	})
+
+	sizeBasis := widget.NewRadioGroup([]string{sizeBasisApparentLabel, sizeBasisOnDiskLabel}, func(selected string) {
+		draft.SizeBasis = config.SizeApparent
+		if selected == sizeBasisOnDiskLabel {
+			draft.SizeBasis = config.SizeAllocated
+		}
+	})
+	sizeBasis.Horizontal = true
+	sizeBasis.Required = true
+
+	labels := make([]string, len(localeChoices))
+	for i, choice := range localeChoices {
+		labels[i] = choice.label
+	}
+	localeSelect := widget.NewSelect(labels, func(selected string) {
+		for _, choice := range localeChoices {
+			if choice.label == selected {
+				draft.Locale = choice.tag
+			}
+		}
+	})
+
+	footer := widget.NewCheck("Append directory and file counts and scan date to output", func(checked bool) {
+		draft.Footer = checked
+	})
+
+	portable := widget.NewCheck("Portable output (C-locale numbers and dates)", func(checked bool) {
+		draft.Portable = checked
+	})
+
+	showExports := widget.NewCheck("Show files saved by this app in scans", func(checked bool) {
+		draft.ShowExports = checked
+	})
+
+	depthColors := widget.NewCheck("Depth colors in HTML exports", func(checked bool) {
+		draft.DepthColors = checked
+	})
+
+	honorIgnore := widget.NewCheck("Leave export-ignore paths out of context packs", func(checked bool) {
+		draft.HonorExportIgnore = checked
+	})
+
+	hideIgnored := widget.NewCheck("Hide export-ignore paths in output", func(checked bool) {
+		draft.HideExportIgnored = checked
+	})
+
+	quoteNames := widget.NewCheck("Quote names that look like tree connectors", func(checked bool) {
+		draft.QuoteNames = checked
+	})
+
+	markUnreadable := widget.NewCheck("Mark directories that could not be read with ⚠", func(checked bool) {
+		draft.MarkUnreadable = checked
+	})
+
+	onlyXattrs := widget.NewCheck("Show only entries with extended attributes or alternate data streams", func(checked bool) {
+		draft.OnlyXattrs = checked
+	})
+
+	kindIcons := widget.NewCheck("Icons by file type (⚙ code, 🖼 images, 📝 documents, 📦 archives, 💾 binaries)", func(checked bool) {
+		draft.KindIcons = checked
+	})
+
+	symbols := widget.NewCheck("Use symbols instead of colors (+ added, − removed, ≠ modified, ● flagged)", func(checked bool) {
+		draft.Symbols = checked
+	})
+
+	highContrast := widget.NewCheck("High contrast", func(checked bool) {
+		draft.HighContrast = checked
+	})
+
+	redactions := widget.NewMultiLineEntry()
+	redactions.SetPlaceHolder("One rule per line: literal text, or re: and a regular expression")
+	redactions.SetMinRowsVisible(3)
+	redactions.Validator = func(text string) error {
+		_, err := renderer.NewRedactor(parseSkipPaths(text), false)
+		return err
+	}
+	redactions.OnChanged = func(text string) {
+		if _, err := renderer.NewRedactor(parseSkipPaths(text), false); err == nil {
+			draft.Redactions = parseSkipPaths(text)
+		}
+	}
+
+	pseudonyms := widget.NewCheck("Replace redacted text with stable pseudonyms instead of "+renderer.RedactionMask, func(checked bool) {
+		draft.Pseudonyms = checked
+	})
+
+	redactionWarning := widget.NewLabel("Redaction applies to saved and copied output, not the tree view. Context packs are processed too: paths and any matching text inside the files are replaced.")
+	redactionWarning.Wrapping = fyne.TextWrapWord
+	redactionWarning.Importance = widget.WarningImportance
+
+	gitignore := widget.NewCheck("Skip entries matched by .gitignore files", func(checked bool) {
+		draft.RespectGitignore = checked
+	})
+
+	treeIgnore := widget.NewCheck("Skip entries matched by a .treeignore file in the scanned folder", func(checked bool) {
+		draft.TreeIgnore = checked
+	})
+
+	followLinks := widget.NewCheck("Follow symbolic links to directories", func(checked bool) {
+		draft.FollowSymlinks = checked
+	})
+
+	oneFileSystem := widget.NewCheck("Stay on one filesystem (don't read mounted drives or shares)", func(checked bool) {
+		draft.OneFileSystem = checked
+	})
+
+	collectTimes := widget.NewCheck("Collect modification dates (slower on large trees)", func(checked bool) {
+		draft.CollectTimes = checked
+	})
+
+	collectMode := widget.NewCheck("Show permissions, like drwxr-xr-x (slower on large trees)", func(checked bool) {
+		draft.CollectMode = checked
+	})
+
+	computeHashes := widget.NewCheck("Find duplicate files (reads every file; much slower)", func(checked bool) {
+		draft.ComputeHashes = checked
+	})
+
+	countLines := widget.NewCheck("Count lines of text files up to 1 MB (reads them; slower)", func(checked bool) {
+		draft.CountLines = checked
+	})
+
+	estimateTokens := widget.NewCheck("Estimate AI tokens of text files up to 1 MB, with folder totals (reads them; slower)", func(checked bool) {
+		draft.EstimateTokens = checked
+	})
+
+	collectXattrs := widget.NewCheck("List extended attributes and alternate data streams (slower)", func(checked bool) {
+		draft.CollectXattrs = checked
+	})
+
+	pruneEmpty := widget.NewCheck("Hide folders left empty by filtering", func(checked bool) {
+		draft.PruneEmptyDirs = checked
+	})
+
+	naturalSort := widget.NewCheck("Natural sort order (file2 before file10, ignoring case)", func(checked bool) {
+		draft.NaturalSort = checked
+	})
+
+	background := widget.NewCheck("Scan at background priority (slower, keeps the machine responsive)", func(checked bool) {
+		draft.Background = checked
+	})
+
+	prescan := widget.NewCheck("Choose top-level entries before scanning large folders", func(checked bool) {
+		draft.PrescanDialog = checked
+	})
+
+	prescanMin := widget.NewEntry()
+	prescanMin.Validator = func(text string) error {
+		if entries, err := strconv.Atoi(text); err != nil || entries < 0 {
+			return fmt.Errorf("enter 0 or a positive number")
+		}
+		return nil
+	}
+	prescanMin.OnChanged = func(text string) {
+		if entries, err := strconv.Atoi(text); err == nil && entries >= 0 {
+			draft.PrescanMinEntries = entries
+		}
+	}
+
+	maxDepth := widget.NewEntry()
+	maxDepth.Validator = func(text string) error {
+		if depth, err := strconv.Atoi(text); err != nil || depth < -1 {
+			return fmt.Errorf("enter -1 for unlimited, or 0 or more")
+		}
+		return nil
+	}
+	maxDepth.OnChanged = func(text string) {
+		if depth, err := strconv.Atoi(text); err == nil && depth >= -1 {
+			draft.MaxDepth = depth
+		}
+	}
+
+	hardDepth := widget.NewEntry()
+	hardDepth.Validator = func(text string) error {
+		if depth, err := strconv.Atoi(text); err != nil || depth < 0 {
+			return fmt.Errorf("enter 0 or a positive number")
+		}
+		return nil
+	}
+	hardDepth.OnChanged = func(text string) {
+		if depth, err := strconv.Atoi(text); err == nil && depth >= 0 {
+			draft.HardDepthLimit = depth
+		}
+	}
+
+	maxNodes := widget.NewEntry()
+	maxNodes.Validator = func(text string) error {
+		if nodes, err := strconv.Atoi(text); err != nil || nodes < 0 {
+			return fmt.Errorf("enter 0 or a positive number")
+		}
+		return nil
+	}
+	maxNodes.OnChanged = func(text string) {
+		if nodes, err := strconv.Atoi(text); err == nil && nodes >= 0 {
+			draft.MaxNodes = nodes
+		}
+	}
+
+	timeLimit := widget.NewEntry()
+	timeLimit.Validator = func(text string) error {
+		if seconds, err := strconv.Atoi(text); err != nil || seconds < 0 {
+			return fmt.Errorf("enter 0 or a positive number of seconds")
+		}
+		return nil
+	}
+	timeLimit.OnChanged = func(text string) {
+		if seconds, err := strconv.Atoi(text); err == nil && seconds >= 0 {
+			draft.TimeLimit = seconds
+		}
+	}
+
+	dirTimeout := widget.NewEntry()
+	dirTimeout.Validator = func(text string) error {
+		if seconds, err := strconv.Atoi(text); err != nil || seconds < 0 {
+			return fmt.Errorf("enter 0 or a positive number of seconds")
+		}
+		return nil
+	}
+	dirTimeout.OnChanged = func(text string) {
+		if seconds, err := strconv.Atoi(text); err == nil && seconds >= 0 {
+			draft.DirTimeout = seconds
+		}
+	}
+
+	duplicateDirs := widget.NewEntry()
+	duplicateDirs.Validator = func(text string) error {
+		if items, err := strconv.Atoi(text); err != nil || items < 0 {
+			return fmt.Errorf("enter 0 or a positive number")
+		}
+		return nil
+	}
+	duplicateDirs.OnChanged = func(text string) {
+		if items, err := strconv.Atoi(text); err == nil && items >= 0 {
+			draft.DuplicateDirMin = items
+		}
+	}
+
+	skipPaths := widget.NewMultiLineEntry()
+	skipPaths.SetPlaceHolder("One path per line; empty scans everything")
+	skipPaths.SetMinRowsVisible(4)
+	skipPaths.OnChanged = func(text string) {
+		draft.SkipPaths = parseSkipPaths(text)
+	}
+	skipDefaults := widget.NewButton("Restore Defaults", func() {
+		skipPaths.SetText(strings.Join(config.DefaultSkipPaths(), "\n"))
+	})
+	skipClear := widget.NewButton("Clear", func() { skipPaths.SetText("") })
+
+	alwaysShow := widget.NewMultiLineEntry()
+	alwaysShow.SetPlaceHolder("One name or glob per line, e.g. .env.example")
+	alwaysShow.SetMinRowsVisible(3)
+	alwaysShow.Validator = func(text string) error {
+		for _, name := range parseSkipPaths(text) {
+			if _, err := filepath.Match(name, ""); err != nil {
+				return fmt.Errorf("invalid name pattern %q: %w", name, err)
+			}
+		}
+		return nil
+	}
+	alwaysShow.OnChanged = func(text string) {
+		draft.AlwaysShowNames = parseSkipPaths(text)
+	}
+
+	inventoryRows := widget.NewEntry()
+	inventoryRows.Validator = func(text string) error {
+		if rows, err := strconv.Atoi(text); err != nil || rows < 0 {
+			return fmt.Errorf("enter 0 or a positive number")
+		}
+		return nil
+	}
+	inventoryRows.OnChanged = func(text string) {
+		if rows, err := strconv.Atoi(text); err == nil && rows >= 0 {
+			draft.InventoryRows = rows
+		}
+	}
+
+	outlineLabels := make([]string, len(outlineIndents))
+	for i, choice := range outlineIndents {
+		outlineLabels[i] = choice.label
+	}
+	outlineIndent := widget.NewSelect(outlineLabels, func(selected string) {
+		for _, choice := range outlineIndents {
+			if choice.label == selected {
+				draft.OutlineIndent = choice.indent
+			}
+		}
+	})
+
+	dropLabels := make([]string, len(dropChoices))
+	for i, choice := range dropChoices {
+		dropLabels[i] = choice.label
+	}
+	dropAction := widget.NewSelect(dropLabels, func(selected string) {
+		for _, choice := range dropChoices {
+			if choice.label == selected {
+				draft.DropAction = choice.action
+			}
+		}
+	})
+
+	shell := widget.NewRadioGroup([]string{shellPOSIXLabel, shellPowerShellLabel}, func(selected string) {
+		draft.CommandShell = string(shellcmd.POSIX)
+		if selected == shellPowerShellLabel {
+			draft.CommandShell = string(shellcmd.PowerShell)
+		}
+	})
+	shell.Horizontal = true
+	shell.Required = true
+
+	// showDraft sets every control from the draft, when the dialog opens and after a revert
+	showDraft := func() {
+		guides.SetChecked(draft.TreeGuides)
+		shading.SetChecked(draft.TreeShading)
+		density.SetSelected(densityComfortableLabel)
+		if draft.TreeDensity == densityCompact {
+			density.SetSelected(densityCompactLabel)
+		}
+		monospace.SetChecked(draft.Monospace)
+		showSize.SetChecked(draft.ShowSize)
+		sizeBasis.SetSelected(sizeBasisApparentLabel)
+		if draft.SizeBasis == config.SizeAllocated {
+			sizeBasis.SetSelected(sizeBasisOnDiskLabel)
+		}
+		localeSelect.SetSelected(labels[0])
+		for _, choice := range localeChoices {
+			if choice.tag == draft.Locale {
+				localeSelect.SetSelected(choice.label)
+			}
+		}
+		footer.SetChecked(draft.Footer)
+		portable.SetChecked(draft.Portable)
+		showExports.SetChecked(draft.ShowExports)
+		depthColors.SetChecked(draft.DepthColors)
+		honorIgnore.SetChecked(draft.HonorExportIgnore)
+		hideIgnored.SetChecked(draft.HideExportIgnored)
+		quoteNames.SetChecked(draft.QuoteNames)
+		markUnreadable.SetChecked(draft.MarkUnreadable)
+		onlyXattrs.SetChecked(draft.OnlyXattrs)
+		kindIcons.SetChecked(draft.KindIcons)
+		symbols.SetChecked(draft.Symbols)
+		highContrast.SetChecked(draft.HighContrast)
+		redactions.SetText(strings.Join(draft.Redactions, "\n"))
+		pseudonyms.SetChecked(draft.Pseudonyms)
+		gitignore.SetChecked(draft.RespectGitignore)
+		treeIgnore.SetChecked(draft.TreeIgnore)
+		followLinks.SetChecked(draft.FollowSymlinks)
+		oneFileSystem.SetChecked(draft.OneFileSystem)
+		collectTimes.SetChecked(draft.CollectTimes)
+		collectMode.SetChecked(draft.CollectMode)
+		computeHashes.SetChecked(draft.ComputeHashes)
+		countLines.SetChecked(draft.CountLines)
+		estimateTokens.SetChecked(draft.EstimateTokens)
+		collectXattrs.SetChecked(draft.CollectXattrs)
+		naturalSort.SetChecked(draft.NaturalSort)
+		pruneEmpty.SetChecked(draft.PruneEmptyDirs)
+		background.SetChecked(draft.Background)
+		prescan.SetChecked(draft.PrescanDialog)
+		prescanMin.SetText(strconv.Itoa(draft.PrescanMinEntries))
+		maxDepth.SetText(strconv.Itoa(draft.MaxDepth))
+		hardDepth.SetText(strconv.Itoa(draft.HardDepthLimit))
+		maxNodes.SetText(strconv.Itoa(draft.MaxNodes))
+		timeLimit.SetText(strconv.Itoa(draft.TimeLimit))
+		dirTimeout.SetText(strconv.Itoa(draft.DirTimeout))
+		duplicateDirs.SetText(strconv.Itoa(draft.DuplicateDirMin))
+		skipPaths.SetText(strings.Join(draft.SkipPaths, "\n"))
+		alwaysShow.SetText(strings.Join(draft.AlwaysShowNames, "\n"))
+		inventoryRows.SetText(strconv.Itoa(draft.InventoryRows))
+		outlineIndent.SetSelected(outlineLabels[0])
+		for _, choice := range outlineIndents {
+			if choice.indent == draft.OutlineIndent {
+				outlineIndent.SetSelected(choice.label)
+			}
+		}
+		dropAction.SetSelected(dropLabels[0])
+		for _, choice := range dropChoices {
+			if choice.action == draft.DropAction {
+				dropAction.SetSelected(choice.label)
+			}
+		}
+		shell.SetSelected(shellPOSIXLabel)
+		if draft.CommandShell == string(shellcmd.PowerShell) {
+			shell.SetSelected(shellPowerShellLabel)
+		}
+	}
+	showDraft()
+
+	// section returns a section heading with a button resetting the section's settings in the draft
+	section := func(title string, revert func(d *uiSettings)) fyne.CanvasObject {
+		button := widget.NewButton("Revert to Defaults", func() {
+			revert(&draft)
+			showDraft()
+		})
+		return container.NewBorder(nil, nil, widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), button)
+	}
+
+	content := container.NewVBox(
+		section("Tree view", func(d *uiSettings) {
+			d.TreeGuides, d.TreeShading = defaults.TreeGuides, defaults.TreeShading
+			d.TreeDensity, d.Monospace = defaults.TreeDensity, defaults.Monospace
+		}),
+		guides,
+		shading,
+		container.NewBorder(nil, nil, widget.NewLabel("Row density"), nil, density),
+		monospace,
+		section("Sizes", func(d *uiSettings) {
+			d.ShowSize, d.SizeBasis = defaults.ShowSize, defaults.SizeBasis
+		}),
+		showSize,
+		sizeBasis,
+		section("Scanning", func(d *uiSettings) {
+			d.RespectGitignore, d.FollowSymlinks, d.NaturalSort = defaults.RespectGitignore, defaults.FollowSymlinks, defaults.NaturalSort
+			d.CollectTimes, d.ComputeHashes, d.CollectXattrs = defaults.CollectTimes, defaults.ComputeHashes, defaults.CollectXattrs
+			d.DuplicateDirMin, d.Background = defaults.DuplicateDirMin, defaults.Background
+			d.PrescanDialog, d.PrescanMinEntries = defaults.PrescanDialog, defaults.PrescanMinEntries
+			d.MaxDepth, d.HardDepthLimit, d.MaxNodes = defaults.MaxDepth, defaults.HardDepthLimit, defaults.MaxNodes
+			d.DropAction, d.SkipPaths, d.PruneEmptyDirs = defaults.DropAction, defaults.SkipPaths, defaults.PruneEmptyDirs
+			d.AlwaysShowNames, d.TreeIgnore = defaults.AlwaysShowNames, defaults.TreeIgnore
+			d.OneFileSystem, d.CollectMode, d.CountLines = defaults.OneFileSystem, defaults.CollectMode, defaults.CountLines
+			d.EstimateTokens, d.TimeLimit, d.DirTimeout = defaults.EstimateTokens, defaults.TimeLimit, defaults.DirTimeout
+		}),
+		gitignore,
+		treeIgnore,
+		followLinks,
+		oneFileSystem,
+		naturalSort,
+		pruneEmpty,
+		collectTimes,
+		collectMode,
+		computeHashes,
+		countLines,
+		estimateTokens,
+		collectXattrs,
+		container.NewBorder(nil, nil, widget.NewLabel("Find copied folders holding at least (0 = off)"), nil, duplicateDirs),
+		background,
+		prescan,
+		container.NewBorder(nil, nil, widget.NewLabel("Ask when a folder has more entries than"), nil, prescanMin),
+		container.NewBorder(nil, nil, widget.NewLabel("Maximum depth (-1 = unlimited)"), nil, maxDepth),
+		container.NewBorder(nil, nil, widget.NewLabel("Never scan deeper than (0 = no cap)"), nil, hardDepth),
+		container.NewBorder(nil, nil, widget.NewLabel("Stop after this many items (0 = no limit)"), nil, maxNodes),
+		container.NewBorder(nil, nil, widget.NewLabel("Stop after this many seconds (0 = no limit)"), nil, timeLimit),
+		container.NewBorder(nil, nil, widget.NewLabel("Skip a folder not listed within seconds (0 = wait)"), nil, dirTimeout),
+		container.NewBorder(nil, nil, widget.NewLabel("Dropping a folder onto a result"), nil, dropAction),
+		widget.NewLabel("System paths never scanned (whole path components, e.g. Windows\\System32\\config)"),
+		skipPaths,
+		container.NewHBox(skipDefaults, skipClear),
+		widget.NewLabel("Hidden names shown anyway (exclude patterns and .gitignore still leave them out)"),
+		alwaysShow,
+		section("Formatting", func(d *uiSettings) {
+			d.Locale, d.Footer, d.Portable = defaults.Locale, defaults.Footer, defaults.Portable
+			d.QuoteNames, d.MarkUnreadable = defaults.QuoteNames, defaults.MarkUnreadable
+			d.OnlyXattrs, d.KindIcons, d.DepthColors = defaults.OnlyXattrs, defaults.KindIcons, defaults.DepthColors
+			d.ShowExports, d.InventoryRows, d.OutlineIndent = defaults.ShowExports, defaults.InventoryRows, defaults.OutlineIndent
+		}),
+		container.NewBorder(nil, nil, widget.NewLabel("Locale"), nil, localeSelect),
+		footer,
+		portable,
+		quoteNames,
+		markUnreadable,
+		onlyXattrs,
+		kindIcons,
+		depthColors,
+		showExports,
+		container.NewBorder(nil, nil, widget.NewLabel("Rows per CSV inventory file (0 = one file)"), nil, inventoryRows),
+		container.NewBorder(nil, nil, widget.NewLabel("Indent outlines (.outline files, Copy as Outline) with"), nil, outlineIndent),
+		section("Accessibility", func(d *uiSettings) {
+			d.Symbols, d.HighContrast = defaults.Symbols, defaults.HighContrast
+		}),
+		symbols,
+		highContrast,
+		section("Redaction", func(d *uiSettings) {
+			d.Redactions, d.Pseudonyms = defaults.Redactions, defaults.Pseudonyms
+		}),
+		redactions,
+		pseudonyms,
+		redactionWarning,
+		section(".gitattributes export-ignore", func(d *uiSettings) {
+			d.HonorExportIgnore, d.HideExportIgnored = defaults.HonorExportIgnore, defaults.HideExportIgnored
+		}),
+		honorIgnore,
+		hideIgnored,
+		section("Copy as command", func(d *uiSettings) {
+			d.CommandShell = defaults.CommandShell
+		}),
+		shell,
+	)
+
+	validated := []*widget.Entry{prescanMin, maxDepth, hardDepth, maxNodes, timeLimit, dirTimeout, duplicateDirs, alwaysShow, inventoryRows, redactions}
+	var settingsDialog *dialog.CustomDialog
+	cancel := widget.NewButton("Cancel", func() { settingsDialog.Hide() })
+	apply := widget.NewButton("Apply", func() {
+		for _, entry := range validated {
+			if err := entry.Validate(); err != nil {
+				app.showError("Settings", fmt.Errorf("settings were not applied: %w", err))
+				return
+			}
+		}
+		settingsDialog.Hide()
+		app.applySettingsChange(draft)
+	})
+	apply.Importance = widget.HighImportance
+
+	settingsDialog = dialog.NewCustomWithoutButtons("Settings", content, app.window)
+	settingsDialog.SetButtons([]fyne.CanvasObject{cancel, apply})
+	settingsDialog.Show()
+}
+
+// applySettings persists the current settings, refreshes affected widgets and publishes the
+// configuration change, if any, to app.configChanges.
+func (app *FileTreeApp) applySettings() {
+	app.settings.save(app.app.Preferences())
+	app.settings.applyTo(app.config)
+	if prioritized, ok := app.scanner.(scanner.PriorityScanner); ok {
+		prioritized.SetBackgroundPriority(app.settings.Background)
+	}
+	app.reindexRows()
+	if app.tree != nil {
+		app.tree.Refresh()
+	}
+	app.schedulePrerender()
+
+	previous := app.appliedConfig
+	app.appliedConfig = app.config.Clone()
+	app.configChanges.Publish(previous, app.config)
+}
+
+// configChanged brings the shown result in line with an applied configuration change. Options
+// that only a scan can apply take effect from the next one.
+func (app *FileTreeApp) configChanged(change config.Change) {
+	previous, current := change.Previous, change.Current
+	if scanner.OrderOf(previous) != scanner.OrderOf(current) {
+		app.resort()
+	}
+	if previous.PruneEmptyDirs != current.PruneEmptyDirs {
+		app.pruneEmptyDirs(current.PruneEmptyDirs)
+	}
+	if app.currentResult != nil && (!slices.Equal(previous.IncludePatterns, current.IncludePatterns) ||
+		!slices.Equal(previous.ExcludePatterns, current.ExcludePatterns)) {
+		app.status.setMessage("Patterns apply from the next scan; Refresh to scan again")
+	}
+}
+
+// settingsSnapshot is what Edit → Undo Settings Change returns to.
+type settingsSnapshot struct {
+	settings uiSettings
+	config   *config.Config
+}
+
+// applySettingsChange puts settings edited in the settings dialog into effect, keeping the
+// previous ones to undo the change. Settings edited outside the dialog keep their current values.
+func (app *FileTreeApp) applySettingsChange(next uiSettings) {
+	next.keepOutsideDialog(app.settings)
+	if reflect.DeepEqual(next, app.settings) {
+		return
+	}
+	app.undoSettings = &settingsSnapshot{settings: app.settings, config: app.config.Clone()}
+	app.commitSettings(next)
+	app.status.setMessage("Settings applied")
+}
+
+// handleUndoSettings returns to the settings in effect before the last change applied in the
+// settings dialog. There is one level of undo.
+func (app *FileTreeApp) handleUndoSettings() {
+	snapshot := app.undoSettings
+	if snapshot == nil {
+		return
+	}
+	app.undoSettings = nil
+	previous := snapshot.settings
+	previous.keepOutsideDialog(app.settings)
+	app.config.Restore(snapshot.config)
+	app.commitSettings(previous)
+	app.status.setMessage("Settings change undone")
+}
+
+// commitSettings makes next the current settings and brings the window in line with them,
+// redoing only the work the change calls for: configChanged handles the configuration, and this
+// the settings of the window alone. Edits in the settings dialog reach the rest of the window
+// only through here.
+func (app *FileTreeApp) commitSettings(next uiSettings) {
+	previous := app.settings
+	app.settings = next
+	app.applySettings()
+	if app.undoSettingsItem != nil {
+		app.undoSettingsItem.Disabled = app.undoSettings == nil
+		if app.window.MainMenu() != nil {
+			app.window.MainMenu().Refresh()
+		}
+	}
+
+	if !previous.rendersLike(next) {
+		app.rerenderOutput()
+	}
+	if previous.HighContrast != next.HighContrast {
+		app.applyTheme()
+	}
+}
+
+// keepOutsideDialog takes the settings edited outside the settings dialog from current: saved
+// files, the snapshot folder, scan patterns and the sort order.
+func (s *uiSettings) keepOutsideDialog(current uiSettings) {
+	s.ExportPaths, s.SnapshotDir = current.ExportPaths, current.SnapshotDir
+	s.IncludePatterns, s.ExcludePatterns = current.IncludePatterns, current.ExcludePatterns
+	s.SortBy, s.SortDescending = current.SortBy, current.SortDescending
+	s.AutoRefresh = current.AutoRefresh
+}
+
+// rendersLike reports whether output rendered with s and other reads the same.
+func (s uiSettings) rendersLike(other uiSettings) bool {
+	return s.SizeBasis == other.SizeBasis && s.Locale == other.Locale &&
+		s.Footer == other.Footer && s.Portable == other.Portable &&
+		s.HideExportIgnored == other.HideExportIgnored && s.QuoteNames == other.QuoteNames &&
+		s.MarkUnreadable == other.MarkUnreadable && s.OnlyXattrs == other.OnlyXattrs &&
+		s.KindIcons == other.KindIcons && s.Pseudonyms == other.Pseudonyms && s.Symbols == other.Symbols &&
+		slices.Equal(s.Redactions, other.Redactions)
+}
+
+// recordExport remembers a saved file so later scans can leave it out.
+func (app *FileTreeApp) recordExport(path string) {
+	paths := []string{}
+	for _, recorded := range app.settings.ExportPaths {
+		if recorded != path {
+			paths = append(paths, recorded)
+		}
+	}
+	paths = append(paths, path)
+	if len(paths) > maxRecordedExports {
+		paths = paths[len(paths)-maxRecordedExports:]
+	}
+	app.settings.ExportPaths = paths
+	app.applySettings()
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+)
+
+// patternControls returns the entry and Apply button of a row made by createPatternEntry.
+func patternControls(t *testing.T, row fyne.CanvasObject) (*widget.Entry, *widget.Button) {
+	t.Helper()
+	var entry *widget.Entry
+	var apply *widget.Button
+	for _, object := range row.(*fyne.Container).Objects {
+		switch o := object.(type) {
+		case *widget.Entry:
+			entry = o
+		case *widget.Button:
+			apply = o
+		}
+	}
+	if entry == nil || apply == nil {
+		t.Fatal("pattern row without an entry and a button")
+	}
+	return entry, apply
+}
+
+// recordChanges returns the configuration changes app publishes from now on.
+func recordChanges(app *FileTreeApp) *[]config.Change {
+	changes := &[]config.Change{}
+	app.configChanges.Subscribe(func(change config.Change) { *changes = append(*changes, change) })
+	return changes
+}
+
+func TestPatternEntryStagesEdits(t *testing.T) {
+	app := newTestApp(t)
+	changes := recordChanges(app)
+	entry, apply := patternControls(t, app.createPatternEntry("Exclude", "", &app.settings.ExcludePatterns))
+
+	test.Type(entry, "*.log, build/**")
+	if len(app.settings.ExcludePatterns) != 0 || len(app.config.ExcludePatterns) != 0 {
+		t.Fatalf("typing applied %v", app.config.ExcludePatterns)
+	}
+	if saved := app.app.Preferences().StringList(prefExclude); len(saved) != 0 {
+		t.Fatalf("typing saved %v", saved)
+	}
+	if len(*changes) != 0 {
+		t.Fatalf("typing published %d changes", len(*changes))
+	}
+	if apply.Disabled() {
+		t.Fatal("Apply is disabled with valid patterns staged")
+	}
+
+	test.Tap(apply)
+	want := []string{"*.log", "build/**"}
+	if !slices.Equal(app.config.ExcludePatterns, want) {
+		t.Errorf("applied %v, want %v", app.config.ExcludePatterns, want)
+	}
+	if saved := app.app.Preferences().StringList(prefExclude); !slices.Equal(saved, want) {
+		t.Errorf("saved %v, want %v", saved, want)
+	}
+	if len(*changes) != 1 || !slices.Equal((*changes)[0].Current.ExcludePatterns, want) {
+		t.Errorf("published %d changes, want one to %v", len(*changes), want)
+	}
+	if !apply.Disabled() {
+		t.Error("Apply is enabled with nothing staged")
+	}
+}
+
+func TestPatternEntryAppliesOnEnter(t *testing.T) {
+	app := newTestApp(t)
+	entry, _ := patternControls(t, app.createPatternEntry("Include", "", &app.settings.IncludePatterns))
+
+	test.Type(entry, "*.go")
+	entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	if !slices.Equal(app.config.IncludePatterns, []string{"*.go"}) {
+		t.Errorf("Enter applied %v, want [*.go]", app.config.IncludePatterns)
+	}
+}
+
+func TestPatternEntryKeepsInvalidEditsStaged(t *testing.T) {
+	app := newTestApp(t)
+	changes := recordChanges(app)
+	entry, apply := patternControls(t, app.createPatternEntry("Exclude", "", &app.settings.ExcludePatterns))
+
+	test.Type(entry, "src/[")
+	if !apply.Disabled() {
+		t.Error("Apply is enabled with an invalid pattern")
+	}
+	entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyReturn})
+	if len(app.config.ExcludePatterns) != 0 || len(*changes) != 0 {
+		t.Errorf("an invalid pattern was applied: %v", app.config.ExcludePatterns)
+	}
+}
+
+func TestApplySettingsPublishesOnlyChanges(t *testing.T) {
+	app := newTestApp(t)
+	changes := recordChanges(app)
+
+	app.applySettings()
+	if len(*changes) != 0 {
+		t.Fatalf("unchanged settings published %d changes", len(*changes))
+	}
+
+	app.settings.MaxDepth = 4
+	app.applySettings()
+	if len(*changes) != 1 || (*changes)[0].Previous.MaxDepth == 4 || (*changes)[0].Current.MaxDepth != 4 {
+		t.Fatalf("changes %+v, want one setting MaxDepth to 4", *changes)
+	}
+}
+
+func TestUndoSettingsPublishesChange(t *testing.T) {
+	app := newTestApp(t)
+	changes := recordChanges(app)
+	before := app.config.MaxDepth
+
+	next := app.settings
+	next.MaxDepth = before + 3
+	app.applySettingsChange(next)
+	app.handleUndoSettings()
+
+	if len(*changes) != 2 {
+		t.Fatalf("published %d changes, want the change and its undo", len(*changes))
+	}
+	if undo := (*changes)[1]; undo.Previous.MaxDepth != before+3 || undo.Current.MaxDepth != before {
+		t.Errorf("undo changed MaxDepth from %d to %d, want %d to %d", undo.Previous.MaxDepth, undo.Current.MaxDepth, before+3, before)
+	}
+	if app.config.MaxDepth != before {
+		t.Errorf("MaxDepth %d after undo, want %d", app.config.MaxDepth, before)
+	}
+}
@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/locale"
+	"github.com/Akaiko1/file-tree-scanner/internal/report"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/snapshot"
+)
+
+// handleSnapshots browses the snapshots in the configured snapshot folder, asking for the folder
+// the first time.
+func (app *FileTreeApp) handleSnapshots() {
+	if app.settings.SnapshotDir == "" {
+		app.chooseSnapshotDir()
+		return
+	}
+	app.showSnapshots()
+}
+
+// chooseSnapshotDir asks for the folder snapshots are kept in and browses it.
+func (app *FileTreeApp) chooseSnapshotDir() {
+	dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+		if err != nil {
+			app.showError("Folder Selection Error", err)
+			return
+		}
+		if folder == nil {
+			return // User cancelled
+		}
+		app.settings.SnapshotDir = folder.Path()
+		app.applySettings()
+		app.showSnapshots()
+	}, app.window)
+}
+
+// showSnapshots lists the snapshots in the snapshot folder, newest first, to open one or compare
+// two. Snapshots are only read: the scheduled CLI writes and deletes them.
+func (app *FileTreeApp) showSnapshots() {
+	dir := app.settings.SnapshotDir
+	snapshots, err := snapshot.List(dir)
+	if err != nil {
+		app.showError("Snapshots", err)
+		return
+	}
+	slices.Reverse(snapshots)
+
+	f := app.formatter()
+	labels := make([]string, len(snapshots))
+	for i, s := range snapshots {
+		labels[i] = fmt.Sprintf("%s  (%s)", f.Date(s.Time.Local()), f.Size(s.Size))
+	}
+
+	var snapshotsDialog dialog.Dialog
+	changeFolder := widget.NewButton("Change Folder…", func() {
+		snapshotsDialog.Hide()
+		app.chooseSnapshotDir()
+	})
+	header := container.NewBorder(nil, nil, nil, changeFolder, widget.NewLabel(dir))
+
+	var body fyne.CanvasObject
+	if len(snapshots) == 0 {
+		empty := widget.NewLabel("No snapshots here yet. Take them with:\nfile-tree-scanner --schedule @daily --snapshot-dir \"" + dir + "\" <folder>")
+		empty.Wrapping = fyne.TextWrapWord
+		body = empty
+	} else {
+		selected := 0
+		list := widget.NewList(
+			func() int { return len(labels) },
+			func() fyne.CanvasObject { return widget.NewLabel("") },
+			func(id widget.ListItemID, obj fyne.CanvasObject) { obj.(*widget.Label).SetText(labels[id]) },
+		)
+		list.OnSelected = func(id widget.ListItemID) { selected = id }
+		list.Select(0)
+		open := widget.NewButton("Open", func() {
+			if app.openSnapshot(snapshots[selected]) {
+				snapshotsDialog.Hide()
+			}
+		})
+
+		older := widget.NewSelect(labels, nil)
+		newer := widget.NewSelect(labels, nil)
+		newer.SetSelectedIndex(0)
+		older.SetSelectedIndex(min(1, len(labels)-1))
+		compare := widget.NewButton("Compare", func() {
+			a, b := older.SelectedIndex(), newer.SelectedIndex()
+			if a < 0 || b < 0 || a == b {
+				dialog.ShowInformation("Snapshots", "Choose two different snapshots to compare.", app.window)
+				return
+			}
+			if a < b {
+				a, b = b, a // The list is newest first
+			}
+			app.compareSnapshots(snapshots[a], snapshots[b])
+		})
+		compareRow := container.NewHBox(widget.NewLabel("Compare"), older, widget.NewLabel("with"), newer, compare)
+		body = container.NewBorder(nil, container.NewVBox(open, compareRow), nil, nil, list)
+	}
+
+	snapshotsDialog = dialog.NewCustom("Snapshots", "Close", container.NewBorder(header, nil, nil, nil, body), app.window)
+	snapshotsDialog.Resize(fyne.NewSize(windowWidth*0.7, windowHeight*0.7))
+	snapshotsDialog.Show()
+}
+
+// openSnapshot shows a snapshot like an opened tree file, reporting whether it could be read.
+func (app *FileTreeApp) openSnapshot(s snapshot.Snapshot) bool {
+	result, err := snapshot.Load(s.Path)
+	if err != nil {
+		app.showError("Open Error", err)
+		return false
+	}
+	app.showSnapshot(result)
+	return true
+}
+
+// showSnapshot shows a reopened snapshot as a scan result, noting in the status bar when it
+// was taken.
+func (app *FileTreeApp) showSnapshot(result *scanner.ScanResult) {
+	app.showImportedResult(result, "snapshot")
+	message := "Snapshot of " + result.RootPath
+	if !result.ScannedAt.IsZero() {
+		message += " from " + app.formatter().Date(result.ScannedAt.Local())
+	}
+	app.status.setMessage(message)
+}
+
+// handleSaveSnapshot saves the scanned tree with everything known about its entries and the
+// options it was scanned with, to open later with File → Open Snapshot…, here or elsewhere.
+// Entries excluded from the view are kept.
+func (app *FileTreeApp) handleSaveSnapshot() {
+	result := app.baseResult
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+	doc := report.NewTreeDocument(result)
+	doc.Options = app.config.Clone()
+
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			app.showError("Save Error", err)
+			return
+		}
+		if writer == nil {
+			return // User cancelled
+		}
+		defer writer.Close()
+
+		if werr := report.WriteTree(writer, doc); werr != nil {
+			app.showError("Save Error", werr)
+			return
+		}
+		app.recordExport(writer.URI().Path())
+		app.status.setMessage("Saved snapshot to " + writer.URI().Path())
+	}, app.window)
+	saveDialog.SetFileName(filepath.Base(result.RootPath) + "-snapshot-" + time.Now().Format(timeFormat) + ".json")
+	saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+	saveDialog.Show()
+}
+
+// compareSnapshots lists what changed from the older snapshot to the newer one.
+func (app *FileTreeApp) compareSnapshots(older, newer snapshot.Snapshot) {
+	before, err := snapshot.Load(older.Path)
+	if err != nil {
+		app.showError("Compare Snapshots", err)
+		return
+	}
+	after, err := snapshot.Load(newer.Path)
+	if err != nil {
+		app.showError("Compare Snapshots", err)
+		return
+	}
+
+	f := app.formatter()
+	changes := snapshot.Diff(before.Root, after.Root, before.HasSizes && after.HasSizes)
+	title := fmt.Sprintf("From %s to %s", f.Date(older.Time.Local()), f.Date(newer.Time.Local()))
+	output := widget.NewLabel(title + "\n\n" + formatSnapshotDiff(changes, f))
+	output.TextStyle.Monospace = true
+	output.Wrapping = fyne.TextWrapOff
+
+	diffDialog := dialog.NewCustom("Compare Snapshots", "Close", container.NewScroll(output), app.window)
+	diffDialog.Resize(fyne.NewSize(windowWidth*0.8, windowHeight*0.6))
+	diffDialog.Show()
+}
+
+// formatSnapshotDiff lists changes between snapshots one per line, with the entries below added
+// or removed folders and the sizes of resized files.
+func formatSnapshotDiff(changes []snapshot.Change, f *locale.Formatter) string {
+	if len(changes) == 0 {
+		return "No changes."
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s changes:\n", f.Int(len(changes))))
+	for _, change := range changes {
+		builder.WriteString(string(change.Kind) + ": " + change.Path)
+		switch {
+		case change.Kind == snapshot.Resized:
+			builder.WriteString(" (" + f.Size(change.OldSize) + " → " + f.Size(change.NewSize) + ")")
+		case change.Entries > 0:
+			builder.WriteString(" (" + f.Int(change.Entries) + " entries)")
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
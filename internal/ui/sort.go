@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// sortChoices are the sort keys offered in the toolbar, in order.
+var sortChoices = []struct{ label, key string }{
+	{"Name", config.SortByName},
+	{"Size", config.SortBySize},
+	{"Date modified", config.SortByModTime},
+	{"Extension", config.SortByExtension},
+}
+
+// createSortControls creates the toolbar dropdown and direction check that sort the shown tree
+// again without scanning it.
+func (app *FileTreeApp) createSortControls() fyne.CanvasObject {
+	labels := make([]string, len(sortChoices))
+	for i, choice := range sortChoices {
+		labels[i] = choice.label
+	}
+	// Callbacks are attached after the initial state so creating the controls does not re-sort
+	sortBy := widget.NewSelect(labels, nil)
+	sortBy.SetSelected(labels[0])
+	for _, choice := range sortChoices {
+		if choice.key == app.settings.SortBy {
+			sortBy.SetSelected(choice.label)
+		}
+	}
+	sortBy.OnChanged = func(selected string) {
+		for _, choice := range sortChoices {
+			if choice.label == selected {
+				app.settings.SortBy = choice.key
+			}
+		}
+		app.applySettings() // Sorts the shown tree again through configChanged
+	}
+
+	descending := widget.NewCheck("Descending", nil)
+	descending.SetChecked(app.settings.SortDescending)
+	descending.OnChanged = func(checked bool) {
+		app.settings.SortDescending = checked
+		app.applySettings()
+	}
+
+	return container.NewHBox(widget.NewLabel("Sort by"), sortBy, descending)
+}
+
+// resort sorts the shown result again in the configured order and redraws it, keeping view
+// exclusions. Sizes and dates sort only when the scan collected them.
+func (app *FileTreeApp) resort() {
+	result := app.baseResult
+	if result == nil || result.Root == nil {
+		return
+	}
+	scanner.SortTreeBy(result.Root, scanner.OrderOf(app.config))
+	if len(app.viewExclusions) == 0 {
+		app.renderText(result) // With exclusions, the view is rendered as it is rebuilt
+	}
+	app.applyViewExclusions()
+
+	switch {
+	case app.config.SortBy == config.SortBySize && !result.HasSizes:
+		app.status.setMessage("Sizes were not collected, so entries stay sorted by name; enable \"Collect file sizes\" and refresh")
+	case app.config.SortBy == config.SortByModTime && !result.HasTimes:
+		app.status.setMessage("Dates were not collected, so entries stay sorted by name; enable \"Collect modification dates\" and refresh")
+	}
+}
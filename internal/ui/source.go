@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// createSourceBanner creates the hidden banner shown when the scanned folder disappears.
+func (app *FileTreeApp) createSourceBanner() fyne.CanvasObject {
+	app.sourceLabel = widget.NewLabel("")
+	app.sourceLabel.Importance = widget.WarningImportance
+	app.sourceLabel.Wrapping = fyne.TextWrapWord
+
+	pickBtn := widget.NewButton("Pick new location…", app.handlePickNewLocation)
+	app.sourceBanner = container.NewBorder(nil, nil, nil, pickBtn, app.sourceLabel)
+	app.sourceBanner.Hide()
+	return app.sourceBanner
+}
+
+// checkSource verifies that the scanned root still exists, switching to the
+// "source missing" state if it does not. It returns true when the source is usable.
+func (app *FileTreeApp) checkSource() bool {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil || result.Root.IsVirtual {
+		app.setSourceMissing(false)
+		return result != nil && result.Root != nil && !result.Root.IsVirtual
+	}
+
+	info, err := os.Stat(result.RootPath)
+	missing := err != nil || !info.IsDir()
+	app.setSourceMissing(missing)
+	return !missing
+}
+
+// setSourceMissing toggles the banner and the actions that need the folder on disk.
+// The tree itself stays viewable, copyable and savable.
+func (app *FileTreeApp) setSourceMissing(missing bool) {
+	app.sourceMissing = missing
+	app.updateWarningBadge()
+	if app.sourceBanner == nil {
+		return
+	}
+
+	if missing {
+		app.sourceLabel.SetText(fmt.Sprintf("The scanned folder no longer exists: %s. The tree is kept, but refreshing is disabled until the folder is found.", app.getCurrentRootPath()))
+		app.sourceBanner.Show()
+	} else {
+		app.sourceBanner.Hide()
+	}
+	app.updateControls()
+}
+
+// importedTree reports whether the current tree was imported from a listing, archive or tree
+// file rather than scanned, so there is no folder on this machine to read. Joined folders have
+// a virtual root too, but each of them was scanned.
+func (app *FileTreeApp) importedTree() bool {
+	result := app.getCurrentResult()
+	return result != nil && result.Root != nil && result.Root.IsVirtual && len(result.Roots) == 0
+}
+
+// handleCheckSource re-checks the scanned folder on demand.
+func (app *FileTreeApp) handleCheckSource() {
+	if app.getCurrentResult() == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+	if app.checkSource() {
+		app.status.setMessage("Scanned folder is available: " + app.getCurrentRootPath())
+	} else {
+		app.status.setMessage("Scanned folder is missing: " + app.getCurrentRootPath())
+	}
+}
+
+// handlePickNewLocation lets the user point to the folder's new location and rebinds the tree to it.
+func (app *FileTreeApp) handlePickNewLocation() {
+	dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+		if err != nil {
+			app.showError("Folder Selection Error", err)
+			return
+		}
+		if folder == nil {
+			return // User cancelled
+		}
+
+		if rerr := app.rebindSource(folder.Path()); rerr != nil {
+			app.showError("Relocation Error", rerr)
+		}
+	}, app.window)
+}
+
+// rebindSource points the shown tree at dir, where its folder was moved, once dir is checked
+// to look like it.
+func (app *FileTreeApp) rebindSource(dir string) error {
+	result := app.baseResult
+	if result == nil || result.Root == nil {
+		return nil
+	}
+	if err := scanner.VerifyRelocation(result.Root, dir); err != nil {
+		return err
+	}
+
+	oldPath := result.RootPath
+	scanner.RebaseResult(result, dir)
+	annotate.Prepare(result.Root)
+	app.renderText(result)
+	app.applyViewExclusions()
+	app.setSourceMissing(false)
+	app.startWatching()
+	app.status.setMessage(fmt.Sprintf("Rebound %s to %s", oldPath, dir))
+	return nil
+}
+
+// handleRefresh rescans the current folder.
+func (app *FileTreeApp) handleRefresh() {
+	result := app.getCurrentResult()
+	if result == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+	if len(result.Roots) > 0 {
+		root := app.selectedRoot()
+		if root == "" {
+			dialog.ShowInformation("Refresh", "Select an entry of the folder to rescan.", app.window)
+			return
+		}
+		app.refreshRoot(root)
+		return
+	}
+	if result.Root != nil && result.Root.IsVirtual {
+		dialog.ShowInformation("Refresh", "Imported trees have no folder to rescan.", app.window)
+		return
+	}
+	if !app.checkSource() {
+		return
+	}
+	app.startScan(result.RootPath, scanOverrides{refresh: true})
+}
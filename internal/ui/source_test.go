@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/pkg/filetree"
+)
+
+// showScannedFolder scans a new folder holding a few files and shows the result in app,
+// returning the folder's path.
+func showScannedFolder(t *testing.T, app *FileTreeApp) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "project")
+	for _, name := range []string{"src/main.go", "README.md"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := scanner.NewFileTreeScanner(app.config).ScanDirectory(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.showResult(result)
+	return root
+}
+
+func TestSourceMissingAndRebind(t *testing.T) {
+	app := newTestApp(t)
+	root := showScannedFolder(t, app)
+	if !app.checkSource() || app.sourceBanner.Visible() || app.refreshBtn.Disabled() {
+		t.Fatal("an existing folder was reported missing")
+	}
+
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.Rename(root, moved); err != nil {
+		t.Fatal(err)
+	}
+	if app.checkSource() {
+		t.Fatal("a moved folder was reported available")
+	}
+	if !app.sourceMissing || !app.sourceBanner.Visible() || !app.refreshBtn.Disabled() {
+		t.Errorf("missing %v, banner shown %v, refresh disabled %v; want all set",
+			app.sourceMissing, app.sourceBanner.Visible(), app.refreshBtn.Disabled())
+	}
+	if !strings.Contains(app.sourceLabel.Text, root) {
+		t.Errorf("banner %q does not name the folder", app.sourceLabel.Text)
+	}
+	if app.getCurrentResult() == nil || app.getCurrentResult().Root == nil {
+		t.Fatal("the tree was dropped with its folder")
+	}
+
+	// A folder that does not look like the scanned one is refused
+	if err := app.rebindSource(t.TempDir()); err == nil {
+		t.Error("an unrelated folder was accepted")
+	}
+	if !app.sourceMissing || app.getCurrentRootPath() != root {
+		t.Errorf("a refused folder changed the state: missing %v, root %s", app.sourceMissing, app.getCurrentRootPath())
+	}
+
+	if err := app.rebindSource(moved); err != nil {
+		t.Fatal(err)
+	}
+	result := app.getCurrentResult()
+	if result.RootPath != moved || result.Root.Path != moved {
+		t.Errorf("root %s (%s), want %s", result.RootPath, result.Root.Path, moved)
+	}
+	if main := filepath.Join(moved, "src", "main.go"); findNode(result.Root, main) == nil {
+		t.Errorf("%s not in the rebound tree", main)
+	}
+	if app.sourceMissing || app.sourceBanner.Visible() || app.refreshBtn.Disabled() {
+		t.Error("the missing state outlived the rebind")
+	}
+	if !app.checkSource() {
+		t.Error("the rebound folder was reported missing")
+	}
+}
+
+func TestSourceOfImportedTree(t *testing.T) {
+	app := newTestApp(t)
+	app.showResult(&scanner.ScanResult{RootPath: "/nowhere", Root: &scanner.TreeNode{Name: "nowhere", Path: "/nowhere", IsDir: true, IsVirtual: true}})
+	if app.checkSource() {
+		t.Error("an imported tree was reported to have a folder")
+	}
+	if app.sourceMissing || app.sourceBanner.Visible() {
+		t.Error("an imported tree was reported missing its folder")
+	}
+}
+
+func TestDerivedTreesLeaveViewAlone(t *testing.T) {
+	app := newTestApp(t)
+	showScannedFolder(t, app)
+	root := app.currentResult.Root
+	treeData := fmt.Sprint(app.treeData)
+	treeText := app.currentResult.TreeText
+	names := fmt.Sprint(childNames(root))
+
+	derived := []*filetree.Node{
+		filetree.SortChildren(root, func(a, b *filetree.Node) bool { return a.Name < b.Name }),
+		filetree.Prune(root, func(node *filetree.Node) bool { return node.Name == "README.md" }),
+		filetree.Filter(root, func(node *filetree.Node) bool { return node.Name == "main.go" }),
+	}
+	for _, tree := range derived {
+		if tree == root {
+			t.Fatal("a changed tree was returned as the shown one")
+		}
+		tree.Name = "changed"
+		tree.Children = append(tree.Children[:0], &filetree.Node{Name: "added"})
+	}
+
+	if app.currentResult.Root != root || fmt.Sprint(childNames(root)) != names {
+		t.Errorf("shown tree changed: %v, want %v", childNames(app.currentResult.Root), names)
+	}
+	if got := fmt.Sprint(app.treeData); got != treeData {
+		t.Errorf("tree view changed:\n%s\nwant:\n%s", got, treeData)
+	}
+	if app.currentResult.TreeText != treeText {
+		t.Errorf("tree text changed:\n%s", app.currentResult.TreeText)
+	}
+}
+
+// childNames lists the names of node's children.
+func childNames(node *scanner.TreeNode) []string {
+	var names []string
+	for _, child := range node.Children {
+		names = append(names, child.Name)
+	}
+	return names
+}
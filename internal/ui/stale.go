@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// staleInterval is how often the scanned folder is checked for changes.
+const staleInterval = 30 * time.Second
+
+// staleCheck is a snapshot of what the poller needs, taken on the UI thread.
+type staleCheck struct {
+	result    *scanner.ScanResult
+	root      string
+	scannedAt time.Time
+}
+
+// createStaleBanner creates the hidden banner shown when the scanned folder changed after the scan.
+func (app *FileTreeApp) createStaleBanner() fyne.CanvasObject {
+	label := widget.NewLabel("Contents may have changed since the scan.")
+	refreshBtn := widget.NewButton("Refresh", app.handleRefresh)
+	app.staleBanner = container.NewBorder(nil, nil, nil, refreshBtn, label)
+	app.staleBanner.Hide()
+	return app.staleBanner
+}
+
+// foreground tracks whether the application is in the foreground, for the pollers of all windows.
+var (
+	foreground      atomic.Bool
+	trackForeground sync.Once
+)
+
+// startStalePolling checks the scanned folder every staleInterval while the application is in
+// the foreground, until closed is closed. Each check stats the root and its immediate
+// children off the UI thread.
+func (app *FileTreeApp) startStalePolling(closed <-chan struct{}) {
+	trackForeground.Do(func() {
+		foreground.Store(true)
+		app.app.Lifecycle().SetOnEnteredForeground(func() { foreground.Store(true) })
+		app.app.Lifecycle().SetOnExitedForeground(func() { foreground.Store(false) })
+	})
+
+	go func() {
+		ticker := time.NewTicker(staleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closed:
+				return
+			case <-ticker.C:
+			}
+			if !foreground.Load() {
+				continue
+			}
+
+			var check *staleCheck
+			fyne.DoAndWait(func() { check = app.staleCheck() })
+			if check == nil || !changedSince(check.root, check.scannedAt) {
+				continue
+			}
+
+			fyne.Do(func() {
+				// The result may have been replaced while the folder was being checked
+				if app.currentResult == check.result {
+					app.staleBanner.Show()
+				}
+			})
+		}
+	}()
+}
+
+// staleCheck returns what to check for the current result, or nil when there is nothing to poll:
+// no result, an imported tree, a missing source, a result already marked stale, or one kept up
+// to date by auto-refresh.
+func (app *FileTreeApp) staleCheck() *staleCheck {
+	result := app.currentResult
+	if result == nil || result.Root == nil || result.Root.IsVirtual || result.ScannedAt.IsZero() {
+		return nil
+	}
+	if app.sourceMissing || app.watchCancel != nil || app.staleBanner == nil || app.staleBanner.Visible() {
+		return nil
+	}
+	return &staleCheck{result: result, root: result.RootPath, scannedAt: result.ScannedAt}
+}
+
+// changedSince reports whether root or one of its immediate children was modified after t.
+func changedSince(root string, t time.Time) bool {
+	info, err := os.Stat(root)
+	if err != nil {
+		return false // A missing folder is reported by the source check instead
+	}
+	if info.ModTime().After(t) {
+		return true
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if info, err := os.Lstat(filepath.Join(root, entry.Name())); err == nil && info.ModTime().After(t) {
+			return true
+		}
+	}
+	return false
+}
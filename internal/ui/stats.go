@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+	"github.com/Akaiko1/file-tree-scanner/internal/volume"
+)
+
+// treeTotals aggregates counts and sizes over a tree.
+type treeTotals struct {
+	dirs, files      int
+	apparent, onDisk int64
+}
+
+// sumTree walks node and accumulates its totals.
+func sumTree(node *scanner.TreeNode, totals *treeTotals) {
+	if node.IsDir {
+		totals.dirs++
+	} else {
+		totals.files++
+		totals.apparent += node.Size
+		totals.onDisk += node.DiskSize
+	}
+	for _, child := range node.Children {
+		sumTree(child, totals)
+	}
+}
+
+// handleStatistics shows counts and size totals for the current result.
+func (app *FileTreeApp) handleStatistics() {
+	result := app.getCurrentResult()
+	if result == nil || result.Root == nil {
+		dialog.ShowInformation("No Data", msgNoData, app.window)
+		return
+	}
+
+	var totals treeTotals
+	sumTree(result.Root, &totals)
+
+	f := app.formatter()
+	text := fmt.Sprintf("Root: %s\nItems: %s\nDirectories: %s\nFiles: %s\n",
+		result.RootPath, f.Int(result.NodeCount), f.Int(totals.dirs), f.Int(totals.files))
+	rules := make([]string, 0, len(result.Skipped))
+	for rule := range result.Skipped {
+		if rule != scanner.AlwaysShownRule { // Kept entries, listed on their own
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) > 0 {
+		sort.Strings(rules)
+		text += "Skipped:"
+		for _, rule := range rules {
+			text += fmt.Sprintf(" %s %s;", rule, f.Int(result.Skipped[rule]))
+		}
+		text = strings.TrimSuffix(text, ";") + "\n"
+	}
+	if shown := result.Skipped[scanner.AlwaysShownRule]; shown > 0 {
+		text += fmt.Sprintf("Hidden but always shown: %s\n", f.Int(shown))
+	}
+	if len(result.Languages) > 0 {
+		text += fmt.Sprintf("Languages (by %s): %s\n", result.LanguagesBy, scanner.FormatLanguages(result.Languages))
+	}
+	if !result.ScannedAt.IsZero() {
+		text += fmt.Sprintf("Scanned: %s\n", f.Date(result.ScannedAt))
+	}
+	if result.Background {
+		text += "Priority: background (durations are not comparable with normal scans)\n"
+	}
+	if result.BackgroundKept {
+		text += "Priority: stayed at background after it was turned off; the system only lets administrators raise it again\n"
+	}
+	if app.config.ShowSize {
+		basis := "apparent size"
+		if app.config.SizeBasis == config.SizeAllocated {
+			basis = "size on disk"
+		}
+		text += fmt.Sprintf("Apparent size: %s\nSize on disk: %s\nViews use: %s\n",
+			f.Size(totals.apparent), f.Size(totals.onDisk), basis)
+	} else {
+		text += "Sizes: not collected (enable \"Collect file sizes\" in Settings)\n"
+	}
+	if fp, ok := app.currentFingerprint(); ok {
+		text += fmt.Sprintf("Fingerprint: %s\n", fp)
+	}
+	if info, err := volume.Stat(result.RootPath); err == nil {
+		text += app.volumeSummary(info) + "\n"
+	}
+
+	label := widget.NewLabel(text)
+	dialog.ShowCustom("Statistics", "Close", label, app.window)
+}
@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Badge names for the right-hand status region, drawn in this order.
+const (
+	badgeWarning = "warning"
+	badgeWatch   = "watch"
+	badgeProfile = "profile"
+)
+
+// badgeOrder lists the badges with a fixed slot.
+var badgeOrder = []string{badgeWarning, badgeWatch, badgeProfile}
+
+// statusBar is the bottom bar of the main window. Its regions update independently:
+// the last message on the left, the result summary in the center and badges on the right.
+type statusBar struct {
+	message *statusRegion
+	summary *statusRegion
+	badges  *statusRegion
+
+	badgeText map[string]string
+}
+
+// newStatusBar creates a status bar showing message.
+func newStatusBar(message string) *statusBar {
+	bar := &statusBar{
+		message:   newStatusRegion(fyne.TextAlignLeading),
+		summary:   newStatusRegion(fyne.TextAlignCenter),
+		badges:    newStatusRegion(fyne.TextAlignTrailing),
+		badgeText: make(map[string]string),
+	}
+	bar.message.SetText(message)
+	return bar
+}
+
+// content returns the bar's canvas object.
+func (b *statusBar) content() fyne.CanvasObject {
+	return container.NewGridWithColumns(3, b.message, b.summary, b.badges)
+}
+
+// setMessage replaces the left region with the current operation or last event.
+func (b *statusBar) setMessage(text string) {
+	b.message.SetText(text)
+}
+
+// setSummary replaces the center region; an empty text clears it.
+func (b *statusBar) setSummary(text string) {
+	b.summary.SetText(text)
+}
+
+// setBadge shows text as the named badge in the right region; an empty text removes it.
+func (b *statusBar) setBadge(name, text string) {
+	if text == "" {
+		delete(b.badgeText, name)
+	} else {
+		b.badgeText[name] = text
+	}
+
+	var parts []string
+	for _, name := range badgeOrder {
+		if text, ok := b.badgeText[name]; ok {
+			parts = append(parts, text)
+		}
+	}
+	// Badges without a fixed slot follow in name order
+	var extra []string
+	for name := range b.badgeText {
+		if !containsString(badgeOrder, name) {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		parts = append(parts, b.badgeText[name])
+	}
+	b.badges.SetText(strings.Join(parts, "  "))
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// showResultStatus fills the summary and warning badge from result.
+func (app *FileTreeApp) showResultStatus(result *scanner.ScanResult) {
+	if result == nil || result.Root == nil {
+		app.status.setSummary("")
+		app.status.setBadge(badgeWarning, "")
+		return
+	}
+
+	var totals treeTotals
+	sumTree(result.Root, &totals)
+	f := app.formatter()
+	summary := fmt.Sprintf("%s dirs, %s files", f.Int(totals.dirs), f.Int(totals.files))
+	if ignored := result.Skipped[scanner.GitignoreRule] + result.Skipped[scanner.TreeIgnoreRule]; ignored > 0 {
+		summary += fmt.Sprintf(" (%s ignored)", f.Int(ignored))
+	}
+	if app.config.ShowSize && !result.Root.IsVirtual {
+		size := totals.apparent
+		if app.config.SizeBasis == config.SizeAllocated {
+			size = totals.onDisk
+		}
+		summary += ", " + f.Size(size)
+	}
+	if result.HasLines {
+		summary += fmt.Sprintf(", %s lines of text", f.Int(result.TotalLines))
+	}
+	if result.HasTokens {
+		summary += ", " + renderer.TokensLabel(result.TotalTokens, f)
+	}
+	if files, size := result.DuplicateWaste(); files > 0 {
+		summary += fmt.Sprintf(", %s duplicate files", f.Int(files))
+		if result.HasSizes {
+			summary += " wasting " + f.Size(size)
+		}
+	}
+	if result.Latest != nil {
+		summary += ", last change: " + f.Age(time.Since(result.Latest.ModTime))
+	}
+	if skipped := result.Skipped[scanner.SystemPathRule]; skipped == 1 {
+		summary += ", 1 system path skipped"
+	} else if skipped > 1 {
+		summary += fmt.Sprintf(", %s system paths skipped", f.Int(skipped))
+	}
+	if cut := result.TruncatedCount; cut == 1 {
+		summary += ", 1 folder not shown in full"
+	} else if cut > 1 {
+		summary += fmt.Sprintf(", %s folders not shown in full", f.Int(cut))
+	}
+	if unreadable := result.UnreadableDirs(); unreadable == 1 {
+		summary += ", 1 directory could not be read"
+	} else if unreadable > 1 {
+		summary += fmt.Sprintf(", %s directories could not be read", f.Int(unreadable))
+	}
+	if result.BackgroundKept {
+		summary += ", background priority could not be turned off"
+	}
+	if bad := treeIgnoreProblems(result); bad > 0 {
+		summary += fmt.Sprintf(", %s lines of %s skipped", f.Int(bad), scanner.TreeIgnoreFile)
+	}
+	app.status.setSummary(summary)
+	app.updateWarningBadge()
+}
+
+// treeIgnoreProblems returns the number of problems reading the .treeignore file of result.
+func treeIgnoreProblems(result *scanner.ScanResult) int {
+	count := 0
+	for _, e := range result.Errors {
+		if e.Op == scanner.ScanOpParse {
+			count++
+		}
+	}
+	return count
+}
+
+// updateWarningBadge shows the most important problem with the current result, if any.
+func (app *FileTreeApp) updateWarningBadge() {
+	result := app.getCurrentResult()
+	switch {
+	case app.sourceMissing:
+		app.status.setBadge(badgeWarning, "⚠ Source missing")
+	case result != nil && result.Truncated:
+		app.status.setBadge(badgeWarning, "⚠ Partial result ("+string(result.TruncatedReason)+")")
+	case result != nil && result.TruncatedCount > 0:
+		app.status.setBadge(badgeWarning, "⚠ Some folders cut short")
+	case result != nil && treeIgnoreProblems(result) > 0:
+		app.status.setBadge(badgeWarning, "⚠ Check "+scanner.TreeIgnoreFile)
+	default:
+		app.status.setBadge(badgeWarning, "")
+	}
+}
+
+// statusRegion is a single-line label that truncates with an ellipsis and shows
+// its full text in a pop-up while hovered, so narrow windows lose nothing.
+type statusRegion struct {
+	widget.Label
+
+	popUp *widget.PopUp
+}
+
+// newStatusRegion creates an empty region with the given alignment.
+func newStatusRegion(align fyne.TextAlign) *statusRegion {
+	region := &statusRegion{}
+	region.Alignment = align
+	region.Truncation = fyne.TextTruncateEllipsis
+	region.ExtendBaseWidget(region)
+	return region
+}
+
+// truncated reports whether the text does not fit the region's width.
+func (r *statusRegion) truncated() bool {
+	full := widget.NewLabel(r.Text)
+	return full.MinSize().Width > r.Size().Width
+}
+
+// MouseIn shows the full text when it is truncated.
+func (r *statusRegion) MouseIn(*desktop.MouseEvent) {
+	if r.Text == "" || !r.truncated() {
+		return
+	}
+	canvas := fyne.CurrentApp().Driver().CanvasForObject(r)
+	if canvas == nil {
+		return
+	}
+	r.popUp = widget.NewPopUp(widget.NewLabel(r.Text), canvas)
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(r)
+	pos.Y -= r.popUp.MinSize().Height
+	r.popUp.ShowAtPosition(pos)
+}
+
+// MouseMoved implements desktop.Hoverable.
+func (r *statusRegion) MouseMoved(*desktop.MouseEvent) {}
+
+// MouseOut hides the full text.
+func (r *statusRegion) MouseOut() {
+	if r.popUp != nil {
+		r.popUp.Hide()
+		r.popUp = nil
+	}
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+)
+
+// highContrastTheme is the default theme with text, borders and marks at full contrast against
+// the background, for Settings → High contrast.
+type highContrastTheme struct {
+	fyne.Theme
+}
+
+// Colors of highContrastTheme; the rest come from the default theme.
+var (
+	contrastLight = map[fyne.ThemeColorName]color.Color{
+		theme.ColorNameBackground:        color.White,
+		theme.ColorNameForeground:        color.Black,
+		theme.ColorNameDisabled:          color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xff},
+		theme.ColorNamePlaceHolder:       color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xff},
+		theme.ColorNameSeparator:         color.Black,
+		theme.ColorNameInputBorder:       color.Black,
+		theme.ColorNamePrimary:           color.NRGBA{R: 0x00, G: 0x3c, B: 0xb3, A: 0xff},
+		theme.ColorNameError:             color.NRGBA{R: 0xb0, G: 0x00, B: 0x00, A: 0xff},
+		theme.ColorNameWarning:           color.NRGBA{R: 0x80, G: 0x4a, B: 0x00, A: 0xff},
+		theme.ColorNameSuccess:           color.NRGBA{R: 0x00, G: 0x60, B: 0x00, A: 0xff},
+		theme.ColorNameScrollBar:         color.Black,
+		theme.ColorNameForegroundOnError: color.White,
+	}
+	contrastDark = map[fyne.ThemeColorName]color.Color{
+		theme.ColorNameBackground:        color.Black,
+		theme.ColorNameForeground:        color.White,
+		theme.ColorNameDisabled:          color.NRGBA{R: 0xd0, G: 0xd0, B: 0xd0, A: 0xff},
+		theme.ColorNamePlaceHolder:       color.NRGBA{R: 0xd0, G: 0xd0, B: 0xd0, A: 0xff},
+		theme.ColorNameSeparator:         color.White,
+		theme.ColorNameInputBorder:       color.White,
+		theme.ColorNamePrimary:           color.NRGBA{R: 0x7a, G: 0xb8, B: 0xff, A: 0xff},
+		theme.ColorNameError:             color.NRGBA{R: 0xff, G: 0x70, B: 0x70, A: 0xff},
+		theme.ColorNameWarning:           color.NRGBA{R: 0xff, G: 0xc8, B: 0x40, A: 0xff},
+		theme.ColorNameSuccess:           color.NRGBA{R: 0x70, G: 0xe0, B: 0x70, A: 0xff},
+		theme.ColorNameScrollBar:         color.White,
+		theme.ColorNameForegroundOnError: color.Black,
+	}
+)
+
+// Color returns the high-contrast color for name, or the default theme's.
+func (t highContrastTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	colors := contrastLight
+	if variant == theme.VariantDark {
+		colors = contrastDark
+	}
+	if c, ok := colors[name]; ok {
+		return c
+	}
+	return t.Theme.Color(name, variant)
+}
+
+// applyTheme puts the theme chosen in Settings into effect. The theme belongs to the whole
+// application, so every window follows the one that applied it last.
+func (app *FileTreeApp) applyTheme() {
+	if app.settings.HighContrast {
+		app.app.Settings().SetTheme(highContrastTheme{theme.DefaultTheme()})
+		return
+	}
+	app.app.Settings().SetTheme(theme.DefaultTheme())
+}
+
+// annotationStyle returns how the tree and output mark entries, from Settings.
+func (app *FileTreeApp) annotationStyle() renderer.AnnotationStyle {
+	return renderer.AnnotationStyle{Symbols: app.settings.Symbols}
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+func TestTreeDataSwappedWhole(t *testing.T) {
+	app := newTestApp(t)
+	root := showScannedFolder(t, app)
+	app.window.Resize(fyne.NewSize(800, 600))
+	test.WidgetRenderer(app.tree)
+	src := filepath.Join(root, "src")
+	app.tree.OpenBranch(root)
+	app.tree.OpenBranch(src)
+
+	// Every read of the data source must find the data of exactly the shown result
+	var reads int
+	check := func(uid string) {
+		reads++
+		result := app.currentResult
+		if result == nil {
+			return
+		}
+		if want := countTree(result.Root); len(app.treeData) != want || len(app.treeDepth) != want {
+			t.Errorf("read of %q saw %d of %d nodes", uid, len(app.treeData), want)
+		}
+	}
+	app.tree.ChildUIDs = func(uid string) []string {
+		check(uid)
+		return app.childUIDs(uid)
+	}
+	app.tree.IsBranch = func(uid string) bool {
+		check(uid)
+		return app.isBranch(uid)
+	}
+
+	for i, name := range []string{"new.go", "more.go"} {
+		if err := os.WriteFile(filepath.Join(src, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		result, err := scanner.NewFileTreeScanner(app.config).ScanDirectory(context.Background(), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reads = 0
+		app.updateTreeDataSimple(result)
+		if reads == 0 {
+			t.Fatalf("refresh %d: the tree did not read the new data", i+1)
+		}
+		if got := len(app.treeData[src]); got != i+2 {
+			t.Errorf("refresh %d: src has %d children, want %d", i+1, got, i+2)
+		}
+		// Open branches survive a refresh that keeps their paths
+		if !app.tree.IsBranchOpen(root) || !app.tree.IsBranchOpen(src) {
+			t.Errorf("refresh %d closed the open branches", i+1)
+		}
+	}
+}
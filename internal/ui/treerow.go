@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// rowShadeAlpha is the opacity of the foreground color used to shade alternate rows.
+const rowShadeAlpha = 0x14
+
+// rowFlagAlpha is the opacity of the error color behind flagged rows.
+const rowFlagAlpha = 0x40
+
+// treeRow is the tree item widget: a label with optional row shading and indentation guides.
+// The guides and shading extend to the left of the row's own bounds, over the indentation
+// the Fyne tree reserves for ancestors, so their positions mirror widget.Tree's layout.
+type treeRow struct {
+	widget.BaseWidget
+
+	label      *widget.Label
+	background *canvas.Rectangle
+	guides     []*canvas.Line
+
+	depth      int
+	shaded     bool
+	flagged    bool // Tinted with the error color, e.g. for a layout violation
+	showGuides bool
+	compact    bool // Trim the label's vertical padding, down to minRowHeight
+}
+
+// newTreeRow creates a tree row showing text.
+func newTreeRow(text string) *treeRow {
+	row := &treeRow{
+		label:      widget.NewLabel(text),
+		background: canvas.NewRectangle(color.Transparent),
+	}
+	row.ExtendBaseWidget(row)
+	return row
+}
+
+// update sets the row's text and decoration state and refreshes it.
+func (r *treeRow) update(text string, depth int, shaded, flagged, showGuides bool) {
+	r.depth = depth
+	r.shaded = shaded
+	r.flagged = flagged
+	r.showGuides = showGuides
+	r.label.SetText(text)
+	r.Refresh()
+}
+
+// setStyle sets the row density and face; muted rows, standing in for what a scan left out,
+// are gray and italic. Rows are reused, so this runs on every update and only refreshes when
+// something changed.
+func (r *treeRow) setStyle(compact, monospace, muted bool) {
+	importance := widget.MediumImportance
+	if muted {
+		importance = widget.LowImportance
+	}
+	if r.compact == compact && r.label.TextStyle.Monospace == monospace && r.label.TextStyle.Italic == muted {
+		return
+	}
+	r.compact = compact
+	r.label.TextStyle.Monospace = monospace
+	r.label.TextStyle.Italic = muted
+	r.label.Importance = importance
+	r.label.Refresh()
+	r.Refresh()
+}
+
+// minRowHeight is the shortest a compact row gets: an inline icon with padding, which keeps
+// rows comfortably clickable and touchable.
+func minRowHeight(th fyne.Theme) float32 {
+	return th.Size(theme.SizeNameInlineIcon) + 2*th.Size(theme.SizeNamePadding)
+}
+
+// CreateRenderer implements fyne.Widget.
+func (r *treeRow) CreateRenderer() fyne.WidgetRenderer {
+	return &treeRowRenderer{row: r}
+}
+
+// treeRowRenderer lays out the row's background, guides and label.
+type treeRowRenderer struct {
+	row     *treeRow
+	objects []fyne.CanvasObject
+}
+
+// contentOffset returns the x position of the row's content within the tree item.
+func (r *treeRowRenderer) contentOffset(th fyne.Theme) float32 {
+	pad := th.Size(theme.SizeNamePadding)
+	icon := th.Size(theme.SizeNameInlineIcon)
+	return pad + float32(r.row.depth)*(icon+pad) + icon + pad
+}
+
+// Layout positions the decorations relative to the tree indentation.
+func (r *treeRowRenderer) Layout(size fyne.Size) {
+	th := r.row.Theme()
+	pad := th.Size(theme.SizeNamePadding)
+	icon := th.Size(theme.SizeNameInlineIcon)
+	offset := r.contentOffset(th)
+
+	r.row.background.Move(fyne.NewPos(-offset, 0))
+	r.row.background.Resize(fyne.NewSize(size.Width+offset, size.Height))
+
+	for level, line := range r.row.guides {
+		x := pad + float32(level)*(icon+pad) + icon/2 - offset
+		line.Position1 = fyne.NewPos(x, 0)
+		line.Position2 = fyne.NewPos(x, size.Height)
+	}
+
+	// A compact row is shorter than its label; centering keeps the text clear of the trimmed padding
+	labelHeight := r.row.label.MinSize().Height
+	r.row.label.Move(fyne.NewPos(0, (size.Height-labelHeight)/2))
+	r.row.label.Resize(fyne.NewSize(size.Width, labelHeight))
+}
+
+// MinSize returns the label's minimum size, less its vertical padding when compact.
+func (r *treeRowRenderer) MinSize() fyne.Size {
+	size := r.row.label.MinSize()
+	if r.row.compact {
+		th := r.row.Theme()
+		size.Height = max(size.Height-th.Size(theme.SizeNameInnerPadding), minRowHeight(th))
+	}
+	return size
+}
+
+// Refresh updates colors and the number of guide lines.
+func (r *treeRowRenderer) Refresh() {
+	r.rebuild()
+	canvas.Refresh(r.row)
+}
+
+// rebuild recomputes decoration colors and the object list.
+func (r *treeRowRenderer) rebuild() {
+	th := r.row.Theme()
+	variant := fyne.CurrentApp().Settings().ThemeVariant()
+
+	r.row.background.FillColor = color.Transparent
+	if r.row.shaded {
+		fg := color.NRGBAModel.Convert(th.Color(theme.ColorNameForeground, variant)).(color.NRGBA)
+		fg.A = rowShadeAlpha
+		r.row.background.FillColor = fg
+	}
+	if r.row.flagged {
+		flag := color.NRGBAModel.Convert(th.Color(theme.ColorNameError, variant)).(color.NRGBA)
+		flag.A = rowFlagAlpha
+		r.row.background.FillColor = flag
+	}
+
+	guideCount := 0
+	if r.row.showGuides {
+		guideCount = r.row.depth
+	}
+	for len(r.row.guides) < guideCount {
+		r.row.guides = append(r.row.guides, canvas.NewLine(color.Transparent))
+	}
+	r.row.guides = r.row.guides[:guideCount]
+
+	guideColor := th.Color(theme.ColorNameSeparator, variant)
+	for _, line := range r.row.guides {
+		line.StrokeColor = guideColor
+		line.StrokeWidth = 1
+	}
+
+	objects := make([]fyne.CanvasObject, 0, len(r.row.guides)+2)
+	objects = append(objects, r.row.background)
+	for _, line := range r.row.guides {
+		objects = append(objects, line)
+	}
+	r.objects = append(objects, r.row.label)
+
+	r.Layout(r.row.Size())
+}
+
+// Objects returns the row's canvas objects, background first.
+func (r *treeRowRenderer) Objects() []fyne.CanvasObject {
+	if r.objects == nil {
+		r.rebuild()
+	}
+	return r.objects
+}
+
+// Destroy implements fyne.WidgetRenderer.
+func (r *treeRowRenderer) Destroy() {}
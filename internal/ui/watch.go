@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// watchInterval is the least time between two updates of a watched tree, so a storm of
+// changes, like an npm install, redraws the window once a second rather than for each file.
+const watchInterval = time.Second
+
+// maxRowRefreshes is how many rows are redrawn one by one before the whole tree is redrawn instead.
+const maxRowRefreshes = 50
+
+// createAutoRefreshToggle creates the toolbar check that keeps the shown tree up to date as
+// the scanned folder changes.
+func (app *FileTreeApp) createAutoRefreshToggle() fyne.CanvasObject {
+	autoRefresh := widget.NewCheck("Auto-refresh", nil)
+	if _, ok := app.scanner.(scanner.WatchingScanner); !ok {
+		autoRefresh.Hide()
+	}
+	// The callback is attached after the initial state so creating the check starts no watch
+	autoRefresh.SetChecked(app.settings.AutoRefresh)
+	autoRefresh.OnChanged = func(checked bool) {
+		app.settings.AutoRefresh = checked
+		app.settings.save(app.app.Preferences())
+		if !checked {
+			app.stopWatching()
+			return
+		}
+		if app.startWatching() {
+			app.status.setMessage("Watching " + app.baseResult.RootPath + " for changes")
+		}
+	}
+	return autoRefresh
+}
+
+// startWatching watches the folder of the shown result when auto-refresh is on, replacing any
+// earlier watch, and reports whether it does. Imported trees and results of several folders
+// are not watched.
+func (app *FileTreeApp) startWatching() bool {
+	app.stopWatching()
+	result := app.baseResult
+	watcher, ok := app.scanner.(scanner.WatchingScanner)
+	if !ok || !app.settings.AutoRefresh || result == nil || result.Root == nil || result.Root.IsVirtual || result.ScannedAt.IsZero() {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := watcher.WatchDirectory(app.overridesContext(ctx, app.watchOverrides), result.RootPath)
+	if err != nil {
+		cancel()
+		log.Printf("Warning: auto-refresh is off: %v", err)
+		app.status.setMessage("Auto-refresh is not available: " + err.Error())
+		return false
+	}
+	app.watchCancel = cancel
+	app.status.setBadge(badgeWatch, "👁 Auto-refresh")
+	go app.batchChanges(changes, result)
+	return true
+}
+
+// stopWatching tears down the watch of the shown folder, if any.
+func (app *FileTreeApp) stopWatching() {
+	if app.watchCancel != nil {
+		app.watchCancel()
+		app.watchCancel = nil
+		app.status.setBadge(badgeWatch, "")
+	}
+}
+
+// batchChanges collects the changes of a watch on result until it ends, handing them to the UI
+// thread at most once every watchInterval. A change after a quiet spell is shown at once.
+func (app *FileTreeApp) batchChanges(changes <-chan scanner.TreeChange, result *scanner.ScanResult) {
+	var pending []scanner.TreeChange
+	var due <-chan time.Time
+	var last time.Time
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				return // Torn down
+			}
+			pending = append(pending, change)
+			if due == nil {
+				due = time.After(time.Until(last.Add(watchInterval)))
+			}
+		case <-due:
+			batch := pending
+			pending, due, last = nil, nil, time.Now()
+			fyne.Do(func() { app.applyChanges(result, batch) })
+		}
+	}
+}
+
+// applyChanges brings result, if still shown, in line with a batch of changes, updating the tree
+// data and rows of the affected branches only. Without view exclusions the view is result itself;
+// with them it is rebuilt from it.
+func (app *FileTreeApp) applyChanges(result *scanner.ScanResult, changes []scanner.TreeChange) {
+	watcher, ok := app.scanner.(scanner.WatchingScanner)
+	if !ok || app.watchCancel == nil || app.baseResult != result {
+		return // Torn down, or replaced by a newer result, while the batch waited
+	}
+
+	// Output rendered ahead reads the tree, which is about to change
+	app.prerender.stop()
+	changed := watcher.ApplyChanges(context.Background(), result, changes)
+	if len(changed) == 0 {
+		app.schedulePrerender()
+		return
+	}
+	if len(app.viewExclusions) > 0 {
+		app.applyViewExclusions()
+		return
+	}
+
+	app.renderText(result)
+	structural := false
+	for _, node := range changed {
+		if node.IsDir && app.patchBranch(node) && app.tree != nil && app.tree.IsBranchOpen(node.Path) {
+			structural = true
+		}
+	}
+	app.reindexRows()
+	app.showResultStatus(result)
+	app.schedulePrerender()
+	if app.preview != nil {
+		app.preview.SetText(result.TreeText)
+	}
+	if app.staleBanner != nil {
+		app.staleBanner.Hide()
+	}
+	app.refreshRows(changed, structural)
+}
+
+// patchBranch updates the tree data of dir, whose entries changed, reading again only the
+// entries that are new or whose own entries differ, and reports whether it is shown.
+func (app *FileTreeApp) patchBranch(dir *scanner.TreeNode) bool {
+	depth, shown := app.treeDepth[dir.Path]
+	if !shown {
+		return false
+	}
+	old := make(map[string]bool)
+	for _, uid := range app.treeData[dir.Path] {
+		old[uid] = true
+	}
+
+	var children []string
+	entries := append(dir.Children[:len(dir.Children):len(dir.Children)], renderer.Placeholders(dir)...)
+	for _, child := range entries {
+		children = append(children, child.Path)
+		if old[child.Path] && len(app.treeData[child.Path]) == len(child.Children)+len(renderer.Placeholders(child)) {
+			delete(old, child.Path)
+			if app.treeNodes != nil {
+				app.treeNodes[child.Path] = child
+			}
+			continue
+		}
+		delete(old, child.Path)
+		app.forgetBranch(child.Path)
+		buildTreeData(child, depth+1, app.treeData, app.treeDepth, app.treeNodes)
+	}
+	for uid := range old {
+		app.forgetBranch(uid)
+	}
+	app.treeData[dir.Path] = children
+	if app.treeNodes != nil {
+		app.treeNodes[dir.Path] = dir
+	}
+	return true
+}
+
+// forgetBranch drops the tree data of uid and everything below it.
+func (app *FileTreeApp) forgetBranch(uid string) {
+	for _, child := range app.treeData[uid] {
+		app.forgetBranch(child)
+	}
+	delete(app.treeData, uid)
+	delete(app.treeDepth, uid)
+	delete(app.treeNodes, uid)
+}
+
+// refreshRows redraws the rows of the changed nodes and of the folders above them, whose sizes
+// and tokens include theirs. The whole tree is laid out again instead when structural, as
+// entries came or went in an open branch, or when too many rows changed.
+func (app *FileTreeApp) refreshRows(changed []*scanner.TreeNode, structural bool) {
+	if app.tree == nil {
+		return
+	}
+	if structural {
+		// Rows moved, so the rows below are laid out anew
+		app.tree.Refresh()
+		return
+	}
+	rows := make(map[string]bool)
+	for _, node := range changed {
+		for ; node != nil && !rows[node.Path]; node = node.Parent {
+			rows[node.Path] = true
+		}
+	}
+	if len(rows) > maxRowRefreshes {
+		app.tree.Refresh()
+		return
+	}
+	for uid := range rows {
+		app.tree.RefreshItem(uid)
+	}
+}
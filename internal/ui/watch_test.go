@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// batchRecorder is a watching scanner that records when each batch of changes is applied.
+type batchRecorder struct {
+	scanner.FileSystemScanner
+
+	mu      sync.Mutex
+	times   []time.Time
+	changes int
+}
+
+// WatchDirectory implements scanner.WatchingScanner.
+func (r *batchRecorder) WatchDirectory(context.Context, string) (<-chan scanner.TreeChange, error) {
+	return nil, nil
+}
+
+// ApplyChanges implements scanner.WatchingScanner.
+func (r *batchRecorder) ApplyChanges(_ context.Context, _ *scanner.ScanResult, changes []scanner.TreeChange) []*scanner.TreeNode {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.times = append(r.times, time.Now())
+	r.changes += len(changes)
+	return nil
+}
+
+// applied returns the times batches were applied and the number of changes in them.
+func (r *batchRecorder) applied() ([]time.Time, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]time.Time(nil), r.times...), r.changes
+}
+
+func TestBatchChangesOncePerInterval(t *testing.T) {
+	app := newTestApp(t)
+	recorder := &batchRecorder{FileSystemScanner: app.scanner}
+	app.scanner = recorder
+	result := &scanner.ScanResult{RootPath: "root", Root: &scanner.TreeNode{Name: "root", Path: "root", IsDir: true}}
+	app.baseResult = result
+	app.watchCancel = func() {}
+
+	changes := make(chan scanner.TreeChange)
+	defer close(changes)
+	go app.batchChanges(changes, result)
+
+	// A change after a quiet spell is applied at once
+	start := time.Now()
+	changes <- scanner.TreeChange{Op: scanner.ChangeAdded, Path: "root/first"}
+	waitFor(t, "the first change", func() bool { _, n := recorder.applied(); return n == 1 })
+	if wait := time.Since(start); wait >= watchInterval/2 {
+		t.Errorf("first change applied after %v", wait)
+	}
+
+	// A storm of changes is applied at most once per interval
+	sent := 1
+	for storm := time.Now(); time.Since(storm) < 3*watchInterval/2; sent++ {
+		changes <- scanner.TreeChange{Op: scanner.ChangeModified, Path: "root/first"}
+		time.Sleep(20 * time.Millisecond)
+	}
+	waitFor(t, "every change", func() bool { _, n := recorder.applied(); return n == sent })
+	times, _ := recorder.applied()
+	if len(times) > 3 {
+		t.Errorf("%d batches for %d changes over %v", len(times), sent, time.Since(start))
+	}
+	const slack = 50 * time.Millisecond // Batches are applied on the UI thread after they are due
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < watchInterval-slack {
+			t.Errorf("batch %d applied %v after the one before", i, gap)
+		}
+	}
+}
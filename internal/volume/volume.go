@@ -0,0 +1,27 @@
+// Package volume reports the capacity and free space of the file system holding a path.
+package volume
+
+import "errors"
+
+// ErrUnsupported is returned by Stat on platforms it cannot query.
+var ErrUnsupported = errors.New("volume figures are not available on this platform")
+
+// Info holds the totals of a file system. Inode counts are zero where the platform or file
+// system does not report them.
+type Info struct {
+	Total      uint64 `json:"total_bytes"`
+	Used       uint64 `json:"used_bytes"`
+	Free       uint64 `json:"free_bytes"` // Available to the current user, which may be less than Total-Used
+	Inodes     uint64 `json:"inodes,omitempty"`
+	FreeInodes uint64 `json:"free_inodes,omitempty"`
+}
+
+// HasInodes reports whether the inode counts are known.
+func (i *Info) HasInodes() bool {
+	return i.Inodes > 0
+}
+
+// Stat returns the totals of the file system containing path.
+func Stat(path string) (*Info, error) {
+	return stat(path)
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package volume
+
+// stat always fails; see ErrUnsupported.
+func stat(path string) (*Info, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,31 @@
+//go:build linux || darwin
+
+package volume
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.VolumeInfo)
+}
+
+// stat queries statfs(2).
+func stat(path string) (*Info, error) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(path, &fs); err != nil {
+		return nil, fmt.Errorf("failed to query volume of %q: %w", path, err)
+	}
+	size := uint64(fs.Bsize)
+	return &Info{
+		Total:      fs.Blocks * size,
+		Used:       (fs.Blocks - fs.Bfree) * size,
+		Free:       fs.Bavail * size,
+		Inodes:     fs.Files,
+		FreeInodes: fs.Ffree,
+	}, nil
+}
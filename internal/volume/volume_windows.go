@@ -0,0 +1,28 @@
+//go:build windows
+
+package volume
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/capability"
+)
+
+func init() {
+	capability.Register(capability.VolumeInfo)
+}
+
+// stat queries GetDiskFreeSpaceEx; Windows volumes have no inode counts.
+func stat(path string) (*Info, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query volume of %q: %w", path, err)
+	}
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &available, &total, &free); err != nil {
+		return nil, fmt.Errorf("failed to query volume of %q: %w", path, err)
+	}
+	return &Info{Total: total, Used: total - free, Free: available}, nil
+}
@@ -0,0 +1,93 @@
+// Package app runs File Tree Scanner, so custom builds can register extensions
+// from the filetree package before starting it.
+package app
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/applog"
+	"github.com/Akaiko1/file-tree-scanner/internal/cli"
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/diagnose"
+	"github.com/Akaiko1/file-tree-scanner/internal/instance"
+	"github.com/Akaiko1/file-tree-scanner/internal/ui"
+)
+
+// Main runs File Tree Scanner with the registered annotators: the command line mode when
+// --no-gui or --doctor is given, the GUI otherwise. Without a display, a command line naming a
+// folder is scanned headless and anything else exits with cli.ExitNoDisplay. It does not return
+// in CLI mode. A folder given to the GUI is handed to an already running instance unless
+// --new-instance is given.
+func Main() {
+	applog.Install()
+
+	if cli.Requested(os.Args[1:]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	// Without a display the GUI toolkit panics on start; a complete scan command runs headless
+	if display := diagnose.Display(); display.Status != diagnose.Pass {
+		if cli.Headless(os.Args[1:]) {
+			log.Printf("Warning: %s, scanning without the GUI", display.Detail)
+			os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+		}
+		os.Exit(cli.NoDisplay(os.Stderr, filepath.Base(os.Args[0]), display.Detail))
+	}
+
+	path, newInstance := guiArgs(os.Args[1:])
+	if !newInstance && instance.Hand(path) {
+		log.Println("Handed over to the running instance")
+		return
+	}
+
+	log.Println("Starting File Tree Scanner...")
+
+	config := config.DefaultConfig()
+	log.Printf("Config: MaxDepth=%d, ShowHidden=%v", config.MaxDepth, config.ShowHidden)
+
+	app := ui.NewFileTreeApp(config)
+	log.Println("App created, starting UI...")
+
+	if !newInstance {
+		listener, err := instance.Listen(app.Open)
+		switch {
+		case errors.Is(err, instance.ErrRunning) && instance.Hand(path):
+			// Another launch got there first
+			log.Println("Handed over to the running instance")
+			return
+		case err != nil:
+			log.Printf("Warning: single-instance mode unavailable: %v", err)
+		default:
+			defer listener.Close()
+		}
+	}
+
+	if path != "" {
+		app.ScanOnStart(path)
+	}
+	app.Run()
+}
+
+// guiArgs returns the folder named on the GUI's command line, if any, and whether
+// --new-instance was given. The folder is made absolute, since a running instance resolves it
+// from its own working directory.
+func guiArgs(args []string) (path string, newInstance bool) {
+	for _, arg := range args {
+		switch {
+		case arg == "-"+instance.Flag || arg == "--"+instance.Flag:
+			newInstance = true
+		case !strings.HasPrefix(arg, "-") && path == "":
+			path = arg
+		}
+	}
+	if path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+	}
+	return path, newInstance
+}
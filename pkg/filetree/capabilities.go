@@ -0,0 +1,36 @@
+package filetree
+
+import "github.com/Akaiko1/file-tree-scanner/internal/capability"
+
+// Capability identifies a feature a build may support, such as "xattrs" or "archives".
+type Capability = capability.Name
+
+// Features to check for with Supports. CapabilityScan and CapabilityRender are always supported.
+const (
+	CapabilityScan               = capability.Scan
+	CapabilityRender             = capability.Render
+	CapabilityHashes             = capability.Hashes
+	CapabilityXattrs             = capability.Xattrs
+	CapabilityAllocatedSize      = capability.AllocatedSize
+	CapabilityBackgroundPriority = capability.BackgroundPriority
+	CapabilityOneFileSystem      = capability.OneFileSystem
+	CapabilityVolumeInfo         = capability.VolumeInfo
+	CapabilityArchives           = capability.Archives
+	CapabilitySQLiteExport       = capability.SQLiteExport
+	CapabilityContextPack        = capability.ContextPack
+	CapabilityLayoutCheck        = capability.LayoutCheck
+	CapabilitySnapshots          = capability.Snapshots
+	CapabilityWatch              = capability.Watch
+)
+
+// Capabilities returns the features this build supports, sorted by name. Features that depend on
+// the platform or build tags appear only where they are implemented, and features of packages the
+// program does not link, such as archive listing when only this package is used, are absent.
+func Capabilities() []Capability {
+	return capability.List()
+}
+
+// Supports reports whether this build supports c.
+func Supports(c Capability) bool {
+	return capability.Has(c)
+}
@@ -0,0 +1,51 @@
+package filetree
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestCoreCapabilities(t *testing.T) {
+	for _, c := range []Capability{CapabilityScan, CapabilityRender, CapabilityHashes, CapabilityContextPack} {
+		if !Supports(c) {
+			t.Errorf("core capability %s is missing", c)
+		}
+	}
+	list := Capabilities()
+	if !slices.IsSorted(list) {
+		t.Errorf("capabilities are not sorted: %q", list)
+	}
+	for _, c := range list {
+		if !Supports(c) {
+			t.Errorf("%s is listed but not supported", c)
+		}
+	}
+}
+
+func TestPlatformCapabilities(t *testing.T) {
+	unix := runtime.GOOS != "windows" && runtime.GOOS != "js" && runtime.GOOS != "wasip1" && runtime.GOOS != "plan9"
+	tests := []struct {
+		c    Capability
+		want bool
+	}{
+		{CapabilityXattrs, runtime.GOOS == "linux" || runtime.GOOS == "darwin" || runtime.GOOS == "windows"},
+		{CapabilityBackgroundPriority, runtime.GOOS == "linux" || runtime.GOOS == "windows"},
+		{CapabilityAllocatedSize, unix || runtime.GOOS == "windows"},
+		{CapabilityOneFileSystem, unix},
+	}
+	for _, tt := range tests {
+		if got := Supports(tt.c); got != tt.want {
+			t.Errorf("%s supported %v on %s, want %v", tt.c, got, runtime.GOOS, tt.want)
+		}
+	}
+}
+
+func TestUnlinkedCapabilities(t *testing.T) {
+	// This package links none of the packages providing these
+	for _, c := range []Capability{CapabilityArchives, CapabilitySQLiteExport, CapabilityLayoutCheck, CapabilitySnapshots, CapabilityVolumeInfo} {
+		if Supports(c) || slices.Contains(Capabilities(), c) {
+			t.Errorf("%s is reported without the module providing it", c)
+		}
+	}
+}
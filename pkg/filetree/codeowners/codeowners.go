@@ -0,0 +1,156 @@
+// Package codeowners is an example annotator that labels files with their owners
+// from a CODEOWNERS file:
+//
+//	func main() {
+//		owners, err := codeowners.Load("CODEOWNERS")
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		owners.Register()
+//		app.Main()
+//	}
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Akaiko1/file-tree-scanner/pkg/filetree"
+)
+
+// rule is one CODEOWNERS line.
+type rule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// Owners resolves file owners from CODEOWNERS rules; the last matching rule wins.
+type Owners struct {
+	rules []rule
+
+	mu     sync.RWMutex
+	labels map[string]string // Owner label per file path, filled by Prepare
+}
+
+// Load reads a CODEOWNERS file.
+func Load(path string) (*Owners, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CODEOWNERS: %w", err)
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads CODEOWNERS rules from r.
+func Parse(r io.Reader) (*Owners, error) {
+	owners := &Owners{labels: make(map[string]string)}
+	lineScanner := bufio.NewScanner(r)
+	for lineNo := 1; lineScanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(lineScanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		pattern, err := compile(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		owners.rules = append(owners.rules, rule{pattern: pattern, owners: fields[1:]})
+	}
+	if err := lineScanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+	return owners, nil
+}
+
+// compile converts a gitignore-style CODEOWNERS pattern to a regular expression over
+// slash-separated paths relative to the repository root.
+func compile(pattern string) (*regexp.Regexp, error) {
+	trimmed := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("(^|/)")
+	}
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case trimmed[i] == '*':
+			expr.WriteString("[^/]*")
+		case trimmed[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(trimmed[i : i+1]))
+		}
+	}
+	// A pattern naming a directory owns everything below it
+	expr.WriteString("(/.*)?$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// OwnersOf returns the owners of a slash-separated path relative to the repository root.
+func (o *Owners) OwnersOf(rel string) []string {
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if o.rules[i].pattern.MatchString(rel) {
+			return o.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// Prepare resolves the owners of every file below root, which is taken as the repository root.
+func (o *Owners) Prepare(root *filetree.Node) {
+	labels := make(map[string]string)
+	var walk func(node *filetree.Node)
+	walk = func(node *filetree.Node) {
+		if !node.IsDir {
+			if rel, err := filepath.Rel(root.Path, node.Path); err == nil {
+				if owners := o.OwnersOf(filepath.ToSlash(rel)); len(owners) > 0 {
+					labels[node.Path] = strings.Join(owners, " ")
+				}
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	o.mu.Lock()
+	o.labels = labels
+	o.mu.Unlock()
+}
+
+// Annotate returns the owners prepared for a file node.
+func (o *Owners) Annotate(node *filetree.Node) (string, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	label, ok := o.labels[node.Path]
+	return label, ok
+}
+
+// Register installs the owners as a batch annotator named "codeowners".
+func (o *Owners) Register() {
+	filetree.RegisterBatchAnnotator("codeowners", o.Prepare, o.Annotate)
+}
@@ -0,0 +1,100 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/renderer"
+	"github.com/Akaiko1/file-tree-scanner/pkg/filetree"
+)
+
+const testCodeowners = `# Default owners
+*           @everyone
+
+*.go        @backend
+/docs/      @writers
+build/      @release
+apps/**/test.js @qa
+/scripts/*.sh @ops @sre
+/docs/drafts/
+`
+
+func TestOwnersOf(t *testing.T) {
+	owners, err := Parse(strings.NewReader(testCodeowners))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"README.md", "@everyone"},
+		{"main.go", "@backend"},
+		{"internal/scanner/scanner.go", "@backend"},
+		{"docs/guide.md", "@writers"},
+		{"docs/api/index.go", "@writers"}, // The later rule wins
+		{"internal/docs/notes.md", "@everyone"},
+		{"build/out.bin", "@release"},
+		{"tools/build/out.bin", "@release"},
+		{"apps/test.js", "@qa"},
+		{"apps/web/unit/test.js", "@qa"},
+		{"scripts/deploy.sh", "@ops @sre"},
+		{"scripts/ci/deploy.sh", "@everyone"},
+		{"docs/drafts/idea.md", ""}, // A rule without owners removes them
+		{"src/[a].txt", "@everyone"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(owners.OwnersOf(tt.path), " "); got != tt.want {
+			t.Errorf("OwnersOf(%s) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "CODEOWNERS")); err == nil {
+		t.Error("loading a missing file succeeded")
+	}
+}
+
+func TestAnnotateRenderedTree(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CODEOWNERS")
+	if err := os.WriteFile(path, []byte(testCodeowners), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	owners, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := &filetree.Node{Name: "repo", Path: "/repo", IsDir: true}
+	docs := &filetree.Node{Name: "docs", Path: "/repo/docs", IsDir: true, Parent: root}
+	guide := &filetree.Node{Name: "guide.md", Path: "/repo/docs/guide.md", Parent: docs}
+	main := &filetree.Node{Name: "main.go", Path: "/repo/main.go", Parent: root}
+	docs.Children = []*filetree.Node{guide}
+	root.Children = []*filetree.Node{docs, main}
+
+	owners.Register()
+	owners.Prepare(root)
+	if label, ok := owners.Annotate(docs); ok {
+		t.Errorf("directory labelled %q; only files are", label)
+	}
+
+	opts := renderer.DefaultOptions()
+	opts.Header = ""
+	got := renderer.NewStandardTreeRenderer(opts).RenderTree(root)
+	want := "📁 docs/\n" +
+		"└── 📄 guide.md [@writers]\n" +
+		"└── 📄 main.go [@backend]\n"
+	if got != want {
+		t.Errorf("rendered:\n%q\nwant:\n%q", got, want)
+	}
+
+	// A new scan replaces the prepared labels
+	owners.Prepare(&filetree.Node{Name: "other", Path: "/other", IsDir: true})
+	if _, ok := owners.Annotate(main); ok {
+		t.Error("labels of the previous tree kept after a new pre-pass")
+	}
+}
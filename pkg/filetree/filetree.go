@@ -0,0 +1,54 @@
+// Package filetree is the public extension API of File Tree Scanner.
+//
+// Annotators attach short labels, such as code owners or build targets, to entries of
+// the scanned tree. Labels are shown in brackets after the entry name in the tree view
+// and in rendered output:
+//
+//	📄 main.go [@backend-team]
+//
+// Register annotators in a small main package before starting the application with
+// the app subpackage:
+//
+//	func main() {
+//		filetree.RegisterAnnotator("owners", func(node *filetree.Node) (string, bool) {
+//			return lookupOwner(node.Path)
+//		})
+//		app.Main()
+//	}
+//
+// Annotate runs during rendering and for every tree row the GUI draws, so it must be
+// fast. Put expensive lookups in a batch pre-pass with RegisterBatchAnnotator; the
+// pre-pass runs once per scan, before any label is requested.
+//
+// Scan reads a directory into a tree the caller owns. SortChildren, Prune and Filter
+// derive changed views of a tree without modifying it, sharing the unchanged parts.
+//
+// Pack writes a context pack of a directory: its tree and the contents of its text
+// files in one Markdown document. Deduplicated packs store each distinct content once;
+// Unpack turns them back into the flat document.
+//
+// Capabilities lists the optional features this build supports, such as extended attributes or
+// archive listing, which depend on the platform and on the packages linked in.
+package filetree
+
+import (
+	"github.com/Akaiko1/file-tree-scanner/internal/annotate"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Node is an entry of a scanned tree. Annotators must treat it as read-only.
+type Node = scanner.TreeNode
+
+// Annotator returns a label for a node, or false to leave the node unannotated.
+type Annotator func(node *Node) (label string, ok bool)
+
+// RegisterAnnotator adds an annotator. Labels from several annotators appear in registration order.
+func RegisterAnnotator(name string, annotator Annotator) {
+	annotate.Register(annotate.Annotator{Name: name, Annotate: annotate.Func(annotator)})
+}
+
+// RegisterBatchAnnotator adds an annotator with a pre-pass that receives the root of every
+// new scan, imported listing or relocated tree before annotator is called for its nodes.
+func RegisterBatchAnnotator(name string, prepare func(root *Node), annotator Annotator) {
+	annotate.Register(annotate.Annotator{Name: name, Prepare: prepare, Annotate: annotate.Func(annotator)})
+}
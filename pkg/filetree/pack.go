@@ -0,0 +1,33 @@
+package filetree
+
+import (
+	"context"
+	"io"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/contextpack"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// PackOptions controls which files a context pack includes and whether it is deduplicated.
+type PackOptions = contextpack.Options
+
+// DefaultPackOptions returns the options used by the GUI and CLI.
+func DefaultPackOptions() PackOptions {
+	return contextpack.DefaultOptions()
+}
+
+// Pack scans dir with the default scan settings and writes a context pack of it to w.
+// With opts.Dedup set, each distinct file content is stored once; Unpack restores the flat form.
+func Pack(ctx context.Context, dir string, w io.Writer, opts PackOptions) error {
+	result, err := scanner.NewFileTreeScanner(config.DefaultConfig()).ScanDirectory(ctx, dir)
+	if err != nil {
+		return err
+	}
+	return contextpack.Write(ctx, w, result, opts)
+}
+
+// Unpack reads a deduplicated context pack and writes the flat pack it stands for.
+func Unpack(r io.Reader, w io.Writer) error {
+	return contextpack.Unpack(r, w)
+}
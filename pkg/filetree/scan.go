@@ -0,0 +1,25 @@
+package filetree
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/Akaiko1/file-tree-scanner/internal/config"
+	"github.com/Akaiko1/file-tree-scanner/internal/scanner"
+)
+
+// Result is the outcome of Scan: the tree below Root and counts describing it.
+type Result = scanner.ScanResult
+
+// Scan reads dir with the default scan settings. The result belongs to the caller: nothing
+// else holds on to it, so it can be changed freely. To keep the original alongside a changed
+// view, use SortChildren, Prune and Filter, which leave their input untouched.
+func Scan(ctx context.Context, dir string) (*Result, error) {
+	return scanner.NewFileTreeScanner(config.DefaultConfig()).ScanDirectory(ctx, dir)
+}
+
+// ScanFS reads all of fsys, such as an embed.FS, with the default scan settings. The root of
+// the result is named rootName, and every path in it starts with rootName.
+func ScanFS(ctx context.Context, fsys fs.FS, rootName string) (*Result, error) {
+	return scanner.NewFileTreeScannerFS(config.DefaultConfig(), fsys, rootName).ScanDirectory(ctx, rootName)
+}
@@ -0,0 +1,70 @@
+package filetree
+
+import "sort"
+
+// The helpers below never modify the tree they are given. They return a new tree that shares
+// every subtree it did not need to change, so a call costs only the nodes on changed paths.
+// A node is copied together with all of its ancestors, and the copies' Children are fresh
+// slices. Shared nodes keep their Parent pointing into the original tree, so walk the result
+// from its root rather than upwards from a node. Directory sizes are not recomputed.
+
+// SortChildren returns root with every directory's children in the order given by less.
+// The sort is stable; directories already in order are shared.
+func SortChildren(root *Node, less func(a, b *Node) bool) *Node {
+	children := make([]*Node, len(root.Children))
+	for i, child := range root.Children {
+		children[i] = SortChildren(child, less)
+	}
+	sort.SliceStable(children, func(i, j int) bool { return less(children[i], children[j]) })
+	return withChildren(root, children)
+}
+
+// Prune returns root without the entries for which drop returns true, along with their
+// contents. The root itself is never dropped.
+func Prune(root *Node, drop func(*Node) bool) *Node {
+	children := make([]*Node, 0, len(root.Children))
+	for _, child := range root.Children {
+		if !drop(child) {
+			children = append(children, Prune(child, drop))
+		}
+	}
+	return withChildren(root, children)
+}
+
+// Filter returns root with only the entries for which keep returns true and the directories
+// leading to them. The root is always returned, possibly without children.
+func Filter(root *Node, keep func(*Node) bool) *Node {
+	filtered, _ := filter(root, keep)
+	return filtered
+}
+
+// filter returns node's filtered copy and whether it or something below it was kept.
+func filter(node *Node, keep func(*Node) bool) (*Node, bool) {
+	children := make([]*Node, 0, len(node.Children))
+	for _, child := range node.Children {
+		if filtered, kept := filter(child, keep); kept {
+			children = append(children, filtered)
+		}
+	}
+	return withChildren(node, children), len(children) > 0 || keep(node)
+}
+
+// withChildren returns node if children are the ones it already has, or a shallow copy of it
+// with children otherwise.
+func withChildren(node *Node, children []*Node) *Node {
+	if len(children) == len(node.Children) {
+		same := true
+		for i := range children {
+			if children[i] != node.Children[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return node
+		}
+	}
+	copied := *node
+	copied.Children = children
+	return &copied
+}
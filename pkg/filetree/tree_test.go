@@ -0,0 +1,178 @@
+package filetree
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testTree returns a small tree:
+//
+//	root
+//	├── b/        z.go, a.txt
+//	├── a/        sorted/ (x.go, y.go)
+//	└── c.md
+func testTree() *Node {
+	dir := func(name string, children ...*Node) *Node {
+		node := &Node{Name: name, IsDir: true, Children: children}
+		for _, child := range children {
+			child.Parent = node
+		}
+		return node
+	}
+	file := func(name string) *Node { return &Node{Name: name} }
+	return dir("root",
+		dir("b", file("z.go"), file("a.txt")),
+		dir("a", dir("sorted", file("x.go"), file("y.go"))),
+		file("c.md"),
+	)
+}
+
+// shape lists the names below node, indented by depth, with the pointer of each node, so any
+// change to the tree, its order or its nodes shows.
+type shapeEntry struct {
+	node *Node
+	line string
+}
+
+func shape(node *Node) []shapeEntry {
+	var entries []shapeEntry
+	var walk func(node *Node, indent string)
+	walk = func(node *Node, indent string) {
+		entries = append(entries, shapeEntry{node, indent + node.Name})
+		for _, child := range node.Children {
+			walk(child, indent+"  ")
+		}
+	}
+	walk(node, "")
+	return entries
+}
+
+// lines returns the names of a shape.
+func lines(entries []shapeEntry) string {
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.line)
+	}
+	return strings.Join(names, "\n")
+}
+
+// byName orders nodes by name.
+func byName(a, b *Node) bool { return a.Name < b.Name }
+
+func TestSortChildren(t *testing.T) {
+	root := testTree()
+	before := shape(root)
+	sorted := SortChildren(root, byName)
+
+	if !reflect.DeepEqual(shape(root), before) {
+		t.Errorf("input changed:\n%s", lines(shape(root)))
+	}
+	want := "root\n  a\n    sorted\n      x.go\n      y.go\n  b\n    a.txt\n    z.go\n  c.md"
+	if got := lines(shape(sorted)); got != want {
+		t.Errorf("sorted:\n%s\nwant:\n%s", got, want)
+	}
+	if sorted == root || sorted.Children[1] == root.Children[0] {
+		t.Error("nodes whose children were reordered are shared with the input")
+	}
+	if sorted.Children[0] != root.Children[1] || sorted.Children[2] != root.Children[2] {
+		t.Error("subtrees already in order are copied rather than shared")
+	}
+	if again := SortChildren(sorted, byName); again != sorted {
+		t.Error("sorting a sorted tree copied it")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root := testTree()
+	before := shape(root)
+	pruned := Prune(root, func(node *Node) bool { return node.Name == "z.go" || node.Name == "sorted" })
+
+	if !reflect.DeepEqual(shape(root), before) {
+		t.Errorf("input changed:\n%s", lines(shape(root)))
+	}
+	want := "root\n  b\n    a.txt\n  a\n  c.md"
+	if got := lines(shape(pruned)); got != want {
+		t.Errorf("pruned:\n%s\nwant:\n%s", got, want)
+	}
+	if pruned.Children[2] != root.Children[2] || pruned.Children[0].Children[0] != root.Children[0].Children[1] {
+		t.Error("entries that were kept unchanged are copied rather than shared")
+	}
+	if kept := Prune(root, func(*Node) bool { return false }); kept != root {
+		t.Error("pruning nothing copied the tree")
+	}
+	if all := Prune(root, func(*Node) bool { return true }); all == root || len(all.Children) != 0 || len(root.Children) != 3 {
+		t.Error("pruning everything did not return the root alone, leaving the input as is")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	root := testTree()
+	before := shape(root)
+	filtered := Filter(root, func(node *Node) bool { return strings.HasSuffix(node.Name, ".go") })
+
+	if !reflect.DeepEqual(shape(root), before) {
+		t.Errorf("input changed:\n%s", lines(shape(root)))
+	}
+	want := "root\n  b\n    z.go\n  a\n    sorted\n      x.go\n      y.go"
+	if got := lines(shape(filtered)); got != want {
+		t.Errorf("filtered:\n%s\nwant:\n%s", got, want)
+	}
+	if filtered.Children[1] != root.Children[1] {
+		t.Error("a directory kept whole is copied rather than shared")
+	}
+	if none := Filter(root, func(*Node) bool { return false }); none == root || len(none.Children) != 0 {
+		t.Error("filtering out everything did not return the root alone")
+	}
+}
+
+func TestDerivedChildrenAreFresh(t *testing.T) {
+	// The Children of copied nodes belong to the result: changing them leaves the input alone
+	root := testTree()
+	before := shape(root)
+	for name, derived := range map[string]*Node{
+		"SortChildren": SortChildren(root, byName),
+		"Prune":        Prune(root, func(node *Node) bool { return node.Name == "c.md" }),
+		"Filter":       Filter(root, func(node *Node) bool { return node.Name != "c.md" }),
+	} {
+		derived.Children[0], derived.Children[1] = derived.Children[1], derived.Children[0]
+		derived.Children = append(derived.Children[:1], &Node{Name: "added"})
+		derived.Name = "renamed"
+		if !reflect.DeepEqual(shape(root), before) {
+			t.Errorf("%s: changing the result changed the input:\n%s", name, lines(shape(root)))
+		}
+	}
+}
+
+func TestScanResultsAreIndependent(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"src/main.go", "README.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first, err := Scan(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Scan(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := lines(shape(second.Root))
+
+	// A caller owns its result: changing it in place affects no other scan
+	first.Root.Children[0].Name = "changed"
+	first.Root.Children[0].Children = nil
+	first.Root.Children = first.Root.Children[:1]
+	if got := lines(shape(second.Root)); got != want {
+		t.Errorf("changing one result changed another:\n%s\nwant:\n%s", got, want)
+	}
+}